@@ -42,6 +42,10 @@ type AgentConfig struct {
 	Parameters    responses.Parameters
 	StickyHandoff bool
 
+	// MaxOutputRepairs caps the turns spent fixing an answer that does not
+	// match Output — see agents.AgentOptions.Output.
+	MaxOutputRepairs *int
+
 	// Skills are folders of instructions the agent reads only when it needs
 	// them — see NewSkillRegistryFromDir. The agent lists them in its prompt
 	// and adds the reader tool to Tools itself.
@@ -51,6 +55,26 @@ type AgentConfig struct {
 	// every tool it calls; a ModelCallHook wraps every call to the model, which
 	// is where a budget or credit check belongs. One hook may be both.
 	Hooks []agents.Hook
+
+	// InputGuardrails and OutputGuardrails can end a run before the agent
+	// acts on its input or before its answer reaches the user — see
+	// agents.InputGuardrail.
+	InputGuardrails  []agents.InputGuardrail
+	OutputGuardrails []agents.OutputGuardrail
+
+	// LifecycleHooks are told about a run's start and end, its handoffs,
+	// approvals, steering messages, summaries and stops — see
+	// agents.LifecycleHook.
+	LifecycleHooks []agents.LifecycleHook
+
+	// Budget caps the tokens, cost, time and tool calls a run may spend,
+	// sub-agents and handoffs included — see agents.RunBudget. A run's own
+	// AgentInput.Budget overrides it.
+	Budget *agents.RunBudget
+
+	// ModelPolicy names the models the agent falls back to when LLM fails a
+	// turn, and the stronger one it escalates to — see agents.ModelPolicy.
+	ModelPolicy *agents.ModelPolicy
 }
 
 func (ac *AgentConfig) toAgentOptions() *agents.AgentOptions {
@@ -68,6 +92,13 @@ func (ac *AgentConfig) toAgentOptions() *agents.AgentOptions {
 		StickyHandoff: ac.StickyHandoff,
 		Skills:        ac.Skills,
 		Hooks:         ac.Hooks,
+
+		MaxOutputRepairs: ac.MaxOutputRepairs,
+		InputGuardrails:  ac.InputGuardrails,
+		OutputGuardrails: ac.OutputGuardrails,
+		LifecycleHooks:   ac.LifecycleHooks,
+		Budget:           ac.Budget,
+		ModelPolicy:      ac.ModelPolicy,
 	}
 }
 
@@ -1,14 +1,17 @@
 package sdk
 
 import (
+	"context"
 	"strings"
 
 	"github.com/hastekit/agent-sdk-go/pkg/gateway"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/models"
 )
 
 type ProviderConfig = gateway.ProviderConfig
 type APIKeyConfig = gateway.APIKeyConfig
+type CircuitHealth = gateway.CircuitHealth
 
 type ProviderName = llm.ProviderName
 
@@ -28,20 +31,44 @@ var (
 )
 
 type LLMClient struct {
-	providerConfigs []ProviderConfig
-	llmGateway      *gateway.InternalLLMGateway
+	configStore gateway.ConfigStore
+	llmGateway  *gateway.InternalLLMGateway
+	health      *gateway.HealthTracker
 }
 
 func NewLLMClient(configs []ProviderConfig) *LLMClient {
-	gw := gateway.NewLLMGateway(gateway.NewInMemoryConfigStore(configs))
-	gw.UseMiddleware(gateway.NewTracingMiddleware())
+	return NewLLMClientWithConfigStore(gateway.NewInMemoryConfigStore(configs))
+}
+
+// NewLLMClientWithConfigStore builds a client whose providers and keys come
+// from store, e.g. a gateway.FileConfigStore so key rotations are picked up
+// without a restart.
+func NewLLMClientWithConfigStore(store gateway.ConfigStore) *LLMClient {
+	health := gateway.NewHealthTracker(gateway.HealthOptions{})
+
+	gw := gateway.NewLLMGateway(store)
+	gw.UseMiddleware(gateway.NewTracingMiddleware(), health)
 
 	return &LLMClient{
-		providerConfigs: configs,
-		llmGateway:      gateway.NewInternalLLMGateway(gw),
+		configStore: store,
+		llmGateway:  gateway.NewInternalLLMGateway(gw),
+		health:      health,
 	}
 }
 
+// Health reports the circuit breaker state of every provider and API key
+// the client has used.
+func (c *LLMClient) Health() []CircuitHealth {
+	return c.health.Snapshot()
+}
+
+// Close releases what the client registered globally, such as its circuit
+// state metric. Call it once the client is no longer needed; a client that
+// is dropped without it stays reachable from the global meter.
+func (c *LLMClient) Close() error {
+	return c.health.Close()
+}
+
 type Model struct {
 	modelId string
 	client  llm.Provider
@@ -52,6 +79,20 @@ func (c *LLMClient) Model(id string) llm.Provider {
 
 	return gateway.NewLLMClient(
 		c.llmGateway,
-		gateway.NewInMemoryConfigStore(c.providerConfigs), gateway.WithModel(llm.ProviderName(i[0]), i[1]),
+		c.configStore, gateway.WithModel(llm.ProviderName(i[0]), i[1]),
+		gateway.WithHealthTracker(c.health),
 	)
 }
+
+// ListModels lists the models of every configured provider that can list
+// them, as "Provider/id" pairs for Model. Listings are cached per provider;
+// see gateway.LLMGateway.ListModels.
+func (c *LLMClient) ListModels(ctx context.Context) ([]models.Model, error) {
+	return c.llmGateway.ListModels(ctx, gateway.ProviderConfigKeyFromContext(ctx))
+}
+
+// ListVoices lists the speech voices of every configured provider that can
+// list them.
+func (c *LLMClient) ListVoices(ctx context.Context) ([]models.Voice, error) {
+	return c.llmGateway.ListVoices(ctx, gateway.ProviderConfigKeyFromContext(ctx))
+}
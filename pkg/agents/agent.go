@@ -48,6 +48,31 @@ type Agent struct {
 	singleTurn     bool
 	modelCallHooks []ModelCallHook
 	skills         SkillProvider
+
+	outputValidator  *outputValidator
+	maxOutputRepairs int
+
+	inputGuardrails  []InputGuardrail
+	outputGuardrails []OutputGuardrail
+
+	reflection *Reflection
+
+	lifecycleHooks []LifecycleHook
+
+	budget *RunBudget
+
+	// llmFamily is the provider family of the agent's own model; a turn
+	// made on another family's is sent without its encrypted state.
+	llmFamily        llm.ProviderName
+	fallbacks        []modelTier
+	escalationModel  *modelTier
+	escalationPolicy *ModelEscalation
+
+	// handoff and caller are the handoff this agent was reached through, and
+	// the agent that followed it — set on the copy a handoff runs, so it can
+	// filter its input and hand a delegated run back.
+	handoff *Handoff
+	caller  *Agent
 }
 
 type AgentOptions struct {
@@ -55,9 +80,16 @@ type AgentOptions struct {
 	Instruction SystemPromptProvider
 	Parameters  responses.Parameters
 
-	Name     string
-	LLM      llm.Provider
-	Output   map[string]any
+	Name string
+	LLM  llm.Provider
+
+	// Output is the JSON schema the agent's final answer must match (see
+	// NewOutputSchema and OutputSchemaFor). An answer that does not is sent
+	// back to the model with the validation error, up to MaxOutputRepairs
+	// times, before the run fails with an *OutputValidationError.
+	Output           map[string]any
+	MaxOutputRepairs *int
+
 	Tools    []Tool
 	Handoffs []*Handoff
 
@@ -91,6 +123,35 @@ type AgentOptions struct {
 	// so a hook's methods become journaled steps either way.
 	Hooks []Hook
 
+	// InputGuardrails check what the run was asked, alongside the model call
+	// that opens it; OutputGuardrails check the final answer before the run
+	// completes. The first to trip ends the run with status guardrail_tripped
+	// and a run.guardrail_tripped chunk, and nothing from the run is saved to
+	// the thread. See InputGuardrail.
+	//
+	// They belong to the agent the run starts on: a handoff target's input
+	// guardrails do not run, since the input was already checked.
+	InputGuardrails  []InputGuardrail
+	OutputGuardrails []OutputGuardrail
+
+	// Reflection has a critic read the agent's draft answer, and send it
+	// back for revision, before the run completes — see Reflection. An
+	// answer the critic passes still goes through the OutputGuardrails.
+	Reflection *Reflection
+
+	// LifecycleHooks are told when a run starts and ends, hands off, asks
+	// for or is given approval, picks up a steering message, summarizes its
+	// history, or stops — see LifecycleHook.
+	LifecycleHooks []LifecycleHook
+
+	// Budget caps what a run of this agent may spend — see RunBudget. An
+	// AgentInput's own Budget takes its place.
+	Budget *RunBudget
+
+	// ModelPolicy names the models the agent turns to when its own fails a
+	// turn, or is not up to the task — see ModelPolicy.
+	ModelPolicy *ModelPolicy
+
 	// SingleTurn ends the run as soon as the model has responded, before any
 	// tool is executed. The returned AgentOutput carries exactly what the model
 	// emitted — assistant text and/or tool calls — with status completed.
@@ -123,6 +184,11 @@ func NewAgent(opts *AgentOptions) *Agent {
 		}
 	}
 
+	maxOutputRepairs := DefaultMaxOutputRepairs
+	if opts.MaxOutputRepairs != nil && *opts.MaxOutputRepairs >= 0 {
+		maxOutputRepairs = *opts.MaxOutputRepairs
+	}
+
 	if opts.History == nil {
 		opts.History = history.NewConversationManager(history.NewInMemoryConversationPersistence())
 	}
@@ -155,6 +221,12 @@ func NewAgent(opts *AgentOptions) *Agent {
 		toolExecutor = aware.WithToolCallHooks(ToolCallHooksOf(opts.Hooks))
 	}
 
+	fallbacks, escalationModel := newModelTiers(opts.ModelPolicy)
+	var escalationPolicy *ModelEscalation
+	if opts.ModelPolicy != nil {
+		escalationPolicy = opts.ModelPolicy.Escalation
+	}
+
 	return &Agent{
 		Name:        opts.Name,
 		output:      opts.Output,
@@ -177,6 +249,23 @@ func NewAgent(opts *AgentOptions) *Agent {
 		stickyHandoff:  opts.StickyHandoff,
 		singleTurn:     opts.SingleTurn,
 		modelCallHooks: ModelCallHooksOf(opts.Hooks),
+
+		outputValidator:  newOutputValidator(opts.Output),
+		maxOutputRepairs: maxOutputRepairs,
+
+		inputGuardrails:  opts.InputGuardrails,
+		outputGuardrails: opts.OutputGuardrails,
+
+		reflection: opts.Reflection,
+
+		lifecycleHooks: opts.LifecycleHooks,
+
+		budget: opts.Budget,
+
+		llmFamily:        providerFamily(opts.LLM),
+		fallbacks:        fallbacks,
+		escalationModel:  escalationModel,
+		escalationPolicy: escalationPolicy,
 	}
 }
 
@@ -184,9 +273,36 @@ func NewAgent(opts *AgentOptions) *Agent {
 // struct wholesale rather than listing fields: this used to enumerate them,
 // and a field added anywhere else was silently dropped here — the copy simply
 // lost it, with nothing to fail until the behaviour went missing at runtime.
+//
+// The agent's provider family stays what its options said unless the new LLM
+// names one: a durable runtime's proxy cannot, and stands in for the same
+// provider.
 func (e *Agent) WithLLM(wrappedLLM LLM) *Agent {
 	clone := *e
 	clone.llm = wrappedLLM
+	if family := providerFamily(wrappedLLM); family != "" {
+		clone.llmFamily = family
+	}
+	return &clone
+}
+
+// WithHistory returns a copy of the agent that keeps its conversations in h,
+// for agents that take turns on one thread, each with its own options.
+func (e *Agent) WithHistory(h *history.CommonConversationManager) *Agent {
+	clone := *e
+	clone.history = h
+	return &clone
+}
+
+// WithStreamBroker returns a copy of the agent that streams through broker,
+// for agents whose runs publish on a stream another run owns. Its tool
+// executor watches broker's stop flag instead of the old one's.
+func (e *Agent) WithStreamBroker(broker StreamBroker) *Agent {
+	clone := *e
+	clone.streamBroker = broker
+	if aware, ok := clone.toolExecutor.(BrokerAwareToolExecutor); ok {
+		clone.toolExecutor = aware.WithStreamBroker(broker)
+	}
 	return &clone
 }
 
@@ -215,19 +331,31 @@ func (e *Agent) PrepareHandoffTools(ctx context.Context) []Tool {
 	coreTools := []Tool{}
 
 	if e.handoffs != nil && len(e.handoffs) > 0 {
+		properties := map[string]any{
+			"agent_name": map[string]any{
+				"type":        "string",
+				"description": "Name of the target agent",
+			},
+			"reason": map[string]any{
+				"type":        "string",
+				"description": "Why the conversation is being transferred",
+			},
+		}
+		if slices.ContainsFunc(e.handoffs, func(h *Handoff) bool { return h.Payload != nil }) {
+			properties["arguments"] = map[string]any{
+				"type":        "object",
+				"description": "Arguments for the target agent, as described for it above",
+			}
+		}
+
 		coreTools = append(coreTools, NewHandoffTool(&responses.ToolUnion{
 			OfFunction: &responses.FunctionTool{
 				Name:        "transfer_to_agent",
-				Description: utils.Ptr("Transfer the conversation to another agent"),
+				Description: utils.Ptr(handoffToolDescription(e.handoffs)),
 				Parameters: map[string]any{
-					"type": "object",
-					"properties": map[string]any{
-						"agent_name": map[string]any{
-							"type":        "string",
-							"description": "Name of the target agent",
-						},
-					},
-					"required": []string{"agent_name"},
+					"type":       "object",
+					"properties": properties,
+					"required":   []string{"agent_name"},
 				},
 			},
 		}))
@@ -286,6 +414,10 @@ type AgentInput struct {
 
 	// This is the conversation ID shared by the parent agent and the sub-agent.
 	SessionID string `json:"shared_session_id"`
+
+	// Budget caps what the run may spend, in place of the agent's own
+	// AgentOptions.Budget. AgentTool sets it to what its caller has left.
+	Budget *RunBudget `json:"budget,omitempty"`
 }
 
 // AgentOutput represents the result of agent execution
@@ -294,6 +426,14 @@ type AgentOutput struct {
 	Status     agentstate.RunStatus          `json:"status"`
 	Output     []responses.InputMessageUnion `json:"output"`
 	Interrupts []responses.Interrupt         `json:"interrupts,omitempty"`
+
+	// Guardrail is the guardrail that ended a run with status
+	// guardrail_tripped.
+	Guardrail *responses.GuardrailTrip `json:"guardrail,omitempty"`
+
+	// Spend is what a run under a budget spent against it, its sub-agents'
+	// spend included.
+	Spend *agentstate.BudgetSpend `json:"spend,omitempty"`
 }
 
 // Execute is the single public entry point for running the agent. It
@@ -392,6 +532,12 @@ func (e *Agent) ExecuteLocal(ctx context.Context, in *AgentInput) (*AgentOutput,
 	// Add the incoming message to the run
 	run.AddMessages(ctx, in.Message)
 
+	// Only the message a run starts with is its request: a resumed run's
+	// input is the resolution of its pause.
+	if run.RunState.Request == "" {
+		run.RunState.Request = guardrailText(in.Message.Messages)
+	}
+
 	runId := run.GetRunID()
 
 	if in.SessionID == "" {
@@ -407,18 +553,55 @@ func (e *Agent) ExecuteLocal(ctx context.Context, in *AgentInput) (*AgentOutput,
 	}
 	run.RunState.TraceID = traceid
 
-	// Emit run.created once (durable step: not resent on replay).
+	// Emit run.created once (durable step: not resent on replay). A run
+	// resumed with shared state sends it too, for the client that reconnects
+	// to it.
 	e.durableStep.Do(func() {
 		e.runCreated(ctx, in.StreamID, runId, traceid)
+		if len(run.RunState.SharedState) > 0 {
+			e.stateUpdated(in.StreamID, run.RunState)
+		}
 	})
 
+	info := e.runInfo(in, runId)
+	e.notify(ctx, "run_start", func(h LifecycleHook) error {
+		return h.OnRunStart(ctx, &RunStartEvent{RunInfo: info, Message: in.Message})
+	})
+	if resolutions := interruptResolutions(in.Message); len(resolutions) > 0 {
+		e.notify(ctx, "approval_resolved", func(h LifecycleHook) error {
+			return h.OnApprovalResolved(ctx, &ApprovalResolvedEvent{RunInfo: info, Resolutions: resolutions})
+		})
+	}
+
+	out, err := e.routeRun(ctx, in, run)
+
+	end := &RunEndEvent{RunInfo: info, Output: out}
+	if err != nil {
+		end.Error = err.Error()
+	}
+	e.notify(ctx, "run_end", func(h LifecycleHook) error { return h.OnRunEnd(ctx, end) })
+
+	return out, err
+}
+
+// routeRun hands a run starting in ExecuteLocal to the agent it should run
+// on.
+func (e *Agent) routeRun(ctx context.Context, in *AgentInput, run *history.ConversationRunManager) (*AgentOutput, error) {
+	// Routing happens only here at the top-level entry — never in
+	// ExecuteWithRun, which is also the handoff target's own entry point.
+	//
+	// A run that paused inside a handoff resumes on its target, reached
+	// through the same handoffs, so the target still filters its input and a
+	// delegate still knows whom to hand back to.
+	if target := e.handoffTarget(run.RunState); target != nil {
+		return target.ExecuteWithRun(ctx, in, run)
+	}
+
 	// Sticky handoff: if a prior turn on this thread ended inside a
 	// specialist reached via handoff, resume there instead of
 	// re-entering this root agent. LastAgentName was carried forward by
 	// NewRun and hasn't been overwritten yet (GetMessages does that, and
-	// it runs later inside the loop). Routing happens only here at the
-	// top-level entry — never in ExecuteWithRun, which is also the
-	// handoff target's own entry point.
+	// it runs later inside the loop).
 	if e.stickyHandoff {
 		if target := e.stickyHandoffTarget(run.RunState.LastAgentName); target != nil {
 			return target.ExecuteWithRun(ctx, in, run)
@@ -427,6 +610,7 @@ func (e *Agent) ExecuteLocal(ctx context.Context, in *AgentInput) (*AgentOutput,
 
 	return e.ExecuteWithRun(ctx, in, run)
 }
+
 func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history.ConversationRunManager) (*AgentOutput, error) {
 	publish := e.publisher(in.StreamID)
 
@@ -436,6 +620,7 @@ func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history
 
 	handoffTools := e.PrepareHandoffTools(ctx)
 	tools := append(e.tools, handoffTools...)
+	tools = append(tools, e.PrepareEscalationTool()...)
 
 	// Connect to MCP servers, and list the tools
 	mcpTools, err := e.PrepareMCPTools(ctx, in.RunContext)
@@ -479,6 +664,10 @@ func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history
 	// Load run state from meta (in-memory, no DB call)
 	runId := run.GetRunID()
 
+	if err := e.startBudget(in, run.RunState); err != nil {
+		return &AgentOutput{Status: agentstate.RunStatusError, RunID: runId}, err
+	}
+
 	// Get the prompt
 	instruction := "You are a helpful assistant."
 	if e.instruction != nil {
@@ -508,6 +697,7 @@ func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history
 			DeferredTools: deferredToolInfos,
 			Skills:        skills,
 			SkillHint:     skillHint,
+			Handoff:       run.RunState.ActiveHandoff(e.Name),
 		})
 		if err != nil {
 			return &AgentOutput{Status: agentstate.RunStatusError, RunID: runId}, err
@@ -529,6 +719,7 @@ func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history
 	}
 
 	finalOutput := []responses.InputMessageUnion{}
+	stopRequested := false
 
 	// Main loop - driven by state machine
 	for run.RunState.LoopIteration < e.maxLoops {
@@ -536,6 +727,18 @@ func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history
 		if in.StreamID != "" && e.streamBroker != nil && !run.RunState.IsComplete() {
 			queued, _ := e.streamBroker.DrainMessages(context.Background(), in.StreamID)
 			run.AddMessagesToQueue(ctx, queued)
+
+			if len(queued) > 0 {
+				info := e.runInfo(in, runId)
+				e.notify(ctx, "message_queued", func(h LifecycleHook) error {
+					return h.OnMessageQueued(ctx, &MessageQueuedEvent{RunInfo: info, Messages: queued})
+				})
+				if resolutions := interruptResolutions(queued...); len(resolutions) > 0 {
+					e.notify(ctx, "approval_resolved", func(h LifecycleHook) error {
+						return h.OnApprovalResolved(ctx, &ApprovalResolvedEvent{RunInfo: info, Resolutions: resolutions})
+					})
+				}
+			}
 		}
 
 		// Honor an external stop signal at iteration boundaries.
@@ -616,6 +819,11 @@ func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history
 				finalOutput = append(finalOutput, cancelMsg)
 				run.RunState.ToolsAwaitingApproval = nil
 				run.RunState.TransitionToComplete()
+				stopRequested = true
+
+				e.notify(ctx, "stop_requested", func(h LifecycleHook) error {
+					return h.OnStopRequested(ctx, &StopRequestedEvent{RunInfo: e.runInfo(in, runId)})
+				})
 			}
 		}
 
@@ -627,10 +835,44 @@ func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history
 				return &AgentOutput{Status: agentstate.RunStatusError, RunID: runId}, err
 			}
 
+			convMessages, err = e.filterHandoffInput(ctx, run.RunState, convMessages)
+			if err != nil {
+				return &AgentOutput{Status: agentstate.RunStatusError, RunID: runId}, err
+			}
+
+			if summary := run.TakeNewSummary(); summary != nil {
+				e.notify(ctx, "summarized", func(h LifecycleHook) error {
+					return h.OnSummarized(ctx, &SummarizedEvent{
+						RunInfo:             e.runInfo(in, runId),
+						SummaryID:           summary.SummaryID,
+						LastSummarizedRunID: summary.LastSummarizedRunID,
+						Summary:             summary.Summary,
+						MessagesKept:        len(summary.MessagesToKeep),
+					})
+				})
+			}
+
 			if reminder := budgetReminder(e.maxLoops - run.RunState.LoopIteration); reminder != nil {
 				convMessages = append(convMessages, *reminder)
 			}
 
+			// A run that has spent its budget gets one last call to wrap up:
+			// no tools, and a note saying why. Its answer ends the run.
+			wrappingUp := false
+			if run.RunState.Budget != nil {
+				if run.RunState.Budget.Exceeded == "" {
+					now, err := e.budgetNow(in.Budget)
+					if err != nil {
+						return &AgentOutput{Status: agentstate.RunStatusError, RunID: runId}, err
+					}
+					run.RunState.Budget.Exceeded = in.Budget.exceeded(run.RunState.Budget, now)
+				}
+				if run.RunState.Budget.Exceeded != "" {
+					wrappingUp = true
+					convMessages = append(convMessages, budgetWrapUpMessage(run.RunState.Budget.Exceeded))
+				}
+			}
+
 			var activatedDeferredToolsDef []responses.ToolUnion
 			if str, ok := run.State["activated_deferred_tools"]; ok {
 				activatedToolNames := strings.Split(str, ",")
@@ -649,12 +891,52 @@ func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history
 				Tools:      append(toolDefs, activatedDeferredToolsDef...),
 				Parameters: parameters,
 			}
+			if wrappingUp {
+				request.Tools = nil
+			}
+
+			// Input guardrails check the run's input once, on the call that
+			// opens the run, and run alongside it. Locally a trip cancels the
+			// call; under a durable runtime the call is a step that runs to
+			// the end, and a trip drops its answer.
+			callCtx := ctx
+			awaitInputGuardrails := func() (*responses.GuardrailTrip, error) { return nil, nil }
+			if len(e.inputGuardrails) > 0 && run.RunState.LoopIteration == 0 {
+				guardrailInput := &GuardrailInput{
+					AgentName:  e.Name,
+					Namespace:  in.Namespace,
+					ThreadID:   in.ThreadID,
+					RunID:      runId,
+					RunContext: in.RunContext,
+					Messages:   in.Message.Messages,
+				}
+
+				if e.durable() {
+					awaitInputGuardrails = e.startDurableInputGuardrails(ctx, guardrailInput)
+				} else {
+					callCtx, awaitInputGuardrails = e.startInputGuardrails(ctx, guardrailInput)
+				}
+			}
+
+			// Locally the call is cut off at the budget's deadline. A durable
+			// runtime's call is a step of its own, which the deadline is only
+			// checked between.
+			cancelDeadline := func() {}
+			if !e.durable() {
+				callCtx, cancelDeadline = withDeadline(callCtx, run.RunState.Budget, wrappingUp)
+			}
+
+			// The run's own model, or the stronger one it escalated to.
+			model := e.turnModel(run.RunState)
 
 			// The hooks see what the call is and what the run has spent, not
 			// the prompt — see ModelCall. A hook that answers for the model
 			// (an exhausted budget, say) supplies the reply and the provider
 			// is never contacted.
-			resp, err := RunWithModelCallHooks(ctx, e.modelCallHooks, &ModelCall{
+			// answeredBy is the provider family of the model that answered,
+			// which the reply is recorded under. A hook's answer has none.
+			var answeredBy llm.ProviderName
+			resp, err := RunWithModelCallHooks(callCtx, e.modelCallHooks, &ModelCall{
 				AgentName:     e.Name,
 				Namespace:     in.Namespace,
 				ThreadID:      in.ThreadID,
@@ -674,9 +956,32 @@ func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history
 				// the waiting rather than the work.
 				streamCtx, cancel := StopCancelContext(callCtx, StopWatcherFrom(e.streamBroker), in.StreamID)
 				defer cancel()
-				return e.llm.NewStreamingResponses(streamCtx, request, publish)
+				resp, family, err := e.callWithFallbacks(streamCtx, run, model, request, publish)
+				answeredBy = family
+				return resp, err
 			})
+			cancelDeadline()
+
+			// A tripped guardrail outranks whatever became of the call it
+			// cancelled.
+			trip, guardrailErr := awaitInputGuardrails()
+			if guardrailErr != nil {
+				return &AgentOutput{Status: agentstate.RunStatusError, RunID: runId}, guardrailErr
+			}
+			if trip != nil {
+				e.cancelBackgroundResponse(ctx, run)
+				return e.guardrailTripped(ctx, in, runId, run, trip)
+			}
+
 			if errors.Is(err, ErrModelCallStopped) {
+				e.cancelBackgroundResponse(ctx, run)
+
+				// Cut off by the budget's deadline rather than stopped by the
+				// user: the next call is the wrap-up.
+				if ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+					run.RunState.Budget.Exceeded = "time"
+				}
+
 				// Not a failure: the user stopped the run while the model was
 				// still talking. Go back to the top of the loop, where the stop
 				// check ends the run the same way it would have between
@@ -691,9 +996,15 @@ func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history
 			if err != nil {
 				return &AgentOutput{Status: agentstate.RunStatusError, RunID: runId}, err
 			}
+			run.RunState.BackgroundResponse = nil
 
 			// Track the LLM's usage
 			run.TrackUsage(resp.Usage)
+			in.Budget.trackUsage(run.RunState.Budget, resp.Model, resp.Usage)
+
+			if wrappingUp {
+				resp.Output = withoutToolCalls(resp.Output)
+			}
 
 			// Convert output to input messages and add to history
 			inputMsgs := []responses.InputMessageUnion{}
@@ -709,9 +1020,31 @@ func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history
 			// AlreadyMeasured: TrackUsage above counted this reply against the
 			// context window as part of the call's reported total, so
 			// estimating it here would count it twice.
-			run.AddMessages(ctx, messages.New(e.Name, inputMsgs), history.AlreadyMeasured())
+			reply := messages.New(e.Name, inputMsgs)
+			reply.Provider = string(answeredBy)
+			run.AddMessages(ctx, reply, history.AlreadyMeasured())
 			finalOutput = append(finalOutput, inputMsgs...)
 
+			// The wrap-up answer is checked like any other final answer, but
+			// the budget leaves no turn to repair or revise it.
+			if wrappingUp {
+				if verr := e.outputValidator.validate(resp.Output); verr != nil {
+					verr.Repairs = run.RunState.OutputRepairs
+					return &AgentOutput{Status: agentstate.RunStatusError, RunID: runId, Output: finalOutput}, verr
+				}
+				if _, err := e.reflect(ctx, in, runId, run, inputMsgs, true); err != nil {
+					return &AgentOutput{Status: agentstate.RunStatusError, RunID: runId}, err
+				}
+				trip, err := e.checkAnswer(ctx, in, runId, inputMsgs)
+				if err != nil {
+					return &AgentOutput{Status: agentstate.RunStatusError, RunID: runId}, err
+				}
+				if trip != nil {
+					return e.guardrailTripped(ctx, in, runId, run, trip)
+				}
+				return e.budgetExceeded(ctx, in, runId, run, finalOutput)
+			}
+
 			// Extract tool calls
 			toolCalls := []responses.FunctionCallMessage{}
 			for _, msg := range resp.Output {
@@ -726,8 +1059,47 @@ func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history
 				// Complete before any tool runs.
 				run.RunState.TransitionToComplete()
 			} else if len(toolCalls) == 0 {
-				// No tools = done
-				run.RunState.TransitionToComplete()
+				// No tools = done, once the answer matches the output schema.
+				// One that does not goes back to the model with the reason,
+				// as a turn of its own, until the repairs run out.
+				verr := e.outputValidator.validate(resp.Output)
+
+				// A stronger model gets a repair turn of its own, whatever
+				// the repairs already spent.
+				escalated := verr != nil && e.escalationPolicy != nil && e.escalationPolicy.OnInvalidOutput &&
+					e.escalate(ctx, run.RunState, EscalationReasonInvalidOutput)
+
+				switch {
+				case verr == nil:
+					// A draft the critic sends back is revised in a turn of
+					// its own; only the answer it lets through is checked.
+					revising, err := e.reflect(ctx, in, runId, run, inputMsgs, false)
+					if err != nil {
+						return &AgentOutput{Status: agentstate.RunStatusError, RunID: runId}, err
+					}
+					if revising {
+						break
+					}
+
+					trip, err := e.checkAnswer(ctx, in, runId, inputMsgs)
+					if err != nil {
+						return &AgentOutput{Status: agentstate.RunStatusError, RunID: runId}, err
+					}
+					if trip != nil {
+						return e.guardrailTripped(ctx, in, runId, run, trip)
+					}
+					run.RunState.TransitionToComplete()
+
+				case escalated || run.RunState.OutputRepairs < e.maxOutputRepairs:
+					slog.InfoContext(ctx, "structured output rejected, asking for a repair", slog.String("agent", e.Name), slog.Any("error", verr.Err))
+					run.AddMessages(ctx, messages.New(in.Message.SenderID, []responses.InputMessageUnion{outputRepairMessage(verr)}))
+					run.RunState.OutputRepairs++
+					run.RunState.TransitionToLLM()
+
+				default:
+					verr.Repairs = run.RunState.OutputRepairs
+					return &AgentOutput{Status: agentstate.RunStatusError, RunID: runId, Output: finalOutput}, verr
+				}
 			} else {
 				// Partition tools by approval requirement
 				needsApproval, immediate := partitionByApproval(ctx, tools, toolCalls)
@@ -788,10 +1160,48 @@ func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history
 
 					for _, handoff := range e.handoffs {
 						if handoff.Name == param["agent_name"] {
+							args, err := handoffArguments(handoff, param)
+							if err != nil {
+								toolResults[i] = toolResponse(toolCall, "Failed to transfer to agent. The arguments do not match its schema: "+err.Error())
+								break
+							}
 							toolResults[i] = toolResponse(toolCall, "Transferred to agent")
 
+							reason, _ := param["reason"].(string)
+							target := handoff.Agent
+							frame := agentstate.HandoffFrame{
+								Name:      handoff.Name,
+								Source:    e.Name,
+								Target:    handoff.Name,
+								Reason:    reason,
+								Arguments: args,
+								Return:    handoff.Return,
+							}
+							if agent, ok := handoff.Agent.(*Agent); ok {
+								frame.Target = agent.Name
+								target = agent.enteredVia(handoff, e)
+							}
+							// Only the last transfer of a turn is followed, so
+							// only it is recorded.
+							if handoffFn != nil {
+								run.RunState.Handoffs[len(run.RunState.Handoffs)-1] = frame
+							} else {
+								run.RunState.Handoffs = append(run.RunState.Handoffs, frame)
+							}
+
+							// Announced as it is taken, so a transfer a later
+							// one in the turn replaced is never reported.
+							event := &HandoffEvent{
+								RunInfo: e.runInfo(in, runId),
+								Source:  frame.Source,
+								Target:  frame.Target,
+								Reason:  reason,
+							}
 							handoffFn = func() (*AgentOutput, error) {
-								return handoff.Agent.ExecuteWithRun(ctx, in, run)
+								e.notify(ctx, "handoff", func(h LifecycleHook) error {
+									return h.OnHandoff(ctx, event)
+								})
+								return target.ExecuteWithRun(ctx, in, run)
 							}
 							break
 						}
@@ -800,6 +1210,8 @@ func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history
 					if toolResults[i] == nil {
 						toolResults[i] = toolResponse(toolCall, "Failed to transfer to agent. Target agent not found")
 					}
+				} else if toolCall.Name == escalationToolName {
+					toolResults[i] = toolResponse(toolCall, e.escalationToolResult(ctx, run.RunState))
 				} else {
 					// Regular tool — queue for parallel execution
 					tool := findTool(ctx, tools, toolCall.Name)
@@ -811,6 +1223,14 @@ func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history
 
 					_, resuming := run.RunState.PausedToolCalls[toolCall.CallID]
 
+					// A resumed call was counted when it was first made.
+					if !resuming {
+						if limit, ok := in.Budget.allowToolCall(run.RunState.Budget, toolCall.Name); !ok {
+							toolResults[i] = toolResponse(toolCall, toolCallLimitReached(toolCall.Name, limit))
+							continue
+						}
+					}
+
 					var resumeMessages []responses.InputMessageUnion
 					if resuming {
 						approvedInner, rejectedInner := run.RunState.CollectNestedApprovalsForParent(toolCall.CallID)
@@ -840,6 +1260,11 @@ func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history
 						}
 					}
 
+					now, err := e.budgetNow(in.Budget)
+					if err != nil {
+						return &AgentOutput{Status: agentstate.RunStatusError, RunID: runId}, err
+					}
+
 					executableToolCalls = append(executableToolCalls, ExecutableToolCall{
 						Index:    i,
 						ToolName: toolCall.Name,
@@ -855,6 +1280,7 @@ func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history
 							State:               run.State,
 							ShouldResume:        resuming,
 							ResumeMessages:      resumeMessages,
+							Budget:              in.Budget.remaining(run.RunState.Budget, now),
 							Progress:            e.progressReporter(in.StreamID, toolCall.CallID, toolCall.Name),
 						},
 					})
@@ -868,12 +1294,30 @@ func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history
 			if len(executableToolCalls) > 0 {
 				results := e.toolExecutor.ExecuteAll(ctx, executableToolCalls)
 
+				// Which calls failed, in order, toward the escalation
+				// policy's AfterToolErrors.
+				failed := make([]bool, len(executableToolCalls))
+				shared := false
+
 				for j, pe := range executableToolCalls {
 					result := results[j]
 					switch {
 					case result.Err == nil && result.Response != nil:
 						toolResults[pe.Index] = result.Response
 
+						// A sub-agent spends the run's budget too.
+						if run.RunState.Budget != nil {
+							run.RunState.Budget.Spent.Add(result.Response.Spend)
+						}
+
+						if result.Response.SharedState != nil {
+							if run.RunState.SharedState == nil {
+								run.RunState.SharedState = map[string]any{}
+							}
+							maps.Copy(run.RunState.SharedState, result.Response.SharedState)
+							shared = true
+						}
+
 						// If the tool response has interrupts process it
 						if len(result.Response.Interrupts) > 0 {
 							run.ProcessInterrupts(*pe.ToolCall.FunctionCallMessage, result.Response.Interrupts)
@@ -903,14 +1347,24 @@ func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history
 						// Tool error — report to LLM as error result
 						slog.ErrorContext(ctx, "tool execution failed", slog.String("tool_name", pe.ToolCall.Name), slog.Any("error", result.Err))
 						toolResults[pe.Index] = toolResponse(*pe.ToolCall.FunctionCallMessage, fmt.Sprintf("Tool execution failed: %v", result.Err))
+						failed[j] = true
 
 					default:
 						// No response and no error — still needs an output to
 						// keep the call/result pairing intact.
 						slog.ErrorContext(ctx, "tool returned no response", slog.String("tool_name", pe.ToolCall.Name))
 						toolResults[pe.Index] = toolResponse(*pe.ToolCall.FunctionCallMessage, "Tool execution failed: tool returned no response")
+						failed[j] = true
 					}
 				}
+
+				e.trackToolErrors(ctx, run.RunState, failed)
+
+				if shared {
+					e.durableStep.Do(func() {
+						e.stateUpdated(in.StreamID, run.RunState)
+					})
+				}
 			}
 
 			// Process all results in original order
@@ -970,6 +1424,13 @@ func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history
 				e.runPaused(ctx, in.StreamID, runId, run.RunState)
 			})
 
+			e.notify(ctx, "approval_requested", func(h LifecycleHook) error {
+				return h.OnApprovalRequested(ctx, &ApprovalRequestedEvent{
+					RunInfo:    e.runInfo(in, runId),
+					Interrupts: run.RunState.PendingInterrupts(),
+				})
+			})
+
 			return &AgentOutput{
 				RunID:      runId,
 				Status:     agentstate.RunStatusPaused,
@@ -977,6 +1438,19 @@ func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history
 			}, nil
 
 		case agentstate.StepComplete:
+			// A delegate that has finished hands the run back to the agent
+			// that delegated, which carries on from its answer.
+			if frame := run.RunState.ActiveHandoff(e.Name); frame != nil && frame.Return && !stopRequested {
+				if e.caller != nil {
+					run.RunState.Handoffs = run.RunState.Handoffs[:len(run.RunState.Handoffs)-1]
+					run.AddMessages(ctx, messages.New(in.Message.SenderID, []responses.InputMessageUnion{handoffReturnMessage(e.Name)}))
+					run.RunState.TransitionToLLM()
+					return e.caller.ExecuteWithRun(ctx, in, run)
+				}
+				slog.WarnContext(ctx, "delegated run finished with no caller to return to; ending it here",
+					slog.String("agent", e.Name), slog.String("source", frame.Source))
+			}
+
 			err = run.SaveMessages(ctx)
 			if err != nil {
 				return &AgentOutput{Status: agentstate.RunStatusError, RunID: runId}, err
@@ -987,11 +1461,15 @@ func (e *Agent) ExecuteWithRun(ctx context.Context, in *AgentInput, run *history
 				e.runCompleted(ctx, in.StreamID, runId, run.RunState)
 			})
 
-			return &AgentOutput{
+			out := &AgentOutput{
 				RunID:  runId,
 				Status: agentstate.RunStatusCompleted,
 				Output: finalOutput,
-			}, nil
+			}
+			if run.RunState.Budget != nil {
+				out.Spend = run.RunState.Budget.Spent.Clone()
+			}
+			return out, nil
 		}
 	}
 
@@ -1063,6 +1541,15 @@ func (e *Agent) runCreated(ctx context.Context, streamID, runId, traceId string)
 	})
 }
 
+// stateUpdated sends the client the state the run shares with it, whole.
+func (e *Agent) stateUpdated(streamID string, runState *agentstate.RunState) {
+	e.publisher(streamID)(&responses.ResponseChunk{
+		OfStateUpdated: &responses.ChunkState[constants.ChunkTypeStateUpdated]{
+			State: maps.Clone(runState.SharedState),
+		},
+	})
+}
+
 func (e *Agent) runPaused(ctx context.Context, streamID, runId string, runState *agentstate.RunState) {
 	e.publisher(streamID)(&responses.ResponseChunk{
 		OfRunPaused: &responses.ChunkRun[constants.ChunkTypeRunPaused]{
@@ -1171,6 +1658,31 @@ func toolResponse(toolCall responses.FunctionCallMessage, textOutput string) *To
 	}
 }
 
+// handoffTarget follows the run's handoffs from this agent to the one it is
+// inside of, or returns nil when it is inside none — or one this agent no
+// longer has, in which case it runs here as it did before.
+func (e *Agent) handoffTarget(runState *agentstate.RunState) *Agent {
+	if runState.IsComplete() || len(runState.Handoffs) == 0 || runState.Handoffs[0].Source != e.Name {
+		return nil
+	}
+
+	current := e
+	for _, frame := range runState.Handoffs {
+		var next *Agent
+		for _, h := range current.handoffs {
+			if target, ok := h.Agent.(*Agent); ok && h.Name == frame.Name {
+				next = target.enteredVia(h, current)
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		current = next
+	}
+	return current
+}
+
 // stickyHandoffTarget resolves the thread's last-active agent name to a
 // concrete agent reachable from this one through the handoff graph.
 // Returns nil when the name is empty, is this agent itself, or isn't a
@@ -21,6 +21,7 @@ import (
 	"github.com/hastekit/agent-sdk-go/pkg/agents/streambroker"
 	agenttools "github.com/hastekit/agent-sdk-go/pkg/agents/tools"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/llmtest"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
 	"github.com/hastekit/agent-sdk-go/pkg/utils"
 )
@@ -154,14 +155,35 @@ func approvalMessage(approved, rejected []string) history.Message {
 	}})
 }
 
-func newScriptedAgent(name string, llm agents.LLM, hist *history.CommonConversationManager, broker agents.StreamBroker, tools []agents.Tool, handoffs []*agents.Handoff) *agents.Agent {
-	return agents.NewAgent(&agents.AgentOptions{
+// agentOption sets an option newScriptedAgent has no parameter for.
+type agentOption func(*agents.AgentOptions)
+
+// scriptedBy has the agent call fake through its real provider path —
+// streamed chunks folded by the Accumulator — instead of a scripted
+// agents.LLM. Pass a nil llm alongside it.
+func scriptedBy(fake *llmtest.Provider) agentOption {
+	return func(o *agents.AgentOptions) {
+		o.LLM = fake
+	}
+}
+
+func newScriptedAgent(name string, llm agents.LLM, hist *history.CommonConversationManager, broker agents.StreamBroker, tools []agents.Tool, handoffs []*agents.Handoff, opts ...agentOption) *agents.Agent {
+	options := &agents.AgentOptions{
 		Name:         name,
 		History:      hist,
 		StreamBroker: broker,
 		Tools:        tools,
 		Handoffs:     handoffs,
-	}).WithLLM(llm)
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	agent := agents.NewAgent(options)
+	if llm == nil {
+		return agent
+	}
+	return agent.WithLLM(llm)
 }
 
 func runAgent(t *testing.T, agent *agents.Agent, in *agents.AgentInput) *agents.AgentOutput {
@@ -1491,3 +1513,32 @@ func TestAgentLoop_FormElicitationRoundTrip(t *testing.T) {
 	assert.Equal(t, "Ada Lovelace", name, "the submitted form must reach the tool")
 	assert.Contains(t, messagesText(out.Output), "booked for Ada Lovelace")
 }
+
+// The llmtest fake goes through the agent's real provider path — streamed
+// chunks folded by the Accumulator — rather than handing back a finished
+// response, so this covers the loop end to end.
+func TestAgentLoop_FakeProviderToolCallThenAnswer(t *testing.T) {
+	fake := llmtest.New().
+		Respond(llmtest.ToolCallWithID("call_weather", "weather", `{"city":"Paris"}`)).
+		RespondText("It is sunny in Paris.")
+	weather := newFakeTool("weather", false, "sunny, 24C")
+	agent := agents.NewAgent(&agents.AgentOptions{
+		Name:  "main",
+		LLM:   fake,
+		Tools: []agents.Tool{weather},
+	})
+
+	out := runAgent(t, agent, &agents.AgentInput{
+		Namespace: "test",
+		ThreadID:  "thread-fake-provider",
+		Message:   userMessage("weather in Paris?"),
+	})
+
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Equal(t, 1, weather.callCount())
+	assert.Equal(t, 0, fake.Remaining())
+	assert.Contains(t, messagesText(out.Output), "It is sunny in Paris.")
+
+	// The second model call sees the tool's answer.
+	assert.Contains(t, messagesText(fake.Request(1).Input.OfInputMessageList), "sunny, 24C")
+}
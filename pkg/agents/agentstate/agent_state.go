@@ -2,7 +2,9 @@ package agentstate
 
 import (
 	"encoding/json"
+	"maps"
 	"sort"
+	"time"
 
 	"github.com/bytedance/sonic"
 	"github.com/hastekit/agent-sdk-go/pkg/agents/messages"
@@ -27,6 +29,14 @@ const (
 	RunStatusPaused     RunStatus = "paused"
 	RunStatusCompleted  RunStatus = "completed"
 	RunStatusError      RunStatus = "error"
+
+	// RunStatusGuardrailTripped ends a run an input or output guardrail
+	// blocked.
+	RunStatusGuardrailTripped RunStatus = "guardrail_tripped"
+
+	// RunStatusBudgetExceeded ends a run that spent its RunBudget. The run
+	// was given one last turn to wrap up, so its output carries an answer.
+	RunStatusBudgetExceeded RunStatus = "budget_exceeded"
 )
 
 // RunState encapsulates the execution state of an agent run
@@ -78,6 +88,178 @@ type RunState struct {
 
 	// LastAgentName is the name of the agent that was responding to the user last.
 	LastAgentName string `json:"last_agent_name,omitempty"`
+
+	// BackgroundResponse is the response the provider is generating in the
+	// background for the model call in flight. It is persisted as soon as
+	// the provider reports it, so a run resumed after a restart reattaches
+	// to the response instead of asking the model again.
+	BackgroundResponse *BackgroundResponse `json:"background_response,omitempty"`
+
+	// OutputRepairs counts the turns this run spent asking the model to fix
+	// an answer that did not match the agent's output schema.
+	OutputRepairs int `json:"output_repairs,omitempty"`
+
+	// Revisions counts the drafts this run's critic sent back for revision.
+	Revisions int `json:"revisions,omitempty"`
+
+	// Request is the text of the user message the run was started with. A
+	// resumed run's own input is the resolution of its pause, so the request
+	// is kept here for the critic.
+	Request string `json:"request,omitempty"`
+
+	// Budget is what the run has spent against its budget, for a run that
+	// has one. A handoff target continues the same run, and so the same
+	// budget.
+	Budget *BudgetState `json:"budget,omitempty"`
+
+	// Escalation records that the run moved to its agent's stronger model,
+	// and why. Later turns read it rather than deciding again, so a replayed
+	// run calls the same model the original did.
+	Escalation *Escalation `json:"escalation,omitempty"`
+
+	// ConsecutiveToolErrors counts the tool calls in a row that failed, for
+	// an escalation policy's AfterToolErrors. A call that succeeds resets it.
+	ConsecutiveToolErrors int `json:"consecutive_tool_errors,omitempty"`
+
+	// Handoffs are the handoffs the run is inside of, innermost last. A
+	// handoff that returns to its caller is popped when its target
+	// finishes; a one-way one stays until the run ends.
+	Handoffs []HandoffFrame `json:"handoffs,omitempty"`
+
+	// SharedState is the state the run shares with its client, as its tools
+	// last set it. Nil when they have shared none.
+	SharedState map[string]any `json:"shared_state,omitempty"`
+}
+
+// HandoffFrame is one transfer_to_agent call the run followed.
+type HandoffFrame struct {
+	// Name is the handoff's, which is usually but not always its target's.
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Reason string `json:"reason,omitempty"`
+	// Arguments are the payload the model passed, already checked against
+	// the handoff's schema.
+	Arguments map[string]any `json:"arguments,omitempty"`
+	// Return hands the run back to Source when Target finishes.
+	Return bool `json:"return,omitempty"`
+}
+
+// ActiveHandoff is the innermost handoff the run is inside of, if its target
+// is agentName.
+func (s *RunState) ActiveHandoff(agentName string) *HandoffFrame {
+	if len(s.Handoffs) == 0 {
+		return nil
+	}
+	frame := &s.Handoffs[len(s.Handoffs)-1]
+	if frame.Target != agentName {
+		return nil
+	}
+	return frame
+}
+
+// Escalation is a run's move to a stronger model.
+type Escalation struct {
+	// AgentName is the agent that escalated. A handoff target continues the
+	// run but not the escalation, which was its caller's policy.
+	AgentName string `json:"agent_name"`
+	Reason    string `json:"reason"`
+	// LoopIteration is the turn that decided it; the next call is the first
+	// made on the stronger model.
+	LoopIteration int `json:"loop_iteration"`
+}
+
+// BudgetState tracks a run against its budget.
+type BudgetState struct {
+	// Limits are the limits the run started under. A resumed run is held to
+	// them, whatever budget it is resumed with.
+	Limits *BudgetLimits `json:"limits,omitempty"`
+	Spent  BudgetSpend   `json:"spent"`
+	// Deadline is when the budget's wall-clock allowance runs out, fixed
+	// when the run starts. Zero when there is no such allowance.
+	Deadline time.Time `json:"deadline"`
+	// Exceeded names the allowance that ran out, once one has. The run's
+	// next model call is its wrap-up turn.
+	Exceeded string `json:"exceeded,omitempty"`
+}
+
+// BudgetLimits are a budget's limits, as agents.RunBudget sets them.
+type BudgetLimits struct {
+	MaxInputTokens  int                     `json:"max_input_tokens,omitempty"`
+	MaxOutputTokens int                     `json:"max_output_tokens,omitempty"`
+	MaxCost         float64                 `json:"max_cost,omitempty"`
+	Pricing         map[string]ModelPricing `json:"pricing,omitempty"`
+	MaxDuration     time.Duration           `json:"max_duration,omitempty"`
+	MaxToolCalls    map[string]int          `json:"max_tool_calls,omitempty"`
+}
+
+// ModelPricing is what a model charges per million tokens.
+type ModelPricing struct {
+	InputPerMillion float64 `json:"input_per_million"`
+	// CachedInputPerMillion prices input read from the provider's prompt
+	// cache. Zero charges it at InputPerMillion.
+	CachedInputPerMillion float64 `json:"cached_input_per_million,omitempty"`
+	OutputPerMillion      float64 `json:"output_per_million"`
+}
+
+// Cost returns what usage costs at these prices.
+func (p *ModelPricing) Cost(usage responses.Usage) float64 {
+	if p == nil {
+		return 0
+	}
+
+	cachedPrice := p.CachedInputPerMillion
+	if cachedPrice == 0 {
+		cachedPrice = p.InputPerMillion
+	}
+
+	cached := usage.InputTokensDetails.CachedTokens
+	return (float64(usage.InputTokens-cached)*p.InputPerMillion +
+		float64(cached)*cachedPrice +
+		float64(usage.OutputTokens)*p.OutputPerMillion) / 1_000_000
+}
+
+// BudgetSpend is what has been spent against a budget. A sub-agent reports
+// its own, which its caller adds to the run's.
+type BudgetSpend struct {
+	InputTokens  int     `json:"input_tokens,omitempty"`
+	OutputTokens int     `json:"output_tokens,omitempty"`
+	Cost         float64 `json:"cost,omitempty"`
+	// ToolCalls counts the calls made to each tool, by name.
+	ToolCalls map[string]int `json:"tool_calls,omitempty"`
+}
+
+// Add adds other's spend to s.
+func (s *BudgetSpend) Add(other *BudgetSpend) {
+	if other == nil {
+		return
+	}
+
+	s.InputTokens += other.InputTokens
+	s.OutputTokens += other.OutputTokens
+	s.Cost += other.Cost
+	for name, n := range other.ToolCalls {
+		if s.ToolCalls == nil {
+			s.ToolCalls = map[string]int{}
+		}
+		s.ToolCalls[name] += n
+	}
+}
+
+// Clone returns a copy of s that shares nothing with it.
+func (s BudgetSpend) Clone() *BudgetSpend {
+	s.ToolCalls = maps.Clone(s.ToolCalls)
+	return &s
+}
+
+// BackgroundResponse locates a background response and how much of its
+// event stream the run has already published.
+type BackgroundResponse struct {
+	ResponseID string `json:"response_id"`
+	// Model is the model the response was started with; reattaching to or
+	// cancelling the response is routed by it.
+	Model          string `json:"model,omitempty"`
+	SequenceNumber int    `json:"sequence_number"`
 }
 
 // PendingInterrupts projects the current PendingToolCalls into the unified
@@ -247,6 +429,42 @@ func (s *RunState) ToMeta() map[string]any {
 		runStateMap["last_agent_name"] = s.LastAgentName
 	}
 
+	if s.BackgroundResponse != nil {
+		runStateMap["background_response"] = s.BackgroundResponse
+	}
+
+	if s.OutputRepairs > 0 {
+		runStateMap["output_repairs"] = s.OutputRepairs
+	}
+
+	if s.Revisions > 0 {
+		runStateMap["revisions"] = s.Revisions
+	}
+
+	if s.Request != "" {
+		runStateMap["request"] = s.Request
+	}
+
+	if s.Budget != nil {
+		runStateMap["budget"] = s.Budget
+	}
+
+	if s.Escalation != nil {
+		runStateMap["escalation"] = s.Escalation
+	}
+
+	if s.ConsecutiveToolErrors > 0 {
+		runStateMap["consecutive_tool_errors"] = s.ConsecutiveToolErrors
+	}
+
+	if len(s.Handoffs) > 0 {
+		runStateMap["handoffs"] = s.Handoffs
+	}
+
+	if len(s.SharedState) > 0 {
+		runStateMap["shared_state"] = s.SharedState
+	}
+
 	return map[string]any{
 		"run_state": runStateMap,
 	}
@@ -321,6 +539,22 @@ func LoadRunStateFromMeta(meta map[string]any) *RunState {
 		state.PendingContextTokens = pending
 	}
 
+	if repairs, ok := metaInt(runStateData["output_repairs"]); ok {
+		state.OutputRepairs = repairs
+	}
+
+	if revisions, ok := metaInt(runStateData["revisions"]); ok {
+		state.Revisions = revisions
+	}
+
+	if request, ok := runStateData["request"].(string); ok {
+		state.Request = request
+	}
+
+	if toolErrors, ok := metaInt(runStateData["consecutive_tool_errors"]); ok {
+		state.ConsecutiveToolErrors = toolErrors
+	}
+
 	if usageData, ok := runStateData["usage"]; ok {
 		// Parse usage from meta using JSON marshaling for proper type
 		// conversion. Marshal the value as-is rather than asserting it to
@@ -420,6 +654,38 @@ func LoadRunStateFromMeta(meta map[string]any) *RunState {
 		state.LastAgentName = lastAgentName
 	}
 
+	if backgroundResponse, ok := runStateData["background_response"]; ok {
+		backgroundResponseBytes, err := sonic.Marshal(backgroundResponse)
+		if err == nil {
+			sonic.Unmarshal(backgroundResponseBytes, &state.BackgroundResponse)
+		}
+	}
+
+	if budget, ok := runStateData["budget"]; ok {
+		budgetBytes, err := sonic.Marshal(budget)
+		if err == nil {
+			sonic.Unmarshal(budgetBytes, &state.Budget)
+		}
+	}
+
+	if escalation, ok := runStateData["escalation"]; ok {
+		escalationBytes, err := sonic.Marshal(escalation)
+		if err == nil {
+			sonic.Unmarshal(escalationBytes, &state.Escalation)
+		}
+	}
+
+	if handoffs, ok := runStateData["handoffs"]; ok {
+		handoffsBytes, err := sonic.Marshal(handoffs)
+		if err == nil {
+			sonic.Unmarshal(handoffsBytes, &state.Handoffs)
+		}
+	}
+
+	if shared, ok := runStateData["shared_state"].(map[string]any); ok {
+		state.SharedState = shared
+	}
+
 	return state
 }
 
@@ -163,3 +163,54 @@ func TestRunStateRoundTripWithoutJSONHop(t *testing.T) {
 		t.Errorf("Usage = %+v, want input=900 total=1000", out.Usage)
 	}
 }
+
+// TestBackgroundResponseRoundTrip: a run checkpointed mid-call must come back
+// knowing which background response to reattach to, whichever way the meta
+// was stored.
+func TestBackgroundResponseRoundTrip(t *testing.T) {
+	in := NewRunState()
+	in.BackgroundResponse = &BackgroundResponse{ResponseID: "resp_123", Model: "OpenAI/o3-deep-research", SequenceNumber: 4}
+
+	raw, err := sonic.Marshal(in.ToMeta())
+	if err != nil {
+		t.Fatalf("marshal meta: %v", err)
+	}
+	var meta map[string]any
+	if err := sonic.Unmarshal(raw, &meta); err != nil {
+		t.Fatalf("unmarshal meta: %v", err)
+	}
+
+	for name, m := range map[string]map[string]any{"json": meta, "in process": in.ToMeta()} {
+		out := LoadRunStateFromMeta(m)
+		if out.BackgroundResponse == nil || *out.BackgroundResponse != *in.BackgroundResponse {
+			t.Errorf("%s: BackgroundResponse = %+v, want %+v", name, out.BackgroundResponse, in.BackgroundResponse)
+		}
+	}
+
+	if out := LoadRunStateFromMeta(NewRunState().ToMeta()); out.BackgroundResponse != nil {
+		t.Errorf("a run without a background response loaded one: %+v", out.BackgroundResponse)
+	}
+}
+
+// TestSharedStateRoundTrip: a run resumed by a reconnecting client re-sends
+// the state it shared, whichever way the meta was stored.
+func TestSharedStateRoundTrip(t *testing.T) {
+	in := NewRunState()
+	in.SharedState = map[string]any{"plan": map[string]any{"goal": "ship it"}}
+
+	raw, err := sonic.Marshal(in.ToMeta())
+	if err != nil {
+		t.Fatalf("marshal meta: %v", err)
+	}
+	var meta map[string]any
+	if err := sonic.Unmarshal(raw, &meta); err != nil {
+		t.Fatalf("unmarshal meta: %v", err)
+	}
+
+	for name, m := range map[string]map[string]any{"json": meta, "in process": in.ToMeta()} {
+		out := LoadRunStateFromMeta(m)
+		if goal := out.SharedState["plan"].(map[string]any)["goal"]; goal != "ship it" {
+			t.Errorf("%s: SharedState = %+v, want the plan back", name, out.SharedState)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/history"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+)
+
+// backgroundCheckpointInterval bounds how often a background response's
+// progress is saved between the ends of its output items.
+const backgroundCheckpointInterval = time.Second
+
+// callLLM makes the turn's model call.
+//
+// When the agent's Parameters set Background and its LLM can reattach
+// (BackgroundLLM), the provider generates the response in the background.
+// The response id and model are checkpointed into the run the moment the
+// provider reports them, and with them the sequence number of the last event
+// published, so if this process dies mid-call, executing the thread again
+// (with no new message) resumes the run and reattaches to the same response
+// where the stream left off rather than paying for it twice. The durable
+// runtimes journal model calls themselves and never take this path.
+func (e *Agent) callLLM(ctx context.Context, model LLM, run *history.ConversationRunManager, request *responses.Request, publish func(*responses.ResponseChunk)) (*responses.Response, error) {
+	bl, ok := model.(BackgroundLLM)
+	if !ok {
+		return model.NewStreamingResponses(ctx, request, publish)
+	}
+
+	if bg := run.RunState.BackgroundResponse; bg != nil {
+		resp, err := bl.ResumeStreamingResponses(ctx, &responses.StreamResponseFromRequest{
+			ID:             bg.ResponseID,
+			Model:          bg.Model,
+			SequenceNumber: bg.SequenceNumber,
+		}, e.checkpointBackground(ctx, run, publish))
+		if err == nil || errors.Is(err, ErrModelCallStopped) {
+			return resp, err
+		}
+
+		// Background responses are only kept for a while, and a provider
+		// change in between leaves nothing to reattach to.
+		slog.WarnContext(ctx, "reattaching to background response failed, calling the model again",
+			slog.String("response_id", bg.ResponseID), slog.Any("error", err))
+		run.RunState.BackgroundResponse = nil
+	}
+
+	if request.Background == nil || !*request.Background {
+		return model.NewStreamingResponses(ctx, request, publish)
+	}
+
+	// The gateway rewrites the request's model to the provider's name for
+	// it; reattaching routes by the one the request was made with.
+	startedWith := request.Model
+	checkpoint := e.checkpointBackground(ctx, run, publish)
+	return bl.NewStreamingResponses(ctx, request, func(chunk *responses.ResponseChunk) {
+		if created := chunk.OfResponseCreated; created != nil && created.Response.Id != "" && run.RunState.BackgroundResponse == nil {
+			run.RunState.BackgroundResponse = &agentstate.BackgroundResponse{
+				ResponseID:     created.Response.Id,
+				Model:          startedWith,
+				SequenceNumber: created.SequenceNumber,
+			}
+		}
+		checkpoint(chunk)
+	})
+}
+
+// checkpointBackground wraps publish so the run's background response
+// records each event published. The checkpoint is saved when the response
+// is created, at the end of each output item and at most once every
+// backgroundCheckpointInterval in between; a run resumed after a crash
+// publishes again what came after the last save.
+func (e *Agent) checkpointBackground(ctx context.Context, run *history.ConversationRunManager, publish func(*responses.ResponseChunk)) func(*responses.ResponseChunk) {
+	var saved time.Time
+	return func(chunk *responses.ResponseChunk) {
+		publish(chunk)
+
+		bg := run.RunState.BackgroundResponse
+		if bg == nil {
+			return
+		}
+		if seq, ok := chunkSequenceNumber(chunk); ok && seq > bg.SequenceNumber {
+			bg.SequenceNumber = seq
+		}
+		if chunk.OfResponseCreated == nil && chunk.OfOutputItemDone == nil && time.Since(saved) < backgroundCheckpointInterval {
+			return
+		}
+		saved = time.Now()
+		if err := run.SaveMessages(ctx); err != nil {
+			slog.WarnContext(ctx, "checkpointing background response failed", slog.Any("error", err))
+		}
+	}
+}
+
+// chunkSequenceNumber is the provider's sequence number of a streamed event.
+// Every event the Responses API streams has one, but each chunk type carries
+// it in a field of its own.
+func chunkSequenceNumber(chunk *responses.ResponseChunk) (int, bool) {
+	data, err := sonic.Marshal(chunk)
+	if err != nil {
+		return 0, false
+	}
+	node, err := sonic.Get(data, "sequence_number")
+	if err != nil {
+		return 0, false
+	}
+	seq, err := node.Int64()
+	if err != nil {
+		return 0, false
+	}
+	return int(seq), true
+}
+
+// cancelBackgroundResponse cancels the background response of a stopped
+// model call, which would otherwise run, and bill, to completion.
+func (e *Agent) cancelBackgroundResponse(ctx context.Context, run *history.ConversationRunManager) {
+	bg := run.RunState.BackgroundResponse
+	if bg == nil {
+		return
+	}
+	run.RunState.BackgroundResponse = nil
+
+	bl, ok := e.turnModel(run.RunState).llm.(BackgroundLLM)
+	if !ok {
+		return
+	}
+	if err := bl.CancelResponse(context.WithoutCancel(ctx), &responses.CancelResponseRequest{ID: bg.ResponseID, Model: bg.Model}); err != nil {
+		slog.WarnContext(ctx, "cancelling background response failed", slog.String("response_id", bg.ResponseID), slog.Any("error", err))
+	}
+}
@@ -0,0 +1,49 @@
+package agents_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/llmtest"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+)
+
+// A run whose background model call is cut off picks the response back up
+// when the thread is executed again, rather than calling the model anew.
+func TestAgentLoop_ReattachesToBackgroundResponse(t *testing.T) {
+	// The stream breaks off after the response is created and its message
+	// opened, the way a client restart would leave it.
+	fake := llmtest.New().RespondText("the research is done").DropAfter(4)
+	agent := newScriptedAgent("researcher", nil, nil, nil, nil, nil, scriptedBy(fake), func(o *agents.AgentOptions) {
+		o.Parameters = responses.Parameters{Background: utils.Ptr(true)}
+	})
+
+	handle, err := agent.Execute(context.Background(), &agents.AgentInput{
+		Namespace: "test",
+		ThreadID:  "thread-background",
+		Message:   userMessage("research the history of tea"),
+	})
+	require.NoError(t, err)
+	out, err := handle.Result()
+	require.ErrorContains(t, err, "connection lost")
+
+	out = runAgent(t, agent, &agents.AgentInput{
+		Namespace:     "test",
+		ThreadID:      "thread-background",
+		PreviousRunID: out.RunID,
+	})
+
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Equal(t, 1, fake.Calls(), "the model was called again instead of reattaching")
+	require.Len(t, fake.Reattached(), 1)
+	reattached := fake.Reattached()[0]
+	assert.Equal(t, fake.Request(0).Model, reattached.Model, "reattaching is routed by the model the response was started with")
+	assert.Equal(t, 3, reattached.SequenceNumber, "the stream resumes after the last event published")
+	assert.Equal(t, "the research is done", out.Text())
+}
@@ -0,0 +1,308 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/history"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+)
+
+// RunBudget caps what one run may spend. Unlike MaxLoops it follows the work:
+// a handoff target continues the run and its budget, and a sub-agent called
+// through AgentTool is handed what is left of it and reports back what it
+// spent, so nesting agents cannot multiply the allowance.
+//
+// A run that exhausts its token, cost or wall-clock allowance gets one last
+// model call with its tools taken away and a note telling it to wrap up, and
+// then ends with status budget_exceeded — carrying that answer, rather than
+// failing with "exceeded maximum loops".
+//
+// A zero limit means no limit. A negative one is already spent, which is how
+// a sub-agent is told its caller has nothing left to give it. MaxToolCalls is
+// the exception: a tool it lists is capped, so a zero there allows no calls.
+type RunBudget struct {
+	MaxInputTokens  int `json:"max_input_tokens,omitempty"`
+	MaxOutputTokens int `json:"max_output_tokens,omitempty"`
+
+	// MaxCost caps the run's spend in Pricing's currency. It needs Pricing:
+	// without it nothing is counted against it.
+	MaxCost float64 `json:"max_cost,omitempty"`
+
+	// Pricing prices each model the run calls, by the model name its
+	// provider reports on the response. A name with no price of its own takes
+	// that of the longest key it starts with — so "gpt-4o" prices
+	// "gpt-4o-2024-08-06", and "" prices any model not otherwise listed. A
+	// model with no price at all costs nothing.
+	Pricing map[string]ModelPricing `json:"pricing,omitempty"`
+
+	// MaxDuration caps the run's wall-clock time, counted from its start and
+	// including any time it spends paused for approval.
+	MaxDuration time.Duration `json:"max_duration,omitempty"`
+
+	// MaxToolCalls caps the calls made to each tool, by name. Only the tools
+	// it lists are capped, and a listed tool's cap is what it says, so one
+	// listed with 0 may not be called at all. A call over its tool's cap is
+	// not made: the model is told the tool is spent and carries on without
+	// it, so it does not end the run.
+	MaxToolCalls map[string]int `json:"max_tool_calls,omitempty"`
+}
+
+// ModelPricing is what a model charges per million tokens.
+type ModelPricing = agentstate.ModelPricing
+
+// priceOf is what the named model charges, or nil if Pricing does not say.
+func (b *RunBudget) priceOf(model string) *ModelPricing {
+	if price, ok := b.Pricing[model]; ok {
+		return &price
+	}
+
+	var found *ModelPricing
+	longest := -1
+	for prefix, price := range b.Pricing {
+		if len(prefix) > longest && strings.HasPrefix(model, prefix) {
+			found, longest = &price, len(prefix)
+		}
+	}
+	return found
+}
+
+// limits are the budget's limits, as the run saves them.
+func (b *RunBudget) limits() *agentstate.BudgetLimits {
+	return &agentstate.BudgetLimits{
+		MaxInputTokens:  b.MaxInputTokens,
+		MaxOutputTokens: b.MaxOutputTokens,
+		MaxCost:         b.MaxCost,
+		Pricing:         maps.Clone(b.Pricing),
+		MaxDuration:     b.MaxDuration,
+		MaxToolCalls:    maps.Clone(b.MaxToolCalls),
+	}
+}
+
+// runBudgetFrom is the budget a run saved its limits from.
+func runBudgetFrom(l *agentstate.BudgetLimits) *RunBudget {
+	return &RunBudget{
+		MaxInputTokens:  l.MaxInputTokens,
+		MaxOutputTokens: l.MaxOutputTokens,
+		MaxCost:         l.MaxCost,
+		Pricing:         maps.Clone(l.Pricing),
+		MaxDuration:     l.MaxDuration,
+		MaxToolCalls:    maps.Clone(l.MaxToolCalls),
+	}
+}
+
+// startBudget settles which budget the run is under and starts tracking it.
+// A run resumed from its state keeps the limits it started under. Otherwise
+// the input's budget wins over the agent's own; a handoff target, which runs
+// with the same input, inherits whichever it was.
+func (e *Agent) startBudget(in *AgentInput, runState *agentstate.RunState) error {
+	if runState.Budget != nil && runState.Budget.Limits != nil {
+		in.Budget = runBudgetFrom(runState.Budget.Limits)
+		return nil
+	}
+
+	if in.Budget == nil {
+		in.Budget = e.budget
+	}
+	if in.Budget == nil || runState.Budget != nil {
+		return nil
+	}
+
+	runState.Budget = &agentstate.BudgetState{Limits: in.Budget.limits()}
+	if in.Budget.MaxDuration != 0 {
+		now, err := e.now()
+		if err != nil {
+			return err
+		}
+		runState.Budget.Deadline = now.Add(in.Budget.MaxDuration)
+	}
+	return nil
+}
+
+// budgetNow reads the clock for b's wall-clock allowance. A budget without
+// one has no use for the time, and under a durable runtime is not charged a
+// step for it.
+func (e *Agent) budgetNow(b *RunBudget) (time.Time, error) {
+	if b == nil || b.MaxDuration == 0 {
+		return time.Time{}, nil
+	}
+	return e.now()
+}
+
+// exceeded names the allowance the run has used up, or returns "" if none.
+func (b *RunBudget) exceeded(state *agentstate.BudgetState, now time.Time) string {
+	if b == nil || state == nil {
+		return ""
+	}
+
+	spent := state.Spent
+	switch {
+	case b.MaxInputTokens != 0 && spent.InputTokens >= b.MaxInputTokens:
+		return "input tokens"
+	case b.MaxOutputTokens != 0 && spent.OutputTokens >= b.MaxOutputTokens:
+		return "output tokens"
+	case b.MaxCost != 0 && len(b.Pricing) > 0 && spent.Cost >= b.MaxCost:
+		return "cost"
+	case b.MaxDuration != 0 && !now.Before(state.Deadline):
+		return "time"
+	}
+	return ""
+}
+
+// withDeadline gives a model call the budget's deadline, so one still
+// streaming when the run's time runs out is cut off there rather than
+// finishing first. The wrap-up call, made after the deadline, is not given it.
+func withDeadline(ctx context.Context, state *agentstate.BudgetState, wrappingUp bool) (context.Context, context.CancelFunc) {
+	if state == nil || state.Deadline.IsZero() || wrappingUp {
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, state.Deadline)
+}
+
+// trackUsage counts one call to the named model against the budget.
+func (b *RunBudget) trackUsage(state *agentstate.BudgetState, model string, usage *responses.Usage) {
+	if b == nil || state == nil || usage == nil {
+		return
+	}
+
+	state.Spent.InputTokens += usage.InputTokens
+	state.Spent.OutputTokens += usage.OutputTokens
+	state.Spent.Cost += b.priceOf(model).Cost(*usage)
+}
+
+// allowToolCall counts a call to the named tool against its cap, or reports
+// the cap it would break.
+func (b *RunBudget) allowToolCall(state *agentstate.BudgetState, name string) (int, bool) {
+	if b == nil || state == nil {
+		return 0, true
+	}
+
+	limit, capped := b.MaxToolCalls[name]
+	if capped && state.Spent.ToolCalls[name] >= limit {
+		return limit, false
+	}
+
+	if state.Spent.ToolCalls == nil {
+		state.Spent.ToolCalls = map[string]int{}
+	}
+	state.Spent.ToolCalls[name]++
+	return 0, true
+}
+
+// remaining is the budget left for a sub-agent to run under. Each allowance
+// the run has used up is passed on as negative, so the sub-agent wraps up at
+// once rather than reading zero as unlimited. A spent tool cap is passed on as
+// zero, which for a listed tool already allows no calls.
+func (b *RunBudget) remaining(state *agentstate.BudgetState, now time.Time) *RunBudget {
+	if b == nil || state == nil {
+		return nil
+	}
+
+	left := func(limit, spent int) int {
+		if limit == 0 {
+			return 0
+		}
+		return max(limit-spent, -1)
+	}
+
+	r := &RunBudget{
+		MaxInputTokens:  left(b.MaxInputTokens, state.Spent.InputTokens),
+		MaxOutputTokens: left(b.MaxOutputTokens, state.Spent.OutputTokens),
+		Pricing:         b.Pricing,
+	}
+
+	if b.MaxCost != 0 {
+		r.MaxCost = b.MaxCost - state.Spent.Cost
+		if r.MaxCost <= 0 {
+			r.MaxCost = -1
+		}
+	}
+
+	if b.MaxDuration != 0 {
+		r.MaxDuration = state.Deadline.Sub(now)
+		if r.MaxDuration <= 0 {
+			r.MaxDuration = -1
+		}
+	}
+
+	if len(b.MaxToolCalls) > 0 {
+		r.MaxToolCalls = make(map[string]int, len(b.MaxToolCalls))
+		for name, limit := range b.MaxToolCalls {
+			r.MaxToolCalls[name] = max(limit-state.Spent.ToolCalls[name], 0)
+		}
+	}
+
+	return r
+}
+
+// budgetWrapUpMessage tells the model on its last turn that the budget is
+// spent. Like budgetReminder it is sent, not saved to history.
+func budgetWrapUpMessage(exceeded string) responses.InputMessageUnion {
+	text := fmt.Sprintf("This run has used up its budget (%s). Tools are no longer available. "+
+		"Give the user your final answer now, using whatever you have gathered so far, and say briefly what is left undone.", exceeded)
+
+	return responses.InputMessageUnion{
+		OfInputMessage: &responses.InputMessage{
+			Role: constants.RoleUser,
+			Content: responses.InputContent{
+				{OfInputText: &responses.InputTextContent{Text: text}},
+			},
+		},
+	}
+}
+
+// toolCallLimitReached is what the model is told in place of a call over its
+// tool's cap.
+func toolCallLimitReached(name string, limit int) string {
+	return fmt.Sprintf("Not run: %s has reached its limit of %d calls for this run. Carry on without it.", name, limit)
+}
+
+// withoutToolCalls drops any tool call from the wrap-up turn's reply. The
+// model was given no tools, but one that calls one anyway must not leave a
+// call in history that nothing will answer.
+func withoutToolCalls(output []responses.OutputMessageUnion) []responses.OutputMessageUnion {
+	kept := output[:0:0]
+	for _, msg := range output {
+		if msg.OfFunctionCall == nil {
+			kept = append(kept, msg)
+		}
+	}
+	return kept
+}
+
+// budgetExceeded ends a run after its wrap-up turn. The run did finish, with
+// an answer, so it is saved and announced with run.completed like any other —
+// under its own status, so a caller can tell it was cut short.
+func (e *Agent) budgetExceeded(ctx context.Context, in *AgentInput, runId string, run *history.ConversationRunManager, finalOutput []responses.InputMessageUnion) (*AgentOutput, error) {
+	run.RunState.TransitionToComplete()
+	if err := run.SaveMessages(ctx); err != nil {
+		return &AgentOutput{Status: agentstate.RunStatusError, RunID: runId}, err
+	}
+
+	// Durable step: emit run.completed once, not on every replay.
+	e.durableStep.Do(func() {
+		e.publisher(in.StreamID)(&responses.ResponseChunk{
+			OfRunCompleted: &responses.ChunkRun[constants.ChunkTypeRunCompleted]{
+				RunState: responses.ChunkRunData{
+					Id:      runId,
+					Object:  "run",
+					Status:  string(agentstate.RunStatusBudgetExceeded),
+					Usage:   run.RunState.Usage,
+					TraceID: run.RunState.TraceID,
+				},
+			},
+		})
+	})
+
+	return &AgentOutput{
+		RunID:  runId,
+		Status: agentstate.RunStatusBudgetExceeded,
+		Output: finalOutput,
+		Spend:  run.RunState.Budget.Spent.Clone(),
+	}, nil
+}
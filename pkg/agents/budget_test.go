@@ -0,0 +1,285 @@
+package agents_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	agenttools "github.com/hastekit/agent-sdk-go/pkg/agents/tools"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/llmtest"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+)
+
+func TestRunBudget_ExhaustedTokensGetAWrapUpTurn(t *testing.T) {
+	search := newFakeTool("search", false, "three results")
+	fake := llmtest.New().
+		CallTool("search", "{}").WithUsage(600, 20).
+		CallTool("search", "{}").WithUsage(700, 20).
+		RespondText("Here is what I found so far.").WithUsage(800, 30)
+	agent := newScriptedAgent("researcher", nil, nil, nil, []agents.Tool{search}, nil, scriptedBy(fake), func(o *agents.AgentOptions) {
+		o.Budget = &agents.RunBudget{MaxInputTokens: 1000}
+	})
+
+	out, chunks, err := runCollecting(t, agent, &agents.AgentInput{Message: userMessage("research this")})
+	require.NoError(t, err)
+	requireStatus(t, out, agentstate.RunStatusBudgetExceeded)
+	assert.Equal(t, "Here is what I found so far.", out.Text())
+	assert.Equal(t, 2100, out.Spend.InputTokens)
+	assert.Equal(t, 2, search.callCount())
+
+	// The last call had no tools to reach for and was told why.
+	require.Equal(t, 3, fake.Calls())
+	assert.NotEmpty(t, fake.Request(1).Tools)
+	assert.Empty(t, fake.Request(2).Tools)
+	assert.Contains(t, messagesText(fake.Request(2).Input.OfInputMessageList), "used up its budget (input tokens)")
+
+	last := chunks[len(chunks)-1]
+	require.NotNil(t, last.OfRunCompleted)
+	assert.Equal(t, "budget_exceeded", last.OfRunCompleted.RunState.Status)
+}
+
+func TestRunBudget_WrapUpToolCallsAreDropped(t *testing.T) {
+	search := newFakeTool("search", false, "three results")
+	fake := llmtest.New().
+		Respond(llmtest.ToolCallWithID("call_1", "search", "{}")).WithUsage(10, 500).
+		Respond(llmtest.ToolCallWithID("call_2", "search", "{}")).WithUsage(10, 10)
+	agent := newScriptedAgent("researcher", nil, nil, nil, []agents.Tool{search}, nil, scriptedBy(fake), func(o *agents.AgentOptions) {
+		o.Budget = &agents.RunBudget{MaxOutputTokens: 100}
+	})
+
+	out := runAgent(t, agent, &agents.AgentInput{Message: userMessage("research this")})
+	requireStatus(t, out, agentstate.RunStatusBudgetExceeded)
+	assert.Equal(t, 1, search.callCount())
+
+	// The wrap-up turn's call is neither made nor left in the output
+	// unanswered.
+	assert.Equal(t, 2, fake.Calls())
+	for _, msg := range out.Output {
+		if msg.OfFunctionCall != nil {
+			assert.Equal(t, "call_1", msg.OfFunctionCall.CallID)
+		}
+	}
+}
+
+func TestRunBudget_InputBudgetOverridesTheAgents(t *testing.T) {
+	fake := llmtest.New().RespondText("done").WithUsage(5000, 10)
+	agent := newScriptedAgent("assistant", nil, nil, nil, nil, nil, scriptedBy(fake), func(o *agents.AgentOptions) {
+		o.Budget = &agents.RunBudget{MaxInputTokens: 1}
+	})
+
+	out := runAgent(t, agent, &agents.AgentInput{
+		Message: userMessage("hi"),
+		Budget:  &agents.RunBudget{MaxInputTokens: 100_000},
+	})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Equal(t, 5000, out.Spend.InputTokens)
+}
+
+func TestRunBudget_ElapsedDeadlineWrapsUp(t *testing.T) {
+	slow := newFakeTool("slow", false, "done")
+	slowExecute := slow.execute
+	slow.execute = func(ctx context.Context, params *agents.ToolCall) (*agents.ToolCallResponse, error) {
+		time.Sleep(20 * time.Millisecond)
+		return slowExecute(ctx, params)
+	}
+	fake := llmtest.New().CallTool("slow", "{}").RespondText("Out of time.")
+	agent := newScriptedAgent("assistant", nil, nil, nil, []agents.Tool{slow}, nil, scriptedBy(fake), func(o *agents.AgentOptions) {
+		o.Budget = &agents.RunBudget{MaxDuration: 10 * time.Millisecond}
+	})
+
+	out := runAgent(t, agent, &agents.AgentInput{Message: userMessage("hi")})
+	requireStatus(t, out, agentstate.RunStatusBudgetExceeded)
+	assert.Contains(t, messagesText(fake.Request(1).Input.OfInputMessageList), "used up its budget (time)")
+}
+
+// A call still streaming at the deadline is cut off there, and the run
+// wraps up rather than waiting for it.
+func TestRunBudget_DeadlineCutsOffTheModelCall(t *testing.T) {
+	fake := llmtest.New().Hang().RespondText("Out of time.")
+	agent := newScriptedAgent("assistant", nil, nil, nil, nil, nil, scriptedBy(fake), func(o *agents.AgentOptions) {
+		o.Budget = &agents.RunBudget{MaxDuration: 20 * time.Millisecond}
+	})
+
+	out := runAgent(t, agent, &agents.AgentInput{Message: userMessage("hi")})
+	requireStatus(t, out, agentstate.RunStatusBudgetExceeded)
+	assert.Equal(t, "Out of time.", out.Text())
+	assert.Contains(t, messagesText(fake.Request(1).Input.OfInputMessageList), "used up its budget (time)")
+	assert.Eventually(t, func() bool { return fake.Cancelled() == 1 }, time.Second, time.Millisecond)
+}
+
+// The wrap-up answer is still the run's answer, and the output guardrails
+// read it.
+func TestRunBudget_WrapUpAnswerIsGuarded(t *testing.T) {
+	fake := llmtest.New().
+		CallTool("search", "{}").WithUsage(10, 500).
+		RespondText("the secret is 42")
+	guarded := 0
+	tools := []agents.Tool{newFakeTool("search", false, "found it")}
+	agent := newScriptedAgent("assistant", nil, nil, nil, tools, nil, scriptedBy(fake), func(o *agents.AgentOptions) {
+		o.Budget = &agents.RunBudget{MaxOutputTokens: 100}
+		o.OutputGuardrails = []agents.OutputGuardrail{
+			agents.NewOutputGuardrail("secrets", func(ctx context.Context, out *agents.GuardrailOutput) (agents.GuardrailResult, error) {
+				guarded++
+				return agents.TripGuardrail("leaks a secret"), nil
+			}),
+		}
+	})
+
+	out := runAgent(t, agent, &agents.AgentInput{Message: userMessage("what is the secret?")})
+	requireStatus(t, out, agentstate.RunStatusGuardrailTripped)
+	assert.Equal(t, 1, guarded)
+	require.NotNil(t, out.Guardrail)
+	assert.Equal(t, "secrets", out.Guardrail.Name)
+}
+
+// A run resumed after an approval is held to the limits it started under,
+// not to whatever budget it is resumed with.
+func TestRunBudget_ResumedRunKeepsItsLimits(t *testing.T) {
+	fake := llmtest.New().
+		Respond(llmtest.ToolCallWithID("call_danger", "dangerous", "{}")).WithUsage(10, 150).
+		RespondText("wrapped up")
+	danger := newFakeTool("dangerous", true, "dangerous done")
+	agent := newScriptedAgent("main", nil, nil, nil, []agents.Tool{danger}, nil, scriptedBy(fake))
+
+	out := runAgent(t, agent, &agents.AgentInput{
+		Namespace: "test",
+		ThreadID:  "thread-budget",
+		Message:   userMessage("do it"),
+		Budget:    &agents.RunBudget{MaxOutputTokens: 100},
+	})
+	requireStatus(t, out, agentstate.RunStatusPaused)
+
+	out = runAgent(t, agent, &agents.AgentInput{
+		Namespace:     "test",
+		ThreadID:      "thread-budget",
+		PreviousRunID: out.RunID,
+		Message:       approvalMessage([]string{"call_danger"}, nil),
+	})
+	requireStatus(t, out, agentstate.RunStatusBudgetExceeded)
+	assert.Equal(t, 1, danger.callCount())
+	assert.Empty(t, fake.Request(1).Tools)
+}
+
+// Each call is priced by the model that answered it.
+func TestRunBudget_PricesEachModelItsOwnWay(t *testing.T) {
+	fake := llmtest.New().
+		CallTool("search", "{}").WithUsage(1_000_000, 0).WithModel("gpt-4.1-mini-2025-04-14").
+		RespondText("done").WithUsage(1_000_000, 0).WithModel("gpt-4.1")
+	tools := []agents.Tool{newFakeTool("search", false, "found it")}
+	agent := newScriptedAgent("assistant", nil, nil, nil, tools, nil, scriptedBy(fake), func(o *agents.AgentOptions) {
+		o.Budget = &agents.RunBudget{
+			MaxCost: 100,
+			Pricing: map[string]agents.ModelPricing{
+				"gpt-4.1":      {InputPerMillion: 2},
+				"gpt-4.1-mini": {InputPerMillion: 0.4},
+			},
+		}
+	})
+
+	out := runAgent(t, agent, &agents.AgentInput{Message: userMessage("hi")})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.InDelta(t, 2.4, out.Spend.Cost, 1e-9)
+}
+
+func TestRunBudget_ToolCallCapRefusesTheCall(t *testing.T) {
+	search := newFakeTool("search", false, "three results")
+	fake := llmtest.New().
+		CallTool("search", "{}").
+		CallTool("search", "{}").
+		RespondText("done")
+	agent := newScriptedAgent("researcher", nil, nil, nil, []agents.Tool{search}, nil, scriptedBy(fake), func(o *agents.AgentOptions) {
+		o.Budget = &agents.RunBudget{MaxToolCalls: map[string]int{"search": 1}}
+	})
+
+	out := runAgent(t, agent, &agents.AgentInput{Message: userMessage("research this")})
+
+	// The cap refuses the call but does not end the run.
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Equal(t, 1, search.callCount())
+	assert.Contains(t, messagesText(fake.Request(2).Input.OfInputMessageList), "search has reached its limit of 1 calls")
+	assert.Equal(t, map[string]int{"search": 1}, out.Spend.ToolCalls)
+}
+
+// Unlike the scalar limits, a tool capped at zero may not be called at all.
+func TestRunBudget_ZeroToolCallCapAllowsNoCalls(t *testing.T) {
+	search := newFakeTool("search", false, "three results")
+	fake := llmtest.New().
+		CallTool("search", "{}").WithUsage(10, 10).
+		RespondText("done").WithUsage(10, 10)
+	agent := newScriptedAgent("researcher", nil, nil, nil, []agents.Tool{search}, nil, scriptedBy(fake), func(o *agents.AgentOptions) {
+		o.Budget = &agents.RunBudget{MaxInputTokens: 0, MaxOutputTokens: 0, MaxToolCalls: map[string]int{"search": 0}}
+	})
+
+	out := runAgent(t, agent, &agents.AgentInput{Message: userMessage("research this")})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Zero(t, search.callCount())
+	assert.Contains(t, messagesText(fake.Request(1).Input.OfInputMessageList), "search has reached its limit of 0 calls")
+}
+
+func TestRunBudget_SubAgentSpendsTheCallersBudget(t *testing.T) {
+	var childBudget *agents.RunBudget
+	childLLM := llmtest.New().RespondText("child answer").WithUsage(10, 300)
+	child := newScriptedAgent("child", nil, nil, nil, nil, nil, scriptedBy(childLLM))
+
+	delegate := agenttools.NewAgentTool("child_agent", "delegate to the child agent", child, agenttools.SubAgentContextModeIsolated)
+	spy := newFakeTool("child_agent", false, "")
+	spy.BaseTool = delegate.BaseTool
+	spy.execute = func(ctx context.Context, params *agents.ToolCall) (*agents.ToolCallResponse, error) {
+		childBudget = params.Budget
+		return delegate.Execute(ctx, params)
+	}
+
+	parentLLM := llmtest.New().
+		CallTool("child_agent", `{"message":"go"}`).WithUsage(10, 100).
+		RespondText("wrapped up").WithUsage(10, 5)
+	parent := newScriptedAgent("parent", nil, nil, nil, []agents.Tool{spy}, nil, scriptedBy(parentLLM), func(o *agents.AgentOptions) {
+		o.Budget = &agents.RunBudget{MaxOutputTokens: 350}
+	})
+
+	out := runAgent(t, parent, &agents.AgentInput{Message: userMessage("delegate")})
+
+	// The child was handed what was left, and what it spent counts against
+	// the parent, which is over budget because of it.
+	require.NotNil(t, childBudget)
+	assert.Equal(t, 250, childBudget.MaxOutputTokens)
+	requireStatus(t, out, agentstate.RunStatusBudgetExceeded)
+	assert.Equal(t, 405, out.Spend.OutputTokens)
+	assert.Empty(t, parentLLM.Request(1).Tools)
+}
+
+func TestModelPricing_Cost(t *testing.T) {
+	pricing := &agents.ModelPricing{InputPerMillion: 3, CachedInputPerMillion: 0.3, OutputPerMillion: 15}
+
+	usage := responses.Usage{InputTokens: 1_000_000, OutputTokens: 100_000}
+	usage.InputTokensDetails.CachedTokens = 500_000
+
+	assert.InDelta(t, 1.5+0.15+1.5, pricing.Cost(usage), 1e-9)
+	assert.Zero(t, (*agents.ModelPricing)(nil).Cost(usage))
+}
+
+func TestBudgetState_RoundTripsThroughMeta(t *testing.T) {
+	state := agentstate.NewRunState()
+	state.Budget = &agentstate.BudgetState{
+		Limits: &agentstate.BudgetLimits{
+			MaxOutputTokens: 100,
+			Pricing:         map[string]agentstate.ModelPricing{"gpt-4.1": {InputPerMillion: 2}},
+			MaxDuration:     time.Minute,
+		},
+		Spent:    agentstate.BudgetSpend{InputTokens: 12, Cost: 0.5, ToolCalls: map[string]int{"search": 2}},
+		Deadline: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Exceeded: "cost",
+	}
+
+	loaded := agentstate.LoadRunStateFromMeta(state.ToMeta())
+	require.NotNil(t, loaded)
+	require.NotNil(t, loaded.Budget)
+	assert.Equal(t, state.Budget.Limits, loaded.Budget.Limits)
+	assert.Equal(t, state.Budget.Spent, loaded.Budget.Spent)
+	assert.True(t, state.Budget.Deadline.Equal(loaded.Budget.Deadline))
+	assert.Equal(t, "cost", loaded.Budget.Exceeded)
+}
@@ -1,5 +1,7 @@
 package agents
 
+import "time"
+
 // DurableStep runs a side effect exactly once per logical occurrence, even
 // when the agent loop replays under a durable runtime.
 //
@@ -27,3 +29,23 @@ type DurableStep interface {
 type localDurableStep struct{}
 
 func (localDurableStep) Do(fn func()) { fn() }
+
+// DurableClock is a DurableStep that can also tell the time the way its
+// runtime replays it. The loop reads the clock to enforce a RunBudget's
+// wall-clock allowance, and a reading that differed on replay would send the
+// replay down a different path than the original run took.
+//
+// A DurableStep that is not one is read with time.Now.
+type DurableClock interface {
+	// Now returns an error when the runtime could not record the reading. The
+	// loop fails the run rather than go on with an unrecorded one.
+	Now() (time.Time, error)
+}
+
+// now is the current time, as the agent's runtime records it.
+func (e *Agent) now() (time.Time, error) {
+	if clock, ok := e.durableStep.(DurableClock); ok {
+		return clock.Now()
+	}
+	return time.Now(), nil
+}
@@ -0,0 +1,458 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/history"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+)
+
+// The stages a guardrail can trip at, as reported in GuardrailTrip.Stage.
+const (
+	GuardrailStageInput  = "input"
+	GuardrailStageOutput = "output"
+)
+
+// InputGuardrail checks what a run was asked before the agent acts on it.
+//
+// Input guardrails run alongside the model call that opens the run rather than
+// ahead of it, so a run they pass pays nothing for them. One that trips cancels
+// that call where it stands and ends the run with status guardrail_tripped.
+// Under a durable runtime the call is a step of its own that runs to the end,
+// and a trip drops its answer instead.
+//
+// Like a hook it is an interface, so a durable runtime can run each check as
+// its own journaled step: a classifier that calls a model must not be asked
+// again every time a workflow replays.
+type InputGuardrail interface {
+	// GetName identifies the guardrail in the run.guardrail_tripped chunk and
+	// names its step under a durable runtime, so it must be unique among the
+	// guardrails on one agent and stable across deploys.
+	GetName() string
+
+	// CheckInput returns TripGuardrail to end the run, or PassGuardrail to
+	// let it go on. An error fails the run: a guardrail that cannot decide
+	// does not wave the input through.
+	CheckInput(ctx context.Context, in *GuardrailInput) (GuardrailResult, error)
+}
+
+// InputGuardrailStarter is an InputGuardrail whose check can be started
+// without waiting for its verdict. The durable runtimes' guardrails are
+// starters: the loop, which must not start goroutines of its own there, starts
+// each check as a step before the model call so the two run side by side.
+type InputGuardrailStarter interface {
+	InputGuardrail
+
+	// StartCheckInput schedules the check and returns a wait for its verdict.
+	StartCheckInput(ctx context.Context, in *GuardrailInput) func() (GuardrailResult, error)
+}
+
+// OutputGuardrail checks the agent's final answer before the run completes.
+// An answer it trips on is not saved to the thread or returned to the caller.
+type OutputGuardrail interface {
+	// GetName is as for InputGuardrail.
+	GetName() string
+
+	// CheckOutput is CheckInput for the final answer.
+	CheckOutput(ctx context.Context, out *GuardrailOutput) (GuardrailResult, error)
+}
+
+// GuardrailInput is what an input guardrail is asked to check: the messages
+// the run was started with, and who they came from.
+type GuardrailInput struct {
+	AgentName  string                        `json:"agent_name"`
+	Namespace  string                        `json:"namespace"`
+	ThreadID   string                        `json:"thread_id"`
+	RunID      string                        `json:"run_id,omitempty"`
+	RunContext map[string]any                `json:"run_context,omitempty"`
+	Messages   []responses.InputMessageUnion `json:"messages"`
+}
+
+// Text returns the text of the input messages, one per line.
+func (in *GuardrailInput) Text() string {
+	return guardrailText(in.Messages)
+}
+
+// GuardrailOutput is what an output guardrail is asked to check: the messages
+// of the agent's final answer.
+type GuardrailOutput struct {
+	AgentName  string                        `json:"agent_name"`
+	Namespace  string                        `json:"namespace"`
+	ThreadID   string                        `json:"thread_id"`
+	RunID      string                        `json:"run_id,omitempty"`
+	RunContext map[string]any                `json:"run_context,omitempty"`
+	Messages   []responses.InputMessageUnion `json:"messages"`
+}
+
+// Text returns the text of the answer, one message per line.
+func (out *GuardrailOutput) Text() string {
+	return guardrailText(out.Messages)
+}
+
+// GuardrailResult is a guardrail's verdict.
+type GuardrailResult struct {
+	// Tripped ends the run.
+	Tripped bool `json:"tripped"`
+	// Reason says why, for the run.guardrail_tripped chunk and the caller.
+	Reason string `json:"reason,omitempty"`
+}
+
+// PassGuardrail lets the run go on. It is the zero value.
+func PassGuardrail() GuardrailResult {
+	return GuardrailResult{}
+}
+
+// TripGuardrail ends the run, for the given reason.
+func TripGuardrail(reason string) GuardrailResult {
+	return GuardrailResult{Tripped: true, Reason: reason}
+}
+
+// NewInputGuardrail makes an input guardrail of a function.
+func NewInputGuardrail(name string, fn func(ctx context.Context, in *GuardrailInput) (GuardrailResult, error)) InputGuardrail {
+	return &inputGuardrailFunc{name: name, fn: fn}
+}
+
+type inputGuardrailFunc struct {
+	name string
+	fn   func(ctx context.Context, in *GuardrailInput) (GuardrailResult, error)
+}
+
+func (g *inputGuardrailFunc) GetName() string { return g.name }
+
+func (g *inputGuardrailFunc) CheckInput(ctx context.Context, in *GuardrailInput) (GuardrailResult, error) {
+	return g.fn(ctx, in)
+}
+
+// NewOutputGuardrail makes an output guardrail of a function.
+func NewOutputGuardrail(name string, fn func(ctx context.Context, out *GuardrailOutput) (GuardrailResult, error)) OutputGuardrail {
+	return &outputGuardrailFunc{name: name, fn: fn}
+}
+
+type outputGuardrailFunc struct {
+	name string
+	fn   func(ctx context.Context, out *GuardrailOutput) (GuardrailResult, error)
+}
+
+func (g *outputGuardrailFunc) GetName() string { return g.name }
+
+func (g *outputGuardrailFunc) CheckOutput(ctx context.Context, out *GuardrailOutput) (GuardrailResult, error) {
+	return g.fn(ctx, out)
+}
+
+// RegexGuardrail trips when the text matches any of its patterns. It works as
+// either kind of guardrail: on the input it keeps, say, card numbers away from
+// the model, and on the output it keeps them away from the user.
+type RegexGuardrail struct {
+	name     string
+	reason   string
+	patterns []*regexp.Regexp
+}
+
+var (
+	_ InputGuardrail  = (*RegexGuardrail)(nil)
+	_ OutputGuardrail = (*RegexGuardrail)(nil)
+)
+
+// NewRegexGuardrail returns a guardrail that trips with reason when any of
+// patterns matches. An empty reason reports the pattern that matched.
+func NewRegexGuardrail(name, reason string, patterns ...*regexp.Regexp) *RegexGuardrail {
+	return &RegexGuardrail{name: name, reason: reason, patterns: patterns}
+}
+
+func (g *RegexGuardrail) GetName() string { return g.name }
+
+func (g *RegexGuardrail) CheckInput(_ context.Context, in *GuardrailInput) (GuardrailResult, error) {
+	return g.check(in.Text()), nil
+}
+
+func (g *RegexGuardrail) CheckOutput(_ context.Context, out *GuardrailOutput) (GuardrailResult, error) {
+	return g.check(out.Text()), nil
+}
+
+func (g *RegexGuardrail) check(text string) GuardrailResult {
+	for _, p := range g.patterns {
+		if p.MatchString(text) {
+			if g.reason != "" {
+				return TripGuardrail(g.reason)
+			}
+			return TripGuardrail("matched " + p.String())
+		}
+	}
+	return PassGuardrail()
+}
+
+// LLMGuardrailOptions configures an LLMGuardrail.
+type LLMGuardrailOptions struct {
+	Name string
+	LLM  llm.Provider
+	// Instruction tells the classifier what to trip on, e.g. "Flag requests
+	// for anything other than help with our billing product."
+	Instruction string
+	// Parameters picks the model. A small, fast one is the usual choice: the
+	// classifier runs alongside the agent's own first call, and only hides
+	// its latency while it is the quicker of the two.
+	Parameters responses.Parameters
+}
+
+// LLMGuardrail asks a model whether the text breaks the rule in its
+// instruction. It works as either kind of guardrail.
+type LLMGuardrail struct {
+	name        string
+	llm         llm.Provider
+	instruction string
+	parameters  responses.Parameters
+}
+
+var (
+	_ InputGuardrail  = (*LLMGuardrail)(nil)
+	_ OutputGuardrail = (*LLMGuardrail)(nil)
+)
+
+func NewLLMGuardrail(opts *LLMGuardrailOptions) *LLMGuardrail {
+	parameters := opts.Parameters
+	parameters.Text = &responses.TextFormat{
+		Format: map[string]any{
+			"type":   "json_schema",
+			"name":   "guardrail_verdict",
+			"strict": false,
+			"schema": OutputSchemaFor[GuardrailResult](),
+		},
+	}
+
+	return &LLMGuardrail{
+		name:        opts.Name,
+		llm:         opts.LLM,
+		instruction: opts.Instruction,
+		parameters:  parameters,
+	}
+}
+
+func (g *LLMGuardrail) GetName() string { return g.name }
+
+func (g *LLMGuardrail) CheckInput(ctx context.Context, in *GuardrailInput) (GuardrailResult, error) {
+	return g.classify(ctx, "a user's message to an assistant", in.Text())
+}
+
+func (g *LLMGuardrail) CheckOutput(ctx context.Context, out *GuardrailOutput) (GuardrailResult, error) {
+	return g.classify(ctx, "an assistant's answer to a user", out.Text())
+}
+
+func (g *LLMGuardrail) classify(ctx context.Context, what, text string) (GuardrailResult, error) {
+	instruction := "You are a guardrail. Decide whether the text you are given breaks this rule:\n\n" + g.instruction +
+		"\n\nAnswer with JSON only: \"tripped\" is true if the rule is broken, and \"reason\" says why in one sentence."
+
+	resp, err := g.llm.NewResponses(ctx, &responses.Request{
+		Instructions: utils.Ptr(instruction),
+		Input: responses.InputUnion{
+			OfInputMessageList: responses.InputMessageList{{
+				OfInputMessage: &responses.InputMessage{
+					Role: constants.RoleUser,
+					Content: responses.InputContent{
+						{OfInputText: &responses.InputTextContent{Text: fmt.Sprintf("The text is %s:\n\n%s", what, text)}},
+					},
+				},
+			}},
+		},
+		Parameters: g.parameters,
+	})
+	if err != nil {
+		return GuardrailResult{}, err
+	}
+
+	answer := ""
+	for _, msg := range resp.Output {
+		if msg.OfOutputMessage != nil {
+			answer += outputMessageText(msg.OfOutputMessage.Content)
+		}
+	}
+
+	var verdict GuardrailResult
+	if err := sonic.Unmarshal([]byte(stripCodeFence(answer)), &verdict); err != nil {
+		return GuardrailResult{}, fmt.Errorf("guardrail classifier gave no verdict: %w", err)
+	}
+
+	return verdict, nil
+}
+
+// guardrailText joins the text of msgs, whichever side wrote them.
+func guardrailText(msgs []responses.InputMessageUnion) string {
+	var lines []string
+	for _, msg := range msgs {
+		var content responses.InputContent
+		switch {
+		case msg.OfOutputMessage != nil:
+			if text := outputMessageText(msg.OfOutputMessage.Content); text != "" {
+				lines = append(lines, text)
+			}
+			continue
+		case msg.OfEasyInput != nil && msg.OfEasyInput.Content.OfString != nil:
+			lines = append(lines, *msg.OfEasyInput.Content.OfString)
+			continue
+		case msg.OfEasyInput != nil:
+			content = msg.OfEasyInput.Content.OfInputMessageList
+		case msg.OfInputMessage != nil:
+			content = msg.OfInputMessage.Content
+		}
+
+		var b strings.Builder
+		for _, c := range content {
+			switch {
+			case c.OfInputText != nil:
+				b.WriteString(c.OfInputText.Text)
+			case c.OfOutputText != nil:
+				b.WriteString(c.OfOutputText.Text)
+			}
+		}
+		if b.Len() > 0 {
+			lines = append(lines, b.String())
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// checkInputGuardrails runs the agent's input guardrails in order and returns
+// the first trip, if any.
+func (e *Agent) checkInputGuardrails(ctx context.Context, in *GuardrailInput) (*responses.GuardrailTrip, error) {
+	for _, g := range e.inputGuardrails {
+		res, err := g.CheckInput(ctx, in)
+		if err != nil {
+			return nil, fmt.Errorf("input guardrail %q: %w", g.GetName(), err)
+		}
+		if res.Tripped {
+			return &responses.GuardrailTrip{Name: g.GetName(), Stage: GuardrailStageInput, Reason: res.Reason}, nil
+		}
+	}
+	return nil, nil
+}
+
+// checkOutputGuardrails is checkInputGuardrails for the final answer.
+func (e *Agent) checkOutputGuardrails(ctx context.Context, out *GuardrailOutput) (*responses.GuardrailTrip, error) {
+	for _, g := range e.outputGuardrails {
+		res, err := g.CheckOutput(ctx, out)
+		if err != nil {
+			return nil, fmt.Errorf("output guardrail %q: %w", g.GetName(), err)
+		}
+		if res.Tripped {
+			return &responses.GuardrailTrip{Name: g.GetName(), Stage: GuardrailStageOutput, Reason: res.Reason}, nil
+		}
+	}
+	return nil, nil
+}
+
+// checkAnswer runs the output guardrails over the run's final answer.
+func (e *Agent) checkAnswer(ctx context.Context, in *AgentInput, runId string, answer []responses.InputMessageUnion) (*responses.GuardrailTrip, error) {
+	return e.checkOutputGuardrails(ctx, &GuardrailOutput{
+		AgentName:  e.Name,
+		Namespace:  in.Namespace,
+		ThreadID:   in.ThreadID,
+		RunID:      runId,
+		RunContext: in.RunContext,
+		Messages:   answer,
+	})
+}
+
+// startInputGuardrails runs the input guardrails alongside the model call. It
+// returns the context the call should use, which a trip cancels, and a wait
+// for the verdict.
+//
+// Under a durable runtime see startDurableInputGuardrails instead.
+func (e *Agent) startInputGuardrails(ctx context.Context, in *GuardrailInput) (context.Context, func() (*responses.GuardrailTrip, error)) {
+	callCtx, cancel := context.WithCancelCause(ctx)
+	type verdict struct {
+		trip *responses.GuardrailTrip
+		err  error
+	}
+	done := make(chan verdict, 1)
+
+	go func() {
+		trip, err := e.checkInputGuardrails(ctx, in)
+		if trip != nil || err != nil {
+			cancel(fmt.Errorf("input guardrail tripped"))
+		}
+		done <- verdict{trip: trip, err: err}
+	}()
+
+	return callCtx, func() (*responses.GuardrailTrip, error) {
+		v := <-done
+		cancel(nil)
+		return v.trip, v.err
+	}
+}
+
+// startDurableInputGuardrails is startInputGuardrails for a durable runtime.
+// Each guardrail that is an InputGuardrailStarter has its check started, in
+// order, as a step of the runtime's own, which runs alongside the model call
+// made after it. The verdicts are awaited in the same order once the call is
+// done; a guardrail that is not a starter is checked then.
+func (e *Agent) startDurableInputGuardrails(ctx context.Context, in *GuardrailInput) func() (*responses.GuardrailTrip, error) {
+	waits := make([]func() (GuardrailResult, error), len(e.inputGuardrails))
+	for i, g := range e.inputGuardrails {
+		if starter, ok := g.(InputGuardrailStarter); ok {
+			waits[i] = starter.StartCheckInput(ctx, in)
+		}
+	}
+
+	return func() (*responses.GuardrailTrip, error) {
+		for i, g := range e.inputGuardrails {
+			var res GuardrailResult
+			var err error
+			if waits[i] != nil {
+				res, err = waits[i]()
+			} else {
+				res, err = g.CheckInput(ctx, in)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("input guardrail %q: %w", g.GetName(), err)
+			}
+			if res.Tripped {
+				return &responses.GuardrailTrip{Name: g.GetName(), Stage: GuardrailStageInput, Reason: res.Reason}, nil
+			}
+		}
+		return nil, nil
+	}
+}
+
+// durable reports whether the agent runs under a durable runtime, where the
+// loop must not start goroutines of its own.
+func (e *Agent) durable() bool {
+	_, local := e.durableStep.(localDurableStep)
+	return !local
+}
+
+// guardrailTripped ends a run a guardrail blocked. Nothing the run added is
+// saved to the thread: neither the input that tripped, nor the answer.
+func (e *Agent) guardrailTripped(ctx context.Context, in *AgentInput, runId string, run *history.ConversationRunManager, trip *responses.GuardrailTrip) (*AgentOutput, error) {
+	slog.InfoContext(ctx, "guardrail tripped", slog.String("agent", e.Name), slog.String("guardrail", trip.Name), slog.String("stage", trip.Stage), slog.String("reason", trip.Reason))
+
+	// Durable step: emit run.guardrail_tripped once, not on every replay.
+	e.durableStep.Do(func() {
+		e.publisher(in.StreamID)(&responses.ResponseChunk{
+			OfRunGuardrailTripped: &responses.ChunkRun[constants.ChunkTypeRunGuardrailTripped]{
+				RunState: responses.ChunkRunData{
+					Id:        runId,
+					Object:    "run",
+					Status:    string(agentstate.RunStatusGuardrailTripped),
+					Usage:     run.RunState.Usage,
+					TraceID:   run.RunState.TraceID,
+					Guardrail: trip,
+				},
+			},
+		})
+	})
+
+	return &AgentOutput{
+		RunID:     runId,
+		Status:    agentstate.RunStatusGuardrailTripped,
+		Guardrail: trip,
+	}, nil
+}
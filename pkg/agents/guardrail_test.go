@@ -0,0 +1,145 @@
+package agents_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/llmtest"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+)
+
+// runCollecting runs the agent and returns its output along with every chunk
+// it streamed.
+func runCollecting(t *testing.T, agent *agents.Agent, in *agents.AgentInput) (*agents.AgentOutput, []*responses.ResponseChunk, error) {
+	t.Helper()
+	handle, err := agent.Execute(context.Background(), in)
+	require.NoError(t, err)
+
+	var chunks []*responses.ResponseChunk
+	for chunk := range handle.Chunks {
+		chunks = append(chunks, chunk)
+	}
+	out, err := handle.Wait()
+	return out, chunks, err
+}
+
+func TestInputGuardrail_TripCancelsTheModelCall(t *testing.T) {
+	fake := llmtest.New().Hang()
+	guardrail := agents.NewInputGuardrail("off_topic", func(ctx context.Context, in *agents.GuardrailInput) (agents.GuardrailResult, error) {
+		if strings.Contains(in.Text(), "homework") {
+			return agents.TripGuardrail("not a support question"), nil
+		}
+		return agents.PassGuardrail(), nil
+	})
+
+	agent := newScriptedAgent("support", nil, nil, nil, nil, nil, scriptedBy(fake), func(o *agents.AgentOptions) {
+		o.InputGuardrails = []agents.InputGuardrail{guardrail}
+	})
+
+	out, chunks, err := runCollecting(t, agent, &agents.AgentInput{
+		Message: userMessage("do my maths homework"),
+	})
+	require.NoError(t, err)
+	requireStatus(t, out, agentstate.RunStatusGuardrailTripped)
+	assert.Equal(t, &responses.GuardrailTrip{Name: "off_topic", Stage: agents.GuardrailStageInput, Reason: "not a support question"}, out.Guardrail)
+	assert.Empty(t, out.Output)
+
+	// The model call was under way when the guardrail tripped, and was cut off.
+	assert.Eventually(t, func() bool { return fake.Cancelled() == 1 }, time.Second, time.Millisecond)
+
+	last := chunks[len(chunks)-1]
+	require.NotNil(t, last.OfRunGuardrailTripped)
+	assert.Equal(t, "guardrail_tripped", last.OfRunGuardrailTripped.RunState.Status)
+	assert.Equal(t, "off_topic", last.OfRunGuardrailTripped.RunState.Guardrail.Name)
+}
+
+func TestInputGuardrail_TrippedInputIsNotSaved(t *testing.T) {
+	fake := llmtest.New().RespondText("noted").RespondText("hello")
+	cards := agents.NewRegexGuardrail("card_numbers", "", regexp.MustCompile(`\b(?:\d[ -]?){13,16}\b`))
+	agent := newScriptedAgent("support", nil, nil, nil, nil, nil, scriptedBy(fake), func(o *agents.AgentOptions) {
+		o.InputGuardrails = []agents.InputGuardrail{cards}
+	})
+
+	out, _, err := runCollecting(t, agent, &agents.AgentInput{ThreadID: "t1", Message: userMessage("my card is 4111 1111 1111 1111")})
+	require.NoError(t, err)
+	requireStatus(t, out, agentstate.RunStatusGuardrailTripped)
+	assert.Contains(t, out.Guardrail.Reason, "matched")
+
+	out = runAgent(t, agent, &agents.AgentInput{ThreadID: "t1", Message: userMessage("hi")})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+
+	// The next run on the thread never sees the blocked message.
+	sent := messagesText(fake.Request(fake.Calls() - 1).Input.OfInputMessageList)
+	assert.Contains(t, sent, "hi")
+	assert.NotContains(t, sent, "4111")
+}
+
+func TestInputGuardrail_PassingRunCompletes(t *testing.T) {
+	fake := llmtest.New().RespondText("Your invoice is attached.")
+
+	var seen string
+	guardrail := agents.NewInputGuardrail("seen", func(ctx context.Context, in *agents.GuardrailInput) (agents.GuardrailResult, error) {
+		seen = in.Text()
+		return agents.PassGuardrail(), nil
+	})
+
+	agent := newScriptedAgent("support", nil, nil, nil, nil, nil, scriptedBy(fake), func(o *agents.AgentOptions) {
+		o.InputGuardrails = []agents.InputGuardrail{guardrail}
+	})
+
+	out := runAgent(t, agent, &agents.AgentInput{
+		Message: userMessage("where is my invoice?"),
+	})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Equal(t, "where is my invoice?", seen)
+	assert.Equal(t, "Your invoice is attached.", out.Text())
+}
+
+func TestInputGuardrail_ErrorFailsTheRun(t *testing.T) {
+	fake := llmtest.New().RespondText("hello")
+	guardrail := agents.NewInputGuardrail("classifier", func(ctx context.Context, in *agents.GuardrailInput) (agents.GuardrailResult, error) {
+		return agents.GuardrailResult{}, errors.New("classifier unavailable")
+	})
+
+	agent := newScriptedAgent("support", nil, nil, nil, nil, nil, scriptedBy(fake), func(o *agents.AgentOptions) {
+		o.InputGuardrails = []agents.InputGuardrail{guardrail}
+	})
+
+	out, _, err := runCollecting(t, agent, &agents.AgentInput{
+		Message: userMessage("hi"),
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `input guardrail "classifier"`)
+	requireStatus(t, out, agentstate.RunStatusError)
+}
+
+func TestOutputGuardrail_TripWithholdsTheAnswer(t *testing.T) {
+	fake := llmtest.New().RespondText("The admin password is hunter2.")
+	secrets := agents.NewRegexGuardrail("secrets", "the answer leaks a credential", regexp.MustCompile(`(?i)password is`))
+	agent := newScriptedAgent("support", nil, nil, nil, nil, nil, scriptedBy(fake), func(o *agents.AgentOptions) {
+		o.OutputGuardrails = []agents.OutputGuardrail{secrets}
+	})
+
+	out, chunks, err := runCollecting(t, agent, &agents.AgentInput{
+		Message: userMessage("what is the admin password?"),
+	})
+	require.NoError(t, err)
+	requireStatus(t, out, agentstate.RunStatusGuardrailTripped)
+	assert.Equal(t, agents.GuardrailStageOutput, out.Guardrail.Stage)
+	assert.Equal(t, "the answer leaks a credential", out.Guardrail.Reason)
+	assert.Empty(t, out.Output)
+
+	for _, chunk := range chunks {
+		assert.Nil(t, chunk.OfRunCompleted)
+	}
+	require.NotNil(t, chunks[len(chunks)-1].OfRunGuardrailTripped)
+}
@@ -2,8 +2,13 @@ package agents
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
+	"github.com/bytedance/sonic"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
 	"github.com/hastekit/agent-sdk-go/pkg/agents/history"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
 )
 
@@ -15,6 +20,22 @@ type Handoff struct {
 	Name        string
 	Description string
 	Agent       HandoffTarget `json:"-"`
+
+	// InputFilter reshapes the history the target is sent while it runs as
+	// this handoff's target — trimmed, summarized, stripped of tool calls.
+	// Only what the model is sent changes: the thread keeps everything.
+	InputFilter HandoffInputFilter `json:"-"`
+
+	// Payload is the JSON schema of the arguments the model passes with the
+	// transfer. They are checked against it, and reach the target's prompt as
+	// Dependencies.Handoff; a transfer whose arguments do not match is
+	// refused, with the reason, for the model to try again.
+	Payload map[string]any `json:"payload,omitempty"`
+
+	// Return makes the handoff a delegation: once the target finishes, the
+	// agent that handed off takes the run back, with the target's answer in
+	// its history, instead of the target's answer ending the run.
+	Return bool `json:"return,omitempty"`
 }
 
 func NewHandoff(name string, desc string, agent *Agent) *Handoff {
@@ -25,6 +46,58 @@ func NewHandoff(name string, desc string, agent *Agent) *Handoff {
 	}
 }
 
+// HandoffInputFilter returns the messages a handoff's target is sent in
+// place of the run's. It runs before each of the target's model calls; under
+// Temporal and Restate, as a journaled step, so a filter that summarizes with
+// a model is not asked again on replay.
+type HandoffInputFilter func(ctx context.Context, in *HandoffInput) ([]responses.InputMessageUnion, error)
+
+// HandoffInput is what a HandoffInputFilter is given: the handoff being
+// followed and the messages the target would otherwise be sent.
+type HandoffInput struct {
+	Handoff  agentstate.HandoffFrame       `json:"handoff"`
+	Messages []responses.InputMessageUnion `json:"messages"`
+}
+
+// RemoveToolCalls sends the target the conversation without the tool calls
+// made in it: only what the user and the agents said.
+func RemoveToolCalls(ctx context.Context, in *HandoffInput) ([]responses.InputMessageUnion, error) {
+	kept := make([]responses.InputMessageUnion, 0, len(in.Messages))
+	for _, msg := range in.Messages {
+		if msg.OfEasyInput != nil || msg.OfInputMessage != nil || msg.OfOutputMessage != nil {
+			kept = append(kept, msg)
+		}
+	}
+	return kept, nil
+}
+
+// KeepLastMessages sends the target only the last n messages. A tool result
+// whose call was cut off is dropped with it, since a provider rejects one —
+// wherever it falls in the window, as a window can split a batch of parallel
+// calls and keep some of their results but not the calls.
+func KeepLastMessages(n int) HandoffInputFilter {
+	return func(ctx context.Context, in *HandoffInput) ([]responses.InputMessageUnion, error) {
+		msgs := in.Messages
+		if len(msgs) > n {
+			msgs = msgs[len(msgs)-n:]
+		}
+		calls := map[string]bool{}
+		for _, msg := range msgs {
+			if msg.OfFunctionCall != nil {
+				calls[msg.OfFunctionCall.CallID] = true
+			}
+		}
+		kept := make([]responses.InputMessageUnion, 0, len(msgs))
+		for _, msg := range msgs {
+			if msg.OfFunctionCallOutput != nil && !calls[msg.OfFunctionCallOutput.CallID] {
+				continue
+			}
+			kept = append(kept, msg)
+		}
+		return kept, nil
+	}
+}
+
 type HandoffTool struct {
 	*BaseTool
 	agent *Agent
@@ -41,3 +114,82 @@ func NewHandoffTool(t *responses.ToolUnion) *HandoffTool {
 func (t *HandoffTool) Execute(ctx context.Context, params *ToolCall) (*ToolCallResponse, error) {
 	return nil, nil
 }
+
+// handoffToolDescription describes transfer_to_agent, with the arguments each
+// handoff that takes some expects.
+func handoffToolDescription(handoffs []*Handoff) string {
+	var b strings.Builder
+	b.WriteString("Transfer the conversation to another agent")
+	for _, h := range handoffs {
+		if h.Payload == nil {
+			continue
+		}
+		schema, err := sonic.MarshalString(h.Payload)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&b, "\n\nArguments for %s: %s", h.Name, schema)
+	}
+	return b.String()
+}
+
+// enteredVia returns a copy of the agent that knows the handoff it was reached
+// through, and the agent it was reached from, so it can apply the handoff's
+// input filter and hand the run back when it is a delegation.
+func (e *Agent) enteredVia(h *Handoff, caller *Agent) *Agent {
+	clone := *e
+	clone.handoff = h
+	clone.caller = caller
+	return &clone
+}
+
+// handoffArguments checks the arguments of a transfer against the handoff's
+// schema, returning why they do not match.
+func handoffArguments(h *Handoff, param map[string]any) (map[string]any, error) {
+	args, _ := param["arguments"].(map[string]any)
+	if h.Payload == nil {
+		return args, nil
+	}
+
+	v := newOutputValidator(h.Payload)
+	if v == nil {
+		return args, nil
+	}
+	var instance any = args
+	if args == nil {
+		instance = map[string]any{}
+	}
+	if err := v.schema.Validate(instance); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// filterHandoffInput applies the input filter of the handoff this agent was
+// reached through, while the run is inside it.
+func (e *Agent) filterHandoffInput(ctx context.Context, runState *agentstate.RunState, msgs []responses.InputMessageUnion) ([]responses.InputMessageUnion, error) {
+	if e.handoff == nil || e.handoff.InputFilter == nil {
+		return msgs, nil
+	}
+	frame := runState.ActiveHandoff(e.Name)
+	if frame == nil {
+		return msgs, nil
+	}
+	return e.handoff.InputFilter(ctx, &HandoffInput{Handoff: *frame, Messages: msgs})
+}
+
+// handoffReturnMessage tells the agent that delegated that its delegate is
+// done.
+func handoffReturnMessage(target string) responses.InputMessageUnion {
+	text := fmt.Sprintf("%s has finished and handed the conversation back to you. "+
+		"Its answer is above; continue the task from there.", target)
+
+	return responses.InputMessageUnion{
+		OfInputMessage: &responses.InputMessage{
+			Role: constants.RoleUser,
+			Content: responses.InputContent{
+				{OfInputText: &responses.InputTextContent{Text: text}},
+			},
+		},
+	}
+}
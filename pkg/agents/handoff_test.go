@@ -0,0 +1,231 @@
+package agents_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/history"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/streambroker"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/llmtest"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+)
+
+// depsRecorder is a prompt that remembers the dependencies it was given.
+type depsRecorder struct {
+	mu   sync.Mutex
+	deps []*agents.Dependencies
+}
+
+func (p *depsRecorder) GetPrompt(ctx context.Context, deps *agents.Dependencies) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.deps = append(p.deps, deps)
+	return "You are a specialist.", nil
+}
+
+func TestHandoff_InputFilterTrimsWhatTheTargetSees(t *testing.T) {
+	persistence := history.NewInMemoryConversationPersistence()
+	hist := history.NewConversationManager(persistence)
+	broker := streambroker.NewMemoryStreamBroker()
+	source := llmtest.New().
+		CallTool("lookup_order", "{}").
+		CallTool("transfer_to_agent", `{"agent_name":"billing"}`)
+	target := llmtest.New().RespondText("refunded")
+
+	billing := newScriptedAgent("billing", nil, hist, broker, nil, nil, scriptedBy(target))
+	handoff := &agents.Handoff{Name: "billing", Description: "handles billing", Agent: billing, InputFilter: agents.RemoveToolCalls}
+	tools := []agents.Tool{newFakeTool("lookup_order", false, "order A-17, paid")}
+	triage := newScriptedAgent("triage", nil, hist, broker, tools, []*agents.Handoff{handoff}, scriptedBy(source))
+
+	out := runAgent(t, triage, &agents.AgentInput{Namespace: "test", ThreadID: "t1", Message: userMessage("refund my order")})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+
+	sent := target.Request(0).Input.OfInputMessageList
+	assert.Contains(t, messagesText(sent), "refund my order")
+	for _, msg := range sent {
+		assert.Nil(t, msg.OfFunctionCall)
+		assert.Nil(t, msg.OfFunctionCallOutput)
+	}
+
+	// The thread keeps everything.
+	saved, err := persistence.LoadMessages(context.Background(), "test", "t1", out.RunID)
+	require.NoError(t, err)
+	var calls int
+	for _, bundle := range saved {
+		for _, msg := range bundle.Messages {
+			for _, item := range msg.Messages {
+				if item.OfFunctionCall != nil {
+					calls++
+				}
+			}
+		}
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestHandoff_KeepLastMessagesDropsAnOrphanedToolResult(t *testing.T) {
+	call, err := toolCallResponse("call_1", "lookup_order", "{}").Output[0].AsInput()
+	require.NoError(t, err)
+	answer, err := textResponse("it is paid").Output[0].AsInput()
+	require.NoError(t, err)
+
+	msgs, err := agents.KeepLastMessages(2)(context.Background(), &agents.HandoffInput{
+		Messages: []responses.InputMessageUnion{
+			userMessage("refund my order").Messages[0],
+			call,
+			{OfFunctionCallOutput: &responses.FunctionCallOutputMessage{CallID: "call_1"}},
+			answer,
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, "it is paid", strings.TrimSpace(messagesText(msgs)))
+}
+
+// A window that starts inside a batch of parallel calls keeps the results
+// of the calls it has and drops those of the calls it cut off, even behind
+// a call it kept.
+func TestHandoff_KeepLastMessagesSplitsAParallelBatch(t *testing.T) {
+	first, err := toolCallResponse("call_1", "lookup_order", "{}").Output[0].AsInput()
+	require.NoError(t, err)
+	second, err := toolCallResponse("call_2", "lookup_invoice", "{}").Output[0].AsInput()
+	require.NoError(t, err)
+	answer, err := textResponse("it is paid").Output[0].AsInput()
+	require.NoError(t, err)
+
+	msgs, err := agents.KeepLastMessages(4)(context.Background(), &agents.HandoffInput{
+		Messages: []responses.InputMessageUnion{
+			userMessage("refund my order").Messages[0],
+			first,
+			second,
+			{OfFunctionCallOutput: &responses.FunctionCallOutputMessage{CallID: "call_1"}},
+			{OfFunctionCallOutput: &responses.FunctionCallOutputMessage{CallID: "call_2"}},
+			answer,
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, msgs, 3)
+	assert.Equal(t, "call_2", msgs[0].OfFunctionCall.CallID)
+	assert.Equal(t, "call_2", msgs[1].OfFunctionCallOutput.CallID)
+	assert.Equal(t, "it is paid", strings.TrimSpace(messagesText(msgs[2:])))
+}
+
+func TestHandoff_PayloadIsCheckedAndReachesThePrompt(t *testing.T) {
+	hist := history.NewConversationManager(history.NewInMemoryConversationPersistence())
+	broker := streambroker.NewMemoryStreamBroker()
+	source := llmtest.New().
+		CallTool("transfer_to_agent", `{"agent_name":"billing","reason":"refund","arguments":{}}`).
+		CallTool("transfer_to_agent", `{"agent_name":"billing","reason":"refund","arguments":{"order_id":"A-17"}}`)
+	prompt := &depsRecorder{}
+
+	billing := newScriptedAgent("billing", nil, hist, broker, nil, nil, scriptedBy(llmtest.New().RespondText("refunded")), func(o *agents.AgentOptions) {
+		o.Instruction = prompt
+	})
+	handoff := &agents.Handoff{
+		Name:        "billing",
+		Description: "handles billing",
+		Agent:       billing,
+		Payload: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"order_id": map[string]any{"type": "string"}},
+			"required":   []string{"order_id"},
+		},
+	}
+	triage := newScriptedAgent("triage", nil, hist, broker, nil, []*agents.Handoff{handoff}, scriptedBy(source))
+
+	out := runAgent(t, triage, &agents.AgentInput{Message: userMessage("refund my order")})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+
+	// The model was told what the target expects, and refused until it
+	// passed it.
+	tool := source.Request(0).Tools
+	var description string
+	for _, def := range tool {
+		if def.OfFunction != nil && def.OfFunction.Name == "transfer_to_agent" {
+			description = *def.OfFunction.Description
+		}
+	}
+	assert.Contains(t, description, `Arguments for billing: {`)
+	assert.Contains(t, messagesText(source.Request(1).Input.OfInputMessageList), "do not match its schema")
+
+	require.Len(t, prompt.deps, 1)
+	require.NotNil(t, prompt.deps[0].Handoff)
+	assert.Equal(t, "triage", prompt.deps[0].Handoff.Source)
+	assert.Equal(t, "refund", prompt.deps[0].Handoff.Reason)
+	assert.Equal(t, map[string]any{"order_id": "A-17"}, prompt.deps[0].Handoff.Arguments)
+}
+
+func TestHandoff_ReturnHandsTheRunBackToTheCaller(t *testing.T) {
+	hist := history.NewConversationManager(history.NewInMemoryConversationPersistence())
+	broker := streambroker.NewMemoryStreamBroker()
+	source := llmtest.New().
+		CallTool("transfer_to_agent", `{"agent_name":"billing"}`).
+		RespondText("Billing has refunded your order. Anything else?")
+
+	billing := newScriptedAgent("billing", nil, hist, broker, nil, nil, scriptedBy(llmtest.New().RespondText("refund issued for A-17")))
+	handoff := &agents.Handoff{Name: "billing", Description: "handles billing", Agent: billing, Return: true}
+	triage := newScriptedAgent("triage", nil, hist, broker, nil, []*agents.Handoff{handoff}, scriptedBy(source))
+
+	out := runAgent(t, triage, &agents.AgentInput{Message: userMessage("refund my order")})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Equal(t, "Billing has refunded your order. Anything else?", out.Text())
+
+	// The caller carried on from the delegate's answer.
+	back := messagesText(source.Request(1).Input.OfInputMessageList)
+	assert.Contains(t, back, "refund issued for A-17")
+	assert.Contains(t, back, "billing has finished and handed the conversation back to you")
+}
+
+func TestHandoff_ReturnSurvivesAPauseInTheDelegate(t *testing.T) {
+	hist := history.NewConversationManager(history.NewInMemoryConversationPersistence())
+	broker := streambroker.NewMemoryStreamBroker()
+	source := llmtest.New().
+		CallTool("transfer_to_agent", `{"agent_name":"billing"}`).
+		RespondText("All done.")
+	target := llmtest.New().
+		Respond(llmtest.ToolCallWithID("call_refund", "refund", "{}")).
+		RespondText("refund issued")
+	refund := newFakeTool("refund", true, "refunded")
+
+	billing := newScriptedAgent("billing", nil, hist, broker, []agents.Tool{refund}, nil, scriptedBy(target))
+	handoff := &agents.Handoff{Name: "billing", Description: "handles billing", Agent: billing, Return: true}
+	triage := newScriptedAgent("triage", nil, hist, broker, nil, []*agents.Handoff{handoff}, scriptedBy(source))
+
+	out := runAgent(t, triage, &agents.AgentInput{Namespace: "test", ThreadID: "t1", Message: userMessage("refund my order")})
+	requireStatus(t, out, agentstate.RunStatusPaused)
+
+	// Resumed on the agent the run started on, it goes back to the delegate,
+	// and from there back to the caller.
+	out = runAgent(t, triage, &agents.AgentInput{
+		Namespace:     "test",
+		ThreadID:      "t1",
+		PreviousRunID: out.RunID,
+		Message:       approvalMessage([]string{"call_refund"}, nil),
+	})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Equal(t, "All done.", out.Text())
+	assert.Equal(t, 1, refund.callCount())
+	assert.Equal(t, 2, target.Calls())
+	assert.Equal(t, 2, source.Calls())
+}
+
+func TestHandoffFrames_RoundTripThroughMeta(t *testing.T) {
+	state := agentstate.NewRunState()
+	state.Handoffs = []agentstate.HandoffFrame{{
+		Name: "billing", Source: "triage", Target: "billing", Reason: "refund",
+		Arguments: map[string]any{"order_id": "A-17"}, Return: true,
+	}}
+
+	loaded := agentstate.LoadRunStateFromMeta(state.ToMeta())
+	require.NotNil(t, loaded)
+	assert.Equal(t, state.Handoffs, loaded.Handoffs)
+	assert.Equal(t, &loaded.Handoffs[0], loaded.ActiveHandoff("billing"))
+	assert.Nil(t, loaded.ActiveHandoff("triage"))
+}
@@ -143,6 +143,7 @@ type ConversationRunManager struct {
 
 	summarizer         HistorySummarizer
 	summaries          *SummaryResult
+	newSummary         *SummaryResult // taken since TakeNewSummary last asked
 	messageFilter      MessageFilter
 	messageAttribution bool
 
@@ -153,6 +154,11 @@ type ConversationRunManager struct {
 	// to the run that received it, which stops being useful once that run ends
 	// and the message is simply part of the thread's history.
 	steeredIDs map[string]struct{}
+
+	// writtenBy maps the reasoning items (by id) and function calls (by call
+	// id) of the messages GetMessages last returned to the provider recorded
+	// on the reply that carried them.
+	writtenBy map[string]string
 }
 
 func NewRun(ctx context.Context, cm *CommonConversationManager, namespace string, threadID string, previousRunID string, options ...RunOption) (*ConversationRunManager, error) {
@@ -320,9 +326,45 @@ func (cm *ConversationRunManager) GetMessages(ctx context.Context, agentName str
 	if cm.messageFilter != nil {
 		msgList = cm.messageFilter.Filter(ctx, msgList, agentName)
 	}
+
+	cm.writtenBy = map[string]string{}
+	for _, bundle := range msgList {
+		if bundle.Provider == "" {
+			continue
+		}
+		for _, msg := range bundle.Messages {
+			if key := providerStateKey(msg); key != "" {
+				cm.writtenBy[key] = bundle.Provider
+			}
+		}
+	}
+
 	return cm.attributeMessages(msgList, agentName), nil
 }
 
+// WrittenBy is the provider family of the model whose reply carried msg, as
+// recorded when the reply was written, or "" if none was. msg is one of the
+// messages GetMessages last returned; only its reasoning items and function
+// calls, which carry a provider's encrypted state, are known.
+func (cm *ConversationRunManager) WrittenBy(msg responses.InputMessageUnion) string {
+	if key := providerStateKey(msg); key != "" {
+		return cm.writtenBy[key]
+	}
+	return ""
+}
+
+// providerStateKey is the key WrittenBy finds msg by, or "" for a message it
+// does not track.
+func providerStateKey(msg responses.InputMessageUnion) string {
+	switch {
+	case msg.OfReasoning != nil && msg.OfReasoning.ID != "":
+		return "reasoning:" + msg.OfReasoning.ID
+	case msg.OfFunctionCall != nil && msg.OfFunctionCall.CallID != "":
+		return "function_call:" + msg.OfFunctionCall.CallID
+	}
+	return ""
+}
+
 // summarize hands the summarizer the whole outgoing conversation — the history
 // loaded from persistence *and* everything this run has produced so far — and
 // applies what it decides to trim.
@@ -373,6 +415,7 @@ func (cm *ConversationRunManager) summarize(ctx context.Context) error {
 	}
 
 	cm.summaries = result
+	cm.newSummary = result
 
 	inFlight := make(map[string]struct{}, len(cm.newMessages))
 	for _, m := range cm.newMessages {
@@ -408,6 +451,15 @@ func (cm *ConversationRunManager) summarize(ctx context.Context) error {
 	return nil
 }
 
+// TakeNewSummary returns the summary GetMessages took since the last call, or
+// nil if it took none. The agent reports it to its lifecycle hooks; taking it
+// clears it, so each summary is reported once.
+func (cm *ConversationRunManager) TakeNewSummary() *SummaryResult {
+	result := cm.newSummary
+	cm.newSummary = nil
+	return result
+}
+
 // markSteered records that a bundle reached this run mid-flight rather than
 // opening it.
 func (cm *ConversationRunManager) markSteered(m Message) {
@@ -91,6 +91,25 @@ func estimateMessageTokens(msg responses.InputMessageUnion) int {
 		for _, o := range msg.OfCodeInterpreterCall.Outputs {
 			total += textTokens(o.Logs)
 		}
+
+	case msg.OfFileSearchCall != nil:
+		for _, r := range msg.OfFileSearchCall.Results {
+			total += textTokens(r.Text)
+		}
+
+	case msg.OfMcpCall != nil:
+		total += textTokens(msg.OfMcpCall.Arguments)
+		if msg.OfMcpCall.Output != nil {
+			total += textTokens(*msg.OfMcpCall.Output)
+		}
+
+	case msg.OfWebFetchCall != nil:
+		// A fetched PDF is base64; only a text page is counted as text.
+		if msg.OfWebFetchCall.MediaType == "" || msg.OfWebFetchCall.MediaType == "text/plain" {
+			total += textTokens(msg.OfWebFetchCall.Data)
+		} else {
+			total += fileTokens
+		}
 	}
 
 	return total
@@ -0,0 +1,171 @@
+package agents
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents/history"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+)
+
+// LifecycleHook is told about the events of a run that are neither a tool call
+// nor a model call: the run starting and ending, a handoff, an approval asked
+// for and given, a message steered into the run, a summary taken, a stop.
+//
+// It observes and cannot intercept. That is what sets it apart from Hook, and
+// why it is a separate interface rather than more methods on Hook: what it is
+// for — syncing a CRM, counting for analytics, notifying a tenant — has no
+// decision to return, and adding methods to Hook would break every hook
+// already written. Embed NoopLifecycleHook and implement the events you want.
+//
+// An error is logged and the run goes on: an observer that is down must not
+// take the run with it. A hook that wants its work retried retries it itself.
+//
+// Under Temporal and Restate each method runs as its own journaled step, like
+// a Hook's, so a replayed run does not notify twice.
+type LifecycleHook interface {
+	GetName() string
+
+	// OnRunStart and OnRunEnd bracket each time a run is executed — once
+	// for a run that completes in one go, and once more for each resume of a
+	// run that paused for approval. A paused run ends with status paused.
+	// They belong to the agent the run was started on, whichever agent it
+	// ends on after a handoff.
+	OnRunStart(ctx context.Context, event *RunStartEvent) error
+	OnRunEnd(ctx context.Context, event *RunEndEvent) error
+
+	// The rest are the running agent's: a handoff is reported by the agent
+	// handing off, and what happens after it by the agent handed to.
+	OnHandoff(ctx context.Context, event *HandoffEvent) error
+	OnApprovalRequested(ctx context.Context, event *ApprovalRequestedEvent) error
+	OnApprovalResolved(ctx context.Context, event *ApprovalResolvedEvent) error
+	OnMessageQueued(ctx context.Context, event *MessageQueuedEvent) error
+	OnSummarized(ctx context.Context, event *SummarizedEvent) error
+	OnStopRequested(ctx context.Context, event *StopRequestedEvent) error
+}
+
+// RunInfo says which run an event belongs to. Every event carries it.
+type RunInfo struct {
+	AgentName  string         `json:"agent_name"`
+	Namespace  string         `json:"namespace"`
+	ThreadID   string         `json:"thread_id"`
+	SessionID  string         `json:"session_id"`
+	RunID      string         `json:"run_id"`
+	RunContext map[string]any `json:"run_context,omitempty"`
+}
+
+type RunStartEvent struct {
+	RunInfo
+	Message history.Message `json:"message"`
+}
+
+// RunEndEvent carries what the run returned. Output is nil only when the run
+// failed before it had one; Error is the failure, if any.
+type RunEndEvent struct {
+	RunInfo
+	Output *AgentOutput `json:"output,omitempty"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// HandoffEvent reports the handoff a run took: the last transfer_to_agent
+// call of its turn that found a target. Source and Target are the agents'
+// names, as the run's handoff frames record them. Reason is what the model
+// gave, which it may not have.
+type HandoffEvent struct {
+	RunInfo
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type ApprovalRequestedEvent struct {
+	RunInfo
+	Interrupts []responses.Interrupt `json:"interrupts"`
+}
+
+// ApprovalResolvedEvent carries the answers a resumed run was given.
+type ApprovalResolvedEvent struct {
+	RunInfo
+	Resolutions []responses.InterruptResolution `json:"resolutions"`
+}
+
+// MessageQueuedEvent carries the messages the user sent while the run was
+// working, as the run picks them up.
+type MessageQueuedEvent struct {
+	RunInfo
+	Messages []history.Message `json:"messages"`
+}
+
+// SummarizedEvent reports that the conversation was summarized before a
+// model call. Summary is nil when the summarizer only dropped messages.
+type SummarizedEvent struct {
+	RunInfo
+	SummaryID           string           `json:"summary_id"`
+	LastSummarizedRunID string           `json:"last_summarized_run_id"`
+	Summary             *history.Message `json:"summary,omitempty"`
+	MessagesKept        int              `json:"messages_kept"`
+}
+
+// StopRequestedEvent reports a stop the run has honoured; the run ends with
+// status completed right after.
+type StopRequestedEvent struct {
+	RunInfo
+}
+
+// NoopLifecycleHook implements every LifecycleHook event, doing nothing.
+// Embed it and implement the events you want.
+type NoopLifecycleHook struct{}
+
+func (NoopLifecycleHook) OnRunStart(context.Context, *RunStartEvent) error { return nil }
+func (NoopLifecycleHook) OnRunEnd(context.Context, *RunEndEvent) error     { return nil }
+func (NoopLifecycleHook) OnHandoff(context.Context, *HandoffEvent) error   { return nil }
+func (NoopLifecycleHook) OnApprovalRequested(context.Context, *ApprovalRequestedEvent) error {
+	return nil
+}
+func (NoopLifecycleHook) OnApprovalResolved(context.Context, *ApprovalResolvedEvent) error {
+	return nil
+}
+func (NoopLifecycleHook) OnMessageQueued(context.Context, *MessageQueuedEvent) error { return nil }
+func (NoopLifecycleHook) OnSummarized(context.Context, *SummarizedEvent) error       { return nil }
+func (NoopLifecycleHook) OnStopRequested(context.Context, *StopRequestedEvent) error { return nil }
+
+// runInfo is the RunInfo for events of this agent's run.
+func (e *Agent) runInfo(in *AgentInput, runId string) RunInfo {
+	return RunInfo{
+		AgentName:  e.Name,
+		Namespace:  in.Namespace,
+		ThreadID:   in.ThreadID,
+		SessionID:  in.SessionID,
+		RunID:      runId,
+		RunContext: in.RunContext,
+	}
+}
+
+// notify tells each of the agent's lifecycle hooks about an event, in the
+// order they were configured. An error is logged, not returned.
+func (e *Agent) notify(ctx context.Context, event string, fn func(LifecycleHook) error) {
+	for _, hook := range e.lifecycleHooks {
+		if hook == nil {
+			continue
+		}
+		if err := fn(hook); err != nil {
+			slog.WarnContext(ctx, "lifecycle hook failed",
+				slog.String("agent", e.Name), slog.String("hook", hook.GetName()),
+				slog.String("event", event), slog.Any("error", err))
+		}
+	}
+}
+
+// interruptResolutions collects the approval answers in a bundle of
+// messages.
+func interruptResolutions(msgs ...history.Message) []responses.InterruptResolution {
+	var out []responses.InterruptResolution
+	for _, m := range msgs {
+		for _, msg := range m.Messages {
+			if msg.OfFunctionCallInterruptResolution != nil {
+				out = append(out, msg.OfFunctionCallInterruptResolution.Resolutions...)
+			}
+		}
+	}
+	return out
+}
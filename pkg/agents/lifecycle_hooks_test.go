@@ -0,0 +1,243 @@
+package agents_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/history"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/streambroker"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/llmtest"
+)
+
+// recordingHook writes down each event it is told about, one line per event.
+type recordingHook struct {
+	agents.NoopLifecycleHook
+	mu     sync.Mutex
+	events []string
+	ends   []*agents.RunEndEvent
+	fail   bool
+}
+
+func (h *recordingHook) GetName() string { return "recorder" }
+
+func (h *recordingHook) record(event string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, event)
+	if h.fail {
+		return errors.New("crm unavailable")
+	}
+	return nil
+}
+
+func (h *recordingHook) recorded() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.events...)
+}
+
+func (h *recordingHook) OnRunStart(ctx context.Context, event *agents.RunStartEvent) error {
+	return h.record("run_start " + event.AgentName)
+}
+
+func (h *recordingHook) OnRunEnd(ctx context.Context, event *agents.RunEndEvent) error {
+	h.mu.Lock()
+	h.ends = append(h.ends, event)
+	h.mu.Unlock()
+	return h.record("run_end " + string(event.Output.Status))
+}
+
+func (h *recordingHook) OnHandoff(ctx context.Context, event *agents.HandoffEvent) error {
+	return h.record("handoff " + event.Source + " -> " + event.Target + ": " + event.Reason)
+}
+
+func (h *recordingHook) OnApprovalRequested(ctx context.Context, event *agents.ApprovalRequestedEvent) error {
+	return h.record("approval_requested " + event.Interrupts[0].FunctionCallMessage.CallID)
+}
+
+func (h *recordingHook) OnApprovalResolved(ctx context.Context, event *agents.ApprovalResolvedEvent) error {
+	return h.record("approval_resolved " + event.Resolutions[0].CallID + " " + string(event.Resolutions[0].Action))
+}
+
+func (h *recordingHook) OnMessageQueued(ctx context.Context, event *agents.MessageQueuedEvent) error {
+	return h.record("message_queued " + strings.TrimSpace(messagesText(event.Messages[0].Messages)))
+}
+
+func (h *recordingHook) OnSummarized(ctx context.Context, event *agents.SummarizedEvent) error {
+	return h.record("summarized " + event.SummaryID)
+}
+
+func (h *recordingHook) OnStopRequested(ctx context.Context, event *agents.StopRequestedEvent) error {
+	return h.record("stop_requested")
+}
+
+func TestLifecycleHooks_RunAndHandoff(t *testing.T) {
+	hist := history.NewConversationManager(history.NewInMemoryConversationPersistence())
+	broker := streambroker.NewMemoryStreamBroker()
+	hook := &recordingHook{}
+
+	billing := newScriptedAgent("billing", nil, hist, broker, nil, nil, scriptedBy(llmtest.New().RespondText("refunded")))
+	handoffs := []*agents.Handoff{agents.NewHandoff("billing", "handles billing", billing)}
+	fake := llmtest.New().CallTool("transfer_to_agent", `{"agent_name":"billing","reason":"refund request"}`)
+	triage := newScriptedAgent("triage", nil, hist, broker, nil, handoffs, scriptedBy(fake), func(o *agents.AgentOptions) {
+		o.LifecycleHooks = []agents.LifecycleHook{hook}
+	})
+
+	out := runAgent(t, triage, &agents.AgentInput{Namespace: "test", ThreadID: "t1", Message: userMessage("refund me")})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+
+	// The run's ends are the starting agent's to report, though it finished
+	// on the one it handed off to.
+	assert.Equal(t, []string{
+		"run_start triage",
+		"handoff triage -> billing: refund request",
+		"run_end completed",
+	}, hook.recorded())
+	assert.Equal(t, out.RunID, hook.ends[0].RunID)
+	assert.Equal(t, "refunded", hook.ends[0].Output.Text())
+}
+
+// Only the handoff the turn took is reported, and by the names of the agents
+// rather than of the handoff.
+func TestLifecycleHooks_HandoffReportsTheTransferTaken(t *testing.T) {
+	hist := history.NewConversationManager(history.NewInMemoryConversationPersistence())
+	broker := streambroker.NewMemoryStreamBroker()
+	hook := &recordingHook{}
+
+	billing := newScriptedAgent("billing", nil, hist, broker, nil, nil, scriptedBy(llmtest.New().RespondText("refunded")))
+	sales := newScriptedAgent("sales", nil, hist, broker, nil, nil, scriptedBy(llmtest.New().RespondText("sold")))
+	handoffs := []*agents.Handoff{
+		agents.NewHandoff("sales_desk", "handles sales", sales),
+		agents.NewHandoff("billing_desk", "handles billing", billing),
+	}
+
+	fake := llmtest.New().Respond(
+		llmtest.ToolCall("transfer_to_agent", `{"agent_name":"sales_desk","reason":"upsell"}`),
+		llmtest.ToolCall("transfer_to_agent", `{"agent_name":"billing_desk","reason":"refund request"}`),
+	)
+	triage := newScriptedAgent("triage", nil, hist, broker, nil, handoffs, scriptedBy(fake), func(o *agents.AgentOptions) {
+		o.LifecycleHooks = []agents.LifecycleHook{hook}
+	})
+
+	out := runAgent(t, triage, &agents.AgentInput{Namespace: "test", ThreadID: "t1", Message: userMessage("refund me")})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Equal(t, "refunded", out.Text())
+
+	assert.Equal(t, []string{
+		"run_start triage",
+		"handoff triage -> billing: refund request",
+		"run_end completed",
+	}, hook.recorded())
+}
+
+func TestLifecycleHooks_ApprovalRequestedAndResolved(t *testing.T) {
+	hist := history.NewConversationManager(history.NewInMemoryConversationPersistence())
+	hook := &recordingHook{}
+	fake := llmtest.New().
+		Respond(llmtest.ToolCallWithID("call_danger", "dangerous", "{}")).
+		RespondText("done")
+	tools := []agents.Tool{newFakeTool("dangerous", true, "dangerous done")}
+	agent := newScriptedAgent("main", nil, hist, nil, tools, nil, scriptedBy(fake), func(o *agents.AgentOptions) {
+		o.LifecycleHooks = []agents.LifecycleHook{hook}
+	})
+
+	out := runAgent(t, agent, &agents.AgentInput{Namespace: "test", ThreadID: "t1", Message: userMessage("do it")})
+	requireStatus(t, out, agentstate.RunStatusPaused)
+
+	out = runAgent(t, agent, &agents.AgentInput{
+		Namespace:     "test",
+		ThreadID:      "t1",
+		PreviousRunID: out.RunID,
+		Message:       approvalMessage([]string{"call_danger"}, nil),
+	})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+
+	assert.Equal(t, []string{
+		"run_start main",
+		"approval_requested call_danger",
+		"run_end paused",
+		"run_start main",
+		"approval_resolved call_danger approve",
+		"run_end completed",
+	}, hook.recorded())
+}
+
+func TestLifecycleHooks_SteeringAndStop(t *testing.T) {
+	const streamID = "hooked-stream"
+	broker := streambroker.NewMemoryStreamBroker()
+	hook := &recordingHook{}
+
+	gather := newFakeTool("gather", false, "gather done")
+	innerExecute := gather.execute
+	gather.execute = func(ctx context.Context, params *agents.ToolCall) (*agents.ToolCallResponse, error) {
+		if err := broker.EnqueueMessage(ctx, streamID, userMessage("also check the invoices")); err != nil {
+			return nil, err
+		}
+		if err := broker.Stop(ctx, streamID); err != nil {
+			return nil, err
+		}
+		return innerExecute(ctx, params)
+	}
+	fake := llmtest.New().CallTool("gather", "{}")
+	agent := newScriptedAgent("main", nil, nil, broker, []agents.Tool{gather}, nil, scriptedBy(fake), func(o *agents.AgentOptions) {
+		o.LifecycleHooks = []agents.LifecycleHook{hook}
+	})
+
+	out := runAgent(t, agent, &agents.AgentInput{StreamID: streamID, Message: userMessage("start")})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+
+	assert.Equal(t, []string{
+		"run_start main",
+		"message_queued also check the invoices",
+		"stop_requested",
+		"run_end completed",
+	}, hook.recorded())
+}
+
+// summarizeOnce summarizes the conversation on the first call only.
+type summarizeOnce struct{ calls int }
+
+func (s *summarizeOnce) Summarize(ctx context.Context, msgIdToRunId map[string]string, msgs []history.Message, contextTokens int) (*history.SummaryResult, error) {
+	s.calls++
+	if s.calls > 1 {
+		return nil, nil
+	}
+	summary := userMessage("earlier: the user asked for a refund")
+	return &history.SummaryResult{Summary: &summary, SummaryID: "sum_1", MessagesToKeep: msgs}, nil
+}
+
+func TestLifecycleHooks_SummarizedOncePerSummary(t *testing.T) {
+	hist := history.NewConversationManager(history.NewInMemoryConversationPersistence(), history.WithSummarizer(&summarizeOnce{}))
+	hook := &recordingHook{}
+	fake := llmtest.New().CallTool("lookup", "{}").RespondText("done")
+	tools := []agents.Tool{newFakeTool("lookup", false, "found")}
+	agent := newScriptedAgent("main", nil, hist, nil, tools, nil, scriptedBy(fake), func(o *agents.AgentOptions) {
+		o.LifecycleHooks = []agents.LifecycleHook{hook}
+	})
+
+	out := runAgent(t, agent, &agents.AgentInput{Message: userMessage("hi")})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+
+	// Two model calls, one summary.
+	assert.Equal(t, []string{"run_start main", "summarized sum_1", "run_end completed"}, hook.recorded())
+}
+
+func TestLifecycleHooks_ErrorDoesNotFailTheRun(t *testing.T) {
+	hook := &recordingHook{fail: true}
+	agent := newScriptedAgent("main", nil, nil, nil, nil, nil, scriptedBy(llmtest.New().RespondText("hello")), func(o *agents.AgentOptions) {
+		o.LifecycleHooks = []agents.LifecycleHook{hook}
+	})
+
+	out := runAgent(t, agent, &agents.AgentInput{Message: userMessage("hi")})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Equal(t, "hello", out.Text())
+	require.Len(t, hook.recorded(), 2)
+}
@@ -2,6 +2,7 @@ package agents
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
@@ -12,10 +13,33 @@ type LLM interface {
 	NewStreamingResponses(ctx context.Context, in *responses.Request, cb func(chunk *responses.ResponseChunk)) (*responses.Response, error)
 }
 
+// BackgroundLLM is an LLM that can reattach to a response its provider runs
+// in the background (Parameters.Background). The agent loop uses it to pick
+// an in-flight response back up when a run resumes after a restart, rather
+// than asking the model again, and to cancel the response when the run is
+// stopped.
+type BackgroundLLM interface {
+	LLM
+	// ResumeStreamingResponses streams the response's events from after
+	// in.SequenceNumber and folds them into a response, as
+	// NewStreamingResponses does. in.Model is the model the response was
+	// started with, which routes the request to its provider.
+	ResumeStreamingResponses(ctx context.Context, in *responses.StreamResponseFromRequest, cb func(chunk *responses.ResponseChunk)) (*responses.Response, error)
+	CancelResponse(ctx context.Context, in *responses.CancelResponseRequest) error
+}
+
 type WrappedLLM struct {
 	llm llm.Provider
 }
 
+var _ BackgroundLLM = (*WrappedLLM)(nil)
+
+// ProviderName is the provider family of the wrapped provider, or "" if it
+// does not say.
+func (l *WrappedLLM) ProviderName() llm.ProviderName {
+	return providerFamily(l.llm)
+}
+
 func (l *WrappedLLM) NewStreamingResponses(ctx context.Context, in *responses.Request, cb func(chunk *responses.ResponseChunk)) (*responses.Response, error) {
 	acc := Accumulator{}
 
@@ -27,6 +51,33 @@ func (l *WrappedLLM) NewStreamingResponses(ctx context.Context, in *responses.Re
 	return acc.ReadStream(ctx, stream, cb)
 }
 
+// ResumeStreamingResponses reattaches through the provider's
+// responses.BackgroundProvider; it fails for providers without one.
+func (l *WrappedLLM) ResumeStreamingResponses(ctx context.Context, in *responses.StreamResponseFromRequest, cb func(chunk *responses.ResponseChunk)) (*responses.Response, error) {
+	bp, ok := l.llm.(responses.BackgroundProvider)
+	if !ok {
+		return nil, fmt.Errorf("%T cannot reattach to background responses", l.llm)
+	}
+
+	stream, err := bp.StreamResponseFrom(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	acc := Accumulator{}
+	return acc.ReadStream(ctx, stream, cb)
+}
+
+func (l *WrappedLLM) CancelResponse(ctx context.Context, in *responses.CancelResponseRequest) error {
+	bp, ok := l.llm.(responses.BackgroundProvider)
+	if !ok {
+		return fmt.Errorf("%T cannot cancel background responses", l.llm)
+	}
+
+	_, err := bp.CancelResponse(ctx, in)
+	return err
+}
+
 type Accumulator struct {
 }
 
@@ -39,10 +90,14 @@ type Accumulator struct {
 // writes its cancellation notice instead. The unread remainder is drained in
 // the background so the provider's sender is never left blocked on a channel
 // nobody is reading.
+//
+// An error event fails the call the same way: the stream broke off, and what
+// came before it is not an answer.
 func (a *Accumulator) ReadStream(ctx context.Context, stream chan *responses.ResponseChunk, cb func(chunk *responses.ResponseChunk)) (*responses.Response, error) {
 	// Process stream
 	finalOutput := []responses.OutputMessageUnion{}
 	var usage *responses.Usage
+	var model string
 	for {
 		var chunk *responses.ResponseChunk
 		var open bool
@@ -59,7 +114,7 @@ func (a *Accumulator) ReadStream(ctx context.Context, stream chan *responses.Res
 				if ctx.Err() != nil {
 					return nil, ErrModelCallStopped
 				}
-				return &responses.Response{Output: finalOutput, Usage: usage}, nil
+				return &responses.Response{Model: model, Output: finalOutput, Usage: usage}, nil
 			}
 		case <-ctx.Done():
 			go drain(stream)
@@ -67,6 +122,11 @@ func (a *Accumulator) ReadStream(ctx context.Context, stream chan *responses.Res
 		}
 
 		cb(chunk)
+		if chunk.OfError != nil {
+			go drain(stream)
+			return nil, fmt.Errorf("model stream failed: %s", chunk.OfError.Message)
+		}
+
 		switch chunk.ChunkType() {
 		case "response.output_item.done":
 			if chunk.OfOutputItemDone.Item.Type == "message" {
@@ -150,12 +210,105 @@ func (a *Accumulator) ReadStream(ctx context.Context, stream chan *responses.Res
 				})
 			}
 
+			// Calls of the provider-hosted tools are kept so the next turn
+			// replays them to the provider.
+			if hostedCall, ok := hostedToolCallOutput(&chunk.OfOutputItemDone.Item); ok {
+				finalOutput = append(finalOutput, hostedCall)
+			}
+
+		case "response.created":
+			model = chunk.OfResponseCreated.Response.Model
+
 		case "response.completed":
 			usage = &chunk.OfResponseCompleted.Response.Usage
+			if chunk.OfResponseCompleted.Response.Model != "" {
+				model = chunk.OfResponseCompleted.Response.Model
+			}
 		}
 	}
 }
 
+// hostedToolCallOutput converts a finished file search, remote MCP, web
+// fetch, URL context or Google Maps item into its output message.
+func hostedToolCallOutput(item *responses.ChunkOutputItemData) (responses.OutputMessageUnion, bool) {
+	switch item.Type {
+	case "file_search_call":
+		return responses.OutputMessageUnion{
+			OfFileSearchCall: &responses.FileSearchCallMessage{
+				ID:      item.Id,
+				Status:  item.Status,
+				Queries: item.Queries,
+				Results: item.Results,
+			},
+		}, true
+
+	case "mcp_list_tools":
+		return responses.OutputMessageUnion{
+			OfMcpListTools: &responses.McpListToolsMessage{
+				ID:          item.Id,
+				ServerLabel: deref(item.ServerLabel),
+				Tools:       item.Tools,
+				Error:       item.Error,
+			},
+		}, true
+
+	case "mcp_call":
+		return responses.OutputMessageUnion{
+			OfMcpCall: &responses.McpCallMessage{
+				ID:          item.Id,
+				Status:      item.Status,
+				ServerLabel: deref(item.ServerLabel),
+				Name:        deref(item.Name),
+				Arguments:   deref(item.Arguments),
+				Output:      item.Output,
+				Error:       item.Error,
+			},
+		}, true
+
+	case "web_fetch_call":
+		return responses.OutputMessageUnion{
+			OfWebFetchCall: &responses.WebFetchCallMessage{
+				ID:          item.Id,
+				Status:      item.Status,
+				URL:         deref(item.URL),
+				Title:       deref(item.Title),
+				MediaType:   deref(item.MediaType),
+				Data:        deref(item.Data),
+				RetrievedAt: deref(item.RetrievedAt),
+				ErrorCode:   deref(item.ErrorCode),
+			},
+		}, true
+
+	case "url_context_call":
+		return responses.OutputMessageUnion{
+			OfURLContextCall: &responses.URLContextCallMessage{
+				ID:     item.Id,
+				Status: item.Status,
+				URLs:   item.URLs,
+			},
+		}, true
+
+	case "google_maps_call":
+		return responses.OutputMessageUnion{
+			OfGoogleMapsCall: &responses.GoogleMapsCallMessage{
+				ID:                 item.Id,
+				Status:             item.Status,
+				Places:             item.Places,
+				WidgetContextToken: item.WidgetContextToken,
+			},
+		}, true
+	}
+
+	return responses.OutputMessageUnion{}, false
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 // drain reads a stream to its end and discards it, so a provider still writing
 // into a channel nobody reads is released rather than blocked forever.
 func drain(stream chan *responses.ResponseChunk) {
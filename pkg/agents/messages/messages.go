@@ -12,6 +12,12 @@ type Message struct {
 	ID       string                        `json:"id" db:"id"`
 	SenderID string                        `json:"sender_id" db:"sender_id"`
 	Messages []responses.InputMessageUnion `json:"messages" db:"messages"`
+
+	// Provider is the provider family of the model whose reply the bundle
+	// is, recorded when the reply is written; "" for any other bundle. The
+	// reasoning items and thought signatures in a reply are that provider's
+	// encrypted state, which a model of another family is not sent.
+	Provider string `json:"provider,omitempty" db:"provider"`
 }
 
 func New(senderID string, messages []responses.InputMessageUnion) Message {
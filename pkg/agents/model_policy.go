@@ -0,0 +1,328 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/history"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+)
+
+// ModelPolicy says which other models an agent may call, and when. Where
+// WithLLM swaps the model for every run, a policy changes it turn by turn:
+//
+//   - Fallbacks stand in for a single turn whose call failed, tried in order.
+//     The next turn goes back to the run's model.
+//   - Escalation moves the rest of the run to a stronger model once one of
+//     its triggers fires. The move is recorded in the run's state, so a
+//     replay or a resumed run calls the model the original did.
+//
+// A turn goes without the reasoning items and thought signatures that a
+// model of another provider family wrote into its history: they are that
+// provider's encrypted state, which another cannot read and may reject. Each
+// reply is recorded under the family of the model that wrote it — the one its
+// provider names, or else the one its model name resolves to.
+type ModelPolicy struct {
+	Fallbacks  []llm.Provider
+	Escalation *ModelEscalation
+}
+
+// ModelEscalation is the stronger model a run moves to, and what moves it.
+// Triggers left unset never fire; a run escalates at most once.
+type ModelEscalation struct {
+	LLM llm.Provider
+
+	// AfterToolErrors escalates once this many tool calls in a row have
+	// failed.
+	AfterToolErrors int
+
+	// OnInvalidOutput escalates when an answer does not match the agent's
+	// output schema. The repair turn that follows is made on the stronger
+	// model, even if the agent's MaxOutputRepairs were used up.
+	OnInvalidOutput bool
+
+	// OnUnsure gives the model a tool, escalate_to_stronger_model, to call
+	// when it is not confident it can answer well.
+	OnUnsure bool
+}
+
+// Reasons recorded in agentstate.Escalation.
+const (
+	EscalationReasonToolErrors    = "tool_errors"
+	EscalationReasonInvalidOutput = "invalid_output"
+	EscalationReasonUnsure        = "unsure"
+)
+
+const escalationToolName = "escalate_to_stronger_model"
+
+// modelTier is one of the models an agent may call, with the provider family
+// that decides whether its history needs stripping.
+type modelTier struct {
+	llm    LLM
+	family llm.ProviderName
+}
+
+// providerFamily names the provider behind p, or "" when it cannot say.
+func providerFamily(p any) llm.ProviderName {
+	if named, ok := p.(interface{ ProviderName() llm.ProviderName }); ok {
+		return named.ProviderName()
+	}
+	return ""
+}
+
+// newModelTiers wraps a policy's providers for the loop to call.
+func newModelTiers(policy *ModelPolicy) (fallbacks []modelTier, escalation *modelTier) {
+	if policy == nil {
+		return nil, nil
+	}
+
+	for _, p := range policy.Fallbacks {
+		if p == nil {
+			continue
+		}
+		fallbacks = append(fallbacks, modelTier{llm: &WrappedLLM{p}, family: providerFamily(p)})
+	}
+
+	if policy.Escalation != nil && policy.Escalation.LLM != nil {
+		escalation = &modelTier{llm: &WrappedLLM{policy.Escalation.LLM}, family: providerFamily(policy.Escalation.LLM)}
+	}
+
+	return fallbacks, escalation
+}
+
+// WithPolicyLLMs returns a copy of the agent whose model policy calls these
+// in place of its fallback and escalation providers, in the same order. It is
+// WithLLM for the policy: how the durable runtimes put their journaled
+// proxies in place. The providers' families still decide what is stripped.
+func (e *Agent) WithPolicyLLMs(fallbacks []LLM, escalation LLM) *Agent {
+	clone := *e
+
+	clone.fallbacks = make([]modelTier, len(e.fallbacks))
+	for i, tier := range e.fallbacks {
+		if i < len(fallbacks) {
+			tier.llm = fallbacks[i]
+		}
+		clone.fallbacks[i] = tier
+	}
+
+	if e.escalationModel != nil && escalation != nil {
+		clone.escalationModel = &modelTier{llm: escalation, family: e.escalationModel.family}
+	}
+
+	return &clone
+}
+
+// turnModel is the model this turn is made on: the stronger one once the run
+// has escalated, the agent's own until then.
+func (e *Agent) turnModel(runState *agentstate.RunState) modelTier {
+	if esc := runState.Escalation; esc != nil && esc.AgentName == e.Name && e.escalationModel != nil {
+		return *e.escalationModel
+	}
+	return modelTier{llm: e.llm, family: e.llmFamily}
+}
+
+// callWithFallbacks makes the turn's call, and on failure makes it again on
+// each fallback in turn. A stop is not a failure and is never retried. It
+// returns the provider family of the model that answered, for the reply to be
+// recorded under.
+//
+// Whatever the failed call had streamed has already reached the client; the
+// fallback's answer follows it.
+func (e *Agent) callWithFallbacks(ctx context.Context, run *history.ConversationRunManager, model modelTier, request *responses.Request, publish func(*responses.ResponseChunk)) (*responses.Response, llm.ProviderName, error) {
+	answeredBy := model
+	resp, err := e.callLLM(ctx, model.llm, run, e.requestFor(run, model, request), publish)
+
+	for i, fallback := range e.fallbacks {
+		if err == nil || errors.Is(err, ErrModelCallStopped) || ctx.Err() != nil {
+			break
+		}
+
+		slog.WarnContext(ctx, "model call failed, retrying the turn on a fallback",
+			slog.String("agent", e.Name), slog.Int("fallback", i), slog.Any("error", err))
+
+		// A background response belongs to the model that failed.
+		run.RunState.BackgroundResponse = nil
+		answeredBy = fallback
+		resp, err = e.callLLM(ctx, fallback.llm, run, e.requestFor(run, fallback, request), publish)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	family := answeredBy.family
+	if family == "" {
+		family = modelFamily(resp.Model)
+	}
+	return resp, family, nil
+}
+
+// modelFamilies are the families behind the model names providers report,
+// by prefix.
+var modelFamilies = []struct {
+	prefix string
+	family llm.ProviderName
+}{
+	{"gpt-", llm.ProviderNameOpenAI},
+	{"chatgpt-", llm.ProviderNameOpenAI},
+	{"o1", llm.ProviderNameOpenAI},
+	{"o3", llm.ProviderNameOpenAI},
+	{"o4", llm.ProviderNameOpenAI},
+	{"claude-", llm.ProviderNameAnthropic},
+	{"gemini-", llm.ProviderNameGemini},
+	{"grok-", llm.ProviderNameXAI},
+	{"deepseek-", llm.ProviderNameDeepSeek},
+	{"kimi-", llm.ProviderNameMoonshot},
+	{"moonshot-", llm.ProviderNameMoonshot},
+	{"glm-", llm.ProviderNameZAI},
+}
+
+// modelFamily resolves the provider family from a model's name, for a model
+// whose provider does not name its own: a gateway client routed by model
+// string, or any other. A "Provider/model" name says so outright; a bare one
+// is matched against the names the families' models go by. It is "" when the
+// name gives no clue.
+func modelFamily(model string) llm.ProviderName {
+	if provider, _, ok := strings.Cut(model, "/"); ok {
+		for _, m := range modelFamilies {
+			if strings.EqualFold(provider, string(m.family)) {
+				return m.family
+			}
+		}
+	}
+
+	for _, m := range modelFamilies {
+		if strings.HasPrefix(model, m.prefix) {
+			return m.family
+		}
+	}
+	return ""
+}
+
+// requestFor is the request as model should see it. Each reasoning item and
+// thought signature in the history is the encrypted state of the model that
+// wrote it, and model is not sent those another family wrote. A reply with no
+// family recorded is taken to be the agent's own model's; a model whose family
+// is not known is sent everything.
+func (e *Agent) requestFor(run *history.ConversationRunManager, model modelTier, request *responses.Request) *responses.Request {
+	if model.family == "" {
+		return request
+	}
+
+	foreign := func(msg responses.InputMessageUnion) bool {
+		family := llm.ProviderName(run.WrittenBy(msg))
+		if family == "" {
+			family = e.llmFamily
+		}
+		return family != "" && family != model.family
+	}
+	if !slices.ContainsFunc(request.Input.OfInputMessageList, func(msg responses.InputMessageUnion) bool {
+		return carriesProviderState(msg) && foreign(msg)
+	}) {
+		return request
+	}
+
+	stripped := *request
+	stripped.Input.OfInputMessageList = withoutProviderState(request.Input.OfInputMessageList, foreign)
+	return &stripped
+}
+
+// carriesProviderState reports whether msg holds a provider's encrypted
+// state: a reasoning item, or a function call with a thought signature.
+func carriesProviderState(msg responses.InputMessageUnion) bool {
+	return msg.OfReasoning != nil || (msg.OfFunctionCall != nil && msg.OfFunctionCall.ThoughtSignature != nil)
+}
+
+// withoutProviderState drops the reasoning items and clears the thought
+// signatures of the messages strip picks. It copies what it changes: the
+// messages are history's own.
+func withoutProviderState(msgs []responses.InputMessageUnion, strip func(responses.InputMessageUnion) bool) []responses.InputMessageUnion {
+	kept := make([]responses.InputMessageUnion, 0, len(msgs))
+	for _, msg := range msgs {
+		if !carriesProviderState(msg) || !strip(msg) {
+			kept = append(kept, msg)
+			continue
+		}
+		if msg.OfReasoning != nil {
+			continue
+		}
+		call := *msg.OfFunctionCall
+		call.ThoughtSignature = nil
+		msg.OfFunctionCall = &call
+		kept = append(kept, msg)
+	}
+	return kept
+}
+
+// escalate moves the rest of the run to the agent's stronger model, if it
+// has one and the run has not already moved. It reports whether it did.
+func (e *Agent) escalate(ctx context.Context, runState *agentstate.RunState, reason string) bool {
+	if e.escalationModel == nil || (runState.Escalation != nil && runState.Escalation.AgentName == e.Name) {
+		return false
+	}
+
+	slog.InfoContext(ctx, "escalating to the stronger model", slog.String("agent", e.Name), slog.String("reason", reason))
+	runState.Escalation = &agentstate.Escalation{
+		AgentName:     e.Name,
+		Reason:        reason,
+		LoopIteration: runState.LoopIteration,
+	}
+	return true
+}
+
+// trackToolErrors counts a turn's failed tool calls toward AfterToolErrors,
+// and escalates once there have been enough in a row.
+func (e *Agent) trackToolErrors(ctx context.Context, runState *agentstate.RunState, failed []bool) {
+	for _, f := range failed {
+		if f {
+			runState.ConsecutiveToolErrors++
+		} else {
+			runState.ConsecutiveToolErrors = 0
+		}
+	}
+
+	policy := e.escalationPolicy
+	if policy != nil && policy.AfterToolErrors > 0 && runState.ConsecutiveToolErrors >= policy.AfterToolErrors {
+		e.escalate(ctx, runState, EscalationReasonToolErrors)
+	}
+}
+
+// PrepareEscalationTool is the tool a model calls to escalate, for a policy
+// that lets it. Like transfer_to_agent it calls out to nothing: the loop
+// handles it.
+func (e *Agent) PrepareEscalationTool() []Tool {
+	if e.escalationModel == nil || e.escalationPolicy == nil || !e.escalationPolicy.OnUnsure {
+		return nil
+	}
+
+	return []Tool{NewHandoffTool(&responses.ToolUnion{
+		OfFunction: &responses.FunctionTool{
+			Name: escalationToolName,
+			Description: utils.Ptr("Hand the rest of this task to a stronger model. Call this when you are not confident " +
+				"you can answer correctly — the task is beyond you, or your attempts keep failing — rather than guessing."),
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"reason": map[string]any{
+						"type":        "string",
+						"description": "Why you are unsure",
+					},
+				},
+				"required": []string{"reason"},
+			},
+		},
+	})}
+}
+
+// escalationToolResult answers a call to escalate_to_stronger_model.
+func (e *Agent) escalationToolResult(ctx context.Context, runState *agentstate.RunState) string {
+	if !e.escalate(ctx, runState, EscalationReasonUnsure) {
+		return "You are already the strongest model available. Answer as well as you can."
+	}
+	return "Escalated: a stronger model takes over this task from the next turn."
+}
@@ -0,0 +1,228 @@
+package agents_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/history"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/llmtest"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+)
+
+// familyProvider is a scripted provider that says which family it is, the
+// way a real provider does.
+type familyProvider struct {
+	llm.Provider
+	family llm.ProviderName
+}
+
+func (f familyProvider) ProviderName() llm.ProviderName { return f.family }
+
+// asFamily names the family a scripted provider stands in for.
+func asFamily(family llm.ProviderName, fake *llmtest.Provider) llm.Provider {
+	return familyProvider{Provider: fake, family: family}
+}
+
+var errUnavailable = errors.New("provider unavailable")
+
+func newFailingTool(name string) *fakeTool {
+	tool := newFakeTool(name, false, "")
+	tool.execute = func(ctx context.Context, params *agents.ToolCall) (*agents.ToolCallResponse, error) {
+		return nil, errors.New("upstream timeout")
+	}
+	return tool
+}
+
+func TestModelPolicy_FallbackRetriesTheFailedTurnOnly(t *testing.T) {
+	lookup := newFakeTool("lookup", false, "found it")
+	primary := llmtest.New().Fail(errUnavailable).RespondText("done")
+	fallback := llmtest.New().CallTool("lookup", "{}")
+
+	agent := newScriptedAgent("assistant", nil, nil, nil, []agents.Tool{lookup}, nil, func(o *agents.AgentOptions) {
+		o.LLM = asFamily(llm.ProviderNameOpenAI, primary)
+		o.ModelPolicy = &agents.ModelPolicy{Fallbacks: []llm.Provider{asFamily(llm.ProviderNameOpenAI, fallback)}}
+	})
+
+	out := runAgent(t, agent, &agents.AgentInput{Message: userMessage("find it")})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Equal(t, "done", out.Text())
+
+	// The fallback answered the failed turn; the next went back to the
+	// agent's own model.
+	assert.Equal(t, 1, fallback.Calls())
+	assert.Equal(t, 2, primary.Calls())
+	assert.Equal(t, 1, lookup.callCount())
+}
+
+func TestModelPolicy_FallbacksExhaustedFailsTheRun(t *testing.T) {
+	primary := llmtest.New().Fail(errUnavailable)
+	fallback := llmtest.New().Fail(errUnavailable)
+	agent := newScriptedAgent("assistant", nil, nil, nil, nil, nil, scriptedBy(primary), func(o *agents.AgentOptions) {
+		o.ModelPolicy = &agents.ModelPolicy{Fallbacks: []llm.Provider{fallback}}
+	})
+
+	handle, err := agent.Execute(context.Background(), &agents.AgentInput{Message: userMessage("hi")})
+	require.NoError(t, err)
+	_, err = handle.Result()
+	require.ErrorContains(t, err, "provider unavailable")
+	assert.Equal(t, 1, primary.Calls())
+	assert.Equal(t, 1, fallback.Calls())
+}
+
+func TestModelPolicy_FallbackOfAnotherFamilyGetsNoReasoning(t *testing.T) {
+	lookup := newFakeTool("lookup", false, "found it")
+	primary := llmtest.New().
+		Respond(llmtest.Reasoning("look it up"), llmtest.ToolCall("lookup", "{}")).
+		Fail(errUnavailable).
+		RespondText("done")
+	fallback := llmtest.New().CallTool("lookup", "{}")
+
+	agent := newScriptedAgent("assistant", nil, nil, nil, []agents.Tool{lookup}, nil, func(o *agents.AgentOptions) {
+		o.LLM = asFamily(llm.ProviderNameOpenAI, primary)
+		o.ModelPolicy = &agents.ModelPolicy{Fallbacks: []llm.Provider{asFamily(llm.ProviderNameAnthropic, fallback)}}
+	})
+
+	out := runAgent(t, agent, &agents.AgentInput{Message: userMessage("find it")})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+
+	// The other provider did not get the reasoning, but history kept it, and
+	// the agent's own model saw it again.
+	assert.False(t, providerState(fallback.Request(0).Input.OfInputMessageList))
+	assert.True(t, providerState(primary.Request(2).Input.OfInputMessageList))
+}
+
+// providerState reports whether msgs carry a reasoning item or a thought
+// signature.
+func providerState(msgs []responses.InputMessageUnion) bool {
+	for _, msg := range msgs {
+		if msg.OfReasoning != nil || (msg.OfFunctionCall != nil && msg.OfFunctionCall.ThoughtSignature != nil) {
+			return true
+		}
+	}
+	return false
+}
+
+// What is stripped follows the model that wrote it: the agent's own model is
+// not sent the reasoning a fallback of another family left in the history.
+func TestModelPolicy_OwnModelGetsNoReasoningAFallbackWrote(t *testing.T) {
+	lookup := newFakeTool("lookup", false, "found it")
+	primary := llmtest.New().Fail(errUnavailable).RespondText("done")
+	fallback := llmtest.New().Respond(llmtest.Reasoning("look it up"), llmtest.ToolCall("lookup", "{}"))
+
+	agent := newScriptedAgent("assistant", nil, nil, nil, []agents.Tool{lookup}, nil, func(o *agents.AgentOptions) {
+		o.LLM = asFamily(llm.ProviderNameOpenAI, primary)
+		o.ModelPolicy = &agents.ModelPolicy{Fallbacks: []llm.Provider{asFamily(llm.ProviderNameAnthropic, fallback)}}
+	})
+
+	out := runAgent(t, agent, &agents.AgentInput{Message: userMessage("find it")})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.False(t, providerState(primary.Request(1).Input.OfInputMessageList))
+}
+
+// A provider that does not name its family, like a gateway client routed by
+// model string, has its replies recorded under the family the reported model
+// name resolves to.
+func TestModelPolicy_FamilyResolvedFromTheModelName(t *testing.T) {
+	lookup := newFakeTool("lookup", false, "found it")
+	primary := llmtest.New().
+		Respond(llmtest.Reasoning("look it up"), llmtest.ToolCall("lookup", "{}")).WithModel("claude-sonnet-4-5").
+		Fail(errUnavailable)
+	fallback := llmtest.New().RespondText("done")
+
+	agent := newScriptedAgent("assistant", nil, nil, nil, []agents.Tool{lookup}, nil, scriptedBy(primary), func(o *agents.AgentOptions) {
+		o.ModelPolicy = &agents.ModelPolicy{Fallbacks: []llm.Provider{asFamily(llm.ProviderNameOpenAI, fallback)}}
+	})
+
+	out := runAgent(t, agent, &agents.AgentInput{Message: userMessage("find it")})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.False(t, providerState(fallback.Request(0).Input.OfInputMessageList))
+}
+
+func TestModelPolicy_EscalatesAfterConsecutiveToolErrors(t *testing.T) {
+	persistence := history.NewInMemoryConversationPersistence()
+	deploy := newFailingTool("deploy")
+	primary := llmtest.New().CallTool("deploy", "{}").CallTool("deploy", "{}")
+	strong := llmtest.New().RespondText("The deploy target is down.")
+
+	agent := newScriptedAgent("assistant", nil, history.NewConversationManager(persistence), nil, []agents.Tool{deploy}, nil, scriptedBy(primary), func(o *agents.AgentOptions) {
+		o.ModelPolicy = &agents.ModelPolicy{Escalation: &agents.ModelEscalation{LLM: strong, AfterToolErrors: 2}}
+	})
+
+	out := runAgent(t, agent, &agents.AgentInput{Namespace: "test", ThreadID: "t1", Message: userMessage("deploy")})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Equal(t, "The deploy target is down.", out.Text())
+	assert.Equal(t, 2, primary.Calls())
+	assert.Equal(t, 1, strong.Calls())
+
+	// The escalation is part of the persisted run.
+	saved, err := persistence.LoadMessages(context.Background(), "test", "t1", out.RunID)
+	require.NoError(t, err)
+	runState := agentstate.LoadRunStateFromMeta(saved[len(saved)-1].Meta)
+	require.NotNil(t, runState.Escalation)
+	assert.Equal(t, agentstate.Escalation{AgentName: "assistant", Reason: agents.EscalationReasonToolErrors, LoopIteration: 1}, *runState.Escalation)
+}
+
+func TestModelPolicy_ToolSuccessResetsTheErrorCount(t *testing.T) {
+	tools := []agents.Tool{newFailingTool("deploy"), newFakeTool("status", false, "ok")}
+	primary := llmtest.New().
+		CallTool("deploy", "{}").
+		CallTool("status", "{}").
+		CallTool("deploy", "{}").
+		RespondText("gave up")
+	strong := llmtest.New()
+
+	agent := newScriptedAgent("assistant", nil, nil, nil, tools, nil, scriptedBy(primary), func(o *agents.AgentOptions) {
+		o.ModelPolicy = &agents.ModelPolicy{Escalation: &agents.ModelEscalation{LLM: strong, AfterToolErrors: 2}}
+	})
+
+	out := runAgent(t, agent, &agents.AgentInput{Message: userMessage("deploy")})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Equal(t, 0, strong.Calls())
+}
+
+func TestModelPolicy_EscalatesOnInvalidOutput(t *testing.T) {
+	primary := llmtest.New().RespondText(`It is 22 degrees in Paris.`)
+	strong := llmtest.New().RespondText(`{"city":"Paris","celsius":22}`)
+
+	// No repairs of its own: the repair turn is the stronger model's.
+	agent := newScriptedAgent("forecaster", nil, nil, nil, nil, nil, scriptedBy(primary), func(o *agents.AgentOptions) {
+		o.MaxOutputRepairs = utils.Ptr(0)
+		o.ModelPolicy = &agents.ModelPolicy{Escalation: &agents.ModelEscalation{LLM: strong, OnInvalidOutput: true}}
+	})
+
+	got, out, err := agents.RunTyped[weather](context.Background(), agent, &agents.AgentInput{
+		Message: userMessage("weather in Paris?"),
+	})
+	require.NoError(t, err)
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Equal(t, 22.0, got.Celsius)
+	assert.Contains(t, messagesText(strong.Request(0).Input.OfInputMessageList), "not valid JSON")
+}
+
+func TestModelPolicy_ModelEscalatesWhenUnsure(t *testing.T) {
+	primary := llmtest.New().CallTool("escalate_to_stronger_model", `{"reason":"needs a proof"}`)
+	strong := llmtest.New().RespondText("Here is the proof.")
+
+	agent := newScriptedAgent("assistant", nil, nil, nil, nil, nil, scriptedBy(primary), func(o *agents.AgentOptions) {
+		o.ModelPolicy = &agents.ModelPolicy{Escalation: &agents.ModelEscalation{LLM: strong, OnUnsure: true}}
+	})
+
+	out := runAgent(t, agent, &agents.AgentInput{Message: userMessage("prove it")})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Equal(t, "Here is the proof.", out.Text())
+
+	var offered bool
+	for _, tool := range primary.Request(0).Tools {
+		offered = offered || (tool.OfFunction != nil && tool.OfFunction.Name == "escalate_to_stronger_model")
+	}
+	assert.True(t, offered)
+	assert.Contains(t, messagesText(strong.Request(0).Input.OfInputMessageList), "stronger model takes over")
+}
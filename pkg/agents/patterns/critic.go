@@ -0,0 +1,182 @@
+package patterns
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/history"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+)
+
+// NewCritic makes a critic of a function, for an agent's Reflection.
+func NewCritic(name string, fn func(ctx context.Context, in *agents.CritiqueInput) (agents.CritiqueResult, error)) agents.Critic {
+	return &criticFunc{name: name, fn: fn}
+}
+
+type criticFunc struct {
+	name string
+	fn   func(ctx context.Context, in *agents.CritiqueInput) (agents.CritiqueResult, error)
+}
+
+func (c *criticFunc) GetName() string { return c.name }
+
+func (c *criticFunc) Critique(ctx context.Context, in *agents.CritiqueInput) (agents.CritiqueResult, error) {
+	return c.fn(ctx, in)
+}
+
+// critiqueVerdict is the part of a CritiqueResult a model is asked for.
+type critiqueVerdict struct {
+	Approved bool   `json:"approved"`
+	Feedback string `json:"feedback,omitempty"`
+}
+
+const critiqueFormat = "Answer with JSON only: \"approved\" is true if the draft needs no changes; " +
+	"otherwise it is false, and \"feedback\" says what to change and why."
+
+// critiqueRequest is the text a critic is given to read.
+func critiqueRequest(in *agents.CritiqueInput) string {
+	return fmt.Sprintf("The user asked:\n\n%s\n\nThe assistant's draft answer:\n\n%s", in.Request, in.Draft)
+}
+
+// LLMCriticOptions configures an LLMCritic.
+type LLMCriticOptions struct {
+	Name string
+	LLM  llm.Provider
+	// Rubric is what a good answer does, e.g. "Every figure cites its
+	// source, and the answer fits on one screen."
+	Rubric     string
+	Parameters responses.Parameters
+}
+
+// LLMCritic asks a model whether a draft meets its rubric.
+type LLMCritic struct {
+	name       string
+	llm        llm.Provider
+	rubric     string
+	parameters responses.Parameters
+}
+
+var _ agents.Critic = (*LLMCritic)(nil)
+
+func NewLLMCritic(opts *LLMCriticOptions) *LLMCritic {
+	parameters := opts.Parameters
+	parameters.Text = &responses.TextFormat{
+		Format: map[string]any{
+			"type":   "json_schema",
+			"name":   "critique_verdict",
+			"strict": false,
+			"schema": agents.OutputSchemaFor[critiqueVerdict](),
+		},
+	}
+
+	return &LLMCritic{
+		name:       opts.Name,
+		llm:        opts.LLM,
+		rubric:     opts.Rubric,
+		parameters: parameters,
+	}
+}
+
+func (c *LLMCritic) GetName() string { return c.name }
+
+func (c *LLMCritic) Critique(ctx context.Context, in *agents.CritiqueInput) (agents.CritiqueResult, error) {
+	instruction := "You are a reviewer. Decide whether the draft answer you are given meets this rubric:\n\n" + c.rubric +
+		"\n\n" + critiqueFormat
+
+	resp, err := c.llm.NewResponses(ctx, &responses.Request{
+		Instructions: utils.Ptr(instruction),
+		Input: responses.InputUnion{
+			OfInputMessageList: responses.InputMessageList{{
+				OfInputMessage: &responses.InputMessage{
+					Role: constants.RoleUser,
+					Content: responses.InputContent{
+						{OfInputText: &responses.InputTextContent{Text: critiqueRequest(in)}},
+					},
+				},
+			}},
+		},
+		Parameters: c.parameters,
+	})
+	if err != nil {
+		return agents.CritiqueResult{}, err
+	}
+
+	// The verdict is read the way a structured answer is.
+	answer := &agents.AgentOutput{}
+	for _, msg := range resp.Output {
+		if msg.OfOutputMessage != nil {
+			answer.Output = append(answer.Output, responses.InputMessageUnion{OfOutputMessage: msg.OfOutputMessage})
+		}
+	}
+
+	var verdict critiqueVerdict
+	if err := answer.Decode(&verdict); err != nil {
+		return agents.CritiqueResult{}, fmt.Errorf("critic gave no verdict: %w", err)
+	}
+
+	return agents.CritiqueResult{Approved: verdict.Approved, Feedback: verdict.Feedback, Usage: resp.Usage, Model: resp.Model}, nil
+}
+
+// AgentCritic has an agent critique the draft, in a run of its own: one that
+// can call tools — to check a fact, say — before it gives its verdict.
+type AgentCritic struct {
+	name  string
+	agent *agents.Agent
+}
+
+var _ agents.Critic = (*AgentCritic)(nil)
+
+// NewAgentCritic returns a critic that runs agent. The agent's own
+// instruction is its rubric; it is asked for its verdict as structured
+// output.
+func NewAgentCritic(name string, agent *agents.Agent) *AgentCritic {
+	return &AgentCritic{name: name, agent: agent}
+}
+
+func (c *AgentCritic) GetName() string { return c.name }
+
+func (c *AgentCritic) Critique(ctx context.Context, in *agents.CritiqueInput) (agents.CritiqueResult, error) {
+	// A budget, even an unlimited one, has the run report what it spent.
+	budget := in.Budget
+	if budget == nil {
+		budget = &agents.RunBudget{}
+	}
+
+	verdict, out, err := agents.RunTyped[critiqueVerdict](ctx, c.agent, &agents.AgentInput{
+		Namespace:  in.Namespace,
+		RunContext: in.RunContext,
+		Message: history.Message{
+			SenderID: in.AgentName,
+			Messages: []responses.InputMessageUnion{{
+				OfEasyInput: &responses.EasyMessage{
+					Role:    constants.RoleUser,
+					Content: responses.EasyInputContentUnion{OfString: utils.Ptr(critiqueRequest(in) + "\n\n" + critiqueFormat)},
+				},
+			}},
+		},
+		Budget: budget,
+	})
+	if err != nil {
+		return agents.CritiqueResult{}, err
+	}
+	// A critic that pauses for approval has no one to ask: the run it
+	// critiques is the one waiting on it.
+	if out.Status != agentstate.RunStatusCompleted {
+		return agents.CritiqueResult{}, fmt.Errorf("critic run ended with status %q", out.Status)
+	}
+
+	result := agents.CritiqueResult{Approved: verdict.Approved, Feedback: verdict.Feedback, Spend: out.Spend}
+	if out.Spend != nil {
+		result.Usage = &responses.Usage{
+			InputTokens:  out.Spend.InputTokens,
+			OutputTokens: out.Spend.OutputTokens,
+			TotalTokens:  out.Spend.InputTokens + out.Spend.OutputTokens,
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,86 @@
+package patterns_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/patterns"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/streambroker"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/llmtest"
+)
+
+func newReflectingAgent(writer *llmtest.Provider, critic agents.Critic, budget *agents.RunBudget) *agents.Agent {
+	return agents.NewAgent(&agents.AgentOptions{
+		Name:         "writer",
+		LLM:          writer,
+		StreamBroker: streambroker.NewMemoryStreamBroker(),
+		Reflection:   &agents.Reflection{Critic: critic},
+		Budget:       budget,
+	})
+}
+
+func TestLLMCritic_ReadsTheDraftAgainstItsRubric(t *testing.T) {
+	writerLLM := llmtest.New().
+		RespondText("Go is fast.").
+		RespondText("Go compiles to native code, so it is fast.")
+	criticLLM := llmtest.New().
+		RespondText(`{"approved":false,"feedback":"Say why."}`).
+		RespondText("```json\n{\"approved\":true}\n```")
+
+	agent := newReflectingAgent(writerLLM, patterns.NewLLMCritic(&patterns.LLMCriticOptions{
+		Name:   "editor",
+		LLM:    criticLLM,
+		Rubric: "Claims are explained.",
+	}), nil)
+
+	out := runAgent(t, agent, &agents.AgentInput{Namespace: "test", Message: userMessage("Is Go fast?")})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Equal(t, "Go compiles to native code, so it is fast.", out.Text())
+
+	// The critic read each draft against its rubric, asked for a verdict it
+	// could parse; its feedback went back to the writer.
+	assert.Contains(t, *criticLLM.Request(0).Instructions, "Claims are explained.")
+	assert.NotNil(t, criticLLM.Request(0).Text)
+	assert.Contains(t, messagesText(criticLLM.Request(0).Input.OfInputMessageList), "The user asked:\n\nIs Go fast?")
+	assert.Contains(t, messagesText(criticLLM.Request(1).Input.OfInputMessageList), "Go compiles to native code")
+	assert.Contains(t, messagesText(writerLLM.Request(1).Input.OfInputMessageList), "Say why.")
+}
+
+func TestLLMCritic_NoVerdictFailsTheRun(t *testing.T) {
+	writerLLM := llmtest.New().RespondText("Go is fast.")
+	criticLLM := llmtest.New().RespondText("Looks fine to me.")
+
+	agent := newReflectingAgent(writerLLM, patterns.NewLLMCritic(&patterns.LLMCriticOptions{Name: "editor", LLM: criticLLM}), nil)
+
+	handle, err := agent.Execute(t.Context(), &agents.AgentInput{Message: userMessage("Is Go fast?")})
+	require.NoError(t, err)
+	_, err = handle.Result()
+	assert.ErrorContains(t, err, `reflection critic "editor": critic gave no verdict`)
+}
+
+// An agent critic runs under what is left of the run's budget, and what it
+// spends counts against it.
+func TestAgentCritic_SpendsTheRunsBudget(t *testing.T) {
+	reviewerLLM := llmtest.New().RespondText(`{"approved":true}`).WithUsage(300, 20)
+	reviewer := agents.NewAgent(&agents.AgentOptions{
+		Name:         "reviewer",
+		LLM:          reviewerLLM,
+		StreamBroker: streambroker.NewMemoryStreamBroker(),
+	})
+
+	writerLLM := llmtest.New().RespondText("the answer").WithUsage(100, 10)
+	agent := newReflectingAgent(writerLLM, patterns.NewAgentCritic("reviewer", reviewer), &agents.RunBudget{MaxInputTokens: 10_000})
+
+	out := runAgent(t, agent, &agents.AgentInput{Namespace: "test", Message: userMessage("question")})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Equal(t, "the answer", out.Text())
+	assert.Contains(t, messagesText(reviewerLLM.Request(0).Input.OfInputMessageList), "The assistant's draft answer:\n\nthe answer")
+
+	require.NotNil(t, out.Spend)
+	assert.Equal(t, 400, out.Spend.InputTokens)
+	assert.Equal(t, 30, out.Spend.OutputTokens)
+}
@@ -0,0 +1,582 @@
+package patterns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/history"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/messages"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/streambroker"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+)
+
+// DefaultMaxTurns is how many turns a GroupChat takes when its options do
+// not say.
+const DefaultMaxTurns = 10
+
+// GroupChatMember is an agent of a group chat. Description tells an
+// LLMSpeakerSelector what the member is for.
+type GroupChatMember struct {
+	Agent       *agents.Agent
+	Description string
+}
+
+// GroupChatMessage is one message of a group chat's transcript.
+type GroupChatMessage struct {
+	Sender string `json:"sender"`
+	Text   string `json:"text"`
+}
+
+// GroupChatView is the chat as a speaker selector or termination condition
+// sees it: the whole thread, and how many turns this Run has taken.
+type GroupChatView struct {
+	Members  []*GroupChatMember
+	Messages []GroupChatMessage
+	Turns    int
+}
+
+// LastSpeaker is the member who spoke last, or "" when none has.
+func (v *GroupChatView) LastSpeaker() string {
+	for i := len(v.Messages) - 1; i >= 0; i-- {
+		for _, m := range v.Members {
+			if m.Agent.Name == v.Messages[i].Sender {
+				return m.Agent.Name
+			}
+		}
+	}
+	return ""
+}
+
+// SpeakerSelector picks the member who speaks next, by name.
+type SpeakerSelector interface {
+	SelectSpeaker(ctx context.Context, chat *GroupChatView) (string, error)
+}
+
+// SpeakerSelectorFunc is a SpeakerSelector written as a function.
+type SpeakerSelectorFunc func(ctx context.Context, chat *GroupChatView) (string, error)
+
+func (f SpeakerSelectorFunc) SelectSpeaker(ctx context.Context, chat *GroupChatView) (string, error) {
+	return f(ctx, chat)
+}
+
+// RoundRobin has the members speak in the order they were given, starting
+// after whoever spoke last.
+func RoundRobin() SpeakerSelector {
+	return SpeakerSelectorFunc(func(ctx context.Context, chat *GroupChatView) (string, error) {
+		last := chat.LastSpeaker()
+		for i, m := range chat.Members {
+			if m.Agent.Name == last {
+				return chat.Members[(i+1)%len(chat.Members)].Agent.Name, nil
+			}
+		}
+		return chat.Members[0].Agent.Name, nil
+	})
+}
+
+// LLMSpeakerSelectorOptions configures an LLMSpeakerSelector.
+type LLMSpeakerSelectorOptions struct {
+	LLM llm.Provider
+	// Instruction is added to the selector's own, e.g. "The tester speaks
+	// only after the reviewer has approved the code."
+	Instruction string
+	// Parameters picks the model. A small, fast one is usually enough.
+	Parameters responses.Parameters
+}
+
+// LLMSpeakerSelector asks a model who should speak next, given the members'
+// descriptions and the transcript.
+type LLMSpeakerSelector struct {
+	llm         llm.Provider
+	instruction string
+	parameters  responses.Parameters
+}
+
+func NewLLMSpeakerSelector(opts *LLMSpeakerSelectorOptions) *LLMSpeakerSelector {
+	return &LLMSpeakerSelector{
+		llm:         opts.LLM,
+		instruction: opts.Instruction,
+		parameters:  opts.Parameters,
+	}
+}
+
+func (s *LLMSpeakerSelector) SelectSpeaker(ctx context.Context, chat *GroupChatView) (string, error) {
+	var b strings.Builder
+	b.WriteString("You pick who speaks next in a group chat. The members are:\n")
+	for _, m := range chat.Members {
+		fmt.Fprintf(&b, "\n- %s: %s", m.Agent.Name, m.Description)
+	}
+	if s.instruction != "" {
+		b.WriteString("\n\n" + s.instruction)
+	}
+	b.WriteString("\n\nAnswer with the name of the next speaker only.")
+
+	var transcript []string
+	for _, msg := range chat.Messages {
+		transcript = append(transcript, msg.Sender+": "+msg.Text)
+	}
+
+	resp, err := s.llm.NewResponses(ctx, &responses.Request{
+		Instructions: utils.Ptr(b.String()),
+		Input: responses.InputUnion{
+			OfInputMessageList: responses.InputMessageList{{
+				OfInputMessage: &responses.InputMessage{
+					Role: constants.RoleUser,
+					Content: responses.InputContent{
+						{OfInputText: &responses.InputTextContent{Text: "The chat so far:\n\n" + strings.Join(transcript, "\n\n")}},
+					},
+				},
+			}},
+		},
+		Parameters: s.parameters,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var answer string
+	for _, msg := range resp.Output {
+		if msg.OfOutputMessage != nil {
+			answer += messageText(responses.InputMessageUnion{OfOutputMessage: msg.OfOutputMessage})
+		}
+	}
+	answer = strings.Trim(strings.TrimSpace(answer), "\"'`.*")
+
+	// The model may say more than the name; one member named is enough.
+	var named []string
+	for _, m := range chat.Members {
+		if strings.EqualFold(answer, m.Agent.Name) {
+			return m.Agent.Name, nil
+		}
+		if strings.Contains(strings.ToLower(answer), strings.ToLower(m.Agent.Name)) {
+			named = append(named, m.Agent.Name)
+		}
+	}
+	if len(named) == 1 {
+		return named[0], nil
+	}
+	return "", fmt.Errorf("speaker selector answered %q, which names no one member", answer)
+}
+
+// TerminationCondition reports whether the chat is over. It is asked after
+// every turn.
+type TerminationCondition func(ctx context.Context, chat *GroupChatView) (bool, error)
+
+// TerminateOnText ends the chat once a member's message contains text, e.g.
+// "APPROVED".
+func TerminateOnText(text string) TerminationCondition {
+	return func(ctx context.Context, chat *GroupChatView) (bool, error) {
+		if len(chat.Messages) == 0 {
+			return false, nil
+		}
+		last := chat.Messages[len(chat.Messages)-1]
+		return last.Sender == chat.LastSpeaker() && strings.Contains(last.Text, text), nil
+	}
+}
+
+// GroupChatStopReason is why a group chat ended.
+type GroupChatStopReason string
+
+const (
+	GroupChatTerminated GroupChatStopReason = "terminated"
+	GroupChatMaxTurns   GroupChatStopReason = "max_turns"
+	GroupChatStopped    GroupChatStopReason = "stopped"
+)
+
+// GroupChatOutput is how a GroupChat's Run ended.
+//
+// A chat paused on a member's interrupts has status paused; RunID is the
+// paused run, to resume as any run is, with the resolutions as the message
+// of the next Run. A chat that ended otherwise is continued the same way
+// with the user's next message.
+type GroupChatOutput struct {
+	RunID      string                `json:"run_id"`
+	Status     agentstate.RunStatus  `json:"status"`
+	StopReason GroupChatStopReason   `json:"stop_reason,omitempty"`
+	Speaker    string                `json:"speaker"`
+	Interrupts []responses.Interrupt `json:"interrupts,omitempty"`
+
+	// Messages are what the members said in this Run, in order.
+	Messages []GroupChatMessage `json:"messages"`
+}
+
+// GroupChatOptions configures a GroupChat.
+type GroupChatOptions struct {
+	Members []*GroupChatMember
+
+	// Selector picks each turn's speaker. RoundRobin by default.
+	Selector SpeakerSelector
+	// Terminate ends the chat before MaxTurns, when it says so.
+	Terminate TerminationCondition
+	// MaxTurns caps the turns one Run takes. A Run that resumes a paused
+	// chat gets MaxTurns again.
+	MaxTurns int
+
+	// History is the thread the members share. They are each bound to it.
+	// It should attribute messages (history.WithMessageAttribution), so each
+	// member sees who said what, and hide the other members' tool calls
+	// (history.WithMessageFilter(OwnToolCallsOnly{})), which name tools the
+	// member does not have. The default does both, in memory.
+	History *history.CommonConversationManager
+	// StreamBroker carries the chat's stream: every turn's chunks, one after
+	// the other. An in-memory broker by default.
+	StreamBroker agents.StreamBroker
+}
+
+// GroupChat holds several agents on one thread and has them take turns:
+// a debate, or a review between a coder, a reviewer and a tester. Each turn
+// is a run of the speaker's on the shared thread, so a member can call its
+// tools, hand off, or pause for approval as it would on its own.
+type GroupChat struct {
+	members   []*GroupChatMember
+	selector  SpeakerSelector
+	terminate TerminationCondition
+	maxTurns  int
+	history   *history.CommonConversationManager
+	broker    agents.StreamBroker
+}
+
+func NewGroupChat(opts *GroupChatOptions) *GroupChat {
+	hist := opts.History
+	if hist == nil {
+		hist = history.NewConversationManager(history.NewInMemoryConversationPersistence(),
+			history.WithMessageAttribution(),
+			history.WithMessageFilter(OwnToolCallsOnly{}),
+		)
+	}
+
+	members := make([]*GroupChatMember, 0, len(opts.Members))
+	for _, m := range opts.Members {
+		members = append(members, &GroupChatMember{Agent: m.Agent.WithHistory(hist), Description: m.Description})
+	}
+
+	selector := opts.Selector
+	if selector == nil {
+		selector = RoundRobin()
+	}
+
+	maxTurns := opts.MaxTurns
+	if maxTurns <= 0 {
+		maxTurns = DefaultMaxTurns
+	}
+
+	broker := opts.StreamBroker
+	if broker == nil {
+		broker = streambroker.NewMemoryStreamBroker()
+	}
+
+	return &GroupChat{
+		members:   members,
+		selector:  selector,
+		terminate: opts.Terminate,
+		maxTurns:  maxTurns,
+		history:   hist,
+		broker:    broker,
+	}
+}
+
+// StreamBroker returns the broker the chat streams through.
+func (c *GroupChat) StreamBroker() agents.StreamBroker {
+	return c.broker
+}
+
+// Stop asks the chat on streamID to stop. The member speaking is stopped as
+// a user would stop it, and no one speaks after it.
+func (c *GroupChat) Stop(ctx context.Context, streamID string) error {
+	return c.broker.Stop(ctx, streamID)
+}
+
+// Run has the members take turns on in's thread until the termination
+// condition holds, MaxTurns turns are taken, or a member pauses. in's
+// message opens the chat, or continues it; with PreviousRunID on a paused
+// run, it resumes the member who paused.
+//
+// The chat streams on in.StreamID, which is closed when Run returns.
+func (c *GroupChat) Run(ctx context.Context, in *agents.AgentInput) (*GroupChatOutput, error) {
+	if len(c.members) == 0 {
+		return nil, errors.New("group chat: no members")
+	}
+	if in.StreamID == "" {
+		in.StreamID = uuid.NewString()
+	}
+	if in.ThreadID == "" {
+		in.ThreadID = uuid.NewString()
+	}
+	defer c.broker.Close(context.Background(), in.StreamID)
+
+	out := &GroupChatOutput{RunID: in.PreviousRunID, Status: agentstate.RunStatusCompleted}
+	message := in.Message
+	stream := &chatStream{StreamBroker: c.broker, state: map[string]any{}}
+
+	// A paused run resumes on the member it paused in.
+	var speaker *GroupChatMember
+	if in.PreviousRunID != "" {
+		paused, err := c.pausedSpeaker(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+		speaker = paused
+	}
+
+	for turns := 0; ; turns++ {
+		view, err := c.view(ctx, in, out.RunID, message, turns)
+		if err != nil {
+			return nil, err
+		}
+
+		if turns > 0 && c.terminate != nil {
+			done, err := c.terminate(ctx, view)
+			if err != nil {
+				return nil, err
+			}
+			if done {
+				out.StopReason = GroupChatTerminated
+				return out, nil
+			}
+		}
+		if turns >= c.maxTurns {
+			out.StopReason = GroupChatMaxTurns
+			return out, nil
+		}
+		if stopped, _ := c.broker.IsStopped(ctx, in.StreamID); stopped {
+			out.StopReason = GroupChatStopped
+			return out, nil
+		}
+
+		if speaker == nil {
+			name, err := c.selector.SelectSpeaker(ctx, view)
+			if err != nil {
+				return nil, fmt.Errorf("group chat: selecting a speaker: %w", err)
+			}
+			if speaker = c.member(name); speaker == nil {
+				return nil, fmt.Errorf("group chat: %q is not a member", name)
+			}
+		}
+
+		_ = stream.Publish(ctx, in.StreamID, &responses.ResponseChunk{
+			OfStateUpdated: &responses.ChunkState[constants.ChunkTypeStateUpdated]{
+				State: map[string]any{"speaker": speaker.Agent.Name, "turn": turns + 1},
+			},
+		})
+
+		result, err := c.turn(ctx, in, speaker.Agent.WithStreamBroker(stream), out.RunID, message)
+		if err != nil {
+			return nil, err
+		}
+
+		out.RunID, out.Status, out.Speaker = result.RunID, result.Status, speaker.Agent.Name
+		if text := result.Text(); text != "" {
+			out.Messages = append(out.Messages, GroupChatMessage{Sender: speaker.Agent.Name, Text: text})
+		}
+		if result.Status != agentstate.RunStatusCompleted {
+			out.Interrupts = result.Interrupts
+			return out, nil
+		}
+
+		// The opening message is on the thread now; later turns add nothing
+		// of their own.
+		message = messages.Message{SenderID: in.Message.SenderID}
+		speaker = nil
+	}
+}
+
+// turn runs one member's turn on the chat's stream. A stop of the chat is a
+// stop of the turn.
+func (c *GroupChat) turn(ctx context.Context, in *agents.AgentInput, speaker *agents.Agent, previousRunID string, message history.Message) (*agents.AgentOutput, error) {
+	handle, err := speaker.Execute(ctx, &agents.AgentInput{
+		Namespace:     in.Namespace,
+		ThreadID:      in.ThreadID,
+		PreviousRunID: previousRunID,
+		StreamID:      in.StreamID,
+		Message:       message,
+		RunContext:    in.RunContext,
+		SessionID:     in.SessionID,
+		Budget:        in.Budget,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return handle.Result()
+}
+
+// chatStream is the chat's broker as its members see it during one Run. Their
+// runs publish on the chat's stream itself; the state a member shares joins
+// the chat's — who is speaking, the turn — rather than replacing it, and the
+// end of a turn leaves the stream open for the next.
+type chatStream struct {
+	agents.StreamBroker
+
+	mu    sync.Mutex
+	state map[string]any
+}
+
+func (s *chatStream) Publish(ctx context.Context, channel string, chunk *responses.ResponseChunk) error {
+	if chunk.OfStateUpdated != nil {
+		s.mu.Lock()
+		maps.Copy(s.state, chunk.OfStateUpdated.State)
+		chunk = &responses.ResponseChunk{
+			OfStateUpdated: &responses.ChunkState[constants.ChunkTypeStateUpdated]{State: maps.Clone(s.state)},
+		}
+		s.mu.Unlock()
+	}
+	return s.StreamBroker.Publish(ctx, channel, chunk)
+}
+
+// Subscribe gives a turn's run nothing to read: the chat's client is on the
+// stream already, and the chat waits on the run's result.
+func (s *chatStream) Subscribe(ctx context.Context, channel string) (<-chan *responses.ResponseChunk, error) {
+	chunks := make(chan *responses.ResponseChunk)
+	close(chunks)
+	return chunks, nil
+}
+
+// Close leaves the stream open; Run closes it when the chat ends.
+func (s *chatStream) Close(ctx context.Context, channel string) error {
+	return nil
+}
+
+// WatchStop is the chat's broker's, so a stop of the chat cuts a member's
+// model and tool calls short as a stop of its own run would.
+func (s *chatStream) WatchStop(ctx context.Context, channel string) (<-chan struct{}, func()) {
+	if watcher := agents.StopWatcherFrom(s.StreamBroker); watcher != nil {
+		return watcher.WatchStop(ctx, channel)
+	}
+	return make(chan struct{}), func() {}
+}
+
+// pausedSpeaker is the member the run in.PreviousRunID is paused in, or nil
+// when that run has finished.
+func (c *GroupChat) pausedSpeaker(ctx context.Context, in *agents.AgentInput) (*GroupChatMember, error) {
+	saved, err := c.history.ConversationPersistenceAdapter.LoadMessages(ctx, in.Namespace, in.ThreadID, in.PreviousRunID)
+	if err != nil {
+		return nil, err
+	}
+	if len(saved) == 0 {
+		return nil, nil
+	}
+	state := agentstate.LoadRunStateFromMeta(saved[len(saved)-1].Meta)
+	if state == nil || state.IsComplete() {
+		return nil, nil
+	}
+
+	// A member that handed off is paused in the handoff's target; the first
+	// handoff was made by the member itself.
+	name := state.LastAgentName
+	if len(state.Handoffs) > 0 {
+		name = state.Handoffs[0].Source
+	}
+	if m := c.member(name); m != nil {
+		return m, nil
+	}
+	return nil, fmt.Errorf("group chat: run %s is paused in %q, which is not a member", in.PreviousRunID, name)
+}
+
+// view is the chat up to the run previousRunID, and the message not yet on
+// the thread.
+func (c *GroupChat) view(ctx context.Context, in *agents.AgentInput, previousRunID string, pending history.Message, turns int) (*GroupChatView, error) {
+	view := &GroupChatView{Members: c.members, Turns: turns}
+
+	var bundles []messages.Message
+	if previousRunID != "" {
+		saved, err := c.history.ConversationPersistenceAdapter.LoadMessages(ctx, in.Namespace, in.ThreadID, previousRunID)
+		if err != nil {
+			return nil, err
+		}
+		for _, conv := range saved {
+			bundles = append(bundles, conv.Messages...)
+		}
+	}
+	bundles = append(bundles, pending)
+
+	for _, bundle := range bundles {
+		for _, msg := range bundle.Messages {
+			if text := messageText(msg); text != "" {
+				view.Messages = append(view.Messages, GroupChatMessage{Sender: bundle.SenderID, Text: text})
+			}
+		}
+	}
+	return view, nil
+}
+
+func (c *GroupChat) member(name string) *GroupChatMember {
+	for _, m := range c.members {
+		if m.Agent.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// OwnToolCallsOnly is a history.MessageFilter that keeps only the text of the
+// other senders' messages: their tool calls and results, reasoning and the
+// like are dropped. In a group chat another member's tool calls name tools
+// the running member does not have.
+type OwnToolCallsOnly struct{}
+
+func (OwnToolCallsOnly) Filter(ctx context.Context, msgs []messages.Message, agentID string) []messages.Message {
+	dropped := map[string]bool{}
+	out := make([]messages.Message, 0, len(msgs))
+	for _, bundle := range msgs {
+		own := bundle.SenderID == "" || bundle.SenderID == agentID
+
+		kept := make([]responses.InputMessageUnion, 0, len(bundle.Messages))
+		for _, msg := range bundle.Messages {
+			switch {
+			case msg.OfFunctionCallOutput != nil:
+				// Results are saved under the sender of the run's input, not
+				// the caller's name: they go with their call.
+				if !dropped[msg.OfFunctionCallOutput.CallID] {
+					kept = append(kept, msg)
+				}
+			case own:
+				kept = append(kept, msg)
+			case msg.OfFunctionCall != nil:
+				dropped[msg.OfFunctionCall.CallID] = true
+			case msg.OfOutputMessage != nil, msg.OfInputMessage != nil, msg.OfEasyInput != nil:
+				kept = append(kept, msg)
+			}
+		}
+
+		if len(kept) == len(bundle.Messages) {
+			out = append(out, bundle)
+		} else if len(kept) > 0 {
+			bundle.Messages = kept
+			out = append(out, bundle)
+		}
+	}
+	return out
+}
+
+// messageText is the text of a message, whichever side wrote it.
+func messageText(msg responses.InputMessageUnion) string {
+	var b strings.Builder
+	switch {
+	case msg.OfOutputMessage != nil && msg.OfOutputMessage.Content != nil:
+		for _, c := range *msg.OfOutputMessage.Content {
+			if c.OfOutputText != nil {
+				b.WriteString(c.OfOutputText.Text)
+			}
+		}
+	case msg.OfInputMessage != nil:
+		for _, c := range msg.OfInputMessage.Content {
+			switch {
+			case c.OfInputText != nil:
+				b.WriteString(c.OfInputText.Text)
+			case c.OfOutputText != nil:
+				b.WriteString(c.OfOutputText.Text)
+			}
+		}
+	case msg.OfEasyInput != nil && msg.OfEasyInput.Content.OfString != nil:
+		b.WriteString(*msg.OfEasyInput.Content.OfString)
+	}
+	return b.String()
+}
@@ -0,0 +1,240 @@
+package patterns_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/patterns"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/llmtest"
+)
+
+func chatMember(name string, provider llm.Provider, tools ...agents.Tool) *patterns.GroupChatMember {
+	return &patterns.GroupChatMember{
+		Agent:       agents.NewAgent(&agents.AgentOptions{Name: name, LLM: provider, Tools: tools}),
+		Description: "the " + name,
+	}
+}
+
+func TestGroupChat_RoundRobinUntilTerminated(t *testing.T) {
+	coderLLM := llmtest.New().RespondText("func add(a, b int) int { return a + b }")
+	reviewerLLM := llmtest.New().RespondText("Looks right. APPROVED")
+
+	chat := patterns.NewGroupChat(&patterns.GroupChatOptions{
+		Members:   []*patterns.GroupChatMember{chatMember("coder", coderLLM), chatMember("reviewer", reviewerLLM)},
+		Terminate: patterns.TerminateOnText("APPROVED"),
+	})
+
+	out, err := chat.Run(context.Background(), &agents.AgentInput{Namespace: "test", StreamID: "chat-stream", Message: userMessage("write add()")})
+	require.NoError(t, err)
+	assert.Equal(t, agentstate.RunStatusCompleted, out.Status)
+	assert.Equal(t, patterns.GroupChatTerminated, out.StopReason)
+	assert.Equal(t, []patterns.GroupChatMessage{
+		{Sender: "coder", Text: "func add(a, b int) int { return a + b }"},
+		{Sender: "reviewer", Text: "Looks right. APPROVED"},
+	}, out.Messages)
+
+	// The reviewer saw who said what.
+	seen := messagesText(reviewerLLM.Request(0).Input.OfInputMessageList)
+	assert.Contains(t, seen, "(Human) user said: write add()")
+	assert.Contains(t, seen, "(Agent) coder said: func add")
+
+	// Both turns streamed on the chat's stream, each after saying who speaks.
+	chunks, err := chat.StreamBroker().Subscribe(context.Background(), "chat-stream")
+	require.NoError(t, err)
+	var speakers []any
+	var runs int
+	for chunk := range chunks {
+		switch {
+		case chunk.OfStateUpdated != nil:
+			speakers = append(speakers, chunk.OfStateUpdated.State["speaker"])
+		case chunk.OfRunCreated != nil:
+			runs++
+		}
+	}
+	assert.Equal(t, []any{"coder", "reviewer"}, speakers)
+	assert.Equal(t, 2, runs)
+}
+
+func TestGroupChat_StopsAtMaxTurns(t *testing.T) {
+	proLLM := llmtest.New().
+		RespondText("tabs").
+		RespondText("still tabs")
+	conLLM := llmtest.New().RespondText("spaces")
+
+	chat := patterns.NewGroupChat(&patterns.GroupChatOptions{
+		Members:  []*patterns.GroupChatMember{chatMember("pro", proLLM), chatMember("con", conLLM)},
+		MaxTurns: 3,
+	})
+
+	out, err := chat.Run(context.Background(), &agents.AgentInput{Namespace: "test", ThreadID: "t1", Message: userMessage("tabs or spaces?")})
+	require.NoError(t, err)
+	assert.Equal(t, patterns.GroupChatMaxTurns, out.StopReason)
+	require.Len(t, out.Messages, 3)
+	assert.Equal(t, "still tabs", out.Messages[2].Text)
+
+	// Continuing the chat picks up after whoever spoke last.
+	conLLM.RespondText("spaces, finally").RespondText("spaces")
+	proLLM.RespondText("tabs")
+	out, err = chat.Run(context.Background(), &agents.AgentInput{
+		Namespace: "test", ThreadID: "t1", PreviousRunID: out.RunID,
+		Message: userMessage("one more each"),
+	})
+	require.NoError(t, err)
+	require.Len(t, out.Messages, 3)
+	assert.Equal(t, patterns.GroupChatMessage{Sender: "con", Text: "spaces, finally"}, out.Messages[0])
+	assert.Contains(t, messagesText(conLLM.Request(1).Input.OfInputMessageList), "(Human) user said: one more each")
+}
+
+func TestGroupChat_LLMSelectorPicksTheSpeaker(t *testing.T) {
+	selector := llmtest.New().RespondText("tester").RespondText("The coder should answer.")
+	coderLLM := llmtest.New().RespondText("fixed")
+	testerLLM := llmtest.New().RespondText("add(1, 1) returns 3")
+
+	chat := patterns.NewGroupChat(&patterns.GroupChatOptions{
+		Members:  []*patterns.GroupChatMember{chatMember("coder", coderLLM), chatMember("tester", testerLLM)},
+		Selector: patterns.NewLLMSpeakerSelector(&patterns.LLMSpeakerSelectorOptions{LLM: selector}),
+		MaxTurns: 2,
+	})
+
+	out, err := chat.Run(context.Background(), &agents.AgentInput{Namespace: "test", Message: userMessage("is add() right?")})
+	require.NoError(t, err)
+	assert.Equal(t, []patterns.GroupChatMessage{
+		{Sender: "tester", Text: "add(1, 1) returns 3"},
+		{Sender: "coder", Text: "fixed"},
+	}, out.Messages)
+
+	assert.Contains(t, *selector.Request(0).Instructions, "- tester: the tester")
+	assert.Contains(t, messagesText(selector.Request(1).Input.OfInputMessageList), "tester: add(1, 1) returns 3")
+}
+
+func TestGroupChat_UnknownSpeakerIsAnError(t *testing.T) {
+	chat := patterns.NewGroupChat(&patterns.GroupChatOptions{
+		Members: []*patterns.GroupChatMember{chatMember("coder", llmtest.New())},
+		Selector: patterns.SpeakerSelectorFunc(func(ctx context.Context, chat *patterns.GroupChatView) (string, error) {
+			return "manager", nil
+		}),
+	})
+
+	_, err := chat.Run(context.Background(), &agents.AgentInput{Message: userMessage("hi")})
+	assert.EqualError(t, err, `group chat: "manager" is not a member`)
+}
+
+// A member that pauses pauses the chat; the chat resumes on it, and the
+// others never see its tool calls.
+func TestGroupChat_PausedMemberResumes(t *testing.T) {
+	deploy := newFakeTool("deploy", true, "deployed v2")
+	opsLLM := llmtest.New().
+		Respond(llmtest.ToolCallWithID("call_deploy", "deploy", "{}")).
+		RespondText("v2 is live")
+	qaLLM := llmtest.New().RespondText("smoke tests pass. DONE")
+
+	chat := patterns.NewGroupChat(&patterns.GroupChatOptions{
+		Members:   []*patterns.GroupChatMember{chatMember("ops", opsLLM, deploy), chatMember("qa", qaLLM)},
+		Terminate: patterns.TerminateOnText("DONE"),
+	})
+
+	out, err := chat.Run(context.Background(), &agents.AgentInput{Namespace: "test", ThreadID: "t1", Message: userMessage("ship v2")})
+	require.NoError(t, err)
+	assert.Equal(t, agentstate.RunStatusPaused, out.Status)
+	assert.Equal(t, "ops", out.Speaker)
+	require.Len(t, out.Interrupts, 1)
+	assert.Equal(t, "call_deploy", out.Interrupts[0].FunctionCallMessage.CallID)
+
+	out, err = chat.Run(context.Background(), &agents.AgentInput{
+		Namespace: "test", ThreadID: "t1", PreviousRunID: out.RunID,
+		Message: approvalMessage("call_deploy"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, patterns.GroupChatTerminated, out.StopReason)
+	assert.Equal(t, 1, deploy.callCount())
+	assert.Equal(t, []patterns.GroupChatMessage{
+		{Sender: "ops", Text: "v2 is live"},
+		{Sender: "qa", Text: "smoke tests pass. DONE"},
+	}, out.Messages)
+
+	for _, msg := range qaLLM.Request(0).Input.OfInputMessageList {
+		assert.Nil(t, msg.OfFunctionCall)
+		assert.Nil(t, msg.OfFunctionCallOutput)
+	}
+	assert.Contains(t, messagesText(qaLLM.Request(0).Input.OfInputMessageList), "(Agent) ops said: v2 is live")
+}
+
+// planTool shares a plan, as the planner's tools do.
+type planTool struct{ *fakeTool }
+
+func (t *planTool) Execute(ctx context.Context, params *agents.ToolCall) (*agents.ToolCallResponse, error) {
+	resp, err := t.fakeTool.Execute(ctx, params)
+	resp.SharedState = map[string]any{"plan": map[string]any{"goal": "ship v2"}}
+	return resp, err
+}
+
+// A member runs on the chat's stream, and the state it shares joins the
+// chat's rather than replacing it.
+func TestGroupChat_MemberStateJoinsTheChats(t *testing.T) {
+	lead := chatMember("lead", llmtest.New().
+		Respond(llmtest.ToolCallWithID("call_plan", "set_plan", "{}")).
+		RespondText("planned"),
+		&planTool{newFakeTool("set_plan", false, "plan set")})
+
+	chat := patterns.NewGroupChat(&patterns.GroupChatOptions{Members: []*patterns.GroupChatMember{lead}, MaxTurns: 1})
+	_, err := chat.Run(context.Background(), &agents.AgentInput{StreamID: "state-stream", Message: userMessage("plan v2")})
+	require.NoError(t, err)
+
+	chunks, err := chat.StreamBroker().Subscribe(context.Background(), "state-stream")
+	require.NoError(t, err)
+	var states []map[string]any
+	for chunk := range chunks {
+		if chunk.OfStateUpdated != nil {
+			states = append(states, chunk.OfStateUpdated.State)
+		}
+	}
+	require.NotEmpty(t, states)
+	last := states[len(states)-1]
+	assert.Equal(t, "lead", last["speaker"])
+	assert.Equal(t, "ship v2", last["plan"].(map[string]any)["goal"])
+}
+
+// stoppingTool stops the chat and waits for the stop to reach it.
+type stoppingTool struct {
+	*fakeTool
+	chat *patterns.GroupChat
+	cut  bool
+}
+
+func (t *stoppingTool) Execute(ctx context.Context, params *agents.ToolCall) (*agents.ToolCallResponse, error) {
+	_ = t.chat.Stop(ctx, "stop-stream")
+	select {
+	case <-ctx.Done():
+		t.cut = true
+		return nil, ctx.Err()
+	case <-time.After(5 * time.Second):
+		return t.fakeTool.Execute(ctx, params)
+	}
+}
+
+// Stopping the chat stops the member speaking mid-call, and no one speaks
+// after it.
+func TestGroupChat_StopReachesTheSpeaker(t *testing.T) {
+	tool := &stoppingTool{fakeTool: newFakeTool("long_job", false, "done")}
+	qaLLM := llmtest.New().RespondText("checked")
+	chat := patterns.NewGroupChat(&patterns.GroupChatOptions{
+		Members: []*patterns.GroupChatMember{
+			chatMember("ops", llmtest.New().Respond(llmtest.ToolCallWithID("call_job", "long_job", "{}")), tool),
+			chatMember("qa", qaLLM),
+		},
+	})
+	tool.chat = chat
+
+	out, err := chat.Run(context.Background(), &agents.AgentInput{StreamID: "stop-stream", Message: userMessage("run the job")})
+	require.NoError(t, err)
+	assert.Equal(t, patterns.GroupChatStopped, out.StopReason)
+	assert.True(t, tool.cut)
+	assert.Equal(t, 0, qaLLM.Calls())
+}
@@ -0,0 +1,159 @@
+package patterns_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/history"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/messages"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+)
+
+// fakeTool counts executions and returns a fixed text output.
+type fakeTool struct {
+	*agents.BaseTool
+	output string
+	mu     sync.Mutex
+	calls  int
+}
+
+func newFakeTool(name string, requiresApproval bool, output string) *fakeTool {
+	return &fakeTool{
+		BaseTool: &agents.BaseTool{
+			ToolUnion: responses.ToolUnion{
+				OfFunction: &responses.FunctionTool{
+					Name:        name,
+					Description: utils.Ptr("test tool"),
+					Parameters:  map[string]any{"type": "object", "properties": map[string]any{}},
+				},
+			},
+			RequiresApproval: requiresApproval,
+		},
+		output: output,
+	}
+}
+
+func (t *fakeTool) Execute(ctx context.Context, params *agents.ToolCall) (*agents.ToolCallResponse, error) {
+	t.mu.Lock()
+	t.calls++
+	t.mu.Unlock()
+	return &agents.ToolCallResponse{
+		FunctionCallOutputMessage: &responses.FunctionCallOutputMessage{
+			ID:     params.ID,
+			CallID: params.CallID,
+			Output: responses.FunctionCallOutputContentUnion{OfString: utils.Ptr(t.output)},
+		},
+	}, nil
+}
+
+func (t *fakeTool) callCount() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.calls
+}
+
+func userMessage(text string) history.Message {
+	return messages.New("user", []responses.InputMessageUnion{{
+		OfEasyInput: &responses.EasyMessage{
+			Role:    constants.RoleUser,
+			Content: responses.EasyInputContentUnion{OfString: utils.Ptr(text)},
+		},
+	}})
+}
+
+func approvalMessage(approved ...string) history.Message {
+	resolutions := make([]responses.InterruptResolution, 0, len(approved))
+	for _, id := range approved {
+		resolutions = append(resolutions, responses.InterruptResolution{CallID: id, Action: responses.InterruptActionApprove})
+	}
+	return messages.New("user", []responses.InputMessageUnion{{
+		OfFunctionCallInterruptResolution: &responses.FunctionCallInterruptResolutionMessage{
+			Resolutions: resolutions,
+		},
+	}})
+}
+
+func elicitationMessage(callID string, content string) history.Message {
+	return messages.New("user", []responses.InputMessageUnion{{
+		OfFunctionCallInterruptResolution: &responses.FunctionCallInterruptResolutionMessage{
+			Resolutions: []responses.InterruptResolution{{
+				CallID:  callID,
+				Action:  responses.InterruptActionApprove,
+				Content: json.RawMessage(content),
+			}},
+		},
+	}})
+}
+
+func runAgent(t *testing.T, agent *agents.Agent, in *agents.AgentInput) *agents.AgentOutput {
+	t.Helper()
+	handle, err := agent.Execute(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	out, err := handle.Result()
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	return out
+}
+
+// messagesText flattens the text carried by a message list — assistant
+// text, user text, and tool outputs — for substring assertions.
+func messagesText(msgs []responses.InputMessageUnion) string {
+	var b strings.Builder
+	for _, m := range msgs {
+		switch {
+		case m.OfOutputMessage != nil && m.OfOutputMessage.Content != nil:
+			for _, c := range *m.OfOutputMessage.Content {
+				if c.OfOutputText != nil {
+					b.WriteString(c.OfOutputText.Text)
+				}
+			}
+		case m.OfInputMessage != nil:
+			for _, c := range m.OfInputMessage.Content {
+				if c.OfInputText != nil {
+					b.WriteString(c.OfInputText.Text)
+				}
+				if c.OfOutputText != nil {
+					b.WriteString(c.OfOutputText.Text)
+				}
+			}
+		case m.OfEasyInput != nil && m.OfEasyInput.Content.OfString != nil:
+			b.WriteString(*m.OfEasyInput.Content.OfString)
+		case m.OfFunctionCallOutput != nil && m.OfFunctionCallOutput.Output.OfString != nil:
+			b.WriteString(*m.OfFunctionCallOutput.Output.OfString)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func requireStatus(t *testing.T, out *agents.AgentOutput, want agentstate.RunStatus) {
+	t.Helper()
+	if out.Status != want {
+		t.Fatalf("run status = %q, want %q", out.Status, want)
+	}
+}
+
+func requireSinglePendingApproval(t *testing.T, out *agents.AgentOutput, toolName, callID string) {
+	t.Helper()
+	if len(out.Interrupts) != 1 {
+		t.Fatalf("interrupts = %d, want 1: %+v", len(out.Interrupts), out.Interrupts)
+	}
+	intr := out.Interrupts[0]
+	if intr.Mode != responses.InterruptModeApproval {
+		t.Fatalf("interrupt mode = %q, want approval", intr.Mode)
+	}
+	pa := intr.FunctionCallMessage
+	if pa.Name != toolName || pa.CallID != callID {
+		t.Fatalf("pending approval = %s/%s, want %s/%s", pa.Name, pa.CallID, toolName, callID)
+	}
+}
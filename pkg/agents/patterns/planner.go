@@ -0,0 +1,592 @@
+// Package patterns builds agents that work in a set shape out of the plain
+// agent loop: their structure lives in their tools and run state, so they
+// pause, resume and replay like any other agent.
+package patterns
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/bytedance/sonic"
+	"github.com/google/uuid"
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/history"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/prompts"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+)
+
+// PlannerInstruction is the planner's prompt when its options give none.
+const PlannerInstruction = `You work in two phases: plan, then execute.
+
+First call update_plan with the goal and the steps to reach it. Keep each step small enough to be done on its own; the agent doing it sees the goal and what earlier steps found, nothing else.
+
+Then call execute_next_step, once per step. After each, look at what the step found: if the rest of the plan no longer fits, call update_plan with the steps that are left. Steps already done are kept.
+
+When no step is left, answer the user from what the steps found.`
+
+// Planner makes an agent plan before it acts. The agent keeps its plan in
+// the run's state, where it outlives a pause and is shared with the client
+// (on AG-UI, as STATE_SNAPSHOT and STATE_DELTA events). Each step is handed
+// to Executor, an agent with the tools the work needs; the planner itself
+// only plans.
+//
+// With ReviewSteps, the run pauses before every step for the user to approve
+// it. An approval may carry an edited plan — {"goal": ..., "steps": [...]}
+// as its content — which replaces the steps not yet done before the next
+// one runs. A rejection skips running the step and leaves the planner to
+// decide what to do instead.
+//
+// The planner's tools take one call a turn. A second one in the same turn —
+// two steps at once, or a step beside a new plan — is answered with an error
+// rather than run on the plan as the turn began. The calls of a turn are told
+// apart by the process that runs them, so a Planner is shared by the agents
+// it plans for rather than copied.
+type Planner struct {
+	Executor    *agents.Agent
+	ReviewSteps bool
+
+	mu    sync.Mutex
+	taken map[string]planClaim
+}
+
+// NewPlanner is NewAgent for an agent that plans with planner.
+func NewPlanner(opts *agents.AgentOptions, planner *Planner) *agents.Agent {
+	return agents.NewAgent(planner.Options(opts))
+}
+
+// Options returns a copy of opts with the planner's tools added, and
+// PlannerInstruction as its instruction if it has none. The durable runtimes
+// build their agents from options; this is what to register with them.
+func (p *Planner) Options(opts *agents.AgentOptions) *agents.AgentOptions {
+	out := *opts
+	out.Tools = append(append([]agents.Tool(nil), opts.Tools...), p.Tools()...)
+	if out.Instruction == nil {
+		out.Instruction = prompts.New(PlannerInstruction)
+	}
+	return &out
+}
+
+// Tools are update_plan and execute_next_step, for an agent wired up by hand.
+func (p *Planner) Tools() []agents.Tool {
+	return []agents.Tool{
+		&updatePlanTool{planner: p, BaseTool: &agents.BaseTool{
+			ToolUnion: responses.ToolUnion{
+				OfFunction: &responses.FunctionTool{
+					Name:        "update_plan",
+					Description: utils.Ptr("Sets the plan: the goal and the steps still to do, in order. Steps already done are kept; the ones not yet done are replaced."),
+					Parameters: map[string]any{
+						"type":     "object",
+						"required": []string{"steps"},
+						"properties": map[string]any{
+							"goal": map[string]any{
+								"type":        "string",
+								"description": "What the plan is for. Leave empty to keep the current goal.",
+							},
+							"steps": map[string]any{
+								"type":        "array",
+								"description": "The steps still to do, in order",
+								"items":       map[string]any{"type": "string"},
+							},
+						},
+						"additionalProperties": false,
+					},
+				},
+			},
+		}},
+		&executeStepTool{
+			BaseTool: &agents.BaseTool{
+				ToolUnion: responses.ToolUnion{
+					OfFunction: &responses.FunctionTool{
+						Name:        "execute_next_step",
+						Description: utils.Ptr("Does the next step of the plan and answers with what it found, and the steps left."),
+						Parameters: map[string]any{
+							"type":                 "object",
+							"properties":           map[string]any{},
+							"additionalProperties": false,
+						},
+					},
+				},
+			},
+			planner: p,
+		},
+	}
+}
+
+// PlanStepStatus is where a step of a plan is.
+type PlanStepStatus string
+
+const (
+	PlanStepPending    PlanStepStatus = "pending"
+	PlanStepInProgress PlanStepStatus = "in_progress"
+	PlanStepCompleted  PlanStepStatus = "completed"
+	PlanStepFailed     PlanStepStatus = "failed"
+)
+
+// Plan is a goal and the steps toward it, in order.
+type Plan struct {
+	Goal  string     `json:"goal"`
+	Steps []PlanStep `json:"steps"`
+}
+
+// PlanStep is one step of a Plan. Result is what the step found once it
+// completed, or why it failed.
+type PlanStep struct {
+	Title  string         `json:"title"`
+	Status PlanStepStatus `json:"status"`
+	Result string         `json:"result,omitempty"`
+}
+
+// NextStep is the index of the first step still pending, or -1 when none is.
+func (p *Plan) NextStep() int {
+	if p == nil {
+		return -1
+	}
+	for i, step := range p.Steps {
+		if step.Status == PlanStepPending || step.Status == PlanStepInProgress {
+			return i
+		}
+	}
+	return -1
+}
+
+// Clone returns a copy of p that shares nothing with it.
+func (p *Plan) Clone() *Plan {
+	if p == nil {
+		return nil
+	}
+	return &Plan{Goal: p.Goal, Steps: append([]PlanStep(nil), p.Steps...)}
+}
+
+// planStateKey is where the plan is kept in the run's state, and under which
+// it is shared with the client.
+const planStateKey = "plan"
+
+// planState is the plan as kept in the run's state. Revision counts the
+// planner's calls that have answered, which tells the calls of one turn —
+// each handed the state as the turn began — from those of the next.
+type planState struct {
+	Plan     *Plan `json:"plan,omitempty"`
+	Revision int   `json:"revision"`
+}
+
+func loadPlanState(params *agents.ToolCall) (planState, error) {
+	var state planState
+	raw, ok := params.State[planStateKey]
+	if !ok || raw == "" {
+		return state, nil
+	}
+	if err := sonic.Unmarshal([]byte(raw), &state); err != nil {
+		return state, fmt.Errorf("%s: malformed saved plan: %w", params.Name, err)
+	}
+	return state, nil
+}
+
+// planClaim is the call that took a run's plan at a revision.
+type planClaim struct {
+	revision int
+	callID   string
+	name     string
+}
+
+func planClaimKey(params *agents.ToolCall) string {
+	return params.Namespace + "/" + params.ThreadID + "/" + params.AgentName
+}
+
+// take claims the run's plan at revision for the call. It fails, naming the
+// call that holds it, when another call of the same turn took it first.
+func (p *Planner) take(params *agents.ToolCall, revision int) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := planClaimKey(params)
+	if claim, ok := p.taken[key]; ok && claim.revision == revision && claim.callID != params.CallID {
+		return claim.name, false
+	}
+	if p.taken == nil {
+		p.taken = map[string]planClaim{}
+	}
+	p.taken[key] = planClaim{revision: revision, callID: params.CallID, name: params.Name}
+	return "", true
+}
+
+// release gives back the call's claim when it did not answer, so the plan is
+// free for the next turn's calls.
+func (p *Planner) release(params *agents.ToolCall, revision int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := planClaimKey(params)
+	if claim, ok := p.taken[key]; ok && claim.revision == revision && claim.callID == params.CallID {
+		delete(p.taken, key)
+	}
+}
+
+// execute runs one of the planner's tools on the run's plan. The call takes
+// the plan for its turn, and its answer moves the plan to the next revision
+// — with the plan do returns, when it changed one, which is then shared with
+// the client.
+func (p *Planner) execute(ctx context.Context, params *agents.ToolCall, do func(plan *Plan) (*agents.ToolCallResponse, *Plan, error)) (*agents.ToolCallResponse, error) {
+	state, err := loadPlanState(params)
+	if err != nil {
+		return nil, err
+	}
+	if first, ok := p.take(params, state.Revision); !ok {
+		return answer(params, fmt.Sprintf("Not run: %s cannot run in the same turn as %s. Call it again once you have %s's result.", params.Name, first, first)), nil
+	}
+
+	resp, plan, err := do(state.Plan.Clone())
+	if err == nil && ctx.Err() == nil {
+		err = keepPlan(resp, state, plan)
+	}
+	if err != nil || ctx.Err() != nil {
+		p.release(params, state.Revision)
+		return nil, cmp.Or(err, ctx.Err())
+	}
+	return resp, nil
+}
+
+// keepPlan writes the plan's next revision into resp's state updates, and
+// shares plan with the client when the call changed it.
+func keepPlan(resp *agents.ToolCallResponse, state planState, plan *Plan) error {
+	next := planState{Plan: state.Plan, Revision: state.Revision + 1}
+	if plan != nil {
+		next.Plan = plan
+
+		var shared map[string]any
+		buf, err := sonic.Marshal(plan)
+		if err == nil {
+			err = sonic.Unmarshal(buf, &shared)
+		}
+		if err != nil {
+			return err
+		}
+		resp.SharedState = map[string]any{planStateKey: shared}
+	}
+
+	buf, err := sonic.Marshal(next)
+	if err != nil {
+		return err
+	}
+	if resp.StateUpdates == nil {
+		resp.StateUpdates = map[string]string{}
+	}
+	resp.StateUpdates[planStateKey] = string(buf)
+	return nil
+}
+
+// planEdit is update_plan's arguments, and the content of an approval that
+// edits the plan.
+type planEdit struct {
+	Goal  string   `json:"goal"`
+	Steps []string `json:"steps"`
+}
+
+// apply replaces the steps of plan not yet done with the edit's. Done steps
+// stay: they ran, and what they found is already in the conversation.
+func (e planEdit) apply(plan *Plan) *Plan {
+	out := &Plan{Goal: e.Goal}
+	if plan != nil {
+		if out.Goal == "" {
+			out.Goal = plan.Goal
+		}
+		for _, step := range plan.Steps {
+			if step.Status == PlanStepCompleted || step.Status == PlanStepFailed {
+				out.Steps = append(out.Steps, step)
+			}
+		}
+	}
+	for _, title := range e.Steps {
+		if title = strings.TrimSpace(title); title != "" {
+			out.Steps = append(out.Steps, PlanStep{Title: title, Status: PlanStepPending})
+		}
+	}
+	return out
+}
+
+type updatePlanTool struct {
+	*agents.BaseTool
+	planner *Planner
+}
+
+func (t *updatePlanTool) Execute(ctx context.Context, params *agents.ToolCall) (*agents.ToolCallResponse, error) {
+	var edit planEdit
+	if err := sonic.Unmarshal([]byte(params.Arguments), &edit); err != nil {
+		return nil, err
+	}
+
+	return t.planner.execute(ctx, params, func(plan *Plan) (*agents.ToolCallResponse, *Plan, error) {
+		if edit.Goal == "" && plan == nil {
+			return nil, nil, errors.New("update_plan: the first plan needs a goal")
+		}
+		plan = edit.apply(plan)
+		return answer(params, "Plan updated.\n\n"+renderPlan(plan)), plan, nil
+	})
+}
+
+type executeStepTool struct {
+	*agents.BaseTool
+	planner *Planner
+}
+
+// stepPhase is where an execute_next_step call paused.
+type stepPhase string
+
+const (
+	// stepPhaseReview is waiting on the user's approval of the step.
+	stepPhaseReview stepPhase = "review"
+	// stepPhaseExecuting is waiting on an approval inside the executor's run.
+	stepPhaseExecuting stepPhase = "executing"
+)
+
+// stepState is an execute_next_step call as kept across a pause.
+type stepState struct {
+	Phase      stepPhase             `json:"phase"`
+	Step       int                   `json:"step"`
+	ThreadID   string                `json:"thread_id,omitempty"`
+	RunID      string                `json:"run_id,omitempty"`
+	Interrupts []responses.Interrupt `json:"interrupts,omitempty"`
+}
+
+func (t *executeStepTool) Execute(ctx context.Context, params *agents.ToolCall) (*agents.ToolCallResponse, error) {
+	// With no step left the call changes nothing, so it does not take the
+	// plan from a call beside it that might add steps.
+	if !params.ShouldResume {
+		state, err := loadPlanState(params)
+		if err != nil {
+			return nil, err
+		}
+		if state.Plan.NextStep() < 0 {
+			return answer(params, noStepLeft), nil
+		}
+	}
+
+	return t.planner.execute(ctx, params, func(plan *Plan) (*agents.ToolCallResponse, *Plan, error) {
+		return t.step(ctx, params, plan)
+	})
+}
+
+const noStepLeft = "No step is left to do. Answer from what the steps found, or call update_plan to add steps."
+
+// step does the next step of plan, or goes on with the one the call paused
+// on, and returns the plan as the step left it.
+func (t *executeStepTool) step(ctx context.Context, params *agents.ToolCall, plan *Plan) (*agents.ToolCallResponse, *Plan, error) {
+	if !params.ShouldResume {
+		step := plan.NextStep()
+		if step < 0 {
+			return answer(params, noStepLeft), nil, nil
+		}
+		if t.planner.ReviewSteps {
+			return t.review(params, plan, step)
+		}
+		return t.run(ctx, params, plan, step, uuid.NewString(), "", nil)
+	}
+
+	raw, ok := params.State[t.getStateKey(params.ID)]
+	if !ok || raw == "" {
+		return nil, nil, fmt.Errorf("execute_next_step: cannot resume — saved step missing for tool call %s", params.ID)
+	}
+	var state stepState
+	if err := sonic.Unmarshal([]byte(raw), &state); err != nil {
+		return nil, nil, fmt.Errorf("execute_next_step: malformed saved step: %w", err)
+	}
+
+	// The loop hands over every decision made on this call so far —
+	// including, once the step runs, the approval of the step itself — so
+	// each phase takes only the ones it is waiting on.
+	resolutions := map[string]responses.InterruptResolution{}
+	for _, msg := range params.ResumeMessages {
+		if msg.OfFunctionCallInterruptResolution == nil {
+			continue
+		}
+		for _, res := range msg.OfFunctionCallInterruptResolution.Resolutions {
+			resolutions[res.CallID] = res
+		}
+	}
+
+	switch state.Phase {
+	case stepPhaseReview:
+		res, ok := resolutions[params.CallID]
+		if !ok {
+			return t.review(params, plan, state.Step)
+		}
+		step := state.Step
+		if len(res.Content) > 0 && string(res.Content) != "null" {
+			var edit planEdit
+			if err := sonic.Unmarshal(res.Content, &edit); err != nil {
+				return nil, nil, fmt.Errorf("execute_next_step: malformed plan edit: %w", err)
+			}
+			plan = edit.apply(plan)
+			if step = plan.NextStep(); step < 0 {
+				return answer(params, "The user removed the steps that were left.\n\n"+renderPlan(plan)), plan, nil
+			}
+		}
+		return t.run(ctx, params, plan, step, uuid.NewString(), "", nil)
+
+	case stepPhaseExecuting:
+		var forward []responses.InterruptResolution
+		for _, intr := range state.Interrupts {
+			if res, ok := resolutions[intr.FunctionCallMessage.CallID]; ok {
+				forward = append(forward, res)
+			}
+		}
+		if len(forward) == 0 {
+			return t.paused(params, plan, state)
+		}
+		return t.run(ctx, params, plan, state.Step, state.ThreadID, state.RunID, forward)
+	}
+
+	return nil, nil, fmt.Errorf("execute_next_step: unknown saved phase %q", state.Phase)
+}
+
+// review pauses the call for the user to approve the step. The interrupt
+// shows the step and the plan in place of the call's empty arguments.
+func (t *executeStepTool) review(params *agents.ToolCall, plan *Plan, step int) (*agents.ToolCallResponse, *Plan, error) {
+	args, err := sonic.Marshal(map[string]any{
+		"step": plan.Steps[step].Title,
+		"plan": plan,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	call := *params.FunctionCallMessage
+	call.Arguments = string(args)
+
+	return t.paused(params, plan, stepState{
+		Phase: stepPhaseReview,
+		Step:  step,
+		Interrupts: []responses.Interrupt{{
+			FunctionCallMessage: call,
+			Mode:                responses.InterruptModeApproval,
+		}},
+	})
+}
+
+// paused answers the call with its interrupts, keeping where it is.
+func (t *executeStepTool) paused(params *agents.ToolCall, plan *Plan, state stepState) (*agents.ToolCallResponse, *Plan, error) {
+	buf, err := sonic.Marshal(state)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &agents.ToolCallResponse{
+		StateUpdates: map[string]string{t.getStateKey(params.ID): string(buf)},
+		Interrupts:   state.Interrupts,
+	}, plan, nil
+}
+
+// run hands the step to the executor, or continues its run with the given
+// resolutions, and records on the plan how the step went.
+func (t *executeStepTool) run(ctx context.Context, params *agents.ToolCall, plan *Plan, step int, threadID, previousRunID string, resolutions []responses.InterruptResolution) (*agents.ToolCallResponse, *Plan, error) {
+	var messages []responses.InputMessageUnion
+	if len(resolutions) > 0 {
+		messages = []responses.InputMessageUnion{{
+			OfFunctionCallInterruptResolution: &responses.FunctionCallInterruptResolutionMessage{
+				Resolutions: resolutions,
+			},
+		}}
+	} else {
+		messages = []responses.InputMessageUnion{{
+			OfEasyInput: &responses.EasyMessage{
+				Role:    constants.RoleUser,
+				Content: responses.EasyInputContentUnion{OfString: utils.Ptr(stepMessage(plan, step))},
+			},
+		}}
+	}
+
+	plan.Steps[step].Status = PlanStepInProgress
+	params.ReportProgress(ctx, agents.ToolProgress{
+		Progress: float64(step),
+		Total:    float64(len(plan.Steps)),
+		Message:  plan.Steps[step].Title,
+	})
+
+	handle, err := t.planner.Executor.Execute(ctx, &agents.AgentInput{
+		Namespace:     params.Namespace + "/" + params.Name,
+		ThreadID:      threadID,
+		PreviousRunID: previousRunID,
+		Message:       history.Message{SenderID: params.AgentName, Messages: messages},
+		SessionID:     params.SessionID,
+		Budget:        params.Budget,
+	})
+	var result *agents.AgentOutput
+	if err == nil {
+		result, err = handle.Result()
+	}
+
+	switch {
+	case err != nil:
+		plan.Steps[step].Status, plan.Steps[step].Result = PlanStepFailed, err.Error()
+	case result.Status == agentstate.RunStatusPaused:
+		return t.paused(params, plan, stepState{
+			Phase:      stepPhaseExecuting,
+			Step:       step,
+			ThreadID:   threadID,
+			RunID:      result.RunID,
+			Interrupts: result.Interrupts,
+		})
+	case result.Status == agentstate.RunStatusCompleted:
+		plan.Steps[step].Status, plan.Steps[step].Result = PlanStepCompleted, result.Text()
+	default:
+		plan.Steps[step].Status, plan.Steps[step].Result = PlanStepFailed, fmt.Sprintf("run ended with status %s", result.Status)
+	}
+
+	outcome := "completed"
+	if plan.Steps[step].Status == PlanStepFailed {
+		outcome = "failed"
+	}
+	resp := answer(params, fmt.Sprintf("Step %d %s: %s\n\n%s", step+1, outcome, plan.Steps[step].Result, renderPlan(plan)))
+	if result != nil {
+		resp.Spend = result.Spend
+	}
+	return resp, plan, nil
+}
+
+func (t *executeStepTool) getStateKey(toolCallId string) string {
+	return fmt.Sprintf("plan_step/%s/%s", t.planner.Executor.Name, toolCallId)
+}
+
+// stepMessage is what the executor is asked: the goal, what the steps before
+// found, and its own step.
+func stepMessage(plan *Plan, step int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Goal: %s\n\n", plan.Goal)
+	var done []string
+	for i, s := range plan.Steps[:step] {
+		if s.Status == PlanStepCompleted {
+			done = append(done, fmt.Sprintf("%d. %s\n%s", i+1, s.Title, s.Result))
+		}
+	}
+	if len(done) > 0 {
+		fmt.Fprintf(&b, "Done so far:\n%s\n\n", strings.Join(done, "\n\n"))
+	}
+	fmt.Fprintf(&b, "Your step (%d of %d): %s", step+1, len(plan.Steps), plan.Steps[step].Title)
+	return b.String()
+}
+
+// renderPlan is the plan as the planner reads it back.
+func renderPlan(plan *Plan) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Goal: %s", plan.Goal)
+	for i, step := range plan.Steps {
+		fmt.Fprintf(&b, "\n%d. [%s] %s", i+1, step.Status, step.Title)
+	}
+	return b.String()
+}
+
+func answer(params *agents.ToolCall, output string) *agents.ToolCallResponse {
+	return &agents.ToolCallResponse{
+		FunctionCallOutputMessage: &responses.FunctionCallOutputMessage{
+			ID:     params.ID,
+			CallID: params.CallID,
+			Output: responses.FunctionCallOutputContentUnion{
+				OfString: utils.Ptr(output),
+			},
+		},
+	}
+}
@@ -0,0 +1,228 @@
+package patterns_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/patterns"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/streambroker"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/llmtest"
+)
+
+func newPlanner(plannerLLM, executorLLM llm.Provider, broker agents.StreamBroker, review bool, executorTools ...agents.Tool) *agents.Agent {
+	executor := agents.NewAgent(&agents.AgentOptions{
+		Name:         "executor",
+		LLM:          executorLLM,
+		StreamBroker: streambroker.NewMemoryStreamBroker(),
+		Tools:        executorTools,
+	})
+
+	return patterns.NewPlanner(&agents.AgentOptions{
+		Name:         "planner",
+		LLM:          plannerLLM,
+		StreamBroker: broker,
+	}, &patterns.Planner{Executor: executor, ReviewSteps: review})
+}
+
+// streamedPlans is every plan the run sent its client, in order.
+func streamedPlans(t *testing.T, broker agents.StreamBroker, streamID string) []map[string]any {
+	t.Helper()
+	chunks, err := broker.Subscribe(context.Background(), streamID)
+	require.NoError(t, err)
+
+	var plans []map[string]any
+	for chunk := range chunks {
+		if chunk.OfStateUpdated != nil {
+			plan, _ := chunk.OfStateUpdated.State["plan"].(map[string]any)
+			plans = append(plans, plan)
+		}
+	}
+	return plans
+}
+
+func stepStatuses(plan map[string]any) []string {
+	var statuses []string
+	steps, _ := plan["steps"].([]any)
+	for _, step := range steps {
+		statuses = append(statuses, step.(map[string]any)["status"].(string))
+	}
+	return statuses
+}
+
+func TestPlanner_PlansExecutesAndReplans(t *testing.T) {
+	broker := streambroker.NewMemoryStreamBroker()
+	plannerLLM := llmtest.New().
+		Respond(llmtest.ToolCallWithID("call_plan", "update_plan", `{"goal":"compare pricing","steps":["find acme's price","find globex's price","write a summary"]}`)).
+		Respond(llmtest.ToolCallWithID("call_step_1", "execute_next_step", "{}")).
+		Respond(llmtest.ToolCallWithID("call_replan", "update_plan", `{"steps":["write a summary"]}`)).
+		Respond(llmtest.ToolCallWithID("call_step_2", "execute_next_step", "{}")).
+		Respond(llmtest.ToolCallWithID("call_step_3", "execute_next_step", "{}")).
+		RespondText("acme charges $10")
+	executorLLM := llmtest.New().
+		RespondText("acme charges $10").
+		RespondText("only acme publishes prices: $10")
+
+	out := runAgent(t, newPlanner(plannerLLM, executorLLM, broker, false), &agents.AgentInput{
+		StreamID: "plan-stream",
+		Message:  userMessage("compare acme and globex pricing"),
+	})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+
+	// Each step was handed the goal and what the steps before it found.
+	first := messagesText(executorLLM.Request(0).Input.OfInputMessageList)
+	assert.Contains(t, first, "Goal: compare pricing")
+	assert.Contains(t, first, "Your step (1 of 3): find acme's price")
+	second := messagesText(executorLLM.Request(1).Input.OfInputMessageList)
+	assert.Contains(t, second, "acme charges $10")
+	assert.Contains(t, second, "Your step (2 of 2): write a summary")
+
+	// The replan kept the step that was done.
+	assert.Contains(t, messagesText(plannerLLM.Request(3).Input.OfInputMessageList), "1. [completed] find acme's price\n2. [pending] write a summary")
+	assert.Contains(t, messagesText(plannerLLM.Request(5).Input.OfInputMessageList), "No step is left to do.")
+
+	plans := streamedPlans(t, broker, "plan-stream")
+	require.Len(t, plans, 4)
+	assert.Equal(t, []string{"pending", "pending", "pending"}, stepStatuses(plans[0]))
+	assert.Equal(t, []string{"completed", "pending", "pending"}, stepStatuses(plans[1]))
+	assert.Equal(t, []string{"completed", "pending"}, stepStatuses(plans[2]))
+	assert.Equal(t, []string{"completed", "completed"}, stepStatuses(plans[3]))
+	assert.Equal(t, "compare pricing", plans[3]["goal"])
+}
+
+// With ReviewSteps the user sees each step before it runs, and can change
+// what is left of the plan in the approval.
+func TestPlanner_ReviewedStepCanBeEdited(t *testing.T) {
+	broker := streambroker.NewMemoryStreamBroker()
+	plannerLLM := llmtest.New().
+		Respond(llmtest.ToolCallWithID("call_plan", "update_plan", `{"goal":"tidy the docs","steps":["rewrite the readme","delete old pages"]}`)).
+		Respond(llmtest.ToolCallWithID("call_step", "execute_next_step", "{}")).
+		RespondText("the readme is fixed")
+	executorLLM := llmtest.New().RespondText("fixed typos in the readme")
+	planner := newPlanner(plannerLLM, executorLLM, broker, true)
+
+	out := runAgent(t, planner, &agents.AgentInput{Namespace: "test", ThreadID: "t1", Message: userMessage("tidy the docs")})
+	requireStatus(t, out, agentstate.RunStatusPaused)
+	requireSinglePendingApproval(t, out, "execute_next_step", "call_step")
+	assert.Contains(t, out.Interrupts[0].FunctionCallMessage.Arguments, `"step":"rewrite the readme"`)
+	assert.Equal(t, 0, executorLLM.Calls())
+
+	out = runAgent(t, planner, &agents.AgentInput{
+		Namespace: "test", ThreadID: "t1", PreviousRunID: out.RunID, StreamID: "review-stream",
+		Message: elicitationMessage("call_step", `{"steps":["fix typos in the readme"]}`),
+	})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+
+	assert.Contains(t, messagesText(executorLLM.Request(0).Input.OfInputMessageList), "Your step (1 of 1): fix typos in the readme")
+	assert.Contains(t, messagesText(plannerLLM.Request(2).Input.OfInputMessageList), "Step 1 completed: fixed typos in the readme")
+
+	// The resumed run re-sent the plan it picked up, then the edited one.
+	plans := streamedPlans(t, broker, "review-stream")
+	require.Len(t, plans, 2)
+	assert.Equal(t, []string{"pending", "pending"}, stepStatuses(plans[0]))
+	assert.Equal(t, []string{"completed"}, stepStatuses(plans[1]))
+}
+
+func TestPlanner_StepPausesOnTheExecutorsApproval(t *testing.T) {
+	broker := streambroker.NewMemoryStreamBroker()
+	plannerLLM := llmtest.New().
+		Respond(llmtest.ToolCallWithID("call_plan", "update_plan", `{"goal":"refund order A-17","steps":["refund the order"]}`)).
+		Respond(llmtest.ToolCallWithID("call_step", "execute_next_step", "{}")).
+		RespondText("your order is refunded")
+	executorLLM := llmtest.New().
+		Respond(llmtest.ToolCallWithID("call_refund", "refund", "{}")).
+		RespondText("refunded A-17")
+	refund := newFakeTool("refund", true, "refunded")
+	planner := newPlanner(plannerLLM, executorLLM, broker, false, refund)
+
+	out := runAgent(t, planner, &agents.AgentInput{Namespace: "test", ThreadID: "t1", StreamID: "refund-stream", Message: userMessage("refund A-17")})
+	requireStatus(t, out, agentstate.RunStatusPaused)
+	requireSinglePendingApproval(t, out, "refund", "call_refund")
+	assert.True(t, out.Interrupts[0].IsNested)
+
+	// The client sees the step as under way while it waits.
+	plans := streamedPlans(t, broker, "refund-stream")
+	assert.Equal(t, []string{"in_progress"}, stepStatuses(plans[len(plans)-1]))
+
+	out = runAgent(t, planner, &agents.AgentInput{
+		Namespace: "test", ThreadID: "t1", PreviousRunID: out.RunID,
+		Message: approvalMessage("call_refund"),
+	})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Equal(t, "your order is refunded", out.Text())
+	assert.Equal(t, 1, refund.callCount())
+	assert.Equal(t, 2, executorLLM.Calls())
+	assert.Contains(t, messagesText(plannerLLM.Request(2).Input.OfInputMessageList), "Step 1 completed: refunded A-17")
+}
+
+// Calls to the planner's tools in one turn would each start from the plan as
+// the turn began: two steps at once would both run the first. Only the
+// turn's first runs; the other is answered with an error, and the model
+// calls it again on the next turn.
+func TestPlanner_ParallelStepCallsRunOneStep(t *testing.T) {
+	broker := streambroker.NewMemoryStreamBroker()
+	plannerLLM := llmtest.New().
+		Respond(llmtest.ToolCallWithID("call_plan", "update_plan", `{"goal":"compare pricing","steps":["find acme's price","find globex's price"]}`)).
+		Respond(
+			llmtest.ToolCallWithID("call_step_1", "execute_next_step", "{}"),
+			llmtest.ToolCallWithID("call_step_2", "execute_next_step", "{}"),
+		).
+		Respond(llmtest.ToolCallWithID("call_step_3", "execute_next_step", "{}")).
+		RespondText("acme charges $10, globex $12")
+	executorLLM := llmtest.New().
+		RespondText("acme charges $10").
+		RespondText("globex charges $12")
+
+	out := runAgent(t, newPlanner(plannerLLM, executorLLM, broker, false), &agents.AgentInput{
+		StreamID: "parallel-stream",
+		Message:  userMessage("compare acme and globex pricing"),
+	})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+
+	require.Equal(t, 2, executorLLM.Calls())
+	assert.Contains(t, messagesText(executorLLM.Request(0).Input.OfInputMessageList), "Your step (1 of 2): find acme's price")
+	assert.Contains(t, messagesText(executorLLM.Request(1).Input.OfInputMessageList), "Your step (2 of 2): find globex's price")
+
+	afterParallel := messagesText(plannerLLM.Request(2).Input.OfInputMessageList)
+	assert.Contains(t, afterParallel, "Step 1 completed: acme charges $10")
+	assert.Contains(t, afterParallel, "Not run: execute_next_step cannot run in the same turn as execute_next_step.")
+
+	plans := streamedPlans(t, broker, "parallel-stream")
+	assert.Equal(t, []string{"completed", "completed"}, stepStatuses(plans[len(plans)-1]))
+}
+
+// A step beside a new plan in the same turn would run on the old one. The
+// two run at once, so either may take the plan; the other waits for the next
+// turn.
+func TestPlanner_StepBesideANewPlanRunsOneOfThem(t *testing.T) {
+	plannerLLM := llmtest.New().
+		Respond(llmtest.ToolCallWithID("call_plan", "update_plan", `{"goal":"tidy the docs","steps":["rewrite the readme"]}`)).
+		Respond(
+			llmtest.ToolCallWithID("call_replan", "update_plan", `{"steps":["fix typos in the readme"]}`),
+			llmtest.ToolCallWithID("call_step", "execute_next_step", "{}"),
+		).
+		RespondText("planned")
+	executorLLM := llmtest.New().RespondText("rewrote the readme")
+
+	out := runAgent(t, newPlanner(plannerLLM, executorLLM, streambroker.NewMemoryStreamBroker(), false), &agents.AgentInput{
+		Message: userMessage("tidy the docs"),
+	})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+
+	afterTurn := messagesText(plannerLLM.Request(2).Input.OfInputMessageList)
+	assert.Equal(t, 1, strings.Count(afterTurn, "Not run:"))
+	replanned := strings.Contains(afterTurn, "Not run: execute_next_step cannot run in the same turn as update_plan.")
+	stepped := strings.Contains(afterTurn, "Not run: update_plan cannot run in the same turn as execute_next_step.")
+	assert.True(t, replanned != stepped)
+	if replanned {
+		assert.Equal(t, 0, executorLLM.Calls())
+	} else {
+		assert.Contains(t, afterTurn, "Step 1 completed: rewrote the readme")
+	}
+}
@@ -2,6 +2,8 @@ package agents
 
 import (
 	"context"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
 )
 
 // DeferredToolInfo is the projection of a deferred Tool that prompt
@@ -31,6 +33,10 @@ type Dependencies struct {
 	// Temporal activity boundary.
 	Skills    []Skill `json:"skills,omitempty"`
 	SkillHint string  `json:"skill_hint,omitempty"`
+
+	// Handoff is the handoff the agent is running as the target of, with the
+	// reason and arguments it was handed, or nil.
+	Handoff *agentstate.HandoffFrame `json:"handoff,omitempty"`
 }
 
 type SystemPromptProvider interface {
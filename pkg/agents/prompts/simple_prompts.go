@@ -7,6 +7,7 @@ import (
 	"strings"
 	"text/template"
 
+	"github.com/bytedance/sonic"
 	"github.com/hastekit/agent-sdk-go/pkg/agents"
 	"github.com/hastekit/agent-sdk-go/pkg/utils"
 	"go.opentelemetry.io/otel"
@@ -92,6 +93,7 @@ func DefaultResolvers() []PromptResolverFn {
 	return []PromptResolverFn{
 		ResolveSkills,
 		ResolveHandoffs,
+		ResolveHandoffPayload,
 		ResolveDeferredTools,
 		ResolveTemplate,
 	}
@@ -230,6 +232,37 @@ func ResolveHandoffs(prompt string, deps *agents.Dependencies) (string, error) {
 	return prompt + p.String(), nil
 }
 
+// ResolveHandoffPayload appends, for an agent running as a handoff's target,
+// who handed it the conversation, why, and the arguments it was given.
+func ResolveHandoffPayload(prompt string, deps *agents.Dependencies) (string, error) {
+	h := deps.Handoff
+	if h == nil {
+		return prompt, nil
+	}
+
+	var p strings.Builder
+
+	p.WriteString("\n\n" + "## Handoff\n\n")
+	p.WriteString(fmt.Sprintf("%s transferred this conversation to you.", h.Source))
+	if h.Reason != "" {
+		p.WriteString(fmt.Sprintf(" Reason: %s", h.Reason))
+	}
+	p.WriteString("\n")
+	if h.Return {
+		p.WriteString(fmt.Sprintf("When you have finished your part, give your answer: the conversation then returns to %s.\n", h.Source))
+	}
+	if len(h.Arguments) > 0 {
+		args, err := sonic.MarshalString(h.Arguments)
+		if err != nil {
+			return prompt, err
+		}
+		p.WriteString(fmt.Sprintf("<handoff_arguments>%s</handoff_arguments>", args))
+	}
+	p.WriteString("\n---\n")
+
+	return prompt + p.String(), nil
+}
+
 // ResolveDeferredTools appends the tools the model must activate before it can
 // call them.
 func ResolveDeferredTools(prompt string, deps *agents.Dependencies) (string, error) {
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
 	"github.com/hastekit/agent-sdk-go/pkg/agents/prompts"
 )
 
@@ -154,3 +155,29 @@ func TestAResolverErrorStopsTheChain(t *testing.T) {
 		t.Error("GetPrompt succeeded despite a failing resolver")
 	}
 }
+
+func TestHandoffPayloadIsGivenToTheTarget(t *testing.T) {
+	prompt, err := prompts.New("base", prompts.WithResolver(prompts.DefaultResolvers()...)).
+		GetPrompt(context.Background(), &agents.Dependencies{
+			Handoff: &agentstate.HandoffFrame{
+				Source:    "triage",
+				Target:    "billing",
+				Reason:    "refund request",
+				Arguments: map[string]any{"order_id": "A-17"},
+				Return:    true,
+			},
+		})
+	if err != nil {
+		t.Fatalf("GetPrompt: %v", err)
+	}
+
+	for _, want := range []string{
+		"triage transferred this conversation to you. Reason: refund request",
+		"returns to triage",
+		`<handoff_arguments>{"order_id":"A-17"}</handoff_arguments>`,
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt is missing %q:\n%s", want, prompt)
+		}
+	}
+}
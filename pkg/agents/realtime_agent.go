@@ -0,0 +1,346 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/bytedance/sonic"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/realtime"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+)
+
+const (
+	// toolCancelledByBargeIn is what the model is told for a call whose
+	// response the user talked over.
+	toolCancelledByBargeIn = "Tool call cancelled: the user interrupted before it finished."
+
+	toolNeedsApprovalInRealtime = "Tool call refused: this tool requires approval, which is not available in a voice session."
+)
+
+type RealtimeAgentOptions struct {
+	Name string
+
+	// Provider opens the session, e.g. an LLMClient's Model(...) asserted
+	// to realtime.Provider.
+	Provider realtime.Provider
+
+	// Session configures the model, voice, VAD and so on. Its Tools are
+	// replaced by Tools below.
+	Session realtime.SessionConfig
+
+	// Tools the model can call. Deferred tools are left out, since a
+	// realtime session has no tool search; tools that need approval are
+	// offered but refused when called.
+	Tools []Tool
+
+	// RunContext is passed to every tool call.
+	RunContext map[string]any
+}
+
+// RealtimeAgent runs a voice conversation over a realtime session,
+// executing the model's function calls with agents.Tool the way Agent does
+// for a text run.
+type RealtimeAgent struct {
+	opts RealtimeAgentOptions
+}
+
+func NewRealtimeAgent(opts *RealtimeAgentOptions) *RealtimeAgent {
+	return &RealtimeAgent{opts: *opts}
+}
+
+// Connect opens a session and starts serving its function calls. The
+// returned conversation lives until Close or until ctx is done.
+func (a *RealtimeAgent) Connect(ctx context.Context) (*RealtimeConversation, error) {
+	if a.opts.Provider == nil {
+		return nil, errors.New("realtime agent has no provider")
+	}
+
+	var tools []Tool
+	cfg := a.opts.Session
+	cfg.Tools = nil
+	for _, t := range a.opts.Tools {
+		def := t.Tool(ctx)
+		if def == nil || def.OfFunction == nil || t.IsDeferred() {
+			continue
+		}
+		tools = append(tools, t)
+
+		var description string
+		if def.OfFunction.Description != nil {
+			description = *def.OfFunction.Description
+		}
+		cfg.Tools = append(cfg.Tools, realtime.Tool{
+			Name:        def.OfFunction.Name,
+			Description: description,
+			Parameters:  def.OfFunction.Parameters,
+		})
+	}
+
+	session, err := a.opts.Provider.NewRealtimeSession(ctx, &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	c := &RealtimeConversation{
+		Session:    session,
+		agent:      a,
+		tools:      tools,
+		events:     make(chan *realtime.Event, 64),
+		ctx:        ctx,
+		cancel:     cancel,
+		batches:    map[string]*realtimeCallBatch{},
+		inProgress: map[string]*realtimeCall{},
+	}
+	go c.pump()
+
+	return c, nil
+}
+
+// RealtimeConversation is a realtime.Session whose function calls are
+// answered by the agent's tools. Events passes everything through, with an
+// EventFunctionCallOutput after each call is answered.
+type RealtimeConversation struct {
+	realtime.Session
+
+	agent  *RealtimeAgent
+	tools  []Tool
+	events chan *realtime.Event
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu sync.Mutex
+	// batches tracks, per response, the calls still running, so the model
+	// is asked to continue once, after the last of them is answered.
+	batches map[string]*realtimeCallBatch
+	// inProgress holds the running tool calls, by call id.
+	inProgress map[string]*realtimeCall
+}
+
+type realtimeCall struct {
+	responseID string
+	cancel     context.CancelFunc
+}
+
+type realtimeCallBatch struct {
+	pending     int
+	done        bool
+	interrupted bool
+}
+
+var _ realtime.Session = (*RealtimeConversation)(nil)
+
+func (c *RealtimeConversation) Events() <-chan *realtime.Event {
+	return c.events
+}
+
+// Close ends the session and waits for running tool calls to unwind.
+func (c *RealtimeConversation) Close() error {
+	c.cancel()
+	err := c.Session.Close()
+	c.wg.Wait()
+	return err
+}
+
+func (c *RealtimeConversation) pump() {
+	go func() {
+		<-c.ctx.Done()
+		_ = c.Session.Close()
+	}()
+
+	for ev := range c.Session.Events() {
+		// The call goes out before its output can.
+		c.emit(ev)
+
+		switch ev.Type {
+		case realtime.EventFunctionCall:
+			c.startCall(ev)
+		case realtime.EventInterrupted:
+			c.interrupt(ev.ResponseID)
+		case realtime.EventSpeechStarted:
+			c.yieldFloor()
+		case realtime.EventResponseDone:
+			c.finishResponse(ev.ResponseID)
+		}
+	}
+
+	// The session is over; unwind whatever is still running.
+	c.cancel()
+	c.wg.Wait()
+	close(c.events)
+}
+
+func (c *RealtimeConversation) emit(ev *realtime.Event) {
+	select {
+	case c.events <- ev:
+	case <-c.ctx.Done():
+	}
+}
+
+func (c *RealtimeConversation) startCall(ev *realtime.Event) {
+	callCtx, cancel := context.WithCancel(c.ctx)
+
+	c.mu.Lock()
+	batch := c.batches[ev.ResponseID]
+	if batch == nil {
+		batch = &realtimeCallBatch{}
+		c.batches[ev.ResponseID] = batch
+	}
+	batch.pending++
+	c.inProgress[ev.FunctionCall.CallID] = &realtimeCall{responseID: ev.ResponseID, cancel: cancel}
+	c.mu.Unlock()
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer cancel()
+
+		output := c.execute(callCtx, ev)
+		if callCtx.Err() != nil && c.ctx.Err() == nil {
+			output = toolCancelledByBargeIn
+		}
+		c.answer(ev, output)
+	}()
+}
+
+// execute runs one call and renders its outcome as the text the model sees.
+// Failures are reported to the model, as in a text run.
+func (c *RealtimeConversation) execute(ctx context.Context, ev *realtime.Event) string {
+	call := ev.FunctionCall
+
+	tool := findTool(ctx, c.tools, call.Name)
+	if tool == nil {
+		return fmt.Sprintf("Tool execution failed: unknown tool %q", call.Name)
+	}
+	if tool.NeedApproval() {
+		return toolNeedsApprovalInRealtime
+	}
+
+	resp, err := tool.Execute(ctx, &ToolCall{
+		FunctionCallMessage: &responses.FunctionCallMessage{
+			ID:        ev.ItemID,
+			CallID:    call.CallID,
+			Name:      call.Name,
+			Arguments: call.Arguments,
+		},
+		AgentName:  c.agent.opts.Name,
+		RunContext: c.agent.opts.RunContext,
+	})
+	switch {
+	case err != nil:
+		slog.ErrorContext(ctx, "tool execution failed", slog.String("tool_name", call.Name), slog.Any("error", err))
+		return fmt.Sprintf("Tool execution failed: %v", err)
+	case resp == nil || resp.FunctionCallOutputMessage == nil:
+		return "Tool execution failed: tool returned no response"
+	}
+
+	if s := resp.Output.OfString; s != nil {
+		return *s
+	}
+	out, err := sonic.MarshalString(resp.Output.OfList)
+	if err != nil {
+		return fmt.Sprintf("Tool execution failed: %v", err)
+	}
+	return out
+}
+
+// answer sends a call's output and, if it was the last one outstanding for
+// a finished, uninterrupted response, asks the model to carry on.
+func (c *RealtimeConversation) answer(ev *realtime.Event, output string) {
+	callID := ev.FunctionCall.CallID
+
+	if err := c.Session.SendFunctionResult(c.ctx, callID, output); err != nil {
+		if c.ctx.Err() == nil && !errors.Is(err, realtime.ErrSessionClosed) {
+			slog.ErrorContext(c.ctx, "failed to send function result", slog.String("call_id", callID), slog.Any("error", err))
+		}
+		return
+	}
+	c.emit(&realtime.Event{
+		Type:         realtime.EventFunctionCallOutput,
+		ResponseID:   ev.ResponseID,
+		ItemID:       ev.ItemID,
+		FunctionCall: ev.FunctionCall,
+		Text:         output,
+	})
+
+	c.mu.Lock()
+	delete(c.inProgress, callID)
+	batch := c.batches[ev.ResponseID]
+	var resume bool
+	if batch != nil {
+		batch.pending--
+		resume = c.settle(ev.ResponseID, batch)
+	}
+	c.mu.Unlock()
+
+	if resume {
+		c.createResponse()
+	}
+}
+
+func (c *RealtimeConversation) finishResponse(responseID string) {
+	c.mu.Lock()
+	batch := c.batches[responseID]
+	var resume bool
+	if batch != nil {
+		batch.done = true
+		resume = c.settle(responseID, batch)
+	}
+	c.mu.Unlock()
+
+	if resume {
+		c.createResponse()
+	}
+}
+
+// settle drops a batch once its response is done and every call answered,
+// reporting whether the model should be asked to continue. Callers hold mu.
+func (c *RealtimeConversation) settle(responseID string, batch *realtimeCallBatch) bool {
+	if !batch.done || batch.pending > 0 {
+		return false
+	}
+	delete(c.batches, responseID)
+	// After a barge-in the user has the floor; server VAD starts the next
+	// response when they finish.
+	return !batch.interrupted
+}
+
+// interrupt cancels the tool calls of a response the user talked over.
+func (c *RealtimeConversation) interrupt(responseID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	batch := c.batches[responseID]
+	if batch == nil {
+		return
+	}
+	batch.interrupted = true
+	for _, call := range c.inProgress {
+		if call.responseID == responseID {
+			call.cancel()
+		}
+	}
+}
+
+// yieldFloor stops pending calls from prompting a new response once they
+// finish: the user has started talking, and server VAD will respond to them
+// — with the calls' results, if they are in by then.
+func (c *RealtimeConversation) yieldFloor() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, batch := range c.batches {
+		batch.interrupted = true
+	}
+}
+
+func (c *RealtimeConversation) createResponse() {
+	if err := c.Session.CreateResponse(c.ctx); err != nil && c.ctx.Err() == nil {
+		slog.ErrorContext(c.ctx, "failed to resume realtime response", slog.Any("error", err))
+	}
+}
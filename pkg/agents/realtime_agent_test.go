@@ -0,0 +1,149 @@
+package agents_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/realtime"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/realtime/realtimetest"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/openai/openai_realtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// standInProvider dials OpenAI Realtime sessions against a stand-in server.
+type standInProvider struct{ url string }
+
+func (p standInProvider) NewRealtimeSession(ctx context.Context, cfg *realtime.SessionConfig) (realtime.Session, error) {
+	return openai_realtime.Dial(ctx, &openai_realtime.Options{BaseURL: p.url}, cfg)
+}
+
+func connectRealtimeAgent(t *testing.T, tools ...agents.Tool) (*agents.RealtimeConversation, *realtimetest.Conn) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	srv := realtimetest.NewServer()
+	t.Cleanup(srv.Close)
+
+	agent := agents.NewRealtimeAgent(&agents.RealtimeAgentOptions{
+		Name:     "phone",
+		Provider: standInProvider{url: srv.URL},
+		Session:  realtime.SessionConfig{Model: "gpt-realtime"},
+		Tools:    tools,
+	})
+	conv, err := agent.Connect(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conv.Close() })
+
+	conn, err := srv.Accept(ctx)
+	require.NoError(t, err)
+	return conv, conn
+}
+
+// waitForEvent skips conversation events until one of type typ.
+func waitForEvent(t *testing.T, conv *agents.RealtimeConversation, typ realtime.EventType) *realtime.Event {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev, ok := <-conv.Events():
+			require.True(t, ok, "events closed before %s", typ)
+			if ev.Type == typ {
+				return ev
+			}
+		case <-timeout:
+			t.Fatalf("no %s event", typ)
+		}
+	}
+}
+
+func functionCallItem(callID string) map[string]any {
+	return map[string]any{
+		"type": "response.output_item.done", "response_id": "resp_1",
+		"item": map[string]any{"id": "item_" + callID, "type": "function_call", "call_id": callID, "name": "lookup", "arguments": "{}"},
+	}
+}
+
+func TestRealtimeAgent_ExecutesToolsThenResumes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tool := newFakeTool("lookup", false, "order shipped")
+	approval := newFakeTool("refund", true, "refunded")
+	conv, conn := connectRealtimeAgent(t, tool, approval)
+
+	update, err := conn.ReceiveType(ctx, "type", "session.update")
+	require.NoError(t, err)
+	assert.Len(t, update["session"].(map[string]any)["tools"], 2)
+
+	require.NoError(t, conn.Send(map[string]any{"type": "response.created", "response": map[string]any{"id": "resp_1"}}))
+	require.NoError(t, conn.Send(functionCallItem("call_1")))
+	require.NoError(t, conn.Send(map[string]any{
+		"type": "response.output_item.done", "response_id": "resp_1",
+		"item": map[string]any{"id": "item_call_2", "type": "function_call", "call_id": "call_2", "name": "refund", "arguments": "{}"},
+	}))
+	require.NoError(t, conn.Send(map[string]any{"type": "response.done", "response": map[string]any{"id": "resp_1"}}))
+
+	outputs := map[string]string{}
+	for range 2 {
+		msg, err := conn.ReceiveType(ctx, "type", "conversation.item.create")
+		require.NoError(t, err)
+		item := msg["item"].(map[string]any)
+		outputs[item["call_id"].(string)] = item["output"].(string)
+	}
+	assert.Equal(t, "order shipped", outputs["call_1"])
+	assert.Contains(t, outputs["call_2"], "requires approval")
+	assert.Equal(t, 0, approval.callCount())
+
+	// One follow-up response once both calls are answered.
+	_, err = conn.ReceiveType(ctx, "type", "response.create")
+	require.NoError(t, err)
+	assert.Equal(t, 1, tool.callCount())
+
+	out := waitForEvent(t, conv, realtime.EventFunctionCallOutput)
+	assert.NotEmpty(t, out.Text)
+}
+
+func TestRealtimeAgent_BargeInCancelsRunningTools(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tool := newFakeTool("lookup", false, "")
+	started := make(chan struct{})
+	tool.execute = func(ctx context.Context, _ *agents.ToolCall) (*agents.ToolCallResponse, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	conv, conn := connectRealtimeAgent(t, tool)
+
+	require.NoError(t, conn.Send(map[string]any{"type": "response.created", "response": map[string]any{"id": "resp_1"}}))
+	require.NoError(t, conn.Send(functionCallItem("call_1")))
+	<-started
+
+	// The user talks over the model while the tool is still running.
+	require.NoError(t, conn.Send(map[string]any{"type": "input_audio_buffer.speech_started"}))
+	waitForEvent(t, conv, realtime.EventInterrupted)
+
+	out := waitForEvent(t, conv, realtime.EventFunctionCallOutput)
+	assert.Contains(t, out.Text, "interrupted")
+
+	require.NoError(t, conn.Send(map[string]any{"type": "response.done", "response": map[string]any{"id": "resp_1", "status": "cancelled"}}))
+	waitForEvent(t, conv, realtime.EventResponseDone)
+
+	// No follow-up response is requested: the user has the floor. The next
+	// thing the server sees after the cancelled output is the user's turn.
+	require.NoError(t, conv.SendText(ctx, "never mind"))
+
+	msg, err := conn.ReceiveType(ctx, "type", "conversation.item.create")
+	require.NoError(t, err)
+	assert.Equal(t, "function_call_output", msg["item"].(map[string]any)["type"])
+
+	next, err := conn.Receive(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "conversation.item.create", next["type"])
+	assert.Equal(t, "message", next["item"].(map[string]any)["type"])
+}
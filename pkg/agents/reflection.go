@@ -0,0 +1,168 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/history"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/messages"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+)
+
+// DefaultMaxRevisions is how many revisions a critic may ask for when
+// Reflection.MaxRevisions is not set.
+const DefaultMaxRevisions = 2
+
+// Reflection has a critic read the agent's draft answer before the run
+// completes. A draft the critic does not approve goes back to the model with
+// the critique, as a turn of its own, and the revision is read again — until
+// the critic approves or MaxRevisions have been made. The last revision
+// allowed is not read: there would be nothing left to do about a rejection.
+// A run's wrap-up answer, given when its budget ran out, is read but never
+// sent back — the budget has no turn left for a revision — so the verdict
+// tells the client what it is getting.
+//
+// Each verdict is streamed as a reflection.critique chunk, and each revision
+// as a message of its own after it, so a client can show the drafts in turn.
+// What the critic spends is billed to the run, and to its budget.
+type Reflection struct {
+	Critic Critic
+	// MaxRevisions caps the revisions one run may make. Zero means
+	// DefaultMaxRevisions.
+	MaxRevisions int
+}
+
+func (r *Reflection) maxRevisions() int {
+	if r.MaxRevisions > 0 {
+		return r.MaxRevisions
+	}
+	return DefaultMaxRevisions
+}
+
+// Critic reads a draft answer and says whether it is good enough.
+//
+// Like a guardrail it is an interface, so a durable runtime can run each
+// critique as its own journaled step rather than asking again on replay. The
+// patterns package has critics that ask a model, or run an agent.
+type Critic interface {
+	// GetName names the critic in the reflection.critique chunk and its step
+	// under a durable runtime, so it must be stable across deploys.
+	GetName() string
+
+	// Critique returns an approval, or the feedback the draft should be
+	// revised with. An error fails the run.
+	Critique(ctx context.Context, in *CritiqueInput) (CritiqueResult, error)
+}
+
+// CritiqueInput is what a critic is asked to read.
+type CritiqueInput struct {
+	AgentName  string         `json:"agent_name"`
+	Namespace  string         `json:"namespace"`
+	ThreadID   string         `json:"thread_id"`
+	RunID      string         `json:"run_id,omitempty"`
+	RunContext map[string]any `json:"run_context,omitempty"`
+	// Request is the text of the message the run was started with.
+	Request string `json:"request"`
+	// Draft is the text of the answer to critique.
+	Draft string `json:"draft"`
+	// Revision numbers the draft, the first being 0.
+	Revision int `json:"revision"`
+	// Budget is what is left of the run's budget, for a critic that runs an
+	// agent. Nil when the run has none.
+	Budget *RunBudget `json:"budget,omitempty"`
+}
+
+// CritiqueResult is a critic's verdict.
+type CritiqueResult struct {
+	Approved bool   `json:"approved"`
+	Feedback string `json:"feedback,omitempty"`
+	// Usage is what the critique cost, billed to the run.
+	Usage *responses.Usage `json:"usage,omitempty"`
+	// Model is the model that made the critique, which the run's budget
+	// prices Usage by.
+	Model string `json:"model,omitempty"`
+	// Spend is what a critic that ran under the run's budget spent against
+	// it, already priced. When set it is counted in place of Usage.
+	Spend *agentstate.BudgetSpend `json:"spend,omitempty"`
+}
+
+// revisionMessage hands the critique back to the model. Like an output repair
+// it is a turn of the conversation, saved with the run.
+func revisionMessage(critique CritiqueResult) responses.InputMessageUnion {
+	text := "A reviewer read your answer and asked for changes:\n\n" + critique.Feedback +
+		"\n\nRevise your answer to address this. Reply with the full revised answer: it replaces the one above."
+
+	return responses.InputMessageUnion{
+		OfInputMessage: &responses.InputMessage{
+			Role: constants.RoleUser,
+			Content: responses.InputContent{
+				{OfInputText: &responses.InputTextContent{Text: text}},
+			},
+		},
+	}
+}
+
+// reflect has the critic read the draft, and reports whether it sent it back
+// for a revision. The critique is billed to the run either way. A wrap-up
+// answer is read, but never sent back.
+func (e *Agent) reflect(ctx context.Context, in *AgentInput, runId string, run *history.ConversationRunManager, draft []responses.InputMessageUnion, wrappingUp bool) (bool, error) {
+	if e.reflection == nil || e.reflection.Critic == nil || (!wrappingUp && run.RunState.Revisions >= e.reflection.maxRevisions()) {
+		return false, nil
+	}
+
+	now, err := e.budgetNow(in.Budget)
+	if err != nil {
+		return false, err
+	}
+
+	critic := e.reflection.Critic
+	critique, err := critic.Critique(ctx, &CritiqueInput{
+		AgentName:  e.Name,
+		Namespace:  in.Namespace,
+		ThreadID:   in.ThreadID,
+		RunID:      runId,
+		RunContext: in.RunContext,
+		Request:    run.RunState.Request,
+		Draft:      guardrailText(draft),
+		Revision:   run.RunState.Revisions,
+		Budget:     in.Budget.remaining(run.RunState.Budget, now),
+	})
+	if err != nil {
+		return false, fmt.Errorf("reflection critic %q: %w", critic.GetName(), err)
+	}
+
+	run.TrackAuxiliaryUsage(critique.Usage)
+	if critique.Spend != nil {
+		if run.RunState.Budget != nil {
+			run.RunState.Budget.Spent.Add(critique.Spend)
+		}
+	} else {
+		in.Budget.trackUsage(run.RunState.Budget, critique.Model, critique.Usage)
+	}
+
+	// Durable step: emit the verdict once, not on every replay.
+	revision := run.RunState.Revisions
+	e.durableStep.Do(func() {
+		e.publisher(in.StreamID)(&responses.ResponseChunk{
+			OfReflectionCritique: &responses.ChunkCritique[constants.ChunkTypeReflectionCritique]{
+				Critic:   critic.GetName(),
+				Revision: revision,
+				Approved: critique.Approved,
+				Feedback: critique.Feedback,
+			},
+		})
+	})
+
+	if critique.Approved || wrappingUp {
+		return false, nil
+	}
+
+	slog.InfoContext(ctx, "draft answer sent back for revision", slog.String("agent", e.Name), slog.String("critic", critic.GetName()), slog.Int("revision", revision))
+	run.AddMessages(ctx, messages.New(in.Message.SenderID, []responses.InputMessageUnion{revisionMessage(critique)}))
+	run.RunState.Revisions++
+	run.RunState.TransitionToLLM()
+	return true, nil
+}
@@ -0,0 +1,183 @@
+package agents_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/history"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/streambroker"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/llmtest"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+)
+
+func newReflectingAgent(writer *llmtest.Provider, reflection *agents.Reflection, budget *agents.RunBudget) *agents.Agent {
+	hist := history.NewConversationManager(history.NewInMemoryConversationPersistence())
+	return newScriptedAgent("writer", nil, hist, streambroker.NewMemoryStreamBroker(), nil, nil, scriptedBy(writer), func(o *agents.AgentOptions) {
+		o.Reflection = reflection
+		o.Budget = budget
+	})
+}
+
+// funcCritic is a critic of a function.
+type funcCritic struct {
+	name string
+	fn   func(in *agents.CritiqueInput) agents.CritiqueResult
+}
+
+func (c *funcCritic) GetName() string { return c.name }
+
+func (c *funcCritic) Critique(ctx context.Context, in *agents.CritiqueInput) (agents.CritiqueResult, error) {
+	return c.fn(in), nil
+}
+
+// critiques returns the reflection.critique chunks in chunks.
+func critiques(chunks []*responses.ResponseChunk) []*responses.ResponseChunk {
+	var found []*responses.ResponseChunk
+	for _, chunk := range chunks {
+		if chunk.OfReflectionCritique != nil {
+			found = append(found, chunk)
+		}
+	}
+	return found
+}
+
+func TestReflection_RevisesUntilTheCriticApproves(t *testing.T) {
+	writerLLM := llmtest.New().
+		RespondText("Go is fast.").WithUsage(50, 5).
+		RespondText("Go compiles to native code, so it is fast.").WithUsage(50, 5)
+	var read []*agents.CritiqueInput
+	verdicts := []agents.CritiqueResult{
+		{Feedback: "Say why.", Usage: &responses.Usage{InputTokens: 100, OutputTokens: 10}},
+		{Approved: true, Usage: &responses.Usage{InputTokens: 120, OutputTokens: 5}},
+	}
+	critic := &funcCritic{name: "editor", fn: func(in *agents.CritiqueInput) agents.CritiqueResult {
+		read = append(read, in)
+		return verdicts[len(read)-1]
+	}}
+
+	agent := newReflectingAgent(writerLLM, &agents.Reflection{Critic: critic}, nil)
+
+	out, chunks, err := runCollecting(t, agent, &agents.AgentInput{Namespace: "test", Message: userMessage("Is Go fast?")})
+	require.NoError(t, err)
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Equal(t, "Go compiles to native code, so it is fast.", out.Text())
+
+	// The critic read each draft against the request, and its feedback went
+	// back to the writer.
+	require.Len(t, read, 2)
+	assert.Equal(t, "Is Go fast?", read[0].Request)
+	assert.Equal(t, "Go is fast.", read[0].Draft)
+	assert.Equal(t, 1, read[1].Revision)
+	assert.Contains(t, messagesText(writerLLM.Request(1).Input.OfInputMessageList), "Say why.")
+
+	// Each verdict was streamed, and each draft as a message of its own.
+	found := critiques(chunks)
+	require.Len(t, found, 2)
+	assert.Equal(t, "editor", found[0].OfReflectionCritique.Critic)
+	assert.Equal(t, 0, found[0].OfReflectionCritique.Revision)
+	assert.False(t, found[0].OfReflectionCritique.Approved)
+	assert.Equal(t, "Say why.", found[0].OfReflectionCritique.Feedback)
+	assert.Equal(t, 1, found[1].OfReflectionCritique.Revision)
+	assert.True(t, found[1].OfReflectionCritique.Approved)
+
+	drafts := 0
+	for _, chunk := range chunks {
+		if chunk.OfOutputItemDone != nil && chunk.OfOutputItemDone.Item.Type == "message" {
+			drafts++
+		}
+	}
+	assert.Equal(t, 2, drafts)
+
+	// The critic's tokens were billed to the run, with the writer's.
+	var usage responses.Usage
+	for _, chunk := range chunks {
+		if chunk.OfRunCompleted != nil {
+			usage = chunk.OfRunCompleted.RunState.Usage
+		}
+	}
+	assert.Equal(t, 320, usage.InputTokens)
+	assert.Equal(t, 25, usage.OutputTokens)
+}
+
+// The last revision allowed is not read: a rejection would change nothing.
+func TestReflection_StopsAtMaxRevisions(t *testing.T) {
+	writerLLM := llmtest.New().RespondText("draft 1").RespondText("draft 2")
+	calls := 0
+	critic := &funcCritic{name: "strict", fn: func(in *agents.CritiqueInput) agents.CritiqueResult {
+		calls++
+		return agents.CritiqueResult{Feedback: "not yet"}
+	}}
+
+	agent := newReflectingAgent(writerLLM, &agents.Reflection{Critic: critic, MaxRevisions: 1}, nil)
+	out := runAgent(t, agent, &agents.AgentInput{Namespace: "test", Message: userMessage("write")})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Equal(t, "draft 2", out.Text())
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 2, writerLLM.Calls())
+}
+
+// The wrap-up answer is read too, but the budget leaves no turn to revise it.
+func TestReflection_WrapUpAnswerIsReadButNotRevised(t *testing.T) {
+	writerLLM := llmtest.New().
+		RespondText("draft 1").WithUsage(10, 500).
+		RespondText("wrap-up")
+	var read []string
+	critic := &funcCritic{name: "strict", fn: func(in *agents.CritiqueInput) agents.CritiqueResult {
+		read = append(read, in.Draft)
+		return agents.CritiqueResult{Feedback: "not yet"}
+	}}
+
+	agent := newReflectingAgent(writerLLM, &agents.Reflection{Critic: critic}, &agents.RunBudget{MaxOutputTokens: 100})
+	out := runAgent(t, agent, &agents.AgentInput{Namespace: "test", Message: userMessage("write")})
+	requireStatus(t, out, agentstate.RunStatusBudgetExceeded)
+	assert.Equal(t, "wrap-up", out.Text())
+	assert.Equal(t, []string{"draft 1", "wrap-up"}, read)
+	assert.Equal(t, 2, writerLLM.Calls())
+}
+
+// A run resumed from an approval is critiqued against the message it was
+// started with, not the approval that resumed it.
+func TestReflection_ResumedRunIsCritiquedAgainstItsRequest(t *testing.T) {
+	writerLLM := llmtest.New().
+		Respond(llmtest.ToolCallWithID("call_lookup", "lookup", `{}`)).
+		RespondText("Go is fast.")
+	var requests []string
+	critic := &funcCritic{name: "editor", fn: func(in *agents.CritiqueInput) agents.CritiqueResult {
+		requests = append(requests, in.Request)
+		return agents.CritiqueResult{Approved: true}
+	}}
+
+	hist := history.NewConversationManager(history.NewInMemoryConversationPersistence())
+	tools := []agents.Tool{newFakeTool("lookup", true, "benchmarks")}
+	agent := newScriptedAgent("writer", nil, hist, nil, tools, nil, scriptedBy(writerLLM), func(o *agents.AgentOptions) {
+		o.Reflection = &agents.Reflection{Critic: critic}
+	})
+
+	out := runAgent(t, agent, &agents.AgentInput{Namespace: "test", ThreadID: "thread-resume", Message: userMessage("Is Go fast?")})
+	requireStatus(t, out, agentstate.RunStatusPaused)
+
+	out = runAgent(t, agent, &agents.AgentInput{
+		Namespace:     "test",
+		ThreadID:      "thread-resume",
+		PreviousRunID: out.RunID,
+		Message:       approvalMessage([]string{"call_lookup"}, nil),
+	})
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Equal(t, []string{"Is Go fast?"}, requests)
+}
+
+func TestRevisions_RoundTripThroughMeta(t *testing.T) {
+	state := agentstate.NewRunState()
+	state.Revisions = 2
+	state.Request = "Is Go fast?"
+
+	loaded := agentstate.LoadRunStateFromMeta(state.ToMeta())
+	require.NotNil(t, loaded)
+	assert.Equal(t, 2, loaded.Revisions)
+	assert.Equal(t, "Is Go fast?", loaded.Request)
+}
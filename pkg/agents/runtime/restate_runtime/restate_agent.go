@@ -83,10 +83,11 @@ func (w *AgentWorkflow) newRestateAgentProxy(restateCtx restate.WorkflowContext,
 	}
 
 	opts := &agents.AgentOptions{
-		Name:       agentOptions.Name,
-		Output:     agentOptions.Output,
-		Parameters: agentOptions.Parameters,
-		MaxLoops:   agentOptions.MaxLoops,
+		Name:             agentOptions.Name,
+		Output:           agentOptions.Output,
+		MaxOutputRepairs: agentOptions.MaxOutputRepairs,
+		Parameters:       agentOptions.Parameters,
+		MaxLoops:         agentOptions.MaxLoops,
 
 		Instruction: promptProxy,
 		History:     conversationHistory,
@@ -99,17 +100,29 @@ func (w *AgentWorkflow) newRestateAgentProxy(restateCtx restate.WorkflowContext,
 		McpServers:   mcpClients,
 		ToolExecutor: NewRestateToolExecutor(restateCtx),
 		// The real hooks, each wrapped so its methods run as their own steps.
-		Hooks:        restateHooks(restateCtx, agentOptions.Hooks),
+		Hooks:            restateHooks(restateCtx, agentOptions.Hooks),
+		InputGuardrails:  restateInputGuardrails(restateCtx, agentOptions.InputGuardrails),
+		OutputGuardrails: restateOutputGuardrails(restateCtx, agentOptions.OutputGuardrails),
+		Reflection:       restateReflection(restateCtx, agentOptions.Reflection),
+		LifecycleHooks:   restateLifecycleHooks(restateCtx, agentOptions.LifecycleHooks),
+		Budget:           agentOptions.Budget,
+		// The real providers, never called from here — WithLLM and
+		// WithPolicyLLMs put the journaled wrappers in their place. The agent
+		// reads their provider families, to know what a turn on another must
+		// not be sent.
+		LLM:          agentOptions.LLM,
+		ModelPolicy:  agentOptions.ModelPolicy,
 		StreamBroker: NewRestateStreamBroker(restateCtx, w.broker),
 		DurableStep:  NewRestateDurableStep(restateCtx),
 	}
 
 	for _, h := range agentOptions.Handoffs {
 		agentOption := w.agentConfigs[h.Name]
-		opts.Handoffs = append(opts.Handoffs, agents.NewHandoff(
-			h.Name, h.Description, w.newRestateAgentProxy(restateCtx, agentOption, providerConfigKey, streamID),
+		opts.Handoffs = append(opts.Handoffs, restateHandoff(
+			restateCtx, h, w.newRestateAgentProxy(restateCtx, agentOption, providerConfigKey, streamID),
 		))
 	}
 
-	return agents.NewAgent(opts).WithLLM(llmProxy)
+	fallbackLLMs, escalationLLM := restatePolicyLLMs(restateCtx, agentOptions.ModelPolicy, providerConfigKey, w.broker, streamID)
+	return agents.NewAgent(opts).WithLLM(llmProxy).WithPolicyLLMs(fallbackLLMs, escalationLLM)
 }
@@ -1,6 +1,8 @@
 package restate_runtime
 
 import (
+	"time"
+
 	"github.com/hastekit/agent-sdk-go/pkg/agents"
 	restate "github.com/restatedev/sdk-go"
 )
@@ -19,7 +21,10 @@ func NewRestateDurableStep(restateCtx restate.WorkflowContext) *RestateDurableSt
 	return &RestateDurableStep{restateCtx: restateCtx}
 }
 
-var _ agents.DurableStep = (*RestateDurableStep)(nil)
+var (
+	_ agents.DurableStep  = (*RestateDurableStep)(nil)
+	_ agents.DurableClock = (*RestateDurableStep)(nil)
+)
 
 func (s *RestateDurableStep) Do(fn func()) {
 	_ = restate.RunVoid(s.restateCtx, func(restate.RunContext) error {
@@ -27,3 +32,13 @@ func (s *RestateDurableStep) Do(fn func()) {
 		return nil
 	})
 }
+
+// Now reads the clock in a journaled step, so a replay gets the time the
+// original run saw. A step that fails is reported, not read again from the
+// local clock: that reading would not be journaled, and a replay could see
+// another.
+func (s *RestateDurableStep) Now() (time.Time, error) {
+	return restate.Run(s.restateCtx, func(restate.RunContext) (time.Time, error) {
+		return time.Now(), nil
+	}, restate.WithName("now"))
+}
@@ -0,0 +1,82 @@
+package restate_runtime
+
+import (
+	"context"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	restate "github.com/restatedev/sdk-go"
+)
+
+// RestateInputGuardrail runs an input guardrail's check as its own Restate
+// step, so a classifier's verdict is journaled once and replayed rather than
+// asked for again when the workflow is recovered.
+type RestateInputGuardrail struct {
+	restateCtx       restate.WorkflowContext
+	wrappedGuardrail agents.InputGuardrail
+}
+
+var _ agents.InputGuardrailStarter = (*RestateInputGuardrail)(nil)
+
+func NewRestateInputGuardrail(restateCtx restate.WorkflowContext, wrappedGuardrail agents.InputGuardrail) *RestateInputGuardrail {
+	return &RestateInputGuardrail{restateCtx: restateCtx, wrappedGuardrail: wrappedGuardrail}
+}
+
+func (g *RestateInputGuardrail) GetName() string { return g.wrappedGuardrail.GetName() }
+
+func (g *RestateInputGuardrail) CheckInput(ctx context.Context, in *agents.GuardrailInput) (agents.GuardrailResult, error) {
+	return g.StartCheckInput(ctx, in)()
+}
+
+// StartCheckInput journals the check as an asynchronous step: Restate runs
+// it while the handler awaits the model call's step, and replays its verdict.
+func (g *RestateInputGuardrail) StartCheckInput(ctx context.Context, in *agents.GuardrailInput) func() (agents.GuardrailResult, error) {
+	future := restate.RunAsync(g.restateCtx, func(restate.RunContext) (agents.GuardrailResult, error) {
+		return g.wrappedGuardrail.CheckInput(ctx, in)
+	}, restate.WithName(g.GetName()+"_CheckInput"))
+	return future.Result
+}
+
+// RestateOutputGuardrail is RestateInputGuardrail for output guardrails.
+type RestateOutputGuardrail struct {
+	restateCtx       restate.WorkflowContext
+	wrappedGuardrail agents.OutputGuardrail
+}
+
+var _ agents.OutputGuardrail = (*RestateOutputGuardrail)(nil)
+
+func NewRestateOutputGuardrail(restateCtx restate.WorkflowContext, wrappedGuardrail agents.OutputGuardrail) *RestateOutputGuardrail {
+	return &RestateOutputGuardrail{restateCtx: restateCtx, wrappedGuardrail: wrappedGuardrail}
+}
+
+func (g *RestateOutputGuardrail) GetName() string { return g.wrappedGuardrail.GetName() }
+
+func (g *RestateOutputGuardrail) CheckOutput(ctx context.Context, out *agents.GuardrailOutput) (agents.GuardrailResult, error) {
+	return restate.Run(g.restateCtx, func(restate.RunContext) (agents.GuardrailResult, error) {
+		return g.wrappedGuardrail.CheckOutput(ctx, out)
+	}, restate.WithName(g.GetName()+"_CheckOutput"))
+}
+
+// restateInputGuardrails wraps an agent's input guardrails so each check runs
+// as its own step.
+func restateInputGuardrails(restateCtx restate.WorkflowContext, guardrails []agents.InputGuardrail) []agents.InputGuardrail {
+	var wrapped []agents.InputGuardrail
+	for _, g := range guardrails {
+		if g == nil {
+			continue
+		}
+		wrapped = append(wrapped, NewRestateInputGuardrail(restateCtx, g))
+	}
+	return wrapped
+}
+
+// restateOutputGuardrails is restateInputGuardrails for output guardrails.
+func restateOutputGuardrails(restateCtx restate.WorkflowContext, guardrails []agents.OutputGuardrail) []agents.OutputGuardrail {
+	var wrapped []agents.OutputGuardrail
+	for _, g := range guardrails {
+		if g == nil {
+			continue
+		}
+		wrapped = append(wrapped, NewRestateOutputGuardrail(restateCtx, g))
+	}
+	return wrapped
+}
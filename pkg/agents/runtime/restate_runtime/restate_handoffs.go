@@ -0,0 +1,28 @@
+package restate_runtime
+
+import (
+	"context"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	restate "github.com/restatedev/sdk-go"
+)
+
+// restateHandoff is h as the workflow follows it: to the target's proxy
+// agent, with its input filter run as its own step — a filter may summarize
+// with a model, which a recovered run must not ask again.
+func restateHandoff(restateCtx restate.WorkflowContext, h *agents.Handoff, target *agents.Agent) *agents.Handoff {
+	proxy := agents.NewHandoff(h.Name, h.Description, target)
+	proxy.Payload = h.Payload
+	proxy.Return = h.Return
+
+	if filter := h.InputFilter; filter != nil {
+		proxy.InputFilter = func(ctx context.Context, in *agents.HandoffInput) ([]responses.InputMessageUnion, error) {
+			return restate.Run(restateCtx, func(restate.RunContext) ([]responses.InputMessageUnion, error) {
+				return filter(ctx, in)
+			}, restate.WithName(h.Name+"_HandoffInputFilter"))
+		}
+	}
+
+	return proxy
+}
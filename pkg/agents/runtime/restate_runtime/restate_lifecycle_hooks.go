@@ -0,0 +1,85 @@
+package restate_runtime
+
+import (
+	"context"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	restate "github.com/restatedev/sdk-go"
+)
+
+// LifecycleHookFailedErrorCode marks the step failure a lifecycle hook reports
+// when it returns an error. The agent logs it and carries on.
+const LifecycleHookFailedErrorCode = 597
+
+// RestateLifecycleHook runs each of a lifecycle hook's methods as its own
+// Restate step, so a recovered run does not report an event twice.
+//
+// A hook's error is made terminal. Restate would otherwise retry the step
+// without end, and the run would wait on an observer that is down; one that
+// wants its work retried retries it itself.
+type RestateLifecycleHook struct {
+	restateCtx  restate.WorkflowContext
+	wrappedHook agents.LifecycleHook
+}
+
+var _ agents.LifecycleHook = (*RestateLifecycleHook)(nil)
+
+func NewRestateLifecycleHook(restateCtx restate.WorkflowContext, wrappedHook agents.LifecycleHook) *RestateLifecycleHook {
+	return &RestateLifecycleHook{restateCtx: restateCtx, wrappedHook: wrappedHook}
+}
+
+func (h *RestateLifecycleHook) GetName() string { return h.wrappedHook.GetName() }
+
+func (h *RestateLifecycleHook) run(method string, fn func() error) error {
+	return restate.RunVoid(h.restateCtx, func(restate.RunContext) error {
+		if err := fn(); err != nil {
+			return restate.TerminalError(err, LifecycleHookFailedErrorCode)
+		}
+		return nil
+	}, restate.WithName(h.GetName()+"_"+method))
+}
+
+func (h *RestateLifecycleHook) OnRunStart(ctx context.Context, event *agents.RunStartEvent) error {
+	return h.run("OnRunStart", func() error { return h.wrappedHook.OnRunStart(ctx, event) })
+}
+
+func (h *RestateLifecycleHook) OnRunEnd(ctx context.Context, event *agents.RunEndEvent) error {
+	return h.run("OnRunEnd", func() error { return h.wrappedHook.OnRunEnd(ctx, event) })
+}
+
+func (h *RestateLifecycleHook) OnHandoff(ctx context.Context, event *agents.HandoffEvent) error {
+	return h.run("OnHandoff", func() error { return h.wrappedHook.OnHandoff(ctx, event) })
+}
+
+func (h *RestateLifecycleHook) OnApprovalRequested(ctx context.Context, event *agents.ApprovalRequestedEvent) error {
+	return h.run("OnApprovalRequested", func() error { return h.wrappedHook.OnApprovalRequested(ctx, event) })
+}
+
+func (h *RestateLifecycleHook) OnApprovalResolved(ctx context.Context, event *agents.ApprovalResolvedEvent) error {
+	return h.run("OnApprovalResolved", func() error { return h.wrappedHook.OnApprovalResolved(ctx, event) })
+}
+
+func (h *RestateLifecycleHook) OnMessageQueued(ctx context.Context, event *agents.MessageQueuedEvent) error {
+	return h.run("OnMessageQueued", func() error { return h.wrappedHook.OnMessageQueued(ctx, event) })
+}
+
+func (h *RestateLifecycleHook) OnSummarized(ctx context.Context, event *agents.SummarizedEvent) error {
+	return h.run("OnSummarized", func() error { return h.wrappedHook.OnSummarized(ctx, event) })
+}
+
+func (h *RestateLifecycleHook) OnStopRequested(ctx context.Context, event *agents.StopRequestedEvent) error {
+	return h.run("OnStopRequested", func() error { return h.wrappedHook.OnStopRequested(ctx, event) })
+}
+
+// restateLifecycleHooks wraps an agent's lifecycle hooks so each event runs
+// as its own step.
+func restateLifecycleHooks(restateCtx restate.WorkflowContext, hooks []agents.LifecycleHook) []agents.LifecycleHook {
+	var wrapped []agents.LifecycleHook
+	for _, hook := range hooks {
+		if hook == nil {
+			continue
+		}
+		wrapped = append(wrapped, NewRestateLifecycleHook(restateCtx, hook))
+	}
+	return wrapped
+}
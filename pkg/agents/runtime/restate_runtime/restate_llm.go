@@ -10,6 +10,26 @@ import (
 	restate "github.com/restatedev/sdk-go"
 )
 
+// ModelCallFailedErrorCode marks the run-step failure a model call that
+// failed on its own reports; 502 is the conventional "bad gateway".
+const ModelCallFailedErrorCode = 502
+
+// modelCallMaxAttempts bounds the attempts at a model call's step. A model's
+// error is not retried at all (see modelCallError); what is retried is
+// anything else the step fails with.
+const modelCallMaxAttempts = 3
+
+// modelCallError converts a failed model call into a terminal run-step
+// failure: the turn goes on to the agent's fallbacks, or fails the run,
+// rather than Restate retrying the model that failed. A stop is reported as
+// the cancellation it is.
+func modelCallError(err error) error {
+	if stoppedWork(err) {
+		return cancellationError(err)
+	}
+	return restate.TerminalError(err, ModelCallFailedErrorCode)
+}
+
 type RestateLLM struct {
 	restateCtx        restate.WorkflowContext
 	wrappedLLM        llm.Provider
@@ -48,7 +68,7 @@ func (l *RestateLLM) NewStreamingResponses(ctx context.Context, in *responses.Re
 
 		stream, err := l.wrappedLLM.NewStreamingResponses(runCtx, in)
 		if err != nil {
-			return nil, cancellationError(err)
+			return nil, modelCallError(err)
 		}
 
 		acc := agents.Accumulator{}
@@ -58,11 +78,11 @@ func (l *RestateLLM) NewStreamingResponses(ctx context.Context, in *responses.Re
 		if err != nil {
 			// Terminal, or Restate replays into this step and calls the model
 			// again — the one thing a user who pressed stop must not get.
-			return nil, cancellationError(err)
+			return nil, modelCallError(err)
 		}
 
 		return resp, nil
-	}, restate.WithName("LLMCall"))
+	}, restate.WithName("LLMCall"), restate.WithMaxRetryAttempts(modelCallMaxAttempts))
 
 	// A call the stop cut short comes back as a terminal step failure. Report
 	// it as the stop it is, so the loop ends the run cleanly instead of failing
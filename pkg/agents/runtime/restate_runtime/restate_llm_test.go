@@ -0,0 +1,28 @@
+package restate_runtime
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	restate "github.com/restatedev/sdk-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// A model's error has to reach Restate as terminal, or Restate retries the
+// step against the model that failed and the turn never reaches the agent's
+// fallbacks.
+func TestModelCallError_IsTerminal(t *testing.T) {
+	unavailable := errors.New("provider unavailable")
+
+	err := modelCallError(unavailable)
+	assert.True(t, restate.IsTerminalError(err))
+	assert.EqualValues(t, ModelCallFailedErrorCode, restate.ErrorCode(err))
+	assert.ErrorIs(t, err, unavailable)
+}
+
+// A stop is still reported as one, so the loop ends the run rather than
+// falling back.
+func TestModelCallError_KeepsAStopAStop(t *testing.T) {
+	assert.True(t, wasCancelled(modelCallError(agents.ErrModelCallStopped)))
+}
@@ -0,0 +1,28 @@
+package restate_runtime
+
+import (
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	restate "github.com/restatedev/sdk-go"
+)
+
+// restatePolicyLLMs wraps the models of an agent's ModelPolicy so each call
+// is a journaled step, like the agent's own model's, for
+// agents.Agent.WithPolicyLLMs.
+func restatePolicyLLMs(restateCtx restate.WorkflowContext, policy *agents.ModelPolicy, providerConfigKey string, broker agents.StreamBroker, streamID string) (fallbacks []agents.LLM, escalation agents.LLM) {
+	if policy == nil {
+		return nil, nil
+	}
+
+	for _, p := range policy.Fallbacks {
+		if p == nil {
+			continue
+		}
+		fallbacks = append(fallbacks, NewRestateLLM(restateCtx, p, providerConfigKey, broker, streamID))
+	}
+
+	if policy.Escalation != nil && policy.Escalation.LLM != nil {
+		escalation = NewRestateLLM(restateCtx, policy.Escalation.LLM, providerConfigKey, broker, streamID)
+	}
+
+	return fallbacks, escalation
+}
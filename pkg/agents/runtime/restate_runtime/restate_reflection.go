@@ -0,0 +1,42 @@
+package restate_runtime
+
+import (
+	"context"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	restate "github.com/restatedev/sdk-go"
+)
+
+// RestateCritic runs a critic's critique as its own Restate step, so its
+// verdict is journaled once and replayed rather than asked for again when the
+// workflow is recovered.
+type RestateCritic struct {
+	restateCtx    restate.WorkflowContext
+	wrappedCritic agents.Critic
+}
+
+var _ agents.Critic = (*RestateCritic)(nil)
+
+func NewRestateCritic(restateCtx restate.WorkflowContext, wrappedCritic agents.Critic) *RestateCritic {
+	return &RestateCritic{restateCtx: restateCtx, wrappedCritic: wrappedCritic}
+}
+
+func (c *RestateCritic) GetName() string { return c.wrappedCritic.GetName() }
+
+func (c *RestateCritic) Critique(ctx context.Context, in *agents.CritiqueInput) (agents.CritiqueResult, error) {
+	return restate.Run(c.restateCtx, func(restate.RunContext) (agents.CritiqueResult, error) {
+		return c.wrappedCritic.Critique(ctx, in)
+	}, restate.WithName(c.GetName()+"_Critique"))
+}
+
+// restateReflection is the agent's Reflection with its critic wrapped so each
+// critique runs as its own step.
+func restateReflection(restateCtx restate.WorkflowContext, reflection *agents.Reflection) *agents.Reflection {
+	if reflection == nil || reflection.Critic == nil {
+		return nil
+	}
+	return &agents.Reflection{
+		Critic:       NewRestateCritic(restateCtx, reflection.Critic),
+		MaxRevisions: reflection.MaxRevisions,
+	}
+}
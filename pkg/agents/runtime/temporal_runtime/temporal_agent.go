@@ -34,6 +34,7 @@ func (a *TemporalAgentV2) GetActivities() map[string]interface{} {
 
 	temporalLLM := NewTemporalLLM(a.options.LLM, a.broker)
 	activities[a.options.Name+"_NewStreamingResponsesActivity"] = temporalLLM.NewStreamingResponsesActivity
+	maps.Copy(activities, policyLLMActivities(a.options.Name, a.options.ModelPolicy, a.broker))
 
 	temporalConversationPersistence := NewTemporalConversationPersistence(a.options.History.ConversationPersistenceAdapter)
 	activities[a.options.Name+"_LoadMessagesActivity"] = temporalConversationPersistence.LoadMessages
@@ -65,6 +66,10 @@ func (a *TemporalAgentV2) GetActivities() map[string]interface{} {
 	// Four activities per hook, so the workflow can run each method as its own
 	// step.
 	maps.Copy(activities, hookActivities(a.options.Name, a.options.Hooks))
+	maps.Copy(activities, guardrailActivities(a.options.Name, a.options.InputGuardrails, a.options.OutputGuardrails))
+	maps.Copy(activities, reflectionActivities(a.options.Name, a.options.Reflection))
+	maps.Copy(activities, lifecycleHookActivities(a.options.Name, a.options.LifecycleHooks))
+	maps.Copy(activities, handoffInputFilterActivities(a.options.Name, a.options.Handoffs))
 
 	for _, mcpClient := range a.options.McpServers {
 		temporalMCP := NewTemporalMCPServer(mcpClient, a.broker)
@@ -133,10 +138,11 @@ func (a *TemporalAgentV2) newTemporalProxyAgent(ctx workflow.Context) *agents.Ag
 	}
 
 	opts := &agents.AgentOptions{
-		Name:       a.options.Name,
-		Output:     a.options.Output,
-		Parameters: a.options.Parameters,
-		MaxLoops:   a.options.MaxLoops,
+		Name:             a.options.Name,
+		Output:           a.options.Output,
+		MaxOutputRepairs: a.options.MaxOutputRepairs,
+		Parameters:       a.options.Parameters,
+		MaxLoops:         a.options.MaxLoops,
 
 		History:     conversationHistory,
 		Instruction: promptProxy,
@@ -150,19 +156,31 @@ func (a *TemporalAgentV2) newTemporalProxyAgent(ctx workflow.Context) *agents.Ag
 		ToolExecutor: NewTemporalToolExecutor(ctx),
 		// Proxies, not the hooks themselves: the executor and the loop both run
 		// in the workflow, so each hook method becomes its own activity.
-		Hooks:        hookProxies(ctx, a.options.Name, a.options.Hooks),
+		Hooks:            hookProxies(ctx, a.options.Name, a.options.Hooks),
+		InputGuardrails:  inputGuardrailProxies(ctx, a.options.Name, a.options.InputGuardrails),
+		OutputGuardrails: outputGuardrailProxies(ctx, a.options.Name, a.options.OutputGuardrails),
+		Reflection:       reflectionProxy(ctx, a.options.Name, a.options.Reflection),
+		LifecycleHooks:   lifecycleHookProxies(ctx, a.options.Name, a.options.LifecycleHooks),
+		Budget:           a.options.Budget,
+		// The real providers, never called from here — WithLLM and
+		// WithPolicyLLMs put the proxies in their place. The agent reads
+		// their provider families, to know what a turn on another must not
+		// be sent.
+		LLM:          a.options.LLM,
+		ModelPolicy:  a.options.ModelPolicy,
 		StreamBroker: NewTemporalStreamBrokerProxy(ctx, a.options.Name, a.broker),
 		DurableStep:  NewTemporalDurableStep(ctx),
 	}
 
 	for _, h := range a.options.Handoffs {
 		agentOptions := a.agentConfigs[h.Name]
-		opts.Handoffs = append(opts.Handoffs, agents.NewHandoff(
-			h.Name, h.Description, NewTemporalAgent(a.agentConfigs, agentOptions, a.broker).newTemporalProxyAgent(ctx),
+		opts.Handoffs = append(opts.Handoffs, handoffProxy(
+			ctx, a.options.Name, h, NewTemporalAgent(a.agentConfigs, agentOptions, a.broker).newTemporalProxyAgent(ctx),
 		))
 	}
 
-	return agents.NewAgent(opts).WithLLM(llmProxy)
+	fallbackProxies, escalationProxy := policyLLMProxies(ctx, a.options.Name, a.options.ModelPolicy, a.broker)
+	return agents.NewAgent(opts).WithLLM(llmProxy).WithPolicyLLMs(fallbackProxies, escalationProxy)
 }
 
 func getToolName(prefix string, tool agents.Tool) string {
@@ -179,6 +197,26 @@ func getToolName(prefix string, tool agents.Tool) string {
 		if t.OfImageGeneration != nil {
 			toolName = "image_generation"
 		}
+
+		if t.OfFileSearch != nil {
+			toolName = "file_search"
+		}
+
+		if t.OfMCP != nil {
+			toolName = "mcp_" + t.OfMCP.ServerLabel
+		}
+
+		if t.OfWebFetch != nil {
+			toolName = "web_fetch"
+		}
+
+		if t.OfURLContext != nil {
+			toolName = "url_context"
+		}
+
+		if t.OfGoogleMaps != nil {
+			toolName = "google_maps"
+		}
 	}
 
 	return prefix + "_" + toolName
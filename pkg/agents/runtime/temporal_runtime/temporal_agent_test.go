@@ -0,0 +1,99 @@
+package temporal_runtime_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/messages"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/prompts"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/runtime/temporal_runtime"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/streambroker"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/llmtest"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+)
+
+// runAgentWorkflow runs the agent's workflow end to end in Temporal's test
+// environment, with every activity the agent registers, and returns what the
+// run returned.
+func runAgentWorkflow(t *testing.T, agent *temporal_runtime.TemporalAgentV2, message string) *agents.AgentOutput {
+	t.Helper()
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	for name, fn := range agent.GetActivities() {
+		env.RegisterActivityWithOptions(fn, activityNamed(name))
+	}
+	env.RegisterWorkflowWithOptions(agent.Execute, workflow.RegisterOptions{Name: "AgentWorkflow"})
+
+	env.ExecuteWorkflow("AgentWorkflow", &agents.AgentInput{
+		Namespace: "test",
+		Message: messages.New("user", []responses.InputMessageUnion{{
+			OfEasyInput: &responses.EasyMessage{
+				Role:    constants.RoleUser,
+				Content: responses.EasyInputContentUnion{OfString: utils.Ptr(message)},
+			},
+		}}),
+	})
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+
+	var out agents.AgentOutput
+	require.NoError(t, env.GetWorkflowResult(&out))
+	return &out
+}
+
+// startedLLM reports when the model is first called.
+type startedLLM struct {
+	*llmtest.Provider
+	started chan struct{}
+}
+
+func (l *startedLLM) NewStreamingResponses(ctx context.Context, in *responses.Request) (chan *responses.ResponseChunk, error) {
+	select {
+	case <-l.started:
+	default:
+		close(l.started)
+	}
+	return l.Provider.NewStreamingResponses(ctx, in)
+}
+
+// The input guardrails' activities are scheduled before the model call's, and
+// run alongside it rather than ahead of it: this guardrail only answers once
+// the model has been called.
+func TestTemporalAgent_InputGuardrailsRunAlongsideTheModelCall(t *testing.T) {
+	model := &startedLLM{Provider: llmtest.New().RespondText("hello"), started: make(chan struct{})}
+	guardrail := agents.NewInputGuardrail("topic", func(ctx context.Context, in *agents.GuardrailInput) (agents.GuardrailResult, error) {
+		select {
+		case <-model.started:
+			return agents.TripGuardrail("off topic"), nil
+		case <-time.After(5 * time.Second):
+			return agents.GuardrailResult{}, assert.AnError
+		}
+	})
+
+	agent := temporal_runtime.NewTemporalAgent(nil, &agents.AgentOptions{
+		Name:            "Agent",
+		Instruction:     prompts.New("You are a tutor."),
+		LLM:             model,
+		History:         newTestHistory(),
+		InputGuardrails: []agents.InputGuardrail{guardrail},
+	}, streambroker.NewMemoryStreamBroker())
+
+	out := runAgentWorkflow(t, agent, "write my homework")
+
+	assert.Equal(t, agentstate.RunStatusGuardrailTripped, out.Status)
+	require.NotNil(t, out.Guardrail)
+	assert.Equal(t, "topic", out.Guardrail.Name)
+	assert.Equal(t, 1, model.Calls())
+}
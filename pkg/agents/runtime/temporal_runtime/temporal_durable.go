@@ -1,6 +1,8 @@
 package temporal_runtime
 
 import (
+	"time"
+
 	"github.com/hastekit/agent-sdk-go/pkg/agents"
 	"go.temporal.io/sdk/workflow"
 )
@@ -19,7 +21,10 @@ func NewTemporalDurableStep(workflowCtx workflow.Context) *TemporalDurableStep {
 	return &TemporalDurableStep{workflowCtx: workflowCtx}
 }
 
-var _ agents.DurableStep = (*TemporalDurableStep)(nil)
+var (
+	_ agents.DurableStep  = (*TemporalDurableStep)(nil)
+	_ agents.DurableClock = (*TemporalDurableStep)(nil)
+)
 
 func (s *TemporalDurableStep) Do(fn func()) {
 	if workflow.IsReplaying(s.workflowCtx) {
@@ -27,3 +32,8 @@ func (s *TemporalDurableStep) Do(fn func()) {
 	}
 	fn()
 }
+
+// Now is the workflow's clock, which replays as it first read.
+func (s *TemporalDurableStep) Now() (time.Time, error) {
+	return workflow.Now(s.workflowCtx), nil
+}
@@ -0,0 +1,112 @@
+package temporal_runtime
+
+import (
+	"context"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Activity name suffixes for the two kinds of guardrail check. A guardrail
+// that is both kinds registers both.
+const (
+	checkInputActivitySuffix  = "_CheckInputActivity"
+	checkOutputActivitySuffix = "_CheckOutputActivity"
+)
+
+// guardrailActivities returns the activities to register for an agent's
+// guardrails, scoped to the agent for the same reason hook activities are.
+//
+// A guardrail's error is left retryable, unlike a tool hook's: it is a check
+// that could not answer — a classifier that timed out — not a refusal, and
+// asking again is the right thing to do. A refusal is a trip, which is a
+// result.
+func guardrailActivities(agentName string, input []agents.InputGuardrail, output []agents.OutputGuardrail) map[string]any {
+	activities := map[string]any{}
+	for _, g := range input {
+		if g == nil {
+			continue
+		}
+		activities[hookActivityName(agentName, g.GetName())+checkInputActivitySuffix] = g.CheckInput
+	}
+	for _, g := range output {
+		if g == nil {
+			continue
+		}
+		activities[hookActivityName(agentName, g.GetName())+checkOutputActivitySuffix] = g.CheckOutput
+	}
+	return activities
+}
+
+// TemporalGuardrailProxy is a guardrail as seen from inside a workflow: each
+// check runs as an activity, so its verdict is journaled once and replayed
+// thereafter. The real guardrail — which may hold a model client — runs on the
+// activity side.
+type TemporalGuardrailProxy struct {
+	workflowCtx workflow.Context
+	name        string
+	displayName string
+}
+
+var (
+	_ agents.InputGuardrailStarter = (*TemporalGuardrailProxy)(nil)
+	_ agents.OutputGuardrail       = (*TemporalGuardrailProxy)(nil)
+)
+
+func NewTemporalGuardrailProxy(workflowCtx workflow.Context, agentName, guardrailName string) *TemporalGuardrailProxy {
+	return &TemporalGuardrailProxy{
+		workflowCtx: workflowCtx,
+		name:        hookActivityName(agentName, guardrailName),
+		displayName: guardrailName,
+	}
+}
+
+// GetName is the guardrail's own name, not its activity's: it is what the
+// run.guardrail_tripped chunk reports.
+func (g *TemporalGuardrailProxy) GetName() string { return g.displayName }
+
+func (g *TemporalGuardrailProxy) CheckInput(ctx context.Context, in *agents.GuardrailInput) (agents.GuardrailResult, error) {
+	return g.StartCheckInput(ctx, in)()
+}
+
+// StartCheckInput schedules the check's activity without waiting for it, so
+// the loop can schedule the model call's activity alongside.
+func (g *TemporalGuardrailProxy) StartCheckInput(ctx context.Context, in *agents.GuardrailInput) func() (agents.GuardrailResult, error) {
+	future := workflow.ExecuteActivity(g.workflowCtx, g.name+checkInputActivitySuffix, in)
+	return func() (agents.GuardrailResult, error) {
+		var out agents.GuardrailResult
+		err := future.Get(g.workflowCtx, &out)
+		return out, err
+	}
+}
+
+func (g *TemporalGuardrailProxy) CheckOutput(ctx context.Context, result *agents.GuardrailOutput) (agents.GuardrailResult, error) {
+	var out agents.GuardrailResult
+	err := workflow.ExecuteActivity(g.workflowCtx, g.name+checkOutputActivitySuffix, result).Get(g.workflowCtx, &out)
+	return out, err
+}
+
+// inputGuardrailProxies builds the workflow-side stand-ins for an agent's
+// input guardrails, in the order they were configured.
+func inputGuardrailProxies(workflowCtx workflow.Context, agentName string, guardrails []agents.InputGuardrail) []agents.InputGuardrail {
+	var proxies []agents.InputGuardrail
+	for _, g := range guardrails {
+		if g == nil {
+			continue
+		}
+		proxies = append(proxies, NewTemporalGuardrailProxy(workflowCtx, agentName, g.GetName()))
+	}
+	return proxies
+}
+
+// outputGuardrailProxies is inputGuardrailProxies for output guardrails.
+func outputGuardrailProxies(workflowCtx workflow.Context, agentName string, guardrails []agents.OutputGuardrail) []agents.OutputGuardrail {
+	var proxies []agents.OutputGuardrail
+	for _, g := range guardrails {
+		if g == nil {
+			continue
+		}
+		proxies = append(proxies, NewTemporalGuardrailProxy(workflowCtx, agentName, g.GetName()))
+	}
+	return proxies
+}
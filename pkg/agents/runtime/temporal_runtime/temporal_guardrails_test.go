@@ -0,0 +1,61 @@
+package temporal_runtime_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/runtime/temporal_runtime"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+)
+
+// The proxy runs the check as an activity and reports the guardrail's own
+// name, which is what the run.guardrail_tripped chunk carries.
+func TestTemporalGuardrailProxy_RunsTheCheckAsAnActivity(t *testing.T) {
+	checks := 0
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterActivityWithOptions(
+		func(ctx context.Context, in *agents.GuardrailInput) (agents.GuardrailResult, error) {
+			checks++
+			return agents.TripGuardrail("blocked: " + in.Text()), nil
+		}, activityNamed("Agent_topic_CheckInputActivity"))
+
+	env.RegisterWorkflow(guardrailProxyWorkflow)
+	env.ExecuteWorkflow(guardrailProxyWorkflow)
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+
+	var out agents.GuardrailResult
+	require.NoError(t, env.GetWorkflowResult(&out))
+	assert.Equal(t, agents.TripGuardrail("blocked: homework"), out)
+	assert.Equal(t, 1, checks)
+
+	assert.Equal(t, "topic", temporal_runtime.NewTemporalGuardrailProxy(nil, "Agent", "topic").GetName())
+}
+
+func guardrailProxyWorkflow(ctx workflow.Context) (agents.GuardrailResult, error) {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: testActivityTimeout,
+	})
+
+	proxy := temporal_runtime.NewTemporalGuardrailProxy(ctx, "Agent", "topic")
+	return proxy.CheckInput(context.Background(), &agents.GuardrailInput{
+		AgentName: "Agent",
+		Messages: []responses.InputMessageUnion{{
+			OfEasyInput: &responses.EasyMessage{
+				Role:    constants.RoleUser,
+				Content: responses.EasyInputContentUnion{OfString: utils.Ptr("homework")},
+			},
+		}},
+	})
+}
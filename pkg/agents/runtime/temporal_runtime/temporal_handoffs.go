@@ -0,0 +1,50 @@
+package temporal_runtime
+
+import (
+	"context"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"go.temporal.io/sdk/workflow"
+)
+
+const handoffInputFilterActivitySuffix = "_HandoffInputFilterActivity"
+
+// handoffInputFilterActivities returns the activities to register for the
+// input filters of an agent's handoffs, one per handoff that has one. A filter
+// may summarize with a model, so it runs as an activity like anything else
+// that may call out.
+func handoffInputFilterActivities(agentName string, handoffs []*agents.Handoff) map[string]any {
+	activities := map[string]any{}
+	for _, h := range handoffs {
+		if h == nil || h.InputFilter == nil {
+			continue
+		}
+		activities[hookActivityName(agentName, h.Name)+handoffInputFilterActivitySuffix] = h.InputFilter
+	}
+	return activities
+}
+
+// NewTemporalHandoffInputFilterProxy runs a handoff's input filter as its
+// activity.
+func NewTemporalHandoffInputFilterProxy(workflowCtx workflow.Context, agentName string, h *agents.Handoff) agents.HandoffInputFilter {
+	if h.InputFilter == nil {
+		return nil
+	}
+	name := hookActivityName(agentName, h.Name) + handoffInputFilterActivitySuffix
+	return func(ctx context.Context, in *agents.HandoffInput) ([]responses.InputMessageUnion, error) {
+		var out []responses.InputMessageUnion
+		err := workflow.ExecuteActivity(workflowCtx, name, in).Get(workflowCtx, &out)
+		return out, err
+	}
+}
+
+// handoffProxy is h as the workflow follows it: to the target's proxy agent,
+// through the filter's activity.
+func handoffProxy(workflowCtx workflow.Context, agentName string, h *agents.Handoff, target *agents.Agent) *agents.Handoff {
+	proxy := agents.NewHandoff(h.Name, h.Description, target)
+	proxy.InputFilter = NewTemporalHandoffInputFilterProxy(workflowCtx, agentName, h)
+	proxy.Payload = h.Payload
+	proxy.Return = h.Return
+	return proxy
+}
@@ -0,0 +1,133 @@
+package temporal_runtime
+
+import (
+	"context"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// LifecycleHookFailedErrorType marks the activity failure a lifecycle hook
+// reports when it returns an error. The agent logs it and carries on.
+const LifecycleHookFailedErrorType = "LifecycleHookFailed"
+
+// Activity name suffixes for a lifecycle hook's methods, one activity each.
+const (
+	onRunStartActivitySuffix          = "_OnRunStartActivity"
+	onRunEndActivitySuffix            = "_OnRunEndActivity"
+	onHandoffActivitySuffix           = "_OnHandoffActivity"
+	onApprovalRequestedActivitySuffix = "_OnApprovalRequestedActivity"
+	onApprovalResolvedActivitySuffix  = "_OnApprovalResolvedActivity"
+	onMessageQueuedActivitySuffix     = "_OnMessageQueuedActivity"
+	onSummarizedActivitySuffix        = "_OnSummarizedActivity"
+	onStopRequestedActivitySuffix     = "_OnStopRequestedActivity"
+)
+
+// observed makes a lifecycle hook method's error one Temporal will not retry.
+// With no RetryPolicy set the server retries without limit, and the workflow
+// waits on the activity the whole time: a CRM that is down would hold every
+// run that reports to it. The hook is an observer, and the agent goes on
+// without it; one that wants its work retried retries it itself.
+func observed[E any](fn func(context.Context, *E) error) func(context.Context, *E) error {
+	return func(ctx context.Context, event *E) error {
+		if err := fn(ctx, event); err != nil {
+			return temporal.NewNonRetryableApplicationError(err.Error(), LifecycleHookFailedErrorType, nil)
+		}
+		return nil
+	}
+}
+
+// lifecycleHookActivities returns the activities to register for an agent's
+// lifecycle hooks — one per event per hook, scoped to the agent as hook
+// activities are.
+func lifecycleHookActivities(agentName string, hooks []agents.LifecycleHook) map[string]any {
+	activities := map[string]any{}
+	for _, hook := range hooks {
+		if hook == nil {
+			continue
+		}
+		name := hookActivityName(agentName, hook.GetName())
+		activities[name+onRunStartActivitySuffix] = observed(hook.OnRunStart)
+		activities[name+onRunEndActivitySuffix] = observed(hook.OnRunEnd)
+		activities[name+onHandoffActivitySuffix] = observed(hook.OnHandoff)
+		activities[name+onApprovalRequestedActivitySuffix] = observed(hook.OnApprovalRequested)
+		activities[name+onApprovalResolvedActivitySuffix] = observed(hook.OnApprovalResolved)
+		activities[name+onMessageQueuedActivitySuffix] = observed(hook.OnMessageQueued)
+		activities[name+onSummarizedActivitySuffix] = observed(hook.OnSummarized)
+		activities[name+onStopRequestedActivitySuffix] = observed(hook.OnStopRequested)
+	}
+	return activities
+}
+
+// TemporalLifecycleHookProxy is a lifecycle hook as seen from inside a
+// workflow: each event is an activity, so a replayed run does not report it
+// again. The real hook runs on the activity side.
+type TemporalLifecycleHookProxy struct {
+	workflowCtx workflow.Context
+	name        string
+	displayName string
+}
+
+var _ agents.LifecycleHook = (*TemporalLifecycleHookProxy)(nil)
+
+func NewTemporalLifecycleHookProxy(workflowCtx workflow.Context, agentName, hookName string) *TemporalLifecycleHookProxy {
+	return &TemporalLifecycleHookProxy{
+		workflowCtx: workflowCtx,
+		name:        hookActivityName(agentName, hookName),
+		displayName: hookName,
+	}
+}
+
+// GetName is the hook's own name, which is what the agent logs a failure
+// under.
+func (h *TemporalLifecycleHookProxy) GetName() string { return h.displayName }
+
+func (h *TemporalLifecycleHookProxy) run(suffix string, event any) error {
+	return workflow.ExecuteActivity(h.workflowCtx, h.name+suffix, event).Get(h.workflowCtx, nil)
+}
+
+func (h *TemporalLifecycleHookProxy) OnRunStart(ctx context.Context, event *agents.RunStartEvent) error {
+	return h.run(onRunStartActivitySuffix, event)
+}
+
+func (h *TemporalLifecycleHookProxy) OnRunEnd(ctx context.Context, event *agents.RunEndEvent) error {
+	return h.run(onRunEndActivitySuffix, event)
+}
+
+func (h *TemporalLifecycleHookProxy) OnHandoff(ctx context.Context, event *agents.HandoffEvent) error {
+	return h.run(onHandoffActivitySuffix, event)
+}
+
+func (h *TemporalLifecycleHookProxy) OnApprovalRequested(ctx context.Context, event *agents.ApprovalRequestedEvent) error {
+	return h.run(onApprovalRequestedActivitySuffix, event)
+}
+
+func (h *TemporalLifecycleHookProxy) OnApprovalResolved(ctx context.Context, event *agents.ApprovalResolvedEvent) error {
+	return h.run(onApprovalResolvedActivitySuffix, event)
+}
+
+func (h *TemporalLifecycleHookProxy) OnMessageQueued(ctx context.Context, event *agents.MessageQueuedEvent) error {
+	return h.run(onMessageQueuedActivitySuffix, event)
+}
+
+func (h *TemporalLifecycleHookProxy) OnSummarized(ctx context.Context, event *agents.SummarizedEvent) error {
+	return h.run(onSummarizedActivitySuffix, event)
+}
+
+func (h *TemporalLifecycleHookProxy) OnStopRequested(ctx context.Context, event *agents.StopRequestedEvent) error {
+	return h.run(onStopRequestedActivitySuffix, event)
+}
+
+// lifecycleHookProxies builds the workflow-side stand-ins for an agent's
+// lifecycle hooks, in the order they were configured.
+func lifecycleHookProxies(workflowCtx workflow.Context, agentName string, hooks []agents.LifecycleHook) []agents.LifecycleHook {
+	var proxies []agents.LifecycleHook
+	for _, hook := range hooks {
+		if hook == nil {
+			continue
+		}
+		proxies = append(proxies, NewTemporalLifecycleHookProxy(workflowCtx, agentName, hook.GetName()))
+	}
+	return proxies
+}
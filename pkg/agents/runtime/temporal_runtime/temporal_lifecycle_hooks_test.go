@@ -0,0 +1,68 @@
+package temporal_runtime_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/runtime/temporal_runtime"
+)
+
+// crmHook stands in for a hook that reports handoffs to a CRM, which is down.
+type crmHook struct {
+	agents.NoopLifecycleHook
+	name  string
+	calls int
+}
+
+func (h *crmHook) GetName() string { return h.name }
+
+func (h *crmHook) OnHandoff(ctx context.Context, event *agents.HandoffEvent) error {
+	h.calls++
+	return errors.New("crm unavailable")
+}
+
+// A hook's error comes back to the workflow once, rather than being retried
+// until the service it reports to recovers.
+func TestTemporalLifecycleHookProxy_ErrorIsNotRetried(t *testing.T) {
+	hook := &crmHook{name: "crm"}
+	agent := temporal_runtime.NewTemporalAgent(nil, &agents.AgentOptions{
+		Name:           "Agent",
+		History:        newTestHistory(),
+		LifecycleHooks: []agents.LifecycleHook{hook},
+	}, nil)
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterActivityWithOptions(agent.GetActivities()["Agent_crm_OnHandoffActivity"], activityNamed("Agent_crm_OnHandoffActivity"))
+
+	env.RegisterWorkflow(lifecycleHookWorkflow)
+	env.ExecuteWorkflow(lifecycleHookWorkflow)
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+
+	var out string
+	require.NoError(t, env.GetWorkflowResult(&out))
+	assert.Contains(t, out, "crm unavailable")
+	assert.Equal(t, 1, hook.calls)
+}
+
+func lifecycleHookWorkflow(ctx workflow.Context) (string, error) {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: testActivityTimeout,
+	})
+
+	proxy := temporal_runtime.NewTemporalLifecycleHookProxy(ctx, "Agent", "crm")
+	err := proxy.OnHandoff(context.Background(), &agents.HandoffEvent{Source: "triage", Target: "billing"})
+	if err == nil {
+		return "", nil
+	}
+	return err.Error(), nil
+}
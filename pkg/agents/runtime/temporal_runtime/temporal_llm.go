@@ -8,9 +8,30 @@ import (
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
 	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 )
 
+// ModelCallFailedErrorType marks the activity failure a model call that
+// failed on its own reports.
+const ModelCallFailedErrorType = "ModelCallFailed"
+
+// modelCallRetryPolicy bounds the retries of a model call's activity. A
+// model's error is not retried at all (see modelCallError); what is retried
+// is the activity itself, for a timeout or a worker lost mid-call.
+var modelCallRetryPolicy = &temporal.RetryPolicy{MaximumAttempts: 3}
+
+// modelCallError converts a failed model call into an activity failure
+// Temporal will not retry: the turn goes on to the agent's fallbacks, or
+// fails the run, rather than waiting on the model that failed. A stop is
+// reported as the cancellation it is.
+func modelCallError(err error) error {
+	if stoppedWork(err) {
+		return cancellationError(err)
+	}
+	return temporal.NewNonRetryableApplicationError(err.Error(), ModelCallFailedErrorType, err)
+}
+
 type TemporalLLM struct {
 	wrappedLLM llm.Provider
 	broker     agents.StreamBroker
@@ -36,7 +57,7 @@ func (l *TemporalLLM) NewStreamingResponsesActivity(ctx context.Context, in *res
 
 	stream, err := l.wrappedLLM.NewStreamingResponses(ctx, in)
 	if err != nil {
-		return nil, cancellationError(err)
+		return nil, modelCallError(err)
 	}
 
 	acc := agents.Accumulator{}
@@ -48,7 +69,7 @@ func (l *TemporalLLM) NewStreamingResponsesActivity(ctx context.Context, in *res
 	if err != nil {
 		// Non-retryable, or Temporal calls the model again — the one thing a
 		// user who pressed stop must not get.
-		return nil, cancellationError(err)
+		return nil, modelCallError(err)
 	}
 
 	return resp, nil
@@ -70,7 +91,8 @@ func NewTemporalLLMProxy(workflowCtx workflow.Context, prefix string, broker age
 
 func (l *TemporalLLMProxy) NewStreamingResponses(ctx context.Context, in *responses.Request, cb func(chunk *responses.ResponseChunk)) (*responses.Response, error) {
 	var response *responses.Response
-	err := workflow.ExecuteActivity(l.workflowCtx, l.prefix+"_NewStreamingResponsesActivity", in).Get(l.workflowCtx, &response)
+	activityCtx := workflow.WithRetryPolicy(l.workflowCtx, *modelCallRetryPolicy)
+	err := workflow.ExecuteActivity(activityCtx, l.prefix+"_NewStreamingResponsesActivity", in).Get(l.workflowCtx, &response)
 	if err != nil {
 		// A call the stop cut short comes back as an activity failure. Report it
 		// as the stop it is, so the loop ends the run cleanly instead of
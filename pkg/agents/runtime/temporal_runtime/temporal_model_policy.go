@@ -0,0 +1,63 @@
+package temporal_runtime
+
+import (
+	"strconv"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Activity-name prefixes for the models of an agent's ModelPolicy. Each model
+// is called through an activity of its own, named like the agent's own
+// model's, so a turn retried on a fallback or made on the stronger model is
+// journaled as the call it was.
+func fallbackLLMPrefix(agentName string, i int) string {
+	return agentName + "_Fallback" + strconv.Itoa(i)
+}
+
+func escalationLLMPrefix(agentName string) string {
+	return agentName + "_Escalation"
+}
+
+// policyLLMActivities returns the activities to register for the models of
+// an agent's policy.
+func policyLLMActivities(agentName string, policy *agents.ModelPolicy, broker agents.StreamBroker) map[string]any {
+	activities := map[string]any{}
+	if policy == nil {
+		return activities
+	}
+
+	for i, p := range policy.Fallbacks {
+		if p == nil {
+			continue
+		}
+		activities[fallbackLLMPrefix(agentName, i)+"_NewStreamingResponsesActivity"] = NewTemporalLLM(p, broker).NewStreamingResponsesActivity
+	}
+
+	if policy.Escalation != nil && policy.Escalation.LLM != nil {
+		activities[escalationLLMPrefix(agentName)+"_NewStreamingResponsesActivity"] = NewTemporalLLM(policy.Escalation.LLM, broker).NewStreamingResponsesActivity
+	}
+
+	return activities
+}
+
+// policyLLMProxies builds the workflow-side stand-ins for the models of an
+// agent's policy, for agents.Agent.WithPolicyLLMs.
+func policyLLMProxies(ctx workflow.Context, agentName string, policy *agents.ModelPolicy, broker agents.StreamBroker) (fallbacks []agents.LLM, escalation agents.LLM) {
+	if policy == nil {
+		return nil, nil
+	}
+
+	for i, p := range policy.Fallbacks {
+		if p == nil {
+			continue
+		}
+		fallbacks = append(fallbacks, NewTemporalLLMProxy(ctx, fallbackLLMPrefix(agentName, i), broker))
+	}
+
+	if policy.Escalation != nil && policy.Escalation.LLM != nil {
+		escalation = NewTemporalLLMProxy(ctx, escalationLLMPrefix(agentName), broker)
+	}
+
+	return fallbacks, escalation
+}
@@ -0,0 +1,37 @@
+package temporal_runtime_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/prompts"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/runtime/temporal_runtime"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/streambroker"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/llmtest"
+)
+
+// A model's error fails its activity for good: the turn goes to the fallback
+// rather than Temporal calling the model that failed again.
+func TestTemporalAgent_FailedModelCallFallsBack(t *testing.T) {
+	primary := llmtest.New().Fail(errors.New("provider unavailable")).Fail(errors.New("provider unavailable"))
+	fallback := llmtest.New().RespondText("from the fallback")
+
+	agent := temporal_runtime.NewTemporalAgent(nil, &agents.AgentOptions{
+		Name:        "Agent",
+		Instruction: prompts.New("You are a tutor."),
+		LLM:         primary,
+		History:     newTestHistory(),
+		ModelPolicy: &agents.ModelPolicy{Fallbacks: []llm.Provider{fallback}},
+	}, streambroker.NewMemoryStreamBroker())
+
+	out := runAgentWorkflow(t, agent, "hello")
+
+	assert.Equal(t, agentstate.RunStatusCompleted, out.Status)
+	assert.Equal(t, 1, primary.Calls())
+	assert.Equal(t, 1, fallback.Calls())
+}
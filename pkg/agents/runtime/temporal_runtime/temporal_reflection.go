@@ -0,0 +1,63 @@
+package temporal_runtime
+
+import (
+	"context"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"go.temporal.io/sdk/workflow"
+)
+
+const critiqueActivitySuffix = "_CritiqueActivity"
+
+// reflectionActivities returns the activity to register for an agent's
+// critic, scoped to the agent like a guardrail's. Its error is left retryable
+// for the same reason.
+func reflectionActivities(agentName string, reflection *agents.Reflection) map[string]any {
+	if reflection == nil || reflection.Critic == nil {
+		return nil
+	}
+	return map[string]any{
+		hookActivityName(agentName, reflection.Critic.GetName()) + critiqueActivitySuffix: reflection.Critic.Critique,
+	}
+}
+
+// TemporalCriticProxy is a critic as seen from inside a workflow: each
+// critique runs as an activity, so its verdict is journaled once and replayed
+// thereafter.
+type TemporalCriticProxy struct {
+	workflowCtx workflow.Context
+	name        string
+	displayName string
+}
+
+var _ agents.Critic = (*TemporalCriticProxy)(nil)
+
+func NewTemporalCriticProxy(workflowCtx workflow.Context, agentName, criticName string) *TemporalCriticProxy {
+	return &TemporalCriticProxy{
+		workflowCtx: workflowCtx,
+		name:        hookActivityName(agentName, criticName),
+		displayName: criticName,
+	}
+}
+
+// GetName is the critic's own name, which the reflection.critique chunk
+// reports.
+func (c *TemporalCriticProxy) GetName() string { return c.displayName }
+
+func (c *TemporalCriticProxy) Critique(ctx context.Context, in *agents.CritiqueInput) (agents.CritiqueResult, error) {
+	var out agents.CritiqueResult
+	err := workflow.ExecuteActivity(c.workflowCtx, c.name+critiqueActivitySuffix, in).Get(c.workflowCtx, &out)
+	return out, err
+}
+
+// reflectionProxy is the agent's Reflection with its critic swapped for the
+// workflow-side stand-in.
+func reflectionProxy(workflowCtx workflow.Context, agentName string, reflection *agents.Reflection) *agents.Reflection {
+	if reflection == nil || reflection.Critic == nil {
+		return nil
+	}
+	return &agents.Reflection{
+		Critic:       NewTemporalCriticProxy(workflowCtx, agentName, reflection.Critic.GetName()),
+		MaxRevisions: reflection.MaxRevisions,
+	}
+}
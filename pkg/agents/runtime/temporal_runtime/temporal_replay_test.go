@@ -0,0 +1,262 @@
+package temporal_runtime_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	failurepb "go.temporal.io/api/failure/v1"
+	historypb "go.temporal.io/api/history/v1"
+	taskqueuepb "go.temporal.io/api/taskqueue/v1"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/patterns"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/runtime/temporal_runtime"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/streambroker"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/llmtest"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+)
+
+// Every step the agent's workflow hands to an activity — a guardrail check, a
+// model of the policy, a lifecycle hook, a handoff's input filter, the critic —
+// runs once, in the activity the agent registers for it, and a replay of the
+// workflow reads its result back from the history instead of running it
+// again. A step run in the workflow itself would run a second time on replay.
+func TestDurableSteps_AreNotRerunOnReplay(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		activity string
+		setup    func(opts *agents.AgentOptions) (step func(ctx workflow.Context) (any, error), ran func() int)
+	}{
+		{
+			name:     "input guardrail",
+			activity: "Agent_topic_CheckInputActivity",
+			setup: func(opts *agents.AgentOptions) (func(workflow.Context) (any, error), func() int) {
+				checks := 0
+				opts.InputGuardrails = []agents.InputGuardrail{agents.NewInputGuardrail("topic",
+					func(ctx context.Context, in *agents.GuardrailInput) (agents.GuardrailResult, error) {
+						checks++
+						return agents.TripGuardrail("off topic"), nil
+					})}
+				return func(ctx workflow.Context) (any, error) {
+					return temporal_runtime.NewTemporalGuardrailProxy(ctx, "Agent", "topic").
+						CheckInput(context.Background(), &agents.GuardrailInput{AgentName: "Agent"})
+				}, func() int { return checks }
+			},
+		},
+		{
+			name:     "fallback model",
+			activity: "Agent_Fallback0_NewStreamingResponsesActivity",
+			setup: func(opts *agents.AgentOptions) (func(workflow.Context) (any, error), func() int) {
+				fallback := llmtest.New().RespondText("from the fallback")
+				opts.ModelPolicy = &agents.ModelPolicy{Fallbacks: []llm.Provider{fallback}}
+				return func(ctx workflow.Context) (any, error) {
+					resp, err := temporal_runtime.NewTemporalLLMProxy(ctx, "Agent_Fallback0", nil).
+						NewStreamingResponses(context.Background(), &responses.Request{}, nil)
+					if err != nil {
+						return nil, err
+					}
+					return resp.Status, nil
+				}, fallback.Calls
+			},
+		},
+		{
+			name:     "lifecycle hook",
+			activity: "Agent_crm_OnHandoffActivity",
+			setup: func(opts *agents.AgentOptions) (func(workflow.Context) (any, error), func() int) {
+				hook := &crmHook{name: "crm"}
+				opts.LifecycleHooks = []agents.LifecycleHook{hook}
+				return func(ctx workflow.Context) (any, error) {
+					return nil, temporal_runtime.NewTemporalLifecycleHookProxy(ctx, "Agent", "crm").
+						OnHandoff(context.Background(), &agents.HandoffEvent{Source: "triage", Target: "billing"})
+				}, func() int { return hook.calls }
+			},
+		},
+		{
+			name:     "handoff input filter",
+			activity: "Agent_billing_HandoffInputFilterActivity",
+			setup: func(opts *agents.AgentOptions) (func(workflow.Context) (any, error), func() int) {
+				filters := 0
+				billing := agents.NewAgent(&agents.AgentOptions{Name: "billing", History: newTestHistory()})
+				handoff := agents.NewHandoff("billing", "handles billing", billing)
+				handoff.InputFilter = func(ctx context.Context, in *agents.HandoffInput) ([]responses.InputMessageUnion, error) {
+					filters++
+					return nil, nil
+				}
+				opts.Handoffs = []*agents.Handoff{handoff}
+				return func(ctx workflow.Context) (any, error) {
+					out, err := temporal_runtime.NewTemporalHandoffInputFilterProxy(ctx, "Agent", handoff)(context.Background(), &agents.HandoffInput{})
+					return len(out), err
+				}, func() int { return filters }
+			},
+		},
+		{
+			name:     "critic",
+			activity: "Agent_editor_CritiqueActivity",
+			setup: func(opts *agents.AgentOptions) (func(workflow.Context) (any, error), func() int) {
+				critiques := 0
+				opts.Reflection = &agents.Reflection{Critic: patterns.NewCritic("editor",
+					func(ctx context.Context, in *agents.CritiqueInput) (agents.CritiqueResult, error) {
+						critiques++
+						return agents.CritiqueResult{Feedback: "cite the policy"}, nil
+					})}
+				return func(ctx workflow.Context) (any, error) {
+					return temporal_runtime.NewTemporalCriticProxy(ctx, "Agent", "editor").
+						Critique(context.Background(), &agents.CritiqueInput{})
+				}, func() int { return critiques }
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := &agents.AgentOptions{Name: "Agent", LLM: llmtest.New(), History: newTestHistory()}
+			step, ran := tc.setup(opts)
+			agent := temporal_runtime.NewTemporalAgent(nil, opts, streambroker.NewMemoryStreamBroker())
+
+			stepWorkflow := func(ctx workflow.Context) (string, error) {
+				ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+					StartToCloseTimeout: testActivityTimeout,
+				})
+				out, err := step(ctx)
+				if err != nil {
+					return "error", nil
+				}
+				return fmt.Sprint(out), nil
+			}
+
+			history := runStep(t, agent, stepWorkflow, tc.activity)
+			require.Equal(t, 1, ran(), "the step runs once, in its activity")
+
+			replayer := worker.NewWorkflowReplayer()
+			replayer.RegisterWorkflowWithOptions(stepWorkflow, workflow.RegisterOptions{Name: "StepWorkflow"})
+			require.NoError(t, replayer.ReplayWorkflowHistory(nil, history))
+			assert.Equal(t, 1, ran(), "the replay reads the step's result from the history")
+		})
+	}
+}
+
+// runStep runs stepWorkflow with the agent's activities and returns the
+// history a Temporal server would have recorded for it: the one activity,
+// named activityName, and the workflow's result. The test environment keeps
+// no history of its own, and it never replays.
+func runStep(t *testing.T, agent *temporal_runtime.TemporalAgentV2, stepWorkflow func(workflow.Context) (string, error), activityName string) *historypb.History {
+	t.Helper()
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	for name, fn := range agent.GetActivities() {
+		env.RegisterActivityWithOptions(fn, activityNamed(name))
+	}
+	env.RegisterWorkflowWithOptions(stepWorkflow, workflow.RegisterOptions{Name: "StepWorkflow"})
+
+	var activities []string
+	var result *commonpb.Payloads
+	var failure *failurepb.Failure
+	env.SetOnActivityCompletedListener(func(info *activity.Info, value converter.EncodedValue, err error) {
+		activities = append(activities, info.ActivityType.Name)
+		if err != nil {
+			failure = temporal.GetDefaultFailureConverter().ErrorToFailure(err)
+			return
+		}
+		if value != nil && value.HasValue() {
+			var raw json.RawMessage
+			require.NoError(t, value.Get(&raw))
+			result = encoded(t, raw)
+		}
+	})
+
+	env.ExecuteWorkflow("StepWorkflow")
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	require.Equal(t, []string{activityName}, activities)
+
+	var out string
+	require.NoError(t, env.GetWorkflowResult(&out))
+
+	taskQueue := &taskqueuepb.TaskQueue{Name: "default-test-taskqueue"}
+	events := []*historypb.HistoryEvent{
+		{EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED, Attributes: &historypb.HistoryEvent_WorkflowExecutionStartedEventAttributes{
+			WorkflowExecutionStartedEventAttributes: &historypb.WorkflowExecutionStartedEventAttributes{
+				WorkflowType: &commonpb.WorkflowType{Name: "StepWorkflow"},
+				TaskQueue:    taskQueue,
+			},
+		}},
+		{EventType: enumspb.EVENT_TYPE_WORKFLOW_TASK_SCHEDULED, Attributes: &historypb.HistoryEvent_WorkflowTaskScheduledEventAttributes{
+			WorkflowTaskScheduledEventAttributes: &historypb.WorkflowTaskScheduledEventAttributes{TaskQueue: taskQueue},
+		}},
+		{EventType: enumspb.EVENT_TYPE_WORKFLOW_TASK_STARTED, Attributes: &historypb.HistoryEvent_WorkflowTaskStartedEventAttributes{
+			WorkflowTaskStartedEventAttributes: &historypb.WorkflowTaskStartedEventAttributes{ScheduledEventId: 2},
+		}},
+		{EventType: enumspb.EVENT_TYPE_WORKFLOW_TASK_COMPLETED, Attributes: &historypb.HistoryEvent_WorkflowTaskCompletedEventAttributes{
+			WorkflowTaskCompletedEventAttributes: &historypb.WorkflowTaskCompletedEventAttributes{ScheduledEventId: 2, StartedEventId: 3},
+		}},
+		{EventType: enumspb.EVENT_TYPE_ACTIVITY_TASK_SCHEDULED, Attributes: &historypb.HistoryEvent_ActivityTaskScheduledEventAttributes{
+			ActivityTaskScheduledEventAttributes: &historypb.ActivityTaskScheduledEventAttributes{
+				ActivityId:                   "5", // a worker names an activity after the event scheduling it
+				ActivityType:                 &commonpb.ActivityType{Name: activityName},
+				TaskQueue:                    taskQueue,
+				WorkflowTaskCompletedEventId: 4,
+			},
+		}},
+		{EventType: enumspb.EVENT_TYPE_ACTIVITY_TASK_STARTED, Attributes: &historypb.HistoryEvent_ActivityTaskStartedEventAttributes{
+			ActivityTaskStartedEventAttributes: &historypb.ActivityTaskStartedEventAttributes{ScheduledEventId: 5, Attempt: 1},
+		}},
+	}
+	if failure != nil {
+		events = append(events, &historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_ACTIVITY_TASK_FAILED, Attributes: &historypb.HistoryEvent_ActivityTaskFailedEventAttributes{
+			ActivityTaskFailedEventAttributes: &historypb.ActivityTaskFailedEventAttributes{
+				Failure:          failure,
+				ScheduledEventId: 5,
+				StartedEventId:   6,
+				RetryState:       enumspb.RETRY_STATE_NON_RETRYABLE_FAILURE,
+			},
+		}})
+	} else {
+		events = append(events, &historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_ACTIVITY_TASK_COMPLETED, Attributes: &historypb.HistoryEvent_ActivityTaskCompletedEventAttributes{
+			ActivityTaskCompletedEventAttributes: &historypb.ActivityTaskCompletedEventAttributes{
+				Result:           result,
+				ScheduledEventId: 5,
+				StartedEventId:   6,
+			},
+		}})
+	}
+	events = append(events,
+		&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_WORKFLOW_TASK_SCHEDULED, Attributes: &historypb.HistoryEvent_WorkflowTaskScheduledEventAttributes{
+			WorkflowTaskScheduledEventAttributes: &historypb.WorkflowTaskScheduledEventAttributes{TaskQueue: taskQueue},
+		}},
+		&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_WORKFLOW_TASK_STARTED, Attributes: &historypb.HistoryEvent_WorkflowTaskStartedEventAttributes{
+			WorkflowTaskStartedEventAttributes: &historypb.WorkflowTaskStartedEventAttributes{ScheduledEventId: 8},
+		}},
+		&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_WORKFLOW_TASK_COMPLETED, Attributes: &historypb.HistoryEvent_WorkflowTaskCompletedEventAttributes{
+			WorkflowTaskCompletedEventAttributes: &historypb.WorkflowTaskCompletedEventAttributes{ScheduledEventId: 8, StartedEventId: 9},
+		}},
+		&historypb.HistoryEvent{EventType: enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_COMPLETED, Attributes: &historypb.HistoryEvent_WorkflowExecutionCompletedEventAttributes{
+			WorkflowExecutionCompletedEventAttributes: &historypb.WorkflowExecutionCompletedEventAttributes{
+				Result:                       encoded(t, out),
+				WorkflowTaskCompletedEventId: 10,
+			},
+		}},
+	)
+	for i, event := range events {
+		event.EventId = int64(i + 1)
+	}
+	return &historypb.History{Events: events}
+}
+
+func encoded(t *testing.T, value any) *commonpb.Payloads {
+	t.Helper()
+	payloads, err := converter.GetDefaultDataConverter().ToPayloads(value)
+	require.NoError(t, err)
+	return payloads
+}
@@ -0,0 +1,260 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	invopop "github.com/invopop/jsonschema"
+)
+
+// DefaultMaxOutputRepairs is how many times an agent with an Output schema
+// asks the model to fix an answer that does not match it, when
+// AgentOptions.MaxOutputRepairs is not set.
+const DefaultMaxOutputRepairs = 2
+
+// NewOutputSchema reflects v's type into the JSON schema AgentOptions.Output
+// takes. It returns nil if the type cannot be reflected.
+func NewOutputSchema(v any) map[string]any {
+	r := &invopop.Reflector{
+		// Inline the root struct (type/properties/required at top level)
+		// instead of emitting a top-level $ref into $defs.
+		ExpandedStruct: true,
+		// Inline all nested definitions so the schema is self-contained
+		// (no $ref/$defs), which the LLM tool APIs require.
+		DoNotReference: true,
+	}
+
+	schema := r.Reflect(v)
+	// Drop $schema and $id metadata that the tool APIs reject.
+	schema.Version = ""
+	schema.ID = ""
+
+	buf, err := schema.MarshalJSON()
+	if err != nil {
+		slog.Warn("failed to reflect output schema: " + err.Error())
+		return nil
+	}
+
+	ss := map[string]any{}
+	err = sonic.Unmarshal(buf, &ss)
+	if err != nil {
+		slog.Warn("failed to unmarshal output schema: " + err.Error())
+		return nil
+	}
+
+	return ss
+}
+
+// OutputSchemaFor is NewOutputSchema for the type T.
+func OutputSchemaFor[T any]() map[string]any {
+	return NewOutputSchema(new(T))
+}
+
+// OutputValidationError is what a run fails with when its final answer still
+// does not match the agent's output schema after the repair turns ran out.
+type OutputValidationError struct {
+	// Err is why the last answer was rejected: it was not JSON, or it broke
+	// the schema.
+	Err error
+	// RawText is the last answer, exactly as the model wrote it.
+	RawText string
+	// Repairs is how many repair turns the model was given.
+	Repairs int
+}
+
+func (e *OutputValidationError) Error() string {
+	return fmt.Sprintf("structured output does not match the schema after %d repair turns: %v", e.Repairs, e.Err)
+}
+
+func (e *OutputValidationError) Unwrap() error {
+	return e.Err
+}
+
+// outputValidator checks a final answer against the agent's Output schema.
+type outputValidator struct {
+	schema *jsonschema.Resolved
+}
+
+// newOutputValidator resolves schema for validation. A schema the validator
+// cannot read is logged and leaves the agent unvalidated, as it was before
+// validation existed, rather than failing every run.
+func newOutputValidator(schema map[string]any) *outputValidator {
+	if schema == nil {
+		return nil
+	}
+
+	buf, err := sonic.Marshal(schema)
+	if err != nil {
+		slog.Warn("output schema is not serializable, answers will not be validated", slog.Any("error", err))
+		return nil
+	}
+
+	s := &jsonschema.Schema{}
+	if err := s.UnmarshalJSON(buf); err != nil {
+		slog.Warn("output schema is not a JSON schema, answers will not be validated", slog.Any("error", err))
+		return nil
+	}
+
+	resolved, err := s.Resolve(nil)
+	if err != nil {
+		slog.Warn("output schema cannot be resolved, answers will not be validated", slog.Any("error", err))
+		return nil
+	}
+
+	return &outputValidator{schema: resolved}
+}
+
+// validate returns why the model's final output does not match the schema,
+// or nil if it does.
+func (v *outputValidator) validate(output []responses.OutputMessageUnion) *OutputValidationError {
+	if v == nil {
+		return nil
+	}
+
+	text := ""
+	for _, msg := range output {
+		if msg.OfOutputMessage != nil {
+			text = outputMessageText(msg.OfOutputMessage.Content)
+		}
+	}
+
+	var instance any
+	if err := sonic.Unmarshal([]byte(stripCodeFence(text)), &instance); err != nil {
+		return &OutputValidationError{Err: fmt.Errorf("the answer is not valid JSON: %w", err), RawText: text}
+	}
+
+	if err := v.schema.Validate(instance); err != nil {
+		return &OutputValidationError{Err: err, RawText: text}
+	}
+
+	return nil
+}
+
+// outputRepairMessage asks the model to answer again, telling it what was
+// wrong with the answer it gave.
+func outputRepairMessage(verr *OutputValidationError) responses.InputMessageUnion {
+	text := "Your answer does not match the required JSON schema: " + verr.Err.Error() +
+		"\nReply again with only the corrected JSON object, and nothing else."
+
+	return responses.InputMessageUnion{
+		OfInputMessage: &responses.InputMessage{
+			Role: constants.RoleUser,
+			Content: responses.InputContent{
+				{OfInputText: &responses.InputTextContent{Text: text}},
+			},
+		},
+	}
+}
+
+// stripCodeFence removes the ```json fence models sometimes put around JSON
+// even when asked for JSON alone.
+func stripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") || !strings.HasSuffix(text, "```") || len(text) < 6 {
+		return text
+	}
+
+	text = strings.TrimSuffix(strings.TrimPrefix(text, "```"), "```")
+	// The fence's language tag runs to the end of its line.
+	if i := strings.IndexByte(text, '\n'); i >= 0 && !strings.ContainsAny(text[:i], "{[") {
+		text = text[i+1:]
+	}
+
+	return strings.TrimSpace(text)
+}
+
+func outputMessageText(content *responses.OutputContent) string {
+	if content == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, c := range *content {
+		if c.OfOutputText != nil {
+			b.WriteString(c.OfOutputText.Text)
+		}
+	}
+
+	return b.String()
+}
+
+// Text returns the text of the run's last assistant message.
+func (o *AgentOutput) Text() string {
+	for i := len(o.Output) - 1; i >= 0; i-- {
+		msg := o.Output[i]
+		switch {
+		case msg.OfOutputMessage != nil:
+			return outputMessageText(msg.OfOutputMessage.Content)
+
+		case msg.OfInputMessage != nil && msg.OfInputMessage.Role == constants.RoleAssistant:
+			var b strings.Builder
+			for _, c := range msg.OfInputMessage.Content {
+				if c.OfOutputText != nil {
+					b.WriteString(c.OfOutputText.Text)
+				}
+			}
+			return b.String()
+		}
+	}
+
+	return ""
+}
+
+// Decode unmarshals the run's last assistant message into v. It is how a
+// caller reads the answer of an agent configured with an Output schema.
+func (o *AgentOutput) Decode(v any) error {
+	text := o.Text()
+	if text == "" {
+		return errors.New("the run produced no assistant message to decode")
+	}
+
+	if err := sonic.Unmarshal([]byte(stripCodeFence(text)), v); err != nil {
+		return fmt.Errorf("failed to decode structured output: %w", err)
+	}
+
+	return nil
+}
+
+// WithOutput returns a copy of the agent that answers in, and is validated
+// against, the given JSON schema — see AgentOptions.Output.
+func (e *Agent) WithOutput(schema map[string]any) *Agent {
+	clone := *e
+	clone.output = schema
+	clone.outputValidator = newOutputValidator(schema)
+	return &clone
+}
+
+// RunTyped runs the agent with T's schema as its Output and decodes the answer
+// into a T. An answer that still breaks the schema once the repair turns are
+// spent fails the run with an *OutputValidationError carrying the raw text.
+//
+// A run that pauses returns the zero T with the paused output, to be resumed
+// like any other. RunTyped drains the stream; to watch the chunks, call
+// WithOutput and Execute and decode the result with AgentOutput.Decode.
+//
+// Under the Temporal and Restate runtimes the worker builds the agent from its
+// own options, so give those Output: OutputSchemaFor[T]() as well.
+func RunTyped[T any](ctx context.Context, agent *Agent, in *AgentInput) (T, *AgentOutput, error) {
+	var result T
+
+	handle, err := agent.WithOutput(OutputSchemaFor[T]()).Execute(ctx, in)
+	if err != nil {
+		return result, nil, err
+	}
+
+	out, err := handle.Result()
+	if err != nil || out == nil || out.Status != agentstate.RunStatusCompleted {
+		return result, out, err
+	}
+
+	err = out.Decode(&result)
+	return result, out, err
+}
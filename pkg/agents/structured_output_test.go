@@ -0,0 +1,91 @@
+package agents_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/llmtest"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+)
+
+type weather struct {
+	City    string  `json:"city"`
+	Celsius float64 `json:"celsius"`
+}
+
+func TestRunTyped_DecodesValidAnswer(t *testing.T) {
+	fake := llmtest.New().RespondText("```json\n{\"city\":\"Paris\",\"celsius\":22}\n```")
+	agent := newScriptedAgent("forecaster", nil, nil, nil, nil, nil, scriptedBy(fake))
+
+	got, out, err := agents.RunTyped[weather](context.Background(), agent, &agents.AgentInput{
+		Message: userMessage("weather in Paris?"),
+	})
+	require.NoError(t, err)
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Equal(t, weather{City: "Paris", Celsius: 22}, got)
+
+	// The schema derived from T is what the model was asked to follow.
+	format := fake.Request(0).Parameters.Text.Format
+	schema := format["schema"].(map[string]any)
+	assert.Contains(t, schema["properties"], "celsius")
+}
+
+func TestRunTyped_RepairsInvalidAnswer(t *testing.T) {
+	fake := llmtest.New().
+		RespondText(`It is 22 degrees in Paris.`).
+		RespondText(`{"city":"Paris"}`).
+		RespondText(`{"city":"Paris","celsius":22}`)
+	agent := newScriptedAgent("forecaster", nil, nil, nil, nil, nil, scriptedBy(fake))
+
+	got, out, err := agents.RunTyped[weather](context.Background(), agent, &agents.AgentInput{
+		Message: userMessage("weather in Paris?"),
+	})
+	require.NoError(t, err)
+	requireStatus(t, out, agentstate.RunStatusCompleted)
+	assert.Equal(t, 22.0, got.Celsius)
+	require.Equal(t, 3, fake.Calls())
+
+	// Each repair turn tells the model what was wrong with its last answer.
+	assert.Contains(t, messagesText(fake.Request(1).Input.OfInputMessageList), "not valid JSON")
+	assert.Contains(t, messagesText(fake.Request(2).Input.OfInputMessageList), "celsius")
+}
+
+func TestRunTyped_FailsWhenRepairsRunOut(t *testing.T) {
+	fake := llmtest.New().
+		RespondText(`{"city":"Paris"}`).
+		RespondText(`{"city":"Paris","celsius":"warm"}`)
+	agent := newScriptedAgent("forecaster", nil, nil, nil, nil, nil, scriptedBy(fake), func(o *agents.AgentOptions) {
+		o.MaxOutputRepairs = utils.Ptr(1)
+	})
+
+	_, out, err := agents.RunTyped[weather](context.Background(), agent, &agents.AgentInput{
+		Message: userMessage("weather in Paris?"),
+	})
+	require.Error(t, err)
+	requireStatus(t, out, agentstate.RunStatusError)
+
+	var verr *agents.OutputValidationError
+	require.True(t, errors.As(err, &verr))
+	assert.Equal(t, 1, verr.Repairs)
+	assert.Equal(t, `{"city":"Paris","celsius":"warm"}`, verr.RawText)
+	assert.Equal(t, 2, fake.Calls())
+}
+
+func TestAgentOutputDecode(t *testing.T) {
+	fake := llmtest.New().RespondText(`{"city":"Oslo","celsius":-3}`)
+	agent := newScriptedAgent("forecaster", nil, nil, nil, nil, nil, scriptedBy(fake)).WithOutput(agents.OutputSchemaFor[weather]())
+
+	out := runAgent(t, agent, &agents.AgentInput{Message: userMessage("weather in Oslo?")})
+
+	var got weather
+	require.NoError(t, out.Decode(&got))
+	assert.Equal(t, "Oslo", got.City)
+	assert.True(t, strings.HasPrefix(out.Text(), "{"))
+}
@@ -3,6 +3,7 @@ package agents
 import (
 	"context"
 
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
 )
 
@@ -32,6 +33,10 @@ type ToolCall struct {
 	// ShouldResume is false.
 	ResumeMessages []responses.InputMessageUnion `json:"resume_messages,omitempty"`
 
+	// Budget is what is left of the run's RunBudget, for a tool that runs an
+	// agent of its own to run it under. Nil when the run has no budget.
+	Budget *RunBudget `json:"budget,omitempty"`
+
 	// Progress is the sink for mid-execution progress updates. It is
 	// injected by whoever runs the tool in-process (the agent loop for the
 	// local runtime; the tool activity/step for durable runtimes) and is
@@ -45,6 +50,14 @@ type ToolCallResponse struct {
 	*responses.FunctionCallOutputMessage
 	StateUpdates map[string]string     `json:"state_updates,omitempty"`
 	Interrupts   []responses.Interrupt `json:"interrupts,omitempty"`
+
+	// Spend is what the tool spent of the ToolCall's Budget, which the run
+	// adds to its own. AgentTool reports its sub-agent's here.
+	Spend *agentstate.BudgetSpend `json:"spend,omitempty"`
+
+	// SharedState is merged, key by key, into the state the run shares with
+	// its client, which is then sent to it. Nil leaves it as it was.
+	SharedState map[string]any `json:"shared_state,omitempty"`
 }
 
 type Tool interface {
@@ -89,6 +89,10 @@ type DefaultToolExecutor struct {
 	// loop before anything reaches an executor, and the target agent's own
 	// hooks apply to what it then does.
 	Hooks []ToolCallHook
+
+	// injectedWatcher is whether StopWatcher came from a broker rather
+	// than the caller, so binding another broker replaces it.
+	injectedWatcher bool
 }
 
 var (
@@ -104,12 +108,14 @@ func (e *DefaultToolExecutor) WithToolCallHooks(hooks []ToolCallHook) ToolExecut
 }
 
 // WithStreamBroker implements BrokerAwareToolExecutor. A watcher set by
-// the caller wins; injection only fills a gap.
+// the caller wins; injection only fills a gap, or replaces the watcher of
+// the broker bound before.
 func (e *DefaultToolExecutor) WithStreamBroker(broker StreamBroker) ToolExecutor {
 	bound := *e
-	if bound.StopWatcher == nil {
+	if bound.StopWatcher == nil || bound.injectedWatcher {
+		bound.StopWatcher, bound.injectedWatcher = nil, false
 		if watcher, ok := broker.(StopWatcher); ok {
-			bound.StopWatcher = watcher
+			bound.StopWatcher, bound.injectedWatcher = watcher, true
 		}
 	}
 	return &bound
@@ -181,6 +181,9 @@ func (t *AgentTool) Execute(ctx context.Context, params *agents.ToolCall) (*agen
 		PreviousRunID: previousRunID,
 		Message:       history.Message{SenderID: params.AgentName, Messages: messages},
 		SessionID:     params.SessionID, // Using conversation id as the shared session id
+		// What the caller has left of its budget, so a sub-agent cannot spend
+		// past it.
+		Budget: params.Budget,
 	}
 	if t.withoutTracing {
 		result, err = t.agent.ExecuteWithoutTrace(ctx, agentInput)
@@ -265,9 +268,20 @@ func (t *AgentTool) responseFromResult(params *agents.ToolCall, result *agents.A
 		StateUpdates: map[string]string{
 			t.getSubAgentThreadIdStateKey(): threadId,
 		},
+		Spend: spendOf(result),
 	}, nil
 }
 
+// spendOf is what the sub-agent's run spent, reported back so the caller's
+// budget covers it. Only a finished run reports: a paused one's spend is
+// carried in its own run state and reported once it finishes.
+func spendOf(result *agents.AgentOutput) *agentstate.BudgetSpend {
+	if result == nil {
+		return nil
+	}
+	return result.Spend
+}
+
 func (t *AgentTool) getSubAgentThreadIdStateKey() string {
 	return fmt.Sprintf("sub_agent_thread_id/%s", t.agent.Name)
 }
@@ -0,0 +1,454 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/google/uuid"
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/history"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+)
+
+// FanOutTaskStatus is how a task of a fan-out ended.
+type FanOutTaskStatus string
+
+const (
+	FanOutTaskCompleted FanOutTaskStatus = "completed"
+	FanOutTaskFailed    FanOutTaskStatus = "failed"
+
+	// fanOutTaskPaused is a task waiting on an approval. It never reaches the
+	// aggregate: the call pauses until every task has ended.
+	fanOutTaskPaused FanOutTaskStatus = "paused"
+)
+
+// FanOutTaskResult is one task's entry in a FanOutResult.
+type FanOutTaskResult struct {
+	ID     string           `json:"id"`
+	Status FanOutTaskStatus `json:"status"`
+	Output string           `json:"output,omitempty"`
+	Error  string           `json:"error,omitempty"`
+}
+
+// FanOutResult is what a FanOutTool answers with, in the order the tasks
+// were given. Error is set when more tasks failed than the tool tolerates.
+type FanOutResult struct {
+	Succeeded int                `json:"succeeded"`
+	Failed    int                `json:"failed"`
+	Error     string             `json:"error,omitempty"`
+	Results   []FanOutTaskResult `json:"results"`
+}
+
+// FanOutTool runs one task of a sub-agent per entry of its call, at once, and
+// answers with every task's result or error. It is AgentTool for work that
+// splits — one task per competitor, per source — which through AgentTool
+// would take the model a call per task.
+//
+// Each task is a run of its own on a fresh thread. A task that asks for
+// approval pauses the call; the interrupts of every paused task surface
+// together, and the call resumes once they are resolved, running only the
+// tasks that were.
+type FanOutTool struct {
+	*agents.BaseTool
+	agent       *agents.Agent
+	concurrency int
+	taskTimeout time.Duration
+	maxFailures int
+}
+
+type fanOutArgument struct {
+	Tasks []fanOutTask `json:"tasks"`
+}
+
+type fanOutTask struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+// fanOutTaskState is a task as kept across a pause of the call.
+type fanOutTaskState struct {
+	FanOutTaskResult
+	Message    string                `json:"message"`
+	ThreadID   string                `json:"thread_id"`
+	RunID      string                `json:"run_id,omitempty"`
+	Interrupts []responses.Interrupt `json:"interrupts,omitempty"`
+}
+
+type FanOutOption func(*FanOutTool)
+
+// WithConcurrency caps how many tasks run at a time. By default they all do.
+func WithConcurrency(n int) FanOutOption {
+	return func(t *FanOutTool) {
+		t.concurrency = n
+	}
+}
+
+// WithTaskTimeout fails a task that runs longer than d. A task resumed after
+// an approval gets d again.
+func WithTaskTimeout(d time.Duration) FanOutOption {
+	return func(t *FanOutTool) {
+		t.taskTimeout = d
+	}
+}
+
+// WithMaxFailures is how many tasks may fail before the fan-out as a whole
+// has. Past it the results still come back, with FanOutResult.Error set, so
+// what the tasks that did finish found — and spent — is not lost. By default
+// any number may fail.
+func WithMaxFailures(n int) FanOutOption {
+	return func(t *FanOutTool) {
+		t.maxFailures = n
+	}
+}
+
+func NewFanOutTool(name string, description string, agent *agents.Agent, opts ...FanOutOption) *FanOutTool {
+	ft := &FanOutTool{
+		BaseTool: &agents.BaseTool{
+			ToolUnion: responses.ToolUnion{
+				OfFunction: &responses.FunctionTool{
+					Name:        name,
+					Description: utils.Ptr(description),
+					Parameters: map[string]any{
+						"type":     "object",
+						"required": []string{"tasks"},
+						"properties": map[string]any{
+							"tasks": map[string]any{
+								"type":        "array",
+								"description": "Tasks for the agent, run at the same time. Each is done on its own, without seeing the others.",
+								"minItems":    1,
+								"items": map[string]any{
+									"type":     "object",
+									"required": []string{"id", "message"},
+									"properties": map[string]any{
+										"id": map[string]any{
+											"type":        "string",
+											"description": "A short name for the task, unique within the call. Results are reported under it.",
+										},
+										"message": map[string]any{
+											"type":        "string",
+											"description": "Message for the agent",
+										},
+									},
+									"additionalProperties": false,
+								},
+							},
+						},
+						"additionalProperties": false,
+					},
+				},
+			},
+		},
+		agent:       agent,
+		maxFailures: -1,
+	}
+
+	for _, opt := range opts {
+		opt(ft)
+	}
+
+	return ft
+}
+
+// Execute starts every task of the call, or on resume continues the tasks
+// whose interrupts were resolved, and waits for them. Tasks that finished
+// before a pause keep their results; they are not run again.
+//
+// Every task is handed what the caller has left of its budget. They run at
+// once, so together they may spend past it; what they spent is reported back
+// and the caller's next turn sees it.
+func (t *FanOutTool) Execute(ctx context.Context, params *agents.ToolCall) (*agents.ToolCallResponse, error) {
+	var tasks []*fanOutTaskState
+	resume := map[int][]responses.InterruptResolution{}
+
+	if params.ShouldResume {
+		raw, ok := params.State[t.getStateKey(params.ID)]
+		if !ok || raw == "" {
+			return nil, fmt.Errorf("fan_out: cannot resume — saved tasks missing for tool call %s", params.ID)
+		}
+		if err := sonic.Unmarshal([]byte(raw), &tasks); err != nil {
+			return nil, fmt.Errorf("fan_out: malformed saved tasks: %w", err)
+		}
+
+		// Each resolution goes to the task that is waiting on it. The loop
+		// hands over every decision made on this call so far, so one for an
+		// interrupt a task is no longer waiting on is left out.
+		resolutions := map[string]responses.InterruptResolution{}
+		for _, msg := range params.ResumeMessages {
+			if msg.OfFunctionCallInterruptResolution == nil {
+				continue
+			}
+			for _, res := range msg.OfFunctionCallInterruptResolution.Resolutions {
+				resolutions[res.CallID] = res
+			}
+		}
+		for i, task := range tasks {
+			if task.Status != fanOutTaskPaused {
+				continue
+			}
+			for _, intr := range task.Interrupts {
+				if res, ok := resolutions[intr.FunctionCallMessage.CallID]; ok {
+					resume[i] = append(resume[i], res)
+				}
+			}
+		}
+	} else {
+		var args fanOutArgument
+		if err := sonic.Unmarshal([]byte(params.Arguments), &args); err != nil {
+			return nil, err
+		}
+		if len(args.Tasks) == 0 {
+			return nil, errors.New("fan_out: no tasks given")
+		}
+
+		seen := map[string]bool{}
+		for _, task := range args.Tasks {
+			if task.ID == "" {
+				return nil, errors.New("fan_out: every task needs an id")
+			}
+			if seen[task.ID] {
+				return nil, fmt.Errorf("fan_out: task id %q is used twice", task.ID)
+			}
+			seen[task.ID] = true
+
+			tasks = append(tasks, &fanOutTaskState{
+				FanOutTaskResult: FanOutTaskResult{ID: task.ID},
+				Message:          task.Message,
+				ThreadID:         uuid.NewString(),
+			})
+		}
+	}
+
+	var toRun []int
+	for i, task := range tasks {
+		if task.Status == "" || len(resume[i]) > 0 {
+			toRun = append(toRun, i)
+		}
+	}
+
+	spend := t.runAll(ctx, params, tasks, toRun, resume)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return t.response(params, tasks, spend)
+}
+
+// runAll runs the tasks at the given indexes, at most concurrency at a time,
+// and returns what the ones that ended spent.
+func (t *FanOutTool) runAll(ctx context.Context, params *agents.ToolCall, tasks []*fanOutTaskState, toRun []int, resume map[int][]responses.InterruptResolution) *agentstate.BudgetSpend {
+	limit := t.concurrency
+	if limit <= 0 || limit > len(toRun) {
+		limit = len(toRun)
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		spend agentstate.BudgetSpend
+		ended int
+	)
+	for _, task := range tasks {
+		if task.Status == FanOutTaskCompleted || task.Status == FanOutTaskFailed {
+			ended++
+		}
+	}
+
+	// Progress counts tasks that have ended, over all of the call's.
+	report := func(task *fanOutTaskState, done bool, message string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if done {
+			ended++
+		}
+		params.ReportProgress(ctx, agents.ToolProgress{
+			Progress: float64(ended),
+			Total:    float64(len(tasks)),
+			Message:  task.ID + ": " + message,
+		})
+	}
+
+	sem := make(chan struct{}, max(limit, 1))
+	for _, i := range toRun {
+		task := tasks[i]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+
+			report(task, false, "started")
+			taskSpend := t.run(ctx, params, task, resume[i])
+
+			switch task.Status {
+			case fanOutTaskPaused:
+				report(task, false, "waiting for approval")
+			case FanOutTaskFailed:
+				report(task, true, "failed: "+task.Error)
+			default:
+				report(task, true, "completed")
+			}
+
+			mu.Lock()
+			spend.Add(taskSpend)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return &spend
+}
+
+// run runs one task, or continues it with the given resolutions, and records
+// how it ended on task.
+func (t *FanOutTool) run(ctx context.Context, params *agents.ToolCall, task *fanOutTaskState, resolutions []responses.InterruptResolution) *agentstate.BudgetSpend {
+	var (
+		previousRunID string
+		messages      []responses.InputMessageUnion
+	)
+	if len(resolutions) > 0 {
+		previousRunID = task.RunID
+		messages = []responses.InputMessageUnion{{
+			OfFunctionCallInterruptResolution: &responses.FunctionCallInterruptResolutionMessage{
+				Resolutions: resolutions,
+			},
+		}}
+	} else {
+		messages = []responses.InputMessageUnion{{
+			OfEasyInput: &responses.EasyMessage{
+				Role:    constants.RoleUser,
+				Content: responses.EasyInputContentUnion{OfString: utils.Ptr(task.Message)},
+			},
+		}}
+	}
+
+	handle, err := t.agent.Execute(ctx, &agents.AgentInput{
+		Namespace:     params.Namespace + "/" + params.Name,
+		ThreadID:      task.ThreadID,
+		PreviousRunID: previousRunID,
+		Message:       history.Message{SenderID: params.AgentName, Messages: messages},
+		SessionID:     params.SessionID,
+		Budget:        params.Budget,
+	})
+	if err != nil {
+		task.Status, task.Error = FanOutTaskFailed, err.Error()
+		return nil
+	}
+
+	result, timedOut, err := t.wait(ctx, handle)
+
+	task.Interrupts = nil
+	switch {
+	case timedOut:
+		task.Status, task.Error = FanOutTaskFailed, fmt.Sprintf("timed out after %s", t.taskTimeout)
+	case err != nil:
+		task.Status, task.Error = FanOutTaskFailed, err.Error()
+	case result.Status == agentstate.RunStatusPaused:
+		task.Status, task.RunID, task.Interrupts = fanOutTaskPaused, result.RunID, result.Interrupts
+		return nil
+	case result.Status == agentstate.RunStatusCompleted:
+		task.Status, task.Output = FanOutTaskCompleted, result.Text()
+	default:
+		task.Status, task.Error = FanOutTaskFailed, fmt.Sprintf("run ended with status %s", result.Status)
+	}
+
+	if result == nil {
+		return nil
+	}
+	return result.Spend
+}
+
+// wait waits for a task's run to end. A run does not end with the context it
+// was started under, so one past the task timeout — or whose caller was
+// stopped — is stopped the way a user would stop it, and waited on while it
+// unwinds.
+func (t *FanOutTool) wait(ctx context.Context, handle *agents.AgentHandle) (*agents.AgentOutput, bool, error) {
+	waitCtx := ctx
+	if t.taskTimeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, t.taskTimeout)
+		defer cancel()
+	}
+
+	type outcome struct {
+		result *agents.AgentOutput
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := handle.Result()
+		done <- outcome{result, err}
+	}()
+
+	select {
+	case out := <-done:
+		return out.result, false, out.err
+	case <-waitCtx.Done():
+		_ = handle.Stop(context.WithoutCancel(ctx))
+		out := <-done
+		return out.result, ctx.Err() == nil, out.err
+	}
+}
+
+// response is the call's answer: the interrupts of every paused task, or once
+// none is, the aggregate of them all.
+func (t *FanOutTool) response(params *agents.ToolCall, tasks []*fanOutTaskState, spend *agentstate.BudgetSpend) (*agents.ToolCallResponse, error) {
+	var interrupts []responses.Interrupt
+	for _, task := range tasks {
+		interrupts = append(interrupts, task.Interrupts...)
+	}
+	if len(interrupts) > 0 {
+		buf, err := sonic.Marshal(tasks)
+		if err != nil {
+			return nil, err
+		}
+		return &agents.ToolCallResponse{
+			StateUpdates: map[string]string{t.getStateKey(params.ID): string(buf)},
+			Interrupts:   interrupts,
+			Spend:        spend,
+		}, nil
+	}
+
+	result := FanOutResult{Results: make([]FanOutTaskResult, 0, len(tasks))}
+	for _, task := range tasks {
+		if task.Status == FanOutTaskCompleted {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+		result.Results = append(result.Results, task.FanOutTaskResult)
+	}
+	if t.maxFailures >= 0 && result.Failed > t.maxFailures {
+		result.Error = fmt.Sprintf("%d of %d tasks failed, more than the %d allowed", result.Failed, len(tasks), t.maxFailures)
+	}
+
+	buf, err := sonic.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &agents.ToolCallResponse{
+		FunctionCallOutputMessage: &responses.FunctionCallOutputMessage{
+			ID:     params.ID,
+			CallID: params.CallID,
+			Output: responses.FunctionCallOutputContentUnion{
+				OfString: utils.Ptr(string(buf)),
+			},
+		},
+		Spend: spend,
+	}, nil
+}
+
+func (t *FanOutTool) getStateKey(toolCallId string) string {
+	return fmt.Sprintf("fan_out/%s/%s", t.agent.Name, toolCallId)
+}
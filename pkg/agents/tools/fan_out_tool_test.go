@@ -0,0 +1,308 @@
+package tools_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/agentstate"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/history"
+	"github.com/hastekit/agent-sdk-go/pkg/agents/messages"
+	agenttools "github.com/hastekit/agent-sdk-go/pkg/agents/tools"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/llmtest"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+)
+
+// progressRecorder keeps every progress update it is given.
+type progressRecorder struct {
+	mu      sync.Mutex
+	updates []agents.ToolProgress
+}
+
+func (r *progressRecorder) Report(ctx context.Context, update agents.ToolProgress) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updates = append(r.updates, update)
+}
+
+// runCounter counts the researcher's runs in flight, and holds each open a
+// little so that runs allowed to overlap do.
+type runCounter struct {
+	agents.NoopLifecycleHook
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (c *runCounter) GetName() string { return "run_counter" }
+
+func (c *runCounter) OnRunStart(ctx context.Context, event *agents.RunStartEvent) error {
+	n := c.inFlight.Add(1)
+	for {
+		peak := c.peak.Load()
+		if n <= peak || c.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	return nil
+}
+
+func (c *runCounter) OnRunEnd(ctx context.Context, event *agents.RunEndEvent) error {
+	c.inFlight.Add(-1)
+	return nil
+}
+
+// refundTool needs approval, and counts the refunds it makes.
+type refundTool struct {
+	*agents.BaseTool
+	calls atomic.Int32
+}
+
+func newRefundTool() *refundTool {
+	return &refundTool{BaseTool: &agents.BaseTool{
+		ToolUnion: responses.ToolUnion{OfFunction: &responses.FunctionTool{
+			Name:        "refund",
+			Description: utils.Ptr("refund the customer"),
+			Parameters:  map[string]any{"type": "object", "properties": map[string]any{}},
+		}},
+		RequiresApproval: true,
+	}}
+}
+
+func (t *refundTool) Execute(ctx context.Context, params *agents.ToolCall) (*agents.ToolCallResponse, error) {
+	t.calls.Add(1)
+	return &agents.ToolCallResponse{FunctionCallOutputMessage: &responses.FunctionCallOutputMessage{
+		ID:     params.ID,
+		CallID: params.CallID,
+		Output: responses.FunctionCallOutputContentUnion{OfString: utils.Ptr("refunded")},
+	}}, nil
+}
+
+func newResearcher(fake llm.Provider, tools ...agents.Tool) *agents.Agent {
+	return agents.NewAgent(&agents.AgentOptions{Name: "researcher", LLM: fake, Tools: tools})
+}
+
+func fanOutCall(args string, progress agents.ProgressReporter) *agents.ToolCall {
+	return &agents.ToolCall{
+		FunctionCallMessage: &responses.FunctionCallMessage{ID: "fc_1", CallID: "call_1", Name: "research", Arguments: args},
+		AgentName:           "orchestrator",
+		Namespace:           "test",
+		State:               map[string]string{},
+		Progress:            progress,
+	}
+}
+
+func decodeFanOut(t *testing.T, resp *agents.ToolCallResponse) agenttools.FanOutResult {
+	t.Helper()
+	require.NotNil(t, resp.FunctionCallOutputMessage)
+	var result agenttools.FanOutResult
+	require.NoError(t, sonic.Unmarshal([]byte(*resp.FunctionCallOutputMessage.Output.OfString), &result))
+	return result
+}
+
+func userMessage(text string) history.Message {
+	return messages.New("user", []responses.InputMessageUnion{{
+		OfEasyInput: &responses.EasyMessage{
+			Role:    constants.RoleUser,
+			Content: responses.EasyInputContentUnion{OfString: utils.Ptr(text)},
+		},
+	}})
+}
+
+func approvalMessage(callID string) history.Message {
+	return messages.New("user", []responses.InputMessageUnion{{
+		OfFunctionCallInterruptResolution: &responses.FunctionCallInterruptResolutionMessage{
+			Resolutions: []responses.InterruptResolution{{CallID: callID, Action: responses.InterruptActionApprove}},
+		},
+	}})
+}
+
+func run(t *testing.T, agent *agents.Agent, in *agents.AgentInput) *agents.AgentOutput {
+	t.Helper()
+	handle, err := agent.Execute(context.Background(), in)
+	require.NoError(t, err)
+	out, err := handle.Result()
+	require.NoError(t, err)
+	return out
+}
+
+// toolOutputs joins the tool outputs a request carries.
+func toolOutputs(in *responses.Request) string {
+	var outputs []string
+	for _, msg := range in.Input.OfInputMessageList {
+		if msg.OfFunctionCallOutput != nil && msg.OfFunctionCallOutput.Output.OfString != nil {
+			outputs = append(outputs, *msg.OfFunctionCallOutput.Output.OfString)
+		}
+	}
+	return strings.Join(outputs, "\n")
+}
+
+func TestFanOutTool_AggregatesResultsAndErrors(t *testing.T) {
+	fake := llmtest.ByConversation(map[string]*llmtest.Provider{
+		"acme":   llmtest.New().RespondText("acme raised prices"),
+		"globex": llmtest.New().RespondText("globex launched a new plan"),
+	})
+	tool := agenttools.NewFanOutTool("research", "research competitors", newResearcher(fake), agenttools.WithMaxFailures(0))
+	progress := &progressRecorder{}
+
+	resp, err := tool.Execute(context.Background(), fanOutCall(`{"tasks":[
+		{"id":"acme","message":"acme"},
+		{"id":"globex","message":"globex"},
+		{"id":"initech","message":"initech"}
+	]}`, progress))
+	require.NoError(t, err)
+
+	result := decodeFanOut(t, resp)
+	assert.Equal(t, 2, result.Succeeded)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, "1 of 3 tasks failed, more than the 0 allowed", result.Error)
+
+	// In the order the tasks were given, however they finished.
+	require.Len(t, result.Results, 3)
+	assert.Equal(t, agenttools.FanOutTaskResult{ID: "acme", Status: agenttools.FanOutTaskCompleted, Output: "acme raised prices"}, result.Results[0])
+	assert.Equal(t, "globex launched a new plan", result.Results[1].Output)
+	assert.Equal(t, "initech", result.Results[2].ID)
+	assert.Equal(t, agenttools.FanOutTaskFailed, result.Results[2].Status)
+	assert.Contains(t, result.Results[2].Error, "no scripted turn left")
+
+	// Each task reports its start and its end; the last update counts them
+	// all done.
+	require.Len(t, progress.updates, 6)
+	last := progress.updates[len(progress.updates)-1]
+	assert.Equal(t, agents.ToolProgress{Progress: 3, Total: 3, Message: last.Message}, last)
+	var updates []string
+	for _, update := range progress.updates {
+		updates = append(updates, update.Message)
+	}
+	assert.Contains(t, updates, "acme: completed")
+	assert.Contains(t, updates, "initech: started")
+}
+
+func TestFanOutTool_ConcurrencyCap(t *testing.T) {
+	scripts := map[string]*llmtest.Provider{}
+	var tasks []string
+	for i := range 6 {
+		id := fmt.Sprintf("t%d", i)
+		scripts[id] = llmtest.New().RespondText("done " + id)
+		tasks = append(tasks, fmt.Sprintf(`{"id":%q,"message":%q}`, id, id))
+	}
+	counter := &runCounter{}
+	researcher := agents.NewAgent(&agents.AgentOptions{
+		Name:           "researcher",
+		LLM:            llmtest.ByConversation(scripts),
+		LifecycleHooks: []agents.LifecycleHook{counter},
+	})
+	tool := agenttools.NewFanOutTool("research", "research", researcher, agenttools.WithConcurrency(2))
+
+	resp, err := tool.Execute(context.Background(), fanOutCall(`{"tasks":[`+strings.Join(tasks, ",")+`]}`, nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, 6, decodeFanOut(t, resp).Succeeded)
+	assert.Equal(t, int32(2), counter.peak.Load())
+}
+
+func TestFanOutTool_TaskTimeout(t *testing.T) {
+	fake := llmtest.ByConversation(map[string]*llmtest.Provider{
+		"fast": llmtest.New().RespondText("fast answer"),
+		"slow": llmtest.New().Hang(),
+	})
+	tool := agenttools.NewFanOutTool("research", "research", newResearcher(fake), agenttools.WithTaskTimeout(50*time.Millisecond))
+
+	resp, err := tool.Execute(context.Background(), fanOutCall(`{"tasks":[{"id":"fast","message":"fast"},{"id":"slow","message":"slow"}]}`, nil))
+	require.NoError(t, err)
+
+	result := decodeFanOut(t, resp)
+	assert.Equal(t, 1, result.Succeeded)
+	assert.Equal(t, agenttools.FanOutTaskFailed, result.Results[1].Status)
+	assert.Equal(t, "timed out after 50ms", result.Results[1].Error)
+}
+
+func TestFanOutTool_RejectsDuplicateTaskIDs(t *testing.T) {
+	tool := agenttools.NewFanOutTool("research", "research", newResearcher(llmtest.New()))
+
+	_, err := tool.Execute(context.Background(), fanOutCall(`{"tasks":[{"id":"a","message":"x"},{"id":"a","message":"y"}]}`, nil))
+	assert.EqualError(t, err, `fan_out: task id "a" is used twice`)
+}
+
+// The approvals of every task surface in one pause. Resolving some of them
+// runs only those tasks on; the call answers once the last is resolved.
+func TestFanOutTool_ApprovalsBubbleUpInABatch(t *testing.T) {
+	refund := newRefundTool()
+	acme := llmtest.New().Respond(llmtest.ToolCallWithID("call_refund_acme", "refund", "{}")).RespondText("acme refunded")
+	globex := llmtest.New().Respond(llmtest.ToolCallWithID("call_refund_globex", "refund", "{}")).RespondText("globex refunded")
+	initech := llmtest.New().RespondText("initech needs nothing")
+	researcher := newResearcher(llmtest.ByConversation(map[string]*llmtest.Provider{
+		"acme": acme, "globex": globex, "initech": initech,
+	}), refund)
+
+	fake := llmtest.New().
+		CallTool("research", `{"tasks":[{"id":"acme","message":"acme"},{"id":"globex","message":"globex"},{"id":"initech","message":"initech"}]}`).
+		RespondText("all three handled")
+	orchestrator := agents.NewAgent(&agents.AgentOptions{
+		Name:  "orchestrator",
+		LLM:   fake,
+		Tools: []agents.Tool{agenttools.NewFanOutTool("research", "research", researcher)},
+	})
+
+	out := run(t, orchestrator, &agents.AgentInput{Namespace: "test", ThreadID: "t1", Message: userMessage("refund everyone")})
+	require.Equal(t, agentstate.RunStatusPaused, out.Status)
+	var pending []string
+	for _, intr := range out.Interrupts {
+		pending = append(pending, intr.FunctionCallMessage.CallID)
+		assert.True(t, intr.IsNested)
+	}
+	assert.ElementsMatch(t, []string{"call_refund_acme", "call_refund_globex"}, pending)
+
+	out = run(t, orchestrator, &agents.AgentInput{
+		Namespace: "test", ThreadID: "t1", PreviousRunID: out.RunID,
+		Message: approvalMessage("call_refund_acme"),
+	})
+	require.Equal(t, agentstate.RunStatusPaused, out.Status)
+	require.Len(t, out.Interrupts, 1)
+	assert.Equal(t, "call_refund_globex", out.Interrupts[0].FunctionCallMessage.CallID)
+	assert.Equal(t, int32(1), refund.calls.Load())
+
+	out = run(t, orchestrator, &agents.AgentInput{
+		Namespace: "test", ThreadID: "t1", PreviousRunID: out.RunID,
+		Message: approvalMessage("call_refund_globex"),
+	})
+	require.Equal(t, agentstate.RunStatusCompleted, out.Status)
+	assert.Equal(t, "all three handled", out.Text())
+	assert.Equal(t, int32(2), refund.calls.Load())
+
+	// Two turns for each refunding task, one for the other: none ran again.
+	assert.Equal(t, 2, acme.Calls())
+	assert.Equal(t, 2, globex.Calls())
+	assert.Equal(t, 1, initech.Calls())
+
+	output := toolOutputs(fake.Request(1))
+	assert.Contains(t, output, `"succeeded":3`)
+	assert.Contains(t, output, "acme refunded")
+	assert.Contains(t, output, "initech needs nothing")
+}
+
+func TestFanOutTool_ResumeWithoutSavedTasks(t *testing.T) {
+	tool := agenttools.NewFanOutTool("research", "research", newResearcher(llmtest.New()))
+	call := fanOutCall("", nil)
+	call.ShouldResume = true
+	call.ResumeMessages = []responses.InputMessageUnion{{
+		OfFunctionCallInterruptResolution: &responses.FunctionCallInterruptResolutionMessage{
+			Resolutions: []responses.InterruptResolution{{CallID: "x", Action: responses.InterruptActionApprove}},
+		},
+	}}
+
+	_, err := tool.Execute(context.Background(), call)
+	assert.ErrorContains(t, err, "saved tasks missing")
+}
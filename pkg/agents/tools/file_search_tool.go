@@ -0,0 +1,29 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+)
+
+// FileSearchTool lets the model search the files of OpenAI vector stores.
+type FileSearchTool struct {
+	*agents.BaseTool
+	vectorStoreIDs []string
+}
+
+func NewFileSearchTool(vectorStoreIDs ...string) *FileSearchTool {
+	return &FileSearchTool{
+		BaseTool:       &agents.BaseTool{},
+		vectorStoreIDs: vectorStoreIDs,
+	}
+}
+
+func (t *FileSearchTool) Execute(ctx context.Context, params *agents.ToolCall) (*agents.ToolCallResponse, error) {
+	return nil, nil
+}
+
+func (t *FileSearchTool) Tool(ctx context.Context) *responses.ToolUnion {
+	return &responses.ToolUnion{OfFileSearch: &responses.FileSearchTool{VectorStoreIDs: t.vectorStoreIDs}}
+}
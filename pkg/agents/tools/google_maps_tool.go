@@ -0,0 +1,27 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+)
+
+// GoogleMapsTool grounds the model's answers in Google Maps places (Gemini).
+type GoogleMapsTool struct {
+	*agents.BaseTool
+}
+
+func NewGoogleMapsTool() *GoogleMapsTool {
+	return &GoogleMapsTool{
+		BaseTool: &agents.BaseTool{},
+	}
+}
+
+func (t *GoogleMapsTool) Execute(ctx context.Context, params *agents.ToolCall) (*agents.ToolCallResponse, error) {
+	return nil, nil
+}
+
+func (t *GoogleMapsTool) Tool(ctx context.Context) *responses.ToolUnion {
+	return &responses.ToolUnion{OfGoogleMaps: &responses.GoogleMapsTool{}}
+}
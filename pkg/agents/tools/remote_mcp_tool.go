@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+)
+
+// RemoteMCPTool hands a remote MCP server to the provider, which lists and
+// calls its tools itself. Use mcpclient instead to have the agent call the
+// server.
+type RemoteMCPTool struct {
+	*agents.BaseTool
+	tool responses.MCPTool
+}
+
+type RemoteMCPToolOption func(*responses.MCPTool)
+
+// WithRemoteMCPHeaders sets the headers sent to the server, e.g. for
+// authorization.
+func WithRemoteMCPHeaders(headers map[string]string) RemoteMCPToolOption {
+	return func(t *responses.MCPTool) {
+		t.Headers = headers
+	}
+}
+
+// WithRemoteMCPAllowedTools limits the model to the named tools of the server.
+func WithRemoteMCPAllowedTools(names ...string) RemoteMCPToolOption {
+	return func(t *responses.MCPTool) {
+		t.AllowedTools = names
+	}
+}
+
+func NewRemoteMCPTool(serverLabel, serverURL string, opts ...RemoteMCPToolOption) *RemoteMCPTool {
+	t := &RemoteMCPTool{
+		BaseTool: &agents.BaseTool{},
+		tool: responses.MCPTool{
+			ServerLabel: serverLabel,
+			ServerURL:   serverURL,
+			// The provider would otherwise stop for an approval the agent
+			// has no way to give.
+			RequireApproval: "never",
+		},
+	}
+
+	for _, opt := range opts {
+		opt(&t.tool)
+	}
+
+	return t
+}
+
+func (t *RemoteMCPTool) Execute(ctx context.Context, params *agents.ToolCall) (*agents.ToolCallResponse, error) {
+	return nil, nil
+}
+
+func (t *RemoteMCPTool) Tool(ctx context.Context) *responses.ToolUnion {
+	tool := t.tool
+	return &responses.ToolUnion{OfMCP: &tool}
+}
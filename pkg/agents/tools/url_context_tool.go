@@ -0,0 +1,27 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+)
+
+// URLContextTool lets the model read the URLs in the conversation (Gemini).
+type URLContextTool struct {
+	*agents.BaseTool
+}
+
+func NewURLContextTool() *URLContextTool {
+	return &URLContextTool{
+		BaseTool: &agents.BaseTool{},
+	}
+}
+
+func (t *URLContextTool) Execute(ctx context.Context, params *agents.ToolCall) (*agents.ToolCallResponse, error) {
+	return nil, nil
+}
+
+func (t *URLContextTool) Tool(ctx context.Context) *responses.ToolUnion {
+	return &responses.ToolUnion{OfURLContext: &responses.URLContextTool{}}
+}
@@ -0,0 +1,172 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bytedance/sonic"
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/video_generation"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+)
+
+// VideoGenerator is the part of llm.Provider that VideoGenerationTool needs.
+// An LLMClient model (client.Model("OpenAI/sora-2")) satisfies it.
+type VideoGenerator interface {
+	NewVideoGeneration(ctx context.Context, in *video_generation.Request) (*video_generation.Response, error)
+	GetVideoGeneration(ctx context.Context, in *video_generation.GetRequest) (*video_generation.Response, error)
+	DownloadVideoGeneration(ctx context.Context, in *video_generation.DownloadRequest) (*video_generation.Content, error)
+}
+
+// VideoStore keeps a finished video and returns a reference to it (a URL,
+// a file path) for the agent to pass on.
+type VideoStore func(ctx context.Context, videoID string, video *video_generation.Content) (string, error)
+
+// VideoGenerationTool lets an agent generate videos. Generation takes
+// minutes, so a call with a prompt only starts a job and returns its
+// video_id; the agent checks on it by calling the tool again with that id.
+type VideoGenerationTool struct {
+	*agents.BaseTool
+	generator VideoGenerator
+	model     string
+	store     VideoStore
+}
+
+type videoGenerationArgument struct {
+	Prompt          string  `json:"prompt"`
+	AspectRatio     *string `json:"aspect_ratio"`
+	DurationSeconds *int    `json:"duration_seconds"`
+	VideoID         string  `json:"video_id"`
+}
+
+type videoGenerationResult struct {
+	VideoID  string                  `json:"video_id"`
+	Status   video_generation.Status `json:"status"`
+	Progress *int                    `json:"progress,omitempty"`
+	Error    string                  `json:"error,omitempty"`
+	Video    string                  `json:"video,omitempty"`
+}
+
+type VideoGenerationToolOption func(*VideoGenerationTool)
+
+// WithVideoStore downloads each video once it completes and hands it to
+// store. Without a store the tool only reports that the video is ready, and
+// the application downloads it by id.
+func WithVideoStore(store VideoStore) VideoGenerationToolOption {
+	return func(t *VideoGenerationTool) {
+		t.store = store
+	}
+}
+
+func NewVideoGenerationTool(generator VideoGenerator, model string, opts ...VideoGenerationToolOption) *VideoGenerationTool {
+	t := &VideoGenerationTool{
+		BaseTool: &agents.BaseTool{
+			ToolUnion: responses.ToolUnion{
+				OfFunction: &responses.FunctionTool{
+					Name: "generate_video",
+					Description: utils.Ptr("Generate a video from a text prompt. Generation takes a few minutes: " +
+						"a call with a prompt starts it and returns a video_id. Call again with only the video_id " +
+						"to check on it until its status is completed or failed."),
+					Parameters: map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"prompt": map[string]any{
+								"type":        "string",
+								"description": "Description of the video to generate. Omit when checking on a video.",
+							},
+							"aspect_ratio": map[string]any{
+								"type":        "string",
+								"enum":        []string{"16:9", "9:16"},
+								"description": "Aspect ratio of the video",
+							},
+							"duration_seconds": map[string]any{
+								"type":        "integer",
+								"description": "Length of the video in seconds",
+							},
+							"video_id": map[string]any{
+								"type":        "string",
+								"description": "ID of a video already started, to check on it",
+							},
+						},
+						"additionalProperties": false,
+					},
+				},
+			},
+		},
+		generator: generator,
+		model:     model,
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+func (t *VideoGenerationTool) Execute(ctx context.Context, params *agents.ToolCall) (*agents.ToolCallResponse, error) {
+	var in videoGenerationArgument
+	if err := sonic.Unmarshal([]byte(params.Arguments), &in); err != nil {
+		return nil, err
+	}
+
+	var (
+		resp *video_generation.Response
+		err  error
+	)
+	switch {
+	case in.VideoID != "":
+		resp, err = t.generator.GetVideoGeneration(ctx, &video_generation.GetRequest{Model: t.model, ID: in.VideoID})
+	case in.Prompt != "":
+		resp, err = t.generator.NewVideoGeneration(ctx, &video_generation.Request{
+			Model:           t.model,
+			Prompt:          in.Prompt,
+			AspectRatio:     in.AspectRatio,
+			DurationSeconds: in.DurationSeconds,
+		})
+	default:
+		return nil, errors.New("either prompt or video_id is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("model %s does not support video generation", t.model)
+	}
+
+	result := videoGenerationResult{
+		VideoID:  resp.ID,
+		Status:   resp.Status,
+		Progress: resp.Progress,
+	}
+	if resp.Error != nil {
+		result.Error = resp.Error.Message
+	}
+
+	if resp.Status == video_generation.StatusCompleted && t.store != nil {
+		content, err := t.generator.DownloadVideoGeneration(ctx, &video_generation.DownloadRequest{Model: t.model, ID: resp.ID})
+		if err != nil {
+			return nil, err
+		}
+		if result.Video, err = t.store(ctx, resp.ID, content); err != nil {
+			return nil, err
+		}
+	}
+
+	buf, err := sonic.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &agents.ToolCallResponse{
+		FunctionCallOutputMessage: &responses.FunctionCallOutputMessage{
+			ID:     params.ID,
+			CallID: params.CallID,
+			Output: responses.FunctionCallOutputContentUnion{
+				OfString: utils.Ptr(string(buf)),
+			},
+		},
+	}, nil
+}
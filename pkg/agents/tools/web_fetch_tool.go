@@ -0,0 +1,27 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+)
+
+// WebFetchTool lets the model fetch the content of URLs (Anthropic).
+type WebFetchTool struct {
+	*agents.BaseTool
+}
+
+func NewWebFetchTool() *WebFetchTool {
+	return &WebFetchTool{
+		BaseTool: &agents.BaseTool{},
+	}
+}
+
+func (t *WebFetchTool) Execute(ctx context.Context, params *agents.ToolCall) (*agents.ToolCallResponse, error) {
+	return nil, nil
+}
+
+func (t *WebFetchTool) Tool(ctx context.Context) *responses.ToolUnion {
+	return &responses.ToolUnion{OfWebFetch: &responses.WebFetchTool{}}
+}
@@ -28,7 +28,11 @@
 // pkg/agui/web subpackage.
 package agui
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+)
 
 // EventType is the discriminator string AG-UI clients switch on.
 // Values match the upstream protocol's SCREAMING_SNAKE_CASE
@@ -384,8 +388,8 @@ func (e *StateSnapshotEvent) Marshal() ([]byte, error) {
 }
 
 // StateDeltaEvent applies an RFC 6902 JSON Patch to the client's
-// current state. The delta is opaque to us — the wire shape is ready
-// even though nothing in the SDK publishes state deltas today.
+// current state. The translator sends one for each change to a run's
+// shared state after the first, which goes as a snapshot.
 type StateDeltaEvent struct {
 	BaseEvent
 	Delta []map[string]any `json:"delta"`
@@ -425,6 +429,10 @@ type Message struct {
 	// role="reasoning" message, per the AG-UI reasoning message shape.
 	// Empty (and omitted) on every other role.
 	EncryptedValue string `json:"encryptedValue,omitempty"`
+	// Annotations are the citations on an assistant message's text, in the
+	// same shape the live stream sends them in hastekit.annotation events.
+	// Not part of the AG-UI message schema; strict clients ignore it.
+	Annotations []responses.Annotation `json:"annotations,omitempty"`
 }
 
 // ToolCall is the function-call shape embedded inside an assistant
@@ -491,4 +499,7 @@ const (
 	CustomNameAnnotation    = "hastekit.annotation"
 	CustomNameStreamID      = "hastekit.stream_id"
 	CustomNameToolProgress  = "hastekit.tool_progress"
+
+	CustomNameGuardrailTripped = "hastekit.guardrail_tripped"
+	CustomNameReflection       = "hastekit.reflection"
 )
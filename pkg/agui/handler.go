@@ -372,7 +372,7 @@ func serveStream(w http.ResponseWriter, r *http.Request, agent *agents.Agent, th
 					return
 				}
 			}
-			if chunk.OfRunCompleted != nil || chunk.OfRunPaused != nil {
+			if chunk.OfRunCompleted != nil || chunk.OfRunPaused != nil || chunk.OfRunGuardrailTripped != nil {
 				return
 			}
 		}
@@ -391,6 +391,8 @@ func runIDOf(chunk *responses.ResponseChunk) string {
 		return chunk.OfRunCompleted.RunState.Id
 	case chunk.OfRunPaused != nil:
 		return chunk.OfRunPaused.RunState.Id
+	case chunk.OfRunGuardrailTripped != nil:
+		return chunk.OfRunGuardrailTripped.RunState.Id
 	}
 	return ""
 }
@@ -540,8 +542,9 @@ func serveRun(w http.ResponseWriter, r *http.Request, agent *agents.Agent, o opt
 				}
 			}
 			// Run terminated — close the connection. The translator
-			// has already emitted RUN_FINISHED (completed or paused).
-			if chunk.OfRunCompleted != nil || chunk.OfRunPaused != nil {
+			// has already emitted RUN_FINISHED (completed, paused or
+			// guardrail_tripped).
+			if chunk.OfRunCompleted != nil || chunk.OfRunPaused != nil || chunk.OfRunGuardrailTripped != nil {
 				return
 			}
 		}
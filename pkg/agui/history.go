@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/hastekit/agent-sdk-go/pkg/agents/history"
@@ -69,9 +70,10 @@ func HistoryToMessages(rows []history.ConversationMessage) []Message {
 						continue
 					}
 					out = append(out, Message{
-						ID:      uniq(m.ID),
-						Role:    roleOrUser(string(m.Role)),
-						Content: text,
+						ID:          uniq(m.ID),
+						Role:        roleOrUser(string(m.Role)),
+						Content:     text,
+						Annotations: inputContentAnnotations(m.Content.OfInputMessageList),
 					})
 
 				case msg.OfInputMessage != nil:
@@ -93,9 +95,10 @@ func HistoryToMessages(rows []history.ConversationMessage) []Message {
 						continue
 					}
 					out = append(out, Message{
-						ID:      uniq(m.ID),
-						Role:    RoleAssistant,
-						Content: text,
+						ID:          uniq(m.ID),
+						Role:        RoleAssistant,
+						Content:     text,
+						Annotations: outputContentAnnotations(m.Content),
 					})
 
 				case msg.OfFunctionCall != nil:
@@ -220,6 +223,55 @@ func outputContentText(content *responses.OutputContent) string {
 	return strings.Join(parts, "\n")
 }
 
+// outputContentAnnotations collects the annotations of every text part,
+// shifting their character ranges to where the part lands in the text
+// outputContentText joins.
+func outputContentAnnotations(content *responses.OutputContent) []responses.Annotation {
+	if content == nil {
+		return nil
+	}
+	var out []responses.Annotation
+	offset := 0
+	for _, c := range *content {
+		if c.OfOutputText != nil && c.OfOutputText.Text != "" {
+			out = appendShiftedAnnotations(out, c.OfOutputText.Annotations, offset)
+			offset += utf8.RuneCountInString(c.OfOutputText.Text) + 1 // the joining newline
+		}
+	}
+	return out
+}
+
+// inputContentAnnotations is outputContentAnnotations for the text
+// inputContentText joins, where an assistant turn lands after file
+// persistence.
+func inputContentAnnotations(content responses.InputContent) []responses.Annotation {
+	var out []responses.Annotation
+	offset := 0
+	for _, c := range content {
+		switch {
+		case c.OfInputText != nil && c.OfInputText.Text != "":
+			offset += utf8.RuneCountInString(c.OfInputText.Text) + 1
+		case c.OfOutputText != nil && c.OfOutputText.Text != "":
+			out = appendShiftedAnnotations(out, c.OfOutputText.Annotations, offset)
+			offset += utf8.RuneCountInString(c.OfOutputText.Text) + 1
+		}
+	}
+	return out
+}
+
+func appendShiftedAnnotations(out, annotations []responses.Annotation, offset int) []responses.Annotation {
+	for _, a := range annotations {
+		a.StartIndex += offset
+		a.EndIndex += offset
+		if a.Index != nil {
+			index := *a.Index + offset
+			a.Index = &index
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
 // reasoningSummaryText joins a reasoning item's summary parts into the
 // single string a role="reasoning" message carries. Mirrors how the live
 // stream concatenates summary deltas into one reasoning block.
@@ -108,6 +108,33 @@ func TestHistoryToMessagesAssistantViaEasyInputOutputText(t *testing.T) {
 	assert.Equal(t, "the answer is 42", out[0].Content)
 }
 
+// Citations ride along on the assistant message, with their ranges moved
+// to where each text part lands in the joined content.
+func TestHistoryToMessagesAnnotations(t *testing.T) {
+	cite := responses.Annotation{Type: "url_citation", URL: "https://example.com", Title: "Example", StartIndex: 0, EndIndex: 5}
+	rows := []history.ConversationMessage{{
+		Messages: []history.Message{
+			messages.New("agent", []responses.InputMessageUnion{
+				{OfOutputMessage: &responses.OutputMessage{
+					ID: "msg_1", Role: constants.RoleAssistant,
+					Content: &responses.OutputContent{
+						{OfOutputText: &responses.OutputTextContent{Text: "first"}},
+						{OfOutputText: &responses.OutputTextContent{Text: "héllo", Annotations: []responses.Annotation{cite}}},
+					},
+				}},
+			}),
+		},
+	}}
+
+	out := HistoryToMessages(rows)
+	require.Len(t, out, 1)
+	require.Len(t, out[0].Annotations, 1)
+	assert.Equal(t, "https://example.com", out[0].Annotations[0].URL)
+	assert.Equal(t, 6, out[0].Annotations[0].StartIndex)
+	assert.Equal(t, 11, out[0].Annotations[0].EndIndex)
+	assert.Equal(t, "héllo", string([]rune(out[0].Content)[6:11]))
+}
+
 // The SDK can store a function_call and its function_call_output under
 // the same id. Emitting both verbatim collides in CopilotKit's
 // id-keyed message list and drops the tool render; ids must be unique.
@@ -346,7 +346,7 @@ func (in *RunAgentInput) toSDKMessages(msgs []Message) []responses.InputMessageU
 						Content: &responses.OutputContent{
 							{OfOutputText: &responses.OutputTextContent{
 								Text:        m.Content,
-								Annotations: []responses.Annotation{},
+								Annotations: nonNilAnnotations(m.Annotations),
 							}},
 						},
 					},
@@ -406,3 +406,12 @@ func ensureFunctionCallID(id string) string {
 }
 
 func ptr[T any](v T) *T { return &v }
+
+// nonNilAnnotations keeps the annotations array present on the wire, as
+// the Responses API expects it on every output_text part.
+func nonNilAnnotations(in []responses.Annotation) []responses.Annotation {
+	if in == nil {
+		return []responses.Annotation{}
+	}
+	return in
+}
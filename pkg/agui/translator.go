@@ -2,6 +2,10 @@ package agui
 
 import (
 	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
@@ -66,6 +70,10 @@ type Translator struct {
 	// run.created chunks (shouldn't happen but defensive) don't
 	// re-emit.
 	runStarted bool
+
+	// state is the shared state last sent to the client, which the next
+	// update is diffed against. Nil until the run sends one.
+	state map[string]any
 }
 
 // NewTranslator returns a fresh translator for one run.
@@ -198,7 +206,9 @@ func (t *Translator) Translate(chunk *responses.ResponseChunk) []Event {
 		out = append(out,
 			&StateSnapshotEvent{
 				BaseEvent: baseNow(),
-				Snapshot: map[string]any{
+				// A snapshot replaces the client's state, so the run's
+				// shared state — a plan — goes in alongside the pause.
+				Snapshot: t.withState(map[string]any{
 					"status": "paused",
 					// awaitingApproval reports whether an approve/reject
 					// decision is outstanding, not merely that the run is
@@ -214,7 +224,7 @@ func (t *Translator) Translate(chunk *responses.ResponseChunk) []Event {
 					"interrupts": projected,
 					"threadId":   t.threadID,
 					"runId":      t.runID,
-				},
+				}),
 			},
 			&CustomEvent{
 				BaseEvent: baseNow(),
@@ -268,6 +278,12 @@ func (t *Translator) Translate(chunk *responses.ResponseChunk) []Event {
 		return out
 
 	case chunk.OfRunCompleted != nil:
+		// A run cut short by its budget still completes, with an answer;
+		// its status says so.
+		status := chunk.OfRunCompleted.RunState.Status
+		if status == "" {
+			status = "completed"
+		}
 		out := t.closeOpenItems()
 		out = append(out,
 			&RunFinishedEvent{
@@ -275,12 +291,38 @@ func (t *Translator) Translate(chunk *responses.ResponseChunk) []Event {
 				ThreadID:  t.threadID,
 				RunID:     t.runID,
 				Result: map[string]any{
-					"status": "completed",
+					"status": status,
 					"usage":  chunk.OfRunCompleted.RunState.Usage,
 				},
 			},
 		)
 		return out
+
+	case chunk.OfRunGuardrailTripped != nil:
+		// A guardrail blocked the run. It ended as the agent meant it to,
+		// so it finishes rather than errors; the CUSTOM event ahead of
+		// RUN_FINISHED carries which guardrail and why, for clients that
+		// explain the refusal.
+		run := chunk.OfRunGuardrailTripped.RunState
+		out := t.closeOpenItems()
+		out = append(out,
+			&CustomEvent{
+				BaseEvent: baseNow(),
+				Name:      CustomNameGuardrailTripped,
+				Value:     run.Guardrail,
+			},
+			&RunFinishedEvent{
+				BaseEvent: baseNow(),
+				ThreadID:  t.threadID,
+				RunID:     t.runID,
+				Result: map[string]any{
+					"status":    "guardrail_tripped",
+					"guardrail": run.Guardrail,
+					"usage":     run.Usage,
+				},
+			},
+		)
+		return out
 	}
 
 	// ── Response lifecycle ───────────────────────────────────────
@@ -402,6 +444,42 @@ func (t *Translator) Translate(chunk *responses.ResponseChunk) []Event {
 		}}
 	}
 
+	// ── Reflection ───────────────────────────────────────────────
+	// A critic's verdict on the draft that just streamed. The revision it
+	// asks for streams after it as a message of its own, so a client can
+	// show the drafts in turn.
+	if chunk.OfReflectionCritique != nil {
+		c := chunk.OfReflectionCritique
+		return []Event{&CustomEvent{
+			BaseEvent: baseNow(),
+			Name:      CustomNameReflection,
+			Value: map[string]any{
+				"critic":   c.Critic,
+				"revision": c.Revision,
+				"approved": c.Approved,
+				"feedback": c.Feedback,
+			},
+		}}
+	}
+
+	// ── Shared state ─────────────────────────────────────────────
+	// The first state a run sends is a STATE_SNAPSHOT; each after it is a
+	// STATE_DELTA against the one before, so a client rendering a long plan
+	// is sent the step that changed rather than the plan again.
+	if chunk.OfStateUpdated != nil {
+		next := chunk.OfStateUpdated.State
+		prev := t.state
+		t.state = next
+		if prev == nil {
+			return []Event{&StateSnapshotEvent{BaseEvent: baseNow(), Snapshot: next}}
+		}
+		delta := jsonPatch(nil, "", prev, next)
+		if len(delta) == 0 {
+			return nil
+		}
+		return []Event{&StateDeltaEvent{BaseEvent: baseNow(), Delta: delta}}
+	}
+
 	// ── Function call output (the tool's result) ─────────────────
 	if chunk.OfFunctionCallOutput != nil {
 		fco := chunk.OfFunctionCallOutput
@@ -722,6 +800,81 @@ func (t *Translator) closeOpenItems() []Event {
 	return out
 }
 
+// withState adds the run's shared state to a snapshot the translator builds
+// itself. The snapshot's own keys win.
+func (t *Translator) withState(snapshot map[string]any) map[string]any {
+	for k, v := range t.state {
+		if _, ok := snapshot[k]; !ok {
+			snapshot[k] = v
+		}
+	}
+	return snapshot
+}
+
+// jsonPatch appends to ops the RFC 6902 operations that turn prev into next,
+// both decoded JSON. Objects are compared key by key and arrays index by
+// index, so one changed field is one replace; anything else that differs is
+// replaced whole.
+func jsonPatch(ops []map[string]any, path string, prev, next any) []map[string]any {
+	switch p := prev.(type) {
+	case map[string]any:
+		n, ok := next.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(p)+len(n))
+		for k := range p {
+			keys = append(keys, k)
+		}
+		for k := range n {
+			if _, ok := p[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := path + "/" + jsonPointerEscaper.Replace(k)
+			pv, inPrev := p[k]
+			nv, inNext := n[k]
+			switch {
+			case !inNext:
+				ops = append(ops, map[string]any{"op": "remove", "path": child})
+			case !inPrev:
+				ops = append(ops, map[string]any{"op": "add", "path": child, "value": nv})
+			default:
+				ops = jsonPatch(ops, child, pv, nv)
+			}
+		}
+		return ops
+
+	case []any:
+		n, ok := next.([]any)
+		if !ok {
+			break
+		}
+		common := min(len(p), len(n))
+		for i := 0; i < common; i++ {
+			ops = jsonPatch(ops, path+"/"+strconv.Itoa(i), p[i], n[i])
+		}
+		for i := common; i < len(n); i++ {
+			ops = append(ops, map[string]any{"op": "add", "path": path + "/-", "value": n[i]})
+		}
+		// From the end, so each index is still there when it is removed.
+		for i := len(p) - 1; i >= common; i-- {
+			ops = append(ops, map[string]any{"op": "remove", "path": path + "/" + strconv.Itoa(i)})
+		}
+		return ops
+	}
+
+	if reflect.DeepEqual(prev, next) {
+		return ops
+	}
+	return append(ops, map[string]any{"op": "replace", "path": path, "value": next})
+}
+
+// jsonPointerEscaper escapes a key for a JSON Pointer (RFC 6901).
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
 // baseNow stamps the current millisecond Unix timestamp onto a fresh
 // BaseEvent. Centralised so we don't drift between events.
 func baseNow() BaseEvent {
@@ -224,6 +224,61 @@ func TestRunCompletedClosesOpenItems(t *testing.T) {
 	assert.Equal(t, EventRunFinished, types[len(types)-1])
 }
 
+func TestRunGuardrailTrippedFinishesWithTheTrip(t *testing.T) {
+	tr := NewTranslator("thread-1", "run-1")
+	tr.Start()
+	tr.Translate(messageAdded("msg_1"))
+
+	trip := &responses.GuardrailTrip{Name: "off_topic", Stage: "input", Reason: "not a support question"}
+	events := tr.Translate(&responses.ResponseChunk{
+		OfRunGuardrailTripped: &responses.ChunkRun[constants.ChunkTypeRunGuardrailTripped]{
+			RunState: responses.ChunkRunData{Id: "run-1", Status: "guardrail_tripped", Guardrail: trip},
+		},
+	})
+	types := eventTypes(events)
+	require.Equal(t, []EventType{EventTextMessageEnd, EventCustom, EventRunFinished}, types)
+
+	custom := events[1].(*CustomEvent)
+	assert.Equal(t, CustomNameGuardrailTripped, custom.Name)
+	assert.Equal(t, trip, custom.Value)
+
+	finished := events[2].(*RunFinishedEvent)
+	assert.Equal(t, "guardrail_tripped", finished.Result.(map[string]any)["status"])
+}
+
+func TestReflectionCritiqueIsACustomEvent(t *testing.T) {
+	tr := NewTranslator("thread-1", "run-1")
+	tr.Start()
+
+	events := tr.Translate(&responses.ResponseChunk{
+		OfReflectionCritique: &responses.ChunkCritique[constants.ChunkTypeReflectionCritique]{
+			Critic: "editor", Revision: 0, Feedback: "cite a source",
+		},
+	})
+	require.Equal(t, []EventType{EventCustom}, eventTypes(events))
+
+	custom := events[0].(*CustomEvent)
+	assert.Equal(t, CustomNameReflection, custom.Name)
+	assert.Equal(t, map[string]any{"critic": "editor", "revision": 0, "approved": false, "feedback": "cite a source"}, custom.Value)
+}
+
+func TestRunCompletedCarriesTheRunsStatus(t *testing.T) {
+	tr := NewTranslator("thread-1", "run-1")
+	tr.Start()
+
+	finished := tr.Translate(runCompleted())[0].(*RunFinishedEvent)
+	assert.Equal(t, "completed", finished.Result.(map[string]any)["status"])
+
+	tr = NewTranslator("thread-1", "run-2")
+	tr.Start()
+	finished = tr.Translate(&responses.ResponseChunk{
+		OfRunCompleted: &responses.ChunkRun[constants.ChunkTypeRunCompleted]{
+			RunState: responses.ChunkRunData{Id: "run-2", Status: "budget_exceeded"},
+		},
+	})[0].(*RunFinishedEvent)
+	assert.Equal(t, "budget_exceeded", finished.Result.(map[string]any)["status"])
+}
+
 func TestRunPausedEmitsInterruptThenFinished(t *testing.T) {
 	tr := NewTranslator("thread-1", "run-1")
 	tr.Start()
@@ -421,3 +476,56 @@ func TestUnsetModeProjectsAsApproval(t *testing.T) {
 	assert.Equal(t, "tool_approval", value["kind"])
 	assert.Equal(t, "approval", value["interrupts"].([]map[string]any)[0]["mode"])
 }
+
+func stateUpdated(state map[string]any) *responses.ResponseChunk {
+	return &responses.ResponseChunk{
+		OfStateUpdated: &responses.ChunkState[constants.ChunkTypeStateUpdated]{State: state},
+	}
+}
+
+func planState(statuses ...string) map[string]any {
+	steps := make([]any, 0, len(statuses))
+	for i, status := range statuses {
+		steps = append(steps, map[string]any{"title": "step " + string(rune('a'+i)), "status": status})
+	}
+	return map[string]any{"plan": map[string]any{"goal": "ship it", "steps": steps}}
+}
+
+func TestStateUpdated_SnapshotThenDeltas(t *testing.T) {
+	tr := NewTranslator("thread-1", "run-1")
+	tr.Start()
+
+	events := tr.Translate(stateUpdated(planState("pending", "pending")))
+	require.Equal(t, []EventType{EventStateSnapshot}, eventTypes(events))
+	assert.Equal(t, planState("pending", "pending"), events[0].(*StateSnapshotEvent).Snapshot)
+
+	// One step moved on and one was added: just those go.
+	events = tr.Translate(stateUpdated(planState("completed", "pending", "pending")))
+	require.Equal(t, []EventType{EventStateDelta}, eventTypes(events))
+	assert.Equal(t, []map[string]any{
+		{"op": "replace", "path": "/plan/steps/0/status", "value": "completed"},
+		{"op": "add", "path": "/plan/steps/-", "value": map[string]any{"title": "step c", "status": "pending"}},
+	}, events[0].(*StateDeltaEvent).Delta)
+
+	// Dropped steps are removed from the end.
+	events = tr.Translate(stateUpdated(planState("completed")))
+	assert.Equal(t, []map[string]any{
+		{"op": "remove", "path": "/plan/steps/2"},
+		{"op": "remove", "path": "/plan/steps/1"},
+	}, events[0].(*StateDeltaEvent).Delta)
+
+	// Nothing changed, nothing sent.
+	assert.Empty(t, tr.Translate(stateUpdated(planState("completed"))))
+}
+
+// The pause's snapshot replaces the client's state, so it carries the plan.
+func TestRunPausedSnapshotKeepsSharedState(t *testing.T) {
+	tr := NewTranslator("thread-1", "run-1")
+	tr.Start()
+	tr.Translate(stateUpdated(planState("pending")))
+
+	events := tr.Translate(runPaused(responses.FunctionCallMessage{CallID: "call_1", Name: "execute_next_step", Arguments: "{}"}))
+	snapshot := events[0].(*StateSnapshotEvent).Snapshot.(map[string]any)
+	assert.Equal(t, "paused", snapshot["status"])
+	assert.Equal(t, planState("pending")["plan"], snapshot["plan"])
+}
@@ -0,0 +1,727 @@
+package gateway
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"maps"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/google/uuid"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/chat_completion"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/speech"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
+)
+
+// AuditRecord is the durable record of one LLM exchange: who called, with
+// which key, what was asked, what came back, and what it cost. Request and
+// Response hold the native request/response JSON after redaction.
+//
+// A streamed exchange is reassembled from its chunks into the same response
+// shape the non-streaming call returns, so records of the two paths can be
+// compared and queried alike.
+type AuditRecord struct {
+	ID          string            `json:"id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Provider    string            `json:"provider"`
+	Model       string            `json:"model"`
+	RequestType string            `json:"request_type"`
+	Streaming   bool              `json:"streaming"`
+	VirtualKey  string            `json:"virtual_key,omitempty"`
+	APIKey      string            `json:"api_key,omitempty"`
+	Caller      map[string]string `json:"caller,omitempty"`
+
+	Request  json.RawMessage `json:"request,omitempty"`
+	Response json.RawMessage `json:"response,omitempty"`
+	Error    string          `json:"error,omitempty"`
+
+	Usage *AuditUsage `json:"usage,omitempty"`
+
+	LatencyMs int64 `json:"latency_ms"`
+	// TimeToFirstChunkMs is only set for streamed exchanges.
+	TimeToFirstChunkMs int64 `json:"time_to_first_chunk_ms,omitempty"`
+}
+
+type AuditUsage struct {
+	InputTokens       int64 `json:"input_tokens"`
+	CachedInputTokens int64 `json:"cached_input_tokens,omitempty"`
+	OutputTokens      int64 `json:"output_tokens"`
+}
+
+type AuditOptions struct {
+	// Sink receives every record. Required.
+	Sink AuditSink
+
+	// Redaction is applied to the request and response before they reach
+	// the sink. The zero value still masks the keys used for the exchange.
+	Redaction AuditRedaction
+
+	// SampleRate is the fraction of successful exchanges recorded, between
+	// 0 and 1. Nil records every exchange. Failed exchanges are always
+	// recorded.
+	SampleRate *float64
+
+	// OnSinkError is called when the sink fails to write a record. Defaults
+	// to logging the error; the exchange itself is never failed by it.
+	OnSinkError func(ctx context.Context, err error)
+}
+
+// AuditMiddleware writes an AuditRecord for every gateway exchange to a
+// pluggable sink. Install it with LLMGateway.UseMiddleware; installed after
+// TracingMiddleware, its latency excludes span bookkeeping.
+type AuditMiddleware struct {
+	opts     AuditOptions
+	redactor *auditRedactor
+}
+
+var _ Middleware = (*AuditMiddleware)(nil)
+
+func NewAuditMiddleware(opts AuditOptions) *AuditMiddleware {
+	if opts.OnSinkError == nil {
+		opts.OnSinkError = func(ctx context.Context, err error) {
+			slog.ErrorContext(ctx, "failed to write llm audit record", slog.Any("error", err))
+		}
+	}
+
+	return &AuditMiddleware{
+		opts:     opts,
+		redactor: newAuditRedactor(opts.Redaction),
+	}
+}
+
+func (m *AuditMiddleware) HandleRequest(next RequestHandler) RequestHandler {
+	return func(ctx context.Context, providerName llm.ProviderName, key string, r *llm.Request) (*llm.Response, error) {
+		start := time.Now()
+		resp, err := next(ctx, providerName, key, r)
+
+		rec := m.newRecord(ctx, providerName, key, r, false, start)
+		rec.LatencyMs = time.Since(start).Milliseconds()
+		if err != nil {
+			rec.Error = err.Error()
+		} else {
+			rec.Response, rec.Usage = m.auditResponse(ctx, resp, key)
+		}
+
+		m.write(ctx, rec, err != nil)
+		return resp, err
+	}
+}
+
+func (m *AuditMiddleware) HandleStreamingRequest(next StreamingRequestHandler) StreamingRequestHandler {
+	return func(ctx context.Context, providerName llm.ProviderName, key string, r *llm.Request) (*llm.StreamingResponse, error) {
+		start := time.Now()
+		resp, err := next(ctx, providerName, key, r)
+
+		rec := m.newRecord(ctx, providerName, key, r, true, start)
+		if err != nil {
+			rec.LatencyMs = time.Since(start).Milliseconds()
+			rec.Error = err.Error()
+			m.write(ctx, rec, true)
+			return resp, err
+		}
+
+		// Like tracing, the record is finished when the stream drains: the
+		// wrapper forwards every chunk, folds it into a response and writes
+		// the record once the provider's channel closes.
+		m.wrapStreamingResponse(ctx, key, rec, start, resp)
+		return resp, nil
+	}
+}
+
+func (m *AuditMiddleware) newRecord(ctx context.Context, providerName llm.ProviderName, key string, r *llm.Request, streaming bool, start time.Time) *AuditRecord {
+	_, reqType := operationAndType(r, streaming)
+
+	rec := &AuditRecord{
+		ID:          uuid.NewString(),
+		Timestamp:   start.UTC(),
+		Provider:    string(providerName),
+		Model:       r.GetRequestedModel(),
+		RequestType: reqType,
+		Streaming:   streaming,
+		APIKey:      maskKey(key),
+		VirtualKey:  maskKey(ProviderConfigKeyFromContext(ctx)),
+		Request:     m.redactor.redact(requestPayload(r), key, ProviderConfigKeyFromContext(ctx)),
+	}
+
+	if caller := GetContext(ctx); len(caller) > 0 {
+		rec.Caller = maps.Clone(caller)
+	}
+
+	return rec
+}
+
+func (m *AuditMiddleware) auditResponse(ctx context.Context, resp *llm.Response, key string) (json.RawMessage, *AuditUsage) {
+	payload, usage := responsePayload(resp)
+	return m.redactor.redact(payload, key, ProviderConfigKeyFromContext(ctx)), usage
+}
+
+func (m *AuditMiddleware) write(ctx context.Context, rec *AuditRecord, failed bool) {
+	if !failed && m.opts.SampleRate != nil && rand.Float64() >= *m.opts.SampleRate {
+		return
+	}
+
+	if err := m.opts.Sink.Write(ctx, rec); err != nil {
+		m.opts.OnSinkError(ctx, err)
+	}
+}
+
+func (m *AuditMiddleware) wrapStreamingResponse(ctx context.Context, key string, rec *AuditRecord, start time.Time, resp *llm.StreamingResponse) {
+	var firstChunk time.Time
+	markFirst := func() {
+		if firstChunk.IsZero() {
+			firstChunk = time.Now()
+		}
+	}
+	finish := func(out *llm.Response) {
+		rec.LatencyMs = time.Since(start).Milliseconds()
+		if !firstChunk.IsZero() {
+			rec.TimeToFirstChunkMs = firstChunk.Sub(start).Milliseconds()
+		}
+		rec.Response, rec.Usage = m.auditResponse(ctx, out, key)
+		// A stream that ended on an error chunk is a failure, so sampling
+		// keeps it.
+		m.write(ctx, rec, rec.Error != "")
+	}
+
+	switch {
+	case resp != nil && resp.ResponsesStreamData != nil:
+		orig := resp.ResponsesStreamData
+		wrapped := make(chan *responses.ResponseChunk)
+		resp.ResponsesStreamData = wrapped
+		go func() {
+			defer close(wrapped)
+			acc := &responsesStreamAccumulator{}
+			for chunk := range orig {
+				markFirst()
+				acc.add(chunk)
+				if chunk.OfError != nil {
+					rec.Error = chunk.OfError.Message
+				}
+				if chunk.OfResponseFailed != nil {
+					rec.Error = failureMessage(chunk.OfResponseFailed.Response.Error)
+				}
+				wrapped <- chunk
+			}
+			finish(&llm.Response{OfResponsesOutput: acc.response()})
+		}()
+
+	case resp != nil && resp.ChatCompletionStreamData != nil:
+		orig := resp.ChatCompletionStreamData
+		wrapped := make(chan *chat_completion.ResponseChunk)
+		resp.ChatCompletionStreamData = wrapped
+		go func() {
+			defer close(wrapped)
+			acc := &chatStreamAccumulator{}
+			for chunk := range orig {
+				markFirst()
+				acc.add(chunk)
+				if c := chunk.OfChatCompletionChunk; c != nil && c.Error != nil {
+					rec.Error = c.Error.Message
+				}
+				wrapped <- chunk
+			}
+			finish(&llm.Response{OfChatCompletionOutput: acc.response()})
+		}()
+
+	case resp != nil && resp.SpeechStreamData != nil:
+		orig := resp.SpeechStreamData
+		wrapped := make(chan *speech.ResponseChunk)
+		resp.SpeechStreamData = wrapped
+		go func() {
+			defer close(wrapped)
+			out := &speech.Response{}
+			for chunk := range orig {
+				markFirst()
+				if chunk.OfAudioDelta != nil {
+					audio, err := base64.StdEncoding.DecodeString(chunk.OfAudioDelta.Audio)
+					if err == nil {
+						out.Audio = append(out.Audio, audio...)
+					}
+				}
+				if chunk.OfAudioDone != nil {
+					out.Usage = chunk.OfAudioDone.Usage
+				}
+				wrapped <- chunk
+			}
+			finish(&llm.Response{OfSpeech: out})
+		}()
+
+	case resp != nil && resp.TranscriptionStreamData != nil:
+		orig := resp.TranscriptionStreamData
+		wrapped := make(chan *transcription.ResponseChunk)
+		resp.TranscriptionStreamData = wrapped
+		go func() {
+			defer close(wrapped)
+			out := &transcription.Response{}
+			for chunk := range orig {
+				markFirst()
+				if chunk.OfFinal != nil {
+					out.Words = append(out.Words, chunk.OfFinal.Words...)
+				}
+				if chunk.OfDone != nil {
+					out.Text = chunk.OfDone.Text
+					out.Language = chunk.OfDone.Language
+					out.Duration = chunk.OfDone.Duration
+					out.Usage = chunk.OfDone.Usage
+				}
+				if chunk.OfError != nil {
+					rec.Error = chunk.OfError.Message
+				}
+				wrapped <- chunk
+			}
+			finish(&llm.Response{OfTranscription: out})
+		}()
+
+	default:
+		rec.LatencyMs = time.Since(start).Milliseconds()
+		m.write(ctx, rec, false)
+	}
+}
+
+// requestPayload returns the populated member of the request union. Each
+// member is passed as a pointer so union fields with pointer-receiver
+// MarshalJSON methods marshal through them.
+func requestPayload(r *llm.Request) any {
+	switch {
+	case r.OfResponsesInput != nil:
+		return r.OfResponsesInput
+	case r.OfResponsesGet != nil:
+		return r.OfResponsesGet
+	case r.OfResponsesCancel != nil:
+		return r.OfResponsesCancel
+	case r.OfResponsesStreamFrom != nil:
+		return r.OfResponsesStreamFrom
+	case r.OfChatCompletionInput != nil:
+		return r.OfChatCompletionInput
+	case r.OfEmbeddingsInput != nil:
+		return r.OfEmbeddingsInput
+	case r.OfSpeech != nil:
+		return r.OfSpeech
+	case r.OfTranscription != nil:
+		return r.OfTranscription
+	case r.OfImageGeneration != nil:
+		return r.OfImageGeneration
+	case r.OfImageEdit != nil:
+		return r.OfImageEdit
+	case r.OfVideoGeneration != nil:
+		return r.OfVideoGeneration
+	case r.OfVideoGenerationGet != nil:
+		return r.OfVideoGenerationGet
+	case r.OfVideoGenerationDownload != nil:
+		return r.OfVideoGenerationDownload
+	}
+	return nil
+}
+
+func responsePayload(resp *llm.Response) (any, *AuditUsage) {
+	if resp == nil {
+		return nil, nil
+	}
+
+	switch {
+	case resp.OfResponsesOutput != nil:
+		out := resp.OfResponsesOutput
+		var usage *AuditUsage
+		if out.Usage != nil {
+			usage = &AuditUsage{
+				InputTokens:       int64(out.Usage.InputTokens),
+				CachedInputTokens: int64(out.Usage.InputTokensDetails.CachedTokens),
+				OutputTokens:      int64(out.Usage.OutputTokens),
+			}
+		}
+		return out, usage
+
+	case resp.OfChatCompletionOutput != nil:
+		out := resp.OfChatCompletionOutput
+		return out, &AuditUsage{
+			InputTokens:       out.Usage.PromptTokens,
+			CachedInputTokens: out.Usage.PromptTokensDetails.CachedTokens,
+			OutputTokens:      out.Usage.CompletionTokens,
+		}
+
+	case resp.OfEmbeddingsOutput != nil:
+		out := resp.OfEmbeddingsOutput
+		var usage *AuditUsage
+		if out.Usage != nil {
+			usage = &AuditUsage{InputTokens: out.Usage.PromptTokens}
+		}
+		return out, usage
+
+	case resp.OfSpeech != nil:
+		out := resp.OfSpeech
+		return out, &AuditUsage{
+			InputTokens:       int64(out.Usage.InputTokens),
+			CachedInputTokens: int64(out.Usage.InputTokensDetails.CachedTokens),
+			OutputTokens:      int64(out.Usage.OutputTokens),
+		}
+
+	case resp.OfTranscription != nil:
+		out := resp.OfTranscription
+		var usage *AuditUsage
+		if out.Usage != nil {
+			usage = &AuditUsage{
+				InputTokens:  int64(out.Usage.PromptTokens),
+				OutputTokens: int64(out.Usage.CompletionTokens),
+			}
+		}
+		return out, usage
+	case resp.OfImageGeneration != nil:
+		return resp.OfImageGeneration, nil
+	case resp.OfImageEdit != nil:
+		return resp.OfImageEdit, nil
+	case resp.OfVideoGeneration != nil:
+		return resp.OfVideoGeneration, nil
+	case resp.OfVideoContent != nil:
+		// The video itself is too large to keep in an audit record.
+		return map[string]any{
+			"mime_type": resp.OfVideoContent.MimeType,
+			"bytes":     len(resp.OfVideoContent.Data),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// responsesStreamAccumulator folds a Responses stream into the response the
+// non-streaming call would have returned: every finished output item in
+// order, plus the id, model and usage from the envelope chunks.
+type responsesStreamAccumulator struct {
+	out       responses.Response
+	completed bool
+	// skipped counts finished items of a type the accumulator does not
+	// know, which the folded response leaves out.
+	skipped int
+}
+
+func (a *responsesStreamAccumulator) add(chunk *responses.ResponseChunk) {
+	switch {
+	case chunk.OfResponseCreated != nil:
+		a.out.ID = chunk.OfResponseCreated.Response.Id
+		a.out.Model = chunk.OfResponseCreated.Response.Model
+
+	case chunk.OfOutputItemDone != nil:
+		if item, ok := outputItemFromChunk(chunk.OfOutputItemDone.Item); ok {
+			a.out.Output = append(a.out.Output, item)
+		} else {
+			a.skipped++
+		}
+
+	case chunk.OfResponseCompleted != nil:
+		a.completed = true
+		completed := chunk.OfResponseCompleted.Response
+		usage := completed.Usage
+		a.out.Usage = &usage
+		if a.out.ID == "" {
+			a.out.ID = completed.Id
+		}
+		if completed.Model != "" {
+			a.out.Model = completed.Model
+		}
+	}
+}
+
+func (a *responsesStreamAccumulator) response() *responses.Response {
+	if a.out.Output == nil {
+		a.out.Output = []responses.OutputMessageUnion{}
+	}
+	return &a.out
+}
+
+func outputItemFromChunk(item responses.ChunkOutputItemData) (responses.OutputMessageUnion, bool) {
+	switch item.Type {
+	case "message":
+		content := responses.OutputContent{}
+		if item.Content != nil {
+			for _, c := range *item.Content {
+				if c.OfOutputText != nil {
+					content = append(content, responses.OutputContentUnion{OfOutputText: c.OfOutputText})
+				}
+			}
+		}
+		return responses.OutputMessageUnion{OfOutputMessage: &responses.OutputMessage{
+			ID:      item.Id,
+			Role:    constants.RoleAssistant,
+			Content: &content,
+		}}, true
+
+	case "function_call":
+		call := &responses.FunctionCallMessage{ID: item.Id, ThoughtSignature: item.ThoughtSignature}
+		if item.CallID != nil {
+			call.CallID = *item.CallID
+		}
+		if item.Name != nil {
+			call.Name = *item.Name
+		}
+		if item.Arguments != nil {
+			call.Arguments = *item.Arguments
+		}
+		return responses.OutputMessageUnion{OfFunctionCall: call}, true
+
+	case "reasoning":
+		r := &responses.ReasoningMessage{ID: item.Id, EncryptedContent: item.EncryptedContent}
+		if item.Summary != nil {
+			r.Summary = *item.Summary
+		}
+		return responses.OutputMessageUnion{OfReasoning: r}, true
+
+	case "image_generation_call":
+		return responses.OutputMessageUnion{OfImageGenerationCall: &responses.ImageGenerationCallMessage{
+			ID:           item.Id,
+			Status:       item.Status,
+			Background:   deref(item.Background),
+			OutputFormat: deref(item.OutputFormat),
+			Quality:      deref(item.Quality),
+			Size:         deref(item.Size),
+			Result:       deref(item.Result),
+		}}, true
+
+	case "web_search_call":
+		call := &responses.WebSearchCallMessage{ID: item.Id, Status: item.Status}
+		if item.Action != nil {
+			call.Action = *item.Action
+		}
+		return responses.OutputMessageUnion{OfWebSearchCall: call}, true
+
+	case "code_interpreter_call":
+		return responses.OutputMessageUnion{OfCodeInterpreterCall: &responses.CodeInterpreterCallMessage{
+			ID:          item.Id,
+			Status:      item.Status,
+			Code:        deref(item.Code),
+			ContainerID: deref(item.ContainerID),
+			Outputs:     item.Outputs,
+		}}, true
+
+	case "file_search_call":
+		return responses.OutputMessageUnion{OfFileSearchCall: &responses.FileSearchCallMessage{
+			ID:      item.Id,
+			Status:  item.Status,
+			Queries: item.Queries,
+			Results: item.Results,
+		}}, true
+
+	case "mcp_list_tools":
+		return responses.OutputMessageUnion{OfMcpListTools: &responses.McpListToolsMessage{
+			ID:          item.Id,
+			ServerLabel: deref(item.ServerLabel),
+			Tools:       item.Tools,
+			Error:       item.Error,
+		}}, true
+
+	case "mcp_approval_request":
+		return responses.OutputMessageUnion{OfMcpApprovalRequest: &responses.McpApprovalRequestMessage{
+			ID:          item.Id,
+			ServerLabel: deref(item.ServerLabel),
+			Name:        deref(item.Name),
+			Arguments:   deref(item.Arguments),
+		}}, true
+
+	case "mcp_call":
+		return responses.OutputMessageUnion{OfMcpCall: &responses.McpCallMessage{
+			ID:          item.Id,
+			Status:      item.Status,
+			ServerLabel: deref(item.ServerLabel),
+			Name:        deref(item.Name),
+			Arguments:   deref(item.Arguments),
+			Output:      item.Output,
+			Error:       item.Error,
+		}}, true
+
+	case "web_fetch_call":
+		return responses.OutputMessageUnion{OfWebFetchCall: &responses.WebFetchCallMessage{
+			ID:          item.Id,
+			Status:      item.Status,
+			URL:         deref(item.URL),
+			Title:       deref(item.Title),
+			MediaType:   deref(item.MediaType),
+			Data:        deref(item.Data),
+			RetrievedAt: deref(item.RetrievedAt),
+			ErrorCode:   deref(item.ErrorCode),
+		}}, true
+
+	case "url_context_call":
+		return responses.OutputMessageUnion{OfURLContextCall: &responses.URLContextCallMessage{
+			ID:     item.Id,
+			Status: item.Status,
+			URLs:   item.URLs,
+		}}, true
+
+	case "google_maps_call":
+		return responses.OutputMessageUnion{OfGoogleMapsCall: &responses.GoogleMapsCallMessage{
+			ID:                 item.Id,
+			Status:             item.Status,
+			Places:             item.Places,
+			WidgetContextToken: item.WidgetContextToken,
+		}}, true
+	}
+
+	return responses.OutputMessageUnion{}, false
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// failureMessage reads the message out of a failed response's error, which
+// arrives as whatever JSON object the provider sent.
+func failureMessage(err interface{}) string {
+	switch e := err.(type) {
+	case *responses.Error:
+		if e != nil && e.Message != "" {
+			return e.Message
+		}
+	case map[string]interface{}:
+		if msg, ok := e["message"].(string); ok && msg != "" {
+			return msg
+		}
+	}
+	return "response failed"
+}
+
+// chatStreamAccumulator folds a chat completion stream into a chat
+// completion response, one choice per streamed choice index.
+type chatStreamAccumulator struct {
+	out     chat_completion.Response
+	content map[int]*strings.Builder
+	// toolCalls maps a choice's streamed tool call index to the call's
+	// position in that choice's ToolCalls.
+	toolCalls map[int]map[int]int
+}
+
+func (a *chatStreamAccumulator) add(chunk *chat_completion.ResponseChunk) {
+	c := chunk.OfChatCompletionChunk
+	if c == nil {
+		return
+	}
+
+	if c.Id != "" {
+		a.out.ID = c.Id
+	}
+	if c.Model != "" {
+		a.out.Model = c.Model
+	}
+	if c.Created != 0 {
+		a.out.Created = int64(c.Created)
+	}
+	if c.Usage != nil {
+		a.out.Usage = *c.Usage
+	}
+
+	if a.content == nil {
+		a.content = map[int]*strings.Builder{}
+	}
+	for _, choice := range c.Choices {
+		for len(a.out.Choices) <= choice.Index {
+			a.out.Choices = append(a.out.Choices, chat_completion.Choice{
+				Index:   int64(len(a.out.Choices)),
+				Message: chat_completion.OutputMessage{Role: constants.RoleAssistant},
+			})
+		}
+		b, ok := a.content[choice.Index]
+		if !ok {
+			b = &strings.Builder{}
+			a.content[choice.Index] = b
+		}
+		b.WriteString(choice.Delta.Content)
+		if choice.Delta.Refusal != "" {
+			a.out.Choices[choice.Index].Message.Refusal += choice.Delta.Refusal
+		}
+		for _, call := range choice.Delta.ToolCalls {
+			a.addToolCall(choice.Index, call)
+		}
+		if choice.FinishReason != "" {
+			a.out.Choices[choice.Index].FinishReason = choice.FinishReason
+		}
+	}
+}
+
+// addToolCall folds one tool call fragment into its choice's message. The
+// index is what ties argument fragments to their call; a provider that omits
+// it continues the last call until a fragment with a new id starts the next.
+func (a *chatStreamAccumulator) addToolCall(choice int, call chat_completion.ToolCall) {
+	if a.toolCalls == nil {
+		a.toolCalls = map[int]map[int]int{}
+	}
+	positions, ok := a.toolCalls[choice]
+	if !ok {
+		positions = map[int]int{}
+		a.toolCalls[choice] = positions
+	}
+
+	msg := &a.out.Choices[choice].Message
+	pos, found := 0, false
+	switch {
+	case call.Index != nil:
+		pos, found = positions[*call.Index]
+	case len(msg.ToolCalls) > 0:
+		last := len(msg.ToolCalls) - 1
+		if call.ID == "" || call.ID == msg.ToolCalls[last].ID {
+			pos, found = last, true
+		}
+	}
+	if !found {
+		pos = len(msg.ToolCalls)
+		msg.ToolCalls = append(msg.ToolCalls, chat_completion.ToolCall{Type: "function"})
+		if call.Index != nil {
+			positions[*call.Index] = pos
+		}
+	}
+
+	tc := &msg.ToolCalls[pos]
+	if call.ID != "" {
+		tc.ID = call.ID
+	}
+	if call.Type != "" {
+		tc.Type = call.Type
+	}
+	if call.Function.Name != "" {
+		tc.Function.Name = call.Function.Name
+	}
+	tc.Function.Arguments += call.Function.Arguments
+}
+
+func (a *chatStreamAccumulator) response() *chat_completion.Response {
+	a.out.Object = "chat.completion"
+	for i, b := range a.content {
+		a.out.Choices[i].Message.Content = b.String()
+	}
+	return &a.out
+}
+
+// maskKey keeps just enough of a key to tell keys apart in an audit trail
+// without making the record a credential store.
+func maskKey(key string) string {
+	if key == "" {
+		return ""
+	}
+	if len(key) <= 12 {
+		return "****"
+	}
+	return key[:3] + "..." + key[len(key)-4:]
+}
+
+// marshalAuditPayload is split out so redaction can work on the generic
+// JSON tree regardless of which union member was recorded.
+func marshalAuditPayload(v any) (any, bool) {
+	if v == nil {
+		return nil, false
+	}
+	b, err := sonic.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	var tree any
+	if err := sonic.Unmarshal(b, &tree); err != nil {
+		return nil, false
+	}
+	return tree, true
+}
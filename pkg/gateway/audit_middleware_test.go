@@ -0,0 +1,492 @@
+package gateway
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/chat_completion"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/llmtest"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+)
+
+type memorySink struct {
+	mu      sync.Mutex
+	records []*AuditRecord
+}
+
+func (s *memorySink) Write(_ context.Context, rec *AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, rec)
+	return nil
+}
+
+func (s *memorySink) all() []*AuditRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*AuditRecord(nil), s.records...)
+}
+
+func textRequest(text string) *llm.Request {
+	return &llm.Request{OfResponsesInput: &responses.Request{
+		Model: "gpt-4.1",
+		Input: responses.InputUnion{OfString: utils.Ptr(text)},
+	}}
+}
+
+func fakeHandlers(fake *llmtest.Provider) (RequestHandler, StreamingRequestHandler) {
+	next := func(ctx context.Context, _ llm.ProviderName, _ string, r *llm.Request) (*llm.Response, error) {
+		out, err := fake.NewResponses(ctx, r.OfResponsesInput)
+		if err != nil {
+			return nil, err
+		}
+		return &llm.Response{OfResponsesOutput: out}, nil
+	}
+	nextStream := func(ctx context.Context, _ llm.ProviderName, _ string, r *llm.Request) (*llm.StreamingResponse, error) {
+		ch, err := fake.NewStreamingResponses(ctx, r.OfResponsesInput)
+		if err != nil {
+			return nil, err
+		}
+		return &llm.StreamingResponse{ResponsesStreamData: ch}, nil
+	}
+	return next, nextStream
+}
+
+// Keys — the one used for the call, the virtual key, and credentials that
+// merely look like keys — never reach the sink, and configured PII
+// patterns are scrubbed from both sides of the exchange.
+func TestAuditMiddleware_RedactsKeysAndPII(t *testing.T) {
+	sink := &memorySink{}
+	m := NewAuditMiddleware(AuditOptions{
+		Sink:      sink,
+		Redaction: AuditRedaction{Patterns: []*regexp.Regexp{AuditPatternEmail}},
+	})
+
+	fake := llmtest.New().RespondText("I will email jane@example.com")
+	next, _ := fakeHandlers(fake)
+
+	providerKey := "prov-key-0123456789abcdef"
+	virtualKey := "vk-live-0123456789abcdef"
+	ctx := WithProviderConfigKey(context.Background(), virtualKey)
+	ctx = AddContext(ctx, map[string]string{"user_id": "u-1"})
+
+	prompt := "my key is " + providerKey + ", also sk-abcdefghijklmnopqrstuvwx; reach me at bob@example.org"
+	if _, err := m.HandleRequest(next)(ctx, "openai", providerKey, textRequest(prompt)); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	records := sink.all()
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	rec := records[0]
+
+	line, _ := json.Marshal(rec)
+	for _, secret := range []string{providerKey, virtualKey, "sk-abcdefghijklmnopqrstuvwx", "bob@example.org", "jane@example.com"} {
+		if bytes.Contains(line, []byte(secret)) {
+			t.Fatalf("record leaks %q: %s", secret, line)
+		}
+	}
+	if rec.APIKey != "pro...cdef" || rec.VirtualKey != "vk-...cdef" {
+		t.Fatalf("masked keys = %q / %q", rec.APIKey, rec.VirtualKey)
+	}
+	if rec.Caller["user_id"] != "u-1" {
+		t.Fatalf("caller context = %v", rec.Caller)
+	}
+	if rec.Provider != "openai" || rec.Model != "gpt-4.1" || rec.RequestType != "Responses" || rec.Streaming {
+		t.Fatalf("unexpected record header: %+v", rec)
+	}
+	if rec.Usage == nil || rec.Usage.OutputTokens == 0 {
+		t.Fatalf("usage not recorded: %+v", rec.Usage)
+	}
+}
+
+func TestAuditRedaction_Media(t *testing.T) {
+	r := newAuditRedactor(AuditRedaction{})
+	image := "data:image/png;base64," + base64.StdEncoding.EncodeToString(make([]byte, 300))
+	audio := base64.StdEncoding.EncodeToString(make([]byte, 600))
+
+	got := string(r.redact(map[string]any{"image_url": image, "audio": audio, "id": "aGVsbG8="}))
+	want := `{"audio":"[base64, 600 bytes]","id":"aGVsbG8=","image_url":"[image/png, 300 bytes]"}`
+	if got != want {
+		t.Fatalf("redacted = %s, want %s", got, want)
+	}
+
+	kept := string(newAuditRedactor(AuditRedaction{KeepMedia: true}).redact(map[string]any{"audio": audio}))
+	if !strings.Contains(kept, audio) {
+		t.Fatalf("KeepMedia dropped the payload: %s", kept)
+	}
+}
+
+// The record of a streamed exchange carries the same response a
+// non-streaming call returns, so the two can be queried alike.
+func TestAuditMiddleware_StreamReassemblesResponse(t *testing.T) {
+	sink := &memorySink{}
+	m := NewAuditMiddleware(AuditOptions{Sink: sink})
+
+	fake := llmtest.New().
+		ReasonThenAnswer("thinking", "the answer").WithUsage(40, 9).
+		ReasonThenAnswer("thinking", "the answer").WithUsage(40, 9)
+	next, nextStream := fakeHandlers(fake)
+	ctx := context.Background()
+
+	if _, err := m.HandleRequest(next)(ctx, "openai", "k", textRequest("q")); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	stream, err := m.HandleStreamingRequest(nextStream)(ctx, "openai", "k", textRequest("q"))
+	if err != nil {
+		t.Fatalf("streaming handler returned error: %v", err)
+	}
+	chunks := 0
+	for range stream.ResponsesStreamData {
+		chunks++
+	}
+	if chunks == 0 {
+		t.Fatalf("stream forwarded no chunks")
+	}
+
+	records := sink.all()
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+
+	var plain, streamed responses.Response
+	if err := json.Unmarshal(records[0].Response, &plain); err != nil {
+		t.Fatalf("decode plain response: %v", err)
+	}
+	if err := json.Unmarshal(records[1].Response, &streamed); err != nil {
+		t.Fatalf("decode streamed response: %v", err)
+	}
+
+	if !records[1].Streaming || records[1].Model != records[0].Model {
+		t.Fatalf("streamed record header: %+v", records[1])
+	}
+	if *records[0].Usage != *records[1].Usage {
+		t.Fatalf("usage differs: %+v vs %+v", records[0].Usage, records[1].Usage)
+	}
+	if len(plain.Output) != 2 || len(streamed.Output) != 2 {
+		t.Fatalf("outputs = %d / %d items, want 2", len(plain.Output), len(streamed.Output))
+	}
+	if streamed.Output[0].OfReasoning == nil || streamed.Output[0].OfReasoning.Summary[0].Text != "thinking" {
+		t.Fatalf("streamed reasoning item = %+v", streamed.Output[0])
+	}
+	plainText := (*plain.Output[1].OfOutputMessage.Content)[0].OfOutputText.Text
+	streamedText := (*streamed.Output[1].OfOutputMessage.Content)[0].OfOutputText.Text
+	if plainText != "the answer" || streamedText != plainText {
+		t.Fatalf("text = %q / %q", plainText, streamedText)
+	}
+}
+
+func TestAuditMiddleware_ChatStreamReassembly(t *testing.T) {
+	sink := &memorySink{}
+	m := NewAuditMiddleware(AuditOptions{Sink: sink})
+
+	next := func(ctx context.Context, _ llm.ProviderName, _ string, _ *llm.Request) (*llm.StreamingResponse, error) {
+		ch := make(chan *chat_completion.ResponseChunk, 3)
+		for i, part := range []string{"Hel", "lo"} {
+			chunk := &chat_completion.ChatCompletionChunk{Id: "c-1", Model: "gpt-4o"}
+			chunk.Choices = append(chunk.Choices, chat_completion.ChatCompletionChunkChoice{Index: 0})
+			chunk.Choices[0].Delta.Content = part
+			if i == 1 {
+				chunk.Choices[0].FinishReason = "stop"
+			}
+			ch <- &chat_completion.ResponseChunk{OfChatCompletionChunk: chunk}
+		}
+		ch <- &chat_completion.ResponseChunk{OfChatCompletionChunk: &chat_completion.ChatCompletionChunk{
+			Id:    "c-1",
+			Usage: &chat_completion.Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5},
+		}}
+		close(ch)
+		return &llm.StreamingResponse{ChatCompletionStreamData: ch}, nil
+	}
+
+	resp, err := m.HandleStreamingRequest(next)(context.Background(), "openai", "k", &llm.Request{
+		OfChatCompletionInput: &chat_completion.Request{Model: "gpt-4o"},
+	})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	for range resp.ChatCompletionStreamData {
+	}
+
+	records := sink.all()
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	var out chat_completion.Response
+	if err := json.Unmarshal(records[0].Response, &out); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(out.Choices) != 1 || out.Choices[0].Message.Content != "Hello" || out.Choices[0].FinishReason != "stop" {
+		t.Fatalf("reassembled = %+v", out)
+	}
+	if records[0].Usage.InputTokens != 3 || records[0].Usage.OutputTokens != 2 {
+		t.Fatalf("usage = %+v", records[0].Usage)
+	}
+}
+
+// Streamed tool calls arrive as fragments tied together by index, and can
+// interleave; each is reassembled whole into its choice's message.
+func TestAuditMiddleware_ChatStreamReassemblesToolCalls(t *testing.T) {
+	sink := &memorySink{}
+	m := NewAuditMiddleware(AuditOptions{Sink: sink})
+
+	frames := []string{
+		`{"id":"c-1","model":"gpt-4o","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_a","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}`,
+		`{"id":"c-1","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_b","type":"function","function":{"name":"get_time","arguments":"{\"tz\":"}}]}}]}`,
+		`{"id":"c-1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":\"Paris\"}"}}]}}]}`,
+		`{"id":"c-1","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"function":{"arguments":"\"CET\"}"}}]},"finish_reason":"tool_calls"}]}`,
+	}
+	next := func(ctx context.Context, _ llm.ProviderName, _ string, _ *llm.Request) (*llm.StreamingResponse, error) {
+		ch := make(chan *chat_completion.ResponseChunk, len(frames))
+		for _, frame := range frames {
+			var chunk chat_completion.ResponseChunk
+			if err := json.Unmarshal([]byte(frame), &chunk); err != nil {
+				t.Fatalf("decode frame: %v", err)
+			}
+			ch <- &chunk
+		}
+		close(ch)
+		return &llm.StreamingResponse{ChatCompletionStreamData: ch}, nil
+	}
+
+	resp, err := m.HandleStreamingRequest(next)(context.Background(), "openai", "k", &llm.Request{
+		OfChatCompletionInput: &chat_completion.Request{Model: "gpt-4o"},
+	})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	for range resp.ChatCompletionStreamData {
+	}
+
+	records := sink.all()
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	var out chat_completion.Response
+	if err := json.Unmarshal(records[0].Response, &out); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(out.Choices) != 1 || out.Choices[0].FinishReason != "tool_calls" {
+		t.Fatalf("reassembled = %+v", out)
+	}
+	calls := out.Choices[0].Message.ToolCalls
+	if len(calls) != 2 {
+		t.Fatalf("tool calls = %+v, want 2", calls)
+	}
+	if calls[0].ID != "call_a" || calls[0].Function.Name != "get_weather" || calls[0].Function.Arguments != `{"city":"Paris"}` {
+		t.Fatalf("first call = %+v", calls[0])
+	}
+	if calls[1].ID != "call_b" || calls[1].Function.Name != "get_time" || calls[1].Function.Arguments != `{"tz":"CET"}` {
+		t.Fatalf("second call = %+v", calls[1])
+	}
+}
+
+// Items run by provider-hosted tools are kept in a streamed record, as they
+// are in the response the non-streaming call returns.
+func TestAuditMiddleware_StreamKeepsHostedToolItems(t *testing.T) {
+	sink := &memorySink{}
+	m := NewAuditMiddleware(AuditOptions{Sink: sink})
+
+	fake := llmtest.New().StreamChunks(decodeResponseChunks(t,
+		`{"type":"response.created","sequence_number":0,"response":{"id":"resp_1","model":"gpt-4.1","status":"in_progress"}}`,
+		`{"type":"response.output_item.done","sequence_number":1,"output_index":0,"item":{"type":"web_search_call","id":"ws_1","status":"completed","action":{"type":"search","query":"weather in Paris"}}}`,
+		`{"type":"response.output_item.done","sequence_number":2,"output_index":1,"item":{"type":"mcp_call","id":"mcp_1","status":"completed","server_label":"docs","name":"lookup","arguments":"{}","output":"found"}}`,
+		`{"type":"response.output_item.done","sequence_number":3,"output_index":2,"item":{"type":"message","id":"msg_1","role":"assistant","status":"completed","content":[{"type":"output_text","text":"Sunny.","annotations":[]}]}}`,
+		`{"type":"response.completed","sequence_number":4,"response":{"id":"resp_1","model":"gpt-4.1","status":"completed","usage":{"input_tokens":5,"output_tokens":2,"total_tokens":7}}}`,
+	)...)
+	_, nextStream := fakeHandlers(fake)
+
+	stream, err := m.HandleStreamingRequest(nextStream)(context.Background(), "openai", "k", textRequest("q"))
+	if err != nil {
+		t.Fatalf("streaming handler returned error: %v", err)
+	}
+	for range stream.ResponsesStreamData {
+	}
+
+	records := sink.all()
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	var out responses.Response
+	if err := json.Unmarshal(records[0].Response, &out); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(out.Output) != 3 {
+		t.Fatalf("output = %d items, want 3", len(out.Output))
+	}
+	if ws := out.Output[0].OfWebSearchCall; ws == nil || ws.ID != "ws_1" || ws.Status != "completed" {
+		t.Fatalf("web search item = %+v", out.Output[0])
+	}
+	if mcp := out.Output[1].OfMcpCall; mcp == nil || mcp.Name != "lookup" || mcp.Output == nil || *mcp.Output != "found" {
+		t.Fatalf("mcp item = %+v", out.Output[1])
+	}
+	if out.Output[2].OfOutputMessage == nil {
+		t.Fatalf("message item = %+v", out.Output[2])
+	}
+}
+
+// A stream that reports an error in-band is recorded as a failure, so even a
+// zero sample rate keeps it.
+func TestAuditMiddleware_StreamErrorChunksAreFailures(t *testing.T) {
+	responsesStream := func(frames ...string) StreamingRequestHandler {
+		_, nextStream := fakeHandlers(llmtest.New().StreamChunks(decodeResponseChunks(t, frames...)...))
+		return nextStream
+	}
+	chatStream := func(frame string) StreamingRequestHandler {
+		return func(ctx context.Context, _ llm.ProviderName, _ string, _ *llm.Request) (*llm.StreamingResponse, error) {
+			var chunk chat_completion.ResponseChunk
+			if err := json.Unmarshal([]byte(frame), &chunk); err != nil {
+				t.Fatalf("decode frame: %v", err)
+			}
+			ch := make(chan *chat_completion.ResponseChunk, 1)
+			ch <- &chunk
+			close(ch)
+			return &llm.StreamingResponse{ChatCompletionStreamData: ch}, nil
+		}
+	}
+	chatRequest := &llm.Request{OfChatCompletionInput: &chat_completion.Request{Model: "gpt-4o"}}
+
+	tests := []struct {
+		name    string
+		next    StreamingRequestHandler
+		request *llm.Request
+		want    string
+	}{
+		{
+			name:    "responses error",
+			next:    responsesStream(`{"type":"error","sequence_number":0,"code":"server_error","message":"upstream overloaded"}`),
+			request: textRequest("q"),
+			want:    "upstream overloaded",
+		},
+		{
+			name: "responses failed",
+			next: responsesStream(
+				`{"type":"response.created","sequence_number":0,"response":{"id":"resp_1","model":"gpt-4.1","status":"in_progress"}}`,
+				`{"type":"response.failed","sequence_number":1,"response":{"id":"resp_1","model":"gpt-4.1","status":"failed","error":{"code":"server_error","message":"the model crashed"}}}`,
+			),
+			request: textRequest("q"),
+			want:    "the model crashed",
+		},
+		{
+			name:    "chat error",
+			next:    chatStream(`{"id":"c-1","choices":[],"error":{"type":"server_error","message":"chat backend down"}}`),
+			request: chatRequest,
+			want:    "chat backend down",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &memorySink{}
+			m := NewAuditMiddleware(AuditOptions{Sink: sink, SampleRate: utils.Ptr(0.0)})
+
+			resp, err := m.HandleStreamingRequest(tt.next)(context.Background(), "openai", "k", tt.request)
+			if err != nil {
+				t.Fatalf("handler returned error: %v", err)
+			}
+			if resp.ResponsesStreamData != nil {
+				for range resp.ResponsesStreamData {
+				}
+			}
+			if resp.ChatCompletionStreamData != nil {
+				for range resp.ChatCompletionStreamData {
+				}
+			}
+
+			records := sink.all()
+			if len(records) != 1 {
+				t.Fatalf("expected the failed stream to be kept, got %d records", len(records))
+			}
+			if records[0].Error != tt.want {
+				t.Fatalf("error = %q, want %q", records[0].Error, tt.want)
+			}
+		})
+	}
+}
+
+func decodeResponseChunks(t *testing.T, frames ...string) []*responses.ResponseChunk {
+	t.Helper()
+	chunks := make([]*responses.ResponseChunk, 0, len(frames))
+	for _, frame := range frames {
+		var chunk responses.ResponseChunk
+		if err := json.Unmarshal([]byte(frame), &chunk); err != nil {
+			t.Fatalf("decode chunk %s: %v", frame, err)
+		}
+		chunks = append(chunks, &chunk)
+	}
+	return chunks
+}
+
+// Sampling thins out successful exchanges only; failures are always kept.
+func TestAuditMiddleware_SamplingKeepsErrors(t *testing.T) {
+	sink := &memorySink{}
+	m := NewAuditMiddleware(AuditOptions{Sink: sink, SampleRate: utils.Ptr(0.0)})
+
+	fake := llmtest.New().RespondText("ok").Fail(errors.New("upstream exploded"))
+	next, _ := fakeHandlers(fake)
+	handler := m.HandleRequest(next)
+
+	_, _ = handler(context.Background(), "openai", "k", textRequest("a"))
+	_, _ = handler(context.Background(), "openai", "k", textRequest("b"))
+
+	records := sink.all()
+	if len(records) != 1 {
+		t.Fatalf("expected only the failed exchange, got %d records", len(records))
+	}
+	if records[0].Error != "upstream exploded" || records[0].Response != nil {
+		t.Fatalf("failed record = %+v", records[0])
+	}
+}
+
+func TestJSONLFileSink_Rotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "llm.jsonl")
+	sink, err := NewJSONLFileSink(path, JSONLFileSinkOptions{MaxSizeBytes: 300, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("open sink: %v", err)
+	}
+
+	for i := 0; i < 6; i++ {
+		rec := &AuditRecord{ID: strings.Repeat("x", 100), Provider: "openai"}
+		if err := sink.Write(context.Background(), rec); err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	total := 0
+	for _, p := range []string{path, path + ".1", path + ".2"} {
+		f, err := os.Open(p)
+		if err != nil {
+			t.Fatalf("expected %s: %v", p, err)
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var rec AuditRecord
+			if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+				t.Fatalf("%s holds a partial record: %v", p, err)
+			}
+			total++
+		}
+		_ = f.Close()
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected at most 2 backups")
+	}
+	if total == 0 || total == 6 {
+		t.Fatalf("expected rotation to drop the oldest records, kept %d of 6", total)
+	}
+}
@@ -0,0 +1,181 @@
+package gateway
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bytedance/sonic"
+)
+
+const auditRedacted = "[REDACTED]"
+
+// Patterns for common PII, ready to drop into AuditRedaction.Patterns.
+var (
+	AuditPatternEmail      = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	AuditPatternCardNumber = regexp.MustCompile(`\b(?:\d[ \-]?){13,19}\b`)
+	AuditPatternPhone      = regexp.MustCompile(`\+?\d{1,3}[ \-.]?\(?\d{2,4}\)?[ \-.]?\d{3,4}[ \-.]?\d{3,4}`)
+)
+
+// auditKeyPatterns catch provider credentials that end up in a payload by
+// accident — pasted into a prompt, echoed back in an error.
+var auditKeyPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`sk-[A-Za-z0-9_\-]{16,}`),
+	regexp.MustCompile(`AIza[0-9A-Za-z_\-]{35}`),
+	regexp.MustCompile(`xai-[A-Za-z0-9]{20,}`),
+	regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._\-]{16,}`),
+}
+
+// auditSecretFields are object keys whose values are dropped wholesale.
+var auditSecretFields = map[string]bool{
+	"api_key":       true,
+	"apikey":        true,
+	"authorization": true,
+	"x-api-key":     true,
+	"secret":        true,
+	"password":      true,
+}
+
+// AuditRedaction configures what is scrubbed from audit records. The keys
+// used for the exchange itself are always masked, wherever they appear.
+type AuditRedaction struct {
+	// KeepAPIKeys turns off masking of strings that look like provider
+	// credentials (sk-..., AIza..., bearer tokens) and of secret-named
+	// fields. The exchange's own keys are masked regardless.
+	KeepAPIKeys bool
+
+	// KeepMedia keeps base64 payloads — images, audio, files — in full.
+	// By default they are replaced with a short description of their size.
+	KeepMedia bool
+
+	// Patterns are replaced with Replacement in every string of the
+	// request and response, e.g. AuditPatternEmail.
+	Patterns []*regexp.Regexp
+
+	// Replacement defaults to "[REDACTED]".
+	Replacement string
+}
+
+type auditRedactor struct {
+	opts AuditRedaction
+}
+
+func newAuditRedactor(opts AuditRedaction) *auditRedactor {
+	if opts.Replacement == "" {
+		opts.Replacement = auditRedacted
+	}
+	return &auditRedactor{opts: opts}
+}
+
+// redact marshals v, scrubs every string in the resulting JSON tree and
+// returns the cleaned JSON. secrets are literal values to mask on top of
+// the configured rules.
+func (r *auditRedactor) redact(v any, secrets ...string) json.RawMessage {
+	tree, ok := marshalAuditPayload(v)
+	if !ok {
+		return nil
+	}
+
+	tree = r.walk(tree, secrets)
+
+	b, err := sonic.Marshal(tree)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+func (r *auditRedactor) walk(v any, secrets []string) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			if !r.opts.KeepAPIKeys && auditSecretFields[strings.ToLower(k)] {
+				if _, isString := child.(string); isString {
+					t[k] = auditRedacted
+					continue
+				}
+			}
+			t[k] = r.walk(child, secrets)
+		}
+		return t
+
+	case []any:
+		for i, child := range t {
+			t[i] = r.walk(child, secrets)
+		}
+		return t
+
+	case string:
+		return r.redactString(t, secrets)
+	}
+
+	return v
+}
+
+func (r *auditRedactor) redactString(s string, secrets []string) string {
+	if !r.opts.KeepMedia {
+		if masked, ok := maskMedia(s); ok {
+			return masked
+		}
+	}
+
+	for _, secret := range secrets {
+		// Anything shorter is not a real credential, and replacing it would
+		// mangle ordinary text.
+		if len(secret) >= minAuditSecretLen {
+			s = strings.ReplaceAll(s, secret, auditRedacted)
+		}
+	}
+
+	if !r.opts.KeepAPIKeys {
+		for _, p := range auditKeyPatterns {
+			s = p.ReplaceAllString(s, auditRedacted)
+		}
+	}
+
+	for _, p := range r.opts.Patterns {
+		s = p.ReplaceAllString(s, r.opts.Replacement)
+	}
+
+	return s
+}
+
+const minAuditSecretLen = 8
+
+// minAuditMediaLen is the length from which an unbroken base64 string is
+// treated as media rather than text. Short base64-looking strings — ids,
+// hashes, single words — are left alone.
+const minAuditMediaLen = 512
+
+// maskMedia replaces a data URL or a long raw base64 string with a
+// description of what it held.
+func maskMedia(s string) (string, bool) {
+	if strings.HasPrefix(s, "data:") {
+		header, data, ok := strings.Cut(s, ",")
+		if ok && strings.HasSuffix(header, ";base64") {
+			mime := strings.TrimSuffix(strings.TrimPrefix(header, "data:"), ";base64")
+			return fmt.Sprintf("[%s, %d bytes]", mime, base64.StdEncoding.DecodedLen(len(data))), true
+		}
+	}
+
+	if len(s) >= minAuditMediaLen && isBase64(s) {
+		return fmt.Sprintf("[base64, %d bytes]", base64.StdEncoding.DecodedLen(len(s))), true
+	}
+
+	return "", false
+}
+
+func isBase64(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '+', c == '/', c == '=', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,220 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/bytedance/sonic"
+)
+
+// AuditSink is where AuditMiddleware sends records. Write is called from
+// whichever goroutine finished the exchange — for streams, the one draining
+// the provider's channel — so implementations must be safe for concurrent
+// use.
+type AuditSink interface {
+	Write(ctx context.Context, rec *AuditRecord) error
+}
+
+// WriterSink writes each record as one line of JSON to an io.Writer.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+func (s *WriterSink) Write(_ context.Context, rec *AuditRecord) error {
+	line, err := marshalAuditLine(rec)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.w.Write(line)
+	return err
+}
+
+// SlogSink logs each record through a slog.Logger, with the record's fields
+// as attributes. Request and response bodies are logged as JSON strings.
+type SlogSink struct {
+	logger *slog.Logger
+	level  slog.Level
+}
+
+func NewSlogSink(logger *slog.Logger, level slog.Level) *SlogSink {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogSink{logger: logger, level: level}
+}
+
+func (s *SlogSink) Write(ctx context.Context, rec *AuditRecord) error {
+	attrs := []slog.Attr{
+		slog.String("id", rec.ID),
+		slog.String("provider", rec.Provider),
+		slog.String("model", rec.Model),
+		slog.String("request_type", rec.RequestType),
+		slog.Bool("streaming", rec.Streaming),
+		slog.Int64("latency_ms", rec.LatencyMs),
+	}
+	if rec.VirtualKey != "" {
+		attrs = append(attrs, slog.String("virtual_key", rec.VirtualKey))
+	}
+	if rec.APIKey != "" {
+		attrs = append(attrs, slog.String("api_key", rec.APIKey))
+	}
+	if len(rec.Caller) > 0 {
+		attrs = append(attrs, slog.Any("caller", rec.Caller))
+	}
+	if rec.Usage != nil {
+		attrs = append(attrs, slog.Group("usage",
+			slog.Int64("input_tokens", rec.Usage.InputTokens),
+			slog.Int64("cached_input_tokens", rec.Usage.CachedInputTokens),
+			slog.Int64("output_tokens", rec.Usage.OutputTokens),
+		))
+	}
+	if rec.Request != nil {
+		attrs = append(attrs, slog.String("request", string(rec.Request)))
+	}
+	if rec.Response != nil {
+		attrs = append(attrs, slog.String("response", string(rec.Response)))
+	}
+	if rec.Error != "" {
+		attrs = append(attrs, slog.String("error", rec.Error))
+	}
+
+	s.logger.LogAttrs(ctx, s.level, "llm exchange", attrs...)
+	return nil
+}
+
+type JSONLFileSinkOptions struct {
+	// MaxSizeBytes rotates the file once writing the next record would take
+	// it past this size. Zero never rotates.
+	MaxSizeBytes int64
+
+	// MaxBackups is how many rotated files are kept, named <path>.1 (the
+	// most recent) through <path>.N. Older ones are deleted. Zero keeps
+	// one.
+	MaxBackups int
+}
+
+// JSONLFileSink appends records to a JSON Lines file and rotates it by
+// size. Call Close when done to release the file.
+type JSONLFileSink struct {
+	path string
+	opts JSONLFileSinkOptions
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func NewJSONLFileSink(path string, opts JSONLFileSinkOptions) (*JSONLFileSink, error) {
+	if opts.MaxBackups <= 0 {
+		opts.MaxBackups = 1
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	s := &JSONLFileSink{path: path, opts: opts}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *JSONLFileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	s.file = f
+	s.size = info.Size()
+	return nil
+}
+
+func (s *JSONLFileSink) Write(_ context.Context, rec *AuditRecord) error {
+	line, err := marshalAuditLine(rec)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return fmt.Errorf("audit file %s is closed", s.path)
+	}
+
+	// A record is never split across files; an oversized record still goes
+	// into an otherwise empty file.
+	if s.opts.MaxSizeBytes > 0 && s.size > 0 && s.size+int64(len(line)) > s.opts.MaxSizeBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	return err
+}
+
+func (s *JSONLFileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	s.file = nil
+
+	_ = os.Remove(fmt.Sprintf("%s.%d", s.path, s.opts.MaxBackups))
+	for i := s.opts.MaxBackups - 1; i >= 1; i-- {
+		src := fmt.Sprintf("%s.%d", s.path, i)
+		if _, err := os.Stat(src); err == nil {
+			if err := os.Rename(src, fmt.Sprintf("%s.%d", s.path, i+1)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := os.Rename(s.path, s.path+".1"); err != nil {
+		return err
+	}
+
+	return s.open()
+}
+
+func (s *JSONLFileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+func marshalAuditLine(rec *AuditRecord) ([]byte, error) {
+	b, err := sonic.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
@@ -0,0 +1,387 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"gopkg.in/yaml.v3"
+)
+
+// FileConfig is the on-disk shape of a FileConfigStore. The file may be YAML
+// or JSON; JSON is read as the YAML subset it is. Any string value may use
+// ${VAR} or ${VAR:-default} to pull from the environment. An interpolated
+// value is a string whatever it looks like; a number or boolean read from
+// the environment needs an explicit tag, as weight has below.
+//
+//	providers:
+//	  - name: OpenAI
+//	    base_url: https://api.openai.com/v1
+//	    api_keys:
+//	      - name: primary
+//	        api_key: ${OPENAI_API_KEY}
+//	        weight: !!int ${OPENAI_WEIGHT:-3}
+//	        default: true
+//	        rate_limits:
+//	          - {unit: minute, limit: 500}
+//	virtual_keys:
+//	  - secret_key: ${TEAM_A_KEY}
+//	    allowed_providers: [OpenAI]
+//	    allowed_models: [gpt-4.1-mini]
+type FileConfig struct {
+	Providers   []FileProviderConfig   `yaml:"providers" json:"providers"`
+	VirtualKeys []FileVirtualKeyConfig `yaml:"virtual_keys" json:"virtual_keys"`
+}
+
+type FileProviderConfig struct {
+	Name    string             `yaml:"name" json:"name"`
+	BaseURL string             `yaml:"base_url" json:"base_url"`
+	Headers map[string]string  `yaml:"headers" json:"headers"`
+	APIKeys []FileAPIKeyConfig `yaml:"api_keys" json:"api_keys"`
+}
+
+type FileAPIKeyConfig struct {
+	Name       string      `yaml:"name" json:"name"`
+	APIKey     string      `yaml:"api_key" json:"api_key"`
+	Weight     int         `yaml:"weight" json:"weight"`
+	RateLimits []RateLimit `yaml:"rate_limits" json:"rate_limits"`
+	// Enabled defaults to true; set it to false to take a key out of
+	// rotation without deleting it.
+	Enabled *bool `yaml:"enabled" json:"enabled"`
+	Default bool  `yaml:"default" json:"default"`
+}
+
+type FileVirtualKeyConfig struct {
+	SecretKey        string      `yaml:"secret_key" json:"secret_key"`
+	AllowedProviders []string    `yaml:"allowed_providers" json:"allowed_providers"`
+	AllowedModels    []string    `yaml:"allowed_models" json:"allowed_models"`
+	RateLimits       []RateLimit `yaml:"rate_limits" json:"rate_limits"`
+}
+
+type FileConfigStoreOptions struct {
+	// PollInterval is how often the file is checked for changes. Defaults
+	// to 2s; a negative value turns watching off, leaving Reload as the
+	// only way to pick up changes.
+	PollInterval time.Duration
+
+	// OnReload is called after every reload attempt triggered by a change
+	// to the file. On success err is nil and cfg is the new config; on
+	// failure err says why and the previous config stays in force.
+	OnReload func(cfg *FileConfig, err error)
+
+	// LookupEnv resolves ${VAR} references. Defaults to os.LookupEnv.
+	LookupEnv func(key string) (string, bool)
+}
+
+// FileConfigStore is a ConfigStore read from a YAML or JSON file and
+// reloaded when the file changes. A reload either replaces the whole
+// config at once or, if the new file does not parse or validate, is
+// rejected and the previous config kept. Call Close to stop watching.
+type FileConfigStore struct {
+	path string
+	opts FileConfigStoreOptions
+
+	current atomic.Pointer[fileConfigSnapshot]
+
+	reloadMu sync.Mutex
+	rejected [sha256.Size]byte
+	stop     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+var _ ConfigStore = (*FileConfigStore)(nil)
+
+type fileConfigSnapshot struct {
+	config      *FileConfig
+	digest      [sha256.Size]byte
+	providers   map[llm.ProviderName]*ProviderConfig
+	virtualKeys map[string]*VirtualKeyConfig
+}
+
+// NewFileConfigStore loads path and starts watching it. It fails if the
+// initial load does.
+func NewFileConfigStore(path string, opts FileConfigStoreOptions) (*FileConfigStore, error) {
+	if opts.PollInterval == 0 {
+		opts.PollInterval = 2 * time.Second
+	}
+	if opts.LookupEnv == nil {
+		opts.LookupEnv = os.LookupEnv
+	}
+
+	s := &FileConfigStore{
+		path: path,
+		opts: opts,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	if _, err := s.load(); err != nil {
+		return nil, err
+	}
+
+	if opts.PollInterval > 0 {
+		go s.watch()
+	} else {
+		close(s.done)
+	}
+
+	return s, nil
+}
+
+func (s *FileConfigStore) GetProviderConfig(_ context.Context, providerName llm.ProviderName, _ string) (*ProviderConfig, error) {
+	config := s.current.Load().providers[providerName]
+	if config == nil || len(config.ApiKeys) == 0 {
+		return nil, fmt.Errorf("no API key configured for provider %s", providerName)
+	}
+	return config, nil
+}
+
+func (s *FileConfigStore) GetVirtualKey(_ context.Context, secretKey string) (*VirtualKeyConfig, error) {
+	vk := s.current.Load().virtualKeys[secretKey]
+	if vk == nil {
+		return nil, ErrVirtualKeyNotFound
+	}
+	return vk, nil
+}
+
+// Config returns the config currently in force.
+func (s *FileConfigStore) Config() *FileConfig {
+	return s.current.Load().config
+}
+
+// Reload re-reads the file now, even if it was rejected before — the
+// environment it refers to may have changed. On error the previous config
+// is kept.
+func (s *FileConfigStore) Reload() error {
+	s.reloadMu.Lock()
+	s.rejected = [sha256.Size]byte{}
+	s.reloadMu.Unlock()
+
+	_, err := s.load()
+	return err
+}
+
+// Close stops watching the file. The store keeps serving the last config.
+func (s *FileConfigStore) Close() error {
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+	return nil
+}
+
+func (s *FileConfigStore) watch() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			changed, err := s.load()
+			if !changed && err == nil {
+				continue
+			}
+			if err != nil {
+				slog.Error("rejected config reload, keeping previous config",
+					slog.String("path", s.path), slog.Any("error", err))
+			}
+			if s.opts.OnReload != nil {
+				s.opts.OnReload(s.Config(), err)
+			}
+		}
+	}
+}
+
+// load reads and applies the file, reporting whether it differed from the
+// config in force. The comparison is made after interpolation, so a change
+// to the environment the file refers to counts as a change; a config that
+// is unchanged is not decoded again.
+func (s *FileConfigStore) load() (bool, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	raw, err := os.ReadFile(s.path)
+	if err != nil {
+		return false, fmt.Errorf("read config %s: %w", s.path, err)
+	}
+
+	interpolated, err := interpolateFileConfig(raw, s.opts.LookupEnv)
+	if err != nil {
+		return s.reject(sha256.Sum256(raw), err)
+	}
+
+	digest := sha256.Sum256(interpolated)
+	if prev := s.current.Load(); prev != nil && prev.digest == digest {
+		return false, nil
+	}
+	// A config already rejected is not reported again on every poll.
+	if digest == s.rejected {
+		return false, nil
+	}
+
+	snapshot, err := decodeFileConfig(interpolated)
+	if err != nil {
+		return s.reject(digest, err)
+	}
+	snapshot.digest = digest
+
+	s.current.Store(snapshot)
+	return true, nil
+}
+
+// reject records digest as rejected, so the same config is not reported
+// again on every poll, and returns err for the caller to report once.
+func (s *FileConfigStore) reject(digest [sha256.Size]byte, err error) (bool, error) {
+	if digest == s.rejected {
+		return false, nil
+	}
+	s.rejected = digest
+	return true, fmt.Errorf("config %s: %w", s.path, err)
+}
+
+// interpolateFileConfig parses raw, expands its environment references and
+// re-encodes it.
+func interpolateFileConfig(raw []byte, lookupEnv func(string) (string, bool)) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(raw, &root); err != nil {
+		return nil, err
+	}
+	if err := interpolateEnv(&root, lookupEnv); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(&root)
+}
+
+func decodeFileConfig(interpolated []byte) (*fileConfigSnapshot, error) {
+	// Round-trip through the decoder so unknown fields — usually typos —
+	// are an error rather than silently ignored.
+	dec := yaml.NewDecoder(bytes.NewReader(interpolated))
+	dec.KnownFields(true)
+
+	var cfg FileConfig
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, err
+	}
+
+	return buildFileConfigSnapshot(&cfg)
+}
+
+func buildFileConfigSnapshot(cfg *FileConfig) (*fileConfigSnapshot, error) {
+	snapshot := &fileConfigSnapshot{
+		config:      cfg,
+		providers:   make(map[llm.ProviderName]*ProviderConfig, len(cfg.Providers)),
+		virtualKeys: make(map[string]*VirtualKeyConfig, len(cfg.VirtualKeys)),
+	}
+
+	for i, p := range cfg.Providers {
+		if p.Name == "" {
+			return nil, fmt.Errorf("providers[%d]: name is required", i)
+		}
+		name := llm.ProviderName(p.Name)
+		if _, dup := snapshot.providers[name]; dup {
+			return nil, fmt.Errorf("providers[%d]: provider %s is configured twice", i, p.Name)
+		}
+
+		pc := &ProviderConfig{
+			ProviderName:  name,
+			BaseURL:       p.BaseURL,
+			CustomHeaders: p.Headers,
+		}
+		for j, k := range p.APIKeys {
+			if k.APIKey == "" {
+				return nil, fmt.Errorf("providers[%d].api_keys[%d]: api_key is empty", i, j)
+			}
+			if k.Weight < 0 {
+				return nil, fmt.Errorf("providers[%d].api_keys[%d]: weight must not be negative", i, j)
+			}
+			pc.ApiKeys = append(pc.ApiKeys, &APIKeyConfig{
+				ProviderName: name,
+				APIKey:       k.APIKey,
+				Name:         k.Name,
+				RateLimits:   k.RateLimits,
+				Weight:       k.Weight,
+				Disabled:     k.Enabled != nil && !*k.Enabled,
+				IsDefault:    k.Default,
+			})
+		}
+		snapshot.providers[name] = pc
+	}
+
+	for i, vk := range cfg.VirtualKeys {
+		if vk.SecretKey == "" {
+			return nil, fmt.Errorf("virtual_keys[%d]: secret_key is empty", i)
+		}
+		if _, dup := snapshot.virtualKeys[vk.SecretKey]; dup {
+			return nil, fmt.Errorf("virtual_keys[%d]: secret_key is used twice", i)
+		}
+
+		allowed := make([]llm.ProviderName, len(vk.AllowedProviders))
+		for j, p := range vk.AllowedProviders {
+			allowed[j] = llm.ProviderName(p)
+		}
+		snapshot.virtualKeys[vk.SecretKey] = &VirtualKeyConfig{
+			SecretKey:        vk.SecretKey,
+			AllowedProviders: allowed,
+			AllowedModels:    vk.AllowedModels,
+			RateLimits:       vk.RateLimits,
+		}
+	}
+
+	return snapshot, nil
+}
+
+var envRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// interpolateEnv expands ${VAR} and ${VAR:-default} in every scalar of the
+// document. Expansion happens after parsing, so a value pulled from the
+// environment is always data, never YAML: an expanded scalar is a string,
+// however it reads, unless the file tags it explicitly. A reference to an
+// unset variable without a default is an error.
+func interpolateEnv(node *yaml.Node, lookupEnv func(string) (string, bool)) error {
+	if node.Kind == yaml.ScalarNode && strings.Contains(node.Value, "${") {
+		var missing []string
+		expanded := envRefPattern.ReplaceAllStringFunc(node.Value, func(ref string) string {
+			m := envRefPattern.FindStringSubmatch(ref)
+			if v, ok := lookupEnv(m[1]); ok {
+				return v
+			}
+			if strings.Contains(ref, ":-") {
+				return m[2]
+			}
+			missing = append(missing, m[1])
+			return ref
+		})
+		if len(missing) > 0 {
+			return fmt.Errorf("line %d: environment variable %s is not set", node.Line, strings.Join(missing, ", "))
+		}
+
+		node.Value = expanded
+		// An untagged `${VAR}` resolves as a string, so any other tag was
+		// written in the file, as in `weight: !!int ${W}`, and is kept.
+		// Everything else stays a string, so a key of "true" or "null" is
+		// not re-read as a boolean or an empty value.
+		if node.Tag == "" || node.Tag == "!" {
+			node.Tag = "!!str"
+		}
+		return nil
+	}
+
+	for _, child := range node.Content {
+		if err := interpolateEnv(child, lookupEnv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+package gateway
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	// Write-then-rename, as editors and config management tools do.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatalf("rename config: %v", err)
+	}
+}
+
+func envMap(vars map[string]string) func(string) (string, bool) {
+	return func(k string) (string, bool) {
+		v, ok := vars[k]
+		return v, ok
+	}
+}
+
+const baseConfigYAML = `
+providers:
+  - name: OpenAI
+    base_url: ${OPENAI_BASE_URL:-https://api.openai.com/v1}
+    headers:
+      X-Team: ${TEAM}
+    api_keys:
+      - name: primary
+        api_key: ${OPENAI_KEY}
+        weight: !!int ${PRIMARY_WEIGHT}
+        default: true
+        rate_limits:
+          - {unit: minute, limit: 500}
+      - name: retired
+        api_key: sk-retired
+        enabled: false
+virtual_keys:
+  - secret_key: vk-team-a
+    allowed_providers: [OpenAI]
+    allowed_models: [gpt-4.1-mini]
+`
+
+func TestFileConfigStore_LoadsYAMLWithEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gateway.yaml")
+	writeConfig(t, path, baseConfigYAML)
+
+	store, err := NewFileConfigStore(path, FileConfigStoreOptions{
+		PollInterval: -1,
+		LookupEnv: envMap(map[string]string{
+			"OPENAI_KEY":     "sk-live: with # odd chars",
+			"PRIMARY_WEIGHT": "3",
+			"TEAM":           "search",
+		}),
+	})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	defer store.Close()
+
+	pc, err := store.GetProviderConfig(context.Background(), "OpenAI", "")
+	if err != nil {
+		t.Fatalf("GetProviderConfig: %v", err)
+	}
+	if pc.BaseURL != "https://api.openai.com/v1" || pc.CustomHeaders["X-Team"] != "search" {
+		t.Fatalf("provider config = %+v", pc)
+	}
+	if len(pc.ApiKeys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(pc.ApiKeys))
+	}
+	primary, retired := pc.ApiKeys[0], pc.ApiKeys[1]
+	if primary.APIKey != "sk-live: with # odd chars" || primary.Weight != 3 || primary.Disabled || !primary.IsDefault {
+		t.Fatalf("primary key = %+v", primary)
+	}
+	if len(primary.RateLimits) != 1 || primary.RateLimits[0].Limit != 500 {
+		t.Fatalf("rate limits = %+v", primary.RateLimits)
+	}
+	if !retired.Disabled {
+		t.Fatalf("retired key should be disabled")
+	}
+
+	vk, err := store.GetVirtualKey(context.Background(), "vk-team-a")
+	if err != nil || vk.AllowedModels[0] != "gpt-4.1-mini" || vk.AllowedProviders[0] != "OpenAI" {
+		t.Fatalf("virtual key = %+v, %v", vk, err)
+	}
+	if _, err := store.GetVirtualKey(context.Background(), "vk-unknown"); err == nil {
+		t.Fatalf("expected an error for an unknown virtual key")
+	}
+}
+
+func TestFileConfigStore_LoadsJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gateway.json")
+	writeConfig(t, path, `{"providers": [{"name": "Anthropic", "api_keys": [{"api_key": "${KEY}"}]}]}`)
+
+	store, err := NewFileConfigStore(path, FileConfigStoreOptions{
+		PollInterval: -1,
+		LookupEnv:    envMap(map[string]string{"KEY": "ak-1"}),
+	})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	pc, err := store.GetProviderConfig(context.Background(), "Anthropic", "")
+	if err != nil || pc.ApiKeys[0].APIKey != "ak-1" || pc.ApiKeys[0].Disabled {
+		t.Fatalf("provider config = %+v, %v", pc, err)
+	}
+}
+
+func TestFileConfigStore_RejectsInvalidConfig(t *testing.T) {
+	cases := map[string]string{
+		"missing env":   `providers: [{name: OpenAI, api_keys: [{api_key: "${NOPE}"}]}]`,
+		"unknown field": `providers: [{name: OpenAI, api_key: sk-1}]`,
+		"duplicate":     `providers: [{name: OpenAI, api_keys: [{api_key: a}]}, {name: OpenAI, api_keys: [{api_key: b}]}]`,
+		"bad yaml":      `providers: [`,
+	}
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "gateway.yaml")
+			writeConfig(t, path, content)
+			if _, err := NewFileConfigStore(path, FileConfigStoreOptions{PollInterval: -1, LookupEnv: envMap(nil)}); err == nil {
+				t.Fatalf("expected load to fail")
+			}
+		})
+	}
+}
+
+// A change on disk is picked up by the watcher; a broken change is
+// rejected and the previous config stays in force.
+func TestFileConfigStore_HotReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gateway.yaml")
+	writeConfig(t, path, `providers: [{name: OpenAI, api_keys: [{api_key: sk-old}]}]`)
+
+	var mu sync.Mutex
+	var reloadErrs []error
+	reloaded := make(chan struct{}, 4)
+
+	store, err := NewFileConfigStore(path, FileConfigStoreOptions{
+		PollInterval: 5 * time.Millisecond,
+		LookupEnv:    envMap(nil),
+		OnReload: func(_ *FileConfig, err error) {
+			mu.Lock()
+			reloadErrs = append(reloadErrs, err)
+			mu.Unlock()
+			reloaded <- struct{}{}
+		},
+	})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	defer store.Close()
+
+	waitReload := func() error {
+		t.Helper()
+		select {
+		case <-reloaded:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no reload observed")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return reloadErrs[len(reloadErrs)-1]
+	}
+	currentKey := func() string {
+		pc, err := store.GetProviderConfig(context.Background(), "OpenAI", "")
+		if err != nil {
+			t.Fatalf("GetProviderConfig: %v", err)
+		}
+		return pc.ApiKeys[0].APIKey
+	}
+
+	writeConfig(t, path, `providers: [{name: OpenAI, api_keys: [{api_key: sk-new}]}]`)
+	if err := waitReload(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if got := currentKey(); got != "sk-new" {
+		t.Fatalf("key after reload = %q", got)
+	}
+
+	writeConfig(t, path, `providers: [{name: OpenAI, api_keys: [{api_key: ""}]}]`)
+	err = waitReload()
+	if err == nil || !strings.Contains(err.Error(), "api_key is empty") {
+		t.Fatalf("expected the reload to be rejected, got %v", err)
+	}
+	if got := currentKey(); got != "sk-new" {
+		t.Fatalf("rejected reload replaced the config: key = %q", got)
+	}
+}
+
+// Values from the environment are strings, however they read, unless the
+// file tags them; a key of "null" is not an empty key.
+func TestFileConfigStore_InterpolatedValuesStayStrings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gateway.yaml")
+	writeConfig(t, path, `
+providers:
+  - name: OpenAI
+    headers:
+      X-Debug: ${DEBUG}
+      X-Build: ${BUILD}
+    api_keys:
+      - api_key: ${KEY}
+        enabled: !!bool ${ENABLED}
+`)
+
+	store, err := NewFileConfigStore(path, FileConfigStoreOptions{
+		PollInterval: -1,
+		LookupEnv: envMap(map[string]string{
+			"KEY":     "null",
+			"DEBUG":   "true",
+			"BUILD":   "0123",
+			"ENABLED": "false",
+		}),
+	})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	defer store.Close()
+
+	pc := store.Config().Providers[0]
+	if pc.APIKeys[0].APIKey != "null" {
+		t.Fatalf("api_key = %q, want the literal string", pc.APIKeys[0].APIKey)
+	}
+	if pc.Headers["X-Debug"] != "true" || pc.Headers["X-Build"] != "0123" {
+		t.Fatalf("headers = %v", pc.Headers)
+	}
+	if enabled := pc.APIKeys[0].Enabled; enabled == nil || *enabled {
+		t.Fatalf("tagged enabled = %v, want false", enabled)
+	}
+}
+
+// Reload picks up a change to the environment even when the file itself is
+// unchanged.
+func TestFileConfigStore_ReloadPicksUpEnvChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gateway.yaml")
+	writeConfig(t, path, `providers: [{name: OpenAI, api_keys: [{api_key: "${OPENAI_KEY}"}]}]`)
+
+	env := map[string]string{"OPENAI_KEY": "sk-old"}
+	store, err := NewFileConfigStore(path, FileConfigStoreOptions{
+		PollInterval: -1,
+		LookupEnv:    envMap(env),
+	})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	defer store.Close()
+
+	env["OPENAI_KEY"] = "sk-rotated"
+	if err := store.Reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+
+	pc, err := store.GetProviderConfig(context.Background(), "OpenAI", "")
+	if err != nil {
+		t.Fatalf("GetProviderConfig: %v", err)
+	}
+	if got := pc.ApiKeys[0].APIKey; got != "sk-rotated" {
+		t.Fatalf("key after reload = %q, want the rotated one", got)
+	}
+}
@@ -3,6 +3,7 @@ package gateway
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
 	"go.opentelemetry.io/otel"
@@ -10,18 +11,28 @@ import (
 
 var tracer = otel.Tracer("LLMGateway")
 
+// ErrVirtualKeyNotFound is what a ConfigStore returns, wrapped or not, for a
+// key that is not a virtual key — a provider API key, say.
+var ErrVirtualKeyNotFound = errors.New("virtual key not found")
+
 // ConfigStore is the interface required by LLMGateway to get provider and virtual key configurations.
 type ConfigStore interface {
 	// GetProviderConfig returns provider configuration and associated API keys.
 	GetProviderConfig(ctx context.Context, providerName llm.ProviderName, key string) (*ProviderConfig, error)
 
-	// GetVirtualKey returns virtual key configuration for access control.
+	// GetVirtualKey returns virtual key configuration for access control,
+	// or an error wrapping ErrVirtualKeyNotFound if secretKey is not one.
 	GetVirtualKey(ctx context.Context, secretKey string) (*VirtualKeyConfig, error)
 }
 
 type LLMGateway struct {
 	ConfigStore ConfigStore
+	// ModelCacheTTL is how long ListModels and ListVoices reuse a
+	// provider's listing. Defaults to DefaultModelCacheTTL.
+	ModelCacheTTL time.Duration
+
 	middlewares []Middleware
+	catalog     modelCatalog
 }
 
 func NewLLMGateway(ConfigStore ConfigStore) *LLMGateway {
@@ -70,6 +81,20 @@ func (g *LLMGateway) baseRequestHandler(ctx context.Context, providerName llm.Pr
 			return nil, err
 		}
 
+		resp.OfResponsesOutput = respOut
+	case r.OfResponsesGet != nil:
+		respOut, err := g.handleResponsesGetRequest(ctx, providerName, p, r.OfResponsesGet)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.OfResponsesOutput = respOut
+	case r.OfResponsesCancel != nil:
+		respOut, err := g.handleResponsesCancelRequest(ctx, providerName, p, r.OfResponsesCancel)
+		if err != nil {
+			return nil, err
+		}
+
 		resp.OfResponsesOutput = respOut
 	case r.OfChatCompletionInput != nil:
 		respOut, err := g.handleChatCompletionRequest(ctx, providerName, p, r.OfChatCompletionInput)
@@ -106,6 +131,27 @@ func (g *LLMGateway) baseRequestHandler(ctx context.Context, providerName llm.Pr
 		}
 
 		resp.OfImageEdit = respOut
+	case r.OfVideoGeneration != nil:
+		respOut, err := g.handleVideoGenerationRequest(ctx, providerName, p, r.OfVideoGeneration)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.OfVideoGeneration = respOut
+	case r.OfVideoGenerationGet != nil:
+		respOut, err := g.handleVideoGenerationGetRequest(ctx, providerName, p, r.OfVideoGenerationGet)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.OfVideoGeneration = respOut
+	case r.OfVideoGenerationDownload != nil:
+		respOut, err := g.handleVideoGenerationDownloadRequest(ctx, providerName, p, r.OfVideoGenerationDownload)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.OfVideoContent = respOut
 	}
 
 	return resp, nil
@@ -143,6 +189,15 @@ func (g *LLMGateway) baseStreamingRequestHandler(ctx context.Context, providerNa
 		resp.ResponsesStreamData = respOut
 		return resp, nil
 
+	case r.OfResponsesStreamFrom != nil:
+		respOut, err := g.handleResponsesStreamFromRequest(ctx, providerName, p, r.OfResponsesStreamFrom)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.ResponsesStreamData = respOut
+		return resp, nil
+
 	case r.OfChatCompletionInput != nil:
 		respOut, err := g.handleStreamingChatCompletionRequest(ctx, providerName, p, r.OfChatCompletionInput)
 		if err != nil {
@@ -160,6 +215,15 @@ func (g *LLMGateway) baseStreamingRequestHandler(ctx context.Context, providerNa
 
 		resp.SpeechStreamData = respOut
 		return resp, nil
+
+	case r.OfTranscription != nil:
+		respOut, err := g.handleStreamingTranscriptionRequest(ctx, providerName, p, r.OfTranscription)
+		if err != nil {
+			return nil, err
+		}
+
+		resp.TranscriptionStreamData = respOut
+		return resp, nil
 	}
 
 	return nil, errors.New("invalid request")
@@ -11,6 +11,7 @@ import (
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/speech"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/video_generation"
 )
 
 // InternalLLMGateway uses the internal LLMGatewayAdapter for server-side use.
@@ -133,6 +134,19 @@ func (p *InternalLLMGateway) NewTranscription(ctx context.Context, providerName
 	return resp.OfTranscription, nil
 }
 
+func (p *InternalLLMGateway) NewStreamingTranscription(ctx context.Context, providerName llm.ProviderName, key string, req *transcription.Request) (chan *transcription.ResponseChunk, error) {
+	llmReq := &llm.Request{
+		OfTranscription: req,
+	}
+
+	resp, err := p.gateway.HandleStreamingRequest(ctx, providerName, key, llmReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.TranscriptionStreamData, nil
+}
+
 func (p *InternalLLMGateway) NewImageGeneration(ctx context.Context, providerName llm.ProviderName, key string, req *image_generation.Request) (*image_generation.Response, error) {
 	llmReq := &llm.Request{
 		OfImageGeneration: req,
@@ -158,3 +172,42 @@ func (p *InternalLLMGateway) NewImageEdit(ctx context.Context, providerName llm.
 
 	return resp.OfImageEdit, nil
 }
+
+func (p *InternalLLMGateway) NewVideoGeneration(ctx context.Context, providerName llm.ProviderName, key string, req *video_generation.Request) (*video_generation.Response, error) {
+	llmReq := &llm.Request{
+		OfVideoGeneration: req,
+	}
+
+	resp, err := p.gateway.HandleRequest(ctx, providerName, key, llmReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.OfVideoGeneration, nil
+}
+
+func (p *InternalLLMGateway) GetVideoGeneration(ctx context.Context, providerName llm.ProviderName, key string, req *video_generation.GetRequest) (*video_generation.Response, error) {
+	llmReq := &llm.Request{
+		OfVideoGenerationGet: req,
+	}
+
+	resp, err := p.gateway.HandleRequest(ctx, providerName, key, llmReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.OfVideoGeneration, nil
+}
+
+func (p *InternalLLMGateway) DownloadVideoGeneration(ctx context.Context, providerName llm.ProviderName, key string, req *video_generation.DownloadRequest) (*video_generation.Content, error) {
+	llmReq := &llm.Request{
+		OfVideoGenerationDownload: req,
+	}
+
+	resp, err := p.gateway.HandleRequest(ctx, providerName, key, llmReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.OfVideoContent, nil
+}
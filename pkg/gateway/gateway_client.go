@@ -13,6 +13,7 @@ import (
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/speech"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/video_generation"
 	utils2 "github.com/hastekit/agent-sdk-go/pkg/utils"
 )
 
@@ -45,17 +46,30 @@ type LLMGatewayAdapter interface {
 	// NewTranscription
 	NewTranscription(ctx context.Context, providerName llm.ProviderName, key string, req *transcription.Request) (*transcription.Response, error)
 
+	// NewStreamingTranscription
+	NewStreamingTranscription(ctx context.Context, providerName llm.ProviderName, key string, req *transcription.Request) (chan *transcription.ResponseChunk, error)
+
 	// NewImageGeneration
 	NewImageGeneration(ctx context.Context, providerName llm.ProviderName, key string, req *image_generation.Request) (*image_generation.Response, error)
 
 	// NewImageEdit
 	NewImageEdit(ctx context.Context, providerName llm.ProviderName, key string, req *image_edit.Request) (*image_edit.Response, error)
+
+	// NewVideoGeneration starts a video generation job
+	NewVideoGeneration(ctx context.Context, providerName llm.ProviderName, key string, req *video_generation.Request) (*video_generation.Response, error)
+
+	// GetVideoGeneration polls a video generation job
+	GetVideoGeneration(ctx context.Context, providerName llm.ProviderName, key string, req *video_generation.GetRequest) (*video_generation.Response, error)
+
+	// DownloadVideoGeneration fetches the video of a completed job
+	DownloadVideoGeneration(ctx context.Context, providerName llm.ProviderName, key string, req *video_generation.DownloadRequest) (*video_generation.Content, error)
 }
 
 // LLMClient wraps an LLMGatewayAdapter and provides a high-level interface
 type LLMClient struct {
 	LLMGatewayAdapter
 	configStore ConfigStore
+	health      *HealthTracker
 
 	provider llm.ProviderName
 	key      string
@@ -77,6 +91,15 @@ func WithModel(providerName llm.ProviderName, model string) LLMClientOption {
 	}
 }
 
+// WithHealthTracker routes key selection around keys whose circuit is open.
+// The same tracker should be installed as middleware on the gateway, which
+// is where it learns about failures.
+func WithHealthTracker(t *HealthTracker) LLMClientOption {
+	return func(c *LLMClient) {
+		c.health = t
+	}
+}
+
 // NewLLMClient creates a new LLM client with the given provider.
 func NewLLMClient(p LLMGatewayAdapter, configStore ConfigStore, opts ...LLMClientOption) *LLMClient {
 	cli := &LLMClient{
@@ -91,6 +114,12 @@ func NewLLMClient(p LLMGatewayAdapter, configStore ConfigStore, opts ...LLMClien
 	return cli
 }
 
+// ProviderName is the provider the client is bound to, or "" for a client
+// that takes it from each request's model.
+func (c *LLMClient) ProviderName() llm.ProviderName {
+	return c.provider
+}
+
 func (c *LLMClient) NewResponses(ctx context.Context, in *responses.Request) (*responses.Response, error) {
 	providerName, model, err := c.getProviderAndModelName(in.Model)
 	if err != nil {
@@ -176,6 +205,16 @@ func (c *LLMClient) NewTranscription(ctx context.Context, in *transcription.Requ
 	return c.LLMGatewayAdapter.NewTranscription(ctx, providerName, c.getKey(ctx, providerName), in)
 }
 
+func (c *LLMClient) NewStreamingTranscription(ctx context.Context, in *transcription.Request) (chan *transcription.ResponseChunk, error) {
+	providerName, model, err := c.getProviderAndModelName(in.Model)
+	if err != nil {
+		return nil, err
+	}
+	in.Model = model
+
+	return c.LLMGatewayAdapter.NewStreamingTranscription(ctx, providerName, c.getKey(ctx, providerName), in)
+}
+
 func (c *LLMClient) NewImageGeneration(ctx context.Context, in *image_generation.Request) (*image_generation.Response, error) {
 	providerName, model, err := c.getProviderAndModelName(in.Model)
 	if err != nil {
@@ -196,6 +235,36 @@ func (c *LLMClient) NewImageEdit(ctx context.Context, in *image_edit.Request) (*
 	return c.LLMGatewayAdapter.NewImageEdit(ctx, providerName, c.getKey(ctx, providerName), in)
 }
 
+func (c *LLMClient) NewVideoGeneration(ctx context.Context, in *video_generation.Request) (*video_generation.Response, error) {
+	providerName, model, err := c.getProviderAndModelName(in.Model)
+	if err != nil {
+		return nil, err
+	}
+	in.Model = model
+
+	return c.LLMGatewayAdapter.NewVideoGeneration(ctx, providerName, c.getKey(ctx, providerName), in)
+}
+
+func (c *LLMClient) GetVideoGeneration(ctx context.Context, in *video_generation.GetRequest) (*video_generation.Response, error) {
+	providerName, model, err := c.getProviderAndModelName(in.Model)
+	if err != nil {
+		return nil, err
+	}
+	in.Model = model
+
+	return c.LLMGatewayAdapter.GetVideoGeneration(ctx, providerName, c.getKey(ctx, providerName), in)
+}
+
+func (c *LLMClient) DownloadVideoGeneration(ctx context.Context, in *video_generation.DownloadRequest) (*video_generation.Content, error) {
+	providerName, model, err := c.getProviderAndModelName(in.Model)
+	if err != nil {
+		return nil, err
+	}
+	in.Model = model
+
+	return c.LLMGatewayAdapter.DownloadVideoGeneration(ctx, providerName, c.getKey(ctx, providerName), in)
+}
+
 func (c *LLMClient) getKey(ctx context.Context, providerName llm.ProviderName) string {
 	if c.key != "" {
 		return c.key
@@ -210,21 +279,48 @@ func (c *LLMClient) getKey(ctx context.Context, providerName llm.ProviderName) s
 		return ""
 	}
 
-	if len(providerConfig.ApiKeys) == 0 {
+	// Disabled keys are never handed out.
+	enabled := enabledKeys(providerConfig.ApiKeys)
+	if len(enabled) == 0 {
 		return ""
 	}
 
-	if len(providerConfig.ApiKeys) == 1 {
-		return providerConfig.ApiKeys[0].APIKey
+	// Keys whose circuit is open sit out until their cooldown ends.
+	healthy := enabled
+	if c.health != nil {
+		healthy = nil
+		for _, key := range enabled {
+			if c.health.Available(providerName, key.APIKey) {
+				healthy = append(healthy, key)
+			}
+		}
+	}
+
+	switch len(healthy) {
+	case 0:
+		// Every key is tripped. Fall back to the default key and let the
+		// health middleware fail fast until a probe is due.
+		return defaultKey(enabled).APIKey
+	case 1:
+		return healthy[0].APIKey
 	}
 
 	// Weight random selection
-	weights := make([]int, len(providerConfig.ApiKeys))
-	for idx, key := range providerConfig.ApiKeys {
+	weights := make([]int, len(healthy))
+	for idx, key := range healthy {
 		weights[idx] = key.Weight
 	}
 
-	return providerConfig.ApiKeys[utils2.WeightedRandomIndex(weights)].APIKey
+	return healthy[utils2.WeightedRandomIndex(weights)].APIKey
+}
+
+func defaultKey(keys []*APIKeyConfig) *APIKeyConfig {
+	for _, key := range keys {
+		if key.IsDefault {
+			return key
+		}
+	}
+	return keys[0]
 }
 
 func (c *LLMClient) getProviderAndModelName(input string) (llm.ProviderName, string, error) {
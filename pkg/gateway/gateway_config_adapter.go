@@ -44,5 +44,5 @@ func (s *InMemoryConfigStore) GetProviderConfig(_ context.Context, providerName
 
 func (s *InMemoryConfigStore) GetVirtualKey(_ context.Context, secretKey string) (*VirtualKeyConfig, error) {
 	// In-memory store doesn't support virtual keys - they're managed by agent-server
-	return nil, fmt.Errorf("virtual keys are not supported in direct mode")
+	return nil, fmt.Errorf("virtual keys are not supported in direct mode: %w", ErrVirtualKeyNotFound)
 }
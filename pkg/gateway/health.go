@@ -0,0 +1,441 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var meter = otel.Meter("LLMGateway")
+
+// ErrCircuitOpen is returned, wrapped, for requests the HealthTracker turns
+// away because the key or provider they target is failing.
+var ErrCircuitOpen = errors.New("circuit open")
+
+type CircuitState string
+
+const (
+	// CircuitClosed lets every request through.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen turns requests away until the cooldown ends.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single probe through; its outcome closes or
+	// re-opens the circuit.
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+type HealthOptions struct {
+	// FailureThreshold is the number of consecutive failures that opens a
+	// circuit. Defaults to 5. Authentication and rate-limit errors open a
+	// key's circuit on the first occurrence.
+	FailureThreshold int
+
+	// OpenDuration is how long an open circuit turns requests away before
+	// letting a probe through. Defaults to 30s.
+	OpenDuration time.Duration
+}
+
+// CircuitHealth is a point-in-time view of one circuit. Key is empty for a
+// provider's circuit and masked for a key's.
+type CircuitHealth struct {
+	Provider            llm.ProviderName `json:"provider"`
+	Key                 string           `json:"key,omitempty"`
+	State               CircuitState     `json:"state"`
+	ConsecutiveFailures int              `json:"consecutive_failures"`
+	LastError           string           `json:"last_error,omitempty"`
+	LastStatusCode      int              `json:"last_status_code,omitempty"`
+	OpenedAt            time.Time        `json:"opened_at,omitzero"`
+	RetryAt             time.Time        `json:"retry_at,omitzero"`
+}
+
+type circuitID struct {
+	provider llm.ProviderName
+	key      string
+}
+
+type circuit struct {
+	state      CircuitState
+	failures   int
+	lastErr    string
+	lastStatus int
+	openedAt   time.Time
+	probing    bool
+}
+
+// HealthTracker keeps a circuit breaker per provider and per API key. As a
+// Middleware it turns away requests to open circuits and records the outcome
+// of the rest; LLMClient consults it (see WithHealthTracker) to route
+// around keys whose circuit is open.
+//
+// Errors are classified by HTTP status where the provider reports one:
+// 401, 403 and 429 open the key's circuit at once, other 4xx are the
+// request's fault and count as the key working, and 5xx or transport errors
+// count against both the key and the provider.
+type HealthTracker struct {
+	opts HealthOptions
+	now  func() time.Time
+
+	mu        sync.Mutex
+	circuits  map[circuitID]*circuit
+	providers map[llm.ProviderName]*circuit
+
+	transitions metric.Int64Counter
+	// gauge is the state gauge's callback, unregistered by Close so a
+	// discarded tracker is not observed, and kept alive, forever.
+	gauge metric.Registration
+}
+
+var _ Middleware = (*HealthTracker)(nil)
+
+func NewHealthTracker(opts HealthOptions) *HealthTracker {
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = 5
+	}
+	if opts.OpenDuration <= 0 {
+		opts.OpenDuration = 30 * time.Second
+	}
+
+	t := &HealthTracker{
+		opts:      opts,
+		now:       time.Now,
+		circuits:  make(map[circuitID]*circuit),
+		providers: make(map[llm.ProviderName]*circuit),
+	}
+	t.registerMetrics()
+
+	return t
+}
+
+func (t *HealthTracker) registerMetrics() {
+	var err error
+	t.transitions, err = meter.Int64Counter("llm.gateway.circuit.transitions",
+		metric.WithDescription("Circuit breaker state changes, by the state entered"))
+	if err != nil {
+		slog.Warn("failed to create circuit transition counter", slog.Any("error", err))
+	}
+
+	state, err := meter.Int64ObservableGauge("llm.gateway.circuit.state",
+		metric.WithDescription("Circuit breaker state: 0 closed, 1 half-open, 2 open"))
+	if err != nil {
+		slog.Warn("failed to create circuit state gauge", slog.Any("error", err))
+		return
+	}
+
+	t.gauge, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		for _, h := range t.Snapshot() {
+			o.ObserveInt64(state, stateValue(h.State), metric.WithAttributes(circuitAttributes(h.Provider, h.Key)...))
+		}
+		return nil
+	}, state)
+	if err != nil {
+		slog.Warn("failed to register circuit state callback", slog.Any("error", err))
+	}
+}
+
+// Close stops reporting the tracker's circuits in the state gauge. The
+// tracker keeps working as middleware; it just is no longer observed.
+func (t *HealthTracker) Close() error {
+	t.mu.Lock()
+	gauge := t.gauge
+	t.gauge = nil
+	t.mu.Unlock()
+
+	if gauge == nil {
+		return nil
+	}
+	return gauge.Unregister()
+}
+
+// Available reports whether a request to the key would be let through right
+// now, without claiming a probe slot. Key may be empty to ask about the
+// provider alone.
+func (t *HealthTracker) Available(provider llm.ProviderName, key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.available(t.providers[provider]) {
+		return false
+	}
+	if key == "" {
+		return true
+	}
+	return t.available(t.circuits[circuitID{provider, key}])
+}
+
+// Snapshot returns every circuit the tracker has seen, providers first.
+func (t *HealthTracker) Snapshot() []CircuitHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make([]CircuitHealth, 0, len(t.providers)+len(t.circuits))
+	for provider, c := range t.providers {
+		out = append(out, t.health(provider, "", c))
+	}
+	for id, c := range t.circuits {
+		out = append(out, t.health(id.provider, maskKey(id.key), c))
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Provider != out[j].Provider {
+			return out[i].Provider < out[j].Provider
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+func (t *HealthTracker) health(provider llm.ProviderName, key string, c *circuit) CircuitHealth {
+	h := CircuitHealth{
+		Provider:            provider,
+		Key:                 key,
+		State:               c.state,
+		ConsecutiveFailures: c.failures,
+		LastError:           c.lastErr,
+		LastStatusCode:      c.lastStatus,
+	}
+	if c.state == CircuitOpen {
+		h.OpenedAt = c.openedAt
+		h.RetryAt = c.openedAt.Add(t.opts.OpenDuration)
+	}
+	return h
+}
+
+func (t *HealthTracker) HandleRequest(next RequestHandler) RequestHandler {
+	return func(ctx context.Context, providerName llm.ProviderName, key string, r *llm.Request) (*llm.Response, error) {
+		if err := t.acquire(providerName, key); err != nil {
+			return nil, err
+		}
+
+		resp, err := next(ctx, providerName, key, r)
+		t.record(ctx, providerName, key, err)
+		return resp, err
+	}
+}
+
+// HandleStreamingRequest judges a stream by whether it opens: that is where
+// providers report bad keys, throttling and outages.
+func (t *HealthTracker) HandleStreamingRequest(next StreamingRequestHandler) StreamingRequestHandler {
+	return func(ctx context.Context, providerName llm.ProviderName, key string, r *llm.Request) (*llm.StreamingResponse, error) {
+		if err := t.acquire(providerName, key); err != nil {
+			return nil, err
+		}
+
+		resp, err := next(ctx, providerName, key, r)
+		t.record(ctx, providerName, key, err)
+		return resp, err
+	}
+}
+
+func (t *HealthTracker) available(c *circuit) bool {
+	if c == nil {
+		return true
+	}
+	switch c.state {
+	case CircuitOpen:
+		return !t.now().Before(c.openedAt.Add(t.opts.OpenDuration))
+	case CircuitHalfOpen:
+		return !c.probing
+	}
+	return true
+}
+
+// acquire lets a request through or returns a wrapped ErrCircuitOpen. A
+// request reaching a circuit whose cooldown has ended becomes its probe.
+func (t *HealthTracker) acquire(provider llm.ProviderName, key string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pc := t.providerCircuit(provider)
+	if !t.available(pc) {
+		return fmt.Errorf("%w: provider %s is unavailable", ErrCircuitOpen, provider)
+	}
+
+	var kc *circuit
+	if key != "" {
+		kc = t.keyCircuit(provider, key)
+		if !t.available(kc) {
+			return fmt.Errorf("%w: key %s for provider %s is unavailable", ErrCircuitOpen, maskKey(key), provider)
+		}
+	}
+
+	t.claim(provider, "", pc)
+	if kc != nil {
+		t.claim(provider, key, kc)
+	}
+	return nil
+}
+
+func (t *HealthTracker) claim(provider llm.ProviderName, key string, c *circuit) {
+	if c.state == CircuitOpen {
+		t.transition(provider, key, c, CircuitHalfOpen)
+	}
+	if c.state == CircuitHalfOpen {
+		c.probing = true
+	}
+}
+
+type healthOutcome int
+
+const (
+	outcomeIgnored healthOutcome = iota
+	outcomeSuccess
+	outcomeKeyRejected
+	outcomeFailure
+)
+
+func classifyHealthOutcome(ctx context.Context, err error) (healthOutcome, int) {
+	if err == nil {
+		return outcomeSuccess, 0
+	}
+
+	// The caller gave up; that says nothing about the provider.
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return outcomeIgnored, 0
+	}
+
+	var withStatus interface{ HTTPStatusCode() int }
+	if !errors.As(err, &withStatus) {
+		return outcomeFailure, 0
+	}
+
+	status := withStatus.HTTPStatusCode()
+	switch {
+	case status == http.StatusUnauthorized, status == http.StatusForbidden, status == http.StatusTooManyRequests:
+		return outcomeKeyRejected, status
+	case status >= 500:
+		return outcomeFailure, status
+	}
+	return outcomeSuccess, status
+}
+
+func (t *HealthTracker) record(ctx context.Context, provider llm.ProviderName, key string, err error) {
+	outcome, status := classifyHealthOutcome(ctx, err)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pc := t.providerCircuit(provider)
+	var kc *circuit
+	if key != "" {
+		kc = t.keyCircuit(provider, key)
+	}
+
+	switch outcome {
+	case outcomeIgnored:
+		pc.probing = false
+		if kc != nil {
+			kc.probing = false
+		}
+
+	case outcomeSuccess:
+		t.succeed(provider, "", pc)
+		if kc != nil {
+			t.succeed(provider, key, kc)
+		}
+
+	case outcomeKeyRejected:
+		// The provider answered, so it is up; the key is what's wrong.
+		t.succeed(provider, "", pc)
+		if kc != nil {
+			t.fail(provider, key, kc, err, status, true)
+		}
+
+	case outcomeFailure:
+		t.fail(provider, "", pc, err, status, false)
+		if kc != nil {
+			t.fail(provider, key, kc, err, status, false)
+		}
+	}
+}
+
+func (t *HealthTracker) succeed(provider llm.ProviderName, key string, c *circuit) {
+	c.failures = 0
+	c.probing = false
+	if c.state != CircuitClosed {
+		t.transition(provider, key, c, CircuitClosed)
+	}
+}
+
+func (t *HealthTracker) fail(provider llm.ProviderName, key string, c *circuit, err error, status int, tripNow bool) {
+	c.failures++
+	c.probing = false
+	c.lastErr = err.Error()
+	c.lastStatus = status
+
+	if c.state == CircuitHalfOpen || tripNow || c.failures >= t.opts.FailureThreshold {
+		c.openedAt = t.now()
+		if c.state != CircuitOpen {
+			t.transition(provider, key, c, CircuitOpen)
+		}
+	}
+}
+
+func (t *HealthTracker) transition(provider llm.ProviderName, key string, c *circuit, to CircuitState) {
+	c.state = to
+
+	masked := maskKey(key)
+	if to == CircuitOpen {
+		slog.Warn("llm circuit opened",
+			slog.String("provider", string(provider)),
+			slog.String("key", masked),
+			slog.Int("consecutive_failures", c.failures),
+			slog.String("error", c.lastErr),
+		)
+	}
+
+	if t.transitions != nil {
+		attrs := append(circuitAttributes(provider, masked), attribute.String("state", string(to)))
+		t.transitions.Add(context.Background(), 1, metric.WithAttributes(attrs...))
+	}
+}
+
+func (t *HealthTracker) providerCircuit(provider llm.ProviderName) *circuit {
+	c, ok := t.providers[provider]
+	if !ok {
+		c = &circuit{state: CircuitClosed}
+		t.providers[provider] = c
+	}
+	return c
+}
+
+func (t *HealthTracker) keyCircuit(provider llm.ProviderName, key string) *circuit {
+	id := circuitID{provider, key}
+	c, ok := t.circuits[id]
+	if !ok {
+		c = &circuit{state: CircuitClosed}
+		t.circuits[id] = c
+	}
+	return c
+}
+
+func circuitAttributes(provider llm.ProviderName, maskedKey string) []attribute.KeyValue {
+	scope := "provider"
+	if maskedKey != "" {
+		scope = "key"
+	}
+	return []attribute.KeyValue{
+		attribute.String("provider", string(provider)),
+		attribute.String("key", maskedKey),
+		attribute.String("scope", scope),
+	}
+}
+
+func stateValue(s CircuitState) int64 {
+	switch s {
+	case CircuitHalfOpen:
+		return 1
+	case CircuitOpen:
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,265 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/base"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestTracker(threshold int) (*HealthTracker, *fakeClock) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	t := NewHealthTracker(HealthOptions{FailureThreshold: threshold, OpenDuration: time.Minute})
+	t.now = clock.now
+	return t, clock
+}
+
+// handlerReturning builds a next handler whose error the test can change
+// between calls.
+func handlerReturning(err *error) RequestHandler {
+	return func(context.Context, llm.ProviderName, string, *llm.Request) (*llm.Response, error) {
+		if *err != nil {
+			return nil, *err
+		}
+		return &llm.Response{}, nil
+	}
+}
+
+func circuitFor(t *testing.T, tracker *HealthTracker, key string) CircuitHealth {
+	t.Helper()
+	for _, h := range tracker.Snapshot() {
+		if h.Key == key {
+			return h
+		}
+	}
+	t.Fatalf("no circuit for key %q in %+v", key, tracker.Snapshot())
+	return CircuitHealth{}
+}
+
+func TestHealthTracker_OpensHalfOpensAndCloses(t *testing.T) {
+	tracker, clock := newTestTracker(3)
+	var upstreamErr error = &base.HTTPError{StatusCode: http.StatusBadGateway, Message: "bad gateway"}
+	handler := tracker.HandleRequest(handlerReturning(&upstreamErr))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := handler(ctx, "openai", "key-a-0123456789", &llm.Request{}); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d turned away before the threshold", i)
+		}
+	}
+
+	if _, err := handler(ctx, "openai", "key-a-0123456789", &llm.Request{}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen after 3 failures, got %v", err)
+	}
+	if h := circuitFor(t, tracker, ""); h.State != CircuitOpen || h.LastStatusCode != http.StatusBadGateway {
+		t.Fatalf("provider circuit = %+v", h)
+	}
+
+	// After the cooldown exactly one probe goes through.
+	clock.advance(time.Minute)
+	if !tracker.Available("openai", "key-a-0123456789") {
+		t.Fatalf("circuit should be available for a probe after the cooldown")
+	}
+	if err := tracker.acquire("openai", "key-a-0123456789"); err != nil {
+		t.Fatalf("probe turned away: %v", err)
+	}
+	if tracker.Available("openai", "key-a-0123456789") {
+		t.Fatalf("a second request got through while the probe is in flight")
+	}
+	tracker.record(ctx, "openai", "key-a-0123456789", upstreamErr)
+	if h := circuitFor(t, tracker, ""); h.State != CircuitOpen {
+		t.Fatalf("failed probe should re-open the circuit, got %s", h.State)
+	}
+
+	clock.advance(time.Minute)
+	upstreamErr = nil
+	if _, err := handler(ctx, "openai", "key-a-0123456789", &llm.Request{}); err != nil {
+		t.Fatalf("probe failed: %v", err)
+	}
+	for _, h := range tracker.Snapshot() {
+		if h.State != CircuitClosed || h.ConsecutiveFailures != 0 {
+			t.Fatalf("successful probe should close every circuit, got %+v", h)
+		}
+	}
+}
+
+func TestHealthTracker_ClassifiesErrors(t *testing.T) {
+	tracker, _ := newTestTracker(1)
+	ctx := context.Background()
+
+	// A bad request is the caller's fault, not the key's.
+	tracker.record(ctx, "openai", "key-a-0123456789", &base.HTTPError{StatusCode: http.StatusBadRequest})
+	// Neither is a caller walking away.
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	tracker.record(canceled, "openai", "key-a-0123456789", context.Canceled)
+	if !tracker.Available("openai", "key-a-0123456789") {
+		t.Fatalf("400 or cancellation should not open the circuit")
+	}
+
+	// A rejected key trips only that key; the provider is fine.
+	tracker.record(ctx, "openai", "key-a-0123456789", &base.HTTPError{StatusCode: http.StatusUnauthorized})
+	if tracker.Available("openai", "key-a-0123456789") {
+		t.Fatalf("401 should open the key's circuit")
+	}
+	if !tracker.Available("openai", "") || !tracker.Available("openai", "key-b-0123456789") {
+		t.Fatalf("401 should not affect the provider or other keys")
+	}
+}
+
+func TestLLMClient_GetKeySkipsDisabledAndOpenKeys(t *testing.T) {
+	tracker, _ := newTestTracker(5)
+	store := NewInMemoryConfigStore([]ProviderConfig{{
+		ProviderName: "openai",
+		ApiKeys: []*APIKeyConfig{
+			{APIKey: "key-disabled-0123", Weight: 100, Disabled: true},
+			{APIKey: "key-throttled-0123", Weight: 100},
+			{APIKey: "key-healthy-0123", Weight: 1},
+			{APIKey: "key-default-0123", Weight: 1, IsDefault: true},
+		},
+	}})
+	client := NewLLMClient(nil, store, WithHealthTracker(tracker))
+	ctx := context.Background()
+
+	tracker.record(ctx, "openai", "key-throttled-0123", &base.HTTPError{StatusCode: http.StatusTooManyRequests})
+
+	for i := 0; i < 50; i++ {
+		switch got := client.getKey(ctx, "openai"); got {
+		case "key-healthy-0123", "key-default-0123":
+		default:
+			t.Fatalf("selected %q", got)
+		}
+	}
+
+	// With every enabled key tripped, the default key is handed out.
+	tracker.record(ctx, "openai", "key-healthy-0123", &base.HTTPError{StatusCode: http.StatusUnauthorized})
+	tracker.record(ctx, "openai", "key-default-0123", &base.HTTPError{StatusCode: http.StatusUnauthorized})
+	if got := client.getKey(ctx, "openai"); got != "key-default-0123" {
+		t.Fatalf("selected %q, want the default key", got)
+	}
+}
+
+// A key is enabled unless it says otherwise, so configs that never heard of
+// Disabled keep working; a disabled key is never selected, even when it is
+// the only one.
+func TestInMemoryConfigStore_DisabledKeysNeverSelected(t *testing.T) {
+	client := NewLLMClient(nil, NewInMemoryConfigStore([]ProviderConfig{{ProviderName: "openai", ApiKeys: []*APIKeyConfig{
+		{APIKey: "only-key"},
+	}}}))
+	if got := client.getKey(context.Background(), "openai"); got != "only-key" {
+		t.Fatalf("selected %q", got)
+	}
+
+	client = NewLLMClient(nil, NewInMemoryConfigStore([]ProviderConfig{{ProviderName: "openai", ApiKeys: []*APIKeyConfig{
+		{APIKey: "revoked-key", Disabled: true, IsDefault: true},
+		{APIKey: "retired-key", Disabled: true},
+	}}}))
+	if got := client.getKey(context.Background(), "openai"); got != "" {
+		t.Fatalf("selected disabled key %q", got)
+	}
+}
+
+// Callers still setting the deprecated Enabled flag keep its meaning: once a
+// provider's keys use it, the keys that leave it unset are not selected.
+func TestInMemoryConfigStore_DeprecatedEnabledIsHonored(t *testing.T) {
+	client := NewLLMClient(nil, NewInMemoryConfigStore([]ProviderConfig{{ProviderName: "openai", ApiKeys: []*APIKeyConfig{
+		{APIKey: "revoked-key", Weight: 100},
+		{APIKey: "live-key", Weight: 1, Enabled: true},
+	}}}))
+	for range 20 {
+		if got := client.getKey(context.Background(), "openai"); got != "live-key" {
+			t.Fatalf("selected %q, want the enabled key", got)
+		}
+	}
+
+	client = NewLLMClient(nil, NewInMemoryConfigStore([]ProviderConfig{{ProviderName: "openai", ApiKeys: []*APIKeyConfig{
+		{APIKey: "flagged-key", Enabled: true, Disabled: true},
+	}}}))
+	if got := client.getKey(context.Background(), "openai"); got != "" {
+		t.Fatalf("selected %q, want Disabled to win over Enabled", got)
+	}
+}
+
+var (
+	gwMetricReader     *sdkmetric.ManualReader
+	gwMetricReaderOnce sync.Once
+)
+
+// withMetricReader installs a manual reader as the global meter provider.
+// Like the tracer, the package `meter` delegates to whichever provider is set
+// first, so it is set exactly once.
+func withMetricReader(t *testing.T) *sdkmetric.ManualReader {
+	t.Helper()
+	gwMetricReaderOnce.Do(func() {
+		gwMetricReader = sdkmetric.NewManualReader()
+		otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(gwMetricReader)))
+	})
+	return gwMetricReader
+}
+
+// observedProviders collects the state gauge once and returns the providers
+// it reported.
+func observedProviders(t *testing.T, reader *sdkmetric.ManualReader) map[string]bool {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	providers := map[string]bool{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			gauge, ok := m.Data.(metricdata.Gauge[int64])
+			if m.Name != "llm.gateway.circuit.state" || !ok {
+				continue
+			}
+			for _, dp := range gauge.DataPoints {
+				if v, ok := dp.Attributes.Value("provider"); ok {
+					providers[v.AsString()] = true
+				}
+			}
+		}
+	}
+	return providers
+}
+
+// A closed tracker drops out of the state gauge, so clients that come and go
+// do not pile up callbacks on the global meter.
+func TestHealthTracker_CloseUnregistersStateGauge(t *testing.T) {
+	reader := withMetricReader(t)
+	ctx := context.Background()
+
+	closed := NewHealthTracker(HealthOptions{})
+	live := NewHealthTracker(HealthOptions{})
+	defer live.Close()
+	closed.record(ctx, "closed-provider", "key-closed-0123", nil)
+	live.record(ctx, "live-provider", "key-live-0123", nil)
+
+	if got := observedProviders(t, reader); !got["closed-provider"] || !got["live-provider"] {
+		t.Fatalf("observed %v before close, want both trackers", got)
+	}
+
+	if err := closed.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := closed.Close(); err != nil {
+		t.Fatalf("second close: %v", err)
+	}
+
+	got := observedProviders(t, reader)
+	if got["closed-provider"] || !got["live-provider"] {
+		t.Fatalf("observed %v after close, want only the live tracker", got)
+	}
+}
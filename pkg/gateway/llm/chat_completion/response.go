@@ -22,12 +22,31 @@ type Choice struct {
 }
 
 type OutputMessage struct {
-	Role         constants.Role                   `json:"role"`
-	Refusal      string                           `json:"refusal"`
-	Content      string                           `json:"content"`
-	FunctionCall AssistantMessageFunctionCall     `json:"function_call"`
-	ToolCalls    []ToolChatCompletionMessageUnion `json:"tool_calls"`
-	Audio        OutputMessageAudio               `json:"audio"`
+	Role         constants.Role               `json:"role"`
+	Refusal      string                       `json:"refusal"`
+	Content      string                       `json:"content"`
+	FunctionCall AssistantMessageFunctionCall `json:"function_call"`
+	ToolCalls    []ToolCall                   `json:"tool_calls"`
+	Audio        OutputMessageAudio           `json:"audio"`
+}
+
+// ToolCall is a function call the model made.
+type ToolCall struct {
+	// Index is present on streaming deltas only, and is what ties argument
+	// fragments to the call they belong to.
+	Index    *int                         `json:"index,omitempty"`
+	ID       string                       `json:"id,omitempty"`
+	Type     string                       `json:"type,omitempty"` // "function"
+	Function AssistantMessageFunctionCall `json:"function"`
+}
+
+// Error is an error the provider reported in place of, or in the middle of,
+// a completion.
+type Error struct {
+	Type    string `json:"type,omitempty"`
+	Message string `json:"message"`
+	Param   string `json:"param,omitempty"`
+	Code    string `json:"code,omitempty"`
 }
 
 type OutputMessageAudio struct {
@@ -32,6 +32,7 @@ type ChatCompletionChunk struct {
 	Choices           []ChatCompletionChunkChoice `json:"choices"`
 	Obfuscation       string                      `json:"obfuscation"`
 	Usage             *Usage                      `json:"usage,omitempty"`
+	Error             *Error                      `json:"error,omitempty"`
 }
 
 type ChatCompletionChunkChoice struct {
@@ -42,7 +43,8 @@ type ChatCompletionChunkChoice struct {
 }
 
 type ChatCompletionChunkChoiceDelta struct {
-	Role    constants.Role `json:"role"`
-	Content string         `json:"content"`
-	Refusal string         `json:"refusal"`
+	Role      constants.Role `json:"role"`
+	Content   string         `json:"content"`
+	Refusal   string         `json:"refusal"`
+	ToolCalls []ToolCall     `json:"tool_calls,omitempty"`
 }
@@ -105,6 +105,76 @@ func (m *MessageTypeCodeInterpreterCall) UnmarshalJSON(buf []byte) error {
 	return unmarshalConstantString(m, buf)
 }
 
+type MessageTypeFileSearchCall string
+
+func (m *MessageTypeFileSearchCall) Value() string { return "file_search_call" }
+func (m MessageTypeFileSearchCall) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *MessageTypeFileSearchCall) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type MessageTypeMcpListTools string
+
+func (m *MessageTypeMcpListTools) Value() string { return "mcp_list_tools" }
+func (m MessageTypeMcpListTools) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *MessageTypeMcpListTools) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type MessageTypeMcpCall string
+
+func (m *MessageTypeMcpCall) Value() string { return "mcp_call" }
+func (m MessageTypeMcpCall) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *MessageTypeMcpCall) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type MessageTypeMcpApprovalRequest string
+
+func (m *MessageTypeMcpApprovalRequest) Value() string { return "mcp_approval_request" }
+func (m MessageTypeMcpApprovalRequest) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *MessageTypeMcpApprovalRequest) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type MessageTypeWebFetchCall string
+
+func (m *MessageTypeWebFetchCall) Value() string { return "web_fetch_call" }
+func (m MessageTypeWebFetchCall) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *MessageTypeWebFetchCall) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type MessageTypeURLContextCall string
+
+func (m *MessageTypeURLContextCall) Value() string { return "url_context_call" }
+func (m MessageTypeURLContextCall) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *MessageTypeURLContextCall) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type MessageTypeGoogleMapsCall string
+
+func (m *MessageTypeGoogleMapsCall) Value() string { return "google_maps_call" }
+func (m MessageTypeGoogleMapsCall) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *MessageTypeGoogleMapsCall) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
 // --------------------- //
 // End Of Message Types //
 // ------------------- //
@@ -218,6 +288,30 @@ func (m *ChunkTypeRunCompleted) UnmarshalJSON(buf []byte) error {
 	return unmarshalConstantString(m, buf)
 }
 
+type ChunkTypeRunGuardrailTripped string
+
+func (m *ChunkTypeRunGuardrailTripped) Value() string               { return "run.guardrail_tripped" }
+func (m ChunkTypeRunGuardrailTripped) MarshalJSON() ([]byte, error) { return sonic.Marshal(m.Value()) }
+func (m *ChunkTypeRunGuardrailTripped) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type ChunkTypeStateUpdated string
+
+func (m *ChunkTypeStateUpdated) Value() string               { return "state.updated" }
+func (m ChunkTypeStateUpdated) MarshalJSON() ([]byte, error) { return sonic.Marshal(m.Value()) }
+func (m *ChunkTypeStateUpdated) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type ChunkTypeReflectionCritique string
+
+func (m *ChunkTypeReflectionCritique) Value() string               { return "reflection.critique" }
+func (m ChunkTypeReflectionCritique) MarshalJSON() ([]byte, error) { return sonic.Marshal(m.Value()) }
+func (m *ChunkTypeReflectionCritique) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
 type ChunkTypeToolProgress string
 
 func (m *ChunkTypeToolProgress) Value() string               { return "tool.progress" }
@@ -250,6 +344,22 @@ func (m *ChunkTypeResponseCompleted) UnmarshalJSON(buf []byte) error {
 	return unmarshalConstantString(m, buf)
 }
 
+type ChunkTypeResponseFailed string
+
+func (m *ChunkTypeResponseFailed) Value() string               { return "response.failed" }
+func (m ChunkTypeResponseFailed) MarshalJSON() ([]byte, error) { return sonic.Marshal(m.Value()) }
+func (m *ChunkTypeResponseFailed) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type ChunkTypeError string
+
+func (m *ChunkTypeError) Value() string               { return "error" }
+func (m ChunkTypeError) MarshalJSON() ([]byte, error) { return sonic.Marshal(m.Value()) }
+func (m *ChunkTypeError) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
 type ChunkTypeOutputItemAdded string
 
 func (m *ChunkTypeOutputItemAdded) Value() string               { return "response.output_item.added" }
@@ -548,6 +658,138 @@ func (m *ChunkTypeCodeInterpreterCallCompleted) UnmarshalJSON(buf []byte) error
 	return unmarshalConstantString(m, buf)
 }
 
+type ChunkTypeFileSearchCallInProgress string
+
+func (m *ChunkTypeFileSearchCallInProgress) Value() string {
+	return "response.file_search_call.in_progress"
+}
+func (m ChunkTypeFileSearchCallInProgress) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *ChunkTypeFileSearchCallInProgress) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type ChunkTypeFileSearchCallSearching string
+
+func (m *ChunkTypeFileSearchCallSearching) Value() string {
+	return "response.file_search_call.searching"
+}
+func (m ChunkTypeFileSearchCallSearching) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *ChunkTypeFileSearchCallSearching) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type ChunkTypeFileSearchCallCompleted string
+
+func (m *ChunkTypeFileSearchCallCompleted) Value() string {
+	return "response.file_search_call.completed"
+}
+func (m ChunkTypeFileSearchCallCompleted) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *ChunkTypeFileSearchCallCompleted) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type ChunkTypeMcpCallInProgress string
+
+func (m *ChunkTypeMcpCallInProgress) Value() string {
+	return "response.mcp_call.in_progress"
+}
+func (m ChunkTypeMcpCallInProgress) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *ChunkTypeMcpCallInProgress) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type ChunkTypeMcpCallArgumentsDelta string
+
+func (m *ChunkTypeMcpCallArgumentsDelta) Value() string {
+	return "response.mcp_call_arguments.delta"
+}
+func (m ChunkTypeMcpCallArgumentsDelta) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *ChunkTypeMcpCallArgumentsDelta) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type ChunkTypeMcpCallArgumentsDone string
+
+func (m *ChunkTypeMcpCallArgumentsDone) Value() string {
+	return "response.mcp_call_arguments.done"
+}
+func (m ChunkTypeMcpCallArgumentsDone) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *ChunkTypeMcpCallArgumentsDone) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type ChunkTypeMcpCallCompleted string
+
+func (m *ChunkTypeMcpCallCompleted) Value() string {
+	return "response.mcp_call.completed"
+}
+func (m ChunkTypeMcpCallCompleted) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *ChunkTypeMcpCallCompleted) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type ChunkTypeMcpCallFailed string
+
+func (m *ChunkTypeMcpCallFailed) Value() string {
+	return "response.mcp_call.failed"
+}
+func (m ChunkTypeMcpCallFailed) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *ChunkTypeMcpCallFailed) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type ChunkTypeMcpListToolsInProgress string
+
+func (m *ChunkTypeMcpListToolsInProgress) Value() string {
+	return "response.mcp_list_tools.in_progress"
+}
+func (m ChunkTypeMcpListToolsInProgress) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *ChunkTypeMcpListToolsInProgress) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type ChunkTypeMcpListToolsCompleted string
+
+func (m *ChunkTypeMcpListToolsCompleted) Value() string {
+	return "response.mcp_list_tools.completed"
+}
+func (m ChunkTypeMcpListToolsCompleted) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *ChunkTypeMcpListToolsCompleted) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type ChunkTypeMcpListToolsFailed string
+
+func (m *ChunkTypeMcpListToolsFailed) Value() string {
+	return "response.mcp_list_tools.failed"
+}
+func (m ChunkTypeMcpListToolsFailed) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *ChunkTypeMcpListToolsFailed) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
 // ------------------ //
 // End Of Chunk Type //
 // ---------------- //
@@ -604,6 +846,66 @@ func (m *ToolTypeCodeExecution) UnmarshalJSON(buf []byte) error {
 	return unmarshalConstantString(m, buf)
 }
 
+type ToolTypeFileSearch string
+
+func (m *ToolTypeFileSearch) Value() string {
+	return "file_search"
+}
+func (m ToolTypeFileSearch) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *ToolTypeFileSearch) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type ToolTypeMCP string
+
+func (m *ToolTypeMCP) Value() string {
+	return "mcp"
+}
+func (m ToolTypeMCP) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *ToolTypeMCP) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type ToolTypeWebFetch string
+
+func (m *ToolTypeWebFetch) Value() string {
+	return "web_fetch"
+}
+func (m ToolTypeWebFetch) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *ToolTypeWebFetch) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type ToolTypeURLContext string
+
+func (m *ToolTypeURLContext) Value() string {
+	return "url_context"
+}
+func (m ToolTypeURLContext) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *ToolTypeURLContext) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type ToolTypeGoogleMaps string
+
+func (m *ToolTypeGoogleMaps) Value() string {
+	return "google_maps"
+}
+func (m ToolTypeGoogleMaps) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m *ToolTypeGoogleMaps) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
 // --------------------- //
 // End Of Function Type //
 // ------------------- //
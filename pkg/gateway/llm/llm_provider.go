@@ -11,6 +11,7 @@ import (
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/speech"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/video_generation"
 )
 
 type Provider interface {
@@ -22,8 +23,12 @@ type Provider interface {
 	NewSpeech(ctx context.Context, in *speech.Request) (*speech.Response, error)
 	NewStreamingSpeech(ctx context.Context, in *speech.Request) (chan *speech.ResponseChunk, error)
 	NewTranscription(ctx context.Context, in *transcription.Request) (*transcription.Response, error)
+	NewStreamingTranscription(ctx context.Context, in *transcription.Request) (chan *transcription.ResponseChunk, error)
 	NewImageGeneration(ctx context.Context, in *image_generation.Request) (*image_generation.Response, error)
 	NewImageEdit(ctx context.Context, in *image_edit.Request) (*image_edit.Response, error)
+	NewVideoGeneration(ctx context.Context, in *video_generation.Request) (*video_generation.Response, error)
+	GetVideoGeneration(ctx context.Context, in *video_generation.GetRequest) (*video_generation.Response, error)
+	DownloadVideoGeneration(ctx context.Context, in *video_generation.DownloadRequest) (*video_generation.Content, error)
 }
 
 type ProviderName string
@@ -0,0 +1,89 @@
+package llmtest
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+)
+
+// Conversations serves several conversations at once, the way concurrent
+// runs of one agent call one provider. Each call is answered by the Provider
+// scripted for the first user message of its input, so every conversation
+// takes its own turns in order however the calls interleave:
+//
+//	fake := llmtest.ByConversation(map[string]*llmtest.Provider{
+//		"acme":   llmtest.New().RespondText("acme raised prices"),
+//		"globex": llmtest.New().RespondText("globex launched a new plan"),
+//	})
+//
+// A call whose conversation has no script fails with ErrScriptExhausted.
+type Conversations struct {
+	// Provider answers the calls of unscripted conversations, and stands in
+	// for the APIs that are not scripted.
+	*Provider
+	scripts map[string]*Provider
+}
+
+// ByConversation routes each call to the provider scripted for its
+// conversation, keyed by the text of the conversation's first user message.
+func ByConversation(scripts map[string]*Provider) *Conversations {
+	return &Conversations{Provider: New(), scripts: scripts}
+}
+
+// For returns the provider a call with the given input goes to.
+func (c *Conversations) For(in *responses.Request) *Provider {
+	if p, ok := c.scripts[firstUserText(in)]; ok {
+		return p
+	}
+	return c.Provider
+}
+
+func (c *Conversations) NewResponses(ctx context.Context, in *responses.Request) (*responses.Response, error) {
+	return c.For(in).NewResponses(ctx, in)
+}
+
+func (c *Conversations) NewStreamingResponses(ctx context.Context, in *responses.Request) (chan *responses.ResponseChunk, error) {
+	return c.For(in).NewStreamingResponses(ctx, in)
+}
+
+// Calls is the number of model calls received so far, across every
+// conversation.
+func (c *Conversations) Calls() int {
+	n := c.Provider.Calls()
+	for _, p := range c.scripts {
+		n += p.Calls()
+	}
+	return n
+}
+
+// firstUserText is the text of the first user message in in.
+func firstUserText(in *responses.Request) string {
+	if in.Input.OfString != nil {
+		return *in.Input.OfString
+	}
+
+	for _, msg := range in.Input.OfInputMessageList {
+		switch {
+		case msg.OfEasyInput != nil && msg.OfEasyInput.Role == constants.RoleUser:
+			if msg.OfEasyInput.Content.OfString != nil {
+				return *msg.OfEasyInput.Content.OfString
+			}
+			return contentText(msg.OfEasyInput.Content.OfInputMessageList)
+		case msg.OfInputMessage != nil && msg.OfInputMessage.Role == constants.RoleUser:
+			return contentText(msg.OfInputMessage.Content)
+		}
+	}
+	return ""
+}
+
+func contentText(content responses.InputContent) string {
+	var text strings.Builder
+	for _, part := range content {
+		if part.OfInputText != nil {
+			text.WriteString(part.OfInputText.Text)
+		}
+	}
+	return text.String()
+}
@@ -0,0 +1,522 @@
+// Package llmtest provides a scripted llm.Provider for unit-testing code that
+// talks to a model — agents, hooks, approvals, handoffs — without a network
+// or a real provider.
+//
+// A Provider answers each model call with the next scripted turn:
+//
+//	fake := llmtest.New().
+//		CallTool("get_weather", `{"location":"Paris"}`).
+//		RespondText("It is sunny in Paris.")
+//
+//	agent := agents.NewAgent(&agents.AgentOptions{LLM: fake, ...})
+//
+// Streaming calls emit the same chunk sequence a real provider's converter
+// produces — response.created, output_item.added, deltas, output_item.done,
+// response.completed with usage — so the code under test folds and publishes
+// them exactly as it would in production. Every request received is recorded
+// for assertions.
+//
+// A streamed request with Parameters.Background set is kept, as a provider
+// that stores responses keeps it, so a test can drop its stream (DropAfter)
+// and reattach to it through the responses.BackgroundProvider methods.
+package llmtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/chat_completion"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/embeddings"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/image_edit"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/image_generation"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/speech"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/video_generation"
+)
+
+var (
+	// ErrScriptExhausted is returned when the code under test makes more
+	// model calls than were scripted.
+	ErrScriptExhausted = errors.New("llmtest: no scripted turn left")
+
+	// ErrNotSupported is returned by every non-Responses method.
+	ErrNotSupported = errors.New("llmtest: only the Responses API is scripted")
+)
+
+// StatusError is what a failing turn returns, shaped like a provider's
+// non-2xx answer.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s (status %d)", e.Message, e.StatusCode)
+}
+
+// HTTPStatusCode lets the gateway classify the failure as it would a real
+// provider error.
+func (e *StatusError) HTTPStatusCode() int {
+	return e.StatusCode
+}
+
+// Provider is a scripted llm.Provider. The zero value is not usable; build
+// one with New. It is safe for concurrent use, though concurrent calls take
+// turns in whatever order they reach the provider.
+type Provider struct {
+	mu        sync.Mutex
+	turns     []*Turn
+	served    int
+	requests  []*responses.Request
+	cancelled int
+
+	// background holds the chunks of each background response streamed,
+	// by response id, for reattaching to it.
+	background         map[string][]*responses.ResponseChunk
+	reattached         []*responses.StreamResponseFromRequest
+	cancelledResponses []string
+}
+
+var _ responses.BackgroundProvider = (*Provider)(nil)
+
+func New() *Provider {
+	return &Provider{background: map[string][]*responses.ResponseChunk{}}
+}
+
+// Turn is the answer to one model call: a list of output items, or a raw
+// chunk stream, or an error.
+type Turn struct {
+	Outputs []Output
+	Usage   responses.Usage
+
+	// Model, when set, is the model the turn says answered, the way a
+	// provider reports the dated version of the model asked for. It
+	// defaults to the requested model.
+	Model string
+
+	// Chunks, when set, is streamed verbatim instead of generating chunks
+	// from Outputs.
+	Chunks []*responses.ResponseChunk
+
+	// Err fails the call before any chunk is produced, the way an HTTP
+	// error from a provider does.
+	Err error
+
+	// Hang, when set, never answers: the call produces nothing until its
+	// context is done, the way a model that is still thinking does.
+	Hang bool
+
+	// DropAfter, when positive, breaks the stream off after that many
+	// chunks with an error event, the way a lost connection ends it. A
+	// background response goes on regardless, and StreamResponseFrom
+	// reattaches to it. NewResponses, which has no stream to lose, answers
+	// in full.
+	DropAfter int
+}
+
+func (t *Turn) model(in *responses.Request) string {
+	if t.Model != "" {
+		return t.Model
+	}
+	return in.Model
+}
+
+// Output is one output item of a turn. Build it with Text, ToolCall or
+// Reasoning.
+type Output struct {
+	text      *string
+	reasoning *string
+	call      *responses.FunctionCallMessage
+}
+
+// Text is an assistant message.
+func Text(text string) Output {
+	return Output{text: &text}
+}
+
+// ToolCall is a function call with the given JSON arguments. The call id is
+// generated; use ToolCallWithID when a test needs to refer to it.
+func ToolCall(name, args string) Output {
+	id := responses.NewOutputItemFunctionCallID()
+	return ToolCallWithID("call_"+id[len("fc_"):], name, args)
+}
+
+func ToolCallWithID(callID, name, args string) Output {
+	return Output{call: &responses.FunctionCallMessage{
+		ID:        responses.NewOutputItemFunctionCallID(),
+		CallID:    callID,
+		Name:      name,
+		Arguments: args,
+	}}
+}
+
+// Reasoning is a reasoning item with a single summary part.
+func Reasoning(summary string) Output {
+	return Output{reasoning: &summary}
+}
+
+// Respond scripts a turn answering with the given output items, in order —
+// e.g. several ToolCalls for parallel calls, or a Text followed by a
+// ToolCall.
+func (p *Provider) Respond(outputs ...Output) *Provider {
+	return p.append(&Turn{Outputs: outputs, Usage: defaultUsage(outputs)})
+}
+
+// RespondText scripts a turn answering with a single assistant message.
+func (p *Provider) RespondText(text string) *Provider {
+	return p.Respond(Text(text))
+}
+
+// CallTool scripts a turn that calls one tool.
+func (p *Provider) CallTool(name, args string) *Provider {
+	return p.Respond(ToolCall(name, args))
+}
+
+// ReasonThenAnswer scripts a turn with a reasoning item followed by an
+// assistant message.
+func (p *Provider) ReasonThenAnswer(summary, answer string) *Provider {
+	return p.Respond(Reasoning(summary), Text(answer))
+}
+
+// StreamChunks scripts a turn that streams the given chunks as-is. It is
+// for tests of stream handling itself; NewResponses folds them.
+func (p *Provider) StreamChunks(chunks ...*responses.ResponseChunk) *Provider {
+	return p.append(&Turn{Chunks: chunks})
+}
+
+// Hang scripts a turn that never answers, for tests of what cuts a model
+// call off — a stop, a deadline, a tripped guardrail.
+func (p *Provider) Hang() *Provider {
+	return p.append(&Turn{Hang: true})
+}
+
+// Fail scripts a turn that fails with err.
+func (p *Provider) Fail(err error) *Provider {
+	return p.append(&Turn{Err: err})
+}
+
+// FailRateLimit scripts a turn that fails the way a provider does when the
+// key is over its rate limit.
+func (p *Provider) FailRateLimit() *Provider {
+	return p.Fail(&StatusError{StatusCode: http.StatusTooManyRequests, Message: "Rate limit reached, please try again later"})
+}
+
+// WithUsage overrides the token usage reported by the last scripted turn.
+func (p *Provider) WithUsage(inputTokens, outputTokens int) *Provider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.turns) == 0 {
+		panic("llmtest: WithUsage called before any turn was scripted")
+	}
+	t := p.turns[len(p.turns)-1]
+	t.Usage = responses.Usage{InputTokens: inputTokens, OutputTokens: outputTokens, TotalTokens: inputTokens + outputTokens}
+	return p
+}
+
+// WithModel overrides the model the last scripted turn reports.
+func (p *Provider) WithModel(model string) *Provider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.turns) == 0 {
+		panic("llmtest: WithModel called before any turn was scripted")
+	}
+	p.turns[len(p.turns)-1].Model = model
+	return p
+}
+
+// DropAfter breaks the last scripted turn's stream off after n chunks.
+func (p *Provider) DropAfter(n int) *Provider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.turns) == 0 {
+		panic("llmtest: DropAfter called before any turn was scripted")
+	}
+	p.turns[len(p.turns)-1].DropAfter = n
+	return p
+}
+
+// Then scripts an arbitrary turn.
+func (p *Provider) Then(t *Turn) *Provider {
+	return p.append(t)
+}
+
+func (p *Provider) append(t *Turn) *Provider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.turns = append(p.turns, t)
+	return p
+}
+
+// Requests returns every request received so far, in order.
+func (p *Provider) Requests() []*responses.Request {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]*responses.Request(nil), p.requests...)
+}
+
+// Request returns the i-th request received. It panics if there is none,
+// which in a test reads as a clear failure.
+func (p *Provider) Request(i int) *responses.Request {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.requests[i]
+}
+
+// Calls is the number of model calls received so far.
+func (p *Provider) Calls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.requests)
+}
+
+// Cancelled is the number of calls whose context was done before they had
+// streamed their whole turn.
+func (p *Provider) Cancelled() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.cancelled
+}
+
+func (p *Provider) recordCancelled() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.cancelled++
+}
+
+// Remaining is the number of scripted turns not yet consumed. Asserting it
+// is zero at the end of a test catches a loop that stopped early.
+func (p *Provider) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.turns) - p.served
+}
+
+// Reattached returns every StreamResponseFrom request received so far, in
+// order.
+func (p *Provider) Reattached() []*responses.StreamResponseFromRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]*responses.StreamResponseFromRequest(nil), p.reattached...)
+}
+
+// CancelledResponses returns the ids of the background responses cancelled
+// so far, in order.
+func (p *Provider) CancelledResponses() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]string(nil), p.cancelledResponses...)
+}
+
+func (p *Provider) next(in *responses.Request) (*Turn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.requests = append(p.requests, in)
+	if p.served == len(p.turns) {
+		return nil, fmt.Errorf("%w: call %d but %d turns scripted", ErrScriptExhausted, len(p.requests), len(p.turns))
+	}
+
+	t := p.turns[p.served]
+	p.served++
+	if t.Err != nil {
+		return nil, t.Err
+	}
+	return t, nil
+}
+
+// storedResponse is the chunks of the background response with the given
+// id, or a provider's not-found error.
+func (p *Provider) storedResponse(id string) ([]*responses.ResponseChunk, error) {
+	chunks, ok := p.background[id]
+	if !ok {
+		return nil, &StatusError{StatusCode: http.StatusNotFound, Message: fmt.Sprintf("No response found with id '%s'.", id)}
+	}
+	return chunks, nil
+}
+
+func (p *Provider) NewResponses(ctx context.Context, in *responses.Request) (*responses.Response, error) {
+	t, err := p.next(in)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Hang {
+		<-ctx.Done()
+		p.recordCancelled()
+		return nil, ctx.Err()
+	}
+
+	if t.Chunks != nil {
+		return foldChunks(t.Chunks), nil
+	}
+
+	usage := t.Usage
+	return &responses.Response{
+		ID:     newResponseID(),
+		Model:  t.model(in),
+		Output: outputItems(t.Outputs),
+		Usage:  &usage,
+	}, nil
+}
+
+func (p *Provider) NewStreamingResponses(ctx context.Context, in *responses.Request) (chan *responses.ResponseChunk, error) {
+	t, err := p.next(in)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := t.Chunks
+	if chunks == nil && !t.Hang {
+		s := newStream(t.model(in), t.Outputs, t.Usage)
+		chunks = s.chunks()
+		if in.Background != nil && *in.Background {
+			p.mu.Lock()
+			p.background[s.id] = chunks
+			p.mu.Unlock()
+		}
+	}
+	if t.DropAfter > 0 && t.DropAfter < len(chunks) {
+		chunks = append(chunks[:t.DropAfter:t.DropAfter], &responses.ResponseChunk{OfError: &responses.ChunkError[constants.ChunkTypeError]{
+			Message: "connection lost",
+		}})
+	}
+
+	return p.send(ctx, t, chunks), nil
+}
+
+// send streams chunks on a channel of their own, closing it at the end or
+// when ctx is done.
+func (p *Provider) send(ctx context.Context, t *Turn, chunks []*responses.ResponseChunk) chan *responses.ResponseChunk {
+	out := make(chan *responses.ResponseChunk)
+	go func() {
+		defer close(out)
+		if t.Hang {
+			<-ctx.Done()
+			p.recordCancelled()
+			return
+		}
+		for _, chunk := range chunks {
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				p.recordCancelled()
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// StreamResponseFrom streams the rest of a background response, from the
+// chunk after in.SequenceNumber. It takes no scripted turn: the response is
+// the one the dropped stream was carrying.
+func (p *Provider) StreamResponseFrom(ctx context.Context, in *responses.StreamResponseFromRequest) (chan *responses.ResponseChunk, error) {
+	p.mu.Lock()
+	p.reattached = append(p.reattached, in)
+	chunks, err := p.storedResponse(in.ID)
+	p.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// A generated stream numbers its chunks from zero.
+	from := min(in.SequenceNumber+1, len(chunks))
+	return p.send(ctx, &Turn{}, chunks[from:]), nil
+}
+
+// GetResponse returns a background response as it ends, or as it was when
+// cancelled.
+func (p *Provider) GetResponse(ctx context.Context, in *responses.GetResponseRequest) (*responses.Response, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	chunks, err := p.storedResponse(in.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := foldChunks(chunks)
+	resp.Status = responses.ResponseStatusCompleted
+	if slices.Contains(p.cancelledResponses, in.ID) {
+		resp.Status = responses.ResponseStatusCancelled
+	}
+	return resp, nil
+}
+
+func (p *Provider) CancelResponse(ctx context.Context, in *responses.CancelResponseRequest) (*responses.Response, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, err := p.storedResponse(in.ID); err != nil {
+		return nil, err
+	}
+	p.cancelledResponses = append(p.cancelledResponses, in.ID)
+	return &responses.Response{ID: in.ID, Model: in.Model, Status: responses.ResponseStatusCancelled}, nil
+}
+
+func (p *Provider) NewEmbedding(ctx context.Context, in *embeddings.Request) (*embeddings.Response, error) {
+	return nil, ErrNotSupported
+}
+
+func (p *Provider) NewChatCompletion(ctx context.Context, in *chat_completion.Request) (*chat_completion.Response, error) {
+	return nil, ErrNotSupported
+}
+
+func (p *Provider) NewStreamingChatCompletion(ctx context.Context, in *chat_completion.Request) (chan *chat_completion.ResponseChunk, error) {
+	return nil, ErrNotSupported
+}
+
+func (p *Provider) NewSpeech(ctx context.Context, in *speech.Request) (*speech.Response, error) {
+	return nil, ErrNotSupported
+}
+
+func (p *Provider) NewStreamingSpeech(ctx context.Context, in *speech.Request) (chan *speech.ResponseChunk, error) {
+	return nil, ErrNotSupported
+}
+
+func (p *Provider) NewTranscription(ctx context.Context, in *transcription.Request) (*transcription.Response, error) {
+	return nil, ErrNotSupported
+}
+
+func (p *Provider) NewStreamingTranscription(ctx context.Context, in *transcription.Request) (chan *transcription.ResponseChunk, error) {
+	return nil, ErrNotSupported
+}
+
+func (p *Provider) NewImageGeneration(ctx context.Context, in *image_generation.Request) (*image_generation.Response, error) {
+	return nil, ErrNotSupported
+}
+
+func (p *Provider) NewImageEdit(ctx context.Context, in *image_edit.Request) (*image_edit.Response, error) {
+	return nil, ErrNotSupported
+}
+
+func (p *Provider) NewVideoGeneration(ctx context.Context, in *video_generation.Request) (*video_generation.Response, error) {
+	return nil, ErrNotSupported
+}
+
+func (p *Provider) GetVideoGeneration(ctx context.Context, in *video_generation.GetRequest) (*video_generation.Response, error) {
+	return nil, ErrNotSupported
+}
+
+func (p *Provider) DownloadVideoGeneration(ctx context.Context, in *video_generation.DownloadRequest) (*video_generation.Content, error) {
+	return nil, ErrNotSupported
+}
@@ -0,0 +1,284 @@
+package llmtest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/llmtest"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+)
+
+var (
+	_ llm.Provider = (*llmtest.Provider)(nil)
+	_ llm.Provider = (*llmtest.Conversations)(nil)
+)
+
+func request(text string) *responses.Request {
+	return &responses.Request{Model: "fake-model", Input: responses.InputUnion{OfString: utils.Ptr(text)}}
+}
+
+func collect(t *testing.T, ch chan *responses.ResponseChunk) []*responses.ResponseChunk {
+	t.Helper()
+	var out []*responses.ResponseChunk
+	for c := range ch {
+		out = append(out, c)
+	}
+	return out
+}
+
+func chunkTypes(chunks []*responses.ResponseChunk) []string {
+	types := make([]string, len(chunks))
+	for i, c := range chunks {
+		types[i] = c.ChunkType()
+	}
+	return types
+}
+
+func TestProvider_StreamsTextLikeAProvider(t *testing.T) {
+	fake := llmtest.New().RespondText("hello there")
+
+	ch, err := fake.NewStreamingResponses(context.Background(), request("hi"))
+	require.NoError(t, err)
+	chunks := collect(t, ch)
+
+	assert.Equal(t, []string{
+		"response.created",
+		"response.in_progress",
+		"response.output_item.added",
+		"response.content_part.added",
+		"response.output_text.delta",
+		"response.output_text.delta",
+		"response.output_text.done",
+		"response.content_part.done",
+		"response.output_item.done",
+		"response.completed",
+	}, chunkTypes(chunks))
+
+	var text strings.Builder
+	for i, c := range chunks {
+		if c.OfOutputTextDelta != nil {
+			text.WriteString(c.OfOutputTextDelta.Delta)
+		}
+		// Sequence numbers run without gaps, as they do on the wire.
+		switch {
+		case c.OfOutputTextDelta != nil:
+			assert.Equal(t, i, c.OfOutputTextDelta.SequenceNumber)
+		case c.OfResponseCompleted != nil:
+			assert.Equal(t, i, c.OfResponseCompleted.SequenceNumber)
+		}
+	}
+	assert.Equal(t, "hello there", text.String())
+
+	completed := chunks[len(chunks)-1].OfResponseCompleted
+	assert.Greater(t, completed.Response.Usage.OutputTokens, 0)
+	assert.Equal(t, "fake-model", completed.Response.Model)
+}
+
+func TestProvider_ToolCallArgumentsReassemble(t *testing.T) {
+	args := `{"location":"Paris","unit":"celsius"}`
+	fake := llmtest.New().Respond(llmtest.ToolCallWithID("call_1", "get_weather", args))
+
+	ch, err := fake.NewStreamingResponses(context.Background(), request("weather?"))
+	require.NoError(t, err)
+
+	var deltas strings.Builder
+	var done *responses.ChunkOutputItemData
+	for _, c := range collect(t, ch) {
+		if c.OfFunctionCallArgumentsDelta != nil {
+			deltas.WriteString(c.OfFunctionCallArgumentsDelta.Delta)
+		}
+		if c.OfOutputItemDone != nil {
+			done = &c.OfOutputItemDone.Item
+		}
+	}
+
+	assert.Equal(t, args, deltas.String())
+	require.NotNil(t, done)
+	assert.Equal(t, "function_call", done.Type)
+	assert.Equal(t, "call_1", *done.CallID)
+	assert.Equal(t, "get_weather", *done.Name)
+	assert.Equal(t, args, *done.Arguments)
+}
+
+func TestProvider_TurnsInOrderAndRequestsRecorded(t *testing.T) {
+	fake := llmtest.New().
+		CallTool("lookup", `{}`).
+		ReasonThenAnswer("thinking it over", "forty-two").
+		WithUsage(100, 7)
+
+	ctx := context.Background()
+
+	first, err := fake.NewResponses(ctx, request("first"))
+	require.NoError(t, err)
+	require.Len(t, first.Output, 1)
+	assert.Equal(t, "lookup", first.Output[0].OfFunctionCall.Name)
+
+	second, err := fake.NewResponses(ctx, request("second"))
+	require.NoError(t, err)
+	require.Len(t, second.Output, 2)
+	assert.Equal(t, "thinking it over", second.Output[0].OfReasoning.Summary[0].Text)
+	assert.Equal(t, "forty-two", (*second.Output[1].OfOutputMessage.Content)[0].OfOutputText.Text)
+	assert.Equal(t, 100, second.Usage.InputTokens)
+	assert.Equal(t, 107, second.Usage.TotalTokens)
+
+	assert.Equal(t, 2, fake.Calls())
+	assert.Equal(t, 0, fake.Remaining())
+	assert.Equal(t, "second", *fake.Request(1).Input.OfString)
+
+	_, err = fake.NewResponses(ctx, request("third"))
+	assert.True(t, errors.Is(err, llmtest.ErrScriptExhausted))
+	assert.Len(t, fake.Requests(), 3)
+}
+
+func TestProvider_FailRateLimit(t *testing.T) {
+	fake := llmtest.New().FailRateLimit().RespondText("ok")
+
+	_, err := fake.NewStreamingResponses(context.Background(), request("hi"))
+	var statusErr *llmtest.StatusError
+	require.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, http.StatusTooManyRequests, statusErr.StatusCode)
+
+	// A failed turn still counts: the retry gets the next one.
+	resp, err := fake.NewResponses(context.Background(), request("hi"))
+	require.NoError(t, err)
+	assert.Equal(t, "ok", (*resp.Output[0].OfOutputMessage.Content)[0].OfOutputText.Text)
+}
+
+func TestProvider_StreamChunksVerbatim(t *testing.T) {
+	source := llmtest.New().RespondText("verbatim")
+	ch, err := source.NewStreamingResponses(context.Background(), request("x"))
+	require.NoError(t, err)
+	scripted := collect(t, ch)
+
+	fake := llmtest.New().StreamChunks(scripted...).StreamChunks(scripted...)
+
+	ch, err = fake.NewStreamingResponses(context.Background(), request("x"))
+	require.NoError(t, err)
+	assert.Equal(t, scripted, collect(t, ch))
+
+	resp, err := fake.NewResponses(context.Background(), request("x"))
+	require.NoError(t, err)
+	require.Len(t, resp.Output, 1)
+	assert.Equal(t, "verbatim", (*resp.Output[0].OfOutputMessage.Content)[0].OfOutputText.Text)
+}
+
+func TestProvider_StreamStopsOnCancel(t *testing.T) {
+	fake := llmtest.New().RespondText("a long answer that will not be read")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := fake.NewStreamingResponses(ctx, request("x"))
+	require.NoError(t, err)
+
+	<-ch
+	cancel()
+
+	// The sender gives up instead of blocking on a reader that left.
+	for range ch {
+	}
+}
+
+func TestProvider_HangUntilCancelled(t *testing.T) {
+	fake := llmtest.New().Hang().RespondText("after the hang")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := fake.NewStreamingResponses(ctx, request("x"))
+	require.NoError(t, err)
+
+	select {
+	case chunk := <-ch:
+		t.Fatalf("a hanging turn produced %+v", chunk)
+	case <-time.After(20 * time.Millisecond):
+	}
+	cancel()
+	for range ch {
+	}
+	assert.Equal(t, 1, fake.Cancelled())
+
+	// The turns after it answer as usual.
+	resp, err := fake.NewResponses(context.Background(), request("y"))
+	require.NoError(t, err)
+	assert.Equal(t, "after the hang", (*resp.Output[0].OfOutputMessage.Content)[0].OfOutputText.Text)
+	assert.Equal(t, 1, fake.Cancelled())
+}
+
+func TestProvider_WithModelReportsTheAnsweringModel(t *testing.T) {
+	fake := llmtest.New().RespondText("dated").WithModel("fake-model-2025-01-01").RespondText("as asked")
+
+	ch, err := fake.NewStreamingResponses(context.Background(), request("x"))
+	require.NoError(t, err)
+	chunks := collect(t, ch)
+	assert.Equal(t, "fake-model-2025-01-01", chunks[len(chunks)-1].OfResponseCompleted.Response.Model)
+
+	resp, err := fake.NewResponses(context.Background(), request("y"))
+	require.NoError(t, err)
+	assert.Equal(t, "fake-model", resp.Model)
+}
+
+func TestProvider_ReattachesToADroppedBackgroundResponse(t *testing.T) {
+	fake := llmtest.New().RespondText("the research is done").DropAfter(4)
+
+	in := request("research")
+	in.Background = utils.Ptr(true)
+	ch, err := fake.NewStreamingResponses(context.Background(), in)
+	require.NoError(t, err)
+	dropped := collect(t, ch)
+	require.Len(t, dropped, 5)
+	assert.Equal(t, "connection lost", dropped[4].OfError.Message)
+
+	id := dropped[0].OfResponseCreated.Response.Id
+	ch, err = fake.StreamResponseFrom(context.Background(), &responses.StreamResponseFromRequest{ID: id, Model: "fake-model", SequenceNumber: 3})
+	require.NoError(t, err)
+	rest := collect(t, ch)
+	assert.Equal(t, 4, rest[0].OfOutputTextDelta.SequenceNumber)
+	assert.Equal(t, "response.completed", rest[len(rest)-1].ChunkType())
+	assert.Equal(t, 1, fake.Calls())
+	assert.Len(t, fake.Reattached(), 1)
+
+	cancelled, err := fake.CancelResponse(context.Background(), &responses.CancelResponseRequest{ID: id})
+	require.NoError(t, err)
+	assert.Equal(t, responses.ResponseStatusCancelled, cancelled.Status)
+	assert.Equal(t, []string{id}, fake.CancelledResponses())
+
+	// Only a background response is kept.
+	_, err = fake.StreamResponseFrom(context.Background(), &responses.StreamResponseFromRequest{ID: "resp_unknown"})
+	var statusErr *llmtest.StatusError
+	require.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+}
+
+func TestConversations_RouteByFirstUserMessage(t *testing.T) {
+	acme := llmtest.New().CallTool("lookup", "{}").RespondText("acme raised prices")
+	globex := llmtest.New().RespondText("globex launched a new plan")
+	fake := llmtest.ByConversation(map[string]*llmtest.Provider{"acme": acme, "globex": globex})
+
+	answer := func(in *responses.Request) *responses.Response {
+		resp, err := fake.NewResponses(context.Background(), in)
+		require.NoError(t, err)
+		return resp
+	}
+
+	assert.NotNil(t, answer(request("acme")).Output[0].OfFunctionCall)
+	assert.Equal(t, "globex launched a new plan", (*answer(request("globex")).Output[0].OfOutputMessage.Content)[0].OfOutputText.Text)
+
+	// A later call of the same conversation carries the same first message.
+	later := &responses.Request{Input: responses.InputUnion{OfInputMessageList: responses.InputMessageList{
+		{OfEasyInput: &responses.EasyMessage{Role: constants.RoleUser, Content: responses.EasyInputContentUnion{OfString: utils.Ptr("acme")}}},
+		{OfFunctionCallOutput: &responses.FunctionCallOutputMessage{CallID: "call_1"}},
+	}}}
+	assert.Equal(t, "acme raised prices", (*answer(later).Output[0].OfOutputMessage.Content)[0].OfOutputText.Text)
+	assert.Equal(t, 2, acme.Calls())
+	assert.Equal(t, 3, fake.Calls())
+
+	_, err := fake.NewResponses(context.Background(), request("initech"))
+	assert.ErrorIs(t, err, llmtest.ErrScriptExhausted)
+}
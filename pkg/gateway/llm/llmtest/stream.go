@@ -0,0 +1,371 @@
+package llmtest
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+)
+
+func newResponseID() string {
+	return "resp_" + uuid.NewString()
+}
+
+// defaultUsage gives every scripted turn plausible, non-zero usage, so code
+// that accounts tokens sees something to account.
+func defaultUsage(outputs []Output) responses.Usage {
+	out := 0
+	for _, o := range outputs {
+		switch {
+		case o.text != nil:
+			out += len(strings.Fields(*o.text)) + 1
+		case o.reasoning != nil:
+			out += len(strings.Fields(*o.reasoning)) + 1
+		case o.call != nil:
+			out += len(o.call.Arguments)/4 + 5
+		}
+	}
+	return responses.Usage{InputTokens: 10, OutputTokens: out, TotalTokens: 10 + out}
+}
+
+func outputItems(outputs []Output) []responses.OutputMessageUnion {
+	items := make([]responses.OutputMessageUnion, 0, len(outputs))
+	for _, o := range outputs {
+		switch {
+		case o.text != nil:
+			items = append(items, responses.OutputMessageUnion{OfOutputMessage: &responses.OutputMessage{
+				ID:      responses.NewOutputItemMessageID(),
+				Role:    constants.RoleAssistant,
+				Content: &responses.OutputContent{{OfOutputText: &responses.OutputTextContent{Text: *o.text, Annotations: []responses.Annotation{}}}},
+			}})
+		case o.reasoning != nil:
+			items = append(items, responses.OutputMessageUnion{OfReasoning: &responses.ReasoningMessage{
+				ID:      responses.NewOutputItemReasoningID(),
+				Summary: []responses.SummaryTextContent{{Text: *o.reasoning}},
+			}})
+		case o.call != nil:
+			call := *o.call
+			items = append(items, responses.OutputMessageUnion{OfFunctionCall: &call})
+		}
+	}
+	return items
+}
+
+// stream generates the chunk sequence of one scripted turn, in the order an
+// OpenAI Responses stream delivers it: the response envelope, then each
+// output item opened, fed deltas and closed, then response.completed.
+type stream struct {
+	id      string
+	model   string
+	created int
+	outputs []Output
+	usage   responses.Usage
+
+	seq int
+	out []*responses.ResponseChunk
+}
+
+func newStream(model string, outputs []Output, usage responses.Usage) *stream {
+	return &stream{
+		id:      newResponseID(),
+		model:   model,
+		created: int(time.Now().Unix()),
+		outputs: outputs,
+		usage:   usage,
+	}
+}
+
+func (s *stream) nextSeq() int {
+	n := s.seq
+	s.seq++
+	return n
+}
+
+func (s *stream) responseData(status string, output []responses.OutputMessageUnion, usage responses.Usage) responses.ChunkResponseData {
+	data := responses.ChunkResponseData{
+		Id:        s.id,
+		Object:    "response",
+		CreatedAt: s.created,
+		Status:    status,
+		Output:    output,
+		Usage:     usage,
+	}
+	data.Model = s.model
+	return data
+}
+
+func (s *stream) chunks() []*responses.ResponseChunk {
+	s.out = append(s.out,
+		&responses.ResponseChunk{OfResponseCreated: &responses.ChunkResponse[constants.ChunkTypeResponseCreated]{
+			SequenceNumber: s.nextSeq(),
+			Response:       s.responseData("in_progress", []responses.OutputMessageUnion{}, responses.Usage{}),
+		}},
+		&responses.ResponseChunk{OfResponseInProgress: &responses.ChunkResponse[constants.ChunkTypeResponseInProgress]{
+			SequenceNumber: s.nextSeq(),
+			Response:       s.responseData("in_progress", []responses.OutputMessageUnion{}, responses.Usage{}),
+		}},
+	)
+
+	items := outputItems(s.outputs)
+	for i, item := range items {
+		switch {
+		case item.OfOutputMessage != nil:
+			s.message(i, item.OfOutputMessage)
+		case item.OfReasoning != nil:
+			s.reasoning(i, item.OfReasoning)
+		case item.OfFunctionCall != nil:
+			s.functionCall(i, item.OfFunctionCall)
+		}
+	}
+
+	s.out = append(s.out, &responses.ResponseChunk{OfResponseCompleted: &responses.ChunkResponse[constants.ChunkTypeResponseCompleted]{
+		SequenceNumber: s.nextSeq(),
+		Response:       s.responseData("completed", items, s.usage),
+	}})
+
+	return s.out
+}
+
+func (s *stream) message(index int, msg *responses.OutputMessage) {
+	text := (*msg.Content)[0].OfOutputText.Text
+
+	s.out = append(s.out,
+		&responses.ResponseChunk{OfOutputItemAdded: &responses.ChunkOutputItem[constants.ChunkTypeOutputItemAdded]{
+			SequenceNumber: s.nextSeq(),
+			OutputIndex:    index,
+			Item: responses.ChunkOutputItemData{
+				Type:    "message",
+				Id:      msg.ID,
+				Status:  "in_progress",
+				Role:    constants.RoleAssistant,
+				Content: &responses.ChunkOutputItemContent{},
+			},
+		}},
+		&responses.ResponseChunk{OfContentPartAdded: &responses.ChunkContentPart[constants.ChunkTypeContentPartAdded]{
+			SequenceNumber: s.nextSeq(),
+			ItemId:         msg.ID,
+			OutputIndex:    index,
+			Part:           responses.ChunkOutputItemContentUnion{OfOutputText: &responses.OutputTextContent{Annotations: []responses.Annotation{}}},
+		}},
+	)
+
+	for _, delta := range splitDeltas(text) {
+		s.out = append(s.out, &responses.ResponseChunk{OfOutputTextDelta: &responses.ChunkOutputText[constants.ChunkTypeOutputTextDelta]{
+			SequenceNumber: s.nextSeq(),
+			ItemId:         msg.ID,
+			OutputIndex:    index,
+			Delta:          delta,
+			Logprobs:       []responses.Logprob{},
+		}})
+	}
+
+	part := &responses.OutputTextContent{Text: text, Annotations: []responses.Annotation{}}
+	s.out = append(s.out,
+		&responses.ResponseChunk{OfOutputTextDone: &responses.ChunkOutputText[constants.ChunkTypeOutputTextDone]{
+			SequenceNumber: s.nextSeq(),
+			ItemId:         msg.ID,
+			OutputIndex:    index,
+			Text:           utils.Ptr(text),
+			Logprobs:       []responses.Logprob{},
+		}},
+		&responses.ResponseChunk{OfContentPartDone: &responses.ChunkContentPart[constants.ChunkTypeContentPartDone]{
+			SequenceNumber: s.nextSeq(),
+			ItemId:         msg.ID,
+			OutputIndex:    index,
+			Part:           responses.ChunkOutputItemContentUnion{OfOutputText: part},
+		}},
+		&responses.ResponseChunk{OfOutputItemDone: &responses.ChunkOutputItem[constants.ChunkTypeOutputItemDone]{
+			SequenceNumber: s.nextSeq(),
+			OutputIndex:    index,
+			Item: responses.ChunkOutputItemData{
+				Type:    "message",
+				Id:      msg.ID,
+				Status:  "completed",
+				Role:    constants.RoleAssistant,
+				Content: &responses.ChunkOutputItemContent{{OfOutputText: part}},
+			},
+		}},
+	)
+}
+
+func (s *stream) reasoning(index int, r *responses.ReasoningMessage) {
+	summary := r.Summary[0].Text
+
+	s.out = append(s.out,
+		&responses.ResponseChunk{OfOutputItemAdded: &responses.ChunkOutputItem[constants.ChunkTypeOutputItemAdded]{
+			SequenceNumber: s.nextSeq(),
+			OutputIndex:    index,
+			Item: responses.ChunkOutputItemData{
+				Type:    "reasoning",
+				Id:      r.ID,
+				Summary: &[]responses.SummaryTextContent{},
+			},
+		}},
+		&responses.ResponseChunk{OfReasoningSummaryPartAdded: &responses.ChunkReasoningSummaryPart[constants.ChunkTypeReasoningSummaryPartAdded]{
+			SequenceNumber: s.nextSeq(),
+			ItemId:         r.ID,
+			OutputIndex:    index,
+		}},
+	)
+
+	for _, delta := range splitDeltas(summary) {
+		s.out = append(s.out, &responses.ResponseChunk{OfReasoningSummaryTextDelta: &responses.ChunkReasoningSummaryText[constants.ChunkTypeReasoningSummaryTextDelta]{
+			SequenceNumber: s.nextSeq(),
+			ItemId:         r.ID,
+			OutputIndex:    index,
+			Delta:          delta,
+		}})
+	}
+
+	s.out = append(s.out,
+		&responses.ResponseChunk{OfReasoningSummaryTextDone: &responses.ChunkReasoningSummaryText[constants.ChunkTypeReasoningSummaryTextDone]{
+			SequenceNumber: s.nextSeq(),
+			ItemId:         r.ID,
+			OutputIndex:    index,
+			Text:           utils.Ptr(summary),
+		}},
+		&responses.ResponseChunk{OfReasoningSummaryPartDone: &responses.ChunkReasoningSummaryPart[constants.ChunkTypeReasoningSummaryPartDone]{
+			SequenceNumber: s.nextSeq(),
+			ItemId:         r.ID,
+			OutputIndex:    index,
+			Part:           responses.SummaryTextContent{Text: summary},
+		}},
+		&responses.ResponseChunk{OfOutputItemDone: &responses.ChunkOutputItem[constants.ChunkTypeOutputItemDone]{
+			SequenceNumber: s.nextSeq(),
+			OutputIndex:    index,
+			Item: responses.ChunkOutputItemData{
+				Type:    "reasoning",
+				Id:      r.ID,
+				Summary: &[]responses.SummaryTextContent{{Text: summary}},
+			},
+		}},
+	)
+}
+
+func (s *stream) functionCall(index int, call *responses.FunctionCallMessage) {
+	s.out = append(s.out, &responses.ResponseChunk{OfOutputItemAdded: &responses.ChunkOutputItem[constants.ChunkTypeOutputItemAdded]{
+		SequenceNumber: s.nextSeq(),
+		OutputIndex:    index,
+		Item: responses.ChunkOutputItemData{
+			Type:      "function_call",
+			Id:        call.ID,
+			Status:    "in_progress",
+			CallID:    utils.Ptr(call.CallID),
+			Name:      utils.Ptr(call.Name),
+			Arguments: utils.Ptr(""),
+		},
+	}})
+
+	for _, delta := range splitArguments(call.Arguments) {
+		s.out = append(s.out, &responses.ResponseChunk{OfFunctionCallArgumentsDelta: &responses.ChunkFunctionCall[constants.ChunkTypeFunctionCallArgumentsDelta]{
+			SequenceNumber: s.nextSeq(),
+			ItemId:         call.ID,
+			OutputIndex:    index,
+			Delta:          delta,
+		}})
+	}
+
+	s.out = append(s.out,
+		&responses.ResponseChunk{OfFunctionCallArgumentsDone: &responses.ChunkFunctionCall[constants.ChunkTypeFunctionCallArgumentsDone]{
+			SequenceNumber: s.nextSeq(),
+			ItemId:         call.ID,
+			OutputIndex:    index,
+			Arguments:      call.Arguments,
+		}},
+		&responses.ResponseChunk{OfOutputItemDone: &responses.ChunkOutputItem[constants.ChunkTypeOutputItemDone]{
+			SequenceNumber: s.nextSeq(),
+			OutputIndex:    index,
+			Item: responses.ChunkOutputItemData{
+				Type:      "function_call",
+				Id:        call.ID,
+				Status:    "completed",
+				CallID:    utils.Ptr(call.CallID),
+				Name:      utils.Ptr(call.Name),
+				Arguments: utils.Ptr(call.Arguments),
+			},
+		}},
+	)
+}
+
+// splitDeltas cuts text the way providers tend to: a word at a time, with
+// the separating space kept on the preceding word.
+func splitDeltas(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.SplitAfter(text, " ")
+}
+
+// splitArguments cuts JSON arguments into fixed-size fragments, which is
+// enough to exercise reassembly without caring where the cuts land.
+func splitArguments(args string) []string {
+	const size = 8
+
+	var out []string
+	for len(args) > size {
+		out = append(out, args[:size])
+		args = args[size:]
+	}
+	if args != "" {
+		out = append(out, args)
+	}
+	return out
+}
+
+// foldChunks turns a raw scripted stream into a response for NewResponses,
+// taking each finished item and the usage from response.completed.
+func foldChunks(chunks []*responses.ResponseChunk) *responses.Response {
+	resp := &responses.Response{Output: []responses.OutputMessageUnion{}}
+
+	for _, chunk := range chunks {
+		switch {
+		case chunk.OfResponseCreated != nil:
+			resp.ID = chunk.OfResponseCreated.Response.Id
+			resp.Model = chunk.OfResponseCreated.Response.Model
+
+		case chunk.OfOutputItemDone != nil:
+			item := chunk.OfOutputItemDone.Item
+			switch item.Type {
+			case "message":
+				content := responses.OutputContent{}
+				if item.Content != nil {
+					for _, c := range *item.Content {
+						if c.OfOutputText != nil {
+							content = append(content, responses.OutputContentUnion{OfOutputText: c.OfOutputText})
+						}
+					}
+				}
+				resp.Output = append(resp.Output, responses.OutputMessageUnion{OfOutputMessage: &responses.OutputMessage{
+					ID: item.Id, Role: constants.RoleAssistant, Content: &content,
+				}})
+			case "function_call":
+				call := &responses.FunctionCallMessage{ID: item.Id}
+				if item.CallID != nil {
+					call.CallID = *item.CallID
+				}
+				if item.Name != nil {
+					call.Name = *item.Name
+				}
+				if item.Arguments != nil {
+					call.Arguments = *item.Arguments
+				}
+				resp.Output = append(resp.Output, responses.OutputMessageUnion{OfFunctionCall: call})
+			case "reasoning":
+				r := &responses.ReasoningMessage{ID: item.Id, EncryptedContent: item.EncryptedContent}
+				if item.Summary != nil {
+					r.Summary = *item.Summary
+				}
+				resp.Output = append(resp.Output, responses.OutputMessageUnion{OfReasoning: r})
+			}
+
+		case chunk.OfResponseCompleted != nil:
+			usage := chunk.OfResponseCompleted.Response.Usage
+			resp.Usage = &usage
+		}
+	}
+
+	return resp
+}
@@ -0,0 +1,89 @@
+// Package models describes the models and voices a provider offers, in a
+// provider-neutral shape for model pickers and validation.
+package models
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Provider is implemented by providers that can list their models. It is
+// not part of llm.Provider; callers type-assert for it.
+type Provider interface {
+	ListModels(ctx context.Context) ([]Model, error)
+}
+
+// VoiceProvider is implemented by speech providers that can list their
+// voices. Like Provider, callers type-assert for it.
+type VoiceProvider interface {
+	ListVoices(ctx context.Context) ([]Voice, error)
+}
+
+type Capability string
+
+const (
+	CapabilityText            Capability = "text"
+	CapabilityEmbeddings      Capability = "embeddings"
+	CapabilityImageGeneration Capability = "image_generation"
+	CapabilitySpeech          Capability = "speech"
+	CapabilityTranscription   Capability = "transcription"
+	CapabilityVideoGeneration Capability = "video_generation"
+	CapabilityRealtime        Capability = "realtime"
+)
+
+type Model struct {
+	// ID is the model's id as requests take it, without the provider prefix.
+	ID string `json:"id"`
+	// Provider is the llm.ProviderName the model was listed by. Providers
+	// leave it empty; the gateway fills it in, so Provider + "/" + ID is
+	// what LLMClient.Model takes.
+	Provider    string `json:"provider,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Capabilities is what the model can be used for, as far as the
+	// provider's listing tells. Empty when it does not say.
+	Capabilities    []Capability `json:"capabilities,omitempty"`
+	ContextWindow   *int         `json:"context_window,omitempty"`
+	MaxOutputTokens *int         `json:"max_output_tokens,omitempty"`
+	OwnedBy         string       `json:"owned_by,omitempty"`
+	CreatedAt       *time.Time   `json:"created_at,omitempty"`
+}
+
+type Voice struct {
+	ID          string `json:"id"`
+	Provider    string `json:"provider,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Gender      string `json:"gender,omitempty"`
+	// Languages are the languages the voice speaks, as provider language
+	// codes. Empty when the provider does not say.
+	Languages []string `json:"languages,omitempty"`
+	// Models are the models the voice can be used with. Empty means any of
+	// the provider's speech models.
+	Models     []string `json:"models,omitempty"`
+	PreviewURL string   `json:"preview_url,omitempty"`
+}
+
+// CapabilitiesFromID guesses a model's capabilities from its id, for
+// providers whose listing carries nothing else (OpenAI, xAI, Ollama).
+func CapabilitiesFromID(id string) []Capability {
+	id = strings.ToLower(id)
+	switch {
+	case strings.Contains(id, "embed"):
+		return []Capability{CapabilityEmbeddings}
+	case strings.Contains(id, "realtime"):
+		return []Capability{CapabilityRealtime}
+	case strings.Contains(id, "transcribe"), strings.HasPrefix(id, "whisper"):
+		return []Capability{CapabilityTranscription}
+	case strings.Contains(id, "tts"):
+		return []Capability{CapabilitySpeech}
+	case strings.HasPrefix(id, "sora"), strings.Contains(id, "video"):
+		return []Capability{CapabilityVideoGeneration}
+	case strings.HasPrefix(id, "dall-e"), strings.Contains(id, "image"):
+		return []Capability{CapabilityImageGeneration}
+	case strings.Contains(id, "moderation"), strings.Contains(id, "search-api"):
+		return nil
+	}
+	return []Capability{CapabilityText}
+}
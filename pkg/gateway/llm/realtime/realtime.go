@@ -0,0 +1,204 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrSessionClosed is returned by Session methods once the session is closed,
+// by either side.
+var ErrSessionClosed = errors.New("realtime session closed")
+
+// Provider is implemented by providers that support realtime sessions. It is
+// not part of llm.Provider; callers type-assert for it.
+type Provider interface {
+	NewRealtimeSession(ctx context.Context, cfg *SessionConfig) (Session, error)
+}
+
+// Session is a live, bidirectional connection to a realtime model. Audio and
+// text go in through the Send methods; everything the model says or does
+// comes out of Events, which is closed when the session ends.
+//
+// Send methods are safe for concurrent use.
+type Session interface {
+	// SendAudio appends raw audio, in the session's input format, to the
+	// input buffer. With server VAD on, the model decides when the user has
+	// finished speaking; with it off, call CommitAudio.
+	SendAudio(ctx context.Context, audio []byte) error
+
+	// CommitAudio marks the end of the user's turn when server VAD is off.
+	CommitAudio(ctx context.Context) error
+
+	// SendText adds a user text message and asks the model to respond.
+	SendText(ctx context.Context, text string) error
+
+	// SendFunctionResult returns the output of a function call the model
+	// made. It does not by itself ask the model to continue; call
+	// CreateResponse once every pending call has a result.
+	SendFunctionResult(ctx context.Context, callID string, output string) error
+
+	// CreateResponse asks the model to respond to the conversation so far.
+	// Providers that continue on their own after function results treat it
+	// as a no-op.
+	CreateResponse(ctx context.Context) error
+
+	// Interrupt stops the response in progress. audioPlayedMs is how much of
+	// its audio the listener actually heard, so the provider can trim the
+	// model's memory of what it said. Audio still arriving for the
+	// interrupted response is dropped.
+	Interrupt(ctx context.Context, audioPlayedMs int) error
+
+	// Events delivers normalized server events until the session ends.
+	Events() <-chan *Event
+
+	Close() error
+}
+
+type SessionConfig struct {
+	Model        string `json:"model"`
+	Instructions string `json:"instructions,omitempty"`
+	Voice        string `json:"voice,omitempty"`
+
+	// Modalities the model responds in. Defaults to audio.
+	Modalities []Modality `json:"modalities,omitempty"`
+
+	// InputAudio and OutputAudio default to the provider's native PCM
+	// format: 24kHz in and out for OpenAI, 16kHz in and 24kHz out for
+	// Gemini.
+	InputAudio  *AudioFormat `json:"input_audio,omitempty"`
+	OutputAudio *AudioFormat `json:"output_audio,omitempty"`
+
+	// TurnDetection configures server-side voice activity detection. Nil
+	// keeps the provider's default, which is on.
+	TurnDetection *TurnDetection `json:"turn_detection,omitempty"`
+
+	// InputTranscription, when set, asks for transcripts of the user's
+	// audio as EventInputTranscript. Model is only used by OpenAI.
+	InputTranscription *InputTranscription `json:"input_transcription,omitempty"`
+
+	Tools       []Tool   `json:"tools,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+}
+
+type Modality string
+
+const (
+	ModalityAudio Modality = "audio"
+	ModalityText  Modality = "text"
+)
+
+// AudioFormat describes raw little-endian 16-bit mono PCM.
+type AudioFormat struct {
+	SampleRate int `json:"sample_rate"`
+}
+
+type TurnDetection struct {
+	// Disabled turns server VAD off. Turns then end with CommitAudio.
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Threshold is the activation threshold between 0 and 1 (OpenAI only).
+	Threshold *float64 `json:"threshold,omitempty"`
+
+	PrefixPaddingMs   *int `json:"prefix_padding_ms,omitempty"`
+	SilenceDurationMs *int `json:"silence_duration_ms,omitempty"`
+}
+
+type InputTranscription struct {
+	Model string `json:"model,omitempty"`
+}
+
+type Tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+}
+
+type EventType string
+
+const (
+	// EventSessionCreated is the first event of every session.
+	EventSessionCreated EventType = "session.created"
+
+	// EventSpeechStarted and EventSpeechStopped come from server VAD.
+	EventSpeechStarted EventType = "input.speech_started"
+	EventSpeechStopped EventType = "input.speech_stopped"
+
+	// EventInputTranscript carries a transcript of the user's audio.
+	EventInputTranscript EventType = "input.transcript"
+
+	// EventAudioDelta carries a chunk of the model's audio in the output
+	// format; EventAudioDone ends the audio of one response.
+	EventAudioDelta EventType = "output.audio.delta"
+	EventAudioDone  EventType = "output.audio.done"
+
+	// EventOutputTranscriptDelta carries the text of what the model is saying.
+	EventOutputTranscriptDelta EventType = "output.transcript.delta"
+
+	// EventTextDelta and EventTextDone carry the model's text output when it
+	// responds in text.
+	EventTextDelta EventType = "output.text.delta"
+	EventTextDone  EventType = "output.text.done"
+
+	// EventFunctionCall is a complete function call; reply with
+	// SendFunctionResult.
+	EventFunctionCall EventType = "function_call"
+
+	// EventFunctionCallOutput reports the result a caller sent back for a
+	// function call, with the output in Text. Providers never emit it; it
+	// comes from runners that execute tools on the session's behalf.
+	EventFunctionCallOutput EventType = "function_call.output"
+
+	// EventResponseDone ends one model response.
+	EventResponseDone EventType = "response.done"
+
+	// EventInterrupted means the response in progress was cut off, either
+	// by the user talking over it (barge-in) or by Interrupt. Playback of
+	// buffered audio should stop.
+	EventInterrupted EventType = "response.interrupted"
+
+	EventError EventType = "error"
+)
+
+type Event struct {
+	Type EventType `json:"type"`
+
+	ResponseID string `json:"response_id,omitempty"`
+	ItemID     string `json:"item_id,omitempty"`
+
+	// Audio is decoded PCM for EventAudioDelta.
+	Audio []byte `json:"audio,omitempty"`
+
+	// Text is set for transcript and text events.
+	Text string `json:"text,omitempty"`
+
+	FunctionCall *FunctionCall `json:"function_call,omitempty"`
+
+	// Usage is set on EventResponseDone when the provider reports it.
+	Usage *Usage `json:"usage,omitempty"`
+
+	Error *Error `json:"error,omitempty"`
+}
+
+type FunctionCall struct {
+	CallID    string `json:"call_id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type Usage struct {
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
+	TotalTokens  int64 `json:"total_tokens"`
+}
+
+type Error struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return e.Message
+	}
+	return e.Code + ": " + e.Message
+}
@@ -0,0 +1,142 @@
+// Package realtimetest provides a local WebSocket stand-in for realtime
+// providers, so sessions — and the agents driving them — can be tested
+// without a network.
+//
+// The server plays the provider's side by hand: the test accepts the
+// session's connection, reads what the client sent and scripts the replies.
+//
+//	srv := realtimetest.NewServer()
+//	defer srv.Close()
+//
+//	session, _ := openai_realtime.Dial(ctx, &openai_realtime.Options{BaseURL: srv.URL}, cfg)
+//	conn, _ := srv.Accept(ctx)
+//	update, _ := conn.ReceiveType(ctx, "type", "session.update")
+//	conn.Send(map[string]any{"type": "session.created"})
+package realtimetest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"golang.org/x/net/websocket"
+)
+
+// Server accepts WebSocket connections on a local address. URL is its
+// http:// base; pass it as a provider's BaseURL.
+type Server struct {
+	URL string
+
+	srv   *httptest.Server
+	conns chan *Conn
+}
+
+func NewServer() *Server {
+	s := &Server{conns: make(chan *Conn, 8)}
+	s.srv = httptest.NewServer(websocket.Server{Handler: s.serve})
+	s.URL = s.srv.URL
+	return s
+}
+
+// Accept waits for the next client connection.
+func (s *Server) Accept(ctx context.Context) (*Conn, error) {
+	select {
+	case c := <-s.conns:
+		return c, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("realtimetest: no connection: %w", ctx.Err())
+	}
+}
+
+func (s *Server) Close() {
+	s.srv.CloseClientConnections()
+	s.srv.Close()
+}
+
+func (s *Server) serve(ws *websocket.Conn) {
+	c := &Conn{
+		Request:  ws.Request(),
+		ws:       ws,
+		messages: make(chan map[string]any, 256),
+		closed:   make(chan struct{}),
+	}
+	s.conns <- c
+
+	defer close(c.messages)
+	for {
+		var raw []byte
+		if err := websocket.Message.Receive(ws, &raw); err != nil {
+			return
+		}
+		var msg map[string]any
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			continue
+		}
+		select {
+		case c.messages <- msg:
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+// Conn is the server end of one client connection.
+type Conn struct {
+	// Request is the client's handshake, with its URL and headers.
+	Request *http.Request
+
+	ws       *websocket.Conn
+	writeMu  sync.Mutex
+	messages chan map[string]any
+	closed   chan struct{}
+	once     sync.Once
+}
+
+// Send writes v to the client as one JSON text message.
+func (c *Conn) Send(v any) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return websocket.JSON.Send(c.ws, v)
+}
+
+// Receive returns the next message the client sent.
+func (c *Conn) Receive(ctx context.Context) (map[string]any, error) {
+	select {
+	case msg, ok := <-c.messages:
+		if !ok {
+			return nil, errors.New("realtimetest: client disconnected")
+		}
+		return msg, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("realtimetest: nothing received: %w", ctx.Err())
+	}
+}
+
+// ReceiveType skips client messages until one whose top-level key has the
+// given value — e.g. ("type", "response.create") — and returns it. An
+// empty value matches any message that has the key at all.
+func (c *Conn) ReceiveType(ctx context.Context, key, value string) (map[string]any, error) {
+	for {
+		msg, err := c.Receive(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("waiting for %s=%q: %w", key, value, err)
+		}
+		v, ok := msg[key]
+		if !ok {
+			continue
+		}
+		if s, _ := v.(string); value == "" || s == value {
+			return msg, nil
+		}
+	}
+}
+
+// Close drops the connection, as a provider ending the session would.
+func (c *Conn) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return c.ws.Close()
+}
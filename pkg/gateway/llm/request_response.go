@@ -8,16 +8,27 @@ import (
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/speech"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/video_generation"
 )
 
 type Request struct {
-	OfEmbeddingsInput     *embeddings.Request
-	OfResponsesInput      *responses.Request
+	OfEmbeddingsInput *embeddings.Request
+	OfResponsesInput  *responses.Request
+	// A stored response is retrieved, cancelled or streamed again by id;
+	// see responses.BackgroundProvider.
+	OfResponsesGet        *responses.GetResponseRequest
+	OfResponsesCancel     *responses.CancelResponseRequest
+	OfResponsesStreamFrom *responses.StreamResponseFromRequest
 	OfChatCompletionInput *chat_completion.Request
 	OfSpeech              *speech.Request
 	OfTranscription       *transcription.Request
 	OfImageGeneration     *image_generation.Request
 	OfImageEdit           *image_edit.Request
+	// A video generation job is started, polled and downloaded in three
+	// requests; exactly one of these is set.
+	OfVideoGeneration         *video_generation.Request
+	OfVideoGenerationGet      *video_generation.GetRequest
+	OfVideoGenerationDownload *video_generation.DownloadRequest
 }
 
 func (r *Request) GetRequestedModel() string {
@@ -25,6 +36,18 @@ func (r *Request) GetRequestedModel() string {
 		return r.OfResponsesInput.Model
 	}
 
+	if r.OfResponsesGet != nil {
+		return r.OfResponsesGet.Model
+	}
+
+	if r.OfResponsesCancel != nil {
+		return r.OfResponsesCancel.Model
+	}
+
+	if r.OfResponsesStreamFrom != nil {
+		return r.OfResponsesStreamFrom.Model
+	}
+
 	if r.OfEmbeddingsInput != nil {
 		return r.OfEmbeddingsInput.Model
 	}
@@ -49,6 +72,18 @@ func (r *Request) GetRequestedModel() string {
 		return r.OfImageEdit.Model
 	}
 
+	if r.OfVideoGeneration != nil {
+		return r.OfVideoGeneration.Model
+	}
+
+	if r.OfVideoGenerationGet != nil {
+		return r.OfVideoGenerationGet.Model
+	}
+
+	if r.OfVideoGenerationDownload != nil {
+		return r.OfVideoGenerationDownload.Model
+	}
+
 	return ""
 }
 
@@ -60,6 +95,8 @@ type Response struct {
 	OfTranscription        *transcription.Response
 	OfImageGeneration      *image_generation.Response
 	OfImageEdit            *image_edit.Response
+	OfVideoGeneration      *video_generation.Response
+	OfVideoContent         *video_generation.Content
 	Error                  *Error
 }
 
@@ -67,6 +104,7 @@ type StreamingResponse struct {
 	ResponsesStreamData      chan *responses.ResponseChunk
 	ChatCompletionStreamData chan *chat_completion.ResponseChunk
 	SpeechStreamData         chan *speech.ResponseChunk
+	TranscriptionStreamData  chan *transcription.ResponseChunk
 }
 
 type Error struct {
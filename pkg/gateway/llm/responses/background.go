@@ -0,0 +1,57 @@
+package responses
+
+import "context"
+
+// Status of a stored response (Response.Status). A background response
+// starts queued and ends in one of the other states but in_progress.
+const (
+	ResponseStatusQueued     = "queued"
+	ResponseStatusInProgress = "in_progress"
+	ResponseStatusCompleted  = "completed"
+	ResponseStatusFailed     = "failed"
+	ResponseStatusCancelled  = "cancelled"
+	ResponseStatusIncomplete = "incomplete"
+)
+
+// BackgroundProvider is implemented by providers that store responses and
+// can run them in the background (Parameters.Background and Store). It is
+// not part of llm.Provider; callers type-assert for it.
+type BackgroundProvider interface {
+	// GetResponse retrieves a stored response, to poll a background one.
+	GetResponse(ctx context.Context, in *GetResponseRequest) (*Response, error)
+	// CancelResponse cancels a background response that is still running.
+	CancelResponse(ctx context.Context, in *CancelResponseRequest) (*Response, error)
+	// StreamResponseFrom streams a background response's events again,
+	// starting after the one numbered SequenceNumber. It reattaches to a
+	// response whose original stream was lost.
+	StreamResponseFrom(ctx context.Context, in *StreamResponseFromRequest) (chan *ResponseChunk, error)
+}
+
+type GetResponseRequest struct {
+	// Model routes the request to the provider that holds the response.
+	Model string `json:"model"`
+	ID    string `json:"id"`
+}
+
+type CancelResponseRequest struct {
+	Model string `json:"model"`
+	ID    string `json:"id"`
+}
+
+type StreamResponseFromRequest struct {
+	Model string `json:"model"`
+	ID    string `json:"id"`
+	// SequenceNumber is the last event already seen; streaming resumes with
+	// the one after it.
+	SequenceNumber int `json:"sequence_number"`
+}
+
+// Done reports whether the response has finished running, successfully or
+// not.
+func (r *Response) Done() bool {
+	switch r.Status {
+	case ResponseStatusQueued, ResponseStatusInProgress:
+		return false
+	}
+	return true
+}
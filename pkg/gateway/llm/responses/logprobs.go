@@ -0,0 +1,73 @@
+package responses
+
+import (
+	"math"
+	"slices"
+)
+
+// Logprob is the log probability of one output token. TopLogprobs holds the
+// most likely tokens at its position, the chosen one among them, when
+// Parameters.TopLogprobs asked for them.
+type Logprob struct {
+	Token       string       `json:"token"`
+	Logprob     float64      `json:"logprob"`
+	Bytes       []int        `json:"bytes,omitempty"`
+	TopLogprobs []TopLogprob `json:"top_logprobs,omitempty"`
+}
+
+type TopLogprob struct {
+	Token   string  `json:"token"`
+	Logprob float64 `json:"logprob"`
+	Bytes   []int   `json:"bytes,omitempty"`
+}
+
+// LogprobsRequested reports whether the request asks for token log
+// probabilities, through Include or TopLogprobs.
+func (p *Parameters) LogprobsRequested() bool {
+	return slices.Contains(p.Include, IncludableMessageOutputTextLogprobs) ||
+		(p.TopLogprobs != nil && *p.TopLogprobs > 0)
+}
+
+// SequenceLogprob is the log probability of a whole token sequence: the sum
+// of its tokens' log probabilities. It is 0 for no tokens.
+func SequenceLogprob(logprobs []Logprob) float64 {
+	var sum float64
+	for _, lp := range logprobs {
+		sum += lp.Logprob
+	}
+	return sum
+}
+
+// SequenceProbability is the probability the model gave to generating
+// exactly this token sequence. For a classifier answering with a label it is
+// the confidence in that label.
+func SequenceProbability(logprobs []Logprob) float64 {
+	return math.Exp(SequenceLogprob(logprobs))
+}
+
+// MeanTokenProbability is the geometric mean of the tokens' probabilities.
+// Unlike SequenceProbability it does not shrink with length, so it compares
+// answers of different lengths. It is 0 for no tokens.
+func MeanTokenProbability(logprobs []Logprob) float64 {
+	if len(logprobs) == 0 {
+		return 0
+	}
+	return math.Exp(SequenceLogprob(logprobs) / float64(len(logprobs)))
+}
+
+// OutputLogprobs returns the log probabilities of every output text token of
+// the response, in order. It is empty unless they were requested.
+func (r *Response) OutputLogprobs() []Logprob {
+	var out []Logprob
+	for _, item := range r.Output {
+		if item.OfOutputMessage == nil || item.OfOutputMessage.Content == nil {
+			continue
+		}
+		for _, c := range *item.OfOutputMessage.Content {
+			if c.OfOutputText != nil {
+				out = append(out, c.OfOutputText.Logprobs...)
+			}
+		}
+	}
+	return out
+}
@@ -18,17 +18,20 @@ type Request struct {
 }
 
 type Parameters struct {
-	Temperature     *float64          `json:"temperature,omitempty"`
-	MaxOutputTokens *int              `json:"max_output_tokens,omitempty"`
-	TopP            *float64          `json:"top_p,omitempty"`
-	TopLogprobs     *int64            `json:"top_logprobs,omitempty"`
-	Text            *TextFormat       `json:"text,omitempty"`
-	Background      *bool             `json:"background,omitempty"`
-	Reasoning       *ReasoningParam   `json:"reasoning,omitempty"`
-	Store           *bool             `json:"store,omitempty"`
-	Include         []Includable      `json:"include,omitempty"`
-	Metadata        map[string]string `json:"metadata,omitempty"`
-	Stream          *bool             `json:"stream,omitempty"`
+	Temperature     *float64        `json:"temperature,omitempty"`
+	MaxOutputTokens *int            `json:"max_output_tokens,omitempty"`
+	TopP            *float64        `json:"top_p,omitempty"`
+	TopLogprobs     *int64          `json:"top_logprobs,omitempty"`
+	Text            *TextFormat     `json:"text,omitempty"`
+	Background      *bool           `json:"background,omitempty"`
+	Reasoning       *ReasoningParam `json:"reasoning,omitempty"`
+	Store           *bool           `json:"store,omitempty"`
+	// PreviousResponseID continues from a stored response: the provider
+	// supplies its conversation, so Input carries only the new turn.
+	PreviousResponseID *string           `json:"previous_response_id,omitempty"`
+	Include            []Includable      `json:"include,omitempty"`
+	Metadata           map[string]string `json:"metadata,omitempty"`
+	Stream             *bool             `json:"stream,omitempty"`
 
 	MaxToolCalls      *int  `json:"max_tool_calls,omitempty"`
 	ParallelToolCalls *bool `json:"parallel_tool_calls,omitempty"`
@@ -111,15 +114,18 @@ type InputMessageUnion struct {
 	OfImageGenerationCall             *ImageGenerationCallMessage             `json:",omitempty,inline"`
 	OfWebSearchCall                   *WebSearchCallMessage                   `json:",omitempty,inline"`
 	OfCodeInterpreterCall             *CodeInterpreterCallMessage             `json:",omitempty,inline"`
-	//OfFileSearchCall       *ResponseFileSearchToolCallParam            `json:",omitempty,inline"`
+	OfFileSearchCall                  *FileSearchCallMessage                  `json:",omitempty,inline"`
+	OfMcpListTools                    *McpListToolsMessage                    `json:",omitempty,inline"`
+	OfMcpApprovalRequest              *McpApprovalRequestMessage              `json:",omitempty,inline"`
+	OfMcpCall                         *McpCallMessage                         `json:",omitempty,inline"`
+	OfWebFetchCall                    *WebFetchCallMessage                    `json:",omitempty,inline"`
+	OfURLContextCall                  *URLContextCallMessage                  `json:",omitempty,inline"`
+	OfGoogleMapsCall                  *GoogleMapsCallMessage                  `json:",omitempty,inline"`
 	//OfComputerCall         *ResponseComputerToolCallParam              `json:",omitempty,inline"`
 	//OfComputerCallOutput   *ResponseInputItemComputerCallOutputParam   `json:",omitempty,inline"`
 	//OfLocalShellCall       *ResponseInputItemLocalShellCallParam       `json:",omitempty,inline"`
 	//OfLocalShellCallOutput *ResponseInputItemLocalShellCallOutputParam `json:",omitempty,inline"`
-	//OfMcpListTools         *ResponseInputItemMcpListToolsParam         `json:",omitempty,inline"`
-	//OfMcpApprovalRequest   *ResponseInputItemMcpApprovalRequestParam   `json:",omitempty,inline"`
 	//OfMcpApprovalResponse  *ResponseInputItemMcpApprovalResponseParam  `json:",omitempty,inline"`
-	//OfMcpCall              *ResponseInputItemMcpCallParam              `json:",omitempty,inline"`
 	//OfCustomToolCallOutput *ResponseCustomToolCallOutputParam          `json:",omitempty,inline"`
 	//OfCustomToolCall       *ResponseCustomToolCallParam                `json:",omitempty,inline"`
 	//OfItemReference        *ResponseInputItemItemReferenceParam        `json:",omitempty,inline"`
@@ -166,6 +172,34 @@ func (u *InputMessageUnion) ID() string {
 		return u.OfCodeInterpreterCall.ID
 	}
 
+	if u.OfFileSearchCall != nil {
+		return u.OfFileSearchCall.ID
+	}
+
+	if u.OfMcpListTools != nil {
+		return u.OfMcpListTools.ID
+	}
+
+	if u.OfMcpApprovalRequest != nil {
+		return u.OfMcpApprovalRequest.ID
+	}
+
+	if u.OfMcpCall != nil {
+		return u.OfMcpCall.ID
+	}
+
+	if u.OfWebFetchCall != nil {
+		return u.OfWebFetchCall.ID
+	}
+
+	if u.OfURLContextCall != nil {
+		return u.OfURLContextCall.ID
+	}
+
+	if u.OfGoogleMapsCall != nil {
+		return u.OfGoogleMapsCall.ID
+	}
+
 	return ""
 }
 
@@ -230,6 +264,48 @@ func (u *InputMessageUnion) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 
+	var fileSearchCallMsg FileSearchCallMessage
+	if err := sonic.Unmarshal(data, &fileSearchCallMsg); err == nil {
+		u.OfFileSearchCall = &fileSearchCallMsg
+		return nil
+	}
+
+	var mcpListToolsMsg McpListToolsMessage
+	if err := sonic.Unmarshal(data, &mcpListToolsMsg); err == nil {
+		u.OfMcpListTools = &mcpListToolsMsg
+		return nil
+	}
+
+	var mcpApprovalRequestMsg McpApprovalRequestMessage
+	if err := sonic.Unmarshal(data, &mcpApprovalRequestMsg); err == nil {
+		u.OfMcpApprovalRequest = &mcpApprovalRequestMsg
+		return nil
+	}
+
+	var mcpCallMsg McpCallMessage
+	if err := sonic.Unmarshal(data, &mcpCallMsg); err == nil {
+		u.OfMcpCall = &mcpCallMsg
+		return nil
+	}
+
+	var webFetchCallMsg WebFetchCallMessage
+	if err := sonic.Unmarshal(data, &webFetchCallMsg); err == nil {
+		u.OfWebFetchCall = &webFetchCallMsg
+		return nil
+	}
+
+	var urlContextCallMsg URLContextCallMessage
+	if err := sonic.Unmarshal(data, &urlContextCallMsg); err == nil {
+		u.OfURLContextCall = &urlContextCallMsg
+		return nil
+	}
+
+	var googleMapsCallMsg GoogleMapsCallMessage
+	if err := sonic.Unmarshal(data, &googleMapsCallMsg); err == nil {
+		u.OfGoogleMapsCall = &googleMapsCallMsg
+		return nil
+	}
+
 	return errors.New("invalid input message union")
 }
 
@@ -274,6 +350,34 @@ func (u *InputMessageUnion) MarshalJSON() ([]byte, error) {
 		return sonic.Marshal(u.OfCodeInterpreterCall)
 	}
 
+	if u.OfFileSearchCall != nil {
+		return sonic.Marshal(u.OfFileSearchCall)
+	}
+
+	if u.OfMcpListTools != nil {
+		return sonic.Marshal(u.OfMcpListTools)
+	}
+
+	if u.OfMcpApprovalRequest != nil {
+		return sonic.Marshal(u.OfMcpApprovalRequest)
+	}
+
+	if u.OfMcpCall != nil {
+		return sonic.Marshal(u.OfMcpCall)
+	}
+
+	if u.OfWebFetchCall != nil {
+		return sonic.Marshal(u.OfWebFetchCall)
+	}
+
+	if u.OfURLContextCall != nil {
+		return sonic.Marshal(u.OfURLContextCall)
+	}
+
+	if u.OfGoogleMapsCall != nil {
+		return sonic.Marshal(u.OfGoogleMapsCall)
+	}
+
 	return nil, nil
 }
 
@@ -376,6 +480,99 @@ type CodeInterpreterCallOutputParam struct {
 	Logs string `json:"logs"`
 }
 
+type FileSearchCallMessage struct {
+	Type    constants.MessageTypeFileSearchCall `json:"type"`
+	ID      string                              `json:"id"`
+	Status  string                              `json:"status"` // "in_progress", "searching", "completed", "incomplete", "failed"
+	Queries []string                            `json:"queries"`
+	Results []FileSearchCallResult              `json:"results,omitempty"` // Only with include=["file_search_call.results"]
+}
+
+type FileSearchCallResult struct {
+	FileID     string         `json:"file_id"`
+	Filename   string         `json:"filename"`
+	Score      float64        `json:"score"`
+	Text       string         `json:"text"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// McpListToolsMessage lists the tools of a remote MCP server. The provider
+// emits it the first time a server is used, and reads it back from the
+// input on later turns instead of listing again.
+type McpListToolsMessage struct {
+	Type        constants.MessageTypeMcpListTools `json:"type"`
+	ID          string                            `json:"id"`
+	ServerLabel string                            `json:"server_label"`
+	Tools       []McpListToolsTool                `json:"tools"`
+	Error       *string                           `json:"error,omitempty"`
+}
+
+type McpListToolsTool struct {
+	Name        string         `json:"name"`
+	Description *string        `json:"description,omitempty"`
+	InputSchema map[string]any `json:"input_schema"`
+	Annotations any            `json:"annotations,omitempty"`
+}
+
+type McpApprovalRequestMessage struct {
+	Type        constants.MessageTypeMcpApprovalRequest `json:"type"`
+	ID          string                                  `json:"id"`
+	ServerLabel string                                  `json:"server_label"`
+	Name        string                                  `json:"name"`
+	Arguments   string                                  `json:"arguments"`
+}
+
+type McpCallMessage struct {
+	Type        constants.MessageTypeMcpCall `json:"type"`
+	ID          string                       `json:"id"`
+	Status      string                       `json:"status,omitempty"` // "in_progress", "completed", "incomplete", "calling", "failed"
+	ServerLabel string                       `json:"server_label"`
+	Name        string                       `json:"name"`
+	Arguments   string                       `json:"arguments"`
+	Output      *string                      `json:"output,omitempty"`
+	Error       *string                      `json:"error,omitempty"`
+}
+
+// WebFetchCallMessage is a URL fetched by the provider. Data is the page as
+// text, or base64 when MediaType is not "text/plain" (e.g. a PDF).
+type WebFetchCallMessage struct {
+	Type        constants.MessageTypeWebFetchCall `json:"type"`
+	ID          string                            `json:"id"`
+	Status      string                            `json:"status"` // "completed", "failed"
+	URL         string                            `json:"url"`
+	Title       string                            `json:"title,omitempty"`
+	MediaType   string                            `json:"media_type,omitempty"`
+	Data        string                            `json:"data,omitempty"`
+	RetrievedAt string                            `json:"retrieved_at,omitempty"`
+	ErrorCode   string                            `json:"error_code,omitempty"` // Set when Status is "failed"
+}
+
+type URLContextCallMessage struct {
+	Type   constants.MessageTypeURLContextCall `json:"type"`
+	ID     string                              `json:"id"`
+	Status string                              `json:"status"`
+	URLs   []URLContextCallURL                 `json:"urls"`
+}
+
+type URLContextCallURL struct {
+	URL    string `json:"url"`
+	Status string `json:"status"` // "URL_RETRIEVAL_STATUS_SUCCESS", "URL_RETRIEVAL_STATUS_ERROR", ...
+}
+
+type GoogleMapsCallMessage struct {
+	Type               constants.MessageTypeGoogleMapsCall `json:"type"`
+	ID                 string                              `json:"id"`
+	Status             string                              `json:"status"`
+	Places             []GoogleMapsCallPlace               `json:"places"`
+	WidgetContextToken *string                             `json:"widget_context_token,omitempty"`
+}
+
+type GoogleMapsCallPlace struct {
+	PlaceID string `json:"place_id"`
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+}
+
 type EasyInputContentUnion struct {
 	OfString           *string      `json:",omitempty"`
 	OfInputMessageList InputContent `json:",omitempty"`
@@ -480,6 +677,9 @@ type OutputTextContent struct {
 	Type        constants.ContentTypeOutputText `json:"type"`
 	Text        string                          `json:"text"`
 	Annotations []Annotation                    `json:"annotations"`
+	// Logprobs is set when the request asked for them (see
+	// Parameters.LogprobsRequested).
+	Logprobs []Logprob `json:"logprobs,omitempty"`
 }
 
 type ReasoningTextContent struct {
@@ -507,13 +707,28 @@ type SummaryTextContent struct {
 	Text string                           `json:"text"`
 }
 
+// Annotation is a citation attached to the output text. The citations of
+// every provider are normalized to it: a web page is a "url_citation", a
+// document or an uploaded file is a "file_citation". The provider's own
+// citation is kept in ExtraParams under the provider name, so it can be
+// replayed to that provider as it was.
 type Annotation struct {
 	Type       string `json:"type"` // Any of "file_citation", "url_citation", "container_file_citation", "file_path".
 	Title      string `json:"title"`
 	URL        string `json:"url"`
-	StartIndex int    `json:"start_index"`
+	StartIndex int    `json:"start_index"` // Character range of the output text the citation supports
 	EndIndex   int    `json:"end_index"`
 
+	// For "file_citation", "container_file_citation" and "file_path"
+	FileID      string `json:"file_id,omitempty"`
+	Filename    string `json:"filename,omitempty"`
+	ContainerID string `json:"container_id,omitempty"` // Only for "container_file_citation"
+	Index       *int   `json:"index,omitempty"`        // OpenAI places a file citation at a single character instead of a range
+
+	// Snippet is the passage of the source being cited, when the provider
+	// returns it.
+	Snippet string `json:"snippet,omitempty"`
+
 	ExtraParams map[string]any `json:"extra_params"`
 }
 
@@ -555,6 +770,11 @@ type ToolUnion struct {
 	OfImageGeneration *ImageGenerationTool `json:",omitempty"`
 	OfWebSearch       *WebSearchTool       `json:",omitempty"`
 	OfCodeExecution   *CodeExecutionTool   `json:",omitempty"`
+	OfFileSearch      *FileSearchTool      `json:",omitempty"`
+	OfMCP             *MCPTool             `json:",omitempty"`
+	OfWebFetch        *WebFetchTool        `json:",omitempty"`
+	OfURLContext      *URLContextTool      `json:",omitempty"`
+	OfGoogleMaps      *GoogleMapsTool      `json:",omitempty"`
 }
 
 func (u *ToolUnion) UnmarshalJSON(data []byte) error {
@@ -582,6 +802,36 @@ func (u *ToolUnion) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 
+	var fileSearchTool FileSearchTool
+	if err := sonic.Unmarshal(data, &fileSearchTool); err == nil {
+		u.OfFileSearch = &fileSearchTool
+		return nil
+	}
+
+	var mcpTool MCPTool
+	if err := sonic.Unmarshal(data, &mcpTool); err == nil {
+		u.OfMCP = &mcpTool
+		return nil
+	}
+
+	var webFetchTool WebFetchTool
+	if err := sonic.Unmarshal(data, &webFetchTool); err == nil {
+		u.OfWebFetch = &webFetchTool
+		return nil
+	}
+
+	var urlContextTool URLContextTool
+	if err := sonic.Unmarshal(data, &urlContextTool); err == nil {
+		u.OfURLContext = &urlContextTool
+		return nil
+	}
+
+	var googleMapsTool GoogleMapsTool
+	if err := sonic.Unmarshal(data, &googleMapsTool); err == nil {
+		u.OfGoogleMaps = &googleMapsTool
+		return nil
+	}
+
 	return errors.New("invalid tool union")
 }
 
@@ -602,6 +852,26 @@ func (u *ToolUnion) MarshalJSON() ([]byte, error) {
 		return sonic.Marshal(u.OfCodeExecution)
 	}
 
+	if u.OfFileSearch != nil {
+		return sonic.Marshal(u.OfFileSearch)
+	}
+
+	if u.OfMCP != nil {
+		return sonic.Marshal(u.OfMCP)
+	}
+
+	if u.OfWebFetch != nil {
+		return sonic.Marshal(u.OfWebFetch)
+	}
+
+	if u.OfURLContext != nil {
+		return sonic.Marshal(u.OfURLContext)
+	}
+
+	if u.OfGoogleMaps != nil {
+		return sonic.Marshal(u.OfGoogleMaps)
+	}
+
 	return nil, nil
 }
 
@@ -761,3 +1031,43 @@ type CodeExecutionToolContainerConfig struct {
 	FileIds     []string `json:"file_ids"`
 	MemoryLimit string   `json:"memory_limit"`
 }
+
+type FileSearchTool struct {
+	Type           constants.ToolTypeFileSearch `json:"type"` // file_search
+	VectorStoreIDs []string                     `json:"vector_store_ids"`
+	MaxNumResults  *int                         `json:"max_num_results,omitempty"`
+	Filters        map[string]any               `json:"filters,omitempty"` // comparison or compound filter on file attributes
+}
+
+// MCPTool gives the model the tools of a remote MCP server, called by the
+// provider rather than by the agent.
+type MCPTool struct {
+	Type              constants.ToolTypeMCP `json:"type"` // mcp
+	ServerLabel       string                `json:"server_label"`
+	ServerURL         string                `json:"server_url,omitempty"`
+	ServerDescription *string               `json:"server_description,omitempty"`
+	Headers           map[string]string     `json:"headers,omitempty"`
+	AllowedTools      any                   `json:"allowed_tools,omitempty"`    // a list of tool names, or {"tool_names": [...]}
+	RequireApproval   any                   `json:"require_approval,omitempty"` // "always", "never", or {"never": {"tool_names": [...]}}
+}
+
+// WebFetchTool lets the model fetch the content of a URL given in the
+// conversation or found by a web search.
+type WebFetchTool struct {
+	Type             constants.ToolTypeWebFetch `json:"type"` // web_fetch
+	MaxUses          *int                       `json:"max_uses,omitempty"`
+	AllowedDomains   []string                   `json:"allowed_domains,omitempty"`
+	BlockedDomains   []string                   `json:"blocked_domains,omitempty"`
+	MaxContentTokens *int                       `json:"max_content_tokens,omitempty"`
+}
+
+// URLContextTool lets the model read the URLs in the prompt.
+type URLContextTool struct {
+	Type constants.ToolTypeURLContext `json:"type"` // url_context
+}
+
+// GoogleMapsTool grounds the model's answer in Google Maps places.
+type GoogleMapsTool struct {
+	Type         constants.ToolTypeGoogleMaps `json:"type"` // google_maps
+	EnableWidget *bool                        `json:"enable_widget,omitempty"`
+}
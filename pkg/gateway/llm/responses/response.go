@@ -9,8 +9,11 @@ import (
 )
 
 type Response struct {
-	ID          string                 `json:"id"`
-	Model       string                 `json:"model"`
+	ID    string `json:"id"`
+	Model string `json:"model"`
+	// Status is set by providers that store responses: one of the
+	// ResponseStatus values. Empty otherwise.
+	Status      string                 `json:"status,omitempty"`
 	Output      []OutputMessageUnion   `json:"output"`
 	Usage       *Usage                 `json:"usage"`
 	Error       *Error                 `json:"error"`
@@ -26,7 +29,9 @@ type Error struct {
 }
 
 // OutputMessageUnion represents all possible message outputs from the model.
-// Model can output: "text", "function_call", "reasoning" or "image_generation_call"
+// Model can output: "text", "function_call", "reasoning", or a call to one of
+// the provider-hosted tools ("image_generation_call", "web_search_call",
+// "file_search_call", "mcp_call", ...).
 type OutputMessageUnion struct {
 	OfOutputMessage       *OutputMessage              `json:",omitempty"`
 	OfFunctionCall        *FunctionCallMessage        `json:",omitempty"`
@@ -34,6 +39,13 @@ type OutputMessageUnion struct {
 	OfImageGenerationCall *ImageGenerationCallMessage `json:",omitempty"`
 	OfWebSearchCall       *WebSearchCallMessage       `json:",omitempty"`
 	OfCodeInterpreterCall *CodeInterpreterCallMessage `json:",omitempty"`
+	OfFileSearchCall      *FileSearchCallMessage      `json:",omitempty"`
+	OfMcpListTools        *McpListToolsMessage        `json:",omitempty"`
+	OfMcpApprovalRequest  *McpApprovalRequestMessage  `json:",omitempty"`
+	OfMcpCall             *McpCallMessage             `json:",omitempty"`
+	OfWebFetchCall        *WebFetchCallMessage        `json:",omitempty"`
+	OfURLContextCall      *URLContextCallMessage      `json:",omitempty"`
+	OfGoogleMapsCall      *GoogleMapsCallMessage      `json:",omitempty"`
 }
 
 func (u *OutputMessageUnion) UnmarshalJSON(data []byte) error {
@@ -73,6 +85,48 @@ func (u *OutputMessageUnion) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 
+	var fileSearchCallMessage *FileSearchCallMessage
+	if err := sonic.Unmarshal(data, &fileSearchCallMessage); err == nil {
+		u.OfFileSearchCall = fileSearchCallMessage
+		return nil
+	}
+
+	var mcpListToolsMessage *McpListToolsMessage
+	if err := sonic.Unmarshal(data, &mcpListToolsMessage); err == nil {
+		u.OfMcpListTools = mcpListToolsMessage
+		return nil
+	}
+
+	var mcpApprovalRequestMessage *McpApprovalRequestMessage
+	if err := sonic.Unmarshal(data, &mcpApprovalRequestMessage); err == nil {
+		u.OfMcpApprovalRequest = mcpApprovalRequestMessage
+		return nil
+	}
+
+	var mcpCallMessage *McpCallMessage
+	if err := sonic.Unmarshal(data, &mcpCallMessage); err == nil {
+		u.OfMcpCall = mcpCallMessage
+		return nil
+	}
+
+	var webFetchCallMessage *WebFetchCallMessage
+	if err := sonic.Unmarshal(data, &webFetchCallMessage); err == nil {
+		u.OfWebFetchCall = webFetchCallMessage
+		return nil
+	}
+
+	var urlContextCallMessage *URLContextCallMessage
+	if err := sonic.Unmarshal(data, &urlContextCallMessage); err == nil {
+		u.OfURLContextCall = urlContextCallMessage
+		return nil
+	}
+
+	var googleMapsCallMessage *GoogleMapsCallMessage
+	if err := sonic.Unmarshal(data, &googleMapsCallMessage); err == nil {
+		u.OfGoogleMapsCall = googleMapsCallMessage
+		return nil
+	}
+
 	return errors.New("invalid output message union type")
 }
 
@@ -101,6 +155,34 @@ func (u *OutputMessageUnion) MarshalJSON() ([]byte, error) {
 		return sonic.Marshal(u.OfCodeInterpreterCall)
 	}
 
+	if u.OfFileSearchCall != nil {
+		return sonic.Marshal(u.OfFileSearchCall)
+	}
+
+	if u.OfMcpListTools != nil {
+		return sonic.Marshal(u.OfMcpListTools)
+	}
+
+	if u.OfMcpApprovalRequest != nil {
+		return sonic.Marshal(u.OfMcpApprovalRequest)
+	}
+
+	if u.OfMcpCall != nil {
+		return sonic.Marshal(u.OfMcpCall)
+	}
+
+	if u.OfWebFetchCall != nil {
+		return sonic.Marshal(u.OfWebFetchCall)
+	}
+
+	if u.OfURLContextCall != nil {
+		return sonic.Marshal(u.OfURLContextCall)
+	}
+
+	if u.OfGoogleMapsCall != nil {
+		return sonic.Marshal(u.OfGoogleMapsCall)
+	}
+
 	return nil, nil
 }
 
@@ -129,6 +211,34 @@ func (u *OutputMessageUnion) AsInput() (InputMessageUnion, error) {
 		return InputMessageUnion{OfCodeInterpreterCall: u.OfCodeInterpreterCall}, nil
 	}
 
+	if u.OfFileSearchCall != nil {
+		return InputMessageUnion{OfFileSearchCall: u.OfFileSearchCall}, nil
+	}
+
+	if u.OfMcpListTools != nil {
+		return InputMessageUnion{OfMcpListTools: u.OfMcpListTools}, nil
+	}
+
+	if u.OfMcpApprovalRequest != nil {
+		return InputMessageUnion{OfMcpApprovalRequest: u.OfMcpApprovalRequest}, nil
+	}
+
+	if u.OfMcpCall != nil {
+		return InputMessageUnion{OfMcpCall: u.OfMcpCall}, nil
+	}
+
+	if u.OfWebFetchCall != nil {
+		return InputMessageUnion{OfWebFetchCall: u.OfWebFetchCall}, nil
+	}
+
+	if u.OfURLContextCall != nil {
+		return InputMessageUnion{OfURLContextCall: u.OfURLContextCall}, nil
+	}
+
+	if u.OfGoogleMapsCall != nil {
+		return InputMessageUnion{OfGoogleMapsCall: u.OfGoogleMapsCall}, nil
+	}
+
 	return InputMessageUnion{}, errors.New("invalid output message union type")
 }
 
@@ -17,6 +17,11 @@ type ResponseChunk struct {
 	OfResponseInProgress *ChunkResponse[constants.ChunkTypeResponseInProgress] `json:",omitempty"`
 	OfResponseCompleted  *ChunkResponse[constants.ChunkTypeResponseCompleted]  `json:",omitempty"`
 
+	// OfResponseFailed ends a stream whose response failed; the reason is in
+	// Response.Error. OfError reports a failure outside any response.
+	OfResponseFailed *ChunkResponse[constants.ChunkTypeResponseFailed] `json:",omitempty"`
+	OfError          *ChunkError[constants.ChunkTypeError]             `json:",omitempty"`
+
 	OfOutputItemAdded *ChunkOutputItem[constants.ChunkTypeOutputItemAdded] `json:",omitempty"`
 	OfOutputItemDone  *ChunkOutputItem[constants.ChunkTypeOutputItemDone]  `json:",omitempty"`
 
@@ -57,6 +62,23 @@ type ResponseChunk struct {
 	OfCodeInterpreterCallInterpreting *ChunkCodeInterpreterCall[constants.ChunkTypeCodeInterpreterCallInterpreting] `json:",omitempty"`
 	OfCodeInterpreterCallCompleted    *ChunkCodeInterpreterCall[constants.ChunkTypeCodeInterpreterCallCompleted]    `json:",omitempty"`
 
+	// For output item of type "file_search_call"
+	OfFileSearchCallInProgress *ChunkFileSearchCall[constants.ChunkTypeFileSearchCallInProgress] `json:",omitempty"`
+	OfFileSearchCallSearching  *ChunkFileSearchCall[constants.ChunkTypeFileSearchCallSearching]  `json:",omitempty"`
+	OfFileSearchCallCompleted  *ChunkFileSearchCall[constants.ChunkTypeFileSearchCallCompleted]  `json:",omitempty"`
+
+	// For output item of type "mcp_call"
+	OfMcpCallInProgress     *ChunkMcpCall[constants.ChunkTypeMcpCallInProgress]     `json:",omitempty"`
+	OfMcpCallArgumentsDelta *ChunkMcpCall[constants.ChunkTypeMcpCallArgumentsDelta] `json:",omitempty"`
+	OfMcpCallArgumentsDone  *ChunkMcpCall[constants.ChunkTypeMcpCallArgumentsDone]  `json:",omitempty"`
+	OfMcpCallCompleted      *ChunkMcpCall[constants.ChunkTypeMcpCallCompleted]      `json:",omitempty"`
+	OfMcpCallFailed         *ChunkMcpCall[constants.ChunkTypeMcpCallFailed]         `json:",omitempty"`
+
+	// For output item of type "mcp_list_tools"
+	OfMcpListToolsInProgress *ChunkMcpListTools[constants.ChunkTypeMcpListToolsInProgress] `json:",omitempty"`
+	OfMcpListToolsCompleted  *ChunkMcpListTools[constants.ChunkTypeMcpListToolsCompleted]  `json:",omitempty"`
+	OfMcpListToolsFailed     *ChunkMcpListTools[constants.ChunkTypeMcpListToolsFailed]     `json:",omitempty"`
+
 	// Custom Chunks
 	OfRunCreated         *ChunkRun[constants.ChunkTypeRunCreated]    `json:",omitempty"`
 	OfRunInProgress      *ChunkRun[constants.ChunkTypeRunInProgress] `json:",omitempty"`
@@ -64,12 +86,25 @@ type ResponseChunk struct {
 	OfRunCompleted       *ChunkRun[constants.ChunkTypeRunCompleted]  `json:",omitempty"`
 	OfFunctionCallOutput *FunctionCallOutputMessage                  `json:",omitempty"`
 
+	// OfRunGuardrailTripped ends a run that a guardrail blocked. The
+	// guardrail's name, stage and reason are in RunState.Guardrail.
+	OfRunGuardrailTripped *ChunkRun[constants.ChunkTypeRunGuardrailTripped] `json:",omitempty"`
+
 	// OfToolProgress carries a mid-execution progress update emitted by a
 	// running tool call (function tool, MCP tool, etc.). It is a live,
 	// best-effort side stream keyed by CallID — it never enters history and
 	// is not replayed under durable runtimes. For MCP tools it is the
 	// SDK-native projection of the server's notifications/progress.
 	OfToolProgress *ChunkToolProgress[constants.ChunkTypeToolProgress] `json:",omitempty"`
+
+	// OfStateUpdated carries the state a run shares with its client — a
+	// planner's plan — whole, each time it changes.
+	OfStateUpdated *ChunkState[constants.ChunkTypeStateUpdated] `json:",omitempty"`
+
+	// OfReflectionCritique carries a critic's verdict on the agent's draft
+	// answer. One that was not approved is followed by the revision, streamed
+	// as a message of its own.
+	OfReflectionCritique *ChunkCritique[constants.ChunkTypeReflectionCritique] `json:",omitempty"`
 }
 
 func (u *ResponseChunk) UnmarshalJSON(data []byte) error {
@@ -103,6 +138,24 @@ func (u *ResponseChunk) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 
+	var runGuardrailTripped *ChunkRun[constants.ChunkTypeRunGuardrailTripped]
+	if err := sonic.Unmarshal(data, &runGuardrailTripped); err == nil {
+		u.OfRunGuardrailTripped = runGuardrailTripped
+		return nil
+	}
+
+	var stateUpdated *ChunkState[constants.ChunkTypeStateUpdated]
+	if err := sonic.Unmarshal(data, &stateUpdated); err == nil {
+		u.OfStateUpdated = stateUpdated
+		return nil
+	}
+
+	var critique *ChunkCritique[constants.ChunkTypeReflectionCritique]
+	if err := sonic.Unmarshal(data, &critique); err == nil {
+		u.OfReflectionCritique = critique
+		return nil
+	}
+
 	var responseCreated *ChunkResponse[constants.ChunkTypeResponseCreated]
 	if err := sonic.Unmarshal(data, &responseCreated); err == nil {
 		u.OfResponseCreated = responseCreated
@@ -127,6 +180,18 @@ func (u *ResponseChunk) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 
+	var responseFailed *ChunkResponse[constants.ChunkTypeResponseFailed]
+	if err := sonic.Unmarshal(data, &responseFailed); err == nil {
+		u.OfResponseFailed = responseFailed
+		return nil
+	}
+
+	var chunkError *ChunkError[constants.ChunkTypeError]
+	if err := sonic.Unmarshal(data, &chunkError); err == nil {
+		u.OfError = chunkError
+		return nil
+	}
+
 	var outputItemAdded *ChunkOutputItem[constants.ChunkTypeOutputItemAdded]
 	if err := sonic.Unmarshal(data, &outputItemAdded); err == nil {
 		u.OfOutputItemAdded = outputItemAdded
@@ -283,6 +348,72 @@ func (u *ResponseChunk) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 
+	var fileSearchCallInProgress *ChunkFileSearchCall[constants.ChunkTypeFileSearchCallInProgress]
+	if err := sonic.Unmarshal(data, &fileSearchCallInProgress); err == nil {
+		u.OfFileSearchCallInProgress = fileSearchCallInProgress
+		return nil
+	}
+
+	var fileSearchCallSearching *ChunkFileSearchCall[constants.ChunkTypeFileSearchCallSearching]
+	if err := sonic.Unmarshal(data, &fileSearchCallSearching); err == nil {
+		u.OfFileSearchCallSearching = fileSearchCallSearching
+		return nil
+	}
+
+	var fileSearchCallCompleted *ChunkFileSearchCall[constants.ChunkTypeFileSearchCallCompleted]
+	if err := sonic.Unmarshal(data, &fileSearchCallCompleted); err == nil {
+		u.OfFileSearchCallCompleted = fileSearchCallCompleted
+		return nil
+	}
+
+	var mcpCallInProgress *ChunkMcpCall[constants.ChunkTypeMcpCallInProgress]
+	if err := sonic.Unmarshal(data, &mcpCallInProgress); err == nil {
+		u.OfMcpCallInProgress = mcpCallInProgress
+		return nil
+	}
+
+	var mcpCallArgumentsDelta *ChunkMcpCall[constants.ChunkTypeMcpCallArgumentsDelta]
+	if err := sonic.Unmarshal(data, &mcpCallArgumentsDelta); err == nil {
+		u.OfMcpCallArgumentsDelta = mcpCallArgumentsDelta
+		return nil
+	}
+
+	var mcpCallArgumentsDone *ChunkMcpCall[constants.ChunkTypeMcpCallArgumentsDone]
+	if err := sonic.Unmarshal(data, &mcpCallArgumentsDone); err == nil {
+		u.OfMcpCallArgumentsDone = mcpCallArgumentsDone
+		return nil
+	}
+
+	var mcpCallCompleted *ChunkMcpCall[constants.ChunkTypeMcpCallCompleted]
+	if err := sonic.Unmarshal(data, &mcpCallCompleted); err == nil {
+		u.OfMcpCallCompleted = mcpCallCompleted
+		return nil
+	}
+
+	var mcpCallFailed *ChunkMcpCall[constants.ChunkTypeMcpCallFailed]
+	if err := sonic.Unmarshal(data, &mcpCallFailed); err == nil {
+		u.OfMcpCallFailed = mcpCallFailed
+		return nil
+	}
+
+	var mcpListToolsInProgress *ChunkMcpListTools[constants.ChunkTypeMcpListToolsInProgress]
+	if err := sonic.Unmarshal(data, &mcpListToolsInProgress); err == nil {
+		u.OfMcpListToolsInProgress = mcpListToolsInProgress
+		return nil
+	}
+
+	var mcpListToolsCompleted *ChunkMcpListTools[constants.ChunkTypeMcpListToolsCompleted]
+	if err := sonic.Unmarshal(data, &mcpListToolsCompleted); err == nil {
+		u.OfMcpListToolsCompleted = mcpListToolsCompleted
+		return nil
+	}
+
+	var mcpListToolsFailed *ChunkMcpListTools[constants.ChunkTypeMcpListToolsFailed]
+	if err := sonic.Unmarshal(data, &mcpListToolsFailed); err == nil {
+		u.OfMcpListToolsFailed = mcpListToolsFailed
+		return nil
+	}
+
 	return errors.New("invalid response chunk union")
 }
 
@@ -299,6 +430,14 @@ func (u *ResponseChunk) MarshalJSON() ([]byte, error) {
 		return sonic.Marshal(u.OfResponseCompleted)
 	}
 
+	if u.OfResponseFailed != nil {
+		return sonic.Marshal(u.OfResponseFailed)
+	}
+
+	if u.OfError != nil {
+		return sonic.Marshal(u.OfError)
+	}
+
 	if u.OfOutputItemAdded != nil {
 		return sonic.Marshal(u.OfOutputItemAdded)
 	}
@@ -400,6 +539,18 @@ func (u *ResponseChunk) MarshalJSON() ([]byte, error) {
 		return sonic.Marshal(u.OfRunCompleted)
 	}
 
+	if u.OfRunGuardrailTripped != nil {
+		return sonic.Marshal(u.OfRunGuardrailTripped)
+	}
+
+	if u.OfStateUpdated != nil {
+		return sonic.Marshal(u.OfStateUpdated)
+	}
+
+	if u.OfReflectionCritique != nil {
+		return sonic.Marshal(u.OfReflectionCritique)
+	}
+
 	if u.OfFunctionCallOutput != nil {
 		return sonic.Marshal(u.OfFunctionCallOutput)
 	}
@@ -428,6 +579,50 @@ func (u *ResponseChunk) MarshalJSON() ([]byte, error) {
 		return sonic.Marshal(u.OfCodeInterpreterCallCompleted)
 	}
 
+	if u.OfFileSearchCallInProgress != nil {
+		return sonic.Marshal(u.OfFileSearchCallInProgress)
+	}
+
+	if u.OfFileSearchCallSearching != nil {
+		return sonic.Marshal(u.OfFileSearchCallSearching)
+	}
+
+	if u.OfFileSearchCallCompleted != nil {
+		return sonic.Marshal(u.OfFileSearchCallCompleted)
+	}
+
+	if u.OfMcpCallInProgress != nil {
+		return sonic.Marshal(u.OfMcpCallInProgress)
+	}
+
+	if u.OfMcpCallArgumentsDelta != nil {
+		return sonic.Marshal(u.OfMcpCallArgumentsDelta)
+	}
+
+	if u.OfMcpCallArgumentsDone != nil {
+		return sonic.Marshal(u.OfMcpCallArgumentsDone)
+	}
+
+	if u.OfMcpCallCompleted != nil {
+		return sonic.Marshal(u.OfMcpCallCompleted)
+	}
+
+	if u.OfMcpCallFailed != nil {
+		return sonic.Marshal(u.OfMcpCallFailed)
+	}
+
+	if u.OfMcpListToolsInProgress != nil {
+		return sonic.Marshal(u.OfMcpListToolsInProgress)
+	}
+
+	if u.OfMcpListToolsCompleted != nil {
+		return sonic.Marshal(u.OfMcpListToolsCompleted)
+	}
+
+	if u.OfMcpListToolsFailed != nil {
+		return sonic.Marshal(u.OfMcpListToolsFailed)
+	}
+
 	return nil, nil
 }
 
@@ -444,6 +639,14 @@ func (u *ResponseChunk) ChunkType() string {
 		return u.OfResponseCompleted.Type.Value()
 	}
 
+	if u.OfResponseFailed != nil {
+		return u.OfResponseFailed.Type.Value()
+	}
+
+	if u.OfError != nil {
+		return u.OfError.Type.Value()
+	}
+
 	if u.OfOutputItemAdded != nil {
 		return u.OfOutputItemAdded.Type.Value()
 	}
@@ -548,6 +751,50 @@ func (u *ResponseChunk) ChunkType() string {
 		return u.OfCodeInterpreterCallCompleted.Type.Value()
 	}
 
+	if u.OfFileSearchCallInProgress != nil {
+		return u.OfFileSearchCallInProgress.Type.Value()
+	}
+
+	if u.OfFileSearchCallSearching != nil {
+		return u.OfFileSearchCallSearching.Type.Value()
+	}
+
+	if u.OfFileSearchCallCompleted != nil {
+		return u.OfFileSearchCallCompleted.Type.Value()
+	}
+
+	if u.OfMcpCallInProgress != nil {
+		return u.OfMcpCallInProgress.Type.Value()
+	}
+
+	if u.OfMcpCallArgumentsDelta != nil {
+		return u.OfMcpCallArgumentsDelta.Type.Value()
+	}
+
+	if u.OfMcpCallArgumentsDone != nil {
+		return u.OfMcpCallArgumentsDone.Type.Value()
+	}
+
+	if u.OfMcpCallCompleted != nil {
+		return u.OfMcpCallCompleted.Type.Value()
+	}
+
+	if u.OfMcpCallFailed != nil {
+		return u.OfMcpCallFailed.Type.Value()
+	}
+
+	if u.OfMcpListToolsInProgress != nil {
+		return u.OfMcpListToolsInProgress.Type.Value()
+	}
+
+	if u.OfMcpListToolsCompleted != nil {
+		return u.OfMcpListToolsCompleted.Type.Value()
+	}
+
+	if u.OfMcpListToolsFailed != nil {
+		return u.OfMcpListToolsFailed.Type.Value()
+	}
+
 	// Custom Chunks
 	if u.OfRunCreated != nil {
 		return u.OfRunCreated.Type.Value()
@@ -565,6 +812,18 @@ func (u *ResponseChunk) ChunkType() string {
 		return u.OfRunCompleted.Type.Value()
 	}
 
+	if u.OfRunGuardrailTripped != nil {
+		return u.OfRunGuardrailTripped.Type.Value()
+	}
+
+	if u.OfStateUpdated != nil {
+		return u.OfStateUpdated.Type.Value()
+	}
+
+	if u.OfReflectionCritique != nil {
+		return u.OfReflectionCritique.Type.Value()
+	}
+
 	if u.OfFunctionCallOutput != nil {
 		return u.OfFunctionCallOutput.Type.Value()
 	}
@@ -599,6 +858,25 @@ type ChunkToolProgress[T any] struct {
 	Message        string  `json:"message,omitempty"`
 }
 
+// ChunkState is the state a run shares with its client, whole. A client keeps
+// the last one it was sent.
+type ChunkState[T any] struct {
+	Type           T              `json:"type"`
+	SequenceNumber int            `json:"sequence_number"`
+	State          map[string]any `json:"state"`
+}
+
+// ChunkCritique is a critic's verdict on draft Revision of the agent's answer,
+// the first draft being 0.
+type ChunkCritique[T any] struct {
+	Type           T      `json:"type"`
+	SequenceNumber int    `json:"sequence_number"`
+	Critic         string `json:"critic"`
+	Revision       int    `json:"revision"`
+	Approved       bool   `json:"approved"`
+	Feedback       string `json:"feedback,omitempty"`
+}
+
 type ChunkRunData struct {
 	Id                string      `json:"id"`
 	Object            string      `json:"object"` // "run"
@@ -606,6 +884,19 @@ type ChunkRunData struct {
 	PendingInterrupts []Interrupt `json:"pending_interrupts,omitempty"`
 	Usage             Usage       `json:"usage"`
 	TraceID           string      `json:"traceid"`
+	// Guardrail is set on a run.guardrail_tripped chunk.
+	Guardrail *GuardrailTrip `json:"guardrail,omitempty"`
+}
+
+// GuardrailTrip describes the guardrail that blocked a run.
+type GuardrailTrip struct {
+	// Name is the tripped guardrail's name.
+	Name string `json:"name"`
+	// Stage is "input" for a guardrail on the run's input, "output" for one
+	// on its final answer.
+	Stage string `json:"stage"`
+	// Reason is the guardrail's explanation, if it gave one.
+	Reason string `json:"reason,omitempty"`
 }
 
 // InterruptMode discriminates the kind of tool-call intercept a run is
@@ -660,6 +951,15 @@ type ChunkResponseData struct {
 	Request
 }
 
+// ChunkError is a stream-level error event.
+type ChunkError[T any] struct {
+	Type           T      `json:"type"`
+	SequenceNumber int    `json:"sequence_number"`
+	Code           string `json:"code,omitempty"`
+	Message        string `json:"message"`
+	Param          string `json:"param,omitempty"`
+}
+
 type ChunkOutputItem[T any] struct {
 	Type           T                   `json:"type"`
 	SequenceNumber int                 `json:"sequence_number"`
@@ -668,7 +968,7 @@ type ChunkOutputItem[T any] struct {
 }
 
 type ChunkOutputItemData struct {
-	Type string `json:"type"` // "function_call", "message", "reasoning", "image_generation_call", "web_search_call", "code_interpreter_call"
+	Type string `json:"type"` // "function_call", "message", "reasoning", or one of the hosted tool call types ("web_search_call", "mcp_call", ...)
 
 	// Common fields
 	Id     string `json:"id"`
@@ -702,6 +1002,31 @@ type ChunkOutputItemData struct {
 	Code        *string                          `json:"code,omitempty"`
 	ContainerID *string                          `json:"container_id,omitempty"`
 	Outputs     []CodeInterpreterCallOutputParam `json:"outputs,omitempty"`
+
+	// For "file_search_call"
+	Queries []string               `json:"queries,omitempty"`
+	Results []FileSearchCallResult `json:"results,omitempty"`
+
+	// For "mcp_call", "mcp_list_tools" and "mcp_approval_request" (with Name and Arguments)
+	ServerLabel *string            `json:"server_label,omitempty"`
+	Tools       []McpListToolsTool `json:"tools,omitempty"`
+	Output      *string            `json:"output,omitempty"`
+	Error       *string            `json:"error,omitempty"`
+
+	// For "web_fetch_call"
+	URL         *string `json:"url,omitempty"`
+	Title       *string `json:"title,omitempty"`
+	MediaType   *string `json:"media_type,omitempty"`
+	Data        *string `json:"data,omitempty"`
+	RetrievedAt *string `json:"retrieved_at,omitempty"`
+	ErrorCode   *string `json:"error_code,omitempty"`
+
+	// For "url_context_call"
+	URLs []URLContextCallURL `json:"urls,omitempty"`
+
+	// For "google_maps_call"
+	Places             []GoogleMapsCallPlace `json:"places,omitempty"`
+	WidgetContextToken *string               `json:"widget_context_token,omitempty"`
 }
 
 type ChunkOutputItemContent []ChunkOutputItemContentUnion
@@ -749,14 +1074,14 @@ type ChunkContentPart[T any] struct {
 }
 
 type ChunkOutputText[T any] struct {
-	Type           T             `json:"type"`
-	SequenceNumber int           `json:"sequence_number"`
-	ItemId         string        `json:"item_id"`
-	OutputIndex    int           `json:"output_index"`
-	ContentIndex   int           `json:"content_index"`
-	Delta          string        `json:"delta"`
-	Logprobs       []interface{} `json:"logprobs"`
-	Obfuscation    string        `json:"obfuscation"`
+	Type           T         `json:"type"`
+	SequenceNumber int       `json:"sequence_number"`
+	ItemId         string    `json:"item_id"`
+	OutputIndex    int       `json:"output_index"`
+	ContentIndex   int       `json:"content_index"`
+	Delta          string    `json:"delta"`
+	Logprobs       []Logprob `json:"logprobs"`
+	Obfuscation    string    `json:"obfuscation"`
 
 	// Only on content.output_text.done (contains the accumulated content)
 	Text *string `json:"text,omitempty"`
@@ -864,6 +1189,36 @@ type ChunkCodeInterpreterCall[T any] struct {
 	ThoughtSignature *string `json:"thought_signature,omitempty"` // Only for Gemini
 }
 
+type ChunkFileSearchCall[T any] struct {
+	Type T `json:"type"`
+
+	SequenceNumber int    `json:"sequence_number"`
+	ItemId         string `json:"item_id"`
+	OutputIndex    int    `json:"output_index"`
+}
+
+type ChunkMcpCall[T any] struct {
+	Type T `json:"type"`
+
+	SequenceNumber int    `json:"sequence_number"`
+	ItemId         string `json:"item_id"`
+	OutputIndex    int    `json:"output_index"`
+
+	// Only on response.mcp_call_arguments.delta
+	Delta *string `json:"delta,omitempty"`
+
+	// Only on response.mcp_call_arguments.done
+	Arguments *string `json:"arguments,omitempty"`
+}
+
+type ChunkMcpListTools[T any] struct {
+	Type T `json:"type"`
+
+	SequenceNumber int    `json:"sequence_number"`
+	ItemId         string `json:"item_id"`
+	OutputIndex    int    `json:"output_index"`
+}
+
 type ChunkResponseUsage struct {
 	InputTokens        int `json:"input_tokens"`
 	InputTokensDetails struct {
@@ -0,0 +1,56 @@
+package transcription
+
+import (
+	"fmt"
+
+	"github.com/bytedance/sonic"
+)
+
+type StringConstant interface {
+	Value() string
+}
+
+func unmarshalConstantString(c StringConstant, buf []byte) error {
+	var s string
+	if err := sonic.Unmarshal(buf, &s); err != nil {
+		return err
+	}
+
+	if s != c.Value() {
+		return fmt.Errorf("invalid %T: got %q, want %q", c, s, c.Value())
+	}
+
+	return nil
+}
+
+type ChunkTypePartial string
+
+func (m *ChunkTypePartial) Value() string                { return "transcription.partial" }
+func (m *ChunkTypePartial) MarshalJSON() ([]byte, error) { return sonic.Marshal(m.Value()) }
+func (m *ChunkTypePartial) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type ChunkTypeFinal string
+
+func (m *ChunkTypeFinal) Value() string                { return "transcription.final" }
+func (m *ChunkTypeFinal) MarshalJSON() ([]byte, error) { return sonic.Marshal(m.Value()) }
+func (m *ChunkTypeFinal) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type ChunkTypeDone string
+
+func (m *ChunkTypeDone) Value() string                { return "transcription.done" }
+func (m *ChunkTypeDone) MarshalJSON() ([]byte, error) { return sonic.Marshal(m.Value()) }
+func (m *ChunkTypeDone) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
+type ChunkTypeError string
+
+func (m *ChunkTypeError) Value() string                { return "transcription.error" }
+func (m *ChunkTypeError) MarshalJSON() ([]byte, error) { return sonic.Marshal(m.Value()) }
+func (m *ChunkTypeError) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
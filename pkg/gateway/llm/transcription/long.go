@@ -0,0 +1,382 @@
+package transcription
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Transcriber is the part of llm.Provider that TranscribeLong needs.
+type Transcriber interface {
+	NewTranscription(ctx context.Context, in *Request) (*Response, error)
+}
+
+type LongAudioOptions struct {
+	// MaxChunkDuration caps the length of each chunk. Defaults to 10 minutes.
+	MaxChunkDuration time.Duration
+	// MaxChunkBytes caps the size of each chunk, to stay under the provider's
+	// upload limit. Defaults to 24MB, just under OpenAI's 25MB.
+	MaxChunkBytes int
+	// SilenceSearch is how far back from a chunk's cap to look for a pause
+	// to cut at. Defaults to 30 seconds.
+	SilenceSearch time.Duration
+	// SilenceThreshold is the RMS amplitude, out of 32767, below which audio
+	// counts as silence. Defaults to 500.
+	SilenceThreshold float64
+	// Concurrency bounds the chunks transcribed at once. Defaults to 4.
+	Concurrency int
+}
+
+const (
+	defaultMaxChunkDuration = 10 * time.Minute
+	defaultMaxChunkBytes    = 24 << 20
+	defaultSilenceSearch    = 30 * time.Second
+	defaultSilenceThreshold = 500
+	defaultConcurrency      = 4
+
+	// analysisBlock is the window silence is measured over.
+	analysisBlock = 20 * time.Millisecond
+)
+
+// TranscribeLong transcribes audio too long for a single upload. WAV or raw
+// PCM (16-bit; raw PCM is mono at SampleRate, and its AudioFilename must end
+// in .pcm or .raw) is split into chunks at pauses
+// near MaxChunkDuration, the chunks are transcribed concurrently, and the
+// results are stitched back into one Response whose segment and word times
+// run from the start of the whole recording. Audio that fits in one chunk is
+// sent as is, in any format.
+//
+// Speaker labels come from the provider, per chunk: the same person may be
+// labeled differently on either side of a cut.
+func TranscribeLong(ctx context.Context, t Transcriber, in *Request, opts *LongAudioOptions) (*Response, error) {
+	o := LongAudioOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.MaxChunkDuration <= 0 {
+		o.MaxChunkDuration = defaultMaxChunkDuration
+	}
+	if o.MaxChunkBytes <= 0 {
+		o.MaxChunkBytes = defaultMaxChunkBytes
+	}
+	if o.SilenceSearch <= 0 {
+		o.SilenceSearch = defaultSilenceSearch
+	}
+	if o.SilenceThreshold <= 0 {
+		o.SilenceThreshold = defaultSilenceThreshold
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = defaultConcurrency
+	}
+
+	format, pcm, err := decodeAudio(in)
+	if err != nil {
+		if len(in.Audio) <= o.MaxChunkBytes {
+			return transcribeChunks(ctx, t, in, []audioChunk{{audio: in.Audio, filename: in.AudioFilename}}, o.Concurrency)
+		}
+		return nil, err
+	}
+
+	chunks := splitOnSilence(format, pcm, &o)
+	if len(chunks) == 1 {
+		chunks[0].audio, chunks[0].filename = in.Audio, in.AudioFilename
+	}
+	return transcribeChunks(ctx, t, in, chunks, o.Concurrency)
+}
+
+type audioChunk struct {
+	audio    []byte
+	filename string
+	// offset and duration are in seconds; duration is zero when unknown.
+	offset   float64
+	duration float64
+}
+
+func transcribeChunks(ctx context.Context, t Transcriber, in *Request, chunks []audioChunk, concurrency int) (*Response, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]*Response, len(chunks))
+	errs := make([]error, len(chunks))
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+
+			req := *in
+			req.Audio = chunk.audio
+			req.AudioFilename = chunk.filename
+			req.AudioStream, req.AudioFrames = nil, nil
+
+			results[i], errs[i] = t.NewTranscription(ctx, &req)
+			if errs[i] == nil && results[i] == nil {
+				errs[i] = errors.New("provider returned no transcription")
+			}
+			if errs[i] != nil {
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Report the chunk that failed, not the ones cancelled because of it.
+	for i, err := range errs {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return nil, fmt.Errorf("transcribing chunk %d of %d: %w", i+1, len(chunks), err)
+		}
+	}
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("transcribing chunk %d of %d: %w", i+1, len(chunks), err)
+		}
+	}
+
+	return stitch(chunks, results), nil
+}
+
+// stitch joins per-chunk results, moving each chunk's times by its offset.
+func stitch(chunks []audioChunk, results []*Response) *Response {
+	out := &Response{}
+	var texts []string
+	var total float64
+
+	for i, r := range results {
+		chunk := chunks[i]
+
+		if text := strings.TrimSpace(r.Text); text != "" {
+			texts = append(texts, text)
+		}
+		if out.Language == nil && r.Language != nil && *r.Language != "" {
+			out.Language = r.Language
+		}
+
+		duration := chunk.duration
+		if duration == 0 && r.Duration != nil {
+			duration = *r.Duration
+		}
+		total = math.Max(total, chunk.offset+duration)
+
+		for _, w := range r.Words {
+			w.Start += chunk.offset
+			w.End += chunk.offset
+			out.Words = append(out.Words, w)
+		}
+
+		segments := r.Segments
+		switch {
+		case len(segments) > 0:
+		case len(r.Words) > 0:
+			segments = SegmentsFromWords(r.Words)
+		case strings.TrimSpace(r.Text) != "":
+			segments = []Segment{{Text: strings.TrimSpace(r.Text), End: duration}}
+		}
+		for _, s := range segments {
+			s.ID = len(out.Segments)
+			s.Start += chunk.offset
+			s.End += chunk.offset
+			out.Segments = append(out.Segments, s)
+		}
+
+		if r.Usage != nil {
+			if out.Usage == nil {
+				out.Usage = &Usage{}
+			}
+			out.Usage.PromptTokens += r.Usage.PromptTokens
+			out.Usage.CompletionTokens += r.Usage.CompletionTokens
+			out.Usage.TotalTokens += r.Usage.TotalTokens
+		}
+	}
+
+	out.Text = strings.Join(texts, " ")
+	if total > 0 {
+		out.Duration = &total
+	}
+
+	return out
+}
+
+// pcmFormat describes 16-bit PCM audio.
+type pcmFormat struct {
+	sampleRate int
+	channels   int
+}
+
+func (f pcmFormat) bytesPerSecond() int { return f.sampleRate * f.channels * 2 }
+func (f pcmFormat) frameBytes() int     { return f.channels * 2 }
+
+// containerMagic identifies the compressed formats a recording commonly
+// comes in by their first bytes, so they are not mistaken for raw PCM.
+var containerMagic = []struct {
+	name  string
+	magic []byte
+}{
+	{"MP3", []byte("ID3")},
+	{"MP3", []byte{0xFF, 0xFB}},
+	{"MP3", []byte{0xFF, 0xF3}},
+	{"MP3", []byte{0xFF, 0xF2}},
+	{"AAC", []byte{0xFF, 0xF1}},
+	{"AAC", []byte{0xFF, 0xF9}},
+	{"Ogg", []byte("OggS")},
+	{"WebM", []byte{0x1A, 0x45, 0xDF, 0xA3}},
+	{"FLAC", []byte("fLaC")},
+}
+
+// decodeAudio returns the request's audio as 16-bit PCM: the data of a WAV
+// file, or Audio itself when the filename says it is raw PCM. Raw PCM has no
+// header to recognize it by, so anything else is refused rather than cut as
+// if it were samples.
+func decodeAudio(in *Request) (pcmFormat, []byte, error) {
+	if bytes.HasPrefix(in.Audio, []byte("RIFF")) {
+		return parseWAV(in.Audio)
+	}
+	for _, c := range containerMagic {
+		if bytes.HasPrefix(in.Audio, c.magic) {
+			return pcmFormat{}, nil, fmt.Errorf("cannot split %s audio: only WAV and raw PCM can be chunked", c.name)
+		}
+	}
+	// MP4 and M4A start with the size of their ftyp box.
+	if len(in.Audio) >= 8 && string(in.Audio[4:8]) == "ftyp" {
+		return pcmFormat{}, nil, errors.New("cannot split MP4 audio: only WAV and raw PCM can be chunked")
+	}
+
+	switch strings.ToLower(filepath.Ext(in.AudioFilename)) {
+	case ".pcm", ".raw":
+		return pcmFormat{sampleRate: in.StreamSampleRate(), channels: 1}, in.Audio, nil
+	}
+	return pcmFormat{}, nil, fmt.Errorf("cannot split audio %q: it is not WAV, and raw PCM must be named .pcm or .raw", in.AudioFilename)
+}
+
+func parseWAV(b []byte) (pcmFormat, []byte, error) {
+	if len(b) < 12 || string(b[8:12]) != "WAVE" {
+		return pcmFormat{}, nil, errors.New("not a WAV file")
+	}
+
+	var format pcmFormat
+	var haveFormat bool
+	for pos := 12; pos+8 <= len(b); {
+		id := string(b[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(b[pos+4 : pos+8]))
+		body := b[pos+8 : min(pos+8+size, len(b))]
+
+		switch id {
+		case "fmt ":
+			if len(body) < 16 {
+				return pcmFormat{}, nil, errors.New("malformed WAV fmt chunk")
+			}
+			audioFormat := binary.LittleEndian.Uint16(body[0:2])
+			bits := binary.LittleEndian.Uint16(body[14:16])
+			// 0xFFFE is WAVE_FORMAT_EXTENSIBLE, which carries plain PCM too.
+			if (audioFormat != 1 && audioFormat != 0xFFFE) || bits != 16 {
+				return pcmFormat{}, nil, fmt.Errorf("cannot split WAV audio with format %d and %d-bit samples: only 16-bit PCM can be chunked", audioFormat, bits)
+			}
+			format.channels = int(binary.LittleEndian.Uint16(body[2:4]))
+			format.sampleRate = int(binary.LittleEndian.Uint32(body[4:8]))
+			haveFormat = format.channels > 0 && format.sampleRate > 0
+		case "data":
+			if !haveFormat {
+				return pcmFormat{}, nil, errors.New("WAV data before its format")
+			}
+			return format, body, nil
+		}
+
+		// Chunks are padded to an even length.
+		pos += 8 + size + size%2
+	}
+
+	return pcmFormat{}, nil, errors.New("WAV file has no data")
+}
+
+// splitOnSilence cuts pcm into chunks no longer than the options allow,
+// cutting each one in the longest pause within SilenceSearch of its cap, or
+// at its quietest moment if there is no pause.
+func splitOnSilence(format pcmFormat, pcm []byte, o *LongAudioOptions) []audioChunk {
+	bps := format.bytesPerSecond()
+	frame := format.frameBytes()
+	block := max(int(analysisBlock.Seconds()*float64(format.sampleRate)), 1) * frame
+
+	maxBytes := min(int(o.MaxChunkDuration.Seconds()*float64(bps)), o.MaxChunkBytes-44)
+	maxBytes = max(maxBytes-maxBytes%block, block)
+	search := min(int(o.SilenceSearch.Seconds()*float64(bps)), maxBytes/2)
+	search -= search % block
+
+	var chunks []audioChunk
+	for pos := 0; pos < len(pcm); {
+		end := len(pcm)
+		if end-pos > maxBytes {
+			end = quietestCut(pcm, pos+maxBytes-search, pos+maxBytes, block, o.SilenceThreshold)
+		}
+
+		chunks = append(chunks, audioChunk{
+			audio:    encodeWAV(pcm[pos:end], format.sampleRate, format.channels),
+			filename: fmt.Sprintf("chunk_%d.wav", len(chunks)+1),
+			offset:   float64(pos) / float64(bps),
+			duration: float64(end-pos) / float64(bps),
+		})
+		pos = end
+	}
+
+	return chunks
+}
+
+// quietestCut picks a block boundary in [from, to): the middle of the
+// longest run of silent blocks, or else the start of the quietest block.
+func quietestCut(pcm []byte, from, to, block int, threshold float64) int {
+	bestRunStart, bestRunLen := 0, 0
+	runStart, runLen := 0, 0
+	quietest, quietestRMS := to, math.MaxFloat64
+
+	for pos := from; pos+block <= to; pos += block {
+		level := rms(pcm[pos : pos+block])
+		if level < quietestRMS {
+			quietest, quietestRMS = pos, level
+		}
+
+		if level < threshold {
+			if runLen == 0 {
+				runStart = pos
+			}
+			runLen++
+			if runLen > bestRunLen {
+				bestRunStart, bestRunLen = runStart, runLen
+			}
+		} else {
+			runLen = 0
+		}
+	}
+
+	if bestRunLen > 0 {
+		return bestRunStart + bestRunLen/2*block
+	}
+	return quietest
+}
+
+func rms(pcm []byte) float64 {
+	n := len(pcm) / 2
+	if n == 0 {
+		return 0
+	}
+
+	var sum float64
+	for i := 0; i < n; i++ {
+		s := float64(int16(binary.LittleEndian.Uint16(pcm[2*i:])))
+		sum += s * s
+	}
+	return math.Sqrt(sum / float64(n))
+}
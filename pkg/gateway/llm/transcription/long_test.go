@@ -0,0 +1,198 @@
+package transcription_test
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
+)
+
+const rate = 1000 // a low sample rate keeps the fixtures small
+
+// tone returns seconds of loud 16-bit mono PCM; silence returns quiet PCM.
+func tone(seconds float64) []byte {
+	pcm := make([]byte, int(seconds*rate)*2)
+	for i := 0; i < len(pcm); i += 2 {
+		v := int16(8000)
+		if (i/2)%2 == 1 {
+			v = -8000
+		}
+		binary.LittleEndian.PutUint16(pcm[i:], uint16(v))
+	}
+	return pcm
+}
+
+func silence(seconds float64) []byte { return make([]byte, int(seconds*rate)*2) }
+
+func concat(parts ...[]byte) []byte {
+	var out []byte
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+// fakeTranscriber answers each chunk with one segment spanning it, and
+// records the chunks it was sent.
+type fakeTranscriber struct {
+	mu     sync.Mutex
+	chunks map[string]float64 // filename -> seconds of audio
+
+	inFlight, peak atomic.Int32
+	fail           string
+}
+
+func (f *fakeTranscriber) NewTranscription(ctx context.Context, in *transcription.Request) (*transcription.Response, error) {
+	n := f.inFlight.Add(1)
+	defer f.inFlight.Add(-1)
+	for {
+		p := f.peak.Load()
+		if n <= p || f.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	if in.AudioFilename == f.fail {
+		return nil, errors.New("upload too large")
+	}
+
+	seconds := float64(len(in.Audio)-44) / 2 / rate
+
+	f.mu.Lock()
+	f.chunks[in.AudioFilename] = seconds
+	f.mu.Unlock()
+
+	return &transcription.Response{
+		Text:     in.AudioFilename,
+		Segments: []transcription.Segment{{Text: in.AudioFilename, Start: 0.5, End: seconds, Speaker: "A"}},
+		Words:    []transcription.Word{{Word: in.AudioFilename, Start: 0.5, End: 1}},
+		Usage:    &transcription.Usage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12},
+	}, nil
+}
+
+type recordingTranscriber struct {
+	got  []*transcription.Request
+	resp *transcription.Response
+}
+
+func (r *recordingTranscriber) NewTranscription(ctx context.Context, in *transcription.Request) (*transcription.Response, error) {
+	r.got = append(r.got, in)
+	return r.resp, nil
+}
+
+func TestTranscribeLong_CutsAtPausesAndShiftsTimes(t *testing.T) {
+	// 8s of speech, a pause, 8s of speech, a pause, 4s of speech.
+	pcm := concat(tone(8), silence(1), tone(8), silence(1), tone(4))
+	f := &fakeTranscriber{chunks: map[string]float64{}}
+
+	resp, err := transcription.TranscribeLong(context.Background(), f, &transcription.Request{
+		Audio:         pcm,
+		AudioFilename: "meeting.pcm",
+		SampleRate:    func() *int { r := rate; return &r }(),
+	}, &transcription.LongAudioOptions{
+		MaxChunkDuration: 10 * time.Second,
+		SilenceSearch:    5 * time.Second,
+		Concurrency:      2,
+	})
+	require.NoError(t, err)
+
+	// The cuts fall in the middle of the pauses rather than at the 10s cap.
+	require.Len(t, f.chunks, 3)
+	assert.InDelta(t, 8.5, f.chunks["chunk_1.wav"], 0.05)
+	assert.InDelta(t, 9, f.chunks["chunk_2.wav"], 0.05)
+	assert.InDelta(t, 4.5, f.chunks["chunk_3.wav"], 0.05)
+
+	assert.LessOrEqual(t, f.peak.Load(), int32(2))
+
+	assert.Equal(t, "chunk_1.wav chunk_2.wav chunk_3.wav", resp.Text)
+	require.Len(t, resp.Segments, 3)
+	for i, s := range resp.Segments {
+		assert.Equal(t, i, s.ID)
+		assert.Equal(t, "A", s.Speaker)
+	}
+	assert.InDelta(t, 8.5+0.5, resp.Segments[1].Start, 0.05, "times run from the start of the recording")
+	assert.InDelta(t, 17.5+0.5, resp.Words[2].Start, 0.05)
+	assert.InDelta(t, 22, *resp.Duration, 0.05)
+	assert.Equal(t, &transcription.Usage{PromptTokens: 30, CompletionTokens: 6, TotalTokens: 36}, resp.Usage)
+}
+
+func TestTranscribeLong_ReportsTheFailingChunk(t *testing.T) {
+	f := &fakeTranscriber{chunks: map[string]float64{}, fail: "chunk_2.wav"}
+
+	wav := transcription.WAV(tone(30), rate)
+	_, err := transcription.TranscribeLong(context.Background(), f, &transcription.Request{
+		Audio:         wav,
+		AudioFilename: "meeting.wav",
+	}, &transcription.LongAudioOptions{MaxChunkDuration: 10 * time.Second})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "transcribing chunk 2 of")
+	assert.Contains(t, err.Error(), "upload too large")
+}
+
+func TestTranscribeLong_SendsShortAudioAsIs(t *testing.T) {
+	short := &recordingTranscriber{resp: &transcription.Response{
+		Text:  "hi",
+		Words: []transcription.Word{{Word: "hi", Start: 0.1, End: 0.3}},
+	}}
+
+	resp, err := transcription.TranscribeLong(context.Background(), short, &transcription.Request{
+		Audio:         []byte("ID3 mp3 bytes"),
+		AudioFilename: "note.mp3",
+	}, nil)
+	require.NoError(t, err)
+
+	require.Len(t, short.got, 1)
+	assert.Equal(t, "note.mp3", short.got[0].AudioFilename)
+	assert.Equal(t, []byte("ID3 mp3 bytes"), short.got[0].Audio)
+	assert.Equal(t, []transcription.Segment{{Text: "hi", Start: 0.1, End: 0.3}}, resp.Segments, "segments are derived from words")
+}
+
+// Audio too long for one upload is only cut when it is known to be samples:
+// a compressed file, or bytes of no known format, fail up front.
+func TestTranscribeLong_RefusesToSplitOtherFormats(t *testing.T) {
+	long := tone(30)
+	mp4 := append([]byte{0, 0, 0, 0x20}, []byte("ftypM4A ")...)
+
+	for name, in := range map[string]*transcription.Request{
+		"mp3 named .pcm": {Audio: concat([]byte("ID3"), long), AudioFilename: "note.pcm"},
+		"ogg":            {Audio: concat([]byte("OggS"), long), AudioFilename: "note.ogg"},
+		"webm":           {Audio: concat([]byte{0x1A, 0x45, 0xDF, 0xA3}, long)},
+		"flac":           {Audio: concat([]byte("fLaC"), long)},
+		"mpeg frame":     {Audio: concat([]byte{0xFF, 0xFB}, long)},
+		"m4a":            {Audio: concat(mp4, long), AudioFilename: "note.m4a"},
+		"unnamed":        {Audio: long},
+	} {
+		t.Run(name, func(t *testing.T) {
+			f := &fakeTranscriber{chunks: map[string]float64{}}
+			_, err := transcription.TranscribeLong(context.Background(), f, in, &transcription.LongAudioOptions{MaxChunkBytes: 1000})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "cannot split")
+			assert.Empty(t, f.chunks, "nothing was sent")
+		})
+	}
+}
+
+func TestSegmentsFromWords(t *testing.T) {
+	segments := transcription.SegmentsFromWords([]transcription.Word{
+		{Word: "hi", Start: 0, End: 0.2, Speaker: "speaker_0"},
+		{Word: "there", Start: 0.3, End: 0.5, Speaker: "speaker_0"},
+		{Word: "hello", Start: 0.7, End: 1.0, Speaker: "speaker_1"},
+		{Word: "again", Start: 3.0, End: 3.4, Speaker: "speaker_1"},
+	})
+
+	assert.Equal(t, []transcription.Segment{
+		{ID: 0, Text: "hi there", Start: 0, End: 0.5, Speaker: "speaker_0"},
+		{ID: 1, Text: "hello", Start: 0.7, End: 1.0, Speaker: "speaker_1"},
+		{ID: 2, Text: "again", Start: 3.0, End: 3.4, Speaker: "speaker_1"},
+	}, segments, "split on speaker change and on a long pause")
+}
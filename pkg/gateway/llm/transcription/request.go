@@ -1,5 +1,7 @@
 package transcription
 
+import "io"
+
 type Request struct {
 	// Audio is the raw audio data to transcribe
 	Audio []byte `json:"audio"`
@@ -17,4 +19,18 @@ type Request struct {
 	Temperature *float64 `json:"temperature,omitempty"`
 	// TimestampGranularities specifies the timestamp granularities (word, segment)
 	TimestampGranularities []string `json:"timestamp_granularities,omitempty"`
+	// Diarize asks for the speaker of each segment and word, where the
+	// provider can tell speakers apart.
+	Diarize *bool `json:"diarize,omitempty"`
+
+	// AudioStream is read until EOF in place of Audio by
+	// NewStreamingTranscription, so transcription can start before the
+	// recording ends.
+	AudioStream io.Reader `json:"-"`
+	// AudioFrames is the alternative to AudioStream for audio that already
+	// arrives in frames, e.g. from a microphone. Close it to end the input.
+	AudioFrames <-chan []byte `json:"-"`
+	// SampleRate is the rate of streamed audio, which is raw 16-bit mono
+	// little-endian PCM. Defaults to 16000.
+	SampleRate *int `json:"sample_rate,omitempty"`
 }
@@ -1,5 +1,7 @@
 package transcription
 
+import "strings"
+
 type Response struct {
 	Text     string         `json:"text"`
 	Language *string        `json:"language,omitempty"`
@@ -14,6 +16,9 @@ type Word struct {
 	Word  string  `json:"word"`
 	Start float64 `json:"start"`
 	End   float64 `json:"end"`
+	// Speaker is the provider's label for who said it, set when the
+	// transcription was diarized.
+	Speaker string `json:"speaker,omitempty"`
 }
 
 type Segment struct {
@@ -26,6 +31,9 @@ type Segment struct {
 	AvgLogprob       float64 `json:"avg_logprob"`
 	CompressionRatio float64 `json:"compression_ratio"`
 	NoSpeechProb     float64 `json:"no_speech_prob"`
+	// Speaker is the provider's label for who said it, set when the
+	// transcription was diarized.
+	Speaker string `json:"speaker,omitempty"`
 }
 
 type Usage struct {
@@ -33,3 +41,79 @@ type Usage struct {
 	CompletionTokens int `json:"completion_tokens"`
 	TotalTokens      int `json:"total_tokens"`
 }
+
+// ResponseChunk is one event of a streaming transcription. Partials revise
+// the segment being spoken, finals commit it, and a done chunk — or an error
+// chunk, if the stream fails part way — ends the stream.
+type ResponseChunk struct {
+	OfPartial *ChunkPartial[ChunkTypePartial] `json:",omitempty"`
+	OfFinal   *ChunkFinal[ChunkTypeFinal]     `json:",omitempty"`
+	OfDone    *ChunkDone[ChunkTypeDone]       `json:",omitempty"`
+	OfError   *ChunkError[ChunkTypeError]     `json:",omitempty"`
+}
+
+// ChunkPartial is the running hypothesis for the current segment. Each one
+// replaces the last; it is not a delta.
+type ChunkPartial[T any] struct {
+	Type T      `json:"type"`
+	Text string `json:"text"`
+}
+
+// ChunkFinal is a committed segment. Times are seconds from the start of the
+// audio.
+type ChunkFinal[T any] struct {
+	Type     T       `json:"type"`
+	Text     string  `json:"text"`
+	Start    float64 `json:"start"`
+	End      float64 `json:"end"`
+	Words    []Word  `json:"words,omitempty"`
+	Speaker  string  `json:"speaker,omitempty"`
+	Language *string `json:"language,omitempty"`
+}
+
+// ChunkDone carries the full transcript, the finals joined in order.
+type ChunkDone[T any] struct {
+	Type     T        `json:"type"`
+	Text     string   `json:"text"`
+	Language *string  `json:"language,omitempty"`
+	Duration *float64 `json:"duration,omitempty"`
+	Usage    *Usage   `json:"usage,omitempty"`
+}
+
+type ChunkError[T any] struct {
+	Type    T      `json:"type"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// segmentPause is the gap between words that starts a new segment even when
+// the speaker stays the same.
+const segmentPause = 1.0
+
+// SegmentsFromWords groups word timings into segments, for providers that
+// only time words: a segment ends where the speaker changes or after a
+// pause.
+func SegmentsFromWords(words []Word) []Segment {
+	var segments []Segment
+	var text []string
+
+	for i, w := range words {
+		if i > 0 {
+			prev := words[i-1]
+			if w.Speaker != prev.Speaker || w.Start-prev.End > segmentPause {
+				segments[len(segments)-1].Text = strings.Join(text, " ")
+				text = nil
+			}
+		}
+		if text == nil {
+			segments = append(segments, Segment{ID: len(segments), Start: w.Start, Speaker: w.Speaker})
+		}
+		text = append(text, w.Word)
+		segments[len(segments)-1].End = w.End
+	}
+	if len(segments) > 0 {
+		segments[len(segments)-1].Text = strings.Join(text, " ")
+	}
+
+	return segments
+}
@@ -0,0 +1,132 @@
+package transcription
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// DefaultSampleRate is the rate assumed for streamed audio when the request
+// does not set SampleRate.
+const DefaultSampleRate = 16000
+
+// StreamSampleRate returns the sample rate of the streaming input.
+func (r *Request) StreamSampleRate() int {
+	if r.SampleRate != nil && *r.SampleRate > 0 {
+		return *r.SampleRate
+	}
+	return DefaultSampleRate
+}
+
+// ReadAudio hands the streaming input to fn frame by frame: AudioFrames as
+// they arrive, otherwise AudioStream — or, if neither is set, Audio — in
+// frames of up to frameSize bytes. It returns nil once the input ends, or
+// the first error from the reader, fn or ctx.
+func (r *Request) ReadAudio(ctx context.Context, frameSize int, fn func(frame []byte) error) error {
+	if r.AudioFrames != nil {
+		for {
+			select {
+			case frame, ok := <-r.AudioFrames:
+				if !ok {
+					return nil
+				}
+				if len(frame) == 0 {
+					continue
+				}
+				if err := fn(frame); err != nil {
+					return err
+				}
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	src := r.AudioStream
+	if src == nil {
+		src = bytes.NewReader(r.Audio)
+	}
+
+	buf := make([]byte, frameSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := io.ReadFull(src, buf)
+		if n > 0 {
+			frame := make([]byte, n)
+			copy(frame, buf[:n])
+			if err := fn(frame); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// StreamWAV writes the streaming input to w as a WAV file, each frame as it
+// is read, for endpoints that take a file but can read it as it uploads. The
+// length is not known up front, so the header gives the largest one, which
+// decoders read as "until the end of the file". It returns the number of PCM
+// bytes written.
+func (r *Request) StreamWAV(ctx context.Context, w io.Writer) (int, error) {
+	if _, err := w.Write(wavHeader(math.MaxUint32-36, r.StreamSampleRate(), 1)); err != nil {
+		return 0, err
+	}
+
+	var n int
+	err := r.ReadAudio(ctx, 32*1024, func(frame []byte) error {
+		written, err := w.Write(frame)
+		n += written
+		return err
+	})
+	return n, err
+}
+
+// PCMDuration returns the length, in seconds, of n bytes of 16-bit mono PCM.
+func PCMDuration(n int, sampleRate int) float64 {
+	return float64(n) / 2 / float64(sampleRate)
+}
+
+// WAV wraps 16-bit mono PCM in a WAV header, for endpoints that want a file.
+func WAV(pcm []byte, sampleRate int) []byte {
+	return encodeWAV(pcm, sampleRate, 1)
+}
+
+func encodeWAV(pcm []byte, sampleRate, channels int) []byte {
+	var b bytes.Buffer
+	b.Grow(44 + len(pcm))
+	b.Write(wavHeader(uint32(len(pcm)), sampleRate, channels))
+	b.Write(pcm)
+	return b.Bytes()
+}
+
+// wavHeader is the 44-byte header of a 16-bit PCM WAV file whose data chunk
+// is dataLen bytes.
+func wavHeader(dataLen uint32, sampleRate, channels int) []byte {
+	var b bytes.Buffer
+	b.Grow(44)
+
+	b.WriteString("RIFF")
+	_ = binary.Write(&b, binary.LittleEndian, 36+dataLen)
+	b.WriteString("WAVEfmt ")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(16))                    // fmt chunk size
+	_ = binary.Write(&b, binary.LittleEndian, uint16(1))                     // PCM
+	_ = binary.Write(&b, binary.LittleEndian, uint16(channels))              // channels
+	_ = binary.Write(&b, binary.LittleEndian, uint32(sampleRate))            // sample rate
+	_ = binary.Write(&b, binary.LittleEndian, uint32(sampleRate*channels*2)) // byte rate
+	_ = binary.Write(&b, binary.LittleEndian, uint16(channels*2))            // block align
+	_ = binary.Write(&b, binary.LittleEndian, uint16(16))                    // bits per sample
+	b.WriteString("data")
+	_ = binary.Write(&b, binary.LittleEndian, dataLen)
+
+	return b.Bytes()
+}
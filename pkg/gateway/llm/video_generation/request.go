@@ -0,0 +1,45 @@
+package video_generation
+
+// ImageInput is a reference image for the video to start from.
+type ImageInput struct {
+	// Data is the raw image bytes
+	Data []byte `json:"data"`
+	// MimeType is the image's type (e.g., "image/png"); detected from Data when empty
+	MimeType string `json:"mime_type,omitempty"`
+}
+
+// Request starts a video generation job. Jobs take minutes, so the response
+// only identifies the job; poll it with a GetRequest and fetch the result
+// with a DownloadRequest.
+type Request struct {
+	// Prompt describes the video to generate
+	Prompt string `json:"prompt"`
+	// Model is the model to use for video generation
+	Model string `json:"model"`
+	// Image is an optional reference image used as the first frame
+	Image *ImageInput `json:"image,omitempty"`
+	// NegativePrompt describes what the video should not contain (Gemini only)
+	NegativePrompt *string `json:"negative_prompt,omitempty"`
+	// DurationSeconds is the length of the video (e.g., 4, 8, 12)
+	DurationSeconds *int `json:"duration_seconds,omitempty"`
+	// AspectRatio specifies the desired aspect ratio ("16:9" or "9:16")
+	AspectRatio *string `json:"aspect_ratio,omitempty"`
+	// Resolution specifies the output resolution ("720p" or "1080p")
+	Resolution *string `json:"resolution,omitempty"`
+}
+
+// GetRequest polls the job a Request started.
+type GetRequest struct {
+	// Model is the model the job was started with; it routes the request
+	Model string `json:"model"`
+	// ID is the Response.ID of the job
+	ID string `json:"id"`
+}
+
+// DownloadRequest fetches the video of a completed job.
+type DownloadRequest struct {
+	// Model is the model the job was started with; it routes the request
+	Model string `json:"model"`
+	// ID is the Response.ID of the job
+	ID string `json:"id"`
+}
@@ -0,0 +1,41 @@
+package video_generation
+
+type Status string
+
+const (
+	StatusQueued     Status = "queued"
+	StatusInProgress Status = "in_progress"
+	StatusCompleted  Status = "completed"
+	StatusFailed     Status = "failed"
+)
+
+// Response describes a video generation job: as started, or as last polled.
+type Response struct {
+	// ID identifies the job across GetRequest and DownloadRequest. It is
+	// opaque: an OpenAI video id, or a Gemini operation name.
+	ID     string `json:"id"`
+	Model  string `json:"model,omitempty"`
+	Status Status `json:"status"`
+	// Progress is the percentage complete, where the provider reports it
+	Progress *int `json:"progress,omitempty"`
+	// DurationSeconds is the length of the video, where the provider reports it
+	DurationSeconds *int           `json:"duration_seconds,omitempty"`
+	Error           *Error         `json:"error,omitempty"`
+	RawFields       map[string]any `json:"raw_fields,omitempty"`
+}
+
+// Done reports whether the job has finished, successfully or not.
+func (r *Response) Done() bool {
+	return r.Status == StatusCompleted || r.Status == StatusFailed
+}
+
+type Error struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// Content is a generated video.
+type Content struct {
+	Data     []byte `json:"data"`
+	MimeType string `json:"mime_type"`
+}
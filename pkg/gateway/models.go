@@ -0,0 +1,262 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/models"
+)
+
+// DefaultModelCacheTTL is how long a provider's model or voice listing is
+// reused when LLMGateway.ModelCacheTTL is unset.
+const DefaultModelCacheTTL = 10 * time.Minute
+
+// voicelessProviders are served by a client whose voices belong to another
+// provider: Ollama and OpenRouter speak OpenAI's API but not its voices.
+var voicelessProviders = []llm.ProviderName{llm.ProviderNameOllama, llm.ProviderNameOpenRouter}
+
+// modelCatalog caches listings per provider and API key. Its zero value is
+// ready to use.
+type modelCatalog struct {
+	mu      sync.Mutex
+	entries map[catalogKey]catalogEntry
+	now     func() time.Time
+}
+
+type catalogKey struct {
+	kind     string
+	provider llm.ProviderName
+	apiKey   string
+}
+
+type catalogEntry struct {
+	listing any
+	expires time.Time
+}
+
+func (c *modelCatalog) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+func (c *modelCatalog) get(key catalogKey) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || !c.clock().Before(entry.expires) {
+		return nil, false
+	}
+	return entry.listing, true
+}
+
+func (c *modelCatalog) put(key catalogKey, listing any, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[catalogKey]catalogEntry)
+	}
+	c.entries[key] = catalogEntry{listing: listing, expires: c.clock().Add(ttl)}
+}
+
+// ListModels lists the models of every configured provider that can list
+// them. virtualKey is the key provider configs are looked up with; when it
+// is a virtual key, only its AllowedProviders and AllowedModels are listed.
+//
+// Each provider's listing is cached for ModelCacheTTL. A provider whose
+// listing fails is logged and left out, and asked again on the next call.
+func (g *LLMGateway) ListModels(ctx context.Context, virtualKey string) ([]models.Model, error) {
+	vk, err := g.resolveListingKey(ctx, virtualKey)
+	if err != nil {
+		return nil, err
+	}
+
+	listings := listPerProvider(ctx, g, "models", virtualKey, vk, func(ctx context.Context, p llm.Provider) ([]models.Model, bool, error) {
+		mp, ok := p.(models.Provider)
+		if !ok {
+			return nil, false, nil
+		}
+		list, err := mp.ListModels(ctx)
+		return list, true, err
+	})
+
+	var out []models.Model
+	for i, providerName := range llm.GetAllProviderNames() {
+		for _, m := range listings[i] {
+			if vk != nil && len(vk.AllowedModels) > 0 && !modelAllowed(vk.AllowedModels, providerName, m.ID) {
+				continue
+			}
+			m.Provider = string(providerName)
+			out = append(out, m)
+		}
+	}
+
+	return out, nil
+}
+
+// ListVoices lists the speech voices of every configured provider that can
+// list them, restricted like ListModels to a virtual key's AllowedProviders
+// and AllowedModels: a voice is listed if it can be used with a speech model
+// the key allows, and its Models are narrowed to those.
+func (g *LLMGateway) ListVoices(ctx context.Context, virtualKey string) ([]models.Voice, error) {
+	vk, err := g.resolveListingKey(ctx, virtualKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// A voice that names no models can be used with any of its provider's
+	// speech models, so those are listed to see whether the key allows one.
+	var speechModels [][]models.Model
+	if vk != nil && len(vk.AllowedModels) > 0 {
+		speechModels = listPerProvider(ctx, g, "models", virtualKey, vk, func(ctx context.Context, p llm.Provider) ([]models.Model, bool, error) {
+			mp, ok := p.(models.Provider)
+			if !ok {
+				return nil, false, nil
+			}
+			list, err := mp.ListModels(ctx)
+			return list, true, err
+		})
+	}
+
+	listings := listPerProvider(ctx, g, "voices", virtualKey, vk, func(ctx context.Context, p llm.Provider) ([]models.Voice, bool, error) {
+		vp, ok := p.(models.VoiceProvider)
+		if !ok {
+			return nil, false, nil
+		}
+		list, err := vp.ListVoices(ctx)
+		return list, true, err
+	})
+
+	var out []models.Voice
+	for i, providerName := range llm.GetAllProviderNames() {
+		for _, v := range listings[i] {
+			if speechModels != nil {
+				if len(v.Models) > 0 {
+					v.Models = slices.DeleteFunc(slices.Clone(v.Models), func(id string) bool {
+						return !modelAllowed(vk.AllowedModels, providerName, id)
+					})
+					if len(v.Models) == 0 {
+						continue
+					}
+				} else if !slices.ContainsFunc(speechModels[i], func(m models.Model) bool {
+					return slices.Contains(m.Capabilities, models.CapabilitySpeech) && modelAllowed(vk.AllowedModels, providerName, m.ID)
+				}) {
+					continue
+				}
+			}
+			v.Provider = string(providerName)
+			out = append(out, v)
+		}
+	}
+
+	return out, nil
+}
+
+// resolveListingKey resolves virtualKey when it is a virtual key. Any other
+// key — a provider API key the config store looks configs up by — lists
+// without restriction.
+func (g *LLMGateway) resolveListingKey(ctx context.Context, virtualKey string) (*VirtualKeyConfig, error) {
+	if virtualKey == "" {
+		return nil, nil
+	}
+	vk, err := g.ConfigStore.GetVirtualKey(ctx, virtualKey)
+	if errors.Is(err, ErrVirtualKeyNotFound) {
+		return nil, nil
+	}
+	return vk, err
+}
+
+// listPerProvider calls list for every configured provider at once, through
+// the cache. The listings are indexed like llm.GetAllProviderNames.
+func listPerProvider[T any](ctx context.Context, g *LLMGateway, kind, configKey string, vk *VirtualKeyConfig, list func(context.Context, llm.Provider) ([]T, bool, error)) [][]T {
+	ttl := g.ModelCacheTTL
+	if ttl <= 0 {
+		ttl = DefaultModelCacheTTL
+	}
+
+	providerNames := llm.GetAllProviderNames()
+	listings := make([][]T, len(providerNames))
+
+	var wg sync.WaitGroup
+	for i, providerName := range providerNames {
+		if vk != nil && len(vk.AllowedProviders) > 0 && !slices.Contains(vk.AllowedProviders, providerName) {
+			continue
+		}
+		if kind == "voices" && slices.Contains(voicelessProviders, providerName) {
+			continue
+		}
+
+		providerConfig, err := g.ConfigStore.GetProviderConfig(ctx, providerName, configKey)
+		if err != nil || providerConfig == nil {
+			// Not configured.
+			continue
+		}
+		apiKey := listingAPIKey(providerConfig)
+		if apiKey == "" {
+			continue
+		}
+
+		key := catalogKey{kind: kind, provider: providerName, apiKey: apiKey}
+		if cached, ok := g.catalog.get(key); ok {
+			listings[i] = cached.([]T)
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			p, err := g.getProvider(ctx, providerName, nil, apiKey)
+			if err != nil {
+				slog.WarnContext(ctx, "listing "+kind+" failed", slog.String("provider", string(providerName)), slog.Any("error", err))
+				return
+			}
+
+			listing, ok, err := list(ctx, p)
+			if !ok {
+				return
+			}
+			if err != nil {
+				slog.WarnContext(ctx, "listing "+kind+" failed", slog.String("provider", string(providerName)), slog.Any("error", err))
+				return
+			}
+
+			g.catalog.put(key, listing, ttl)
+			listings[i] = listing
+		}()
+	}
+	wg.Wait()
+
+	return listings
+}
+
+// listingAPIKey picks the enabled default key, else the first enabled one.
+func listingAPIKey(providerConfig *ProviderConfig) string {
+	enabled := enabledKeys(providerConfig.ApiKeys)
+	if len(enabled) == 0 {
+		return ""
+	}
+	return defaultKey(enabled).APIKey
+}
+
+// modelAllowed matches a model against AllowedModels entries, which name
+// it either bare ("gpt-4.1-mini") or with its provider ("OpenAI/gpt-4.1-mini").
+func modelAllowed(allowed []string, providerName llm.ProviderName, id string) bool {
+	return slices.Contains(allowed, id) || slices.Contains(allowed, string(providerName)+"/"+id)
+}
+
+func (p *InternalLLMGateway) ListModels(ctx context.Context, virtualKey string) ([]models.Model, error) {
+	return p.gateway.ListModels(ctx, virtualKey)
+}
+
+func (p *InternalLLMGateway) ListVoices(ctx context.Context, virtualKey string) ([]models.Voice, error) {
+	return p.gateway.ListVoices(ctx, virtualKey)
+}
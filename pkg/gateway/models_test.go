@@ -0,0 +1,238 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/models"
+)
+
+type listingConfigStore struct {
+	*InMemoryConfigStore
+	virtualKeys map[string]*VirtualKeyConfig
+}
+
+func (s *listingConfigStore) GetVirtualKey(_ context.Context, secretKey string) (*VirtualKeyConfig, error) {
+	if vk := s.virtualKeys[secretKey]; vk != nil {
+		return vk, nil
+	}
+	return nil, ErrVirtualKeyNotFound
+}
+
+func modelIDs(list []models.Model) []string {
+	var ids []string
+	for _, m := range list {
+		ids = append(ids, m.Provider+"/"+m.ID)
+	}
+	return ids
+}
+
+func TestListModels_CachesFiltersAndSkipsFailures(t *testing.T) {
+	var openaiCalls, anthropicCalls atomic.Int32
+	openaiUp := atomic.Bool{}
+	openaiUp.Store(true)
+
+	openaiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		openaiCalls.Add(1)
+		if r.URL.Path != "/models" || r.Header.Get("Authorization") != "Bearer sk-openai-default" {
+			t.Errorf("unexpected OpenAI request %s with %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		if !openaiUp.Load() {
+			http.Error(w, `{"error":{"message":"down"}}`, http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"object":"list","data":[{"id":"gpt-4.1-mini","created":1700000000,"owned_by":"openai"},{"id":"text-embedding-3-small","owned_by":"openai"}]}`))
+	}))
+	defer openaiSrv.Close()
+
+	anthropicSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		anthropicCalls.Add(1)
+		if r.URL.Query().Get("after_id") == "" {
+			w.Write([]byte(`{"data":[{"id":"claude-sonnet-4-5","display_name":"Claude Sonnet 4.5"}],"has_more":true,"last_id":"claude-sonnet-4-5"}`))
+			return
+		}
+		w.Write([]byte(`{"data":[{"id":"claude-haiku-4-5","display_name":"Claude Haiku 4.5"}],"has_more":false}`))
+	}))
+	defer anthropicSrv.Close()
+
+	store := &listingConfigStore{
+		InMemoryConfigStore: NewInMemoryConfigStore([]ProviderConfig{
+			{ProviderName: llm.ProviderNameOpenAI, BaseURL: openaiSrv.URL, ApiKeys: []*APIKeyConfig{
+				{APIKey: "sk-openai-other"},
+				{APIKey: "sk-openai-default", IsDefault: true},
+			}},
+			{ProviderName: llm.ProviderNameAnthropic, BaseURL: anthropicSrv.URL, ApiKeys: []*APIKeyConfig{{APIKey: "sk-ant"}}},
+		}),
+		virtualKeys: map[string]*VirtualKeyConfig{
+			"vk-team": {AllowedModels: []string{"gpt-4.1-mini", "Anthropic/claude-haiku-4-5"}},
+		},
+	}
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	gw := NewLLMGateway(store)
+	gw.catalog.now = clock.now
+	ctx := context.Background()
+
+	all, err := gw.ListModels(ctx, "")
+	if err != nil {
+		t.Fatalf("ListModels: %v", err)
+	}
+	want := []string{"OpenAI/gpt-4.1-mini", "OpenAI/text-embedding-3-small", "Anthropic/claude-sonnet-4-5", "Anthropic/claude-haiku-4-5"}
+	if got := modelIDs(all); len(got) != len(want) {
+		t.Fatalf("models = %v, want %v", got, want)
+	} else {
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("models = %v, want %v", got, want)
+			}
+		}
+	}
+	if c := all[1].Capabilities; len(c) != 1 || c[0] != models.CapabilityEmbeddings {
+		t.Fatalf("embedding model capabilities = %v", c)
+	}
+	if all[0].CreatedAt == nil || all[2].DisplayName != "Claude Sonnet 4.5" {
+		t.Fatalf("descriptors not mapped: %+v", all)
+	}
+
+	team, err := gw.ListModels(ctx, "vk-team")
+	if err != nil {
+		t.Fatalf("ListModels(vk-team): %v", err)
+	}
+	if got := modelIDs(team); len(got) != 2 || got[0] != "OpenAI/gpt-4.1-mini" || got[1] != "Anthropic/claude-haiku-4-5" {
+		t.Fatalf("virtual key listing = %v", got)
+	}
+	if openaiCalls.Load() != 1 || anthropicCalls.Load() != 2 {
+		t.Fatalf("second listing should come from the cache, got %d OpenAI and %d Anthropic calls", openaiCalls.Load(), anthropicCalls.Load())
+	}
+
+	// A key that is not a virtual key is a provider config key: nothing to
+	// restrict by.
+	if direct, err := gw.ListModels(ctx, "sk-direct"); err != nil || len(direct) != 4 {
+		t.Fatalf("listing with a provider key = %v, %v", modelIDs(direct), err)
+	}
+
+	// Once the cache expires a failing provider is left out, not cached.
+	clock.advance(DefaultModelCacheTTL)
+	openaiUp.Store(false)
+	partial, err := gw.ListModels(ctx, "")
+	if err != nil {
+		t.Fatalf("ListModels: %v", err)
+	}
+	if got := modelIDs(partial); len(got) != 2 || got[0] != "Anthropic/claude-sonnet-4-5" {
+		t.Fatalf("listing with OpenAI down = %v", got)
+	}
+
+	openaiUp.Store(true)
+	recovered, err := gw.ListModels(ctx, "")
+	if err != nil {
+		t.Fatalf("ListModels: %v", err)
+	}
+	if len(recovered) != 4 || openaiCalls.Load() != 3 {
+		t.Fatalf("OpenAI should be asked again after failing, got %d models and %d calls", len(recovered), openaiCalls.Load())
+	}
+}
+
+func TestListVoices_SkipsProvidersWithoutVoicesOfTheirOwn(t *testing.T) {
+	store := NewInMemoryConfigStore([]ProviderConfig{
+		{ProviderName: llm.ProviderNameOpenAI, ApiKeys: []*APIKeyConfig{{APIKey: "sk-openai"}}},
+		{ProviderName: llm.ProviderNameOllama, BaseURL: "http://127.0.0.1:0", ApiKeys: []*APIKeyConfig{{APIKey: "ollama"}}},
+	})
+	gw := NewLLMGateway(store)
+
+	voices, err := gw.ListVoices(context.Background(), "")
+	if err != nil {
+		t.Fatalf("ListVoices: %v", err)
+	}
+	if len(voices) == 0 {
+		t.Fatalf("expected OpenAI's voices")
+	}
+	for _, v := range voices {
+		if v.Provider != string(llm.ProviderNameOpenAI) {
+			t.Fatalf("voice %s listed under %s", v.ID, v.Provider)
+		}
+	}
+}
+
+// Direct mode has no virtual keys; the key passed is a provider key.
+func TestListModels_InMemoryStoreTakesProviderKeys(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"object":"list","data":[{"id":"gpt-4.1-mini"}]}`))
+	}))
+	defer srv.Close()
+
+	gw := NewLLMGateway(NewInMemoryConfigStore([]ProviderConfig{
+		{ProviderName: llm.ProviderNameOpenAI, BaseURL: srv.URL, ApiKeys: []*APIKeyConfig{{APIKey: "sk-openai"}}},
+	}))
+
+	list, err := gw.ListModels(context.Background(), "sk-openai")
+	if err != nil {
+		t.Fatalf("ListModels: %v", err)
+	}
+	if got := modelIDs(list); len(got) != 1 || got[0] != "OpenAI/gpt-4.1-mini" {
+		t.Fatalf("models = %v", got)
+	}
+}
+
+func TestListVoices_FiltersByAllowedModels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"object":"list","data":[{"id":"gpt-4.1-mini"},{"id":"tts-1"},{"id":"gpt-4o-mini-tts"}]}`))
+	}))
+	defer srv.Close()
+
+	store := &listingConfigStore{
+		InMemoryConfigStore: NewInMemoryConfigStore([]ProviderConfig{
+			{ProviderName: llm.ProviderNameOpenAI, BaseURL: srv.URL, ApiKeys: []*APIKeyConfig{{APIKey: "sk-openai"}}},
+		}),
+		virtualKeys: map[string]*VirtualKeyConfig{
+			"vk-tts-1":     {AllowedModels: []string{"tts-1"}},
+			"vk-mini-tts":  {AllowedModels: []string{"OpenAI/gpt-4o-mini-tts"}},
+			"vk-chat-only": {AllowedModels: []string{"gpt-4.1-mini"}},
+		},
+	}
+	gw := NewLLMGateway(store)
+	ctx := context.Background()
+
+	voiceIDs := func(key string) map[string]models.Voice {
+		t.Helper()
+		voices, err := gw.ListVoices(ctx, key)
+		if err != nil {
+			t.Fatalf("ListVoices(%s): %v", key, err)
+		}
+		out := map[string]models.Voice{}
+		for _, v := range voices {
+			out[v.ID] = v
+		}
+		return out
+	}
+
+	all := voiceIDs("")
+	if _, ok := all["marin"]; !ok {
+		t.Fatalf("voices = %v", all)
+	}
+
+	// tts-1 speaks with the voices that name no models, not with the ones
+	// only gpt-4o-mini-tts has.
+	tts1 := voiceIDs("vk-tts-1")
+	if _, ok := tts1["alloy"]; !ok {
+		t.Fatalf("alloy should be listed for tts-1: %v", tts1)
+	}
+	if _, ok := tts1["marin"]; ok {
+		t.Fatalf("marin is only for gpt-4o-mini-tts")
+	}
+
+	miniTTS := voiceIDs("vk-mini-tts")
+	if len(miniTTS) != len(all) {
+		t.Fatalf("gpt-4o-mini-tts has every voice, got %d of %d", len(miniTTS), len(all))
+	}
+	if m := miniTTS["marin"].Models; len(m) != 1 || m[0] != "gpt-4o-mini-tts" {
+		t.Fatalf("marin models = %v", m)
+	}
+
+	if chat := voiceIDs("vk-chat-only"); len(chat) != 0 {
+		t.Fatalf("a key with no speech model has no voices, got %v", chat)
+	}
+}
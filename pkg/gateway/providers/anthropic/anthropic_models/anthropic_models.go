@@ -0,0 +1,36 @@
+package anthropic_models
+
+import (
+	"time"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/models"
+)
+
+// ListResponse is one page of GET /models.
+type ListResponse struct {
+	Data    []Model `json:"data"`
+	HasMore bool    `json:"has_more"`
+	FirstID string  `json:"first_id"`
+	LastID  string  `json:"last_id"`
+}
+
+type Model struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	DisplayName string    `json:"display_name"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (m *Model) ToNative() models.Model {
+	out := models.Model{
+		ID:           m.ID,
+		DisplayName:  m.DisplayName,
+		Capabilities: []models.Capability{models.CapabilityText},
+		OwnedBy:      "anthropic",
+	}
+	if !m.CreatedAt.IsZero() {
+		createdAt := m.CreatedAt
+		out.CreatedAt = &createdAt
+	}
+	return out
+}
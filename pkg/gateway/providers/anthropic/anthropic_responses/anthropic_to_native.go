@@ -3,6 +3,7 @@ package anthropic_responses
 import (
 	"fmt"
 	"time"
+	"unicode/utf8"
 
 	"github.com/bytedance/sonic"
 	"github.com/google/uuid"
@@ -142,28 +143,92 @@ func (in *ToolUnion) ToNative() responses2.ToolUnion {
 		out.OfCodeExecution = &responses2.CodeExecutionTool{}
 	}
 
+	if in.OfWebFetchTool != nil {
+		out.OfWebFetch = &responses2.WebFetchTool{
+			Type:             "web_fetch",
+			MaxUses:          in.OfWebFetchTool.MaxUses,
+			AllowedDomains:   in.OfWebFetchTool.AllowedDomains,
+			BlockedDomains:   in.OfWebFetchTool.BlockedDomains,
+			MaxContentTokens: in.OfWebFetchTool.MaxContentTokens,
+		}
+	}
+
 	return out
 }
 
-func CitationsToNativeAnnotations(citations []Citation) []responses2.Annotation {
-	var annotations []responses2.Annotation
+// ToNative converts a web_fetch_tool_result into a web_fetch_call. The URL
+// comes from the server_tool_use that requested the fetch, since an error
+// result doesn't carry it.
+func (in *WebFetchResultContent) ToNative(serverToolUse *ServerToolUseContent) *responses2.WebFetchCallMessage {
+	out := &responses2.WebFetchCallMessage{
+		ID:     in.ToolUseId,
+		Status: "completed",
+		URL:    in.Content.URL,
+	}
+	if serverToolUse != nil && out.URL == "" {
+		out.URL = serverToolUse.Input.URL
+	}
+
+	if in.Content.ErrorCode != "" {
+		out.Status = "failed"
+		out.ErrorCode = in.Content.ErrorCode
+		return out
+	}
+
+	out.RetrievedAt = in.Content.RetrievedAt
+	if in.Content.Content != nil {
+		out.Title = in.Content.Content.Title
+		out.MediaType = in.Content.Content.Source.MediaType
+		out.Data = in.Content.Content.Source.Data
+	}
+
+	return out
+}
 
+// CitationsToNativeAnnotations converts the citations of a text block. A
+// cited passage is given its own text block, so each citation covers the
+// whole of text.
+func CitationsToNativeAnnotations(citations []Citation, text string) []responses2.Annotation {
+	annotations := []responses2.Annotation{}
 	for _, citation := range citations {
-		annotations = append(annotations, responses2.Annotation{
-			Type:       "url_citation",
-			Title:      citation.Title,
-			URL:        citation.Url,
-			StartIndex: 0,
-			EndIndex:   0,
-			ExtraParams: map[string]any{
-				"Anthropic": citation,
-			},
-		})
+		annotations = append(annotations, citation.ToNative(utf8.RuneCountInString(text)))
 	}
 
 	return annotations
 }
 
+func (in *Citation) ToNative(textLength int) responses2.Annotation {
+	out := responses2.Annotation{
+		Type:       "url_citation",
+		Title:      in.Title,
+		URL:        in.Url,
+		StartIndex: 0,
+		EndIndex:   textLength,
+		Snippet:    in.CitedText,
+		ExtraParams: map[string]any{
+			"Anthropic": *in,
+		},
+	}
+
+	switch in.Type {
+	case "search_result_location":
+		out.URL = in.Source
+
+	case "char_location", "page_location", "content_block_location":
+		out.Type = "file_citation"
+		out.URL = ""
+		if in.DocumentTitle != nil {
+			out.Title = *in.DocumentTitle
+			out.Filename = *in.DocumentTitle
+		}
+		if in.FileID != nil {
+			out.FileID = *in.FileID
+		}
+	}
+
+	return out
+}
+
 func ThinkingEffortToNativeReasoningEffort(effort *string) *string {
 	if effort == nil {
 		return nil
@@ -321,6 +386,12 @@ func (msg *MessageUnion) ToNativeMessage() responses2.InputMessageUnion {
 			}
 		}
 
+		if content.OfWebFetchToolResult != nil {
+			return responses2.InputMessageUnion{
+				OfWebFetchCall: content.OfWebFetchToolResult.ToNative(previousServerToolUse),
+			}
+		}
+
 		if content.OfBashCodeExecutionToolResult != nil {
 			if previousServerToolUse != nil && previousServerToolUse.Name == "bash_code_execution" {
 				id := previousServerToolUse.Id
@@ -352,7 +423,7 @@ func (msg *MessageUnion) ToNativeMessage() responses2.InputMessageUnion {
 				contents = append(contents, responses2.InputContentUnion{
 					OfOutputText: &responses2.OutputTextContent{
 						Text:        content.OfText.Text,
-						Annotations: CitationsToNativeAnnotations(content.OfText.Citations), // Convert citation to annotation
+						Annotations: CitationsToNativeAnnotations(content.OfText.Citations, content.OfText.Text),
 					},
 				})
 			} else {
@@ -398,7 +469,7 @@ func (in *Response) ToNativeResponse() *responses2.Response {
 						{
 							OfOutputText: &responses2.OutputTextContent{
 								Text:        content.OfText.Text,
-								Annotations: CitationsToNativeAnnotations(content.OfText.Citations),
+								Annotations: CitationsToNativeAnnotations(content.OfText.Citations, content.OfText.Text),
 							},
 						},
 					},
@@ -479,6 +550,14 @@ func (in *Response) ToNativeResponse() *responses2.Response {
 			previousWebSearchCall = nil
 		}
 
+		if content.OfWebFetchToolResult != nil {
+			output = append(output, responses2.OutputMessageUnion{
+				OfWebFetchCall: content.OfWebFetchToolResult.ToNative(previousWebSearchCall),
+			})
+
+			previousWebSearchCall = nil
+		}
+
 		if content.OfBashCodeExecutionToolResult != nil {
 			if previousWebSearchCall != nil && previousWebSearchCall.Name == "bash_code_execution" {
 				id := previousWebSearchCall.Id
@@ -547,7 +626,9 @@ type ResponseChunkToNativeResponseChunkConverter struct {
 	contentIndex     int // Always 0, as each Anthropic content becomes a separate native message
 	currentOutputID  string
 	accumulatedDelta string
-	accumulatedSig   string // Accumulated reasoning signature
+	accumulatedSig   string     // Accumulated reasoning signature
+	citations        []Citation // Citations of the current text block
+	serverToolUse    *ServerToolUseContent
 	completedOutputs []responses2.OutputMessageUnion
 }
 
@@ -629,6 +710,8 @@ func (c *ResponseChunkToNativeResponseChunkConverter) handleContentBlockStart(bl
 		return c.handleWebSearchToolResultBlockStart(content.OfWebSearchResult)
 	case content.OfBashCodeExecutionToolResult != nil:
 		return c.handleBashCodeExecutionToolResult(content.OfBashCodeExecutionToolResult)
+	case content.OfWebFetchToolResult != nil:
+		return nil
 	}
 
 	return nil
@@ -675,6 +758,13 @@ func (c *ResponseChunkToNativeResponseChunkConverter) handleServerToolUseBlockSt
 		}
 	}
 
+	if serverToolUse.Name == "web_fetch" {
+		c.serverToolUse = serverToolUse
+		return []*responses2.ResponseChunk{
+			c.buildOutputItemAddedWebFetchCall(),
+		}
+	}
+
 	return []*responses2.ResponseChunk{}
 }
 
@@ -701,10 +791,10 @@ func (c *ResponseChunkToNativeResponseChunkConverter) handleContentBlockDelta(de
 		return []*responses2.ResponseChunk{c.buildOutputTextDelta(text)}
 
 	case content.OfText != nil && delta.Delta.OfCitation != nil:
-		citation := delta.Delta.OfCitation.Citation
-		return []*responses2.ResponseChunk{
-			c.buildOutputTextAnnotationAdded(citation),
-		}
+		// The citations of a block arrive before its text. They cover the
+		// whole block, so they are reported once its length is known.
+		c.citations = append(c.citations, delta.Delta.OfCitation.Citation)
+		return nil
 
 	case content.OfToolUse != nil && delta.Delta.OfInputJSON != nil:
 		json := delta.Delta.OfInputJSON.PartialJSON
@@ -765,6 +855,8 @@ func (c *ResponseChunkToNativeResponseChunkConverter) handleContentBlockStop() [
 		result = c.completeWebSearchCallBlock(content.OfWebSearchResult)
 	case content.OfBashCodeExecutionToolResult != nil:
 		result = c.completeBashCodeExecutionToolResult(content.OfBashCodeExecutionToolResult)
+	case content.OfWebFetchToolResult != nil:
+		result = c.completeWebFetchToolResult(content.OfWebFetchToolResult)
 	}
 
 	// Reset for next block
@@ -778,6 +870,8 @@ func (c *ResponseChunkToNativeResponseChunkConverter) handleContentBlockStop() [
 func (c *ResponseChunkToNativeResponseChunkConverter) completeTextBlock() []*responses2.ResponseChunk {
 	text := c.accumulatedDelta
 	role := c.currentRole()
+	annotations := CitationsToNativeAnnotations(c.citations, text)
+	c.citations = nil
 
 	// Store for final response
 	c.completedOutputs = append(c.completedOutputs, responses2.OutputMessageUnion{
@@ -785,16 +879,21 @@ func (c *ResponseChunkToNativeResponseChunkConverter) completeTextBlock() []*res
 			ID:   c.currentOutputID,
 			Role: role,
 			Content: &responses2.OutputContent{
-				{OfOutputText: &responses2.OutputTextContent{Text: text}},
+				{OfOutputText: &responses2.OutputTextContent{Text: text, Annotations: annotations}},
 			},
 		},
 	})
 
-	return []*responses2.ResponseChunk{
-		c.buildOutputTextDone(text),
-		c.buildContentPartDoneText(text),
-		c.buildOutputItemDoneMessage(text, role),
+	var out []*responses2.ResponseChunk
+	for i := range annotations {
+		out = append(out, c.buildOutputTextAnnotationAdded(i, annotations[i]))
 	}
+
+	return append(out,
+		c.buildOutputTextDone(text),
+		c.buildContentPartDoneText(text, annotations),
+		c.buildOutputItemDoneMessage(text, role, annotations),
+	)
 }
 
 func (c *ResponseChunkToNativeResponseChunkConverter) completeToolUseBlock(toolUse *ToolUseContent) []*responses2.ResponseChunk {
@@ -842,8 +941,8 @@ func (c *ResponseChunkToNativeResponseChunkConverter) completeThinkingBlock() []
 func (c *ResponseChunkToNativeResponseChunkConverter) completeServerToolUseBlock(serverToolUse *ServerToolUseContent) []*responses2.ResponseChunk {
 	text := c.accumulatedDelta
 
-	if serverToolUse.Name == "web_search" {
-		return nil // we don't do anything for server_tool_use content stop, we will wait for content_block_stop of "web_search_tool_result"
+	if serverToolUse.Name == "web_search" || serverToolUse.Name == "web_fetch" {
+		return nil // we don't do anything for server_tool_use content stop, we will wait for content_block_stop of the tool result
 	}
 
 	if serverToolUse.Name == "bash_code_execution" {
@@ -876,6 +975,25 @@ func (c *ResponseChunkToNativeResponseChunkConverter) completeBashCodeExecutionT
 	}
 }
 
+func (c *ResponseChunkToNativeResponseChunkConverter) completeWebFetchToolResult(webFetchResult *WebFetchResultContent) []*responses2.ResponseChunk {
+	// The server_tool_use input streams as JSON deltas; it's only complete here.
+	var serverToolUse *ServerToolUseContent
+	if c.serverToolUse != nil {
+		serverToolUse = utils.Ptr(*c.serverToolUse)
+		_ = sonic.Unmarshal([]byte(c.accumulatedDelta), &serverToolUse.Input)
+	}
+	c.serverToolUse = nil
+
+	webFetchCall := webFetchResult.ToNative(serverToolUse)
+	c.completedOutputs = append(c.completedOutputs, responses2.OutputMessageUnion{
+		OfWebFetchCall: webFetchCall,
+	})
+
+	return []*responses2.ResponseChunk{
+		c.buildOutputItemDoneWebFetchCall(webFetchCall),
+	}
+}
+
 // handleMessageDelta stores usage info for the final response
 func (c *ResponseChunkToNativeResponseChunkConverter) handleMessageDelta(delta *ChunkMessage[ChunkTypeMessageDelta]) []*responses2.ResponseChunk {
 	c.messageDelta = delta
@@ -1162,24 +1280,16 @@ func (c *ResponseChunkToNativeResponseChunkConverter) buildOutputTextDelta(delta
 	}
 }
 
-func (c *ResponseChunkToNativeResponseChunkConverter) buildOutputTextAnnotationAdded(citation Citation) *responses2.ResponseChunk {
+func (c *ResponseChunkToNativeResponseChunkConverter) buildOutputTextAnnotationAdded(index int, annotation responses2.Annotation) *responses2.ResponseChunk {
 	return &responses2.ResponseChunk{
 		OfOutputTextAnnotationAdded: &responses2.ChunkOutputText[constants.ChunkTypeOutputTextAnnotationAdded]{
-			Type:           constants.ChunkTypeOutputTextAnnotationAdded(""),
-			SequenceNumber: c.nextSeqNum(),
-			ItemId:         c.currentOutputID,
-			OutputIndex:    c.outputIndex,
-			ContentIndex:   c.contentIndex,
-			Annotation: &responses2.Annotation{
-				Type:       "url_citation",
-				Title:      citation.Title,
-				URL:        citation.Url,
-				StartIndex: 0,
-				EndIndex:   0,
-				ExtraParams: map[string]any{
-					"Anthropic": citation,
-				},
-			},
+			Type:            constants.ChunkTypeOutputTextAnnotationAdded(""),
+			SequenceNumber:  c.nextSeqNum(),
+			ItemId:          c.currentOutputID,
+			OutputIndex:     c.outputIndex,
+			ContentIndex:    c.contentIndex,
+			Annotation:      &annotation,
+			AnnotationIndex: index,
 		},
 	}
 }
@@ -1235,7 +1345,7 @@ func (c *ResponseChunkToNativeResponseChunkConverter) buildOutputTextDone(text s
 	}
 }
 
-func (c *ResponseChunkToNativeResponseChunkConverter) buildContentPartDoneText(text string) *responses2.ResponseChunk {
+func (c *ResponseChunkToNativeResponseChunkConverter) buildContentPartDoneText(text string, annotations []responses2.Annotation) *responses2.ResponseChunk {
 	return &responses2.ResponseChunk{
 		OfContentPartDone: &responses2.ChunkContentPart[constants.ChunkTypeContentPartDone]{
 			Type:           constants.ChunkTypeContentPartDone(""),
@@ -1243,12 +1353,12 @@ func (c *ResponseChunkToNativeResponseChunkConverter) buildContentPartDoneText(t
 			ItemId:         c.currentOutputID,
 			OutputIndex:    c.outputIndex,
 			ContentIndex:   c.contentIndex,
-			Part:           responses2.ChunkOutputItemContentUnion{OfOutputText: &responses2.OutputTextContent{Text: text}},
+			Part:           responses2.ChunkOutputItemContentUnion{OfOutputText: &responses2.OutputTextContent{Text: text, Annotations: annotations}},
 		},
 	}
 }
 
-func (c *ResponseChunkToNativeResponseChunkConverter) buildOutputItemDoneMessage(text string, role constants.Role) *responses2.ResponseChunk {
+func (c *ResponseChunkToNativeResponseChunkConverter) buildOutputItemDoneMessage(text string, role constants.Role, annotations []responses2.Annotation) *responses2.ResponseChunk {
 	return &responses2.ResponseChunk{
 		OfOutputItemDone: &responses2.ChunkOutputItem[constants.ChunkTypeOutputItemDone]{
 			Type:           constants.ChunkTypeOutputItemDone(""),
@@ -1259,7 +1369,7 @@ func (c *ResponseChunkToNativeResponseChunkConverter) buildOutputItemDoneMessage
 				Id:      c.currentOutputID,
 				Status:  "completed",
 				Role:    role,
-				Content: &responses2.ChunkOutputItemContent{{OfOutputText: &responses2.OutputTextContent{Text: text}}},
+				Content: &responses2.ChunkOutputItemContent{{OfOutputText: &responses2.OutputTextContent{Text: text, Annotations: annotations}}},
 			},
 		},
 	}
@@ -1496,3 +1606,39 @@ func UsageWithSplitCacheTokens(uncachedPrompt, cacheRead, cacheWrite, output, re
 
 	return u
 }
+
+func (c *ResponseChunkToNativeResponseChunkConverter) buildOutputItemAddedWebFetchCall() *responses2.ResponseChunk {
+	return &responses2.ResponseChunk{
+		OfOutputItemAdded: &responses2.ChunkOutputItem[constants.ChunkTypeOutputItemAdded]{
+			Type:           constants.ChunkTypeOutputItemAdded(""),
+			SequenceNumber: c.nextSeqNum(),
+			OutputIndex:    c.outputIndex,
+			Item: responses2.ChunkOutputItemData{
+				Type:   "web_fetch_call",
+				Id:     c.currentOutputID,
+				Status: "in_progress",
+			},
+		},
+	}
+}
+
+func (c *ResponseChunkToNativeResponseChunkConverter) buildOutputItemDoneWebFetchCall(webFetchCall *responses2.WebFetchCallMessage) *responses2.ResponseChunk {
+	return &responses2.ResponseChunk{
+		OfOutputItemDone: &responses2.ChunkOutputItem[constants.ChunkTypeOutputItemDone]{
+			Type:           constants.ChunkTypeOutputItemDone(""),
+			SequenceNumber: c.nextSeqNum(),
+			OutputIndex:    c.outputIndex,
+			Item: responses2.ChunkOutputItemData{
+				Type:        "web_fetch_call",
+				Id:          webFetchCall.ID,
+				Status:      webFetchCall.Status,
+				URL:         &webFetchCall.URL,
+				Title:       &webFetchCall.Title,
+				MediaType:   &webFetchCall.MediaType,
+				Data:        &webFetchCall.Data,
+				RetrievedAt: &webFetchCall.RetrievedAt,
+				ErrorCode:   &webFetchCall.ErrorCode,
+			},
+		},
+	}
+}
@@ -3,6 +3,7 @@ package anthropic_responses
 import (
 	"testing"
 
+	"github.com/bytedance/sonic"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
 	"github.com/stretchr/testify/assert"
@@ -634,3 +635,94 @@ func TestResponseChunkToNative_OutputIndexTracking(t *testing.T) {
 	result = converter.ResponseChunkToNativeResponseChunk(createBlockStopChunk(1))
 	assert.Equal(t, 1, result[2].OfOutputItemDone.OutputIndex) // Second output block increments the output index.
 }
+
+// =============================================================================
+// Test: Web Fetch Results Convert and Replay
+// =============================================================================
+
+func TestWebFetch_ResponseAndReplay(t *testing.T) {
+	body := `{"id":"msg_1","type":"message","role":"assistant","content":[
+		{"type":"server_tool_use","id":"srvtoolu_1","name":"web_fetch","input":{"url":"https://example.com"}},
+		{"type":"web_fetch_tool_result","tool_use_id":"srvtoolu_1","content":{"type":"web_fetch_result","url":"https://example.com","retrieved_at":"2025-09-10T00:00:00Z","content":{"type":"document","source":{"type":"text","media_type":"text/plain","data":"Example Domain"},"title":"Example"}}},
+		{"type":"server_tool_use","id":"srvtoolu_2","name":"web_fetch","input":{"url":"https://example.org"}},
+		{"type":"web_fetch_tool_result","tool_use_id":"srvtoolu_2","content":{"type":"web_fetch_tool_result_error","error_code":"url_not_accessible"}}
+	]}`
+
+	var resp Response
+	require.NoError(t, sonic.Unmarshal([]byte(body), &resp))
+
+	out := resp.ToNativeResponse().Output
+	require.Len(t, out, 2)
+
+	fetched := out[0].OfWebFetchCall
+	require.NotNil(t, fetched)
+	assert.Equal(t, "completed", fetched.Status)
+	assert.Equal(t, "Example", fetched.Title)
+	assert.Equal(t, "Example Domain", fetched.Data)
+
+	failed := out[1].OfWebFetchCall
+	require.NotNil(t, failed)
+	assert.Equal(t, "failed", failed.Status)
+	assert.Equal(t, "https://example.org", failed.URL, "the URL comes from the server_tool_use")
+	assert.Equal(t, "url_not_accessible", failed.ErrorCode)
+
+	// The next turn replays each fetch as the pair Anthropic produced.
+	input, err := out[0].AsInput()
+	require.NoError(t, err)
+	msgs := NativeMessagesToMessage(responses.InputUnion{OfInputMessageList: responses.InputMessageList{input}})
+	require.Len(t, msgs, 1)
+	contents := msgs[0].Content.OfList
+	require.Len(t, contents, 2)
+	assert.Equal(t, "https://example.com", contents[0].OfServerToolUse.Input.URL)
+	require.NotNil(t, contents[1].OfWebFetchToolResult)
+	assert.Equal(t, "srvtoolu_1", contents[1].OfWebFetchToolResult.ToolUseId)
+	assert.Equal(t, "Example Domain", contents[1].OfWebFetchToolResult.Content.Content.Source.Data)
+}
+
+// =============================================================================
+// Test: Citations Become Annotations and Replay as Citations
+// =============================================================================
+
+func TestCitations_ResponseAndReplay(t *testing.T) {
+	body := `{"id":"msg_1","type":"message","role":"assistant","content":[
+		{"type":"text","text":"Café hours are 8–5.","citations":[
+			{"type":"web_search_result_location","url":"https://cafe.example","title":"Café","encrypted_index":"enc","cited_text":"Open 8am to 5pm"}
+		]}
+	]}`
+
+	var resp Response
+	require.NoError(t, sonic.Unmarshal([]byte(body), &resp))
+
+	out := resp.ToNativeResponse().Output
+	require.Len(t, out, 1)
+	text := (*out[0].OfOutputMessage.Content)[0].OfOutputText
+	require.Len(t, text.Annotations, 1)
+	annotation := text.Annotations[0]
+	assert.Equal(t, "url_citation", annotation.Type)
+	assert.Equal(t, "https://cafe.example", annotation.URL)
+	assert.Equal(t, "Open 8am to 5pm", annotation.Snippet)
+	assert.Equal(t, 0, annotation.StartIndex)
+	assert.Equal(t, 19, annotation.EndIndex, "the range counts characters, not bytes")
+
+	// Stored history decodes the original citation as a map; another
+	// provider's annotation must not be replayed to Anthropic.
+	input, err := out[0].AsInput()
+	require.NoError(t, err)
+	buf, err := sonic.Marshal(&input)
+	require.NoError(t, err)
+	var stored responses.InputMessageUnion
+	require.NoError(t, sonic.Unmarshal(buf, &stored))
+	require.NotNil(t, stored.OfEasyInput)
+	content := stored.OfEasyInput.Content.OfInputMessageList
+	content[0].OfOutputText.Annotations = append(content[0].OfOutputText.Annotations, responses.Annotation{
+		Type: "url_citation", URL: "https://other.example", ExtraParams: map[string]any{"Gemini": map[string]any{}},
+	})
+
+	msgs := NativeMessagesToMessage(responses.InputUnion{OfInputMessageList: responses.InputMessageList{stored}})
+	require.Len(t, msgs, 1)
+	replayed := msgs[0].Content.OfList[0].OfText
+	require.NotNil(t, replayed)
+	require.Len(t, replayed.Citations, 1)
+	assert.Equal(t, "enc", replayed.Citations[0].EncryptedIndex)
+	assert.Equal(t, "web_search_result_location", replayed.Citations[0].Type)
+}
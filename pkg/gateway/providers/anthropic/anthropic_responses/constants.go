@@ -100,6 +100,16 @@ func (m ContentTypeBashCodeExecutionToolResultContent) UnmarshalJSON(buf []byte)
 	return unmarshalConstantString(m, buf)
 }
 
+type ContentTypeWebFetchToolResultContent string
+
+func (m ContentTypeWebFetchToolResultContent) Value() string { return "web_fetch_tool_result" }
+func (m ContentTypeWebFetchToolResultContent) MarshalJSON() ([]byte, error) {
+	return sonic.Marshal(m.Value())
+}
+func (m ContentTypeWebFetchToolResultContent) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
 type ContentTypeDeltaText string
 
 func (m ContentTypeDeltaText) Value() string                  { return "text_delta" }
@@ -245,6 +255,14 @@ func (m ToolTypeCodeExecutionTool) UnmarshalJSON(buf []byte) error {
 	return unmarshalConstantString(m, buf)
 }
 
+type ToolTypeWebFetchTool string
+
+func (m ToolTypeWebFetchTool) Value() string                { return "web_fetch_20250910" }
+func (m ToolTypeWebFetchTool) MarshalJSON() ([]byte, error) { return sonic.Marshal(m.Value()) }
+func (m ToolTypeWebFetchTool) UnmarshalJSON(buf []byte) error {
+	return unmarshalConstantString(m, buf)
+}
+
 // ------------------ //
 // End of tool types //
 // ---------------- //
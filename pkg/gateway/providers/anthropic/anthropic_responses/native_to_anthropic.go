@@ -184,6 +184,19 @@ func NativeToolsToTools(nativeTools []responses2.ToolUnion) []ToolUnion {
 				OfCodeExecutionTool: codeExecutionTool,
 			})
 		}
+
+		if nativeTool.OfWebFetch != nil {
+			out = append(out, ToolUnion{
+				OfWebFetchTool: &WebFetchTool{
+					Type:             "web_fetch_20250910",
+					Name:             "web_fetch",
+					MaxUses:          nativeTool.OfWebFetch.MaxUses,
+					AllowedDomains:   nativeTool.OfWebFetch.AllowedDomains,
+					BlockedDomains:   nativeTool.OfWebFetch.BlockedDomains,
+					MaxContentTokens: nativeTool.OfWebFetch.MaxContentTokens,
+				},
+			})
+		}
 	}
 
 	return out
@@ -192,23 +205,64 @@ func NativeToolsToTools(nativeTools []responses2.ToolUnion) []ToolUnion {
 func NativeAnnotationsToCitations(annotations []responses2.Annotation) []Citation {
 	var citations []Citation
 	for _, annotation := range annotations {
-		if raw, exists := annotation.ExtraParams["Anthropic"]; exists {
-			if anthropicCitation, ok := raw.(Citation); ok {
-				citations = append(citations, anthropicCitation)
-			}
-		} else {
-			citations = append(citations, Citation{
-				Type:           "web_search_result_location",
-				Url:            annotation.URL,
-				Title:          annotation.Title,
-				EncryptedIndex: "",
-				CitedText:      "",
-			})
+		citations = append(citations, NativeAnnotationToCitation(annotation))
+	}
+	return citations
+}
+
+// nativeAnnotationsToReplayedCitations keeps the citations Anthropic made.
+// Anthropic checks the citations sent back to it against their sources, so
+// one made by another provider would fail the request.
+func nativeAnnotationsToReplayedCitations(annotations []responses2.Annotation) []Citation {
+	var citations []Citation
+	for _, annotation := range annotations {
+		if _, exists := annotation.ExtraParams["Anthropic"]; exists {
+			citations = append(citations, NativeAnnotationToCitation(annotation))
 		}
 	}
 	return citations
 }
 
+// NativeAnnotationToCitation returns the citation an annotation was made from
+// when it came from Anthropic, and builds one from the normalized fields
+// otherwise.
+func NativeAnnotationToCitation(annotation responses2.Annotation) Citation {
+	if raw, exists := annotation.ExtraParams["Anthropic"]; exists {
+		if anthropicCitation, ok := raw.(Citation); ok {
+			return anthropicCitation
+		}
+
+		// Read back from a stored conversation, the citation is a map.
+		var anthropicCitation Citation
+		if buf, err := sonic.Marshal(raw); err == nil {
+			if err := sonic.Unmarshal(buf, &anthropicCitation); err == nil && anthropicCitation.Type != "" {
+				return anthropicCitation
+			}
+		}
+	}
+
+	if annotation.Type == "file_citation" {
+		out := Citation{
+			Type:      "char_location",
+			CitedText: annotation.Snippet,
+		}
+		if annotation.Title != "" {
+			out.DocumentTitle = utils.Ptr(annotation.Title)
+		}
+		if annotation.FileID != "" {
+			out.FileID = utils.Ptr(annotation.FileID)
+		}
+		return out
+	}
+
+	return Citation{
+		Type:      "web_search_result_location",
+		Url:       annotation.URL,
+		Title:     annotation.Title,
+		CitedText: annotation.Snippet,
+	}
+}
+
 func NativeMessagesToMessage(in responses2.InputUnion) []MessageUnion {
 	out := []MessageUnion{}
 
@@ -253,7 +307,7 @@ func NativeMessagesToMessage(in responses2.InputUnion) []MessageUnion {
 							contents = append(contents, ContentUnion{
 								OfText: &TextContent{
 									Text:      nativeContent.OfOutputText.Text,
-									Citations: NativeAnnotationsToCitations(nativeContent.OfOutputText.Annotations),
+									Citations: nativeAnnotationsToReplayedCitations(nativeContent.OfOutputText.Annotations),
 								},
 							})
 						}
@@ -305,7 +359,7 @@ func NativeMessagesToMessage(in responses2.InputUnion) []MessageUnion {
 						contents = append(contents, ContentUnion{
 							OfText: &TextContent{
 								Text:      nativeContent.OfOutputText.Text,
-								Citations: NativeAnnotationsToCitations(nativeContent.OfOutputText.Annotations),
+								Citations: nativeAnnotationsToReplayedCitations(nativeContent.OfOutputText.Annotations),
 							},
 						})
 					}
@@ -459,10 +513,7 @@ func NativeMessagesToMessage(in responses2.InputUnion) []MessageUnion {
 							OfServerToolUse: &ServerToolUseContent{
 								Id:   nativeMessage.OfWebSearchCall.ID,
 								Name: "web_search",
-								Input: struct {
-									Query   string `json:"query"`
-									Command string `json:"command"`
-								}{
+								Input: ServerToolUseInput{
 									Query: query,
 								},
 							},
@@ -509,12 +560,9 @@ func NativeMessagesToMessage(in responses2.InputUnion) []MessageUnion {
 				// server_tool_use
 				contents = append(contents, ContentUnion{
 					OfServerToolUse: &ServerToolUseContent{
-						Id:   nativeMessage.OfCodeInterpreterCall.ID,
-						Name: "bash_code_execution",
-						Input: struct {
-							Query   string `json:"query"`
-							Command string `json:"command"`
-						}{Command: nativeMessage.OfCodeInterpreterCall.Code},
+						Id:    nativeMessage.OfCodeInterpreterCall.ID,
+						Name:  "bash_code_execution",
+						Input: ServerToolUseInput{Command: nativeMessage.OfCodeInterpreterCall.Code},
 					},
 				})
 
@@ -542,12 +590,62 @@ func NativeMessagesToMessage(in responses2.InputUnion) []MessageUnion {
 					Content: ContentUnionParam{OfList: contents},
 				})
 			}
+
+			if nativeMessage.OfWebFetchCall != nil {
+				out = append(out, MessageUnion{
+					Role:    RoleAssistant,
+					Content: ContentUnionParam{OfList: NativeWebFetchCallToContents(nativeMessage.OfWebFetchCall)},
+				})
+			}
 		}
 	}
 
 	return out
 }
 
+// NativeWebFetchCallToContents replays a fetch as the server_tool_use and
+// web_fetch_tool_result pair Anthropic produced it as.
+func NativeWebFetchCallToContents(in *responses2.WebFetchCallMessage) Contents {
+	result := WebFetchResultParam{
+		Type:        "web_fetch_result",
+		URL:         in.URL,
+		RetrievedAt: in.RetrievedAt,
+	}
+	if in.ErrorCode != "" {
+		result = WebFetchResultParam{
+			Type:      "web_fetch_tool_result_error",
+			ErrorCode: in.ErrorCode,
+		}
+	} else {
+		source := WebFetchDocumentSource{Type: "text", MediaType: "text/plain", Data: in.Data}
+		if in.MediaType != "" && in.MediaType != "text/plain" {
+			source.Type = "base64"
+			source.MediaType = in.MediaType
+		}
+		result.Content = &WebFetchDocument{
+			Type:   "document",
+			Source: source,
+			Title:  in.Title,
+		}
+	}
+
+	return Contents{
+		{
+			OfServerToolUse: &ServerToolUseContent{
+				Id:    in.ID,
+				Name:  "web_fetch",
+				Input: ServerToolUseInput{URL: in.URL},
+			},
+		},
+		{
+			OfWebFetchToolResult: &WebFetchResultContent{
+				ToolUseId: in.ID,
+				Content:   result,
+			},
+		},
+	}
+}
+
 // anthropicUsage is the inverse of nativeUsage: it splits the native
 // cache-inclusive InputTokens back into the shape Anthropic's wire format
 // expects, where input_tokens is only the uncached remainder and the cached
@@ -643,10 +741,7 @@ func NativeResponseToResponse(in *responses2.Response) *Response {
 						OfServerToolUse: &ServerToolUseContent{
 							Id:   nativeOutput.OfWebSearchCall.ID,
 							Name: "web_search",
-							Input: struct {
-								Query   string `json:"query"`
-								Command string `json:"command"`
-							}{
+							Input: ServerToolUseInput{
 								Query: query,
 							},
 						},
@@ -686,12 +781,9 @@ func NativeResponseToResponse(in *responses2.Response) *Response {
 			// server_tool_use
 			contents = append(contents, ContentUnion{
 				OfServerToolUse: &ServerToolUseContent{
-					Id:   nativeOutput.OfCodeInterpreterCall.ID,
-					Name: "bash_code_execution",
-					Input: struct {
-						Query   string `json:"query"`
-						Command string `json:"command"`
-					}{Command: nativeOutput.OfCodeInterpreterCall.Code},
+					Id:    nativeOutput.OfCodeInterpreterCall.ID,
+					Name:  "bash_code_execution",
+					Input: ServerToolUseInput{Command: nativeOutput.OfCodeInterpreterCall.Code},
 				},
 			})
 
@@ -714,6 +806,10 @@ func NativeResponseToResponse(in *responses2.Response) *Response {
 				},
 			})
 		}
+
+		if nativeOutput.OfWebFetchCall != nil {
+			contents = append(contents, NativeWebFetchCallToContents(nativeOutput.OfWebFetchCall)...)
+		}
 	}
 
 	var stopReason StopReason
@@ -1062,12 +1158,9 @@ func (c *NativeResponseChunkToResponseChunkConverter) buildContentBlockStartServ
 			Index: c.outputIndex,
 			ContentBlock: &ContentUnion{
 				OfServerToolUse: &ServerToolUseContent{
-					Id:   id,
-					Name: name,
-					Input: struct {
-						Query   string `json:"query"`
-						Command string `json:"command"`
-					}{Query: ""},
+					Id:    id,
+					Name:  name,
+					Input: ServerToolUseInput{Query: ""},
 				},
 			},
 		},
@@ -1109,15 +1202,13 @@ func (c *NativeResponseChunkToResponseChunkConverter) buildContentBlockDeltaText
 }
 
 func (c *NativeResponseChunkToResponseChunkConverter) buildContentBlockDeltaCitation(index int, annotation responses2.Annotation) ResponseChunk {
-	citations := NativeAnnotationsToCitations([]responses2.Annotation{annotation})
-
 	return ResponseChunk{
 		OfContentBlockDelta: &ChunkContentBlock[ChunkTypeContentBlockDelta]{
 			Type:  ChunkTypeContentBlockDelta("content_block_delta"),
 			Index: c.outputIndex,
 			Delta: &ChunkContentBlockDeltaUnion{
 				OfCitation: &DeltaCitation{
-					Citation: citations[0],
+					Citation: NativeAnnotationToCitation(annotation),
 				},
 			},
 		},
@@ -107,6 +107,7 @@ type ContentUnion struct {
 	OfServerToolUse               *ServerToolUseContent           `json:",omitempty"`
 	OfWebSearchResult             *WebSearchResultContent         `json:",omitempty"`
 	OfBashCodeExecutionToolResult *BashCodeExecutionResultContent `json:",omitempty"`
+	OfWebFetchToolResult          *WebFetchResultContent          `json:",omitempty"`
 }
 
 func (u *ContentUnion) UnmarshalJSON(data []byte) error {
@@ -164,6 +165,12 @@ func (u *ContentUnion) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 
+	var webFetchToolResult WebFetchResultContent
+	if err := sonic.Unmarshal(data, &webFetchToolResult); err == nil {
+		u.OfWebFetchToolResult = &webFetchToolResult
+		return nil
+	}
+
 	return errors.New("invalid input content union")
 }
 
@@ -204,6 +211,10 @@ func (u *ContentUnion) MarshalJSON() ([]byte, error) {
 		return sonic.Marshal(u.OfBashCodeExecutionToolResult)
 	}
 
+	if u.OfWebFetchToolResult != nil {
+		return sonic.Marshal(u.OfWebFetchToolResult)
+	}
+
 	return nil, nil
 }
 
@@ -214,11 +225,26 @@ type TextContent struct {
 }
 
 type Citation struct {
-	Type           string `json:"type"` // web_search_result_location
-	Url            string `json:"url"`
-	Title          string `json:"title"`
-	EncryptedIndex string `json:"encrypted_index"`
-	CitedText      string `json:"cited_text"`
+	Type      string `json:"type"` // "web_search_result_location", "search_result_location", "char_location", "page_location", "content_block_location"
+	CitedText string `json:"cited_text"`
+
+	// For "web_search_result_location" and "search_result_location"
+	Url               string `json:"url,omitempty"`
+	Title             string `json:"title,omitempty"`
+	EncryptedIndex    string `json:"encrypted_index,omitempty"`     // Only for "web_search_result_location"
+	Source            string `json:"source,omitempty"`              // Only for "search_result_location"
+	SearchResultIndex *int   `json:"search_result_index,omitempty"` // Only for "search_result_location"
+
+	// For the locations in a document
+	DocumentIndex   *int    `json:"document_index,omitempty"`
+	DocumentTitle   *string `json:"document_title,omitempty"`
+	FileID          *string `json:"file_id,omitempty"`
+	StartCharIndex  *int    `json:"start_char_index,omitempty"`  // Only for "char_location"
+	EndCharIndex    *int    `json:"end_char_index,omitempty"`    // Only for "char_location"
+	StartPageNumber *int    `json:"start_page_number,omitempty"` // Only for "page_location"
+	EndPageNumber   *int    `json:"end_page_number,omitempty"`   // Only for "page_location"
+	StartBlockIndex *int    `json:"start_block_index,omitempty"` // For "content_block_location" and "search_result_location"
+	EndBlockIndex   *int    `json:"end_block_index,omitempty"`   // For "content_block_location" and "search_result_location"
 }
 
 type ImageContent struct {
@@ -268,11 +294,14 @@ type RedactedThinkingContent struct {
 type ServerToolUseContent struct {
 	Type  ContentTypeServerToolUse `json:"type"`
 	Id    string                   `json:"id"`
-	Name  string                   `json:"name"` // "web_search", "bash_code_execution"
-	Input struct {
-		Query   string `json:"query"`
-		Command string `json:"command"`
-	} `json:"input"`
+	Name  string                   `json:"name"` // "web_search", "web_fetch", "bash_code_execution"
+	Input ServerToolUseInput       `json:"input"`
+}
+
+type ServerToolUseInput struct {
+	Query   string `json:"query"`
+	Command string `json:"command"`
+	URL     string `json:"url,omitempty"` // Only for "web_fetch"
 }
 
 type WebSearchResultContent struct {
@@ -311,10 +340,37 @@ type BashCodeExecutionResultParam struct {
 	Content    []any  `json:"content"`
 }
 
+type WebFetchResultContent struct {
+	Type      ContentTypeWebFetchToolResultContent `json:"type"`
+	ToolUseId string                               `json:"tool_use_id"`
+	Content   WebFetchResultParam                  `json:"content"`
+}
+
+type WebFetchResultParam struct {
+	Type        string            `json:"type"` // "web_fetch_result" or "web_fetch_tool_result_error"
+	URL         string            `json:"url,omitempty"`
+	Content     *WebFetchDocument `json:"content,omitempty"`
+	RetrievedAt string            `json:"retrieved_at,omitempty"`
+	ErrorCode   string            `json:"error_code,omitempty"` // Only for "web_fetch_tool_result_error"
+}
+
+type WebFetchDocument struct {
+	Type   string                 `json:"type"` // "document"
+	Source WebFetchDocumentSource `json:"source"`
+	Title  string                 `json:"title,omitempty"`
+}
+
+type WebFetchDocumentSource struct {
+	Type      string `json:"type"`       // "text" or "base64"
+	MediaType string `json:"media_type"` // "text/plain", "application/pdf"
+	Data      string `json:"data"`
+}
+
 type ToolUnion struct {
 	OfCustomTool        *CustomTool        `json:",omitempty"`
 	OfWebSearchTool     *WebSearchTool     `json:",omitempty"`
 	OfCodeExecutionTool *CodeExecutionTool `json:",omitempty"`
+	OfWebFetchTool      *WebFetchTool      `json:",omitempty"`
 }
 
 func (u *ToolUnion) UnmarshalJSON(data []byte) error {
@@ -336,6 +392,12 @@ func (u *ToolUnion) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 
+	var webFetchTool WebFetchTool
+	if err := sonic.Unmarshal(data, &webFetchTool); err == nil {
+		u.OfWebFetchTool = &webFetchTool
+		return nil
+	}
+
 	return errors.New("invalid tool union")
 }
 
@@ -352,6 +414,10 @@ func (u *ToolUnion) MarshalJSON() ([]byte, error) {
 		return sonic.Marshal(u.OfCodeExecutionTool)
 	}
 
+	if u.OfWebFetchTool != nil {
+		return sonic.Marshal(u.OfWebFetchTool)
+	}
+
 	return nil, nil
 }
 
@@ -375,3 +441,12 @@ type CodeExecutionTool struct {
 	Type ToolTypeCodeExecutionTool `json:"type"`
 	Name string                    `json:"name"` // "code_execution"
 }
+
+type WebFetchTool struct {
+	Type             ToolTypeWebFetchTool `json:"type"`
+	Name             string               `json:"name"` // "web_fetch"
+	MaxUses          *int                 `json:"max_uses,omitempty"`
+	AllowedDomains   []string             `json:"allowed_domains,omitempty"`
+	BlockedDomains   []string             `json:"blocked_domains,omitempty"`
+	MaxContentTokens *int                 `json:"max_content_tokens,omitempty"`
+}
@@ -6,11 +6,15 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"maps"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/bytedance/sonic"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/models"
 	responses2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/anthropic/anthropic_models"
 	anthropic_responses2 "github.com/hastekit/agent-sdk-go/pkg/gateway/providers/anthropic/anthropic_responses"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/base"
 	"github.com/hastekit/agent-sdk-go/pkg/utils"
@@ -22,7 +26,7 @@ type ClientOptions struct {
 	ApiKey  string
 	Headers map[string]string
 
-	transport *http.Client
+	Transport *http.Client
 }
 
 type Client struct {
@@ -31,8 +35,8 @@ type Client struct {
 }
 
 func NewClient(opts *ClientOptions) *Client {
-	if opts.transport == nil {
-		opts.transport = http.DefaultClient
+	if opts.Transport == nil {
+		opts.Transport = http.DefaultClient
 	}
 
 	if opts.BaseURL == "" {
@@ -68,6 +72,9 @@ func (c *Client) NewResponses(ctx context.Context, inp *responses2.Request) (*re
 		if t.OfCodeExecutionTool != nil {
 			betaHeaders = append(betaHeaders, "code-execution-2025-08-25")
 		}
+		if t.OfWebFetchTool != nil {
+			betaHeaders = append(betaHeaders, "web-fetch-2025-09-10")
+		}
 	}
 	if betaHeaders != nil && len(betaHeaders) > 0 {
 		req.Header.Set("anthropic-beta", strings.Join(betaHeaders, ","))
@@ -78,7 +85,7 @@ func (c *Client) NewResponses(ctx context.Context, inp *responses2.Request) (*re
 	}
 	base.AddAdditionalHeaders(req, inp.ExtraFields)
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -121,6 +128,9 @@ func (c *Client) NewStreamingResponses(ctx context.Context, inp *responses2.Requ
 		if t.OfCodeExecutionTool != nil {
 			betaHeaders = append(betaHeaders, "code-execution-2025-08-25")
 		}
+		if t.OfWebFetchTool != nil {
+			betaHeaders = append(betaHeaders, "web-fetch-2025-09-10")
+		}
 	}
 	if betaHeaders != nil && len(betaHeaders) > 0 {
 		req.Header.Set("anthropic-beta", strings.Join(betaHeaders, ","))
@@ -130,7 +140,7 @@ func (c *Client) NewStreamingResponses(ctx context.Context, inp *responses2.Requ
 	}
 	base.AddAdditionalHeaders(req, inp.ExtraFields)
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -175,3 +185,29 @@ func (c *Client) NewStreamingResponses(ctx context.Context, inp *responses2.Requ
 
 	return out, nil
 }
+
+// ListModels lists the Claude models available to the API key, following
+// the listing's pages.
+func (c *Client) ListModels(ctx context.Context) ([]models.Model, error) {
+	headers := map[string]string{
+		"x-api-key":         c.opts.ApiKey,
+		"Anthropic-Version": "2023-06-01",
+	}
+	maps.Copy(headers, c.opts.Headers)
+
+	var out []models.Model
+	query := url.Values{"limit": {"1000"}}
+	for {
+		var page anthropic_models.ListResponse
+		if err := base.GetJSON(ctx, c.opts.Transport, c.opts.BaseURL+"/models?"+query.Encode(), headers, &page); err != nil {
+			return nil, err
+		}
+		for _, m := range page.Data {
+			out = append(out, m.ToNative())
+		}
+		if !page.HasMore || page.LastID == "" {
+			return out, nil
+		}
+		query.Set("after_id", page.LastID)
+	}
+}
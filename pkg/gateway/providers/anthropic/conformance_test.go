@@ -0,0 +1,22 @@
+package anthropic_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/anthropic"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/providertest"
+)
+
+func TestConformance(t *testing.T) {
+	suite := providertest.Suite{
+		Provider:  "anthropic",
+		Model:     "claude-haiku-4-5",
+		APIKeyEnv: "ANTHROPIC_API_KEY",
+		NewProvider: func(apiKey string, transport *http.Client) llm.Provider {
+			return anthropic.NewClient(&anthropic.ClientOptions{ApiKey: apiKey, Transport: transport})
+		},
+	}
+	suite.Run(t)
+}
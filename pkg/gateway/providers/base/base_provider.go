@@ -11,6 +11,7 @@ import (
 	responses2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
 	speech2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/speech"
 	transcription2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
+	video_generation2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/video_generation"
 )
 
 type BaseProvider struct{}
@@ -47,6 +48,10 @@ func (bp *BaseProvider) NewTranscription(ctx context.Context, in *transcription2
 	return nil, nil
 }
 
+func (bp *BaseProvider) NewStreamingTranscription(ctx context.Context, in *transcription2.Request) (chan *transcription2.ResponseChunk, error) {
+	return nil, nil
+}
+
 func (bp *BaseProvider) NewImageGeneration(ctx context.Context, in *image_generation2.Request) (*image_generation2.Response, error) {
 	return nil, nil
 }
@@ -55,6 +60,18 @@ func (bp *BaseProvider) NewImageEdit(ctx context.Context, in *image_edit2.Reques
 	return nil, nil
 }
 
+func (bp *BaseProvider) NewVideoGeneration(ctx context.Context, in *video_generation2.Request) (*video_generation2.Response, error) {
+	return nil, nil
+}
+
+func (bp *BaseProvider) GetVideoGeneration(ctx context.Context, in *video_generation2.GetRequest) (*video_generation2.Response, error) {
+	return nil, nil
+}
+
+func (bp *BaseProvider) DownloadVideoGeneration(ctx context.Context, in *video_generation2.DownloadRequest) (*video_generation2.Content, error) {
+	return nil, nil
+}
+
 func AddAdditionalHeaders(req *http.Request, extraFields map[string]any) {
 	if extraFields != nil {
 		if additionalHeaders, ok := extraFields["additional_headers"]; ok {
@@ -1,7 +1,6 @@
 package base
 
 import (
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -9,6 +8,22 @@ import (
 	"github.com/bytedance/sonic"
 )
 
+// HTTPError is a provider error that carries the HTTP status it came with,
+// so callers can tell a revoked key (401) or throttling (429) from an
+// outage (5xx) without parsing the message.
+type HTTPError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *HTTPError) Error() string {
+	return e.Message
+}
+
+func (e *HTTPError) HTTPStatusCode() int {
+	return e.StatusCode
+}
+
 // ParseErrorResponse turns a non-2xx provider HTTP response into a
 // descriptive error. It reads (and so allows the caller to close) the
 // response body and never panics on an unexpected body shape: when the
@@ -18,18 +33,18 @@ import (
 //
 // Both the object form ({"error":{"message":...}}) and the array form
 // ([{"error":{"message":...}}], used by some Google/Gemini endpoints) are
-// recognized.
+// recognized. The returned error is an *HTTPError.
 func ParseErrorResponse(res *http.Response) error {
 	body, _ := io.ReadAll(res.Body)
 
 	if msg := extractErrorMessage(body); msg != "" {
-		return errors.New(msg)
+		return &HTTPError{StatusCode: res.StatusCode, Message: msg}
 	}
 
 	if len(body) > 0 {
-		return fmt.Errorf("request failed with status %d: %s", res.StatusCode, string(body))
+		return &HTTPError{StatusCode: res.StatusCode, Message: fmt.Sprintf("request failed with status %d: %s", res.StatusCode, string(body))}
 	}
-	return fmt.Errorf("request failed with status %d", res.StatusCode)
+	return &HTTPError{StatusCode: res.StatusCode, Message: fmt.Sprintf("request failed with status %d", res.StatusCode)}
 }
 
 func extractErrorMessage(body []byte) string {
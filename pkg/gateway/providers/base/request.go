@@ -0,0 +1,32 @@
+package base
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+)
+
+// GetJSON sends a GET request and decodes the JSON response into out. A
+// non-2xx response is returned as the error ParseErrorResponse makes of it.
+func GetJSON(ctx context.Context, transport *http.Client, url string, headers map[string]string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := transport.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return ParseErrorResponse(res)
+	}
+
+	return utils.DecodeJSON(res.Body, out)
+}
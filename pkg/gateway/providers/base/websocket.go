@@ -0,0 +1,93 @@
+package base
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"golang.org/x/net/websocket"
+)
+
+// maxWebSocketMessageBytes bounds a single server message. Realtime audio
+// deltas are small, but session and error events can carry whole configs.
+const maxWebSocketMessageBytes = 16 << 20
+
+// WebSocketConn is a JSON-over-WebSocket connection as used by the realtime
+// APIs. Writes are serialized; reads must come from a single goroutine.
+type WebSocketConn struct {
+	ws      *websocket.Conn
+	writeMu sync.Mutex
+}
+
+// WebSocketURL turns an http(s) URL into its ws(s) counterpart.
+func WebSocketURL(httpURL string) string {
+	switch {
+	case strings.HasPrefix(httpURL, "https://"):
+		return "wss://" + strings.TrimPrefix(httpURL, "https://")
+	case strings.HasPrefix(httpURL, "http://"):
+		return "ws://" + strings.TrimPrefix(httpURL, "http://")
+	}
+	return httpURL
+}
+
+func DialWebSocket(ctx context.Context, wsURL string, headers http.Header) (*WebSocketConn, error) {
+	origin := strings.Replace(strings.Replace(wsURL, "wss://", "https://", 1), "ws://", "http://", 1)
+
+	config, err := websocket.NewConfig(wsURL, origin)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		config.Header[k] = v
+	}
+
+	ws, err := config.DialContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ws.MaxPayloadBytes = maxWebSocketMessageBytes
+
+	return &WebSocketConn{ws: ws}, nil
+}
+
+// WriteJSON sends v as one text message. The context's deadline, if any,
+// bounds the write.
+func (c *WebSocketConn) WriteJSON(ctx context.Context, v any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	payload, err := sonic.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	deadline, _ := ctx.Deadline()
+	if err := c.ws.SetWriteDeadline(deadline); err != nil {
+		return err
+	}
+	_, err = c.ws.Write(payload)
+	if err == nil {
+		_ = c.ws.SetWriteDeadline(time.Time{})
+	}
+	return err
+}
+
+// Read returns the next message, text or binary.
+func (c *WebSocketConn) Read() ([]byte, error) {
+	var msg []byte
+	if err := websocket.Message.Receive(c.ws, &msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (c *WebSocketConn) Close() error {
+	return c.ws.Close()
+}
@@ -0,0 +1,64 @@
+package bedrock_models
+
+import (
+	"slices"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/models"
+)
+
+// ListResponse is the response of the control plane's GET /foundation-models.
+type ListResponse struct {
+	ModelSummaries []ModelSummary `json:"modelSummaries"`
+}
+
+type ModelSummary struct {
+	ModelID          string          `json:"modelId"`
+	ModelName        string          `json:"modelName"`
+	ProviderName     string          `json:"providerName"`
+	InputModalities  []string        `json:"inputModalities"`
+	OutputModalities []string        `json:"outputModalities"`
+	ModelLifecycle   *ModelLifecycle `json:"modelLifecycle,omitempty"`
+}
+
+type ModelLifecycle struct {
+	// Status is ACTIVE or LEGACY.
+	Status string `json:"status"`
+}
+
+func (r *ListResponse) ToNative() []models.Model {
+	out := make([]models.Model, 0, len(r.ModelSummaries))
+	for _, m := range r.ModelSummaries {
+		out = append(out, models.Model{
+			ID:           m.ModelID,
+			DisplayName:  m.ModelName,
+			Capabilities: capabilities(m.OutputModalities),
+			OwnedBy:      m.ProviderName,
+		})
+	}
+	return out
+}
+
+func capabilities(outputModalities []string) []models.Capability {
+	var out []models.Capability
+	for _, modality := range outputModalities {
+		var c models.Capability
+		switch modality {
+		case "TEXT":
+			c = models.CapabilityText
+		case "EMBEDDING":
+			c = models.CapabilityEmbeddings
+		case "IMAGE":
+			c = models.CapabilityImageGeneration
+		case "VIDEO":
+			c = models.CapabilityVideoGeneration
+		case "SPEECH":
+			c = models.CapabilitySpeech
+		default:
+			continue
+		}
+		if !slices.Contains(out, c) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -243,6 +243,11 @@ func nativeToolsToConverseTools(nativeTools []responses.ToolUnion) []Tool {
 		if nativeTool.OfCodeExecution != nil {
 			slog.Warn("code execution tool is not supported for bedrock converse, skipping")
 		}
+
+		if nativeTool.OfFileSearch != nil || nativeTool.OfMCP != nil || nativeTool.OfWebFetch != nil ||
+			nativeTool.OfURLContext != nil || nativeTool.OfGoogleMaps != nil {
+			slog.Warn("provider-hosted tools are not supported for bedrock converse, skipping")
+		}
 	}
 
 	return out
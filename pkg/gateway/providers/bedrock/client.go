@@ -6,13 +6,16 @@ import (
 	"errors"
 	"io"
 	"log/slog"
+	"maps"
 	"net/http"
 	"net/url"
 	"strings"
 
 	"github.com/bytedance/sonic"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/models"
 	responses2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/base"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/bedrock/bedrock_models"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/bedrock/bedrock_responses"
 	"github.com/hastekit/agent-sdk-go/pkg/utils"
 )
@@ -23,7 +26,7 @@ type ClientOptions struct {
 	ApiKey  string
 	Headers map[string]string
 
-	transport *http.Client
+	Transport *http.Client
 }
 
 type Client struct {
@@ -32,8 +35,8 @@ type Client struct {
 }
 
 func NewClient(opts *ClientOptions) *Client {
-	if opts.transport == nil {
-		opts.transport = http.DefaultClient
+	if opts.Transport == nil {
+		opts.Transport = http.DefaultClient
 	}
 
 	if opts.BaseURL == "" {
@@ -81,7 +84,7 @@ func (c *Client) NewResponses(ctx context.Context, inp *responses2.Request) (*re
 		req.Header.Set(k, v)
 	}
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -125,7 +128,7 @@ func (c *Client) NewStreamingResponses(ctx context.Context, inp *responses2.Requ
 		req.Header.Set(k, v)
 	}
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -203,3 +206,20 @@ func (c *Client) NewStreamingResponses(ctx context.Context, inp *responses2.Requ
 
 	return out, nil
 }
+
+// ListModels lists the foundation models of the region. The listing lives
+// on the control plane, bedrock.{region}, rather than on the runtime host
+// the client is configured with.
+func (c *Client) ListModels(ctx context.Context) ([]models.Model, error) {
+	headers := map[string]string{"Authorization": "Bearer " + c.opts.ApiKey}
+	maps.Copy(headers, c.opts.Headers)
+
+	controlPlane := strings.Replace(c.opts.BaseURL, "bedrock-runtime", "bedrock", 1)
+
+	var list bedrock_models.ListResponse
+	if err := base.GetJSON(ctx, c.opts.Transport, controlPlane+"/foundation-models", headers, &list); err != nil {
+		return nil, err
+	}
+
+	return list.ToNative(), nil
+}
@@ -0,0 +1,24 @@
+package bedrock_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/bedrock"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/providertest"
+)
+
+func TestConformance(t *testing.T) {
+	suite := providertest.Suite{
+		Provider:  "bedrock",
+		Model:     "us.anthropic.claude-haiku-4-5-20251001-v1:0",
+		APIKeyEnv: "AWS_BEARER_TOKEN_BEDROCK",
+		// Converse requests carry no output schema.
+		Scenarios: []string{"text", "tool_call", "parallel_tool_calls", "reasoning", "image_input"},
+		NewProvider: func(apiKey string, transport *http.Client) llm.Provider {
+			return bedrock.NewClient(&bedrock.ClientOptions{ApiKey: apiKey, Transport: transport})
+		},
+	}
+	suite.Run(t)
+}
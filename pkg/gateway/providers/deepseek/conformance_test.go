@@ -0,0 +1,24 @@
+package deepseek_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/deepseek"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/providertest"
+)
+
+func TestConformance(t *testing.T) {
+	suite := providertest.Suite{
+		Provider:  "deepseek",
+		Model:     "deepseek-chat",
+		APIKeyEnv: "DEEPSEEK_API_KEY",
+		// The model has no vision.
+		Scenarios: []string{"text", "tool_call", "parallel_tool_calls", "reasoning", "structured_output"},
+		NewProvider: func(apiKey string, transport *http.Client) llm.Provider {
+			return deepseek.NewClient(&deepseek.ClientOptions{ApiKey: apiKey, Transport: transport})
+		},
+	}
+	suite.Run(t)
+}
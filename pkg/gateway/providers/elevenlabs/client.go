@@ -6,14 +6,18 @@ import (
 	"context"
 	"errors"
 	"io"
+	"maps"
 	"mime/multipart"
 	"net/http"
+	"slices"
 	"strconv"
 
 	"github.com/bytedance/sonic"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/models"
 	speech2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/speech"
 	transcription2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/base"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/elevenlabs/elevenlabs_models"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/elevenlabs/elevenlabs_speech"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/elevenlabs/elevenlabs_transcription"
 	"github.com/hastekit/agent-sdk-go/pkg/utils"
@@ -25,7 +29,7 @@ type ClientOptions struct {
 	ApiKey  string
 	Headers map[string]string
 
-	transport *http.Client
+	Transport *http.Client
 }
 
 type Client struct {
@@ -34,8 +38,8 @@ type Client struct {
 }
 
 func NewClient(opts *ClientOptions) *Client {
-	if opts.transport == nil {
-		opts.transport = http.DefaultClient
+	if opts.Transport == nil {
+		opts.Transport = http.DefaultClient
 	}
 
 	if opts.BaseURL == "" {
@@ -71,7 +75,7 @@ func (c *Client) NewSpeech(ctx context.Context, in *speech2.Request) (*speech2.R
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("xi-api-key", c.opts.ApiKey)
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -141,7 +145,7 @@ func (c *Client) NewStreamingSpeech(ctx context.Context, in *speech2.Request) (c
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("xi-api-key", c.opts.ApiKey)
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -239,6 +243,12 @@ func (c *Client) NewTranscription(ctx context.Context, in *transcription2.Reques
 		}
 	}
 
+	if in.Diarize != nil && *in.Diarize {
+		if err = writer.WriteField("diarize", "true"); err != nil {
+			return nil, err
+		}
+	}
+
 	if in.Temperature != nil {
 		if err = writer.WriteField("temperature", strconv.FormatFloat(*in.Temperature, 'f', -1, 64)); err != nil {
 			return nil, err
@@ -258,7 +268,7 @@ func (c *Client) NewTranscription(ctx context.Context, in *transcription2.Reques
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 	req.Header.Set("xi-api-key", c.opts.ApiKey)
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -289,3 +299,50 @@ func (c *Client) NewTranscription(ctx context.Context, in *transcription2.Reques
 
 	return elResponse.ToNativeResponse(), nil
 }
+
+// NewStreamingTranscription transcribes live audio over the realtime
+// speech-to-text WebSocket; see elevenlabs_transcription.Stream.
+func (c *Client) NewStreamingTranscription(ctx context.Context, in *transcription2.Request) (chan *transcription2.ResponseChunk, error) {
+	return elevenlabs_transcription.Stream(ctx, &elevenlabs_transcription.StreamOptions{
+		BaseURL: c.opts.BaseURL,
+		ApiKey:  c.opts.ApiKey,
+		Headers: c.opts.Headers,
+	}, in)
+}
+
+// ListModels lists the text-to-speech models available to the API key,
+// followed by the speech-to-text models, which the API does not list.
+func (c *Client) ListModels(ctx context.Context) ([]models.Model, error) {
+	var list []elevenlabs_models.Model
+	if err := base.GetJSON(ctx, c.opts.Transport, c.opts.BaseURL+"/models", c.authHeaders(), &list); err != nil {
+		return nil, err
+	}
+
+	out := make([]models.Model, 0, len(list)+len(elevenlabs_models.TranscriptionModels))
+	for _, m := range list {
+		out = append(out, m.ToNative())
+	}
+	for _, m := range elevenlabs_models.TranscriptionModels {
+		if !slices.ContainsFunc(out, func(listed models.Model) bool { return listed.ID == m.ID }) {
+			out = append(out, m)
+		}
+	}
+
+	return out, nil
+}
+
+// ListVoices lists the premade voices and the account's own.
+func (c *Client) ListVoices(ctx context.Context) ([]models.Voice, error) {
+	var list elevenlabs_models.ListVoicesResponse
+	if err := base.GetJSON(ctx, c.opts.Transport, c.opts.BaseURL+"/voices", c.authHeaders(), &list); err != nil {
+		return nil, err
+	}
+
+	return list.ToNative(), nil
+}
+
+func (c *Client) authHeaders() map[string]string {
+	headers := map[string]string{"xi-api-key": c.opts.ApiKey}
+	maps.Copy(headers, c.opts.Headers)
+	return headers
+}
@@ -0,0 +1,88 @@
+package elevenlabs_models
+
+import (
+	"slices"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/models"
+)
+
+// Model is one entry of GET /models, which returns a bare array.
+type Model struct {
+	ModelID                string     `json:"model_id"`
+	Name                   string     `json:"name"`
+	Description            string     `json:"description"`
+	CanDoTextToSpeech      bool       `json:"can_do_text_to_speech"`
+	CanDoVoiceConversion   bool       `json:"can_do_voice_conversion"`
+	MaximumTextLengthLimit int        `json:"maximum_text_length_per_request"`
+	Languages              []Language `json:"languages"`
+}
+
+type Language struct {
+	LanguageID string `json:"language_id"`
+	Name       string `json:"name"`
+}
+
+func (m *Model) ToNative() models.Model {
+	out := models.Model{
+		ID:          m.ModelID,
+		DisplayName: m.Name,
+		Description: m.Description,
+		OwnedBy:     "elevenlabs",
+	}
+	if m.CanDoTextToSpeech {
+		out.Capabilities = []models.Capability{models.CapabilitySpeech}
+	}
+	return out
+}
+
+// TranscriptionModels are the speech-to-text models, which GET /models does
+// not list.
+var TranscriptionModels = []models.Model{
+	{ID: "scribe_v1", DisplayName: "Scribe v1", Capabilities: []models.Capability{models.CapabilityTranscription}, OwnedBy: "elevenlabs"},
+	{ID: "scribe_v2", DisplayName: "Scribe v2", Capabilities: []models.Capability{models.CapabilityTranscription}, OwnedBy: "elevenlabs"},
+	{ID: "scribe_v2_realtime", DisplayName: "Scribe v2 Realtime", Capabilities: []models.Capability{models.CapabilityTranscription}, OwnedBy: "elevenlabs"},
+}
+
+// ListVoicesResponse is the response of GET /voices: the premade voices and
+// those the account has added or cloned.
+type ListVoicesResponse struct {
+	Voices []Voice `json:"voices"`
+}
+
+type Voice struct {
+	VoiceID     string            `json:"voice_id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Category    string            `json:"category"`
+	Labels      map[string]string `json:"labels"`
+	PreviewURL  string            `json:"preview_url"`
+	// HighQualityBaseModelIDs are the models the voice is tuned for.
+	HighQualityBaseModelIDs []string `json:"high_quality_base_model_ids"`
+	VerifiedLanguages       []struct {
+		Language string `json:"language"`
+	} `json:"verified_languages"`
+}
+
+func (r *ListVoicesResponse) ToNative() []models.Voice {
+	out := make([]models.Voice, 0, len(r.Voices))
+	for _, v := range r.Voices {
+		voice := models.Voice{
+			ID:          v.VoiceID,
+			Name:        v.Name,
+			Description: v.Description,
+			Gender:      v.Labels["gender"],
+			Models:      slices.Clone(v.HighQualityBaseModelIDs),
+			PreviewURL:  v.PreviewURL,
+		}
+		if voice.Description == "" {
+			voice.Description = v.Labels["description"]
+		}
+		for _, l := range v.VerifiedLanguages {
+			if l.Language != "" && !slices.Contains(voice.Languages, l.Language) {
+				voice.Languages = append(voice.Languages, l.Language)
+			}
+		}
+		out = append(out, voice)
+	}
+	return out
+}
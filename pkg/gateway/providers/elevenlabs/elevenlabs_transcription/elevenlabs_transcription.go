@@ -31,12 +31,17 @@ func (r *Response) ToNativeResponse() *transcription.Response {
 	for _, w := range r.Words {
 		if w.Type == "word" {
 			resp.Words = append(resp.Words, transcription.Word{
-				Word:  w.Text,
-				Start: w.Start,
-				End:   w.End,
+				Word:    w.Text,
+				Start:   w.Start,
+				End:     w.End,
+				Speaker: w.SpeakerID,
 			})
 		}
 	}
 
+	// ElevenLabs only times words; segments are derived from them, split
+	// where the speaker changes.
+	resp.Segments = transcription.SegmentsFromWords(resp.Words)
+
 	return resp
 }
@@ -0,0 +1,253 @@
+package elevenlabs_transcription
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/base"
+)
+
+const DefaultRealtimeModel = "scribe_v2_realtime"
+
+// finalCommitTimeout bounds the wait for the transcript of the last commit
+// once the input has ended.
+const finalCommitTimeout = 10 * time.Second
+
+// settleDelay is how long the stream stays open after a transcript that may
+// be the last. Transcripts of VAD commits can still be in flight when the
+// final commit goes out, and nothing tells them apart, so the stream ends
+// once they stop arriving.
+const settleDelay = 500 * time.Millisecond
+
+// frameBytes is 100ms of 16kHz audio, the chunk size ElevenLabs recommends.
+const frameBytes = 3200
+
+type StreamOptions struct {
+	// https://api.elevenlabs.io/v1
+	BaseURL string
+	ApiKey  string
+	Headers map[string]string
+}
+
+// realtimeMessage is any server message on the realtime speech-to-text
+// socket; which fields are set depends on MessageType.
+type realtimeMessage struct {
+	MessageType  string `json:"message_type"`
+	Text         string `json:"text"`
+	LanguageCode string `json:"language_code"`
+	Words        []Word `json:"words"`
+	Error        string `json:"error"`
+}
+
+// Stream transcribes in over the realtime speech-to-text WebSocket. Audio is
+// committed by server VAD as the speaker pauses, and once more when the input
+// ends; the stream closes once the transcripts after that stop arriving.
+func Stream(ctx context.Context, opts *StreamOptions, in *transcription.Request) (chan *transcription.ResponseChunk, error) {
+	model := in.Model
+	if model == "" {
+		model = DefaultRealtimeModel
+	}
+	sampleRate := in.StreamSampleRate()
+
+	q := url.Values{}
+	q.Set("model_id", model)
+	q.Set("audio_format", "pcm_"+strconv.Itoa(sampleRate))
+	q.Set("commit_strategy", "vad")
+	q.Set("include_timestamps", "true")
+	if in.Language != nil {
+		q.Set("language_code", *in.Language)
+	}
+
+	headers := http.Header{}
+	headers.Set("xi-api-key", opts.ApiKey)
+	for k, v := range opts.Headers {
+		headers.Set(k, v)
+	}
+
+	conn, err := base.DialWebSocket(ctx, base.WebSocketURL(opts.BaseURL)+"/speech-to-text/realtime?"+q.Encode(), headers)
+	if err != nil {
+		return nil, fmt.Errorf("elevenlabs realtime transcription: %w", err)
+	}
+
+	s := &stream{conn: conn, out: make(chan *transcription.ResponseChunk, 16), closed: make(chan struct{})}
+	go s.send(ctx, in, sampleRate)
+	go s.receive(ctx)
+
+	return s.out, nil
+}
+
+type stream struct {
+	conn *base.WebSocketConn
+	out  chan *transcription.ResponseChunk
+	// closed is closed when receive returns.
+	closed chan struct{}
+
+	mu sync.Mutex
+	// committed is set once the final commit is sent; from then on each
+	// committed transcript may be the last.
+	committed bool
+	settle    *time.Timer
+	// sendErr is why the input could not be streamed, if it could not.
+	sendErr error
+	// duration is the length of the audio sent, in seconds.
+	duration float64
+}
+
+func (s *stream) send(ctx context.Context, in *transcription.Request, sampleRate int) {
+	chunk := func(audio []byte, commit bool) map[string]any {
+		return map[string]any{
+			"message_type":  "input_audio_chunk",
+			"audio_base_64": base64.StdEncoding.EncodeToString(audio),
+			"commit":        commit,
+			"sample_rate":   sampleRate,
+		}
+	}
+
+	var sent int
+	err := in.ReadAudio(ctx, frameBytes, func(frame []byte) error {
+		sent += len(frame)
+		return s.conn.WriteJSON(ctx, chunk(frame, false))
+	})
+	if err == nil {
+		err = s.conn.WriteJSON(ctx, chunk(nil, true))
+	}
+
+	s.mu.Lock()
+	s.committed = true
+	s.sendErr = err
+	s.duration = transcription.PCMDuration(sent, sampleRate)
+	s.mu.Unlock()
+
+	if err != nil {
+		_ = s.conn.Close()
+		return
+	}
+
+	// Don't wait forever for a transcript that never comes, e.g. when the
+	// final commit had no audio left to transcribe.
+	select {
+	case <-time.After(finalCommitTimeout):
+	case <-s.closed:
+	case <-ctx.Done():
+	}
+	_ = s.conn.Close()
+}
+
+func (s *stream) receive(ctx context.Context) {
+	defer close(s.out)
+	defer close(s.closed)
+
+	var (
+		segments []string
+		language *string
+	)
+	done := func() {
+		s.mu.Lock()
+		duration := s.duration
+		s.mu.Unlock()
+
+		s.out <- &transcription.ResponseChunk{OfDone: &transcription.ChunkDone[transcription.ChunkTypeDone]{
+			Text:     strings.Join(segments, " "),
+			Language: language,
+			Duration: &duration,
+		}}
+	}
+	fail := func(code, message string) {
+		s.out <- &transcription.ResponseChunk{OfError: &transcription.ChunkError[transcription.ChunkTypeError]{
+			Code:    code,
+			Message: message,
+		}}
+	}
+
+	for {
+		raw, err := s.conn.Read()
+		if err != nil {
+			s.mu.Lock()
+			committed, sendErr := s.committed, s.sendErr
+			s.mu.Unlock()
+
+			switch {
+			case sendErr != nil:
+				fail("", sendErr.Error())
+			case ctx.Err() != nil:
+				fail("", ctx.Err().Error())
+			case committed:
+				done()
+			default:
+				fail("", err.Error())
+			}
+			return
+		}
+
+		var msg realtimeMessage
+		if err := sonic.Unmarshal(raw, &msg); err != nil {
+			continue
+		}
+
+		switch {
+		case msg.Error != "":
+			fail(msg.MessageType, msg.Error)
+			_ = s.conn.Close()
+			return
+
+		case msg.MessageType == "partial_transcript":
+			if msg.Text == "" {
+				continue
+			}
+			s.out <- &transcription.ResponseChunk{OfPartial: &transcription.ChunkPartial[transcription.ChunkTypePartial]{
+				Text: msg.Text,
+			}}
+
+		// With include_timestamps every commit arrives twice, plain and
+		// with timestamps; only the latter is used.
+		case msg.MessageType == "committed_transcript_with_timestamps":
+			final := msg.toFinal()
+			if final.Text != "" {
+				segments = append(segments, final.Text)
+				if final.Language != nil {
+					language = final.Language
+				}
+				s.out <- &transcription.ResponseChunk{OfFinal: final}
+			}
+
+			s.mu.Lock()
+			if s.committed && s.sendErr == nil {
+				if s.settle != nil {
+					s.settle.Stop()
+				}
+				s.settle = time.AfterFunc(settleDelay, func() { _ = s.conn.Close() })
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (m *realtimeMessage) toFinal() *transcription.ChunkFinal[transcription.ChunkTypeFinal] {
+	final := &transcription.ChunkFinal[transcription.ChunkTypeFinal]{Text: strings.TrimSpace(m.Text)}
+	if m.LanguageCode != "" {
+		lang := m.LanguageCode
+		final.Language = &lang
+	}
+
+	for _, w := range m.Words {
+		if w.Type != "word" {
+			continue
+		}
+		if len(final.Words) == 0 {
+			final.Start = w.Start
+		}
+		final.End = w.End
+		final.Words = append(final.Words, transcription.Word{Word: w.Text, Start: w.Start, End: w.End})
+	}
+
+	return final
+}
@@ -0,0 +1,115 @@
+package elevenlabs_transcription
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/realtime/realtimetest"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collect(t *testing.T, out chan *transcription.ResponseChunk) []*transcription.ResponseChunk {
+	t.Helper()
+	var chunks []*transcription.ResponseChunk
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case c, ok := <-out:
+			if !ok {
+				return chunks
+			}
+			chunks = append(chunks, c)
+		case <-timeout:
+			t.Fatal("stream did not close")
+		}
+	}
+}
+
+func TestStream_PartialsFinalsAndDone(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	srv := realtimetest.NewServer()
+	defer srv.Close()
+
+	frames := make(chan []byte, 2)
+	out, err := Stream(ctx, &StreamOptions{BaseURL: srv.URL + "/v1", ApiKey: "xi-test"}, &transcription.Request{
+		AudioFrames: frames,
+	})
+	require.NoError(t, err)
+
+	conn, err := srv.Accept(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "/v1/speech-to-text/realtime", conn.Request.URL.Path)
+	assert.Equal(t, DefaultRealtimeModel, conn.Request.URL.Query().Get("model_id"))
+	assert.Equal(t, "pcm_16000", conn.Request.URL.Query().Get("audio_format"))
+	assert.Equal(t, "xi-test", conn.Request.Header.Get("xi-api-key"))
+
+	frames <- []byte{1, 2, 3, 4}
+	msg, err := conn.ReceiveType(ctx, "message_type", "input_audio_chunk")
+	require.NoError(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte{1, 2, 3, 4}), msg["audio_base_64"])
+	assert.Equal(t, false, msg["commit"])
+
+	require.NoError(t, conn.Send(map[string]any{"message_type": "partial_transcript", "text": "hel"}))
+	require.NoError(t, conn.Send(map[string]any{"message_type": "committed_transcript", "text": "hello"}))
+	require.NoError(t, conn.Send(map[string]any{
+		"message_type": "committed_transcript_with_timestamps", "text": "hello", "language_code": "en",
+		"words": []any{map[string]any{"text": "hello", "start": 0.1, "end": 0.5, "type": "word"}},
+	}))
+
+	// Ending the input commits what is left; its transcript ends the stream.
+	close(frames)
+	msg, err = conn.ReceiveType(ctx, "message_type", "input_audio_chunk")
+	require.NoError(t, err)
+	assert.Equal(t, true, msg["commit"])
+	require.NoError(t, conn.Send(map[string]any{
+		"message_type": "committed_transcript_with_timestamps", "text": " world",
+		"words": []any{map[string]any{"text": "world", "start": 0.7, "end": 1.0, "type": "word"}},
+	}))
+
+	chunks := collect(t, out)
+	require.Len(t, chunks, 4)
+	assert.Equal(t, "hel", chunks[0].OfPartial.Text)
+
+	first := chunks[1].OfFinal
+	require.NotNil(t, first)
+	assert.Equal(t, "hello", first.Text)
+	assert.Equal(t, 0.1, first.Start)
+	assert.Equal(t, 0.5, first.End)
+	assert.Equal(t, []transcription.Word{{Word: "hello", Start: 0.1, End: 0.5}}, first.Words)
+
+	assert.Equal(t, "world", chunks[2].OfFinal.Text)
+
+	done := chunks[3].OfDone
+	require.NotNil(t, done)
+	assert.Equal(t, "hello world", done.Text)
+	assert.Equal(t, "en", *done.Language)
+}
+
+func TestStream_ServerError(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	srv := realtimetest.NewServer()
+	defer srv.Close()
+
+	frames := make(chan []byte)
+	defer close(frames)
+	out, err := Stream(ctx, &StreamOptions{BaseURL: srv.URL}, &transcription.Request{AudioFrames: frames})
+	require.NoError(t, err)
+
+	conn, err := srv.Accept(ctx)
+	require.NoError(t, err)
+	require.NoError(t, conn.Send(map[string]any{"message_type": "quota_exceeded", "error": "Quota exceeded"}))
+
+	chunks := collect(t, out)
+	require.Len(t, chunks, 1)
+	require.NotNil(t, chunks[0].OfError)
+	assert.Equal(t, "quota_exceeded", chunks[0].OfError.Code)
+	assert.Equal(t, "Quota exceeded", chunks[0].OfError.Message)
+}
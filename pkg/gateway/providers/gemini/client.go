@@ -4,25 +4,37 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"net/http"
+	"net/url"
+	"slices"
 	"strings"
+	"sync/atomic"
 
 	"github.com/bytedance/sonic"
 	embeddings2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/embeddings"
 	image_edit2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/image_edit"
 	image_generation2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/image_generation"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/models"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/realtime"
 	responses2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
 	speech2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/speech"
 	transcription2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
+	video_generation2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/video_generation"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/base"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/gemini/gemini_embeddings"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/gemini/gemini_image_edit"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/gemini/gemini_image_generation"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/gemini/gemini_models"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/gemini/gemini_realtime"
 	gemini_responses2 "github.com/hastekit/agent-sdk-go/pkg/gateway/providers/gemini/gemini_responses"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/gemini/gemini_speech"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/gemini/gemini_transcription"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/gemini/gemini_video_generation"
 	"github.com/hastekit/agent-sdk-go/pkg/utils"
 )
 
@@ -32,7 +44,7 @@ type ClientOptions struct {
 	ApiKey  string
 	Headers map[string]string
 
-	transport *http.Client
+	Transport *http.Client
 }
 
 type Client struct {
@@ -41,8 +53,8 @@ type Client struct {
 }
 
 func NewClient(opts *ClientOptions) *Client {
-	if opts.transport == nil {
-		opts.transport = http.DefaultClient
+	if opts.Transport == nil {
+		opts.Transport = http.DefaultClient
 	}
 
 	if opts.BaseURL == "" {
@@ -87,7 +99,7 @@ func (c *Client) NewResponses(ctx context.Context, inp *responses2.Request) (*re
 	}
 	base.AddAdditionalHeaders(req, inp.ExtraFields)
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -134,7 +146,7 @@ func (c *Client) NewStreamingResponses(ctx context.Context, inp *responses2.Requ
 		req.Header.Set(k, v)
 	}
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -249,7 +261,7 @@ func (c *Client) NewEmbedding(ctx context.Context, inp *embeddings2.Request) (*e
 		req.Header.Set(k, v)
 	}
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -296,7 +308,7 @@ func (c *Client) NewSpeech(ctx context.Context, inp *speech2.Request) (*speech2.
 		req.Header.Set(k, v)
 	}
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -343,7 +355,7 @@ func (c *Client) NewStreamingSpeech(ctx context.Context, inp *speech2.Request) (
 		req.Header.Set(k, v)
 	}
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -451,7 +463,7 @@ func (c *Client) NewTranscription(ctx context.Context, inp *transcription2.Reque
 		req.Header.Set(k, v)
 	}
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -470,6 +482,121 @@ func (c *Client) NewTranscription(ctx context.Context, inp *transcription2.Reque
 	return geminiResponse.ToNativeResponse(), nil
 }
 
+// NewStreamingTranscription streams the transcript of in's audio as Gemini
+// generates it. Gemini takes the audio inline with the prompt, so the input
+// is uploaded as base64 WAV inside the request as it is read, and the first
+// partial comes back once it ends.
+func (c *Client) NewStreamingTranscription(ctx context.Context, inp *transcription2.Request) (chan *transcription2.ResponseChunk, error) {
+	sampleRate := inp.StreamSampleRate()
+
+	file := *inp
+	file.Audio, file.AudioFrames, file.AudioStream = nil, nil, nil
+	file.AudioFilename = "audio.wav"
+	// Partials are plain text; structured segments would stream as JSON.
+	file.Diarize, file.TimestampGranularities = nil, nil
+	geminiRequest := gemini_transcription.NativeRequestToRequest(&file)
+
+	model := inp.Model
+	if model == "" {
+		model = "gemini-2.0-flash"
+	}
+
+	endpoint := fmt.Sprintf("%s/models/%s:streamGenerateContent?alt=sse", c.opts.BaseURL, model)
+
+	// The request is marshalled with a placeholder for the audio, which is
+	// written in its place as it is read.
+	const placeholder = "hastekit-streamed-audio"
+	geminiRequest.Contents[0].Parts[0].InlineData.Data = placeholder
+	payload, err := sonic.Marshal(geminiRequest)
+	if err != nil {
+		return nil, err
+	}
+	head, tail, _ := bytes.Cut(payload, []byte(`"`+placeholder+`"`))
+
+	body, pw := io.Pipe()
+	var sent atomic.Int64
+	go func() {
+		if _, err := pw.Write(append(head, '"')); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		encoder := base64.NewEncoder(base64.StdEncoding, pw)
+		n, err := inp.StreamWAV(ctx, encoder)
+		sent.Store(int64(n))
+		if err == nil {
+			err = encoder.Close()
+		}
+		if err == nil {
+			_, err = pw.Write(append([]byte(`"`), tail...))
+		}
+		pw.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		_ = body.Close()
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", c.opts.ApiKey)
+
+	for k, v := range c.opts.Headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := c.opts.Transport.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, base.ParseErrorResponse(res)
+	}
+
+	out := make(chan *transcription2.ResponseChunk)
+
+	go func() {
+		defer res.Body.Close()
+		defer close(out)
+
+		reader := bufio.NewReader(res.Body)
+		converter := gemini_transcription.StreamConverter{}
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					out <- &transcription2.ResponseChunk{OfError: &transcription2.ChunkError[transcription2.ChunkTypeError]{Message: err.Error()}}
+					return
+				}
+				break
+			}
+
+			line = strings.TrimRight(line, "\r\n")
+			if !strings.HasPrefix(line, "data:") {
+				continue
+			}
+
+			chunk := &gemini_transcription.Response{}
+			if err = sonic.Unmarshal([]byte(strings.TrimPrefix(line, "data:")), chunk); err != nil {
+				continue
+			}
+			for _, c := range converter.Convert(chunk) {
+				out <- c
+			}
+		}
+
+		// The upload has ended by the time the transcript is complete.
+		converter.Duration = transcription2.PCMDuration(int(sent.Load()), sampleRate)
+		for _, c := range converter.Finish() {
+			out <- c
+		}
+	}()
+
+	return out, nil
+}
+
 func (c *Client) NewImageGeneration(ctx context.Context, inp *image_generation2.Request) (*image_generation2.Response, error) {
 	geminiRequest := gemini_image_generation.NativeRequestToRequest(inp)
 
@@ -497,7 +624,7 @@ func (c *Client) NewImageGeneration(ctx context.Context, inp *image_generation2.
 		req.Header.Set(k, v)
 	}
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -557,7 +684,7 @@ func (c *Client) NewImageEdit(ctx context.Context, inp *image_edit2.Request) (*i
 		req.Header.Set(k, v)
 	}
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -589,3 +716,157 @@ func (c *Client) NewImageEdit(ctx context.Context, inp *image_edit2.Request) (*i
 
 	return geminiEditResponse.ToNativeResponse(), nil
 }
+
+// NewRealtimeSession opens a Gemini Live session.
+func (c *Client) NewRealtimeSession(ctx context.Context, cfg *realtime.SessionConfig) (realtime.Session, error) {
+	return gemini_realtime.Dial(ctx, &gemini_realtime.Options{
+		BaseURL: c.opts.BaseURL,
+		ApiKey:  c.opts.ApiKey,
+		Headers: c.opts.Headers,
+	}, cfg)
+}
+
+// NewVideoGeneration starts a Veo job. The returned ID is the operation name,
+// which GetVideoGeneration polls.
+func (c *Client) NewVideoGeneration(ctx context.Context, inp *video_generation2.Request) (*video_generation2.Response, error) {
+	geminiRequest := gemini_video_generation.NativeRequestToRequest(inp)
+
+	model := inp.Model
+	if model == "" {
+		model = gemini_video_generation.DefaultModel
+	}
+
+	payload, err := sonic.Marshal(geminiRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.doVideoRequest(ctx, http.MethodPost, fmt.Sprintf("%s/models/%s:predictLongRunning", c.opts.BaseURL, model), bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var op *gemini_video_generation.Operation
+	if err = utils.DecodeJSON(res.Body, &op); err != nil {
+		return nil, err
+	}
+
+	return op.ToNativeResponse(), nil
+}
+
+func (c *Client) GetVideoGeneration(ctx context.Context, inp *video_generation2.GetRequest) (*video_generation2.Response, error) {
+	op, err := c.getVideoOperation(ctx, inp.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return op.ToNativeResponse(), nil
+}
+
+func (c *Client) DownloadVideoGeneration(ctx context.Context, inp *video_generation2.DownloadRequest) (*video_generation2.Content, error) {
+	op, err := c.getVideoOperation(ctx, inp.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	uri := op.VideoURI()
+	if uri == "" {
+		resp := op.ToNativeResponse()
+		if resp.Error != nil {
+			return nil, fmt.Errorf("gemini video generation failed: %s", resp.Error.Message)
+		}
+		return nil, errors.New("gemini video generation is not complete")
+	}
+
+	res, err := c.doVideoRequest(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := res.Header.Get("Content-Type")
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = "video/mp4"
+	}
+
+	return &video_generation2.Content{Data: data, MimeType: contentType}, nil
+}
+
+func (c *Client) getVideoOperation(ctx context.Context, name string) (*gemini_video_generation.Operation, error) {
+	res, err := c.doVideoRequest(ctx, http.MethodGet, c.opts.BaseURL+"/"+name, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var op *gemini_video_generation.Operation
+	if err = utils.DecodeJSON(res.Body, &op); err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// doVideoRequest sends a request to the Veo and files APIs and turns an
+// error status into an error.
+func (c *Client) doVideoRequest(ctx context.Context, method, endpoint string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("x-goog-api-key", c.opts.ApiKey)
+	for k, v := range c.opts.Headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := c.opts.Transport.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, base.ParseErrorResponse(res)
+	}
+
+	return res, nil
+}
+
+// ListModels lists the models available to the API key, following the
+// listing's pages.
+func (c *Client) ListModels(ctx context.Context) ([]models.Model, error) {
+	headers := map[string]string{"x-goog-api-key": c.opts.ApiKey}
+	maps.Copy(headers, c.opts.Headers)
+
+	var out []models.Model
+	query := url.Values{"pageSize": {"1000"}}
+	for {
+		var page gemini_models.ListResponse
+		if err := base.GetJSON(ctx, c.opts.Transport, c.opts.BaseURL+"/models?"+query.Encode(), headers, &page); err != nil {
+			return nil, err
+		}
+		for _, m := range page.Models {
+			out = append(out, m.ToNative())
+		}
+		if page.NextPageToken == "" {
+			return out, nil
+		}
+		query.Set("pageToken", page.NextPageToken)
+	}
+}
+
+// ListVoices lists the prebuilt speech voices. Gemini has no voice listing
+// endpoint, so this is a fixed list that makes no request.
+func (c *Client) ListVoices(ctx context.Context) ([]models.Voice, error) {
+	return slices.Clone(gemini_models.Voices), nil
+}
@@ -0,0 +1,22 @@
+package gemini_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/gemini"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/providertest"
+)
+
+func TestConformance(t *testing.T) {
+	suite := providertest.Suite{
+		Provider:  "gemini",
+		Model:     "gemini-2.5-flash",
+		APIKeyEnv: "GEMINI_API_KEY",
+		NewProvider: func(apiKey string, transport *http.Client) llm.Provider {
+			return gemini.NewClient(&gemini.ClientOptions{ApiKey: apiKey, Transport: transport})
+		},
+	}
+	suite.Run(t)
+}
@@ -0,0 +1,114 @@
+package gemini_models
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/models"
+)
+
+// ListResponse is one page of GET /models.
+type ListResponse struct {
+	Models        []Model `json:"models"`
+	NextPageToken string  `json:"nextPageToken"`
+}
+
+type Model struct {
+	// Name is "models/{id}".
+	Name                       string   `json:"name"`
+	DisplayName                string   `json:"displayName"`
+	Description                string   `json:"description"`
+	InputTokenLimit            int      `json:"inputTokenLimit"`
+	OutputTokenLimit           int      `json:"outputTokenLimit"`
+	SupportedGenerationMethods []string `json:"supportedGenerationMethods"`
+}
+
+func (m *Model) ToNative() models.Model {
+	id := strings.TrimPrefix(m.Name, "models/")
+	out := models.Model{
+		ID:           id,
+		DisplayName:  m.DisplayName,
+		Description:  m.Description,
+		Capabilities: capabilities(id, m.SupportedGenerationMethods),
+		OwnedBy:      "google",
+	}
+	if m.InputTokenLimit > 0 {
+		limit := m.InputTokenLimit
+		out.ContextWindow = &limit
+	}
+	if m.OutputTokenLimit > 0 {
+		limit := m.OutputTokenLimit
+		out.MaxOutputTokens = &limit
+	}
+	return out
+}
+
+// capabilities maps the API methods a model supports to what it can be used
+// for. generateContent serves text, speech and image models alike, so the
+// id tells those apart.
+func capabilities(id string, methods []string) []models.Capability {
+	var out []models.Capability
+	add := func(c models.Capability) {
+		if !slices.Contains(out, c) {
+			out = append(out, c)
+		}
+	}
+
+	for _, method := range methods {
+		switch method {
+		case "generateContent":
+			switch {
+			case strings.Contains(id, "tts"):
+				add(models.CapabilitySpeech)
+			case strings.Contains(id, "image"):
+				add(models.CapabilityImageGeneration)
+			default:
+				add(models.CapabilityText)
+			}
+		case "embedContent":
+			add(models.CapabilityEmbeddings)
+		case "predict":
+			add(models.CapabilityImageGeneration)
+		case "predictLongRunning":
+			add(models.CapabilityVideoGeneration)
+		case "bidiGenerateContent":
+			add(models.CapabilityRealtime)
+		}
+	}
+	return out
+}
+
+// The speech API has no voice listing; these are the documented prebuilt
+// voices, each described by its character.
+var Voices = []models.Voice{
+	{ID: "Zephyr", Name: "Zephyr", Description: "Bright"},
+	{ID: "Puck", Name: "Puck", Description: "Upbeat"},
+	{ID: "Charon", Name: "Charon", Description: "Informative"},
+	{ID: "Kore", Name: "Kore", Description: "Firm"},
+	{ID: "Fenrir", Name: "Fenrir", Description: "Excitable"},
+	{ID: "Leda", Name: "Leda", Description: "Youthful"},
+	{ID: "Orus", Name: "Orus", Description: "Firm"},
+	{ID: "Aoede", Name: "Aoede", Description: "Breezy"},
+	{ID: "Callirrhoe", Name: "Callirrhoe", Description: "Easy-going"},
+	{ID: "Autonoe", Name: "Autonoe", Description: "Bright"},
+	{ID: "Enceladus", Name: "Enceladus", Description: "Breathy"},
+	{ID: "Iapetus", Name: "Iapetus", Description: "Clear"},
+	{ID: "Umbriel", Name: "Umbriel", Description: "Easy-going"},
+	{ID: "Algieba", Name: "Algieba", Description: "Smooth"},
+	{ID: "Despina", Name: "Despina", Description: "Smooth"},
+	{ID: "Erinome", Name: "Erinome", Description: "Clear"},
+	{ID: "Algenib", Name: "Algenib", Description: "Gravelly"},
+	{ID: "Rasalgethi", Name: "Rasalgethi", Description: "Informative"},
+	{ID: "Laomedeia", Name: "Laomedeia", Description: "Upbeat"},
+	{ID: "Achernar", Name: "Achernar", Description: "Soft"},
+	{ID: "Alnilam", Name: "Alnilam", Description: "Firm"},
+	{ID: "Schedar", Name: "Schedar", Description: "Even"},
+	{ID: "Gacrux", Name: "Gacrux", Description: "Mature"},
+	{ID: "Pulcherrima", Name: "Pulcherrima", Description: "Forward"},
+	{ID: "Achird", Name: "Achird", Description: "Friendly"},
+	{ID: "Zubenelgenubi", Name: "Zubenelgenubi", Description: "Casual"},
+	{ID: "Vindemiatrix", Name: "Vindemiatrix", Description: "Gentle"},
+	{ID: "Sadachbia", Name: "Sadachbia", Description: "Lively"},
+	{ID: "Sadaltager", Name: "Sadaltager", Description: "Knowledgeable"},
+	{ID: "Sulafat", Name: "Sulafat", Description: "Warm"},
+}
@@ -0,0 +1,616 @@
+package gemini_realtime
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bytedance/sonic"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/realtime"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/base"
+)
+
+// defaultInputSampleRate is what the Live API expects; output is always
+// 24kHz.
+const defaultInputSampleRate = 16000
+
+type Options struct {
+	// https://generativelanguage.googleapis.com/v1beta
+	BaseURL string
+	ApiKey  string
+	Headers map[string]string
+}
+
+// Session is a realtime.Session over the Gemini Live API.
+//
+// Gemini has no response ids; each model turn is numbered instead. Barge-in
+// is reported only as EventInterrupted, since the Live API does not surface
+// its VAD decisions.
+type Session struct {
+	conn   *base.WebSocketConn
+	events chan *realtime.Event
+
+	inputMimeType string
+	manualTurns   bool
+
+	mu sync.Mutex
+	// activityOpen is whether an activityStart has been sent without its
+	// activityEnd, when server VAD is off.
+	activityOpen bool
+	// functionNames maps call ids to function names; Gemini wants both back.
+	functionNames map[string]string
+	turn          turnState
+	turnCount     int
+	usage         *realtime.Usage
+
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	// emitMu is held while sending an event and while closing events, so
+	// an event emitted from the caller's goroutine — by Interrupt — never
+	// lands on a closed channel.
+	emitMu       sync.Mutex
+	eventsClosed bool
+}
+
+// turnState tracks the model turn in progress.
+type turnState struct {
+	id          string
+	audio       bool
+	text        strings.Builder
+	interrupted bool
+}
+
+var _ realtime.Session = (*Session)(nil)
+
+// Dial opens a session and waits for the server to accept its setup.
+func Dial(ctx context.Context, opts *Options, cfg *realtime.SessionConfig) (*Session, error) {
+	wsURL, err := liveURL(opts.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := http.Header{}
+	headers.Set("x-goog-api-key", opts.ApiKey)
+	for k, v := range opts.Headers {
+		headers.Set(k, v)
+	}
+
+	conn, err := base.DialWebSocket(ctx, wsURL, headers)
+	if err != nil {
+		return nil, fmt.Errorf("gemini live: %w", err)
+	}
+
+	inputRate := defaultInputSampleRate
+	if cfg.InputAudio != nil && cfg.InputAudio.SampleRate > 0 {
+		inputRate = cfg.InputAudio.SampleRate
+	}
+
+	s := &Session{
+		conn:          conn,
+		events:        make(chan *realtime.Event, 64),
+		inputMimeType: "audio/pcm;rate=" + strconv.Itoa(inputRate),
+		manualTurns:   cfg.TurnDetection != nil && cfg.TurnDetection.Disabled,
+		functionNames: map[string]string{},
+		closed:        make(chan struct{}),
+	}
+
+	if err := s.setup(ctx, cfg); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("gemini live: %w", err)
+	}
+
+	go s.readLoop()
+
+	return s, nil
+}
+
+// liveURL derives the BidiGenerateContent endpoint from the REST base URL,
+// keeping its host and API version.
+func liveURL(baseURL string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("gemini live: invalid base url: %w", err)
+	}
+
+	version := path.Base(u.Path)
+	if version == "/" || version == "." {
+		version = "v1beta"
+	}
+	prefix := strings.TrimSuffix(path.Dir(u.Path), "/")
+	if prefix == "." {
+		prefix = ""
+	}
+
+	u.Path = prefix + "/ws/google.ai.generativelanguage." + version + ".GenerativeService.BidiGenerateContent"
+	return base.WebSocketURL(u.String()), nil
+}
+
+func (s *Session) setup(ctx context.Context, cfg *realtime.SessionConfig) error {
+	if err := s.conn.WriteJSON(ctx, map[string]any{"setup": setupFromConfig(cfg)}); err != nil {
+		return err
+	}
+
+	// Nothing may be sent until the server has accepted the setup.
+	done := make(chan error, 1)
+	go func() {
+		msg, err := s.conn.Read()
+		if err != nil {
+			done <- err
+			return
+		}
+		var ev serverMessage
+		if err := sonic.Unmarshal(msg, &ev); err != nil {
+			done <- err
+			return
+		}
+		if ev.SetupComplete == nil {
+			done <- errors.New("expected setupComplete, got " + string(msg))
+			return
+		}
+		done <- nil
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return err
+		}
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	s.events <- &realtime.Event{Type: realtime.EventSessionCreated}
+	return nil
+}
+
+func (s *Session) SendAudio(ctx context.Context, audio []byte) error {
+	if s.manualTurns {
+		if err := s.openActivity(ctx); err != nil {
+			return err
+		}
+	}
+
+	return s.send(ctx, map[string]any{
+		"realtimeInput": map[string]any{
+			"audio": map[string]any{
+				"data":     base64.StdEncoding.EncodeToString(audio),
+				"mimeType": s.inputMimeType,
+			},
+		},
+	})
+}
+
+func (s *Session) CommitAudio(ctx context.Context) error {
+	if !s.manualTurns {
+		// With VAD on, the server ends the turn itself; flushing tells it no
+		// more audio is coming for now.
+		return s.send(ctx, map[string]any{"realtimeInput": map[string]any{"audioStreamEnd": true}})
+	}
+
+	s.mu.Lock()
+	open := s.activityOpen
+	s.activityOpen = false
+	s.mu.Unlock()
+
+	if !open {
+		return nil
+	}
+	return s.send(ctx, map[string]any{"realtimeInput": map[string]any{"activityEnd": map[string]any{}}})
+}
+
+func (s *Session) openActivity(ctx context.Context) error {
+	s.mu.Lock()
+	open := s.activityOpen
+	s.activityOpen = true
+	s.mu.Unlock()
+
+	if open {
+		return nil
+	}
+	return s.send(ctx, map[string]any{"realtimeInput": map[string]any{"activityStart": map[string]any{}}})
+}
+
+func (s *Session) SendText(ctx context.Context, text string) error {
+	return s.send(ctx, map[string]any{
+		"clientContent": map[string]any{
+			"turns": []map[string]any{
+				{"role": "user", "parts": []map[string]any{{"text": text}}},
+			},
+			"turnComplete": true,
+		},
+	})
+}
+
+func (s *Session) SendFunctionResult(ctx context.Context, callID string, output string) error {
+	s.mu.Lock()
+	name := s.functionNames[callID]
+	delete(s.functionNames, callID)
+	s.mu.Unlock()
+
+	return s.send(ctx, map[string]any{
+		"toolResponse": map[string]any{
+			"functionResponses": []map[string]any{
+				{"id": callID, "name": name, "response": map[string]any{"output": output}},
+			},
+		},
+	})
+}
+
+// CreateResponse is a no-op: Gemini continues on its own once every function
+// call of a turn has a result.
+func (s *Session) CreateResponse(context.Context) error {
+	return nil
+}
+
+// Interrupt drops the rest of the current turn's output. With server VAD
+// off it also starts a user activity, which is how the Live API is told to
+// stop generating; with VAD on, the server stops when it hears the user.
+// audioPlayedMs is not used: Gemini does not keep unheard audio in context.
+func (s *Session) Interrupt(ctx context.Context, _ int) error {
+	s.mu.Lock()
+	turnID := s.turn.id
+	responding := turnID != "" && !s.turn.interrupted
+	s.turn.interrupted = true
+	s.mu.Unlock()
+
+	if responding {
+		s.emit(&realtime.Event{Type: realtime.EventInterrupted, ResponseID: turnID, ItemID: turnID})
+	}
+
+	if s.manualTurns {
+		return s.openActivity(ctx)
+	}
+	return nil
+}
+
+func (s *Session) Events() <-chan *realtime.Event {
+	return s.events
+}
+
+func (s *Session) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		err = s.conn.Close()
+	})
+	return err
+}
+
+func (s *Session) send(ctx context.Context, v any) error {
+	select {
+	case <-s.closed:
+		return realtime.ErrSessionClosed
+	default:
+	}
+	return s.conn.WriteJSON(ctx, v)
+}
+
+func (s *Session) emit(ev *realtime.Event) {
+	s.emitMu.Lock()
+	defer s.emitMu.Unlock()
+	if s.eventsClosed {
+		return
+	}
+	select {
+	case s.events <- ev:
+	case <-s.closed:
+	}
+}
+
+// closeEvents closes events once the session is closed, which unblocks any
+// emit waiting on a full channel.
+func (s *Session) closeEvents() {
+	s.emitMu.Lock()
+	defer s.emitMu.Unlock()
+	s.eventsClosed = true
+	close(s.events)
+}
+
+func (s *Session) readLoop() {
+	defer s.closeEvents()
+	defer s.Close()
+
+	for {
+		msg, err := s.conn.Read()
+		if err != nil {
+			select {
+			case <-s.closed:
+			default:
+				s.emit(&realtime.Event{Type: realtime.EventError, Error: &realtime.Error{Code: "connection_closed", Message: err.Error()}})
+			}
+			return
+		}
+
+		var ev serverMessage
+		if err := sonic.Unmarshal(msg, &ev); err != nil {
+			slog.Warn("gemini live: unreadable server message", slog.Any("error", err))
+			continue
+		}
+
+		for _, out := range s.translate(&ev) {
+			s.emit(out)
+		}
+	}
+}
+
+// translate maps one server message onto the SDK's event model. A single
+// Live message can carry several things at once — audio, transcripts and
+// the end of the turn — so it may yield several events.
+func (s *Session) translate(msg *serverMessage) []*realtime.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []*realtime.Event
+
+	if u := msg.UsageMetadata; u != nil {
+		s.usage = &realtime.Usage{
+			InputTokens:  u.PromptTokenCount,
+			OutputTokens: u.ResponseTokenCount,
+			TotalTokens:  u.TotalTokenCount,
+		}
+	}
+
+	if tc := msg.ToolCall; tc != nil {
+		turnID := s.startTurn()
+		for _, fc := range tc.FunctionCalls {
+			callID := fc.ID
+			if callID == "" {
+				callID = fc.Name
+			}
+			s.functionNames[callID] = fc.Name
+
+			args := "{}"
+			if len(fc.Args) > 0 {
+				args = string(fc.Args)
+			}
+			out = append(out, &realtime.Event{
+				Type:         realtime.EventFunctionCall,
+				ResponseID:   turnID,
+				ItemID:       callID,
+				FunctionCall: &realtime.FunctionCall{CallID: callID, Name: fc.Name, Arguments: args},
+			})
+		}
+	}
+
+	if msg.GoAway != nil {
+		out = append(out, &realtime.Event{
+			Type:  realtime.EventError,
+			Error: &realtime.Error{Code: "go_away", Message: "server is ending the session in " + msg.GoAway.TimeLeft},
+		})
+	}
+
+	sc := msg.ServerContent
+	if sc == nil {
+		return out
+	}
+
+	if sc.InputTranscription != nil && sc.InputTranscription.Text != "" {
+		out = append(out, &realtime.Event{Type: realtime.EventInputTranscript, Text: sc.InputTranscription.Text})
+	}
+
+	if sc.Interrupted {
+		// The user talked over the model; the server has stopped generating.
+		if s.turn.id != "" && !s.turn.interrupted {
+			out = append(out, &realtime.Event{Type: realtime.EventInterrupted, ResponseID: s.turn.id, ItemID: s.turn.id})
+		}
+		s.turn.interrupted = true
+	}
+
+	if sc.ModelTurn != nil {
+		turnID := s.startTurn()
+		for _, part := range sc.ModelTurn.Parts {
+			if s.turn.interrupted || part.Thought {
+				continue
+			}
+			switch {
+			case part.InlineData != nil && strings.HasPrefix(part.InlineData.MimeType, "audio/"):
+				audio, err := base64.StdEncoding.DecodeString(part.InlineData.Data)
+				if err != nil {
+					out = append(out, &realtime.Event{Type: realtime.EventError, Error: &realtime.Error{Code: "invalid_audio", Message: err.Error()}})
+					continue
+				}
+				s.turn.audio = true
+				out = append(out, &realtime.Event{Type: realtime.EventAudioDelta, ResponseID: turnID, ItemID: turnID, Audio: audio})
+			case part.Text != "":
+				s.turn.text.WriteString(part.Text)
+				out = append(out, &realtime.Event{Type: realtime.EventTextDelta, ResponseID: turnID, ItemID: turnID, Text: part.Text})
+			}
+		}
+	}
+
+	if sc.OutputTranscription != nil && sc.OutputTranscription.Text != "" && !s.turn.interrupted {
+		turnID := s.startTurn()
+		out = append(out, &realtime.Event{Type: realtime.EventOutputTranscriptDelta, ResponseID: turnID, ItemID: turnID, Text: sc.OutputTranscription.Text})
+	}
+
+	if sc.TurnComplete && s.turn.id != "" {
+		turn := &s.turn
+		if !turn.interrupted {
+			if turn.audio {
+				out = append(out, &realtime.Event{Type: realtime.EventAudioDone, ResponseID: turn.id, ItemID: turn.id})
+			}
+			if turn.text.Len() > 0 {
+				out = append(out, &realtime.Event{Type: realtime.EventTextDone, ResponseID: turn.id, ItemID: turn.id, Text: turn.text.String()})
+			}
+		}
+		out = append(out, &realtime.Event{Type: realtime.EventResponseDone, ResponseID: turn.id, Usage: s.usage})
+		s.turn = turnState{}
+		s.usage = nil
+	}
+
+	return out
+}
+
+// startTurn returns the id of the model turn in progress, starting one if
+// needed.
+func (s *Session) startTurn() string {
+	if s.turn.id == "" {
+		s.turnCount++
+		s.turn = turnState{id: "turn_" + strconv.Itoa(s.turnCount)}
+	}
+	return s.turn.id
+}
+
+type serverMessage struct {
+	SetupComplete *struct{} `json:"setupComplete"`
+
+	ServerContent *struct {
+		ModelTurn *struct {
+			Parts []struct {
+				Text       string `json:"text"`
+				Thought    bool   `json:"thought"`
+				InlineData *struct {
+					MimeType string `json:"mimeType"`
+					Data     string `json:"data"`
+				} `json:"inlineData"`
+			} `json:"parts"`
+		} `json:"modelTurn"`
+		TurnComplete        bool           `json:"turnComplete"`
+		Interrupted         bool           `json:"interrupted"`
+		InputTranscription  *transcription `json:"inputTranscription"`
+		OutputTranscription *transcription `json:"outputTranscription"`
+	} `json:"serverContent"`
+
+	ToolCall *struct {
+		FunctionCalls []struct {
+			ID   string          `json:"id"`
+			Name string          `json:"name"`
+			Args json.RawMessage `json:"args"`
+		} `json:"functionCalls"`
+	} `json:"toolCall"`
+
+	UsageMetadata *struct {
+		PromptTokenCount   int64 `json:"promptTokenCount"`
+		ResponseTokenCount int64 `json:"responseTokenCount"`
+		TotalTokenCount    int64 `json:"totalTokenCount"`
+	} `json:"usageMetadata"`
+
+	GoAway *struct {
+		TimeLeft string `json:"timeLeft"`
+	} `json:"goAway"`
+}
+
+type transcription struct {
+	Text string `json:"text"`
+}
+
+type setup struct {
+	Model                    string               `json:"model"`
+	GenerationConfig         generationConfig     `json:"generationConfig"`
+	SystemInstruction        *content             `json:"systemInstruction,omitempty"`
+	Tools                    []tool               `json:"tools,omitempty"`
+	RealtimeInputConfig      *realtimeInputConfig `json:"realtimeInputConfig,omitempty"`
+	InputAudioTranscription  *struct{}            `json:"inputAudioTranscription,omitempty"`
+	OutputAudioTranscription *struct{}            `json:"outputAudioTranscription,omitempty"`
+}
+
+type generationConfig struct {
+	ResponseModalities []string      `json:"responseModalities"`
+	SpeechConfig       *speechConfig `json:"speechConfig,omitempty"`
+	Temperature        *float64      `json:"temperature,omitempty"`
+}
+
+type speechConfig struct {
+	VoiceConfig struct {
+		PrebuiltVoiceConfig struct {
+			VoiceName string `json:"voiceName"`
+		} `json:"prebuiltVoiceConfig"`
+	} `json:"voiceConfig"`
+}
+
+type content struct {
+	Parts []part `json:"parts"`
+}
+
+type part struct {
+	Text string `json:"text"`
+}
+
+type tool struct {
+	FunctionDeclarations []functionDeclaration `json:"functionDeclarations"`
+}
+
+type functionDeclaration struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+}
+
+type realtimeInputConfig struct {
+	AutomaticActivityDetection automaticActivityDetection `json:"automaticActivityDetection"`
+}
+
+type automaticActivityDetection struct {
+	Disabled          bool `json:"disabled,omitempty"`
+	PrefixPaddingMs   *int `json:"prefixPaddingMs,omitempty"`
+	SilenceDurationMs *int `json:"silenceDurationMs,omitempty"`
+}
+
+func setupFromConfig(cfg *realtime.SessionConfig) *setup {
+	model := cfg.Model
+	if !strings.HasPrefix(model, "models/") {
+		model = "models/" + model
+	}
+
+	out := &setup{
+		Model: model,
+		GenerationConfig: generationConfig{
+			Temperature: cfg.Temperature,
+		},
+	}
+
+	modalities := cfg.Modalities
+	if len(modalities) == 0 {
+		modalities = []realtime.Modality{realtime.ModalityAudio}
+	}
+	for _, m := range modalities {
+		out.GenerationConfig.ResponseModalities = append(out.GenerationConfig.ResponseModalities, strings.ToUpper(string(m)))
+		if m == realtime.ModalityAudio {
+			out.OutputAudioTranscription = &struct{}{}
+		}
+	}
+
+	if cfg.Voice != "" {
+		sc := &speechConfig{}
+		sc.VoiceConfig.PrebuiltVoiceConfig.VoiceName = cfg.Voice
+		out.GenerationConfig.SpeechConfig = sc
+	}
+
+	if cfg.Instructions != "" {
+		out.SystemInstruction = &content{Parts: []part{{Text: cfg.Instructions}}}
+	}
+
+	if len(cfg.Tools) > 0 {
+		decls := make([]functionDeclaration, len(cfg.Tools))
+		for i, t := range cfg.Tools {
+			decls[i] = functionDeclaration{Name: t.Name, Description: t.Description, Parameters: t.Parameters}
+		}
+		out.Tools = []tool{{FunctionDeclarations: decls}}
+	}
+
+	if td := cfg.TurnDetection; td != nil {
+		out.RealtimeInputConfig = &realtimeInputConfig{
+			AutomaticActivityDetection: automaticActivityDetection{
+				Disabled:          td.Disabled,
+				PrefixPaddingMs:   td.PrefixPaddingMs,
+				SilenceDurationMs: td.SilenceDurationMs,
+			},
+		}
+	}
+
+	if cfg.InputTranscription != nil {
+		out.InputAudioTranscription = &struct{}{}
+	}
+
+	return out
+}
@@ -0,0 +1,202 @@
+package gemini_realtime
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/realtime"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/realtime/realtimetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dialStandIn opens a session, answering its setup the way the Live API
+// does, and returns the setup it sent.
+func dialStandIn(t *testing.T, cfg *realtime.SessionConfig) (*Session, *realtimetest.Conn, map[string]any) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	srv := realtimetest.NewServer()
+	t.Cleanup(srv.Close)
+
+	type dialed struct {
+		session *Session
+		err     error
+	}
+	done := make(chan dialed, 1)
+	go func() {
+		s, err := Dial(ctx, &Options{BaseURL: srv.URL + "/v1beta", ApiKey: "test-key"}, cfg)
+		done <- dialed{s, err}
+	}()
+
+	conn, err := srv.Accept(ctx)
+	require.NoError(t, err)
+	setup, err := conn.ReceiveType(ctx, "setup", "")
+	require.NoError(t, err)
+	require.NoError(t, conn.Send(map[string]any{"setupComplete": map[string]any{}}))
+
+	d := <-done
+	require.NoError(t, d.err)
+	t.Cleanup(func() { _ = d.session.Close() })
+
+	assert.Equal(t, "/ws/google.ai.generativelanguage.v1beta.GenerativeService.BidiGenerateContent", conn.Request.URL.Path)
+	assert.Equal(t, "test-key", conn.Request.Header.Get("x-goog-api-key"))
+
+	return d.session, conn, setup["setup"].(map[string]any)
+}
+
+func nextEvent(t *testing.T, s realtime.Session) *realtime.Event {
+	t.Helper()
+	select {
+	case ev, ok := <-s.Events():
+		require.True(t, ok, "events closed")
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event")
+		return nil
+	}
+}
+
+func TestSession_SetupAndTurn(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	session, conn, setup := dialStandIn(t, &realtime.SessionConfig{
+		Model:        "gemini-live-2.5-flash-preview",
+		Instructions: "Be brief.",
+		Voice:        "Puck",
+		Tools:        []realtime.Tool{{Name: "get_weather", Description: "Weather by city"}},
+	})
+
+	assert.Equal(t, "models/gemini-live-2.5-flash-preview", setup["model"])
+	gen := setup["generationConfig"].(map[string]any)
+	assert.Equal(t, []any{"AUDIO"}, gen["responseModalities"])
+	assert.Equal(t, "Puck", gen["speechConfig"].(map[string]any)["voiceConfig"].(map[string]any)["prebuiltVoiceConfig"].(map[string]any)["voiceName"])
+	decls := setup["tools"].([]any)[0].(map[string]any)["functionDeclarations"].([]any)
+	assert.Equal(t, "get_weather", decls[0].(map[string]any)["name"])
+
+	assert.Equal(t, realtime.EventSessionCreated, nextEvent(t, session).Type)
+
+	require.NoError(t, session.SendAudio(ctx, []byte{1, 2}))
+	input, err := conn.ReceiveType(ctx, "realtimeInput", "")
+	require.NoError(t, err)
+	audio := input["realtimeInput"].(map[string]any)["audio"].(map[string]any)
+	assert.Equal(t, "audio/pcm;rate=16000", audio["mimeType"])
+
+	pcm := base64.StdEncoding.EncodeToString([]byte("pcm"))
+	require.NoError(t, conn.Send(map[string]any{"toolCall": map[string]any{
+		"functionCalls": []any{map[string]any{"id": "fc_1", "name": "get_weather", "args": map[string]any{"city": "Paris"}}},
+	}}))
+	ev := nextEvent(t, session)
+	assert.Equal(t, realtime.EventFunctionCall, ev.Type)
+	assert.Equal(t, "fc_1", ev.FunctionCall.CallID)
+	assert.JSONEq(t, `{"city":"Paris"}`, ev.FunctionCall.Arguments)
+
+	require.NoError(t, session.SendFunctionResult(ctx, "fc_1", "18C"))
+	resp, err := conn.ReceiveType(ctx, "toolResponse", "")
+	require.NoError(t, err)
+	fr := resp["toolResponse"].(map[string]any)["functionResponses"].([]any)[0].(map[string]any)
+	assert.Equal(t, "get_weather", fr["name"], "the call's name is sent back with its id")
+	assert.Equal(t, map[string]any{"output": "18C"}, fr["response"])
+
+	require.NoError(t, conn.Send(map[string]any{"serverContent": map[string]any{
+		"modelTurn":           map[string]any{"parts": []any{map[string]any{"inlineData": map[string]any{"mimeType": "audio/pcm;rate=24000", "data": pcm}}}},
+		"outputTranscription": map[string]any{"text": "It is 18C."},
+	}}))
+	require.NoError(t, conn.Send(map[string]any{"usageMetadata": map[string]any{"promptTokenCount": 7, "responseTokenCount": 3, "totalTokenCount": 10}}))
+	require.NoError(t, conn.Send(map[string]any{"serverContent": map[string]any{"turnComplete": true}}))
+
+	ev = nextEvent(t, session)
+	assert.Equal(t, realtime.EventAudioDelta, ev.Type)
+	assert.Equal(t, []byte("pcm"), ev.Audio)
+	turnID := ev.ResponseID
+	assert.Equal(t, realtime.EventOutputTranscriptDelta, nextEvent(t, session).Type)
+	assert.Equal(t, realtime.EventAudioDone, nextEvent(t, session).Type)
+	done := nextEvent(t, session)
+	assert.Equal(t, realtime.EventResponseDone, done.Type)
+	assert.Equal(t, turnID, done.ResponseID, "the tool call and the answer are one turn")
+	assert.Equal(t, &realtime.Usage{InputTokens: 7, OutputTokens: 3, TotalTokens: 10}, done.Usage)
+}
+
+func TestSession_Interruption(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	session, conn, setup := dialStandIn(t, &realtime.SessionConfig{
+		Model:         "gemini-live-2.5-flash-preview",
+		TurnDetection: &realtime.TurnDetection{Disabled: true},
+	})
+	assert.Equal(t, true, setup["realtimeInputConfig"].(map[string]any)["automaticActivityDetection"].(map[string]any)["disabled"])
+	assert.Equal(t, realtime.EventSessionCreated, nextEvent(t, session).Type)
+
+	pcm := base64.StdEncoding.EncodeToString([]byte("pcm"))
+	audioTurn := map[string]any{"serverContent": map[string]any{
+		"modelTurn": map[string]any{"parts": []any{map[string]any{"inlineData": map[string]any{"mimeType": "audio/pcm;rate=24000", "data": pcm}}}},
+	}}
+
+	// Server-side barge-in.
+	require.NoError(t, conn.Send(audioTurn))
+	require.NoError(t, conn.Send(map[string]any{"serverContent": map[string]any{"interrupted": true}}))
+	require.NoError(t, conn.Send(audioTurn))
+	require.NoError(t, conn.Send(map[string]any{"serverContent": map[string]any{"turnComplete": true}}))
+
+	assert.Equal(t, realtime.EventAudioDelta, nextEvent(t, session).Type)
+	assert.Equal(t, realtime.EventInterrupted, nextEvent(t, session).Type)
+	assert.Equal(t, realtime.EventResponseDone, nextEvent(t, session).Type, "audio after the interruption is dropped")
+
+	// Client-side interruption with manual turns opens a user activity.
+	require.NoError(t, conn.Send(audioTurn))
+	assert.Equal(t, realtime.EventAudioDelta, nextEvent(t, session).Type)
+	require.NoError(t, session.Interrupt(ctx, 0))
+	assert.Equal(t, realtime.EventInterrupted, nextEvent(t, session).Type)
+	msg, err := conn.ReceiveType(ctx, "realtimeInput", "")
+	require.NoError(t, err)
+	assert.Contains(t, msg["realtimeInput"], "activityStart")
+
+	require.NoError(t, session.CommitAudio(ctx))
+	msg, err = conn.ReceiveType(ctx, "realtimeInput", "")
+	require.NoError(t, err)
+	assert.Contains(t, msg["realtimeInput"], "activityEnd")
+}
+
+// Interrupt emits from the caller's goroutine; once the socket has dropped
+// and events is closed, it must not send on it.
+func TestSession_InterruptAfterDisconnect(t *testing.T) {
+	session, conn, _ := dialStandIn(t, &realtime.SessionConfig{Model: "gemini-live-2.5-flash-preview"})
+	assert.Equal(t, realtime.EventSessionCreated, nextEvent(t, session).Type)
+
+	pcm := base64.StdEncoding.EncodeToString([]byte("pcm"))
+	require.NoError(t, conn.Send(map[string]any{"serverContent": map[string]any{
+		"modelTurn": map[string]any{"parts": []any{map[string]any{"inlineData": map[string]any{"mimeType": "audio/pcm;rate=24000", "data": pcm}}}},
+	}}))
+	assert.Equal(t, realtime.EventAudioDelta, nextEvent(t, session).Type)
+
+	require.NoError(t, conn.Close())
+	drainEvents(t, session)
+
+	// Each attempt finds the turn still responding, so each emits; the send
+	// would race the closed channel rather than always lose to it.
+	for range 20 {
+		assert.NotPanics(t, func() { _ = session.Interrupt(context.Background(), 0) })
+		session.mu.Lock()
+		session.turn.interrupted = false
+		session.mu.Unlock()
+	}
+}
+
+func drainEvents(t *testing.T, s realtime.Session) {
+	t.Helper()
+	for {
+		select {
+		case _, ok := <-s.Events():
+			if !ok {
+				return
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("events not closed")
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/bytedance/sonic"
 	"github.com/google/uuid"
@@ -25,7 +26,7 @@ func (in *Request) ToNativeRequest() *responses2.Request {
 			ParallelToolCalls: nil,
 			Store:             nil,
 			Temperature:       in.GenerationConfig.Temperature,
-			TopLogprobs:       in.GenerationConfig.TopK,
+			TopLogprobs:       in.GenerationConfig.Logprobs,
 			TopP:              in.GenerationConfig.TopP,
 			Include:           nil,
 			Metadata:          nil,
@@ -48,6 +49,10 @@ func (in *Request) ToNativeRequest() *responses2.Request {
 		includables = append(includables, responses2.IncludableReasoningEncryptedContent)
 	}
 
+	if in.GenerationConfig.ResponseLogprobs != nil && *in.GenerationConfig.ResponseLogprobs {
+		includables = append(includables, responses2.IncludableMessageOutputTextLogprobs)
+	}
+
 	if len(includables) > 0 {
 		out.Include = includables
 	}
@@ -111,6 +116,24 @@ func (in *Tool) ToNative() []responses2.ToolUnion {
 		})
 	}
 
+	if in.GoogleSearch != nil {
+		out = append(out, responses2.ToolUnion{
+			OfWebSearch: &responses2.WebSearchTool{},
+		})
+	}
+
+	if in.URLContext != nil {
+		out = append(out, responses2.ToolUnion{
+			OfURLContext: &responses2.URLContextTool{},
+		})
+	}
+
+	if in.GoogleMaps != nil {
+		out = append(out, responses2.ToolUnion{
+			OfGoogleMaps: &responses2.GoogleMapsTool{EnableWidget: in.GoogleMaps.EnableWidget},
+		})
+	}
+
 	return out
 }
 
@@ -226,8 +249,12 @@ func (in *Response) ToNativeResponse() *responses2.Response {
 		}
 	}
 
+	// Gemini reports logprobs for the candidate, not per part; they go on
+	// its first text output.
+	logprobs := in.Candidates[0].LogprobsResult.ToNative()
+
 	var previousExecutableCodePart *ExecutableCodePart
-	for _, part := range in.Candidates[0].Content.Parts {
+	for i, part := range in.Candidates[0].Content.Parts {
 		if part.Text != nil {
 			output = append(output, responses2.OutputMessageUnion{
 				OfOutputMessage: &responses2.OutputMessage{
@@ -235,12 +262,15 @@ func (in *Response) ToNativeResponse() *responses2.Response {
 					Content: &responses2.OutputContent{
 						{
 							OfOutputText: &responses2.OutputTextContent{
-								Text: *part.Text,
+								Text:        *part.Text,
+								Annotations: in.Candidates[0].GroundingMetadata.ToNativeAnnotations(*part.Text, utils.Ptr(i)),
+								Logprobs:    logprobs,
 							},
 						},
 					},
 				},
 			})
+			logprobs = nil
 		}
 
 		if part.FunctionCall != nil {
@@ -280,6 +310,15 @@ func (in *Response) ToNativeResponse() *responses2.Response {
 		}
 	}
 
+	output = append(output, in.Candidates[0].hostedToolCalls()...)
+
+	metadata := map[string]any{
+		"stop_reason": in.Candidates[0].FinishReason,
+	}
+	if in.Candidates[0].AvgLogprobs != nil {
+		metadata["avg_logprobs"] = *in.Candidates[0].AvgLogprobs
+	}
+
 	return &responses2.Response{
 		ID:          in.ResponseID,
 		Model:       in.ModelVersion,
@@ -287,10 +326,132 @@ func (in *Response) ToNativeResponse() *responses2.Response {
 		Usage:       utils.Ptr(nativeUsage(in.UsageMetadata)),
 		Error:       nil,
 		ServiceTier: "",
-		Metadata: map[string]any{
-			"stop_reason": in.Candidates[0].FinishReason,
-		},
+		Metadata:    metadata,
+	}
+}
+
+// hostedToolCalls reports the urlContext and googleMaps tool use, which
+// Gemini gives as candidate metadata rather than as parts.
+func (in *Candidate) hostedToolCalls() []responses2.OutputMessageUnion {
+	var out []responses2.OutputMessageUnion
+
+	if in.URLContextMetadata != nil && len(in.URLContextMetadata.URLMetadata) > 0 {
+		call := &responses2.URLContextCallMessage{
+			ID:     uuid.NewString(),
+			Status: "completed",
+		}
+		for _, m := range in.URLContextMetadata.URLMetadata {
+			call.URLs = append(call.URLs, responses2.URLContextCallURL{URL: m.RetrievedURL, Status: m.URLRetrievalStatus})
+		}
+		out = append(out, responses2.OutputMessageUnion{OfURLContextCall: call})
+	}
+
+	if in.GroundingMetadata != nil {
+		call := &responses2.GoogleMapsCallMessage{
+			ID:                 uuid.NewString(),
+			Status:             "completed",
+			WidgetContextToken: in.GroundingMetadata.GoogleMapsWidgetContextToken,
+		}
+		for _, chunk := range in.GroundingMetadata.GroundingChunks {
+			if chunk.Maps != nil {
+				call.Places = append(call.Places, responses2.GoogleMapsCallPlace{
+					PlaceID: chunk.Maps.PlaceID,
+					Title:   chunk.Maps.Title,
+					URL:     chunk.Maps.URI,
+				})
+			}
+		}
+		if len(call.Places) > 0 {
+			out = append(out, responses2.OutputMessageUnion{OfGoogleMapsCall: call})
+		}
+	}
+
+	return out
+}
+
+// ToNativeAnnotations converts the grounding supports of a text part into
+// annotations, one for each chunk a segment is grounded in. With partIndex
+// nil, the supports of every part are taken as ranges of text.
+func (m *GroundingMetadata) ToNativeAnnotations(text string, partIndex *int) []responses2.Annotation {
+	annotations := []responses2.Annotation{}
+	if m == nil {
+		return annotations
+	}
+
+	for _, support := range m.GroundingSupports {
+		if partIndex != nil && support.Segment.PartIndex != *partIndex {
+			continue
+		}
+
+		start := byteOffsetToCharIndex(text, support.Segment.StartIndex)
+		end := byteOffsetToCharIndex(text, support.Segment.EndIndex)
+		for _, idx := range support.GroundingChunkIndices {
+			if idx < 0 || idx >= len(m.GroundingChunks) {
+				continue
+			}
+
+			chunk := m.GroundingChunks[idx]
+			annotation := responses2.Annotation{
+				Type:       "url_citation",
+				StartIndex: start,
+				EndIndex:   end,
+				ExtraParams: map[string]any{
+					"Gemini": chunk,
+				},
+			}
+
+			switch {
+			case chunk.Web != nil:
+				annotation.URL = chunk.Web.URI
+				annotation.Title = chunk.Web.Title
+			case chunk.Maps != nil:
+				annotation.URL = chunk.Maps.URI
+				annotation.Title = chunk.Maps.Title
+			case chunk.RetrievedContext != nil:
+				annotation.Type = "file_citation"
+				annotation.URL = chunk.RetrievedContext.URI
+				annotation.Title = chunk.RetrievedContext.Title
+				annotation.Filename = chunk.RetrievedContext.Title
+				annotation.Snippet = chunk.RetrievedContext.Text
+			default:
+				continue
+			}
+
+			annotations = append(annotations, annotation)
+		}
+	}
+
+	return annotations
+}
+
+// byteOffsetToCharIndex converts a byte offset into text, as Gemini gives
+// them, into the character index annotations use.
+func byteOffsetToCharIndex(text string, offset int) int {
+	if offset > len(text) {
+		offset = len(text)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return utf8.RuneCountInString(text[:offset])
+}
+
+// ToNative pairs each chosen token with the top candidates at its step.
+func (r *LogprobsResult) ToNative() []responses2.Logprob {
+	if r == nil || len(r.ChosenCandidates) == 0 {
+		return nil
+	}
+
+	out := make([]responses2.Logprob, len(r.ChosenCandidates))
+	for i, chosen := range r.ChosenCandidates {
+		out[i] = responses2.Logprob{Token: chosen.Token, Logprob: chosen.LogProbability}
+		if i < len(r.TopCandidates) {
+			for _, top := range r.TopCandidates[i].Candidates {
+				out[i].TopLogprobs = append(out[i].TopLogprobs, responses2.TopLogprob{Token: top.Token, Logprob: top.LogProbability})
+			}
+		}
 	}
+	return out
 }
 
 // nativeUsage normalizes Gemini's token accounting onto the native Usage
@@ -335,6 +496,7 @@ type ResponseChunkToNativeResponseChunkConverter struct {
 	currentBlock     *Part
 	outputItemActive bool
 	outputItemID     string
+	callID           string
 	outputIndex      int
 	contentIndex     int
 
@@ -342,9 +504,16 @@ type ResponseChunkToNativeResponseChunkConverter struct {
 	previousPart *Part
 
 	// Accumulation
-	accumulatedData  string
+	accumulatedData     string
+	accumulatedLogprobs []responses2.Logprob
+
+	// pendingLogprobs are the current chunk's, waiting for its text part.
+	pendingLogprobs  []responses2.Logprob
 	completedOutputs []responses2.OutputMessageUnion
 
+	// lastCandidate holds the tool metadata, reported once the stream ends.
+	lastCandidate *Candidate
+
 	// Message-level state
 	sequenceNumber int
 	messageID      string
@@ -409,6 +578,10 @@ func (c *ResponseChunkToNativeResponseChunkConverter) ResponseChunkToNativeRespo
 	// Process all parts in this chunk. A chunk may carry no candidate
 	// (e.g. it only updates usage or prompt feedback), so guard the access.
 	if len(in.Candidates) > 0 {
+		c.pendingLogprobs = in.Candidates[0].LogprobsResult.ToNative()
+		if in.Candidates[0].URLContextMetadata != nil || in.Candidates[0].GroundingMetadata != nil {
+			c.lastCandidate = &in.Candidates[0]
+		}
 		for i := range in.Candidates[0].Content.Parts {
 			part := &in.Candidates[0].Content.Parts[i]
 			out = append(out, c.handlePart(part)...)
@@ -440,6 +613,10 @@ func (c *ResponseChunkToNativeResponseChunkConverter) handleStreamEnd() []*respo
 		out = append(out, c.completeCurrentPart()...)
 	}
 
+	if c.lastCandidate != nil {
+		out = append(out, c.completeHostedToolCalls(c.lastCandidate)...)
+	}
+
 	// Emit response.completed
 	out = append(out, c.buildResponseCompleted())
 	c.streamEnded = true
@@ -464,6 +641,7 @@ func (c *ResponseChunkToNativeResponseChunkConverter) handlePart(part *Part) []*
 		out = append(out, c.completeCurrentPart()...)
 		c.outputItemActive = false
 		c.accumulatedData = ""
+		c.accumulatedLogprobs = nil
 	}
 
 	// Store current block for later reference (used in completion)
@@ -505,6 +683,11 @@ func (c *ResponseChunkToNativeResponseChunkConverter) shouldEndPreviousPart(part
 	if c.previousPart == nil {
 		return false
 	}
+	// A function call arrives whole, never split across parts, so the next
+	// one is a call of its own.
+	if c.previousPart.FunctionCall != nil {
+		return true
+	}
 	return c.getPartType(c.previousPart) != c.getPartType(part)
 }
 
@@ -556,8 +739,11 @@ func (c *ResponseChunkToNativeResponseChunkConverter) handleTextPart(part *Part)
 	}
 
 	// Emit delta
-	out = append(out, c.buildOutputTextDelta(*part.Text))
+	logprobs := c.pendingLogprobs
+	c.pendingLogprobs = nil
+	out = append(out, c.buildOutputTextDelta(*part.Text, logprobs))
 	c.accumulatedData += *part.Text
+	c.accumulatedLogprobs = append(c.accumulatedLogprobs, logprobs...)
 
 	return out
 }
@@ -565,22 +751,34 @@ func (c *ResponseChunkToNativeResponseChunkConverter) handleTextPart(part *Part)
 func (c *ResponseChunkToNativeResponseChunkConverter) completeTextPart() []*responses2.ResponseChunk {
 	text := c.accumulatedData
 
+	// The grounding metadata comes with the last chunks, and its segments
+	// are ranges of the text streamed so far.
+	annotations := []responses2.Annotation{}
+	if c.lastCandidate != nil {
+		annotations = c.lastCandidate.GroundingMetadata.ToNativeAnnotations(text, nil)
+	}
+
 	// Store completed output for final response
 	c.completedOutputs = append(c.completedOutputs, responses2.OutputMessageUnion{
 		OfOutputMessage: &responses2.OutputMessage{
 			ID:   c.outputItemID,
 			Role: RoleModel.ToNativeRole(),
 			Content: &responses2.OutputContent{
-				{OfOutputText: &responses2.OutputTextContent{Text: text}},
+				{OfOutputText: &responses2.OutputTextContent{Text: text, Annotations: annotations, Logprobs: c.accumulatedLogprobs}},
 			},
 		},
 	})
 
-	return []*responses2.ResponseChunk{
-		c.buildOutputTextDone(text),
-		c.buildContentPartDoneText(text),
-		c.buildOutputItemDoneMessage(text),
+	var out []*responses2.ResponseChunk
+	for i := range annotations {
+		out = append(out, c.buildOutputTextAnnotationAdded(i, annotations[i]))
 	}
+
+	return append(out,
+		c.buildOutputTextDone(text),
+		c.buildContentPartDoneText(text, annotations),
+		c.buildOutputItemDoneMessage(text, annotations),
+	)
 }
 
 // =============================================================================
@@ -598,8 +796,8 @@ func (c *ResponseChunkToNativeResponseChunkConverter) handleFunctionCallPart(par
 
 	// Emit start events if this is a new output item
 	if !c.outputItemActive {
-		callID := uuid.NewString() + "_" + part.FunctionCall.Name
-		out = append(out, c.buildOutputItemAddedFunctionCall(callID, part.FunctionCall.Name, argsStr, part.ThoughtSignature))
+		c.callID = uuid.NewString() + "_" + part.FunctionCall.Name
+		out = append(out, c.buildOutputItemAddedFunctionCall(c.callID, part.FunctionCall.Name, argsStr, part.ThoughtSignature))
 	}
 
 	// Emit delta
@@ -615,7 +813,7 @@ func (c *ResponseChunkToNativeResponseChunkConverter) completeFunctionCallPart()
 		args = "{}"
 	}
 
-	callID := uuid.NewString()
+	callID := c.callID
 	fnName := c.currentBlock.FunctionCall.Name
 
 	// Store completed output for final response
@@ -772,10 +970,63 @@ func (c *ResponseChunkToNativeResponseChunkConverter) completeCodeExecutionResul
 	return out
 }
 
+// =============================================================================
+// URL Context and Google Maps Handling
+// =============================================================================
+
+func (c *ResponseChunkToNativeResponseChunkConverter) completeHostedToolCalls(candidate *Candidate) []*responses2.ResponseChunk {
+	var out []*responses2.ResponseChunk
+
+	for _, call := range candidate.hostedToolCalls() {
+		c.completedOutputs = append(c.completedOutputs, call)
+
+		item := responses2.ChunkOutputItemData{Status: "completed"}
+		switch {
+		case call.OfURLContextCall != nil:
+			item.Type = "url_context_call"
+			item.Id = call.OfURLContextCall.ID
+			item.URLs = call.OfURLContextCall.URLs
+		case call.OfGoogleMapsCall != nil:
+			item.Type = "google_maps_call"
+			item.Id = call.OfGoogleMapsCall.ID
+			item.Places = call.OfGoogleMapsCall.Places
+			item.WidgetContextToken = call.OfGoogleMapsCall.WidgetContextToken
+		}
+
+		out = append(out, c.buildOutputItemAdded(item), c.buildOutputItemDone(item))
+	}
+
+	return out
+}
+
 // =============================================================================
 // Chunk Builders
 // =============================================================================
 
+func (c *ResponseChunkToNativeResponseChunkConverter) buildOutputItemAdded(item responses2.ChunkOutputItemData) *responses2.ResponseChunk {
+	added := item
+	added.Status = "in_progress"
+	return &responses2.ResponseChunk{
+		OfOutputItemAdded: &responses2.ChunkOutputItem[constants.ChunkTypeOutputItemAdded]{
+			Type:           constants.ChunkTypeOutputItemAdded(""),
+			SequenceNumber: c.nextSeqNum(),
+			OutputIndex:    c.outputIndex,
+			Item:           added,
+		},
+	}
+}
+
+func (c *ResponseChunkToNativeResponseChunkConverter) buildOutputItemDone(item responses2.ChunkOutputItemData) *responses2.ResponseChunk {
+	return &responses2.ResponseChunk{
+		OfOutputItemDone: &responses2.ChunkOutputItem[constants.ChunkTypeOutputItemDone]{
+			Type:           constants.ChunkTypeOutputItemDone(""),
+			SequenceNumber: c.nextSeqNum(),
+			OutputIndex:    c.outputIndex,
+			Item:           item,
+		},
+	}
+}
+
 func (c *ResponseChunkToNativeResponseChunkConverter) buildResponseCreated(id, model string) *responses2.ResponseChunk {
 	return &responses2.ResponseChunk{
 		OfResponseCreated: &responses2.ChunkResponse[constants.ChunkTypeResponseCreated]{
@@ -881,7 +1132,7 @@ func (c *ResponseChunkToNativeResponseChunkConverter) buildContentPartAddedText(
 	}
 }
 
-func (c *ResponseChunkToNativeResponseChunkConverter) buildOutputTextDelta(delta string) *responses2.ResponseChunk {
+func (c *ResponseChunkToNativeResponseChunkConverter) buildOutputTextDelta(delta string, logprobs []responses2.Logprob) *responses2.ResponseChunk {
 	return &responses2.ResponseChunk{
 		OfOutputTextDelta: &responses2.ChunkOutputText[constants.ChunkTypeOutputTextDelta]{
 			Type:           constants.ChunkTypeOutputTextDelta(""),
@@ -890,6 +1141,7 @@ func (c *ResponseChunkToNativeResponseChunkConverter) buildOutputTextDelta(delta
 			OutputIndex:    c.outputIndex,
 			ContentIndex:   c.contentIndex,
 			Delta:          delta,
+			Logprobs:       logprobs,
 		},
 	}
 }
@@ -919,7 +1171,21 @@ func (c *ResponseChunkToNativeResponseChunkConverter) buildOutputTextDone(text s
 	}
 }
 
-func (c *ResponseChunkToNativeResponseChunkConverter) buildContentPartDoneText(text string) *responses2.ResponseChunk {
+func (c *ResponseChunkToNativeResponseChunkConverter) buildOutputTextAnnotationAdded(index int, annotation responses2.Annotation) *responses2.ResponseChunk {
+	return &responses2.ResponseChunk{
+		OfOutputTextAnnotationAdded: &responses2.ChunkOutputText[constants.ChunkTypeOutputTextAnnotationAdded]{
+			Type:            constants.ChunkTypeOutputTextAnnotationAdded(""),
+			SequenceNumber:  c.nextSeqNum(),
+			ItemId:          c.outputItemID,
+			OutputIndex:     c.outputIndex,
+			ContentIndex:    c.contentIndex,
+			Annotation:      &annotation,
+			AnnotationIndex: index,
+		},
+	}
+}
+
+func (c *ResponseChunkToNativeResponseChunkConverter) buildContentPartDoneText(text string, annotations []responses2.Annotation) *responses2.ResponseChunk {
 	return &responses2.ResponseChunk{
 		OfContentPartDone: &responses2.ChunkContentPart[constants.ChunkTypeContentPartDone]{
 			Type:           constants.ChunkTypeContentPartDone(""),
@@ -927,12 +1193,12 @@ func (c *ResponseChunkToNativeResponseChunkConverter) buildContentPartDoneText(t
 			ItemId:         c.outputItemID,
 			OutputIndex:    c.outputIndex,
 			ContentIndex:   c.contentIndex,
-			Part:           responses2.ChunkOutputItemContentUnion{OfOutputText: &responses2.OutputTextContent{Text: text}},
+			Part:           responses2.ChunkOutputItemContentUnion{OfOutputText: &responses2.OutputTextContent{Text: text, Annotations: annotations, Logprobs: c.accumulatedLogprobs}},
 		},
 	}
 }
 
-func (c *ResponseChunkToNativeResponseChunkConverter) buildOutputItemDoneMessage(text string) *responses2.ResponseChunk {
+func (c *ResponseChunkToNativeResponseChunkConverter) buildOutputItemDoneMessage(text string, annotations []responses2.Annotation) *responses2.ResponseChunk {
 	return &responses2.ResponseChunk{
 		OfOutputItemDone: &responses2.ChunkOutputItem[constants.ChunkTypeOutputItemDone]{
 			Type:           constants.ChunkTypeOutputItemDone(""),
@@ -943,7 +1209,7 @@ func (c *ResponseChunkToNativeResponseChunkConverter) buildOutputItemDoneMessage
 				Id:      c.outputItemID,
 				Status:  "completed",
 				Role:    RoleModel.ToNativeRole(),
-				Content: &responses2.ChunkOutputItemContent{{OfOutputText: &responses2.OutputTextContent{Text: text}}},
+				Content: &responses2.ChunkOutputItemContent{{OfOutputText: &responses2.OutputTextContent{Text: text, Annotations: annotations, Logprobs: c.accumulatedLogprobs}}},
 			},
 		},
 	}
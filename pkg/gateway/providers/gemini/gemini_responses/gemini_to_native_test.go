@@ -178,6 +178,37 @@ func TestGeminiToNative_FunctionCall(t *testing.T) {
 	assert.True(t, foundFunctionCall, "Should have found function_call output_item.added")
 }
 
+// Parallel calls arrive as consecutive function call parts, each of which is
+// a call of its own.
+func TestGeminiToNative_ConsecutiveFunctionCallsAreSeparateItems(t *testing.T) {
+	converter := newGeminiToNativeConverter()
+
+	var result []*responses.ResponseChunk
+	result = append(result, converter.ResponseChunkToNativeResponseChunk(createGeminiFunctionCallChunk("resp_fn", "gemini-2.5-flash", "get_weather", map[string]any{"location": "Paris"}, 100, 20, 120))...)
+	result = append(result, converter.ResponseChunkToNativeResponseChunk(createGeminiFunctionCallChunk("resp_fn", "gemini-2.5-flash", "get_weather", map[string]any{"location": "Tokyo"}, 100, 40, 140))...)
+	result = append(result, converter.ResponseChunkToNativeResponseChunk(createGeminiFinishedChunk("resp_fn", "gemini-2.5-flash", "STOP", 100, 40, 140))...)
+	result = append(result, converter.ResponseChunkToNativeResponseChunk(nil)...)
+
+	added := map[string]string{}
+	var done []responses.ChunkOutputItemData
+	for _, r := range result {
+		if r.OfOutputItemAdded != nil && r.OfOutputItemAdded.Item.Type == "function_call" {
+			added[r.OfOutputItemAdded.Item.Id] = *r.OfOutputItemAdded.Item.CallID
+		}
+		if r.OfOutputItemDone != nil && r.OfOutputItemDone.Item.Type == "function_call" {
+			done = append(done, r.OfOutputItemDone.Item)
+		}
+	}
+
+	require.Len(t, done, 2)
+	assert.JSONEq(t, `{"location":"Paris"}`, *done[0].Arguments)
+	assert.JSONEq(t, `{"location":"Tokyo"}`, *done[1].Arguments)
+	for _, item := range done {
+		assert.Equal(t, added[item.Id], *item.CallID, "call id changed between added and done")
+	}
+	assert.NotEqual(t, *done[0].CallID, *done[1].CallID)
+}
+
 // =============================================================================
 // Test: Nil Input (Stream End) Emits response.completed
 // =============================================================================
@@ -470,3 +501,175 @@ func TestGeminiToNative_ModelVersionPropagation(t *testing.T) {
 	}
 	t.Fatal("Should have found response.created")
 }
+
+func TestGeminiToNative_Logprobs(t *testing.T) {
+	result := &LogprobsResult{
+		ChosenCandidates: []LogprobCandidate{{Token: "yes", LogProbability: -0.05}},
+		TopCandidates: []TopCandidates{{Candidates: []LogprobCandidate{
+			{Token: "yes", LogProbability: -0.05},
+			{Token: "no", LogProbability: -3.1},
+		}}},
+	}
+
+	t.Run("request", func(t *testing.T) {
+		req := ResponsesInputToGeminiResponsesInput(&responses.Request{
+			Parameters: responses.Parameters{TopLogprobs: utils.Ptr(int64(2))},
+		})
+		require.NotNil(t, req.GenerationConfig.ResponseLogprobs)
+		assert.True(t, *req.GenerationConfig.ResponseLogprobs)
+		assert.Equal(t, int64(2), *req.GenerationConfig.Logprobs)
+		assert.Nil(t, req.GenerationConfig.TopK, "top logprobs are not a sampling setting")
+	})
+
+	t.Run("response", func(t *testing.T) {
+		chunk := createGeminiTextChunk("resp-1", "gemini-2.5-flash", "yes", 5, 1, 6)
+		chunk.Candidates[0].LogprobsResult = result
+		chunk.Candidates[0].AvgLogprobs = utils.Ptr(-0.05)
+
+		resp := chunk.ToNativeResponse()
+		logprobs := resp.OutputLogprobs()
+		require.Len(t, logprobs, 1)
+		assert.Equal(t, "yes", logprobs[0].Token)
+		assert.Equal(t, "no", logprobs[0].TopLogprobs[1].Token)
+		assert.InDelta(t, 0.951, responses.SequenceProbability(logprobs), 0.001)
+		assert.Equal(t, -0.05, resp.Metadata["avg_logprobs"])
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		converter := newGeminiToNativeConverter()
+		chunk := createGeminiTextChunk("resp-1", "gemini-2.5-flash", "yes", 5, 1, 6)
+		chunk.Candidates[0].LogprobsResult = result
+
+		out := converter.ResponseChunkToNativeResponseChunk(chunk)
+		out = append(out, converter.ResponseChunkToNativeResponseChunk(nil)...)
+
+		var delta *responses.ChunkOutputText[constants.ChunkTypeOutputTextDelta]
+		var done *responses.ChunkOutputItem[constants.ChunkTypeOutputItemDone]
+		for _, c := range out {
+			if c.OfOutputTextDelta != nil {
+				delta = c.OfOutputTextDelta
+			}
+			if c.OfOutputItemDone != nil {
+				done = c.OfOutputItemDone
+			}
+		}
+		require.NotNil(t, delta)
+		require.Len(t, delta.Logprobs, 1)
+		require.NotNil(t, done)
+		assert.Equal(t, delta.Logprobs, (*done.Item.Content)[0].OfOutputText.Logprobs)
+	})
+}
+
+// =============================================================================
+// Test: URL Context and Google Maps Metadata Become Hosted Tool Calls
+// =============================================================================
+
+func TestGeminiToNative_HostedToolCalls(t *testing.T) {
+	withMetadata := func(chunk *Response) *Response {
+		chunk.Candidates[0].URLContextMetadata = &URLContextMetadata{
+			URLMetadata: []URLMetadata{{RetrievedURL: "https://example.com", URLRetrievalStatus: "URL_RETRIEVAL_STATUS_SUCCESS"}},
+		}
+		chunk.Candidates[0].GroundingMetadata = &GroundingMetadata{
+			GroundingChunks: []GroundingChunk{{Maps: &GroundingChunkMaps{URI: "https://maps.google.com/?cid=1", Title: "Cafe", PlaceID: "places/1"}}},
+		}
+		return chunk
+	}
+
+	t.Run("tools", func(t *testing.T) {
+		tools := NativeToolsToTools([]responses.ToolUnion{
+			{OfURLContext: &responses.URLContextTool{}},
+			{OfGoogleMaps: &responses.GoogleMapsTool{EnableWidget: utils.Ptr(true)}},
+		})
+		require.Len(t, tools, 1)
+		assert.NotNil(t, tools[0].URLContext)
+		require.NotNil(t, tools[0].GoogleMaps)
+		assert.True(t, *tools[0].GoogleMaps.EnableWidget)
+	})
+
+	t.Run("response", func(t *testing.T) {
+		resp := withMetadata(createGeminiTextChunk("resp-1", "gemini-2.5-flash", "Try Cafe.", 5, 3, 8)).ToNativeResponse()
+
+		require.Len(t, resp.Output, 3)
+		require.NotNil(t, resp.Output[1].OfURLContextCall)
+		assert.Equal(t, "https://example.com", resp.Output[1].OfURLContextCall.URLs[0].URL)
+		require.NotNil(t, resp.Output[2].OfGoogleMapsCall)
+		assert.Equal(t, "places/1", resp.Output[2].OfGoogleMapsCall.Places[0].PlaceID)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		converter := newGeminiToNativeConverter()
+		out := converter.ResponseChunkToNativeResponseChunk(createGeminiTextChunk("resp-1", "gemini-2.5-flash", "Try ", 5, 1, 6))
+		out = append(out, converter.ResponseChunkToNativeResponseChunk(withMetadata(createGeminiFinishedChunk("resp-1", "gemini-2.5-flash", "STOP", 5, 3, 8)))...)
+		out = append(out, converter.ResponseChunkToNativeResponseChunk(nil)...)
+
+		var doneTypes []string
+		var completed *responses.ChunkResponse[constants.ChunkTypeResponseCompleted]
+		for _, c := range out {
+			if c.OfOutputItemDone != nil {
+				doneTypes = append(doneTypes, c.OfOutputItemDone.Item.Type)
+			}
+			if c.OfResponseCompleted != nil {
+				completed = c.OfResponseCompleted
+			}
+		}
+		assert.Equal(t, []string{"message", "url_context_call", "google_maps_call"}, doneTypes)
+		require.NotNil(t, completed)
+		require.Len(t, completed.Response.Output, 3)
+		assert.NotNil(t, completed.Response.Output[2].OfGoogleMapsCall)
+	})
+}
+
+// =============================================================================
+// Test: Grounding Supports Become Annotations
+// =============================================================================
+
+func TestGeminiToNative_GroundingAnnotations(t *testing.T) {
+	// "Café" is 5 bytes but 4 characters; Gemini's segment offsets are bytes.
+	grounding := &GroundingMetadata{
+		GroundingChunks: []GroundingChunk{
+			{Web: &GroundingChunkWeb{URI: "https://cafe.example", Title: "cafe.example"}},
+		},
+		GroundingSupports: []GroundingSupport{{
+			Segment:               GroundingSegment{StartIndex: 7, EndIndex: 17, Text: "open 8–5"},
+			GroundingChunkIndices: []int{0},
+		}},
+	}
+
+	t.Run("response", func(t *testing.T) {
+		chunk := createGeminiTextChunk("resp-1", "gemini-2.5-flash", "Café: open 8–5", 5, 4, 9)
+		chunk.Candidates[0].GroundingMetadata = grounding
+
+		out := chunk.ToNativeResponse().Output
+		require.NotEmpty(t, out)
+		text := (*out[0].OfOutputMessage.Content)[0].OfOutputText
+		require.Len(t, text.Annotations, 1)
+		assert.Equal(t, "url_citation", text.Annotations[0].Type)
+		assert.Equal(t, "https://cafe.example", text.Annotations[0].URL)
+		assert.Equal(t, 6, text.Annotations[0].StartIndex)
+		assert.Equal(t, 14, text.Annotations[0].EndIndex)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		converter := newGeminiToNativeConverter()
+		chunk := createGeminiTextChunk("resp-1", "gemini-2.5-flash", "Café: open 8–5", 5, 4, 9)
+		chunk.Candidates[0].GroundingMetadata = grounding
+
+		out := converter.ResponseChunkToNativeResponseChunk(chunk)
+		out = append(out, converter.ResponseChunkToNativeResponseChunk(nil)...)
+
+		var added *responses.ChunkOutputText[constants.ChunkTypeOutputTextAnnotationAdded]
+		var done *responses.ChunkOutputItem[constants.ChunkTypeOutputItemDone]
+		for _, c := range out {
+			if c.OfOutputTextAnnotationAdded != nil {
+				added = c.OfOutputTextAnnotationAdded
+			}
+			if c.OfOutputItemDone != nil {
+				done = c.OfOutputItemDone
+			}
+		}
+		require.NotNil(t, added)
+		assert.Equal(t, "https://cafe.example", added.Annotation.URL)
+		require.NotNil(t, done)
+		assert.Len(t, (*done.Item.Content)[0].OfOutputText.Annotations, 1)
+	})
+}
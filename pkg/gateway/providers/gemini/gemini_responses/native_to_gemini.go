@@ -26,7 +26,6 @@ func ResponsesInputToGeminiResponsesInput(in *responses2.Request) *Request {
 			Temperature:     in.Temperature,
 			MaxOutputTokens: in.MaxOutputTokens,
 			TopP:            in.TopP,
-			TopK:            in.TopLogprobs,
 		},
 		Tools:  NativeToolsToTools(in.Tools),
 		Stream: in.Stream,
@@ -34,6 +33,11 @@ func ResponsesInputToGeminiResponsesInput(in *responses2.Request) *Request {
 
 	out.GenerationConfig.ThinkingConfig = NativeReasoningParamToGeminiThinkingConfig(in)
 
+	if in.LogprobsRequested() {
+		out.GenerationConfig.ResponseLogprobs = utils.Ptr(true)
+		out.GenerationConfig.Logprobs = in.TopLogprobs
+	}
+
 	if in.Instructions != nil {
 		out.SystemInstruction = &Content{
 			Parts: []Part{
@@ -113,6 +117,18 @@ func NativeToolsToTools(nativeTools []responses2.ToolUnion) []Tool {
 		if nativeTool.OfCodeExecution != nil {
 			out.CodeExecution = &CodeExecutionTool{}
 		}
+
+		if nativeTool.OfWebSearch != nil {
+			out.GoogleSearch = &GoogleSearchTool{}
+		}
+
+		if nativeTool.OfURLContext != nil {
+			out.URLContext = &URLContextTool{}
+		}
+
+		if nativeTool.OfGoogleMaps != nil {
+			out.GoogleMaps = &GoogleMapsTool{EnableWidget: nativeTool.OfGoogleMaps.EnableWidget}
+		}
 	}
 
 	return []Tool{out}
@@ -343,6 +359,7 @@ func NativeMessagesToMessages(in responses2.InputUnion) []Content {
 
 func NativeResponseToResponse(in *responses2.Response) *Response {
 	parts := []Part{}
+	var logprobs []responses2.Logprob
 
 	for _, nativeOutput := range in.Output {
 		if nativeOutput.OfOutputMessage != nil {
@@ -350,6 +367,7 @@ func NativeResponseToResponse(in *responses2.Response) *Response {
 				parts = append(parts, Part{
 					Text: utils.Ptr(nativeContent.OfOutputText.Text),
 				})
+				logprobs = append(logprobs, nativeContent.OfOutputText.Logprobs...)
 			}
 		}
 
@@ -407,13 +425,32 @@ func NativeResponseToResponse(in *responses2.Response) *Response {
 					Role:  RoleModel,
 					Parts: parts,
 				},
-				FinishReason: stopReason,
+				FinishReason:   stopReason,
+				LogprobsResult: NativeLogprobsToLogprobsResult(logprobs),
 			},
 		},
 		Error: nil,
 	}
 }
 
+func NativeLogprobsToLogprobsResult(in []responses2.Logprob) *LogprobsResult {
+	if len(in) == 0 {
+		return nil
+	}
+
+	out := &LogprobsResult{}
+	for _, lp := range in {
+		out.ChosenCandidates = append(out.ChosenCandidates, LogprobCandidate{Token: lp.Token, LogProbability: lp.Logprob})
+
+		top := TopCandidates{Candidates: []LogprobCandidate{}}
+		for _, t := range lp.TopLogprobs {
+			top.Candidates = append(top.Candidates, LogprobCandidate{Token: t.Token, LogProbability: t.Logprob})
+		}
+		out.TopCandidates = append(out.TopCandidates, top)
+	}
+	return out
+}
+
 // =============================================================================
 // Native to Gemini ResponseChunk Conversion
 // =============================================================================
@@ -473,9 +510,10 @@ func (c *NativeResponseChunkToResponseChunkConverter) handleOutputTextDelta(delt
 		return nil
 	}
 
-	return []Response{
-		c.buildResponse([]Part{{Text: utils.Ptr(delta.Delta)}}),
-	}
+	resp := c.buildResponse([]Part{{Text: utils.Ptr(delta.Delta)}})
+	resp.Candidates[0].LogprobsResult = NativeLogprobsToLogprobsResult(delta.Logprobs)
+
+	return []Response{resp}
 }
 
 // handleReasoningSummaryTextDelta emits Gemini response with a thought part
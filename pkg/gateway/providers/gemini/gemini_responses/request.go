@@ -14,6 +14,8 @@ type GenerationConfig struct {
 	Temperature        *float64        `json:"temperature,omitempty"`
 	TopP               *float64        `json:"topP,omitempty"`
 	TopK               *int64          `json:"topK,omitempty"`
+	ResponseLogprobs   *bool           `json:"responseLogprobs,omitempty"`
+	Logprobs           *int64          `json:"logprobs,omitempty"` // number of top candidates per step
 	ThinkingConfig     *ThinkingConfig `json:"thinkingConfig,omitempty"`
 	ResponseModalities []string        `json:"responseModalities"`
 
@@ -100,6 +102,9 @@ type CodeExecutionResultPart struct {
 type Tool struct {
 	FunctionDeclarations []FunctionTool     `json:"functionDeclarations,omitempty"`
 	CodeExecution        *CodeExecutionTool `json:"code_execution,omitempty"`
+	GoogleSearch         *GoogleSearchTool  `json:"googleSearch,omitempty"`
+	URLContext           *URLContextTool    `json:"urlContext,omitempty"`
+	GoogleMaps           *GoogleMapsTool    `json:"googleMaps,omitempty"`
 }
 
 type FunctionTool struct {
@@ -111,3 +116,13 @@ type FunctionTool struct {
 
 type CodeExecutionTool struct {
 }
+
+type GoogleSearchTool struct {
+}
+
+type URLContextTool struct {
+}
+
+type GoogleMapsTool struct {
+	EnableWidget *bool `json:"enableWidget,omitempty"`
+}
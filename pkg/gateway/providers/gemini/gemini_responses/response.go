@@ -11,6 +11,90 @@ type Response struct {
 type Candidate struct {
 	Content      Content `json:"content"`
 	FinishReason string  `json:"finishReason,omitempty"`
+
+	// Set when GenerationConfig.ResponseLogprobs is. LogprobsResult covers
+	// the whole candidate; in a stream, the tokens of that chunk.
+	AvgLogprobs    *float64        `json:"avgLogprobs,omitempty"`
+	LogprobsResult *LogprobsResult `json:"logprobsResult,omitempty"`
+
+	// Set when the urlContext and googleMaps tools were used. In a stream
+	// they arrive with the last chunks.
+	URLContextMetadata *URLContextMetadata `json:"urlContextMetadata,omitempty"`
+	GroundingMetadata  *GroundingMetadata  `json:"groundingMetadata,omitempty"`
+}
+
+type URLContextMetadata struct {
+	URLMetadata []URLMetadata `json:"urlMetadata"`
+}
+
+type URLMetadata struct {
+	RetrievedURL       string `json:"retrievedUrl"`
+	URLRetrievalStatus string `json:"urlRetrievalStatus"`
+}
+
+type GroundingMetadata struct {
+	GroundingChunks              []GroundingChunk   `json:"groundingChunks,omitempty"`
+	GroundingSupports            []GroundingSupport `json:"groundingSupports,omitempty"`
+	WebSearchQueries             []string           `json:"webSearchQueries,omitempty"`
+	GoogleMapsWidgetContextToken *string            `json:"googleMapsWidgetContextToken,omitempty"`
+}
+
+// GroundingChunk is a source the answer was grounded in.
+type GroundingChunk struct {
+	Web              *GroundingChunkWeb              `json:"web,omitempty"`
+	RetrievedContext *GroundingChunkRetrievedContext `json:"retrievedContext,omitempty"`
+	Maps             *GroundingChunkMaps             `json:"maps,omitempty"`
+}
+
+type GroundingChunkWeb struct {
+	URI    string `json:"uri"`
+	Title  string `json:"title"`
+	Domain string `json:"domain,omitempty"`
+}
+
+type GroundingChunkRetrievedContext struct {
+	URI   string `json:"uri,omitempty"`
+	Title string `json:"title,omitempty"`
+	Text  string `json:"text,omitempty"`
+}
+
+// GroundingSupport ties a segment of the answer to the chunks supporting it.
+type GroundingSupport struct {
+	Segment               GroundingSegment `json:"segment"`
+	GroundingChunkIndices []int            `json:"groundingChunkIndices"`
+	ConfidenceScores      []float64        `json:"confidenceScores,omitempty"`
+}
+
+// GroundingSegment is a range of a part of the answer. The indices are byte
+// offsets into the part's text.
+type GroundingSegment struct {
+	PartIndex  int    `json:"partIndex,omitempty"`
+	StartIndex int    `json:"startIndex,omitempty"`
+	EndIndex   int    `json:"endIndex"`
+	Text       string `json:"text,omitempty"`
+}
+
+type GroundingChunkMaps struct {
+	URI     string `json:"uri"`
+	Title   string `json:"title"`
+	PlaceID string `json:"placeId"`
+}
+
+type LogprobsResult struct {
+	// TopCandidates[i] are the most likely tokens at step i, and
+	// ChosenCandidates[i] the one that was sampled.
+	TopCandidates    []TopCandidates    `json:"topCandidates,omitempty"`
+	ChosenCandidates []LogprobCandidate `json:"chosenCandidates,omitempty"`
+}
+
+type TopCandidates struct {
+	Candidates []LogprobCandidate `json:"candidates"`
+}
+
+type LogprobCandidate struct {
+	Token          string  `json:"token"`
+	TokenID        int     `json:"tokenId"`
+	LogProbability float64 `json:"logProbability"`
 }
 
 type UsageMetadata struct {
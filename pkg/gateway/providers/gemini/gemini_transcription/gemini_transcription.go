@@ -4,9 +4,12 @@ import (
 	"encoding/base64"
 	"mime"
 	"path/filepath"
+	"strings"
 
+	"github.com/bytedance/sonic"
 	transcription2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
 	gemini_responses2 "github.com/hastekit/agent-sdk-go/pkg/gateway/providers/gemini/gemini_responses"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
 )
 
 type Request struct {
@@ -26,7 +29,21 @@ func NativeRequestToRequest(in *transcription2.Request) *Request {
 		prompt = *in.Prompt
 	}
 
+	// Gemini has no timestamp or diarization options; segments are asked
+	// for as structured output instead.
+	var config *gemini_responses2.GenerationConfig
+	diarize := in.Diarize != nil && *in.Diarize
+	if diarize || len(in.TimestampGranularities) > 0 {
+		prompt = segmentsPrompt(in, diarize)
+		config = &gemini_responses2.GenerationConfig{
+			ResponseModalities: []string{"TEXT"},
+			ResponseMimeType:   utils.Ptr("application/json"),
+			ResponseJsonSchema: segmentsSchema,
+		}
+	}
+
 	return &Request{
+		GenerationConfig: config,
 		Contents: []gemini_responses2.Content{
 			{
 				Parts: []gemini_responses2.Part{
@@ -45,6 +62,56 @@ func NativeRequestToRequest(in *transcription2.Request) *Request {
 	}
 }
 
+// segmentsSchema is the structured output asked for when segments are
+// wanted.
+var segmentsSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"segments": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"start":   map[string]any{"type": "number", "description": "Seconds from the start of the audio"},
+					"end":     map[string]any{"type": "number", "description": "Seconds from the start of the audio"},
+					"speaker": map[string]any{"type": "string"},
+					"text":    map[string]any{"type": "string"},
+				},
+				"required": []string{"start", "end", "text"},
+			},
+		},
+	},
+	"required": []string{"segments"},
+}
+
+func segmentsPrompt(in *transcription2.Request, diarize bool) string {
+	var b strings.Builder
+	if in.Prompt != nil {
+		b.WriteString(*in.Prompt)
+		b.WriteString("\n\n")
+	}
+	b.WriteString("Transcribe this audio")
+	if in.Language != nil {
+		b.WriteString(" in " + *in.Language)
+	}
+	b.WriteString(". Split the transcript into segments at pauses")
+	if diarize {
+		b.WriteString(" and wherever the speaker changes, labeling each segment's speaker consistently as \"Speaker 1\", \"Speaker 2\" and so on")
+	}
+	b.WriteString(". Give each segment's start and end time in seconds from the start of the audio.")
+	return b.String()
+}
+
+// transcriptSegments is the structured output described by segmentsSchema.
+type transcriptSegments struct {
+	Segments []struct {
+		Start   float64 `json:"start"`
+		End     float64 `json:"end"`
+		Speaker string  `json:"speaker"`
+		Text    string  `json:"text"`
+	} `json:"segments"`
+}
+
 type Response struct {
 	*gemini_responses2.Response
 }
@@ -66,6 +133,24 @@ func (r *Response) ToNativeResponse() *transcription2.Response {
 		Raw:  map[string]any{"Gemini": r},
 	}
 
+	var structured transcriptSegments
+	if strings.HasPrefix(strings.TrimSpace(text), "{") && sonic.UnmarshalString(text, &structured) == nil && len(structured.Segments) > 0 {
+		var texts []string
+		for i, s := range structured.Segments {
+			resp.Segments = append(resp.Segments, transcription2.Segment{
+				ID:      i,
+				Start:   s.Start,
+				End:     s.End,
+				Text:    strings.TrimSpace(s.Text),
+				Speaker: s.Speaker,
+			})
+			texts = append(texts, strings.TrimSpace(s.Text))
+		}
+		resp.Text = strings.Join(texts, " ")
+		end := structured.Segments[len(structured.Segments)-1].End
+		resp.Duration = &end
+	}
+
 	if r.UsageMetadata != nil {
 		resp.Usage = &transcription2.Usage{
 			PromptTokens:     r.UsageMetadata.PromptTokenCount,
@@ -113,3 +198,57 @@ func mimeTypeFromFilename(filename string) string {
 
 	return mimeType
 }
+
+// StreamConverter folds the chunks of a streamGenerateContent call into a
+// native transcription stream: the text so far as a partial after each chunk,
+// then the whole transcript as one final spanning Duration, the length of the
+// audio sent.
+type StreamConverter struct {
+	Duration float64
+
+	text  strings.Builder
+	usage *transcription2.Usage
+}
+
+func (c *StreamConverter) Convert(r *Response) []*transcription2.ResponseChunk {
+	if r.Response == nil {
+		return nil
+	}
+	if r.UsageMetadata != nil {
+		c.usage = &transcription2.Usage{
+			PromptTokens:     r.UsageMetadata.PromptTokenCount,
+			CompletionTokens: r.UsageMetadata.CandidatesTokenCount,
+			TotalTokens:      r.UsageMetadata.TotalTokenCount,
+		}
+	}
+
+	if len(r.Candidates) == 0 {
+		return nil
+	}
+
+	var delta bool
+	for _, part := range r.Candidates[0].Content.Parts {
+		if part.Text != nil && *part.Text != "" {
+			c.text.WriteString(*part.Text)
+			delta = true
+		}
+	}
+	if !delta {
+		return nil
+	}
+
+	return []*transcription2.ResponseChunk{{OfPartial: &transcription2.ChunkPartial[transcription2.ChunkTypePartial]{
+		Text: c.text.String(),
+	}}}
+}
+
+// Finish ends the stream once the response is complete.
+func (c *StreamConverter) Finish() []*transcription2.ResponseChunk {
+	text := strings.TrimSpace(c.text.String())
+	duration := c.Duration
+
+	return []*transcription2.ResponseChunk{
+		{OfFinal: &transcription2.ChunkFinal[transcription2.ChunkTypeFinal]{Text: text, End: duration}},
+		{OfDone: &transcription2.ChunkDone[transcription2.ChunkTypeDone]{Text: text, Duration: &duration, Usage: c.usage}},
+	}
+}
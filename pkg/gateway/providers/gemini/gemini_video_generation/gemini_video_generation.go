@@ -0,0 +1,150 @@
+package gemini_video_generation
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/video_generation"
+)
+
+const DefaultModel = "veo-3.0-generate-001"
+
+// Request represents a Veo video generation request.
+// Endpoint: POST /v1beta/models/{model}:predictLongRunning
+type Request struct {
+	Instances  []Instance  `json:"instances"`
+	Parameters *Parameters `json:"parameters,omitempty"`
+}
+
+type Instance struct {
+	Prompt string `json:"prompt"`
+	Image  *Image `json:"image,omitempty"`
+}
+
+type Image struct {
+	BytesBase64Encoded string `json:"bytesBase64Encoded"`
+	MimeType           string `json:"mimeType"`
+}
+
+type Parameters struct {
+	AspectRatio     *string `json:"aspectRatio,omitempty"` // "16:9", "9:16"
+	NegativePrompt  *string `json:"negativePrompt,omitempty"`
+	DurationSeconds *int    `json:"durationSeconds,omitempty"` // 4, 6 or 8
+	Resolution      *string `json:"resolution,omitempty"`      // "720p", "1080p"
+}
+
+func NativeRequestToRequest(in *video_generation.Request) *Request {
+	instance := Instance{Prompt: in.Prompt}
+	if in.Image != nil {
+		mimeType := in.Image.MimeType
+		if mimeType == "" {
+			mimeType = http.DetectContentType(in.Image.Data)
+		}
+		instance.Image = &Image{
+			BytesBase64Encoded: base64.StdEncoding.EncodeToString(in.Image.Data),
+			MimeType:           mimeType,
+		}
+	}
+
+	req := &Request{Instances: []Instance{instance}}
+	if in.AspectRatio != nil || in.NegativePrompt != nil || in.DurationSeconds != nil || in.Resolution != nil {
+		req.Parameters = &Parameters{
+			AspectRatio:     in.AspectRatio,
+			NegativePrompt:  in.NegativePrompt,
+			DurationSeconds: in.DurationSeconds,
+			Resolution:      in.Resolution,
+		}
+	}
+
+	return req
+}
+
+// Operation is the long-running operation returned by predictLongRunning and
+// by polling GET /v1beta/{name}.
+type Operation struct {
+	Name     string             `json:"name"`
+	Done     bool               `json:"done"`
+	Response *OperationResponse `json:"response,omitempty"`
+	Error    *OperationError    `json:"error,omitempty"`
+}
+
+type OperationResponse struct {
+	GenerateVideoResponse *GenerateVideoResponse `json:"generateVideoResponse,omitempty"`
+}
+
+type GenerateVideoResponse struct {
+	GeneratedSamples        []GeneratedSample `json:"generatedSamples,omitempty"`
+	RaiMediaFilteredCount   int               `json:"raiMediaFilteredCount,omitempty"`
+	RaiMediaFilteredReasons []string          `json:"raiMediaFilteredReasons,omitempty"`
+}
+
+type GeneratedSample struct {
+	Video struct {
+		URI string `json:"uri"`
+	} `json:"video"`
+}
+
+type OperationError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  string `json:"status,omitempty"`
+}
+
+// VideoURI returns the download URI of the first generated video, or "" if
+// there is none.
+func (o *Operation) VideoURI() string {
+	if o.Response == nil || o.Response.GenerateVideoResponse == nil {
+		return ""
+	}
+	for _, s := range o.Response.GenerateVideoResponse.GeneratedSamples {
+		if s.Video.URI != "" {
+			return s.Video.URI
+		}
+	}
+	return ""
+}
+
+func (o *Operation) ToNativeResponse() *video_generation.Response {
+	resp := &video_generation.Response{
+		ID:     o.Name,
+		Model:  ModelFromOperation(o.Name),
+		Status: video_generation.StatusInProgress,
+		RawFields: map[string]any{
+			"Gemini": o,
+		},
+	}
+
+	switch {
+	case o.Error != nil:
+		resp.Status = video_generation.StatusFailed
+		resp.Error = &video_generation.Error{
+			Code:    strconv.Itoa(o.Error.Code),
+			Message: o.Error.Message,
+		}
+	case !o.Done:
+	case o.VideoURI() != "":
+		resp.Status = video_generation.StatusCompleted
+	default:
+		// A finished operation without a video was blocked by safety filters.
+		resp.Status = video_generation.StatusFailed
+		resp.Error = &video_generation.Error{Code: "filtered", Message: "no video was generated"}
+		if r := o.Response; r != nil && r.GenerateVideoResponse != nil && len(r.GenerateVideoResponse.RaiMediaFilteredReasons) > 0 {
+			resp.Error.Message = strings.Join(r.GenerateVideoResponse.RaiMediaFilteredReasons, "; ")
+		}
+	}
+
+	return resp
+}
+
+// ModelFromOperation returns the model in an operation name of the form
+// "models/{model}/operations/{id}".
+func ModelFromOperation(name string) string {
+	rest, ok := strings.CutPrefix(name, "models/")
+	if !ok {
+		return ""
+	}
+	model, _, _ := strings.Cut(rest, "/")
+	return model
+}
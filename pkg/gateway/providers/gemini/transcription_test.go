@@ -0,0 +1,96 @@
+package gemini_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/gemini"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/gemini/gemini_transcription"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStreamingTranscription(t *testing.T) {
+	var sent gemini_transcription.Request
+	// firstFrame is closed once the server has read the first frame, which
+	// the test waits for before sending the second: the audio is uploaded as
+	// it is read, not after the input ends.
+	firstFrame := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/models/gemini-2.0-flash:streamGenerateContent", r.URL.Path)
+
+		reader := bufio.NewReader(r.Body)
+		var head string
+		for !strings.HasSuffix(head, `"data":`) {
+			more, err := reader.ReadString(':')
+			if !assert.NoError(t, err) {
+				return
+			}
+			head += more
+		}
+		// The WAV header and the first frame, 16044 bytes, are 21392
+		// characters of base64, after the opening quote.
+		frame := make([]byte, 1+21392)
+		if _, err := io.ReadFull(reader, frame); !assert.NoError(t, err) {
+			return
+		}
+		close(firstFrame)
+		rest, _ := io.ReadAll(reader)
+		if !assert.NoError(t, sonic.Unmarshal([]byte(head+string(frame)+string(rest)), &sent)) {
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, `data: {"candidates":[{"content":{"parts":[{"text":"Hel"}]}}]}`+"\n\n"+
+			`data: {"candidates":[{"content":{"parts":[{"text":"lo."}]}}],"usageMetadata":{"promptTokenCount":14,"candidatesTokenCount":3,"totalTokenCount":17}}`+"\n\n")
+	}))
+	defer server.Close()
+
+	client := gemini.NewClient(&gemini.ClientOptions{BaseURL: server.URL, ApiKey: "key"})
+
+	frames := make(chan []byte)
+	go func() {
+		defer close(frames)
+		frames <- make([]byte, 16000)
+		select {
+		case <-firstFrame:
+		case <-time.After(5 * time.Second):
+			t.Error("the first frame was not uploaded before the input ended")
+		}
+		frames <- make([]byte, 16000)
+	}()
+
+	out, err := client.NewStreamingTranscription(context.Background(), &transcription.Request{AudioFrames: frames})
+	require.NoError(t, err)
+
+	var chunks []*transcription.ResponseChunk
+	for c := range out {
+		chunks = append(chunks, c)
+	}
+
+	require.Len(t, sent.Contents, 1)
+	inline := sent.Contents[0].Parts[0].InlineData
+	require.NotNil(t, inline)
+	assert.Equal(t, "audio/wav", inline.MimeType)
+	audio, err := base64.StdEncoding.DecodeString(inline.Data)
+	require.NoError(t, err)
+	assert.Equal(t, "RIFF", string(audio[:4]), "raw PCM is sent as WAV")
+	assert.Len(t, audio, 44+32000)
+	assert.Contains(t, *sent.Contents[0].Parts[1].Text, "Transcribe this audio")
+
+	require.Len(t, chunks, 4)
+	assert.Equal(t, "Hello.", chunks[1].OfPartial.Text, "partials carry the text so far")
+	assert.Equal(t, "Hello.", chunks[2].OfFinal.Text)
+	assert.Equal(t, 1.0, chunks[2].OfFinal.End)
+	assert.Equal(t, &transcription.Usage{PromptTokens: 14, CompletionTokens: 3, TotalTokens: 17}, chunks[3].OfDone.Usage)
+}
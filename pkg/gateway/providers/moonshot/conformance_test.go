@@ -0,0 +1,24 @@
+package moonshot_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/moonshot"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/providertest"
+)
+
+func TestConformance(t *testing.T) {
+	suite := providertest.Suite{
+		Provider:  "moonshot",
+		Model:     "kimi-k2-0905-preview",
+		APIKeyEnv: "MOONSHOT_API_KEY",
+		// The model has no vision.
+		Scenarios: []string{"text", "tool_call", "parallel_tool_calls", "reasoning", "structured_output"},
+		NewProvider: func(apiKey string, transport *http.Client) llm.Provider {
+			return moonshot.NewClient(&moonshot.ClientOptions{ApiKey: apiKey, Transport: transport})
+		},
+	}
+	suite.Run(t)
+}
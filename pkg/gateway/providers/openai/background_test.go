@@ -0,0 +1,70 @@
+package openai_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackgroundResponse_PollCancelResume(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /responses/resp_123":
+			if r.URL.Query().Get("stream") == "true" {
+				assert.Equal(t, "3", r.URL.Query().Get("starting_after"))
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = io.WriteString(w, "event: response.output_text.delta\n"+
+					`data: {"type":"response.output_text.delta","sequence_number":4,"item_id":"msg_1","output_index":0,"content_index":0,"delta":"lo"}`+"\n\n"+
+					"event: response.completed\n"+
+					`data: {"type":"response.completed","sequence_number":5,"response":{"id":"resp_123","model":"o3-deep-research","status":"completed","output":[]}}`+"\n\n")
+				return
+			}
+			_, _ = io.WriteString(w, `{"id":"resp_123","model":"o3-deep-research","status":"in_progress","output":[]}`)
+		case "POST /responses/resp_123/cancel":
+			_, _ = io.WriteString(w, `{"id":"resp_123","model":"o3-deep-research","status":"cancelled","output":[]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"error":{"message":"no such response"}}`)
+		}
+	}))
+	defer server.Close()
+
+	client := openai.NewClient(&openai.ClientOptions{BaseURL: server.URL, ApiKey: "sk-test"})
+	ctx := context.Background()
+
+	polled, err := client.GetResponse(ctx, &responses.GetResponseRequest{ID: "resp_123"})
+	require.NoError(t, err)
+	assert.Equal(t, "resp_123", polled.ID)
+	assert.Equal(t, responses.ResponseStatusInProgress, polled.Status)
+	assert.False(t, polled.Done())
+
+	stream, err := client.StreamResponseFrom(ctx, &responses.StreamResponseFromRequest{ID: "resp_123", SequenceNumber: 3})
+	require.NoError(t, err)
+
+	var chunks []*responses.ResponseChunk
+	for chunk := range stream {
+		chunks = append(chunks, chunk)
+	}
+	require.Len(t, chunks, 2)
+	require.NotNil(t, chunks[0].OfOutputTextDelta)
+	assert.Equal(t, "lo", chunks[0].OfOutputTextDelta.Delta)
+	require.NotNil(t, chunks[1].OfResponseCompleted)
+	assert.Equal(t, 5, chunks[1].OfResponseCompleted.SequenceNumber)
+
+	cancelled, err := client.CancelResponse(ctx, &responses.CancelResponseRequest{ID: "resp_123"})
+	require.NoError(t, err)
+	assert.Equal(t, responses.ResponseStatusCancelled, cancelled.Status)
+	assert.True(t, cancelled.Done())
+
+	_, err = client.GetResponse(ctx, &responses.GetResponseRequest{ID: "resp_missing"})
+	assert.EqualError(t, err, "no such response")
+}
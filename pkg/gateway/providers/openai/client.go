@@ -9,30 +9,40 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"mime"
 	"mime/multipart"
 	"net/http"
 	"net/textproto"
+	"net/url"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"github.com/bytedance/sonic"
 	chat_completion2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/chat_completion"
 	embeddings2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/embeddings"
 	image_edit2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/image_edit"
 	image_generation2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/image_generation"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/models"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/realtime"
 	responses2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
 	speech2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/speech"
 	transcription2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
+	video_generation2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/video_generation"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/base"
 	openai_chat_completion2 "github.com/hastekit/agent-sdk-go/pkg/gateway/providers/openai/openai_chat_completion"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/openai/openai_embeddings"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/openai/openai_image_edit"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/openai/openai_image_generation"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/openai/openai_models"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/openai/openai_realtime"
 	openai_responses2 "github.com/hastekit/agent-sdk-go/pkg/gateway/providers/openai/openai_responses"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/openai/openai_speech"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/openai/openai_transcription"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/openai/openai_video_generation"
 	"github.com/hastekit/agent-sdk-go/pkg/utils"
 )
 
@@ -42,7 +52,7 @@ type ClientOptions struct {
 	ApiKey  string
 	Headers map[string]string
 
-	transport *http.Client
+	Transport *http.Client
 }
 
 type Client struct {
@@ -51,8 +61,8 @@ type Client struct {
 }
 
 func NewClient(opts *ClientOptions) *Client {
-	if opts.transport == nil {
-		opts.transport = http.DefaultClient
+	if opts.Transport == nil {
+		opts.Transport = http.DefaultClient
 	}
 
 	if opts.BaseURL == "" {
@@ -80,7 +90,7 @@ func (c *Client) NewResponses(ctx context.Context, inp *responses2.Request) (*re
 	req.Header.Set("Authorization", "Bearer "+c.opts.ApiKey)
 	base.AddAdditionalHeaders(req, inp.ExtraFields)
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -116,7 +126,7 @@ func (c *Client) NewStreamingResponses(ctx context.Context, inp *responses2.Requ
 	req.Header.Set("Authorization", "Bearer "+c.opts.ApiKey)
 	base.AddAdditionalHeaders(req, inp.ExtraFields)
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -126,6 +136,12 @@ func (c *Client) NewStreamingResponses(ctx context.Context, inp *responses2.Requ
 		return nil, base.ParseErrorResponse(res)
 	}
 
+	return readResponseStream(ctx, res), nil
+}
+
+// readResponseStream turns a Responses event stream into native chunks. It
+// owns res.Body and closes it when the stream ends.
+func readResponseStream(ctx context.Context, res *http.Response) chan *responses2.ResponseChunk {
 	out := make(chan *responses2.ResponseChunk)
 
 	go func() {
@@ -153,7 +169,76 @@ func (c *Client) NewStreamingResponses(ctx context.Context, inp *responses2.Requ
 		}
 	}()
 
-	return out, nil
+	return out
+}
+
+// GetResponse retrieves a stored response. A background response is polled
+// this way until Done.
+func (c *Client) GetResponse(ctx context.Context, in *responses2.GetResponseRequest) (*responses2.Response, error) {
+	return c.doStoredResponseRequest(ctx, http.MethodGet, "/responses/"+url.PathEscape(in.ID))
+}
+
+// CancelResponse cancels a background response. Cancelling one that has
+// already finished returns it unchanged.
+func (c *Client) CancelResponse(ctx context.Context, in *responses2.CancelResponseRequest) (*responses2.Response, error) {
+	return c.doStoredResponseRequest(ctx, http.MethodPost, "/responses/"+url.PathEscape(in.ID)+"/cancel")
+}
+
+// StreamResponseFrom streams a background response's events from after
+// in.SequenceNumber, whether it is still running or has finished. Only
+// responses created with background set can be streamed again.
+func (c *Client) StreamResponseFrom(ctx context.Context, in *responses2.StreamResponseFromRequest) (chan *responses2.ResponseChunk, error) {
+	query := url.Values{
+		"stream":         {"true"},
+		"starting_after": {strconv.Itoa(in.SequenceNumber)},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.opts.BaseURL+"/responses/"+url.PathEscape(in.ID)+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.authHeaders() {
+		req.Header.Set(k, v)
+	}
+
+	res, err := c.opts.Transport.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, base.ParseErrorResponse(res)
+	}
+
+	return readResponseStream(ctx, res), nil
+}
+
+func (c *Client) doStoredResponseRequest(ctx context.Context, method, path string) (*responses2.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.opts.BaseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.authHeaders() {
+		req.Header.Set(k, v)
+	}
+
+	res, err := c.opts.Transport.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, base.ParseErrorResponse(res)
+	}
+
+	var openAiResponse *openai_responses2.Response
+	if err := utils.DecodeJSON(res.Body, &openAiResponse); err != nil {
+		return nil, err
+	}
+
+	return openAiResponse.ToNativeResponse(), nil
 }
 
 func (c *Client) NewEmbedding(ctx context.Context, inp *embeddings2.Request) (*embeddings2.Response, error) {
@@ -172,7 +257,7 @@ func (c *Client) NewEmbedding(ctx context.Context, inp *embeddings2.Request) (*e
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.opts.ApiKey)
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -207,7 +292,7 @@ func (c *Client) NewChatCompletion(ctx context.Context, inp *chat_completion2.Re
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.opts.ApiKey)
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -252,7 +337,7 @@ func (c *Client) NewStreamingChatCompletion(ctx context.Context, inp *chat_compl
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.opts.ApiKey)
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -321,7 +406,7 @@ func (c *Client) NewSpeech(ctx context.Context, in *speech2.Request) (*speech2.R
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.opts.ApiKey)
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -385,7 +470,7 @@ func (c *Client) NewStreamingSpeech(ctx context.Context, in *speech2.Request) (c
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.opts.ApiKey)
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -458,8 +543,20 @@ func (c *Client) NewTranscription(ctx context.Context, in *transcription2.Reques
 		return nil, err
 	}
 
+	// Diarization needs the diarize model and its own response format,
+	// which in turn needs a chunking strategy for audio over 30s.
+	diarize := in.Diarize != nil && *in.Diarize
+	model := in.Model
+	if model == "" && diarize {
+		model = "gpt-4o-transcribe-diarize"
+	}
+	responseFormat := in.ResponseFormat
+	if responseFormat == nil && diarize {
+		responseFormat = utils.Ptr("diarized_json")
+	}
+
 	// Add model field
-	if err = writer.WriteField("model", in.Model); err != nil {
+	if err = writer.WriteField("model", model); err != nil {
 		return nil, err
 	}
 
@@ -476,10 +573,15 @@ func (c *Client) NewTranscription(ctx context.Context, in *transcription2.Reques
 		}
 	}
 
-	if in.ResponseFormat != nil {
-		if err = writer.WriteField("response_format", *in.ResponseFormat); err != nil {
+	if responseFormat != nil {
+		if err = writer.WriteField("response_format", *responseFormat); err != nil {
 			return nil, err
 		}
+		if *responseFormat == "diarized_json" {
+			if err = writer.WriteField("chunking_strategy", "auto"); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	if in.Temperature != nil {
@@ -506,7 +608,7 @@ func (c *Client) NewTranscription(ctx context.Context, in *transcription2.Reques
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 	req.Header.Set("Authorization", "Bearer "+c.opts.ApiKey)
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -535,6 +637,115 @@ func (c *Client) NewTranscription(ctx context.Context, in *transcription2.Reques
 	return openAiResponse.ToNativeResponse(), nil
 }
 
+// NewStreamingTranscription streams the transcript of in's audio as
+// gpt-4o-transcribe produces it. /audio/transcriptions takes a complete file,
+// so the input is uploaded as WAV as it is read, and the first partial comes
+// back once it ends.
+func (c *Client) NewStreamingTranscription(ctx context.Context, in *transcription2.Request) (chan *transcription2.ResponseChunk, error) {
+	sampleRate := in.StreamSampleRate()
+
+	model := in.Model
+	if model == "" {
+		model = "gpt-4o-transcribe"
+	}
+	fields := [][2]string{{"model", model}, {"stream", "true"}}
+	if in.Language != nil {
+		fields = append(fields, [2]string{"language", *in.Language})
+	}
+	if in.Prompt != nil {
+		fields = append(fields, [2]string{"prompt", *in.Prompt})
+	}
+	if in.Temperature != nil {
+		fields = append(fields, [2]string{"temperature", strconv.FormatFloat(*in.Temperature, 'f', -1, 64)})
+	}
+
+	// The fields go first, so the file can be the rest of the body.
+	body, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	var sent atomic.Int64
+	go func() {
+		for _, f := range fields {
+			if err := writer.WriteField(f[0], f[1]); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		filePart, err := writer.CreateFormFile("file", "audio.wav")
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		n, err := in.StreamWAV(ctx, filePart)
+		sent.Store(int64(n))
+		if err == nil {
+			err = writer.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.opts.BaseURL+"/audio/transcriptions", body)
+	if err != nil {
+		_ = body.Close()
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+c.opts.ApiKey)
+	for k, v := range c.opts.Headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := c.opts.Transport.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, base.ParseErrorResponse(res)
+	}
+
+	out := make(chan *transcription2.ResponseChunk)
+
+	go func() {
+		defer res.Body.Close()
+		defer close(out)
+
+		reader := bufio.NewReader(res.Body)
+		converter := openai_transcription.StreamConverter{}
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					out <- &transcription2.ResponseChunk{OfError: &transcription2.ChunkError[transcription2.ChunkTypeError]{Message: err.Error()}}
+				}
+				return
+			}
+
+			line = strings.TrimRight(line, "\r\n")
+			if line == "data: [DONE]" {
+				return
+			}
+			if !strings.HasPrefix(line, "data:") {
+				continue
+			}
+
+			chunk := &openai_transcription.ResponseChunk{}
+			if err = sonic.Unmarshal([]byte(strings.TrimPrefix(line, "data:")), chunk); err != nil {
+				slog.WarnContext(ctx, "unable to unmarshal transcription response chunk", slog.String("data", line), slog.Any("error", err))
+				continue
+			}
+			// The upload has ended by the time the transcript comes back.
+			converter.Duration = transcription2.PCMDuration(int(sent.Load()), sampleRate)
+			for _, c := range converter.Convert(chunk) {
+				out <- c
+			}
+		}
+	}()
+
+	return out, nil
+}
+
 func (c *Client) NewImageGeneration(ctx context.Context, in *image_generation2.Request) (*image_generation2.Response, error) {
 	openAiRequest := openai_image_generation.NativeRequestToRequest(in)
 
@@ -551,7 +762,7 @@ func (c *Client) NewImageGeneration(ctx context.Context, in *image_generation2.R
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.opts.ApiKey)
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -673,7 +884,7 @@ func (c *Client) NewImageEdit(ctx context.Context, in *image_edit2.Request) (*im
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 	req.Header.Set("Authorization", "Bearer "+c.opts.ApiKey)
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -705,3 +916,163 @@ func (c *Client) NewImageEdit(ctx context.Context, in *image_edit2.Request) (*im
 
 	return openAiEditResponse.ToNativeResponse(), nil
 }
+
+// NewRealtimeSession opens a OpenAI Realtime session.
+func (c *Client) NewRealtimeSession(ctx context.Context, cfg *realtime.SessionConfig) (realtime.Session, error) {
+	return openai_realtime.Dial(ctx, &openai_realtime.Options{
+		BaseURL: c.opts.BaseURL,
+		ApiKey:  c.opts.ApiKey,
+		Headers: c.opts.Headers,
+	}, cfg)
+}
+
+// NewVideoGeneration starts a Sora job. The reference image, if any, must
+// match the requested size.
+func (c *Client) NewVideoGeneration(ctx context.Context, in *video_generation2.Request) (*video_generation2.Response, error) {
+	openAiRequest := openai_video_generation.NativeRequestToRequest(in)
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	fields := [][2]string{
+		{"model", openAiRequest.Model},
+		{"prompt", openAiRequest.Prompt},
+		{"seconds", openAiRequest.Seconds},
+		{"size", openAiRequest.Size},
+	}
+	for _, f := range fields {
+		if f[1] == "" {
+			continue
+		}
+		if err := writer.WriteField(f[0], f[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	if in.Image != nil {
+		contentType := in.Image.MimeType
+		if contentType == "" {
+			contentType = http.DetectContentType(in.Image.Data)
+		}
+		filename := "reference"
+		if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+			filename += exts[0]
+		}
+
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="input_reference"; filename="%s"`, filename))
+		header.Set("Content-Type", contentType)
+
+		filePart, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = filePart.Write(in.Image.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	res, err := c.doVideoRequest(ctx, http.MethodPost, "/videos", &buf, writer.FormDataContentType())
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var video *openai_video_generation.Video
+	if err = utils.DecodeJSON(res.Body, &video); err != nil {
+		return nil, err
+	}
+
+	return video.ToNativeResponse(), nil
+}
+
+func (c *Client) GetVideoGeneration(ctx context.Context, in *video_generation2.GetRequest) (*video_generation2.Response, error) {
+	res, err := c.doVideoRequest(ctx, http.MethodGet, "/videos/"+url.PathEscape(in.ID), nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var video *openai_video_generation.Video
+	if err = utils.DecodeJSON(res.Body, &video); err != nil {
+		return nil, err
+	}
+
+	return video.ToNativeResponse(), nil
+}
+
+func (c *Client) DownloadVideoGeneration(ctx context.Context, in *video_generation2.DownloadRequest) (*video_generation2.Content, error) {
+	res, err := c.doVideoRequest(ctx, http.MethodGet, "/videos/"+url.PathEscape(in.ID)+"/content", nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := res.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "video/mp4"
+	}
+
+	return &video_generation2.Content{Data: data, MimeType: contentType}, nil
+}
+
+// doVideoRequest sends a request to the videos API and turns an error status
+// into an error.
+func (c *Client) doVideoRequest(ctx context.Context, method, path string, body io.Reader, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.opts.BaseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.opts.ApiKey)
+	for k, v := range c.opts.Headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := c.opts.Transport.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, base.ParseErrorResponse(res)
+	}
+
+	return res, nil
+}
+
+// ListModels lists the models available to the API key. The listing carries
+// no capabilities, so they are inferred from the model ids.
+func (c *Client) ListModels(ctx context.Context) ([]models.Model, error) {
+	var list openai_models.ListResponse
+	if err := base.GetJSON(ctx, c.opts.Transport, c.opts.BaseURL+"/models", c.authHeaders(), &list); err != nil {
+		return nil, err
+	}
+
+	return list.ToNative(), nil
+}
+
+// ListVoices lists the built-in speech voices. OpenAI has no voice listing
+// endpoint, so this is a fixed list that makes no request.
+func (c *Client) ListVoices(ctx context.Context) ([]models.Voice, error) {
+	return slices.Clone(openai_models.Voices), nil
+}
+
+func (c *Client) authHeaders() map[string]string {
+	headers := map[string]string{"Authorization": "Bearer " + c.opts.ApiKey}
+	maps.Copy(headers, c.opts.Headers)
+	return headers
+}
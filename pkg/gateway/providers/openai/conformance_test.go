@@ -0,0 +1,24 @@
+package openai_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/openai"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/providertest"
+)
+
+func TestConformance(t *testing.T) {
+	suite := providertest.Suite{
+		Provider: "openai",
+		// A reasoning model, so the reasoning scenario's parameters are
+		// accepted.
+		Model:     "gpt-5-mini",
+		APIKeyEnv: "OPENAI_API_KEY",
+		NewProvider: func(apiKey string, transport *http.Client) llm.Provider {
+			return openai.NewClient(&openai.ClientOptions{ApiKey: apiKey, Transport: transport})
+		},
+	}
+	suite.Run(t)
+}
@@ -0,0 +1,73 @@
+package openai_models
+
+import (
+	"time"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/models"
+)
+
+// ListResponse is the response of GET /models. OpenRouter and Ollama serve
+// the same shape; OpenRouter adds a name, description and context length.
+type ListResponse struct {
+	Data []Model `json:"data"`
+}
+
+type Model struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+
+	Name          string       `json:"name,omitempty"`
+	Description   string       `json:"description,omitempty"`
+	ContextLength int          `json:"context_length,omitempty"`
+	TopProvider   *TopProvider `json:"top_provider,omitempty"`
+}
+
+type TopProvider struct {
+	MaxCompletionTokens *int `json:"max_completion_tokens,omitempty"`
+}
+
+func (r *ListResponse) ToNative() []models.Model {
+	out := make([]models.Model, 0, len(r.Data))
+	for _, m := range r.Data {
+		model := models.Model{
+			ID:           m.ID,
+			DisplayName:  m.Name,
+			Description:  m.Description,
+			Capabilities: models.CapabilitiesFromID(m.ID),
+			OwnedBy:      m.OwnedBy,
+		}
+		if m.Created > 0 {
+			created := time.Unix(m.Created, 0).UTC()
+			model.CreatedAt = &created
+		}
+		if m.ContextLength > 0 {
+			contextLength := m.ContextLength
+			model.ContextWindow = &contextLength
+		}
+		if m.TopProvider != nil {
+			model.MaxOutputTokens = m.TopProvider.MaxCompletionTokens
+		}
+		out = append(out, model)
+	}
+	return out
+}
+
+// The speech API has no voice listing; these are the documented voices.
+// The newer ones are only available on gpt-4o-mini-tts.
+var Voices = []models.Voice{
+	{ID: "alloy", Name: "Alloy"},
+	{ID: "ash", Name: "Ash"},
+	{ID: "ballad", Name: "Ballad", Models: []string{"gpt-4o-mini-tts"}},
+	{ID: "coral", Name: "Coral"},
+	{ID: "echo", Name: "Echo"},
+	{ID: "fable", Name: "Fable"},
+	{ID: "nova", Name: "Nova"},
+	{ID: "onyx", Name: "Onyx"},
+	{ID: "sage", Name: "Sage"},
+	{ID: "shimmer", Name: "Shimmer"},
+	{ID: "verse", Name: "Verse", Models: []string{"gpt-4o-mini-tts"}},
+	{ID: "marin", Name: "Marin", Models: []string{"gpt-4o-mini-tts"}},
+	{ID: "cedar", Name: "Cedar", Models: []string{"gpt-4o-mini-tts"}},
+}
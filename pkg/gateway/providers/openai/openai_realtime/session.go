@@ -0,0 +1,498 @@
+package openai_realtime
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/bytedance/sonic"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/realtime"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/base"
+)
+
+const defaultSampleRate = 24000
+
+type Options struct {
+	// https://api.openai.com/v1
+	BaseURL string
+	ApiKey  string
+	Headers map[string]string
+}
+
+// Session is a realtime.Session over the OpenAI Realtime API.
+type Session struct {
+	conn   *base.WebSocketConn
+	events chan *realtime.Event
+
+	mu sync.Mutex
+	// activeResponse is the response being generated, if any.
+	activeResponse string
+	// lastAudioItem is the item the most recent audio belonged to; it is what
+	// Interrupt truncates.
+	lastAudioItem string
+	// interrupted holds responses whose remaining output is dropped.
+	interrupted map[string]bool
+
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	// emitMu is held while sending an event and while closing events, so
+	// an event emitted from the caller's goroutine — by Interrupt — never
+	// lands on a closed channel.
+	emitMu       sync.Mutex
+	eventsClosed bool
+}
+
+var _ realtime.Session = (*Session)(nil)
+
+// Dial opens a session and configures it from cfg.
+func Dial(ctx context.Context, opts *Options, cfg *realtime.SessionConfig) (*Session, error) {
+	wsURL := base.WebSocketURL(opts.BaseURL) + "/realtime?model=" + url.QueryEscape(cfg.Model)
+
+	headers := http.Header{}
+	headers.Set("Authorization", "Bearer "+opts.ApiKey)
+	for k, v := range opts.Headers {
+		headers.Set(k, v)
+	}
+
+	conn, err := base.DialWebSocket(ctx, wsURL, headers)
+	if err != nil {
+		return nil, fmt.Errorf("openai realtime: %w", err)
+	}
+
+	s := &Session{
+		conn:        conn,
+		events:      make(chan *realtime.Event, 64),
+		interrupted: map[string]bool{},
+		closed:      make(chan struct{}),
+	}
+
+	if err := conn.WriteJSON(ctx, map[string]any{
+		"type":    "session.update",
+		"session": sessionFromConfig(cfg),
+	}); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("openai realtime: %w", err)
+	}
+
+	go s.readLoop()
+
+	return s, nil
+}
+
+func (s *Session) SendAudio(ctx context.Context, audio []byte) error {
+	return s.send(ctx, map[string]any{
+		"type":  "input_audio_buffer.append",
+		"audio": base64.StdEncoding.EncodeToString(audio),
+	})
+}
+
+func (s *Session) CommitAudio(ctx context.Context) error {
+	if err := s.send(ctx, map[string]any{"type": "input_audio_buffer.commit"}); err != nil {
+		return err
+	}
+	return s.CreateResponse(ctx)
+}
+
+func (s *Session) SendText(ctx context.Context, text string) error {
+	if err := s.send(ctx, map[string]any{
+		"type": "conversation.item.create",
+		"item": map[string]any{
+			"type": "message",
+			"role": "user",
+			"content": []map[string]any{
+				{"type": "input_text", "text": text},
+			},
+		},
+	}); err != nil {
+		return err
+	}
+	return s.CreateResponse(ctx)
+}
+
+func (s *Session) SendFunctionResult(ctx context.Context, callID string, output string) error {
+	return s.send(ctx, map[string]any{
+		"type": "conversation.item.create",
+		"item": map[string]any{
+			"type":    "function_call_output",
+			"call_id": callID,
+			"output":  output,
+		},
+	})
+}
+
+func (s *Session) CreateResponse(ctx context.Context) error {
+	return s.send(ctx, map[string]any{"type": "response.create"})
+}
+
+func (s *Session) Interrupt(ctx context.Context, audioPlayedMs int) error {
+	s.mu.Lock()
+	responseID := s.activeResponse
+	itemID := s.lastAudioItem
+	alreadyInterrupted := responseID != "" && s.interrupted[responseID]
+	if responseID != "" {
+		s.interrupted[responseID] = true
+	}
+	s.lastAudioItem = ""
+	s.mu.Unlock()
+
+	if responseID != "" {
+		if err := s.send(ctx, map[string]any{"type": "response.cancel"}); err != nil {
+			return err
+		}
+		if !alreadyInterrupted {
+			s.emit(&realtime.Event{Type: realtime.EventInterrupted, ResponseID: responseID, ItemID: itemID})
+		}
+	}
+
+	// Trim the assistant's message to what was heard, so the model does not
+	// believe it said the rest.
+	if itemID != "" {
+		return s.send(ctx, map[string]any{
+			"type":          "conversation.item.truncate",
+			"item_id":       itemID,
+			"content_index": 0,
+			"audio_end_ms":  max(audioPlayedMs, 0),
+		})
+	}
+	return nil
+}
+
+func (s *Session) Events() <-chan *realtime.Event {
+	return s.events
+}
+
+func (s *Session) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		err = s.conn.Close()
+	})
+	return err
+}
+
+func (s *Session) send(ctx context.Context, v any) error {
+	select {
+	case <-s.closed:
+		return realtime.ErrSessionClosed
+	default:
+	}
+	return s.conn.WriteJSON(ctx, v)
+}
+
+func (s *Session) emit(ev *realtime.Event) {
+	s.emitMu.Lock()
+	defer s.emitMu.Unlock()
+	if s.eventsClosed {
+		return
+	}
+	select {
+	case s.events <- ev:
+	case <-s.closed:
+	}
+}
+
+// closeEvents closes events once the session is closed, which unblocks any
+// emit waiting on a full channel.
+func (s *Session) closeEvents() {
+	s.emitMu.Lock()
+	defer s.emitMu.Unlock()
+	s.eventsClosed = true
+	close(s.events)
+}
+
+func (s *Session) readLoop() {
+	defer s.closeEvents()
+	defer s.Close()
+
+	for {
+		msg, err := s.conn.Read()
+		if err != nil {
+			select {
+			case <-s.closed:
+			default:
+				s.emit(&realtime.Event{Type: realtime.EventError, Error: &realtime.Error{Code: "connection_closed", Message: err.Error()}})
+			}
+			return
+		}
+
+		var ev serverEvent
+		if err := sonic.Unmarshal(msg, &ev); err != nil {
+			slog.Warn("openai realtime: unreadable server event", slog.Any("error", err))
+			continue
+		}
+
+		for _, out := range s.translate(&ev) {
+			s.emit(out)
+		}
+	}
+}
+
+// translate maps one server event onto the SDK's event model, tracking the
+// state barge-in needs on the way. It returns nil for events with no SDK
+// counterpart and for output of interrupted responses.
+func (s *Session) translate(ev *serverEvent) []*realtime.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dropped := ev.ResponseID != "" && s.interrupted[ev.ResponseID]
+
+	switch ev.Type {
+	case "session.created":
+		return []*realtime.Event{{Type: realtime.EventSessionCreated}}
+
+	case "input_audio_buffer.speech_started":
+		// With server VAD, the user talking over the model cancels the
+		// response on the server; tell the caller to stop playback too.
+		started := &realtime.Event{Type: realtime.EventSpeechStarted, ItemID: ev.ItemID}
+		if s.activeResponse == "" || s.interrupted[s.activeResponse] {
+			return []*realtime.Event{started}
+		}
+		s.interrupted[s.activeResponse] = true
+		return []*realtime.Event{
+			{Type: realtime.EventInterrupted, ResponseID: s.activeResponse, ItemID: s.lastAudioItem},
+			started,
+		}
+
+	case "input_audio_buffer.speech_stopped":
+		return []*realtime.Event{{Type: realtime.EventSpeechStopped, ItemID: ev.ItemID}}
+
+	case "conversation.item.input_audio_transcription.completed":
+		return []*realtime.Event{{Type: realtime.EventInputTranscript, ItemID: ev.ItemID, Text: ev.Transcript}}
+
+	case "response.created":
+		if ev.Response != nil {
+			s.activeResponse = ev.Response.ID
+		}
+		return nil
+
+	case "response.output_audio.delta", "response.audio.delta":
+		if dropped {
+			return nil
+		}
+		audio, err := base64.StdEncoding.DecodeString(ev.Delta)
+		if err != nil {
+			return []*realtime.Event{{Type: realtime.EventError, Error: &realtime.Error{Code: "invalid_audio", Message: err.Error()}}}
+		}
+		s.lastAudioItem = ev.ItemID
+		return []*realtime.Event{{Type: realtime.EventAudioDelta, ResponseID: ev.ResponseID, ItemID: ev.ItemID, Audio: audio}}
+
+	case "response.output_audio.done", "response.audio.done":
+		if dropped {
+			return nil
+		}
+		return []*realtime.Event{{Type: realtime.EventAudioDone, ResponseID: ev.ResponseID, ItemID: ev.ItemID}}
+
+	case "response.output_audio_transcript.delta", "response.audio_transcript.delta":
+		if dropped {
+			return nil
+		}
+		return []*realtime.Event{{Type: realtime.EventOutputTranscriptDelta, ResponseID: ev.ResponseID, ItemID: ev.ItemID, Text: ev.Delta}}
+
+	case "response.output_text.delta", "response.text.delta":
+		if dropped {
+			return nil
+		}
+		return []*realtime.Event{{Type: realtime.EventTextDelta, ResponseID: ev.ResponseID, ItemID: ev.ItemID, Text: ev.Delta}}
+
+	case "response.output_text.done", "response.text.done":
+		if dropped {
+			return nil
+		}
+		return []*realtime.Event{{Type: realtime.EventTextDone, ResponseID: ev.ResponseID, ItemID: ev.ItemID, Text: ev.Text}}
+
+	case "response.output_item.done":
+		if ev.Item == nil || ev.Item.Type != "function_call" || dropped {
+			return nil
+		}
+		return []*realtime.Event{{
+			Type:       realtime.EventFunctionCall,
+			ResponseID: ev.ResponseID,
+			ItemID:     ev.Item.ID,
+			FunctionCall: &realtime.FunctionCall{
+				CallID:    ev.Item.CallID,
+				Name:      ev.Item.Name,
+				Arguments: ev.Item.Arguments,
+			},
+		}}
+
+	case "response.done":
+		out := &realtime.Event{Type: realtime.EventResponseDone}
+		if ev.Response != nil {
+			out.ResponseID = ev.Response.ID
+			if u := ev.Response.Usage; u != nil {
+				out.Usage = &realtime.Usage{InputTokens: u.InputTokens, OutputTokens: u.OutputTokens, TotalTokens: u.TotalTokens}
+			}
+			delete(s.interrupted, ev.Response.ID)
+			if s.activeResponse == ev.Response.ID {
+				s.activeResponse = ""
+			}
+		}
+		return []*realtime.Event{out}
+
+	case "error":
+		if ev.Error == nil {
+			return nil
+		}
+		// Cancelling a response that already finished is a benign race
+		// between Interrupt and the server.
+		if ev.Error.Code == "response_cancel_not_active" {
+			return nil
+		}
+		return []*realtime.Event{{Type: realtime.EventError, Error: &realtime.Error{Code: ev.Error.Code, Message: ev.Error.Message}}}
+	}
+
+	return nil
+}
+
+type serverEvent struct {
+	Type       string `json:"type"`
+	ResponseID string `json:"response_id"`
+	ItemID     string `json:"item_id"`
+	Delta      string `json:"delta"`
+	Text       string `json:"text"`
+	Transcript string `json:"transcript"`
+
+	Item *struct {
+		ID        string `json:"id"`
+		Type      string `json:"type"`
+		CallID    string `json:"call_id"`
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"item"`
+
+	Response *struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+		Usage  *struct {
+			InputTokens  int64 `json:"input_tokens"`
+			OutputTokens int64 `json:"output_tokens"`
+			TotalTokens  int64 `json:"total_tokens"`
+		} `json:"usage"`
+	} `json:"response"`
+
+	Error *struct {
+		Type    string `json:"type"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type sessionUpdate struct {
+	Type             string        `json:"type"`
+	Instructions     string        `json:"instructions,omitempty"`
+	OutputModalities []string      `json:"output_modalities,omitempty"`
+	Audio            sessionAudio  `json:"audio"`
+	Tools            []sessionTool `json:"tools,omitempty"`
+	ToolChoice       string        `json:"tool_choice,omitempty"`
+	Temperature      *float64      `json:"temperature,omitempty"`
+}
+
+type sessionAudio struct {
+	Input  sessionAudioInput  `json:"input"`
+	Output sessionAudioOutput `json:"output"`
+}
+
+type sessionAudioInput struct {
+	Format audioFormat `json:"format"`
+	// TurnDetection is omitted to keep the server default, or set to JSON
+	// null to turn VAD off.
+	TurnDetection any                 `json:"turn_detection,omitempty"`
+	Transcription *inputTranscription `json:"transcription,omitempty"`
+}
+
+type sessionAudioOutput struct {
+	Format audioFormat `json:"format"`
+	Voice  string      `json:"voice,omitempty"`
+}
+
+type audioFormat struct {
+	Type string `json:"type"`
+	Rate int    `json:"rate"`
+}
+
+type turnDetection struct {
+	Type              string   `json:"type"`
+	Threshold         *float64 `json:"threshold,omitempty"`
+	PrefixPaddingMs   *int     `json:"prefix_padding_ms,omitempty"`
+	SilenceDurationMs *int     `json:"silence_duration_ms,omitempty"`
+	InterruptResponse bool     `json:"interrupt_response"`
+	CreateResponse    bool     `json:"create_response"`
+}
+
+type inputTranscription struct {
+	Model string `json:"model"`
+}
+
+type sessionTool struct {
+	Type        string         `json:"type"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+}
+
+func sessionFromConfig(cfg *realtime.SessionConfig) *sessionUpdate {
+	out := &sessionUpdate{
+		Type:         "realtime",
+		Instructions: cfg.Instructions,
+		Temperature:  cfg.Temperature,
+		Audio: sessionAudio{
+			Input:  sessionAudioInput{Format: pcmFormat(cfg.InputAudio)},
+			Output: sessionAudioOutput{Format: pcmFormat(cfg.OutputAudio), Voice: cfg.Voice},
+		},
+	}
+
+	for _, m := range cfg.Modalities {
+		out.OutputModalities = append(out.OutputModalities, string(m))
+	}
+
+	if td := cfg.TurnDetection; td != nil {
+		if td.Disabled {
+			out.Audio.Input.TurnDetection = json.RawMessage("null")
+		} else {
+			out.Audio.Input.TurnDetection = &turnDetection{
+				Type:              "server_vad",
+				Threshold:         td.Threshold,
+				PrefixPaddingMs:   td.PrefixPaddingMs,
+				SilenceDurationMs: td.SilenceDurationMs,
+				InterruptResponse: true,
+				CreateResponse:    true,
+			}
+		}
+	}
+
+	if it := cfg.InputTranscription; it != nil {
+		model := it.Model
+		if model == "" {
+			model = "gpt-4o-mini-transcribe"
+		}
+		out.Audio.Input.Transcription = &inputTranscription{Model: model}
+	}
+
+	for _, t := range cfg.Tools {
+		out.Tools = append(out.Tools, sessionTool{
+			Type:        "function",
+			Name:        t.Name,
+			Description: t.Description,
+			Parameters:  t.Parameters,
+		})
+	}
+	if len(out.Tools) > 0 {
+		out.ToolChoice = "auto"
+	}
+
+	return out
+}
+
+func pcmFormat(f *realtime.AudioFormat) audioFormat {
+	rate := defaultSampleRate
+	if f != nil && f.SampleRate > 0 {
+		rate = f.SampleRate
+	}
+	return audioFormat{Type: "audio/pcm", Rate: rate}
+}
@@ -0,0 +1,196 @@
+package openai_realtime
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/realtime"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/realtime/realtimetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dialStandIn(t *testing.T, cfg *realtime.SessionConfig) (*Session, *realtimetest.Conn) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	srv := realtimetest.NewServer()
+	t.Cleanup(srv.Close)
+
+	session, err := Dial(ctx, &Options{BaseURL: srv.URL + "/v1", ApiKey: "sk-test"}, cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+
+	conn, err := srv.Accept(ctx)
+	require.NoError(t, err)
+	return session, conn
+}
+
+func nextEvent(t *testing.T, s realtime.Session) *realtime.Event {
+	t.Helper()
+	select {
+	case ev, ok := <-s.Events():
+		require.True(t, ok, "events closed")
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event")
+		return nil
+	}
+}
+
+func TestSession_ConfiguresAndNormalizesEvents(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	session, conn := dialStandIn(t, &realtime.SessionConfig{
+		Model:         "gpt-realtime",
+		Instructions:  "Be brief.",
+		Voice:         "marin",
+		TurnDetection: &realtime.TurnDetection{Disabled: true},
+		Tools:         []realtime.Tool{{Name: "get_weather", Parameters: map[string]any{"type": "object"}}},
+	})
+
+	assert.Equal(t, "/v1/realtime", conn.Request.URL.Path)
+	assert.Equal(t, "gpt-realtime", conn.Request.URL.Query().Get("model"))
+	assert.Equal(t, "Bearer sk-test", conn.Request.Header.Get("Authorization"))
+
+	update, err := conn.ReceiveType(ctx, "type", "session.update")
+	require.NoError(t, err)
+	cfg := update["session"].(map[string]any)
+	assert.Equal(t, "Be brief.", cfg["instructions"])
+	audio := cfg["audio"].(map[string]any)
+	input := audio["input"].(map[string]any)
+	assert.Contains(t, input, "turn_detection")
+	assert.Nil(t, input["turn_detection"], "disabled VAD is sent as null")
+	assert.Equal(t, "marin", audio["output"].(map[string]any)["voice"])
+	assert.Equal(t, "get_weather", cfg["tools"].([]any)[0].(map[string]any)["name"])
+
+	require.NoError(t, session.SendAudio(ctx, []byte{1, 2, 3}))
+	appended, err := conn.ReceiveType(ctx, "type", "input_audio_buffer.append")
+	require.NoError(t, err)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte{1, 2, 3}), appended["audio"])
+
+	require.NoError(t, session.CommitAudio(ctx))
+	_, err = conn.ReceiveType(ctx, "type", "input_audio_buffer.commit")
+	require.NoError(t, err)
+	_, err = conn.ReceiveType(ctx, "type", "response.create")
+	require.NoError(t, err)
+
+	for _, msg := range []map[string]any{
+		{"type": "session.created"},
+		{"type": "response.created", "response": map[string]any{"id": "resp_1"}},
+		{"type": "response.output_audio.delta", "response_id": "resp_1", "item_id": "item_1", "delta": base64.StdEncoding.EncodeToString([]byte("pcm"))},
+		{"type": "response.output_audio_transcript.delta", "response_id": "resp_1", "item_id": "item_1", "delta": "Sunny"},
+		{"type": "response.output_item.done", "response_id": "resp_1", "item": map[string]any{
+			"id": "item_2", "type": "function_call", "call_id": "call_1", "name": "get_weather", "arguments": `{"city":"Paris"}`,
+		}},
+		{"type": "response.done", "response": map[string]any{"id": "resp_1", "usage": map[string]any{"input_tokens": 10, "output_tokens": 4, "total_tokens": 14}}},
+	} {
+		require.NoError(t, conn.Send(msg))
+	}
+
+	assert.Equal(t, realtime.EventSessionCreated, nextEvent(t, session).Type)
+
+	ev := nextEvent(t, session)
+	assert.Equal(t, realtime.EventAudioDelta, ev.Type)
+	assert.Equal(t, []byte("pcm"), ev.Audio)
+	assert.Equal(t, "resp_1", ev.ResponseID)
+
+	ev = nextEvent(t, session)
+	assert.Equal(t, realtime.EventOutputTranscriptDelta, ev.Type)
+	assert.Equal(t, "Sunny", ev.Text)
+
+	ev = nextEvent(t, session)
+	assert.Equal(t, realtime.EventFunctionCall, ev.Type)
+	assert.Equal(t, &realtime.FunctionCall{CallID: "call_1", Name: "get_weather", Arguments: `{"city":"Paris"}`}, ev.FunctionCall)
+
+	ev = nextEvent(t, session)
+	assert.Equal(t, realtime.EventResponseDone, ev.Type)
+	assert.Equal(t, &realtime.Usage{InputTokens: 10, OutputTokens: 4, TotalTokens: 14}, ev.Usage)
+
+	require.NoError(t, session.SendFunctionResult(ctx, "call_1", "18C"))
+	result, err := conn.ReceiveType(ctx, "type", "conversation.item.create")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"type": "function_call_output", "call_id": "call_1", "output": "18C"}, result["item"])
+}
+
+func TestSession_BargeInDropsInterruptedAudio(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	session, conn := dialStandIn(t, &realtime.SessionConfig{Model: "gpt-realtime"})
+	audio := base64.StdEncoding.EncodeToString([]byte("pcm"))
+
+	for _, msg := range []map[string]any{
+		{"type": "response.created", "response": map[string]any{"id": "resp_1"}},
+		{"type": "response.output_audio.delta", "response_id": "resp_1", "item_id": "item_1", "delta": audio},
+		// The user starts talking over the model.
+		{"type": "input_audio_buffer.speech_started", "item_id": "item_user"},
+		{"type": "response.output_audio.delta", "response_id": "resp_1", "item_id": "item_1", "delta": audio},
+		{"type": "response.done", "response": map[string]any{"id": "resp_1", "status": "cancelled"}},
+	} {
+		require.NoError(t, conn.Send(msg))
+	}
+
+	var types []realtime.EventType
+	for range 4 {
+		ev := nextEvent(t, session)
+		types = append(types, ev.Type)
+		if ev.Type == realtime.EventInterrupted {
+			assert.Equal(t, "resp_1", ev.ResponseID)
+			assert.Equal(t, "item_1", ev.ItemID)
+		}
+	}
+	assert.Equal(t, []realtime.EventType{
+		realtime.EventAudioDelta,
+		realtime.EventInterrupted,
+		realtime.EventSpeechStarted,
+		realtime.EventResponseDone,
+	}, types, "audio after the barge-in is dropped")
+
+	// Interrupt trims the assistant's item to what was played.
+	require.NoError(t, conn.Send(map[string]any{"type": "response.created", "response": map[string]any{"id": "resp_2"}}))
+	require.NoError(t, conn.Send(map[string]any{"type": "response.output_audio.delta", "response_id": "resp_2", "item_id": "item_2", "delta": audio}))
+	assert.Equal(t, realtime.EventAudioDelta, nextEvent(t, session).Type)
+
+	require.NoError(t, session.Interrupt(ctx, 320))
+	_, err := conn.ReceiveType(ctx, "type", "response.cancel")
+	require.NoError(t, err)
+	truncate, err := conn.ReceiveType(ctx, "type", "conversation.item.truncate")
+	require.NoError(t, err)
+	assert.Equal(t, "item_2", truncate["item_id"])
+	assert.EqualValues(t, 320, truncate["audio_end_ms"])
+
+	ev := nextEvent(t, session)
+	assert.Equal(t, realtime.EventInterrupted, ev.Type)
+	assert.Equal(t, "resp_2", ev.ResponseID)
+}
+
+// Interrupt emits from the caller's goroutine; once the socket has dropped
+// and events is closed, it must not send on it.
+func TestSession_InterruptAfterDisconnect(t *testing.T) {
+	session, conn := dialStandIn(t, &realtime.SessionConfig{Model: "gpt-realtime"})
+	audio := base64.StdEncoding.EncodeToString([]byte("pcm"))
+
+	require.NoError(t, conn.Send(map[string]any{"type": "response.created", "response": map[string]any{"id": "resp_1"}}))
+	require.NoError(t, conn.Send(map[string]any{"type": "response.output_audio.delta", "response_id": "resp_1", "item_id": "item_1", "delta": audio}))
+	assert.Equal(t, realtime.EventAudioDelta, nextEvent(t, session).Type)
+
+	require.NoError(t, conn.Close())
+	for {
+		_, ok := <-session.Events()
+		if !ok {
+			break
+		}
+	}
+
+	var err error
+	assert.NotPanics(t, func() { err = session.Interrupt(context.Background(), 100) })
+	assert.ErrorIs(t, err, realtime.ErrSessionClosed)
+
+	// The emit a send racing the disconnect would make is dropped too.
+	assert.NotPanics(t, func() { session.emit(&realtime.Event{Type: realtime.EventInterrupted, ResponseID: "resp_1"}) })
+}
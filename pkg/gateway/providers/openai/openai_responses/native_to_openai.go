@@ -1,6 +1,8 @@
 package openai_responses
 
 import (
+	"log/slog"
+
 	responses2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
 )
 
@@ -10,10 +12,47 @@ func NativeRequestToRequest(in *responses2.Request) *Request {
 	}
 
 	req.ExtraFields = nil
+	req.Tools = nativeToolsToTools(in.Tools)
+	if in.Input.OfInputMessageList != nil {
+		req.Input.OfInputMessageList = nativeInputToInput(in.Input.OfInputMessageList)
+	}
 
 	return req
 }
 
+// nativeToolsToTools drops the tools hosted by other providers.
+func nativeToolsToTools(in []responses2.ToolUnion) []responses2.ToolUnion {
+	var out []responses2.ToolUnion
+	for _, tool := range in {
+		switch {
+		case tool.OfWebFetch != nil:
+			slog.Warn("web fetch tool is not supported for openai, skipping")
+		case tool.OfURLContext != nil:
+			slog.Warn("url context tool is not supported for openai, skipping")
+		case tool.OfGoogleMaps != nil:
+			slog.Warn("google maps tool is not supported for openai, skipping")
+		default:
+			out = append(out, tool)
+		}
+	}
+
+	return out
+}
+
+// nativeInputToInput drops the calls other providers' hosted tools left in
+// the history; OpenAI rejects items of a type it doesn't know.
+func nativeInputToInput(in responses2.InputMessageList) responses2.InputMessageList {
+	out := responses2.InputMessageList{}
+	for _, msg := range in {
+		if msg.OfWebFetchCall != nil || msg.OfURLContextCall != nil || msg.OfGoogleMapsCall != nil {
+			continue
+		}
+		out = append(out, msg)
+	}
+
+	return out
+}
+
 func NativeResponseToResponse(in *responses2.Response) *Response {
 	return &Response{
 		*in,
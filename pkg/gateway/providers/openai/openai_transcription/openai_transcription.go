@@ -1,6 +1,8 @@
 package openai_transcription
 
 import (
+	"strings"
+
 	transcription2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
 )
 
@@ -16,7 +18,8 @@ func NativeRequestToRequest(in *transcription2.Request) *Request {
 	return &Request{*in}
 }
 
-// Response represents the OpenAI transcription API response (verbose_json format)
+// Response represents the OpenAI transcription API response, in the json,
+// verbose_json or diarized_json format.
 type Response struct {
 	Task     string    `json:"task"`
 	Language string    `json:"language"`
@@ -24,6 +27,7 @@ type Response struct {
 	Text     string    `json:"text"`
 	Words    []Word    `json:"words,omitempty"`
 	Segments []Segment `json:"segments,omitempty"`
+	Usage    *Usage    `json:"usage,omitempty"`
 }
 
 type Word struct {
@@ -33,7 +37,9 @@ type Word struct {
 }
 
 type Segment struct {
-	ID               int     `json:"id"`
+	// ID is a number in verbose_json and a string ("seg_001") in
+	// diarized_json.
+	ID               any     `json:"id"`
 	Seek             int     `json:"seek"`
 	Start            float64 `json:"start"`
 	End              float64 `json:"end"`
@@ -42,16 +48,33 @@ type Segment struct {
 	AvgLogprob       float64 `json:"avg_logprob"`
 	CompressionRatio float64 `json:"compression_ratio"`
 	NoSpeechProb     float64 `json:"no_speech_prob"`
+	Speaker          string  `json:"speaker,omitempty"`
 }
 
 func (r *Response) ToNativeResponse() *transcription2.Response {
-	lang := r.Language
+	resp := &transcription2.Response{
+		Text: r.Text,
+	}
+
+	// The plain json format carries neither.
+	if r.Language != "" {
+		lang := r.Language
+		resp.Language = &lang
+	}
 	dur := r.Duration
+	if dur == 0 && len(r.Segments) > 0 {
+		dur = r.Segments[len(r.Segments)-1].End
+	}
+	if dur > 0 {
+		resp.Duration = &dur
+	}
 
-	resp := &transcription2.Response{
-		Text:     r.Text,
-		Language: &lang,
-		Duration: &dur,
+	if r.Usage != nil && r.Usage.Type != "duration" {
+		resp.Usage = &transcription2.Usage{
+			PromptTokens:     r.Usage.InputTokens,
+			CompletionTokens: r.Usage.OutputTokens,
+			TotalTokens:      r.Usage.TotalTokens,
+		}
 	}
 
 	for _, w := range r.Words {
@@ -62,9 +85,9 @@ func (r *Response) ToNativeResponse() *transcription2.Response {
 		})
 	}
 
-	for _, s := range r.Segments {
+	for i, s := range r.Segments {
 		resp.Segments = append(resp.Segments, transcription2.Segment{
-			ID:               s.ID,
+			ID:               i,
 			Seek:             s.Seek,
 			Start:            s.Start,
 			End:              s.End,
@@ -73,8 +96,85 @@ func (r *Response) ToNativeResponse() *transcription2.Response {
 			AvgLogprob:       s.AvgLogprob,
 			CompressionRatio: s.CompressionRatio,
 			NoSpeechProb:     s.NoSpeechProb,
+			Speaker:          s.Speaker,
 		})
 	}
 
 	return resp
 }
+
+// ResponseChunk is one event of a streamed transcription (stream=true):
+// transcript.text.delta, transcript.text.segment (diarizing models only) or
+// transcript.text.done.
+type ResponseChunk struct {
+	Type    string  `json:"type"`
+	Delta   string  `json:"delta,omitempty"`
+	Text    string  `json:"text,omitempty"`
+	Start   float64 `json:"start,omitempty"`
+	End     float64 `json:"end,omitempty"`
+	Speaker string  `json:"speaker,omitempty"`
+	Usage   *Usage  `json:"usage,omitempty"`
+}
+
+// Usage is billed in tokens for the gpt-4o models and in seconds of audio
+// (Type "duration") for whisper-1.
+type Usage struct {
+	Type         string  `json:"type,omitempty"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	TotalTokens  int     `json:"total_tokens"`
+	Seconds      float64 `json:"seconds,omitempty"`
+}
+
+// StreamConverter turns OpenAI's text deltas into the running partials and
+// finals of a native transcription stream. Duration is the length of the
+// audio sent; without segments, the whole transcript is one final spanning
+// it.
+type StreamConverter struct {
+	Duration float64
+
+	partial   strings.Builder
+	segmented bool
+}
+
+func (c *StreamConverter) Convert(chunk *ResponseChunk) []*transcription2.ResponseChunk {
+	switch chunk.Type {
+	case "transcript.text.delta":
+		c.partial.WriteString(chunk.Delta)
+		return []*transcription2.ResponseChunk{{OfPartial: &transcription2.ChunkPartial[transcription2.ChunkTypePartial]{
+			Text: c.partial.String(),
+		}}}
+
+	case "transcript.text.segment":
+		c.segmented = true
+		c.partial.Reset()
+		return []*transcription2.ResponseChunk{{OfFinal: &transcription2.ChunkFinal[transcription2.ChunkTypeFinal]{
+			Text:    strings.TrimSpace(chunk.Text),
+			Start:   chunk.Start,
+			End:     chunk.End,
+			Speaker: chunk.Speaker,
+		}}}
+
+	case "transcript.text.done":
+		var out []*transcription2.ResponseChunk
+		if !c.segmented {
+			out = append(out, &transcription2.ResponseChunk{OfFinal: &transcription2.ChunkFinal[transcription2.ChunkTypeFinal]{
+				Text: chunk.Text,
+				End:  c.Duration,
+			}})
+		}
+
+		duration := c.Duration
+		done := &transcription2.ChunkDone[transcription2.ChunkTypeDone]{Text: chunk.Text, Duration: &duration}
+		if chunk.Usage != nil {
+			done.Usage = &transcription2.Usage{
+				PromptTokens:     chunk.Usage.InputTokens,
+				CompletionTokens: chunk.Usage.OutputTokens,
+				TotalTokens:      chunk.Usage.TotalTokens,
+			}
+		}
+		return append(out, &transcription2.ResponseChunk{OfDone: done})
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package openai_video_generation
+
+import (
+	"strconv"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/video_generation"
+)
+
+const DefaultModel = "sora-2"
+
+// Request is the form sent to POST /videos. It is multipart, so the reference
+// image travels as the input_reference file rather than in JSON.
+type Request struct {
+	Model   string
+	Prompt  string
+	Seconds string
+	Size    string
+}
+
+func NativeRequestToRequest(in *video_generation.Request) *Request {
+	req := &Request{
+		Model:  in.Model,
+		Prompt: in.Prompt,
+		Size:   Size(in.AspectRatio, in.Resolution),
+	}
+	if req.Model == "" {
+		req.Model = DefaultModel
+	}
+	if in.DurationSeconds != nil {
+		req.Seconds = strconv.Itoa(*in.DurationSeconds)
+	}
+
+	return req
+}
+
+// Size maps an aspect ratio and resolution to one of the sizes Sora accepts:
+// 1280x720 and 720x1280, or 1792x1024 and 1024x1792 at 1080p (sora-2-pro).
+// It returns "" to leave the size to the API's default.
+func Size(aspectRatio, resolution *string) string {
+	if aspectRatio == nil && resolution == nil {
+		return ""
+	}
+
+	portrait := aspectRatio != nil && *aspectRatio == "9:16"
+	high := resolution != nil && *resolution == "1080p"
+	switch {
+	case portrait && high:
+		return "1024x1792"
+	case portrait:
+		return "720x1280"
+	case high:
+		return "1792x1024"
+	default:
+		return "1280x720"
+	}
+}
+
+// Video is the video object returned by POST /videos and GET /videos/{id}.
+type Video struct {
+	ID          string      `json:"id"`
+	Object      string      `json:"object"`
+	Model       string      `json:"model"`
+	Status      string      `json:"status"` // queued, in_progress, completed, failed
+	Progress    int         `json:"progress"`
+	CreatedAt   int64       `json:"created_at"`
+	CompletedAt *int64      `json:"completed_at,omitempty"`
+	ExpiresAt   *int64      `json:"expires_at,omitempty"`
+	Seconds     string      `json:"seconds"`
+	Size        string      `json:"size"`
+	Error       *VideoError `json:"error,omitempty"`
+}
+
+type VideoError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (v *Video) ToNativeResponse() *video_generation.Response {
+	resp := &video_generation.Response{
+		ID:     v.ID,
+		Model:  v.Model,
+		Status: video_generation.Status(v.Status),
+		RawFields: map[string]any{
+			"OpenAI": v,
+		},
+	}
+
+	progress := v.Progress
+	resp.Progress = &progress
+
+	if seconds, err := strconv.Atoi(v.Seconds); err == nil {
+		resp.DurationSeconds = &seconds
+	}
+
+	if v.Error != nil {
+		resp.Status = video_generation.StatusFailed
+		resp.Error = &video_generation.Error{
+			Code:    v.Error.Code,
+			Message: v.Error.Message,
+		}
+	}
+
+	return resp
+}
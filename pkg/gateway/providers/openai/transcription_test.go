@@ -0,0 +1,130 @@
+package openai_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStreamingTranscription(t *testing.T) {
+	fields := map[string][]string{}
+	var audio []byte
+	// firstFrame is closed once the server has read the first frame, which
+	// the test waits for before sending the second: the audio is uploaded as
+	// it is read, not after the input ends.
+	firstFrame := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/audio/transcriptions", r.URL.Path)
+		reader, err := r.MultipartReader()
+		if !assert.NoError(t, err) {
+			return
+		}
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			if part.FormName() != "file" {
+				value, _ := io.ReadAll(part)
+				fields[part.FormName()] = append(fields[part.FormName()], string(value))
+				continue
+			}
+			audio = make([]byte, 44+16000)
+			if _, err := io.ReadFull(part, audio); !assert.NoError(t, err) {
+				return
+			}
+			close(firstFrame)
+			rest, _ := io.ReadAll(part)
+			audio = append(audio, rest...)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: {\"type\":\"transcript.text.delta\",\"delta\":\"Hel\"}\n\n"+
+			"data: {\"type\":\"transcript.text.delta\",\"delta\":\"lo.\"}\n\n"+
+			"data: {\"type\":\"transcript.text.done\",\"text\":\"Hello.\",\"usage\":{\"type\":\"tokens\",\"input_tokens\":14,\"output_tokens\":3,\"total_tokens\":17}}\n\n")
+	}))
+	defer server.Close()
+
+	client := openai.NewClient(&openai.ClientOptions{BaseURL: server.URL, ApiKey: "sk-test"})
+
+	frames := make(chan []byte)
+	go func() {
+		defer close(frames)
+		frames <- make([]byte, 16000)
+		select {
+		case <-firstFrame:
+		case <-time.After(5 * time.Second):
+			t.Error("the first frame was not uploaded before the input ended")
+		}
+		frames <- make([]byte, 16000)
+	}()
+
+	out, err := client.NewStreamingTranscription(context.Background(), &transcription.Request{AudioFrames: frames})
+	require.NoError(t, err)
+
+	var chunks []*transcription.ResponseChunk
+	for c := range out {
+		chunks = append(chunks, c)
+	}
+
+	assert.Equal(t, []string{"gpt-4o-transcribe"}, fields["model"])
+	assert.Equal(t, []string{"true"}, fields["stream"])
+	assert.Equal(t, "RIFF", string(audio[:4]), "raw PCM is sent as WAV")
+	assert.Len(t, audio, 44+32000)
+
+	require.Len(t, chunks, 4)
+	assert.Equal(t, "Hel", chunks[0].OfPartial.Text)
+	assert.Equal(t, "Hello.", chunks[1].OfPartial.Text, "partials carry the text so far")
+	assert.Equal(t, "Hello.", chunks[2].OfFinal.Text)
+	assert.Equal(t, 1.0, chunks[2].OfFinal.End)
+	assert.Equal(t, &transcription.Usage{PromptTokens: 14, CompletionTokens: 3, TotalTokens: 17}, chunks[3].OfDone.Usage)
+}
+
+func TestNewTranscription_Diarized(t *testing.T) {
+	var fields map[string][]string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !assert.NoError(t, r.ParseMultipartForm(1<<20)) {
+			return
+		}
+		fields = r.MultipartForm.Value
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"task":"transcribe","duration":4.2,"text":"Hi. Hello.",`+
+			`"segments":[{"id":"seg_001","start":0.1,"end":0.6,"text":"Hi.","speaker":"A"},`+
+			`{"id":"seg_002","start":1.0,"end":4.2,"text":"Hello.","speaker":"B"}],`+
+			`"usage":{"type":"tokens","input_tokens":40,"output_tokens":5,"total_tokens":45}}`)
+	}))
+	defer server.Close()
+
+	client := openai.NewClient(&openai.ClientOptions{BaseURL: server.URL, ApiKey: "sk-test"})
+
+	diarize := true
+	resp, err := client.NewTranscription(context.Background(), &transcription.Request{
+		Audio:         []byte("audio"),
+		AudioFilename: "call.mp3",
+		Diarize:       &diarize,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"gpt-4o-transcribe-diarize"}, fields["model"])
+	assert.Equal(t, []string{"diarized_json"}, fields["response_format"])
+	assert.Equal(t, []string{"auto"}, fields["chunking_strategy"])
+
+	require.Len(t, resp.Segments, 2)
+	assert.Equal(t, transcription.Segment{ID: 1, Start: 1.0, End: 4.2, Text: "Hello.", Speaker: "B"}, resp.Segments[1])
+	assert.Equal(t, 4.2, *resp.Duration)
+	assert.Equal(t, &transcription.Usage{PromptTokens: 40, CompletionTokens: 5, TotalTokens: 45}, resp.Usage)
+}
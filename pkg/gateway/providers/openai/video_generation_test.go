@@ -0,0 +1,79 @@
+package openai_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/video_generation"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/openai"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVideoGeneration_CreatePollDownload(t *testing.T) {
+	var fields map[string][]string
+	var reference []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+
+		switch r.Method + " " + r.URL.Path {
+		case "POST /videos":
+			if !assert.NoError(t, r.ParseMultipartForm(1<<20)) {
+				return
+			}
+			fields = r.MultipartForm.Value
+			if file, _, err := r.FormFile("input_reference"); assert.NoError(t, err) {
+				reference, _ = io.ReadAll(file)
+			}
+			_, _ = io.WriteString(w, `{"id":"video_123","object":"video","model":"sora-2","status":"queued","progress":0,"seconds":"8","size":"720x1280"}`)
+		case "GET /videos/video_123":
+			_, _ = io.WriteString(w, `{"id":"video_123","object":"video","model":"sora-2","status":"completed","progress":100,"seconds":"8","size":"720x1280"}`)
+		case "GET /videos/video_123/content":
+			w.Header().Set("Content-Type", "video/mp4")
+			_, _ = io.WriteString(w, "mp4 bytes")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"error":{"message":"no such video"}}`)
+		}
+	}))
+	defer server.Close()
+
+	client := openai.NewClient(&openai.ClientOptions{BaseURL: server.URL, ApiKey: "sk-test"})
+	ctx := context.Background()
+
+	started, err := client.NewVideoGeneration(ctx, &video_generation.Request{
+		Prompt:          "a paper boat on a rainy street",
+		Image:           &video_generation.ImageInput{Data: []byte("\x89PNG\r\n\x1a\nfake")},
+		DurationSeconds: utils.Ptr(8),
+		AspectRatio:     utils.Ptr("9:16"),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"sora-2"}, fields["model"])
+	assert.Equal(t, []string{"8"}, fields["seconds"])
+	assert.Equal(t, []string{"720x1280"}, fields["size"], "aspect ratio maps to a size")
+	assert.Equal(t, "\x89PNG\r\n\x1a\nfake", string(reference))
+
+	assert.Equal(t, "video_123", started.ID)
+	assert.Equal(t, video_generation.StatusQueued, started.Status)
+	assert.False(t, started.Done())
+
+	polled, err := client.GetVideoGeneration(ctx, &video_generation.GetRequest{ID: started.ID})
+	require.NoError(t, err)
+	assert.Equal(t, video_generation.StatusCompleted, polled.Status)
+	assert.Equal(t, 100, *polled.Progress)
+	assert.Equal(t, 8, *polled.DurationSeconds)
+
+	video, err := client.DownloadVideoGeneration(ctx, &video_generation.DownloadRequest{ID: started.ID})
+	require.NoError(t, err)
+	assert.Equal(t, "video/mp4", video.MimeType)
+	assert.Equal(t, []byte("mp4 bytes"), video.Data)
+
+	_, err = client.GetVideoGeneration(ctx, &video_generation.GetRequest{ID: "video_missing"})
+	assert.EqualError(t, err, "no such video")
+}
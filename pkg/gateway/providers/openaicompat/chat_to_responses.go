@@ -21,12 +21,12 @@ func (in *ChatResponse) ToNativeResponse() *responses.Response {
 
 	choice := in.Choices[0]
 	out.Metadata = map[string]any{"finish_reason": choice.FinishReason}
-	out.Output = messageToNativeOutput(choice.Message)
+	out.Output = messageToNativeOutput(choice.Message, choice.Logprobs.tokens())
 
 	return out
 }
 
-func messageToNativeOutput(msg Message) []responses.OutputMessageUnion {
+func messageToNativeOutput(msg Message, logprobs []responses.Logprob) []responses.OutputMessageUnion {
 	out := []responses.OutputMessageUnion{}
 
 	// Reasoning first: it is what the model produced first, and the agent
@@ -48,7 +48,8 @@ func messageToNativeOutput(msg Message) []responses.OutputMessageUnion {
 				Content: &responses.OutputContent{
 					{OfOutputText: &responses.OutputTextContent{
 						Text:        text,
-						Annotations: []responses.Annotation{},
+						Annotations: nativeAnnotations(msg.Annotations),
+						Logprobs:    logprobs,
 					}},
 				},
 			},
@@ -74,6 +75,36 @@ func messageToNativeOutput(msg Message) []responses.OutputMessageUnion {
 	return out
 }
 
+// nativeAnnotations converts the url citations of a message, skipping the
+// annotation types chat completions may add later.
+func nativeAnnotations(in []ChatAnnotation) []responses.Annotation {
+	out := []responses.Annotation{}
+	for _, a := range in {
+		if a.Type != "url_citation" || a.URLCitation == nil {
+			continue
+		}
+
+		out = append(out, responses.Annotation{
+			Type:       "url_citation",
+			Title:      a.URLCitation.Title,
+			URL:        a.URLCitation.URL,
+			StartIndex: a.URLCitation.StartIndex,
+			EndIndex:   a.URLCitation.EndIndex,
+		})
+	}
+
+	return out
+}
+
+// tokens returns the logprobs of the choice's content tokens, if any were
+// returned.
+func (l *ChoiceLogprobs) tokens() []responses.Logprob {
+	if l == nil {
+		return nil
+	}
+	return l.Content
+}
+
 // ToNativeUsage normalizes chat completion token accounting onto the native
 // contract: InputTokens is the whole prompt and CachedTokens is the subset of
 // it served from cache (see responses.Usage).
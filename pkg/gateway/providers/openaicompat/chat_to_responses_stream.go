@@ -45,6 +45,8 @@ type StreamConverter struct {
 	openKind    openItemKind
 	openItemID  string
 	accumulated string
+	logprobs    []responses.Logprob
+	annotations []responses.Annotation
 
 	// Tool call state. toolIndex is the provider's index for the call
 	// currently open, which is how argument fragments are attributed.
@@ -110,8 +112,19 @@ func (c *StreamConverter) Convert(in *ChatResponseChunk) []*responses.ResponseCh
 
 	if choice.Delta.Content != "" {
 		out = append(out, c.openMessage()...)
+		logprobs := choice.Logprobs.tokens()
 		c.accumulated += choice.Delta.Content
-		out = append(out, c.buildOutputTextDelta(choice.Delta.Content))
+		c.logprobs = append(c.logprobs, logprobs...)
+		out = append(out, c.buildOutputTextDelta(choice.Delta.Content, logprobs))
+	}
+
+	// Citations follow the text they cite, in the same or a later frame.
+	if annotations := nativeAnnotations(choice.Delta.Annotations); len(annotations) > 0 {
+		out = append(out, c.openMessage()...)
+		for _, annotation := range annotations {
+			out = append(out, c.buildOutputTextAnnotationAdded(len(c.annotations), annotation))
+			c.annotations = append(c.annotations, annotation)
+		}
 	}
 
 	for _, call := range choice.Delta.ToolCalls {
@@ -217,6 +230,8 @@ func (c *StreamConverter) openMessage() []*responses.ResponseChunk {
 	c.openKind = openItemMessage
 	c.openItemID = responses.NewOutputItemMessageID()
 	c.accumulated = ""
+	c.logprobs = nil
+	c.annotations = []responses.Annotation{}
 
 	return append(out,
 		c.buildOutputItemAddedMessage(),
@@ -257,7 +272,8 @@ func (c *StreamConverter) closeOpenItem() []*responses.ResponseChunk {
 				Content: &responses.OutputContent{
 					{OfOutputText: &responses.OutputTextContent{
 						Text:        text,
-						Annotations: []responses.Annotation{},
+						Annotations: c.annotations,
+						Logprobs:    c.logprobs,
 					}},
 				},
 			},
@@ -382,13 +398,26 @@ func (c *StreamConverter) buildContentPartAddedText() *responses.ResponseChunk {
 	}
 }
 
-func (c *StreamConverter) buildOutputTextDelta(delta string) *responses.ResponseChunk {
+func (c *StreamConverter) buildOutputTextDelta(delta string, logprobs []responses.Logprob) *responses.ResponseChunk {
 	return &responses.ResponseChunk{
 		OfOutputTextDelta: &responses.ChunkOutputText[constants.ChunkTypeOutputTextDelta]{
 			SequenceNumber: c.nextSeqNum(),
 			ItemId:         c.openItemID,
 			OutputIndex:    c.outputIndex,
 			Delta:          delta,
+			Logprobs:       logprobs,
+		},
+	}
+}
+
+func (c *StreamConverter) buildOutputTextAnnotationAdded(index int, annotation responses.Annotation) *responses.ResponseChunk {
+	return &responses.ResponseChunk{
+		OfOutputTextAnnotationAdded: &responses.ChunkOutputText[constants.ChunkTypeOutputTextAnnotationAdded]{
+			SequenceNumber:  c.nextSeqNum(),
+			ItemId:          c.openItemID,
+			OutputIndex:     c.outputIndex,
+			Annotation:      &annotation,
+			AnnotationIndex: index,
 		},
 	}
 }
@@ -410,7 +439,7 @@ func (c *StreamConverter) buildContentPartDoneText(text string) *responses.Respo
 			SequenceNumber: c.nextSeqNum(),
 			ItemId:         c.openItemID,
 			OutputIndex:    c.outputIndex,
-			Part:           responses.ChunkOutputItemContentUnion{OfOutputText: &responses.OutputTextContent{Text: text}},
+			Part:           responses.ChunkOutputItemContentUnion{OfOutputText: &responses.OutputTextContent{Text: text, Annotations: c.annotations, Logprobs: c.logprobs}},
 		},
 	}
 }
@@ -425,7 +454,7 @@ func (c *StreamConverter) buildOutputItemDoneMessage(text string) *responses.Res
 				Id:      c.openItemID,
 				Status:  "completed",
 				Role:    constants.RoleAssistant,
-				Content: &responses.ChunkOutputItemContent{{OfOutputText: &responses.OutputTextContent{Text: text}}},
+				Content: &responses.ChunkOutputItemContent{{OfOutputText: &responses.OutputTextContent{Text: text, Annotations: c.annotations, Logprobs: c.logprobs}}},
 			},
 		},
 	}
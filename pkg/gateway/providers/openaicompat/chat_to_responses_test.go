@@ -278,3 +278,76 @@ func lastCompleted(t *testing.T, chunks []*responses.ResponseChunk) *responses.C
 	t.Fatal("stream has no response.completed chunk")
 	return nil
 }
+
+// Logprobs ride each content delta and are gathered onto the finished text,
+// so a classifier can read the confidence of its label off either.
+func TestStreamConverterLogprobs(t *testing.T) {
+	converter := NewStreamConverter()
+
+	var chunks []*responses.ResponseChunk
+	for _, frame := range []string{
+		`{"id":"c1","choices":[{"index":0,"delta":{"content":"spam"},"logprobs":{"content":[{"token":"spam","logprob":-0.1,"top_logprobs":[{"token":"spam","logprob":-0.1},{"token":"ham","logprob":-2.4}]}]}}]}`,
+		`{"id":"c1","choices":[{"index":0,"delta":{"content":"!"},"logprobs":{"content":[{"token":"!","logprob":-0.2}]}}]}`,
+		`{"id":"c1","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+	} {
+		chunk := &ChatResponseChunk{}
+		if err := sonic.Unmarshal([]byte(frame), chunk); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		chunks = append(chunks, converter.Convert(chunk)...)
+	}
+	chunks = append(chunks, converter.Finish()...)
+
+	var deltas [][]responses.Logprob
+	for _, chunk := range chunks {
+		if chunk.OfOutputTextDelta != nil {
+			deltas = append(deltas, chunk.OfOutputTextDelta.Logprobs)
+		}
+	}
+	if len(deltas) != 2 || len(deltas[0]) != 1 || deltas[0][0].TopLogprobs[1].Token != "ham" {
+		t.Fatalf("delta logprobs = %+v, want each delta's tokens with their alternatives", deltas)
+	}
+
+	completed := lastCompleted(t, chunks)
+	text := (*completed.Response.Output[0].OfOutputMessage.Content)[0].OfOutputText
+	if len(text.Logprobs) != 2 {
+		t.Fatalf("text logprobs = %+v, want both tokens", text.Logprobs)
+	}
+	if got := responses.SequenceLogprob(text.Logprobs); got < -0.30001 || got > -0.29999 {
+		t.Errorf("sequence logprob = %v, want -0.3", got)
+	}
+}
+
+func TestStreamConverterAnnotations(t *testing.T) {
+	converter := NewStreamConverter()
+
+	var chunks []*responses.ResponseChunk
+	for _, frame := range []string{
+		`{"id":"c1","choices":[{"index":0,"delta":{"content":"Go 1.23 shipped."}}]}`,
+		`{"id":"c1","choices":[{"index":0,"delta":{"annotations":[{"type":"url_citation","url_citation":{"start_index":0,"end_index":16,"title":"Go release notes","url":"https://go.dev/doc/go1.23"}}]}}]}`,
+		`{"id":"c1","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+	} {
+		chunk := &ChatResponseChunk{}
+		if err := sonic.Unmarshal([]byte(frame), chunk); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		chunks = append(chunks, converter.Convert(chunk)...)
+	}
+	chunks = append(chunks, converter.Finish()...)
+
+	var added []*responses.Annotation
+	for _, chunk := range chunks {
+		if chunk.OfOutputTextAnnotationAdded != nil {
+			added = append(added, chunk.OfOutputTextAnnotationAdded.Annotation)
+		}
+	}
+	if len(added) != 1 || added[0].URL != "https://go.dev/doc/go1.23" || added[0].EndIndex != 16 {
+		t.Fatalf("annotation.added = %+v, want the url citation", added)
+	}
+
+	completed := lastCompleted(t, chunks)
+	text := (*completed.Response.Output[0].OfOutputMessage.Content)[0].OfOutputText
+	if len(text.Annotations) != 1 || text.Annotations[0].Title != "Go release notes" {
+		t.Errorf("text annotations = %+v, want the citation on the completed output", text.Annotations)
+	}
+}
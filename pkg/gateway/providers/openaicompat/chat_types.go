@@ -15,6 +15,7 @@ package openaicompat
 
 import (
 	"github.com/bytedance/sonic"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
 )
 
 // ChatRequest is the OpenAI /chat/completions request body. Only the fields
@@ -30,6 +31,8 @@ type ChatRequest struct {
 	TopP              *float64          `json:"top_p,omitempty"`
 	MaxTokens         *int              `json:"max_tokens,omitempty"`
 	ReasoningEffort   *string           `json:"reasoning_effort,omitempty"`
+	Logprobs          *bool             `json:"logprobs,omitempty"`
+	TopLogprobs       *int64            `json:"top_logprobs,omitempty"`
 	ResponseFormat    map[string]any    `json:"response_format,omitempty"`
 	Metadata          map[string]string `json:"metadata,omitempty"`
 	Stream            *bool             `json:"stream,omitempty"`
@@ -53,6 +56,23 @@ type Message struct {
 	// responses only — never sent back, since these APIs reject it.
 	ReasoningContent string `json:"reasoning_content,omitempty"`
 	Refusal          string `json:"refusal,omitempty"`
+	// Annotations are the citations of web search models. Like
+	// ReasoningContent, they are read from responses only.
+	Annotations []ChatAnnotation `json:"annotations,omitempty"`
+}
+
+// ChatAnnotation is a citation on an assistant message. Chat completions only
+// define "url_citation".
+type ChatAnnotation struct {
+	Type        string           `json:"type"`
+	URLCitation *ChatURLCitation `json:"url_citation,omitempty"`
+}
+
+type ChatURLCitation struct {
+	StartIndex int    `json:"start_index"`
+	EndIndex   int    `json:"end_index"`
+	Title      string `json:"title"`
+	URL        string `json:"url"`
 }
 
 // Content is the string-or-parts union used by every message role.
@@ -175,9 +195,16 @@ type ChatResponse struct {
 }
 
 type ChatChoice struct {
-	Index        int     `json:"index"`
-	FinishReason string  `json:"finish_reason"`
-	Message      Message `json:"message"`
+	Index        int             `json:"index"`
+	FinishReason string          `json:"finish_reason"`
+	Message      Message         `json:"message"`
+	Logprobs     *ChoiceLogprobs `json:"logprobs,omitempty"`
+}
+
+// ChoiceLogprobs holds a choice's token log probabilities when the request
+// set logprobs. Each token has the same shape as the native one.
+type ChoiceLogprobs struct {
+	Content []responses.Logprob `json:"content"`
 }
 
 type ChatError struct {
@@ -199,17 +226,19 @@ type ChatResponseChunk struct {
 }
 
 type ChatChunkChoice struct {
-	Index        int        `json:"index"`
-	Delta        ChunkDelta `json:"delta"`
-	FinishReason string     `json:"finish_reason"`
+	Index        int             `json:"index"`
+	Delta        ChunkDelta      `json:"delta"`
+	FinishReason string          `json:"finish_reason"`
+	Logprobs     *ChoiceLogprobs `json:"logprobs,omitempty"`
 }
 
 type ChunkDelta struct {
-	Role             string     `json:"role,omitempty"`
-	Content          string     `json:"content,omitempty"`
-	ReasoningContent string     `json:"reasoning_content,omitempty"`
-	Refusal          string     `json:"refusal,omitempty"`
-	ToolCalls        []ToolCall `json:"tool_calls,omitempty"`
+	Role             string           `json:"role,omitempty"`
+	Content          string           `json:"content,omitempty"`
+	ReasoningContent string           `json:"reasoning_content,omitempty"`
+	Refusal          string           `json:"refusal,omitempty"`
+	ToolCalls        []ToolCall       `json:"tool_calls,omitempty"`
+	Annotations      []ChatAnnotation `json:"annotations,omitempty"`
 }
 
 type Usage struct {
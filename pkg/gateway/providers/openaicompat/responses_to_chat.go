@@ -33,6 +33,11 @@ func NativeRequestToChatRequest(in *responses.Request) *ChatRequest {
 		ResponseFormat:    nativeTextFormatToResponseFormat(in.Text),
 	}
 
+	if in.LogprobsRequested() {
+		out.Logprobs = utils.Ptr(true)
+		out.TopLogprobs = in.TopLogprobs
+	}
+
 	if in.IsStreamingRequest() {
 		// Without this the final chunk carries no usage, and the run's token
 		// accounting - which the history summarizer triggers off - is empty.
@@ -88,6 +88,7 @@ func TestNativeRequestToChatRequestParameters(t *testing.T) {
 		Parameters: responses.Parameters{
 			Temperature:     utils.Ptr(0.4),
 			MaxOutputTokens: utils.Ptr(512),
+			TopLogprobs:     utils.Ptr(int64(3)),
 			Stream:          utils.Ptr(true),
 			Reasoning:       &responses.ReasoningParam{Effort: utils.Ptr("xhigh")},
 			Text: &responses.TextFormat{Format: map[string]any{
@@ -116,6 +117,10 @@ func TestNativeRequestToChatRequestParameters(t *testing.T) {
 		t.Errorf("MaxTokens = %v, want 512", out.MaxTokens)
 	}
 
+	if out.Logprobs == nil || !*out.Logprobs || out.TopLogprobs == nil || *out.TopLogprobs != 3 {
+		t.Errorf("Logprobs/TopLogprobs = %v/%v, want true/3", out.Logprobs, out.TopLogprobs)
+	}
+
 	// xhigh has no chat completions equivalent and clamps to high.
 	if out.ReasoningEffort == nil || *out.ReasoningEffort != "high" {
 		t.Errorf("ReasoningEffort = %v, want high", out.ReasoningEffort)
@@ -0,0 +1,125 @@
+// Package providertest records real provider HTTP exchanges into cassette
+// files and replays them offline, and runs a shared conformance suite of
+// scenarios against every provider client on top of that.
+//
+// A provider client is pointed at a Recorder through its ClientOptions
+// Transport. In record mode the Recorder forwards each request to the real
+// API and keeps a redacted copy of the exchange, streamed bodies included —
+// SSE from OpenAI-style APIs and binary AWS event-stream from Bedrock alike.
+// In replay mode it never touches the network and answers from the cassette,
+// so converter regressions surface in CI without credentials.
+package providertest
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Cassette is one recorded conversation with a provider: every request a
+// scenario made, in the order it made them, each with the response it got.
+type Cassette struct {
+	Interactions []Interaction `yaml:"interactions"`
+}
+
+// Interaction is a single request and the response it received.
+type Interaction struct {
+	Request  RecordedRequest  `yaml:"request"`
+	Response RecordedResponse `yaml:"response"`
+}
+
+// RecordedRequest is a request as it is stored: redacted, with its body
+// encoded by encodeBody.
+type RecordedRequest struct {
+	Method       string              `yaml:"method"`
+	URL          string              `yaml:"url"`
+	Headers      map[string][]string `yaml:"headers,omitempty"`
+	Body         string              `yaml:"body,omitempty"`
+	BodyEncoding string              `yaml:"body_encoding,omitempty"`
+}
+
+// RecordedResponse is a response as it is stored. A streamed body is kept
+// whole, exactly as the client read it off the wire, so replaying it feeds
+// the provider's stream parser the same bytes in the same order.
+type RecordedResponse struct {
+	StatusCode   int                 `yaml:"status_code"`
+	Headers      map[string][]string `yaml:"headers,omitempty"`
+	Body         string              `yaml:"body,omitempty"`
+	BodyEncoding string              `yaml:"body_encoding,omitempty"`
+}
+
+// bodyEncodingBase64 marks a body that was not valid UTF-8 — Bedrock's
+// event-stream frames, audio — and was stored base64-encoded instead.
+const bodyEncodingBase64 = "base64"
+
+// LoadCassette reads a cassette from path. A missing file is reported with
+// an error wrapping os.ErrNotExist.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var c Cassette
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+	}
+
+	return &c, nil
+}
+
+// Save writes the cassette to path, creating its directory if needed.
+func (c *Cassette) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+// encodeBody stores text as-is, so SSE streams and JSON stay readable and
+// diffable in review, and falls back to base64 for anything binary.
+func encodeBody(b []byte) (body string, encoding string) {
+	if utf8.Valid(b) {
+		return string(b), ""
+	}
+	return base64.StdEncoding.EncodeToString(b), bodyEncodingBase64
+}
+
+func decodeBody(body, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return []byte(body), nil
+	case bodyEncodingBase64:
+		return base64.StdEncoding.DecodeString(body)
+	default:
+		return nil, fmt.Errorf("unknown body encoding %q", encoding)
+	}
+}
+
+func cloneHeaders(h http.Header) map[string][]string {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(map[string][]string, len(h))
+	for k, v := range h {
+		out[k] = append([]string(nil), v...)
+	}
+	return out
+}
+
+// ErrNoInteraction is returned in replay mode when a request has no unused
+// recorded counterpart — the scenario changed what it sends, or the cassette
+// was recorded for a different one.
+var ErrNoInteraction = errors.New("providertest: no recorded interaction matches request")
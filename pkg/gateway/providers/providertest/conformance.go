@@ -0,0 +1,500 @@
+package providertest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+)
+
+// RecordEnv switches every conformance suite into record mode when set to
+// "1" (or "true"). Recording needs the provider's API key in the suite's
+// APIKeyEnv; replaying needs nothing.
+const RecordEnv = "HASTEKIT_RECORD"
+
+// replayAPIKey is handed to providers in replay mode. It is never sent
+// anywhere — the recorder answers before the network is reached.
+const replayAPIKey = "replay-key"
+
+// Scenario is one provider-agnostic check. Request builds what is sent for a
+// model, and Check asserts on the native response — the folded stream in the
+// streaming variant, so one Check covers both paths.
+type Scenario struct {
+	Name    string
+	Request func(model string) *responses.Request
+	Check   func(t *testing.T, resp *responses.Response)
+}
+
+// Suite runs the conformance scenarios against one provider.
+type Suite struct {
+	// Provider names the cassette directory, e.g. "openai".
+	Provider string
+
+	// NewProvider builds the client under test around the recording
+	// transport, typically by setting it as ClientOptions.Transport.
+	NewProvider func(apiKey string, transport *http.Client) llm.Provider
+
+	Model string
+
+	// APIKeyEnv is read for the real key in record mode.
+	APIKeyEnv string
+
+	// CassetteDir is where cassettes are read and written. Defaults to
+	// testdata/cassettes next to the calling test.
+	CassetteDir string
+
+	// Scenarios limits the run to the named scenarios; empty runs all of
+	// DefaultScenarios. Providers drop what they cannot do, e.g. a model
+	// without vision leaves out "image_input".
+	Scenarios []string
+
+	// Recorder tunes redaction for this provider.
+	Recorder RecorderOptions
+}
+
+// Run executes every selected scenario twice, once through NewResponses and
+// once through NewStreamingResponses, each against its own cassette. A
+// missing cassette fails the scenario outside record mode: a skip would let
+// CI pass without having checked anything.
+func (s *Suite) Run(t *testing.T) {
+	t.Helper()
+
+	dir := s.CassetteDir
+	if dir == "" {
+		dir = filepath.Join("testdata", "cassettes")
+	}
+	mode := ModeFromEnv(os.Getenv, RecordEnv)
+
+	for _, sc := range s.scenarios(t) {
+		for _, stream := range []bool{false, true} {
+			name := sc.Name
+			if stream {
+				name += "_stream"
+			}
+
+			t.Run(name, func(t *testing.T) {
+				s.runScenario(t, sc, stream, filepath.Join(dir, s.Provider, name+".yaml"), mode)
+			})
+		}
+	}
+}
+
+func (s *Suite) scenarios(t *testing.T) []Scenario {
+	if len(s.Scenarios) == 0 {
+		return DefaultScenarios()
+	}
+
+	byName := map[string]Scenario{}
+	for _, sc := range DefaultScenarios() {
+		byName[sc.Name] = sc
+	}
+
+	out := make([]Scenario, 0, len(s.Scenarios))
+	for _, name := range s.Scenarios {
+		sc, ok := byName[name]
+		if !ok {
+			t.Fatalf("unknown conformance scenario %q", name)
+		}
+		out = append(out, sc)
+	}
+	return out
+}
+
+func (s *Suite) runScenario(t *testing.T, sc Scenario, stream bool, cassette string, mode Mode) {
+	apiKey := replayAPIKey
+	if mode == ModeRecord {
+		apiKey = os.Getenv(s.APIKeyEnv)
+		if apiKey == "" {
+			t.Skipf("%s is not set, cannot record %s", s.APIKeyEnv, cassette)
+		}
+	}
+
+	opts := s.Recorder
+	opts.Mode = mode
+	rec, err := NewRecorder(cassette, opts)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("no cassette at %s; record it with %s=1", cassette, RecordEnv)
+	}
+	if err != nil {
+		t.Fatalf("open cassette: %v", err)
+	}
+	defer func() {
+		if err := rec.Stop(); err != nil {
+			t.Errorf("save cassette: %v", err)
+		}
+	}()
+
+	provider := s.NewProvider(apiKey, rec.Client())
+	req := sc.Request(s.Model)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	var resp *responses.Response
+	if stream {
+		req.Stream = utils.Ptr(true)
+		chunks, err := provider.NewStreamingResponses(ctx, req)
+		if err != nil {
+			t.Fatalf("NewStreamingResponses: %v", err)
+		}
+		resp = CollectStream(t, chunks)
+	} else {
+		resp, err = provider.NewResponses(ctx, req)
+		if err != nil {
+			t.Fatalf("NewResponses: %v", err)
+		}
+	}
+
+	sc.Check(t, resp)
+}
+
+// CollectStream drains a chunk stream into a native response, checking the
+// invariants every provider's stream converter must keep along the way: each
+// item that is added is also done, text deltas add up to the text reported
+// when the part is done, and the stream ends with response.completed.
+func CollectStream(t *testing.T, chunks chan *responses.ResponseChunk) *responses.Response {
+	t.Helper()
+
+	resp := &responses.Response{}
+	open := map[string]bool{}
+	deltas := map[string]*strings.Builder{}
+	completed := false
+
+	for chunk := range chunks {
+		if completed {
+			t.Errorf("chunk %q arrived after response.completed", chunk.ChunkType())
+		}
+
+		switch {
+		case chunk.OfResponseCreated != nil:
+			resp.ID = chunk.OfResponseCreated.Response.Id
+			resp.Model = chunk.OfResponseCreated.Response.Model
+
+		case chunk.OfOutputItemAdded != nil:
+			open[chunk.OfOutputItemAdded.Item.Id] = true
+
+		case chunk.OfOutputTextDelta != nil:
+			b, ok := deltas[chunk.OfOutputTextDelta.ItemId]
+			if !ok {
+				b = &strings.Builder{}
+				deltas[chunk.OfOutputTextDelta.ItemId] = b
+			}
+			b.WriteString(chunk.OfOutputTextDelta.Delta)
+
+		case chunk.OfOutputTextDone != nil:
+			done := chunk.OfOutputTextDone
+			if b, ok := deltas[done.ItemId]; ok && done.Text != nil && b.String() != *done.Text {
+				t.Errorf("text deltas of %s add up to %q, done reports %q", done.ItemId, b.String(), *done.Text)
+			}
+
+		case chunk.OfOutputItemDone != nil:
+			item := chunk.OfOutputItemDone.Item
+			if !open[item.Id] {
+				t.Errorf("output item %s (%s) is done without having been added", item.Id, item.Type)
+			}
+			delete(open, item.Id)
+			if out, ok := outputFromItem(item); ok {
+				resp.Output = append(resp.Output, out)
+			}
+
+		case chunk.OfResponseCompleted != nil:
+			completed = true
+			usage := chunk.OfResponseCompleted.Response.Usage
+			resp.Usage = &usage
+			if resp.ID == "" {
+				resp.ID = chunk.OfResponseCompleted.Response.Id
+			}
+		}
+	}
+
+	if !completed {
+		t.Error("stream closed without response.completed")
+	}
+	for id := range open {
+		t.Errorf("output item %s was added but never done", id)
+	}
+
+	return resp
+}
+
+func outputFromItem(item responses.ChunkOutputItemData) (responses.OutputMessageUnion, bool) {
+	switch item.Type {
+	case "message":
+		content := responses.OutputContent{}
+		if item.Content != nil {
+			for _, c := range *item.Content {
+				if c.OfOutputText != nil {
+					content = append(content, responses.OutputContentUnion{OfOutputText: c.OfOutputText})
+				}
+			}
+		}
+		return responses.OutputMessageUnion{OfOutputMessage: &responses.OutputMessage{
+			ID:      item.Id,
+			Role:    constants.RoleAssistant,
+			Content: &content,
+		}}, true
+
+	case "function_call":
+		fc := &responses.FunctionCallMessage{ID: item.Id, ThoughtSignature: item.ThoughtSignature}
+		if item.CallID != nil {
+			fc.CallID = *item.CallID
+		}
+		if item.Name != nil {
+			fc.Name = *item.Name
+		}
+		if item.Arguments != nil {
+			fc.Arguments = *item.Arguments
+		}
+		return responses.OutputMessageUnion{OfFunctionCall: fc}, true
+
+	case "reasoning":
+		r := &responses.ReasoningMessage{ID: item.Id, EncryptedContent: item.EncryptedContent}
+		if item.Summary != nil {
+			r.Summary = *item.Summary
+		}
+		return responses.OutputMessageUnion{OfReasoning: r}, true
+	}
+
+	return responses.OutputMessageUnion{}, false
+}
+
+// OutputText joins the text of every assistant message in the response.
+func OutputText(resp *responses.Response) string {
+	var sb strings.Builder
+	for _, out := range resp.Output {
+		if out.OfOutputMessage == nil || out.OfOutputMessage.Content == nil {
+			continue
+		}
+		for _, c := range *out.OfOutputMessage.Content {
+			if c.OfOutputText != nil {
+				sb.WriteString(c.OfOutputText.Text)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// FunctionCalls returns the function calls in the response, in order.
+func FunctionCalls(resp *responses.Response) []*responses.FunctionCallMessage {
+	var calls []*responses.FunctionCallMessage
+	for _, out := range resp.Output {
+		if out.OfFunctionCall != nil {
+			calls = append(calls, out.OfFunctionCall)
+		}
+	}
+	return calls
+}
+
+// DefaultScenarios is the shared scenario set. Every check is written
+// against the native shape only, so a provider passes by converting
+// correctly, not by matching another provider's wording.
+func DefaultScenarios() []Scenario {
+	return []Scenario{
+		{
+			Name: "text",
+			Request: func(model string) *responses.Request {
+				return &responses.Request{
+					Model: model,
+					Input: responses.InputUnion{OfString: utils.Ptr("Reply with the single word: pong")},
+				}
+			},
+			Check: func(t *testing.T, resp *responses.Response) {
+				if !strings.Contains(strings.ToLower(OutputText(resp)), "pong") {
+					t.Errorf("expected the answer to contain %q, got %q", "pong", OutputText(resp))
+				}
+				checkUsage(t, resp)
+			},
+		},
+		{
+			Name: "tool_call",
+			Request: func(model string) *responses.Request {
+				return &responses.Request{
+					Model: model,
+					Input: responses.InputUnion{OfString: utils.Ptr("What is the weather in Paris right now?")},
+					Tools: []responses.ToolUnion{weatherTool()},
+				}
+			},
+			Check: func(t *testing.T, resp *responses.Response) {
+				calls := FunctionCalls(resp)
+				if len(calls) == 0 {
+					t.Fatalf("expected a get_weather call, got output %q", OutputText(resp))
+				}
+				checkWeatherCall(t, calls[0])
+				checkUsage(t, resp)
+			},
+		},
+		{
+			Name: "parallel_tool_calls",
+			Request: func(model string) *responses.Request {
+				return &responses.Request{
+					Parameters: responses.Parameters{ParallelToolCalls: utils.Ptr(true)},
+					Model:      model,
+					Input:      responses.InputUnion{OfString: utils.Ptr("What is the weather in Paris and in Tokyo? Look both up at once.")},
+					Tools:      []responses.ToolUnion{weatherTool()},
+				}
+			},
+			Check: func(t *testing.T, resp *responses.Response) {
+				calls := FunctionCalls(resp)
+				if len(calls) < 2 {
+					t.Fatalf("expected at least two get_weather calls, got %d", len(calls))
+				}
+				seen := map[string]bool{}
+				for _, call := range calls {
+					checkWeatherCall(t, call)
+					if seen[call.CallID] {
+						t.Errorf("call id %q is used twice", call.CallID)
+					}
+					seen[call.CallID] = true
+				}
+			},
+		},
+		{
+			Name: "reasoning",
+			Request: func(model string) *responses.Request {
+				return &responses.Request{
+					Parameters: responses.Parameters{
+						Reasoning: &responses.ReasoningParam{
+							Effort:       utils.Ptr("low"),
+							Summary:      utils.Ptr("auto"),
+							BudgetTokens: utils.Ptr(1024),
+						},
+						MaxOutputTokens: utils.Ptr(4096),
+					},
+					Model: model,
+					Input: responses.InputUnion{OfString: utils.Ptr("A bat and a ball cost 1.10 in total. The bat costs 1.00 more than the ball. How much does the ball cost? Answer with the number only.")},
+				}
+			},
+			Check: func(t *testing.T, resp *responses.Response) {
+				if !strings.Contains(OutputText(resp), "0.05") {
+					t.Errorf("expected the answer to contain 0.05, got %q", OutputText(resp))
+				}
+				checkUsage(t, resp)
+			},
+		},
+		{
+			Name: "structured_output",
+			Request: func(model string) *responses.Request {
+				return &responses.Request{
+					Parameters: responses.Parameters{
+						Text: &responses.TextFormat{Format: map[string]any{
+							"type":   "json_schema",
+							"name":   "city",
+							"strict": true,
+							"schema": map[string]any{
+								"type": "object",
+								"properties": map[string]any{
+									"name":    map[string]any{"type": "string"},
+									"country": map[string]any{"type": "string"},
+								},
+								"required":             []any{"name", "country"},
+								"additionalProperties": false,
+							},
+						}},
+					},
+					Model: model,
+					Input: responses.InputUnion{OfString: utils.Ptr("Name the capital of France.")},
+				}
+			},
+			Check: func(t *testing.T, resp *responses.Response) {
+				var city struct {
+					Name    string `json:"name"`
+					Country string `json:"country"`
+				}
+				if err := sonic.UnmarshalString(OutputText(resp), &city); err != nil {
+					t.Fatalf("answer is not valid JSON: %v: %q", err, OutputText(resp))
+				}
+				if city.Name == "" || city.Country == "" {
+					t.Errorf("expected both fields set, got %+v", city)
+				}
+			},
+		},
+		{
+			Name: "image_input",
+			Request: func(model string) *responses.Request {
+				return &responses.Request{
+					Model: model,
+					Input: responses.InputUnion{OfInputMessageList: responses.InputMessageList{{
+						OfInputMessage: &responses.InputMessage{
+							Role: constants.RoleUser,
+							Content: responses.InputContent{
+								{OfInputText: &responses.InputTextContent{Text: "What colour is this image? Answer with one word."}},
+								{OfInputImage: &responses.InputImageContent{ImageURL: utils.Ptr(redPixelPNG), Detail: "low"}},
+							},
+						},
+					}}},
+				}
+			},
+			Check: func(t *testing.T, resp *responses.Response) {
+				if !strings.Contains(strings.ToLower(OutputText(resp)), "red") {
+					t.Errorf("expected the answer to mention red, got %q", OutputText(resp))
+				}
+			},
+		},
+	}
+}
+
+func weatherTool() responses.ToolUnion {
+	return responses.ToolUnion{OfFunction: &responses.FunctionTool{
+		Name:        "get_weather",
+		Description: utils.Ptr("Returns the current weather for a city."),
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"location": map[string]any{"type": "string", "description": "City name"},
+			},
+			"required":             []any{"location"},
+			"additionalProperties": false,
+		},
+	}}
+}
+
+func checkWeatherCall(t *testing.T, call *responses.FunctionCallMessage) {
+	t.Helper()
+
+	if call.Name != "get_weather" {
+		t.Errorf("expected a get_weather call, got %q", call.Name)
+	}
+	if call.CallID == "" {
+		t.Error("function call has no call id")
+	}
+
+	var args struct {
+		Location string `json:"location"`
+	}
+	if err := sonic.UnmarshalString(call.Arguments, &args); err != nil {
+		t.Errorf("arguments are not valid JSON: %v: %q", err, call.Arguments)
+		return
+	}
+	if args.Location == "" {
+		t.Errorf("expected a location argument, got %q", call.Arguments)
+	}
+}
+
+func checkUsage(t *testing.T, resp *responses.Response) {
+	t.Helper()
+
+	if resp.Usage == nil {
+		t.Error("response has no usage")
+		return
+	}
+	if resp.Usage.InputTokens <= 0 || resp.Usage.OutputTokens <= 0 {
+		t.Errorf("expected non-zero token usage, got %+v", *resp.Usage)
+	}
+	if resp.Usage.InputTokensDetails.CachedTokens > resp.Usage.InputTokens {
+		t.Errorf("cached tokens (%d) exceed input tokens (%d)", resp.Usage.InputTokensDetails.CachedTokens, resp.Usage.InputTokens)
+	}
+}
+
+// redPixelPNG is an 8x8 solid red PNG, small enough to keep image cassettes
+// readable.
+const redPixelPNG = "data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAAAgAAAAICAIAAABLbSncAAAAE0lEQVR4nGL5z4AdMMEYQ0MCMADPmQESm71WRQAAAABJRU5ErkJggg=="
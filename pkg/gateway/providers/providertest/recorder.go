@@ -0,0 +1,333 @@
+package providertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+type Mode int
+
+const (
+	// ModeReplay answers every request from the cassette and never touches
+	// the network.
+	ModeReplay Mode = iota
+	// ModeRecord forwards every request to the real API and appends the
+	// exchange to the cassette, which is written out on Stop.
+	ModeRecord
+)
+
+const redacted = "REDACTED"
+
+// defaultRedactedHeaders covers the credential header of every provider in
+// this repository. Header names are compared case-insensitively.
+var defaultRedactedHeaders = []string{
+	"Authorization",
+	"X-Api-Key",
+	"X-Goog-Api-Key",
+	"Xi-Api-Key",
+	"Api-Subscription-Key",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// defaultRedactedQueryParams covers credentials passed in the URL - Gemini's
+// non-streaming endpoints take the key as ?key=.
+var defaultRedactedQueryParams = []string{"key"}
+
+type RecorderOptions struct {
+	Mode Mode
+
+	// RedactHeaders and RedactQueryParams are added to the built-in lists.
+	RedactHeaders     []string
+	RedactQueryParams []string
+
+	// RedactBody, when set, rewrites request and response bodies before
+	// they are stored, e.g. to mask an account id echoed in a response.
+	RedactBody func(body []byte) []byte
+
+	// Transport performs real requests in record mode. Defaults to
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+}
+
+// Recorder is an http.RoundTripper that records exchanges to, or replays
+// them from, a cassette file.
+//
+// Replay matches requests by method, redacted URL and redacted body, taking
+// recorded interactions in order, so a scenario that makes the same call twice
+// gets the two recorded answers in sequence. JSON bodies are compared after
+// normalizing them, so key order and whitespace do not matter but any change
+// in what the converter sends does.
+type Recorder struct {
+	path string
+	opts RecorderOptions
+
+	redactHeaders map[string]bool
+	redactQuery   map[string]bool
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// NewRecorder opens the cassette at path. In replay mode the cassette must
+// exist; in record mode it is started empty and overwritten on Stop.
+func NewRecorder(path string, opts RecorderOptions) (*Recorder, error) {
+	if opts.Transport == nil {
+		opts.Transport = http.DefaultTransport
+	}
+
+	r := &Recorder{
+		path:          path,
+		opts:          opts,
+		redactHeaders: map[string]bool{},
+		redactQuery:   map[string]bool{},
+		cassette:      &Cassette{},
+	}
+	for _, h := range append(defaultRedactedHeaders, opts.RedactHeaders...) {
+		r.redactHeaders[http.CanonicalHeaderKey(h)] = true
+	}
+	for _, q := range append(defaultRedactedQueryParams, opts.RedactQueryParams...) {
+		r.redactQuery[q] = true
+	}
+
+	if opts.Mode == ModeReplay {
+		c, err := LoadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = c
+		r.used = make([]bool, len(c.Interactions))
+	}
+
+	return r, nil
+}
+
+// Client returns an *http.Client that sends through the recorder, ready to be
+// set as a provider's ClientOptions.Transport.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Stop writes the cassette in record mode. It is a no-op in replay mode.
+func (r *Recorder) Stop() error {
+	if r.opts.Mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.cassette.Save(r.path)
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.opts.Mode == ModeRecord {
+		return r.record(req)
+	}
+	return r.replay(req)
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := r.opts.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := Interaction{
+		Request: r.recordRequest(req, reqBody),
+		Response: RecordedResponse{
+			StatusCode: res.StatusCode,
+			Headers:    r.redactHeaderMap(res.Header),
+		},
+	}
+
+	// The body is teed rather than read up front so a streamed response
+	// still reaches the client chunk by chunk while it is being recorded.
+	// The interaction is stored once the client is done with the body.
+	res.Body = &teeBody{
+		ReadCloser: res.Body,
+		onClose: func(b []byte) {
+			interaction.Response.Body, interaction.Response.BodyEncoding = encodeBody(r.redactBody(b))
+			r.mu.Lock()
+			r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+			r.mu.Unlock()
+		},
+	}
+
+	return res, nil
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	method, target, sent := req.Method, r.redactURL(req.URL), normalizeBody(r.redactBody(reqBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] || in.Request.Method != method || in.Request.URL != target {
+			continue
+		}
+		recorded, err := decodeBody(in.Request.Body, in.Request.BodyEncoding)
+		if err != nil {
+			return nil, err
+		}
+		if normalizeBody(recorded) != sent {
+			continue
+		}
+		r.used[i] = true
+
+		body, err := decodeBody(in.Response.Body, in.Response.BodyEncoding)
+		if err != nil {
+			return nil, err
+		}
+
+		header := http.Header{}
+		for k, v := range in.Response.Headers {
+			header[k] = append([]string(nil), v...)
+		}
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s %s", ErrNoInteraction, method, target, sent)
+}
+
+// normalizeBody is the form request bodies are compared in: JSON re-encoded
+// with sorted keys and no insignificant whitespace, anything else as-is.
+func normalizeBody(b []byte) string {
+	// UseNumber keeps large integers exact rather than rounding them
+	// through float64.
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if len(b) == 0 || dec.Decode(&v) != nil || dec.More() {
+		return string(b)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return string(b)
+	}
+	return string(out)
+}
+
+func (r *Recorder) recordRequest(req *http.Request, body []byte) RecordedRequest {
+	out := RecordedRequest{
+		Method:  req.Method,
+		URL:     r.redactURL(req.URL),
+		Headers: r.redactHeaderMap(req.Header),
+	}
+	out.Body, out.BodyEncoding = encodeBody(r.redactBody(body))
+	return out
+}
+
+func (r *Recorder) redactURL(u *url.URL) string {
+	c := *u
+	q := c.Query()
+	changed := false
+	for k := range q {
+		if r.redactQuery[k] {
+			q.Set(k, redacted)
+			changed = true
+		}
+	}
+	if changed {
+		c.RawQuery = q.Encode()
+	}
+	return c.String()
+}
+
+func (r *Recorder) redactHeaderMap(h http.Header) map[string][]string {
+	out := cloneHeaders(h)
+	for k := range out {
+		if r.redactHeaders[http.CanonicalHeaderKey(k)] {
+			out[k] = []string{redacted}
+		}
+	}
+	return out
+}
+
+func (r *Recorder) redactBody(b []byte) []byte {
+	if r.opts.RedactBody == nil || len(b) == 0 {
+		return b
+	}
+	return r.opts.RedactBody(b)
+}
+
+// readRequestBody drains the request body and puts an identical reader back
+// so the real transport still sends it.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	b, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	return b, nil
+}
+
+// teeBody keeps a copy of everything read through it and hands the copy to
+// onClose exactly once. Whatever the client left unread is drained first so
+// the recording holds the complete body even if the client stopped early.
+type teeBody struct {
+	io.ReadCloser
+	buf     bytes.Buffer
+	once    sync.Once
+	onClose func([]byte)
+}
+
+func (t *teeBody) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	t.buf.Write(p[:n])
+	return n, err
+}
+
+func (t *teeBody) Close() error {
+	t.once.Do(func() {
+		_, _ = io.Copy(&t.buf, t.ReadCloser)
+		t.onClose(t.buf.Bytes())
+	})
+	return t.ReadCloser.Close()
+}
+
+// ModeFromEnv returns ModeRecord when the named environment variable is set
+// to a true-ish value, and ModeReplay otherwise.
+func ModeFromEnv(lookup func(string) string, name string) Mode {
+	switch strings.ToLower(lookup(name)) {
+	case "1", "true", "yes", "record":
+		return ModeRecord
+	default:
+		return ModeReplay
+	}
+}
@@ -0,0 +1,172 @@
+package providertest
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder_RecordThenReplay(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Set-Cookie", "session=secret")
+		flusher := w.(http.Flusher)
+		for _, event := range []string{"one", "two", "three"} {
+			_, _ = io.WriteString(w, "data: "+event+"\n\n")
+			flusher.Flush()
+		}
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "stream.yaml")
+
+	rec, err := NewRecorder(path, RecorderOptions{Mode: ModeRecord})
+	require.NoError(t, err)
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/stream?key=sk-live&alt=sse", strings.NewReader(`{"prompt":"hi"}`))
+	req.Header.Set("Authorization", "Bearer sk-live")
+	req.Header.Set("X-Goog-Api-Key", "sk-live")
+	res, err := rec.Client().Do(req)
+	require.NoError(t, err)
+
+	// Read only part of the stream: the recording must still hold all of it.
+	line, err := bufio.NewReader(res.Body).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "data: one\n", line)
+	require.NoError(t, res.Body.Close())
+	require.NoError(t, rec.Stop())
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "sk-live")
+	assert.NotContains(t, string(raw), "session=secret")
+
+	c, err := LoadCassette(path)
+	require.NoError(t, err)
+	require.Len(t, c.Interactions, 1)
+	assert.Equal(t, `{"prompt":"hi"}`, c.Interactions[0].Request.Body)
+	assert.Equal(t, "data: one\n\ndata: two\n\ndata: three\n\n", c.Interactions[0].Response.Body)
+
+	replay, err := NewRecorder(path, RecorderOptions{Mode: ModeReplay})
+	require.NoError(t, err)
+
+	// The replayed request carries a different key; matching is on the
+	// redacted URL, so it still finds the recording.
+	req, _ = http.NewRequest(http.MethodPost, srv.URL+"/v1/stream?key=other&alt=sse", strings.NewReader(`{"prompt":"hi"}`))
+	res, err = replay.Client().Do(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+	assert.Equal(t, "data: one\n\ndata: two\n\ndata: three\n\n", string(body))
+	assert.Equal(t, 1, calls, "replay must not reach the server")
+
+	// Each recorded interaction answers once.
+	req, _ = http.NewRequest(http.MethodPost, srv.URL+"/v1/stream?key=other&alt=sse", strings.NewReader(`{"prompt":"hi"}`))
+	_, err = replay.Client().Do(req)
+	assert.True(t, errors.Is(err, ErrNoInteraction))
+}
+
+// A request is matched on its body too, so a converter that starts sending
+// something else fails in replay rather than being handed the old answer.
+func TestRecorder_ReplayMatchesTheBody(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "body.yaml")
+	c := &Cassette{Interactions: []Interaction{{
+		Request:  RecordedRequest{Method: http.MethodPost, URL: "https://api.example.com/v1/responses", Body: `{"model":"m","input":"hi","stream":true}`},
+		Response: RecordedResponse{StatusCode: http.StatusOK, Body: "ok"},
+	}}}
+	require.NoError(t, c.Save(path))
+
+	send := func(body string) error {
+		replay, err := NewRecorder(path, RecorderOptions{Mode: ModeReplay})
+		require.NoError(t, err)
+		req, _ := http.NewRequest(http.MethodPost, "https://api.example.com/v1/responses", strings.NewReader(body))
+		res, err := replay.Client().Do(req)
+		if err == nil {
+			_ = res.Body.Close()
+		}
+		return err
+	}
+
+	// Key order and whitespace are not what the request means.
+	assert.NoError(t, send(`{ "stream": true, "input": "hi", "model": "m" }`))
+
+	err := send(`{"model":"m","input":"hi"}`)
+	assert.True(t, errors.Is(err, ErrNoInteraction))
+}
+
+func TestRecorder_BinaryBodyRoundTrip(t *testing.T) {
+	frame := []byte{0x00, 0x00, 0x00, 0x2a, 0xff, 0xfe, 0x80, 'o', 'k'}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+		_, _ = w.Write(frame)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "binary.yaml")
+	rec, err := NewRecorder(path, RecorderOptions{Mode: ModeRecord})
+	require.NoError(t, err)
+
+	res, err := rec.Client().Get(srv.URL)
+	require.NoError(t, err)
+	_, _ = io.ReadAll(res.Body)
+	require.NoError(t, res.Body.Close())
+	require.NoError(t, rec.Stop())
+
+	c, err := LoadCassette(path)
+	require.NoError(t, err)
+	assert.Equal(t, bodyEncodingBase64, c.Interactions[0].Response.BodyEncoding)
+
+	replay, err := NewRecorder(path, RecorderOptions{Mode: ModeReplay})
+	require.NoError(t, err)
+	res, err = replay.Client().Get(srv.URL)
+	require.NoError(t, err)
+	got, _ := io.ReadAll(res.Body)
+	assert.Equal(t, frame, got)
+}
+
+func TestRecorder_RedactBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"account":"acct_123"}`)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "body.yaml")
+	rec, err := NewRecorder(path, RecorderOptions{
+		Mode: ModeRecord,
+		RedactBody: func(b []byte) []byte {
+			return []byte(strings.ReplaceAll(string(b), "acct_123", "acct_x"))
+		},
+	})
+	require.NoError(t, err)
+
+	res, err := rec.Client().Get(srv.URL)
+	require.NoError(t, err)
+	got, _ := io.ReadAll(res.Body)
+	require.NoError(t, res.Body.Close())
+	require.NoError(t, rec.Stop())
+
+	// The caller sees the real body; only the cassette is redacted.
+	assert.Equal(t, `{"account":"acct_123"}`, string(got))
+
+	c, err := LoadCassette(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"account":"acct_x"}`, c.Interactions[0].Response.Body)
+}
+
+func TestNewRecorder_MissingCassette(t *testing.T) {
+	_, err := NewRecorder(filepath.Join(t.TempDir(), "missing.yaml"), RecorderOptions{Mode: ModeReplay})
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
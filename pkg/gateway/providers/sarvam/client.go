@@ -9,14 +9,17 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"slices"
 
 	"github.com/bytedance/sonic"
 	chat_completion2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/chat_completion"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/models"
 	responses2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
 	speech2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/speech"
 	transcription2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/base"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/openaicompat"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/sarvam/sarvam_models"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/sarvam/sarvam_speech"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/sarvam/sarvam_transcription"
 	"github.com/hastekit/agent-sdk-go/pkg/utils"
@@ -37,7 +40,7 @@ type ClientOptions struct {
 	ApiKey  string
 	Headers map[string]string
 
-	transport *http.Client
+	Transport *http.Client
 }
 
 type Client struct {
@@ -50,8 +53,8 @@ type Client struct {
 }
 
 func NewClient(opts *ClientOptions) *Client {
-	if opts.transport == nil {
-		opts.transport = http.DefaultClient
+	if opts.Transport == nil {
+		opts.Transport = http.DefaultClient
 	}
 
 	if opts.BaseURL == "" {
@@ -68,7 +71,7 @@ func NewClient(opts *ClientOptions) *Client {
 				req.Header.Set("Authorization", "Bearer "+apiKey)
 				req.Header.Set(APIKeyHeader, apiKey)
 			},
-			Transport: opts.transport,
+			Transport: opts.Transport,
 		}),
 	}
 }
@@ -104,7 +107,7 @@ func (c *Client) NewSpeech(ctx context.Context, in *speech2.Request) (*speech2.R
 
 	req.Header.Set("Content-Type", "application/json")
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -198,7 +201,7 @@ func (c *Client) NewTranscription(ctx context.Context, in *transcription2.Reques
 
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -216,6 +219,16 @@ func (c *Client) NewTranscription(ctx context.Context, in *transcription2.Reques
 	return sarvamResponse.ToNativeResponse(), nil
 }
 
+// NewStreamingTranscription transcribes live audio over the streaming
+// speech-to-text WebSocket; see sarvam_transcription.Stream.
+func (c *Client) NewStreamingTranscription(ctx context.Context, in *transcription2.Request) (chan *transcription2.ResponseChunk, error) {
+	return sarvam_transcription.Stream(ctx, &sarvam_transcription.StreamOptions{
+		BaseURL: c.opts.BaseURL,
+		ApiKey:  c.opts.ApiKey,
+		Headers: c.opts.Headers,
+	}, in)
+}
+
 func (c *Client) newRequest(ctx context.Context, path string, body io.Reader) (*http.Request, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.opts.BaseURL+path, body)
 	if err != nil {
@@ -230,3 +243,20 @@ func (c *Client) newRequest(ctx context.Context, path string, body io.Reader) (*
 
 	return req, nil
 }
+
+// ListModels lists Sarvam's chat, speech and transcription models. Sarvam
+// has no listing endpoint, so this is a fixed list that makes no request.
+func (c *Client) ListModels(ctx context.Context) ([]models.Model, error) {
+	return slices.Clone(sarvam_models.Models), nil
+}
+
+// ListVoices lists the bulbul:v2 speakers, without a request.
+func (c *Client) ListVoices(ctx context.Context) ([]models.Voice, error) {
+	out := make([]models.Voice, 0, len(sarvam_models.Voices))
+	for _, v := range sarvam_models.Voices {
+		v.Languages = slices.Clone(sarvam_models.Languages)
+		v.Models = []string{sarvam_speech.DefaultModel}
+		out = append(out, v)
+	}
+	return out, nil
+}
@@ -0,0 +1,30 @@
+// Package sarvam_models lists Sarvam's models and voices. Sarvam has no
+// listing endpoints, so both are the documented catalogue.
+package sarvam_models
+
+import (
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/models"
+)
+
+// Languages are the language codes Sarvam's speech models cover.
+var Languages = []string{"en-IN", "hi-IN", "bn-IN", "gu-IN", "kn-IN", "ml-IN", "mr-IN", "od-IN", "pa-IN", "ta-IN", "te-IN"}
+
+var Models = []models.Model{
+	{ID: "sarvam-m", DisplayName: "Sarvam-M", Capabilities: []models.Capability{models.CapabilityText}, OwnedBy: "sarvam"},
+	{ID: "bulbul:v2", DisplayName: "Bulbul v2", Capabilities: []models.Capability{models.CapabilitySpeech}, OwnedBy: "sarvam"},
+	{ID: "bulbul:v3", DisplayName: "Bulbul v3", Capabilities: []models.Capability{models.CapabilitySpeech}, OwnedBy: "sarvam"},
+	{ID: "saarika:v2.5", DisplayName: "Saarika v2.5", Description: "Speech to text in the spoken language", Capabilities: []models.Capability{models.CapabilityTranscription}, OwnedBy: "sarvam"},
+	{ID: "saaras:v2.5", DisplayName: "Saaras v2.5", Description: "Speech to English text", Capabilities: []models.Capability{models.CapabilityTranscription}, OwnedBy: "sarvam"},
+}
+
+// Voices are the bulbul:v2 speakers; each speaks every language in
+// Languages.
+var Voices = []models.Voice{
+	{ID: "anushka", Name: "Anushka", Gender: "female"},
+	{ID: "manisha", Name: "Manisha", Gender: "female"},
+	{ID: "vidya", Name: "Vidya", Gender: "female"},
+	{ID: "arya", Name: "Arya", Gender: "female"},
+	{ID: "abhilash", Name: "Abhilash", Gender: "male"},
+	{ID: "karun", Name: "Karun", Gender: "male"},
+	{ID: "hitesh", Name: "Hitesh", Gender: "male"},
+}
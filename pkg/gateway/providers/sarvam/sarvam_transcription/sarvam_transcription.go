@@ -50,6 +50,52 @@ func (r *Response) ToNativeResponse() *transcription.Response {
 
 		out.Words = append(out.Words, entry)
 	}
+	out.Segments = transcription.SegmentsFromWords(out.Words)
 
 	return out
 }
+
+// StreamMessage is a server message on the streaming speech-to-text socket.
+// Type is "data" for a transcript, "error" for a failure and "events" for
+// VAD signals.
+type StreamMessage struct {
+	Type string          `json:"type"`
+	Data StreamedSegment `json:"data"`
+}
+
+// StreamedSegment is the payload of a "data" or "error" message.
+type StreamedSegment struct {
+	Response
+	Metrics *struct {
+		AudioDuration float64 `json:"audio_duration"`
+	} `json:"metrics,omitempty"`
+
+	Error string `json:"error,omitempty"`
+	Code  string `json:"code,omitempty"`
+}
+
+// ToFinal turns a streamed transcript into a final chunk. Sarvam times each
+// segment from its own start, so offset — the audio already transcribed —
+// is added to place it in the stream.
+func (s *StreamedSegment) ToFinal(offset float64) *transcription.ChunkFinal[transcription.ChunkTypeFinal] {
+	resp := s.ToNativeResponse()
+
+	final := &transcription.ChunkFinal[transcription.ChunkTypeFinal]{
+		Text:     resp.Text,
+		Start:    offset,
+		End:      offset,
+		Language: resp.Language,
+	}
+	for _, w := range resp.Words {
+		final.Words = append(final.Words, transcription.Word{Word: w.Word, Start: offset + w.Start, End: offset + w.End})
+	}
+
+	switch {
+	case s.Metrics != nil && s.Metrics.AudioDuration > 0:
+		final.End = offset + s.Metrics.AudioDuration
+	case len(final.Words) > 0:
+		final.End = final.Words[len(final.Words)-1].End
+	}
+
+	return final
+}
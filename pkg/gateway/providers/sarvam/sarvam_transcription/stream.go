@@ -0,0 +1,218 @@
+package sarvam_transcription
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/base"
+)
+
+// flushTimeout bounds the wait for the transcript of the final flush once
+// the input has ended.
+const flushTimeout = 10 * time.Second
+
+// flushSettleDelay is how long the stream stays open after a transcript that
+// may be the flush's: segments Sarvam finalized on its own can still be in
+// flight when the flush goes out.
+const flushSettleDelay = 500 * time.Millisecond
+
+// frameBytes is 100ms of 16kHz audio.
+const frameBytes = 3200
+
+// pcmCodec is the codec streamed audio is sent in: raw 16-bit little-endian
+// PCM, named both when the socket is opened and in each audio message.
+const pcmCodec = "pcm_s16le"
+
+type StreamOptions struct {
+	// https://api.sarvam.ai
+	BaseURL string
+	ApiKey  string
+	Headers map[string]string
+}
+
+// apiKeyHeader is sarvam.APIKeyHeader, which this package cannot import.
+const apiKeyHeader = "api-subscription-key"
+
+// Stream transcribes in over the streaming speech-to-text WebSocket. Sarvam
+// sends each segment once, as it is finalized, so the stream carries finals
+// but no partials. When the input ends the remaining audio is flushed, and
+// the stream closes once the transcripts after that stop arriving.
+func Stream(ctx context.Context, opts *StreamOptions, in *transcription.Request) (chan *transcription.ResponseChunk, error) {
+	sampleRate := in.StreamSampleRate()
+
+	languageCode := LanguageCodeUnknown
+	if in.Language != nil && *in.Language != "" {
+		languageCode = *in.Language
+	}
+
+	q := url.Values{}
+	q.Set("language-code", languageCode)
+	q.Set("sample_rate", fmt.Sprint(sampleRate))
+	q.Set("input_audio_codec", pcmCodec)
+	if in.Model != "" {
+		q.Set("model", in.Model)
+	}
+
+	headers := http.Header{}
+	headers.Set(apiKeyHeader, opts.ApiKey)
+	for k, v := range opts.Headers {
+		headers.Set(k, v)
+	}
+
+	conn, err := base.DialWebSocket(ctx, base.WebSocketURL(opts.BaseURL)+"/speech-to-text/ws?"+q.Encode(), headers)
+	if err != nil {
+		return nil, fmt.Errorf("sarvam streaming transcription: %w", err)
+	}
+
+	s := &stream{
+		conn:   conn,
+		out:    make(chan *transcription.ResponseChunk, 16),
+		closed: make(chan struct{}),
+	}
+	go s.send(ctx, in, sampleRate)
+	go s.receive(ctx)
+
+	return s.out, nil
+}
+
+type stream struct {
+	conn *base.WebSocketConn
+	out  chan *transcription.ResponseChunk
+	// closed is closed when receive returns.
+	closed chan struct{}
+
+	mu sync.Mutex
+	// flushed is set once the input has ended and been flushed; from then on
+	// each transcript may be the last.
+	flushed bool
+	settle  *time.Timer
+	// sendErr is why the input could not be streamed, if it could not.
+	sendErr error
+	// duration is the length of the audio sent, in seconds.
+	duration float64
+}
+
+func (s *stream) send(ctx context.Context, in *transcription.Request, sampleRate int) {
+	var sent int
+	err := in.ReadAudio(ctx, frameBytes, func(frame []byte) error {
+		sent += len(frame)
+		return s.conn.WriteJSON(ctx, map[string]any{
+			"audio": map[string]any{
+				"data":        base64.StdEncoding.EncodeToString(frame),
+				"sample_rate": sampleRate,
+				"encoding":    pcmCodec,
+			},
+		})
+	})
+	if err == nil {
+		err = s.conn.WriteJSON(ctx, map[string]any{"type": "flush"})
+	}
+
+	s.mu.Lock()
+	s.flushed = true
+	s.sendErr = err
+	s.duration = transcription.PCMDuration(sent, sampleRate)
+	s.mu.Unlock()
+
+	if err != nil {
+		_ = s.conn.Close()
+		return
+	}
+
+	// Nothing comes back for a flush with no audio left in it.
+	select {
+	case <-time.After(flushTimeout):
+	case <-s.closed:
+	case <-ctx.Done():
+	}
+	_ = s.conn.Close()
+}
+
+func (s *stream) receive(ctx context.Context) {
+	defer close(s.out)
+	defer close(s.closed)
+
+	var (
+		segments []string
+		language *string
+		offset   float64
+	)
+	done := func() {
+		s.mu.Lock()
+		duration := s.duration
+		s.mu.Unlock()
+
+		s.out <- &transcription.ResponseChunk{OfDone: &transcription.ChunkDone[transcription.ChunkTypeDone]{
+			Text:     strings.Join(segments, " "),
+			Language: language,
+			Duration: &duration,
+		}}
+	}
+	fail := func(code, message string) {
+		s.out <- &transcription.ResponseChunk{OfError: &transcription.ChunkError[transcription.ChunkTypeError]{
+			Code:    code,
+			Message: message,
+		}}
+	}
+
+	for {
+		raw, err := s.conn.Read()
+		if err != nil {
+			s.mu.Lock()
+			flushed, sendErr := s.flushed, s.sendErr
+			s.mu.Unlock()
+
+			switch {
+			case sendErr != nil:
+				fail("", sendErr.Error())
+			case ctx.Err() != nil:
+				fail("", ctx.Err().Error())
+			case flushed:
+				done()
+			default:
+				fail("", err.Error())
+			}
+			return
+		}
+
+		var msg StreamMessage
+		if err := sonic.Unmarshal(raw, &msg); err != nil {
+			continue
+		}
+
+		switch msg.Type {
+		case "error":
+			fail(msg.Data.Code, msg.Data.Error)
+			_ = s.conn.Close()
+			return
+
+		case "data":
+			final := msg.Data.ToFinal(offset)
+			offset = final.End
+			if final.Text != "" {
+				segments = append(segments, final.Text)
+				if final.Language != nil {
+					language = final.Language
+				}
+				s.out <- &transcription.ResponseChunk{OfFinal: final}
+			}
+
+			s.mu.Lock()
+			if s.flushed && s.sendErr == nil {
+				if s.settle != nil {
+					s.settle.Stop()
+				}
+				s.settle = time.AfterFunc(flushSettleDelay, func() { _ = s.conn.Close() })
+			}
+			s.mu.Unlock()
+		}
+	}
+}
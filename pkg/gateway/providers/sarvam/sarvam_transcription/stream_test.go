@@ -0,0 +1,92 @@
+package sarvam_transcription
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/realtime/realtimetest"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+)
+
+func TestStream(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	srv := realtimetest.NewServer()
+	defer srv.Close()
+
+	// 0.5s of 16kHz audio in 100ms frames.
+	out, err := Stream(ctx, &StreamOptions{BaseURL: srv.URL, ApiKey: "sk_test"}, &transcription.Request{
+		AudioStream: bytes.NewReader(make([]byte, 16000)),
+		Language:    utils.Ptr("hi-IN"),
+	})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+
+	conn, err := srv.Accept(ctx)
+	if err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+	if conn.Request.URL.Path != "/speech-to-text/ws" || conn.Request.URL.Query().Get("language-code") != "hi-IN" {
+		t.Errorf("url = %s", conn.Request.URL)
+	}
+	if got := conn.Request.URL.Query().Get("input_audio_codec"); got != "pcm_s16le" {
+		t.Errorf("input_audio_codec = %q", got)
+	}
+	if got := conn.Request.Header.Get("api-subscription-key"); got != "sk_test" {
+		t.Errorf("api-subscription-key = %q", got)
+	}
+
+	frames := 0
+	for {
+		msg, err := conn.Receive(ctx)
+		if err != nil {
+			t.Fatalf("Receive: %v", err)
+		}
+		if msg["type"] == "flush" {
+			break
+		}
+		// Raw PCM is labelled as such, not as the WAV it is not.
+		if audio, _ := msg["audio"].(map[string]any); audio["encoding"] != "pcm_s16le" {
+			t.Errorf("audio message = %v", msg)
+		}
+		frames++
+	}
+	if frames != 5 {
+		t.Errorf("sent %d audio frames, want 5", frames)
+	}
+
+	segment := func(text string, duration float64) map[string]any {
+		return map[string]any{"type": "data", "data": map[string]any{
+			"transcript": text, "language_code": "hi-IN",
+			"timestamps": map[string]any{"words": []string{text}, "start_time_seconds": []float64{0.1}, "end_time_seconds": []float64{0.2}},
+			"metrics":    map[string]any{"audio_duration": duration},
+		}}
+	}
+	_ = conn.Send(segment("नमस्ते", 0.3))
+	_ = conn.Send(segment("दुनिया", 0.2))
+
+	var chunks []*transcription.ResponseChunk
+	for c := range out {
+		chunks = append(chunks, c)
+	}
+	if len(chunks) != 3 || chunks[0].OfFinal == nil || chunks[1].OfFinal == nil || chunks[2].OfDone == nil {
+		t.Fatalf("chunks = %+v", chunks)
+	}
+
+	// Each segment is timed from its own start; the stream places it after
+	// the audio already transcribed.
+	second := chunks[1].OfFinal
+	if second.Start != 0.3 || second.End != 0.5 || second.Words[0].Start != 0.4 {
+		t.Errorf("second final = %+v", second)
+	}
+
+	done := chunks[2].OfDone
+	if done.Text != "नमस्ते दुनिया" || done.Duration == nil || *done.Duration != 0.5 {
+		t.Errorf("done = %+v", done)
+	}
+}
@@ -9,15 +9,18 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"net/http"
 	"strings"
 
 	"github.com/bytedance/sonic"
 	image_edit2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/image_edit"
 	image_generation2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/image_generation"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/models"
 	responses2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
 	speech2 "github.com/hastekit/agent-sdk-go/pkg/gateway/llm/speech"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/base"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/openai/openai_models"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/xai/xai_image_edit"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/xai/xai_image_generation"
 	xai_responses2 "github.com/hastekit/agent-sdk-go/pkg/gateway/providers/xai/xai_responses"
@@ -30,7 +33,7 @@ type ClientOptions struct {
 	ApiKey  string
 	Headers map[string]string
 
-	transport *http.Client
+	Transport *http.Client
 }
 
 type Client struct {
@@ -39,8 +42,8 @@ type Client struct {
 }
 
 func NewClient(opts *ClientOptions) *Client {
-	if opts.transport == nil {
-		opts.transport = http.DefaultClient
+	if opts.Transport == nil {
+		opts.Transport = http.DefaultClient
 	}
 
 	if opts.BaseURL == "" {
@@ -69,7 +72,7 @@ func (c *Client) NewResponses(ctx context.Context, inp *responses2.Request) (*re
 	req.Header.Set("Authorization", "Bearer "+c.opts.ApiKey)
 	base.AddAdditionalHeaders(req, inp.ExtraFields)
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -111,7 +114,7 @@ func (c *Client) NewStreamingResponses(ctx context.Context, inp *responses2.Requ
 	req.Header.Set("Authorization", "Bearer "+c.opts.ApiKey)
 	base.AddAdditionalHeaders(req, inp.ExtraFields)
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -167,7 +170,7 @@ func (c *Client) NewSpeech(ctx context.Context, in *speech2.Request) (*speech2.R
 	req.Header.Set("Authorization", "Bearer "+c.opts.ApiKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -225,7 +228,7 @@ func (c *Client) NewImageGeneration(ctx context.Context, in *image_generation2.R
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.opts.ApiKey)
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -270,7 +273,7 @@ func (c *Client) NewImageEdit(ctx context.Context, in *image_edit2.Request) (*im
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.opts.ApiKey)
 
-	res, err := c.opts.transport.Do(req)
+	res, err := c.opts.Transport.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -298,3 +301,17 @@ func (c *Client) NewImageEdit(ctx context.Context, in *image_edit2.Request) (*im
 
 	return xaiEditResponse.ToNativeResponse(), nil
 }
+
+// ListModels lists the models available to the API key. xAI's listing is
+// OpenAI's shape.
+func (c *Client) ListModels(ctx context.Context) ([]models.Model, error) {
+	headers := map[string]string{"Authorization": "Bearer " + c.opts.ApiKey}
+	maps.Copy(headers, c.opts.Headers)
+
+	var list openai_models.ListResponse
+	if err := base.GetJSON(ctx, c.opts.Transport, c.opts.BaseURL+"/models", headers, &list); err != nil {
+		return nil, err
+	}
+
+	return list.ToNative(), nil
+}
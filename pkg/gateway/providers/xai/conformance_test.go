@@ -0,0 +1,22 @@
+package xai_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/providertest"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/xai"
+)
+
+func TestConformance(t *testing.T) {
+	suite := providertest.Suite{
+		Provider:  "xai",
+		Model:     "grok-4-fast",
+		APIKeyEnv: "XAI_API_KEY",
+		NewProvider: func(apiKey string, transport *http.Client) llm.Provider {
+			return xai.NewClient(&xai.ClientOptions{ApiKey: apiKey, Transport: transport})
+		},
+	}
+	suite.Run(t)
+}
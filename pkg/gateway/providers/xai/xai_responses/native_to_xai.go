@@ -19,11 +19,15 @@ func NativeRequestToRequest(in *responses2.Request) *Request {
 
 	// Filter out image generation tools as xAI doesn't support image generation as a tool.
 	// xAI supports image generation via a separate api.
+	// The tools hosted only by other providers are dropped as well.
 	tools := []responses2.ToolUnion{}
 	for _, tool := range in.Tools {
 		if tool.OfImageGeneration != nil {
 			continue
 		}
+		if tool.OfFileSearch != nil || tool.OfWebFetch != nil || tool.OfURLContext != nil || tool.OfGoogleMaps != nil {
+			continue
+		}
 		tools = append(tools, tool)
 	}
 	r.Tools = tools
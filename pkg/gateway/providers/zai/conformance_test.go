@@ -0,0 +1,24 @@
+package zai_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/providertest"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/providers/zai"
+)
+
+func TestConformance(t *testing.T) {
+	suite := providertest.Suite{
+		Provider:  "zai",
+		Model:     "glm-4.6",
+		APIKeyEnv: "ZAI_API_KEY",
+		// The model has no vision.
+		Scenarios: []string{"text", "tool_call", "parallel_tool_calls", "reasoning", "structured_output"},
+		NewProvider: func(apiKey string, transport *http.Client) llm.Provider {
+			return zai.NewClient(&zai.ClientOptions{ApiKey: apiKey, Transport: transport})
+		},
+	}
+	suite.Run(t)
+}
@@ -0,0 +1,81 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/realtime"
+	"github.com/hastekit/agent-sdk-go/pkg/genai"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// NewRealtimeSession opens a realtime session with the provider. A session
+// is a long-lived connection rather than a request, so it does not pass
+// through the middleware chain; only the connection attempt is traced.
+func (g *LLMGateway) NewRealtimeSession(ctx context.Context, providerName llm.ProviderName, key string, cfg *realtime.SessionConfig) (realtime.Session, error) {
+	ctx, span := tracer.Start(ctx, genai.OpRealtime+" "+cfg.Model)
+	defer span.End()
+	addToSpan(ctx, span)
+	span.SetAttributes(
+		attribute.String(genai.AttrOperationName, genai.OpRealtime),
+		attribute.String(genai.AttrProviderName, string(providerName)),
+		attribute.String(genai.AttrRequestModel, cfg.Model),
+		attribute.String(genai.AttrRequestType, genai.RequestTypeRealtime),
+	)
+
+	p, err := g.getProvider(ctx, providerName, nil, key)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	rp, ok := p.(realtime.Provider)
+	if !ok {
+		err := fmt.Errorf("provider %s does not support realtime sessions", providerName)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	session, err := rp.NewRealtimeSession(ctx, cfg)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (p *InternalLLMGateway) NewRealtimeSession(ctx context.Context, providerName llm.ProviderName, key string, cfg *realtime.SessionConfig) (realtime.Session, error) {
+	return p.gateway.NewRealtimeSession(ctx, providerName, key, cfg)
+}
+
+// realtimeGatewayAdapter is implemented by LLMGatewayAdapters that can open
+// realtime sessions. It is kept out of LLMGatewayAdapter so adapters that
+// only speak HTTP need not implement it.
+type realtimeGatewayAdapter interface {
+	NewRealtimeSession(ctx context.Context, providerName llm.ProviderName, key string, cfg *realtime.SessionConfig) (realtime.Session, error)
+}
+
+var _ realtime.Provider = (*LLMClient)(nil)
+
+// NewRealtimeSession opens a realtime session. cfg.Model is resolved like
+// any other request's model.
+func (c *LLMClient) NewRealtimeSession(ctx context.Context, cfg *realtime.SessionConfig) (realtime.Session, error) {
+	adapter, ok := c.LLMGatewayAdapter.(realtimeGatewayAdapter)
+	if !ok {
+		return nil, fmt.Errorf("%T does not support realtime sessions", c.LLMGatewayAdapter)
+	}
+
+	providerName, model, err := c.getProviderAndModelName(cfg.Model)
+	if err != nil {
+		return nil, err
+	}
+	resolved := *cfg
+	resolved.Model = model
+
+	return adapter.NewRealtimeSession(ctx, providerName, c.getKey(ctx, providerName), &resolved)
+}
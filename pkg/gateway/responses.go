@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
@@ -16,3 +17,126 @@ func (g *LLMGateway) handleResponsesRequest(ctx context.Context, providerName ll
 func (g *LLMGateway) handleStreamingResponsesRequest(ctx context.Context, providerName llm.ProviderName, p llm.Provider, in *responses.Request) (chan *responses.ResponseChunk, error) {
 	return p.NewStreamingResponses(ctx, in)
 }
+
+func (g *LLMGateway) handleResponsesGetRequest(ctx context.Context, providerName llm.ProviderName, p llm.Provider, in *responses.GetResponseRequest) (*responses.Response, error) {
+	bp, err := backgroundProvider(providerName, p)
+	if err != nil {
+		return nil, err
+	}
+	return bp.GetResponse(ctx, in)
+}
+
+func (g *LLMGateway) handleResponsesCancelRequest(ctx context.Context, providerName llm.ProviderName, p llm.Provider, in *responses.CancelResponseRequest) (*responses.Response, error) {
+	bp, err := backgroundProvider(providerName, p)
+	if err != nil {
+		return nil, err
+	}
+	return bp.CancelResponse(ctx, in)
+}
+
+func (g *LLMGateway) handleResponsesStreamFromRequest(ctx context.Context, providerName llm.ProviderName, p llm.Provider, in *responses.StreamResponseFromRequest) (chan *responses.ResponseChunk, error) {
+	bp, err := backgroundProvider(providerName, p)
+	if err != nil {
+		return nil, err
+	}
+	return bp.StreamResponseFrom(ctx, in)
+}
+
+func backgroundProvider(providerName llm.ProviderName, p llm.Provider) (responses.BackgroundProvider, error) {
+	bp, ok := p.(responses.BackgroundProvider)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support stored responses", providerName)
+	}
+	return bp, nil
+}
+
+func (p *InternalLLMGateway) GetResponse(ctx context.Context, providerName llm.ProviderName, key string, req *responses.GetResponseRequest) (*responses.Response, error) {
+	resp, err := p.gateway.HandleRequest(ctx, providerName, key, &llm.Request{OfResponsesGet: req})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.OfResponsesOutput, nil
+}
+
+func (p *InternalLLMGateway) CancelResponse(ctx context.Context, providerName llm.ProviderName, key string, req *responses.CancelResponseRequest) (*responses.Response, error) {
+	resp, err := p.gateway.HandleRequest(ctx, providerName, key, &llm.Request{OfResponsesCancel: req})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.OfResponsesOutput, nil
+}
+
+func (p *InternalLLMGateway) StreamResponseFrom(ctx context.Context, providerName llm.ProviderName, key string, req *responses.StreamResponseFromRequest) (chan *responses.ResponseChunk, error) {
+	streamResp, err := p.gateway.HandleStreamingRequest(ctx, providerName, key, &llm.Request{OfResponsesStreamFrom: req})
+	if err != nil {
+		return nil, err
+	}
+
+	return streamResp.ResponsesStreamData, nil
+}
+
+// backgroundGatewayAdapter is implemented by LLMGatewayAdapters that can
+// reach stored responses. Like realtimeGatewayAdapter, it is kept out of
+// LLMGatewayAdapter so adapters without it need not implement it.
+type backgroundGatewayAdapter interface {
+	GetResponse(ctx context.Context, providerName llm.ProviderName, key string, req *responses.GetResponseRequest) (*responses.Response, error)
+	CancelResponse(ctx context.Context, providerName llm.ProviderName, key string, req *responses.CancelResponseRequest) (*responses.Response, error)
+	StreamResponseFrom(ctx context.Context, providerName llm.ProviderName, key string, req *responses.StreamResponseFromRequest) (chan *responses.ResponseChunk, error)
+}
+
+var _ responses.BackgroundProvider = (*LLMClient)(nil)
+
+func (c *LLMClient) backgroundAdapter() (backgroundGatewayAdapter, error) {
+	adapter, ok := c.LLMGatewayAdapter.(backgroundGatewayAdapter)
+	if !ok {
+		return nil, fmt.Errorf("%T does not support stored responses", c.LLMGatewayAdapter)
+	}
+	return adapter, nil
+}
+
+func (c *LLMClient) GetResponse(ctx context.Context, in *responses.GetResponseRequest) (*responses.Response, error) {
+	adapter, err := c.backgroundAdapter()
+	if err != nil {
+		return nil, err
+	}
+
+	providerName, model, err := c.getProviderAndModelName(in.Model)
+	if err != nil {
+		return nil, err
+	}
+	in.Model = model
+
+	return adapter.GetResponse(ctx, providerName, c.getKey(ctx, providerName), in)
+}
+
+func (c *LLMClient) CancelResponse(ctx context.Context, in *responses.CancelResponseRequest) (*responses.Response, error) {
+	adapter, err := c.backgroundAdapter()
+	if err != nil {
+		return nil, err
+	}
+
+	providerName, model, err := c.getProviderAndModelName(in.Model)
+	if err != nil {
+		return nil, err
+	}
+	in.Model = model
+
+	return adapter.CancelResponse(ctx, providerName, c.getKey(ctx, providerName), in)
+}
+
+func (c *LLMClient) StreamResponseFrom(ctx context.Context, in *responses.StreamResponseFromRequest) (chan *responses.ResponseChunk, error) {
+	adapter, err := c.backgroundAdapter()
+	if err != nil {
+		return nil, err
+	}
+
+	providerName, model, err := c.getProviderAndModelName(in.Model)
+	if err != nil {
+		return nil, err
+	}
+	in.Model = model
+
+	return adapter.StreamResponseFrom(ctx, providerName, c.getKey(ctx, providerName), in)
+}
@@ -0,0 +1,677 @@
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/google/uuid"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/genai"
+	"github.com/hastekit/agent-sdk-go/pkg/knowledge/embedder"
+	"github.com/hastekit/agent-sdk-go/pkg/knowledge/vectorstores"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type cacheNamespaceContextKey struct{}
+type cacheTagsContextKey struct{}
+type cacheDisabledContextKey struct{}
+
+// WithCacheNamespace scopes semantic cache entries: a lookup only sees
+// entries written under the same namespace. Use it to keep tenants apart.
+func WithCacheNamespace(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, cacheNamespaceContextKey{}, namespace)
+}
+
+// WithCacheTags labels the entries written by calls made with ctx, so they
+// can be dropped together with SemanticCache.InvalidateTag — e.g. tag answers
+// drawn from a knowledge base with its id, and invalidate it on re-index.
+func WithCacheTags(ctx context.Context, tags ...string) context.Context {
+	return context.WithValue(ctx, cacheTagsContextKey{}, tags)
+}
+
+// WithoutCache makes calls with ctx bypass the semantic cache entirely.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheDisabledContextKey{}, true)
+}
+
+func cacheNamespaceFromContext(ctx context.Context) string {
+	ns, _ := ctx.Value(cacheNamespaceContextKey{}).(string)
+	return ns
+}
+
+func cacheTagsFromContext(ctx context.Context) []string {
+	tags, _ := ctx.Value(cacheTagsContextKey{}).([]string)
+	return tags
+}
+
+type SemanticCacheOptions struct {
+	// Embedder embeds the user's question. Required.
+	Embedder embedder.Embedder
+
+	// Store holds cached answers. Required.
+	Store vectorstores.VectorStore
+
+	// Collection defaults to "llm_semantic_cache".
+	Collection string
+
+	// Dimension is the embedder's vector size, used to create the
+	// collection. Required.
+	Dimension int
+
+	// Threshold is the similarity from which a cached answer is served.
+	// Defaults to 0.95; paraphrases of one question typically land above
+	// 0.9 with general-purpose embedding models, different questions well
+	// below.
+	Threshold float32
+
+	// TTL is how long an answer is served. Defaults to 24h.
+	TTL time.Duration
+}
+
+// SemanticCache is a Middleware that answers a Responses request from an
+// earlier answer to a question that means the same thing. It embeds the
+// last user turn, looks up the nearest cached question, and above the
+// similarity threshold returns the cached response without calling the
+// provider — with zero usage, since nothing was spent.
+//
+// A cached answer is only reused where it was given: same namespace, model,
+// instructions, tools, output format and earlier conversation. Only turns
+// that end on a user message are looked up, and only answers made of plain
+// assistant messages are stored — a turn where the model called a tool is
+// never cached, since replaying it would skip the tool.
+//
+// Install it after TracingMiddleware: it records the outcome on the LLM span.
+type SemanticCache struct {
+	opts SemanticCacheOptions
+	now  func() time.Time
+
+	// ensureMu guards ensured; a failed attempt — a cancelled caller, an
+	// unreachable store — is retried by the next lookup.
+	ensureMu sync.Mutex
+	ensured  bool
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+var _ Middleware = (*SemanticCache)(nil)
+
+const (
+	semanticCacheHit  = "hit"
+	semanticCacheMiss = "miss"
+	semanticCacheSkip = "skip"
+)
+
+func NewSemanticCache(opts SemanticCacheOptions) *SemanticCache {
+	if opts.Collection == "" {
+		opts.Collection = "llm_semantic_cache"
+	}
+	if opts.Threshold == 0 {
+		opts.Threshold = 0.95
+	}
+	if opts.TTL == 0 {
+		opts.TTL = 24 * time.Hour
+	}
+
+	return &SemanticCache{
+		opts: opts,
+		now:  time.Now,
+	}
+}
+
+// InvalidateTag drops every entry written with tag, for every process that
+// shares the store.
+//
+// A vector store can only delete by id, so each tag has a generation, kept
+// in the store next to the entries. An entry records the generation of each
+// of its tags as it was when its question was looked up, and a lookup
+// ignores entries from an older generation — including answers still being
+// generated when the tag was invalidated.
+func (c *SemanticCache) InvalidateTag(ctx context.Context, tag string) error {
+	if err := c.ensureCollection(ctx); err != nil {
+		return err
+	}
+
+	generation, err := c.tagGeneration(ctx, tag)
+	if err != nil {
+		return err
+	}
+
+	err = c.opts.Store.UpsertVectors(ctx, c.opts.Collection, []string{tagMarkerID(tag)}, [][]float64{c.markerVector()}, []map[string]any{{
+		"kind":       semanticCacheTagMarker,
+		"tag":        tag,
+		"generation": generation + 1,
+	}})
+	if err != nil {
+		return fmt.Errorf("semantic cache: invalidate tag %q: %w", tag, err)
+	}
+	return nil
+}
+
+// semanticCacheTagMarker is the kind of the point holding a tag's
+// generation. Entries have no kind, and lookups filter on namespace, so a
+// marker is never served as an answer.
+const semanticCacheTagMarker = "tag_generation"
+
+// tagMarkerID is the id of tag's marker, the same in every process.
+func tagMarkerID(tag string) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte("semantic-cache-tag:"+tag)).String()
+}
+
+// markerVector is the vector markers are stored and looked up under. Its
+// value does not matter, since markers are found by their filter, but it
+// must have the collection's dimension and, for cosine stores, a length.
+func (c *SemanticCache) markerVector() []float64 {
+	v := make([]float64, c.opts.Dimension)
+	if len(v) > 0 {
+		v[0] = 1
+	}
+	return v
+}
+
+// tagGeneration reads tag's current generation; a tag never invalidated is
+// at generation 0.
+func (c *SemanticCache) tagGeneration(ctx context.Context, tag string) (int64, error) {
+	results, err := c.opts.Store.Search(ctx, c.opts.Collection, c.markerVector(), 1, map[string]any{
+		"kind": semanticCacheTagMarker,
+		"tag":  tag,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("semantic cache: read generation of tag %q: %w", tag, err)
+	}
+	if len(results) == 0 {
+		return 0, nil
+	}
+	generation, _ := metadataInt(results[0].Metadata["generation"])
+	return generation, nil
+}
+
+// Stats returns the number of lookups answered from the cache and the
+// number that went to the provider.
+func (c *SemanticCache) Stats() (hits, misses int64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+func (c *SemanticCache) HandleRequest(next RequestHandler) RequestHandler {
+	return func(ctx context.Context, providerName llm.ProviderName, key string, r *llm.Request) (*llm.Response, error) {
+		lookup, ok := c.prepare(ctx, providerName, r)
+		if !ok {
+			c.annotate(ctx, semanticCacheSkip, 0)
+			return next(ctx, providerName, key, r)
+		}
+
+		if cached, score, ok := c.lookup(ctx, lookup); ok {
+			c.annotate(ctx, semanticCacheHit, score)
+			return &llm.Response{OfResponsesOutput: cached}, nil
+		}
+		c.annotate(ctx, semanticCacheMiss, 0)
+
+		resp, err := next(ctx, providerName, key, r)
+		if err == nil && resp != nil && resp.OfResponsesOutput != nil {
+			c.store(ctx, lookup, resp.OfResponsesOutput)
+		}
+		return resp, err
+	}
+}
+
+func (c *SemanticCache) HandleStreamingRequest(next StreamingRequestHandler) StreamingRequestHandler {
+	return func(ctx context.Context, providerName llm.ProviderName, key string, r *llm.Request) (*llm.StreamingResponse, error) {
+		lookup, ok := c.prepare(ctx, providerName, r)
+		if !ok {
+			c.annotate(ctx, semanticCacheSkip, 0)
+			return next(ctx, providerName, key, r)
+		}
+
+		if cached, score, ok := c.lookup(ctx, lookup); ok {
+			c.annotate(ctx, semanticCacheHit, score)
+			return &llm.StreamingResponse{ResponsesStreamData: replayResponse(ctx, cached)}, nil
+		}
+		c.annotate(ctx, semanticCacheMiss, 0)
+
+		resp, err := next(ctx, providerName, key, r)
+		if err != nil || resp == nil || resp.ResponsesStreamData == nil {
+			return resp, err
+		}
+
+		// Fold the stream as it passes; store the answer only if the stream
+		// ran to response.completed and every item in it was understood. An
+		// item the fold leaves out — the output of a hosted tool too new to
+		// be known — would otherwise be cached as a plain answer without it.
+		orig := resp.ResponsesStreamData
+		wrapped := make(chan *responses.ResponseChunk)
+		resp.ResponsesStreamData = wrapped
+		go func() {
+			defer close(wrapped)
+			acc := &responsesStreamAccumulator{}
+			for chunk := range orig {
+				acc.add(chunk)
+				wrapped <- chunk
+			}
+			if acc.completed && acc.skipped == 0 {
+				c.store(context.WithoutCancel(ctx), lookup, acc.response())
+			}
+		}()
+
+		return resp, nil
+	}
+}
+
+// cacheLookup is what identifies a cacheable request.
+type cacheLookup struct {
+	namespace string
+	scope     string
+	question  string
+	tags      []string
+	vector    []float64
+	// generations are those of tags when the question was looked up, and
+	// what the answer is stored under.
+	generations []int64
+}
+
+func (c *SemanticCache) prepare(ctx context.Context, providerName llm.ProviderName, r *llm.Request) (*cacheLookup, bool) {
+	if r.OfResponsesInput == nil {
+		return nil, false
+	}
+	// A background request is answered with a queued response, and one that
+	// continues a stored response carries context the cache cannot see.
+	if in := r.OfResponsesInput; (in.Background != nil && *in.Background) || in.PreviousResponseID != nil {
+		return nil, false
+	}
+	if disabled, _ := ctx.Value(cacheDisabledContextKey{}).(bool); disabled {
+		return nil, false
+	}
+
+	question, history, ok := lastUserTurn(r.OfResponsesInput)
+	if !ok || strings.TrimSpace(question) == "" {
+		return nil, false
+	}
+
+	scope, err := cacheScope(providerName, r.OfResponsesInput, history)
+	if err != nil {
+		return nil, false
+	}
+
+	return &cacheLookup{
+		namespace: cacheNamespaceFromContext(ctx),
+		scope:     scope,
+		question:  question,
+		tags:      cacheTagsFromContext(ctx),
+	}, true
+}
+
+func (c *SemanticCache) lookup(ctx context.Context, l *cacheLookup) (*responses.Response, float32, bool) {
+	if err := c.ensureCollection(ctx); err != nil {
+		c.misses.Add(1)
+		return nil, 0, false
+	}
+
+	vector, err := c.opts.Embedder.Embed(ctx, l.question)
+	if err != nil {
+		slog.WarnContext(ctx, "semantic cache: failed to embed question", slog.Any("error", err))
+		c.misses.Add(1)
+		return nil, 0, false
+	}
+
+	// Read before the provider is called, so that an answer generated while
+	// one of its tags is invalidated is stored under the old generation.
+	generations := make([]int64, len(l.tags))
+	for i, tag := range l.tags {
+		if generations[i], err = c.tagGeneration(ctx, tag); err != nil {
+			slog.WarnContext(ctx, "semantic cache: lookup failed", slog.Any("error", err))
+			c.misses.Add(1)
+			return nil, 0, false
+		}
+	}
+	l.vector = vector
+	l.generations = generations
+
+	results, err := c.opts.Store.Search(ctx, c.opts.Collection, vector, 1, map[string]any{
+		"namespace": l.namespace,
+		"scope":     l.scope,
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "semantic cache: lookup failed", slog.Any("error", err))
+		c.misses.Add(1)
+		return nil, 0, false
+	}
+
+	if len(results) == 0 || results[0].Score < c.opts.Threshold {
+		c.misses.Add(1)
+		return nil, 0, false
+	}
+	hit := results[0]
+
+	if !c.fresh(ctx, hit.Metadata) {
+		_ = c.opts.Store.DeleteVectors(ctx, c.opts.Collection, []string{hit.ID})
+		c.misses.Add(1)
+		return nil, 0, false
+	}
+
+	raw, _ := hit.Metadata["response"].(string)
+	var cached responses.Response
+	if err := sonic.UnmarshalString(raw, &cached); err != nil {
+		c.misses.Add(1)
+		return nil, 0, false
+	}
+
+	c.hits.Add(1)
+	cached.ID = "resp_" + uuid.NewString()
+	cached.Usage = &responses.Usage{}
+	return &cached, hit.Score, true
+}
+
+// fresh reports whether an entry is within its TTL and none of its tags has
+// been invalidated since it was written. An entry whose tags cannot be
+// checked is not served.
+func (c *SemanticCache) fresh(ctx context.Context, meta map[string]any) bool {
+	expiresAt, ok := metadataInt(meta["expires_at"])
+	if !ok || c.now().Unix() >= expiresAt {
+		return false
+	}
+
+	raw, _ := meta["tags"].(string)
+	if raw == "" {
+		return true
+	}
+
+	for _, entry := range strings.Split(raw, ",") {
+		tag, gen, _ := strings.Cut(entry, "@")
+		written, _ := strconv.ParseInt(gen, 10, 64)
+		current, err := c.tagGeneration(ctx, tag)
+		if err != nil {
+			slog.WarnContext(ctx, "semantic cache: lookup failed", slog.Any("error", err))
+			return false
+		}
+		if written < current {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *SemanticCache) store(ctx context.Context, l *cacheLookup, resp *responses.Response) {
+	answer, ok := cacheableAnswer(resp)
+	if !ok || l.vector == nil {
+		return
+	}
+
+	raw, err := sonic.MarshalString(answer)
+	if err != nil {
+		return
+	}
+
+	tags := make([]string, len(l.tags))
+	for i, tag := range l.tags {
+		tags[i] = tag + "@" + strconv.FormatInt(l.generations[i], 10)
+	}
+
+	meta := map[string]any{
+		"namespace":  l.namespace,
+		"scope":      l.scope,
+		"question":   l.question,
+		"response":   raw,
+		"tags":       strings.Join(tags, ","),
+		"expires_at": c.now().Add(c.opts.TTL).Unix(),
+	}
+
+	err = c.opts.Store.UpsertVectors(ctx, c.opts.Collection, []string{uuid.NewString()}, [][]float64{l.vector}, []map[string]any{meta})
+	if err != nil {
+		slog.WarnContext(ctx, "semantic cache: failed to store answer", slog.Any("error", err))
+	}
+}
+
+func (c *SemanticCache) ensureCollection(ctx context.Context) error {
+	c.ensureMu.Lock()
+	defer c.ensureMu.Unlock()
+	if c.ensured {
+		return nil
+	}
+
+	if err := c.opts.Store.EnsureCollection(ctx, c.opts.Collection, c.opts.Dimension); err != nil {
+		slog.ErrorContext(ctx, "semantic cache: failed to create collection", slog.Any("error", err))
+		return err
+	}
+	c.ensured = true
+	return nil
+}
+
+func (c *SemanticCache) annotate(ctx context.Context, result string, score float32) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	hits, misses := c.Stats()
+	attrs := []attribute.KeyValue{
+		attribute.String(genai.AttrSemanticCacheResult, result),
+		attribute.Int64(genai.AttrSemanticCacheHits, hits),
+		attribute.Int64(genai.AttrSemanticCacheMisses, misses),
+	}
+	if result == semanticCacheHit {
+		attrs = append(attrs, attribute.Float64(genai.AttrSemanticCacheSimilarity, float64(score)))
+	}
+	span.SetAttributes(attrs...)
+}
+
+// lastUserTurn splits the input into the closing user question and what
+// came before it. A turn that does not end on a plain-text user message —
+// a tool result, an image, an assistant prefill — is not cacheable.
+func lastUserTurn(in *responses.Request) (string, responses.InputMessageList, bool) {
+	if in.Input.OfString != nil {
+		return *in.Input.OfString, nil, true
+	}
+
+	items := in.Input.OfInputMessageList
+	if len(items) == 0 {
+		return "", nil, false
+	}
+	last := items[len(items)-1]
+	history := items[:len(items)-1]
+
+	switch {
+	case last.OfEasyInput != nil:
+		msg := last.OfEasyInput
+		if msg.Role != "" && msg.Role != constants.RoleUser {
+			return "", nil, false
+		}
+		if msg.Content.OfString != nil {
+			return *msg.Content.OfString, history, true
+		}
+		text, ok := inputContentText(msg.Content.OfInputMessageList)
+		return text, history, ok
+
+	case last.OfInputMessage != nil:
+		msg := last.OfInputMessage
+		if msg.Role != "" && msg.Role != constants.RoleUser {
+			return "", nil, false
+		}
+		text, ok := inputContentText(msg.Content)
+		return text, history, ok
+	}
+
+	return "", nil, false
+}
+
+func inputContentText(content responses.InputContent) (string, bool) {
+	var parts []string
+	for _, c := range content {
+		if c.OfInputText == nil {
+			return "", false
+		}
+		parts = append(parts, c.OfInputText.Text)
+	}
+	return strings.Join(parts, "\n"), len(parts) > 0
+}
+
+// cacheScope hashes everything besides the question that shapes the answer.
+func cacheScope(providerName llm.ProviderName, in *responses.Request, history responses.InputMessageList) (string, error) {
+	b, err := sonic.Marshal(struct {
+		Provider     llm.ProviderName           `json:"provider"`
+		Model        string                     `json:"model"`
+		Instructions *string                    `json:"instructions"`
+		Tools        []responses.ToolUnion      `json:"tools"`
+		Text         *responses.TextFormat      `json:"text"`
+		History      responses.InputMessageList `json:"history"`
+	}{providerName, in.Model, in.Instructions, in.Tools, in.Text, history})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// cacheableAnswer keeps the assistant messages of resp, or reports false if
+// the model did anything else a replay would skip, such as calling a tool.
+func cacheableAnswer(resp *responses.Response) (*responses.Response, bool) {
+	answer := &responses.Response{Model: resp.Model}
+	for _, item := range resp.Output {
+		switch {
+		case item.OfOutputMessage != nil:
+			answer.Output = append(answer.Output, item)
+		case item.OfReasoning != nil:
+			// Reasoning is bound to the call that produced it; the answer
+			// stands without it.
+		default:
+			return nil, false
+		}
+	}
+	return answer, len(answer.Output) > 0
+}
+
+// replayResponse streams a cached response the way a provider would stream
+// it, one delta per text part, so streaming consumers need no special case.
+func replayResponse(ctx context.Context, resp *responses.Response) chan *responses.ResponseChunk {
+	out := make(chan *responses.ResponseChunk)
+
+	go func() {
+		defer close(out)
+
+		seq := 0
+		send := func(chunk *responses.ResponseChunk) bool {
+			select {
+			case out <- chunk:
+				seq++
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		envelope := func(status string, output []responses.OutputMessageUnion) responses.ChunkResponseData {
+			data := responses.ChunkResponseData{
+				Id:        resp.ID,
+				Object:    "response",
+				CreatedAt: int(time.Now().Unix()),
+				Status:    status,
+				Output:    output,
+			}
+			data.Model = resp.Model
+			return data
+		}
+
+		if !send(&responses.ResponseChunk{OfResponseCreated: &responses.ChunkResponse[constants.ChunkTypeResponseCreated]{
+			SequenceNumber: seq,
+			Response:       envelope("in_progress", []responses.OutputMessageUnion{}),
+		}}) {
+			return
+		}
+
+		for index, item := range resp.Output {
+			msg := item.OfOutputMessage
+			if msg == nil || msg.Content == nil {
+				continue
+			}
+
+			if !send(&responses.ResponseChunk{OfOutputItemAdded: &responses.ChunkOutputItem[constants.ChunkTypeOutputItemAdded]{
+				SequenceNumber: seq,
+				OutputIndex:    index,
+				Item: responses.ChunkOutputItemData{
+					Type:    "message",
+					Id:      msg.ID,
+					Status:  "in_progress",
+					Role:    constants.RoleAssistant,
+					Content: &responses.ChunkOutputItemContent{},
+				},
+			}}) {
+				return
+			}
+
+			done := responses.ChunkOutputItemContent{}
+			for _, part := range *msg.Content {
+				if part.OfOutputText == nil {
+					continue
+				}
+				text := part.OfOutputText.Text
+				if !send(&responses.ResponseChunk{OfOutputTextDelta: &responses.ChunkOutputText[constants.ChunkTypeOutputTextDelta]{
+					SequenceNumber: seq,
+					ItemId:         msg.ID,
+					OutputIndex:    index,
+					Delta:          text,
+				}}) {
+					return
+				}
+				if !send(&responses.ResponseChunk{OfOutputTextDone: &responses.ChunkOutputText[constants.ChunkTypeOutputTextDone]{
+					SequenceNumber: seq,
+					ItemId:         msg.ID,
+					OutputIndex:    index,
+					Text:           utils.Ptr(text),
+				}}) {
+					return
+				}
+				done = append(done, responses.ChunkOutputItemContentUnion{OfOutputText: part.OfOutputText})
+			}
+
+			if !send(&responses.ResponseChunk{OfOutputItemDone: &responses.ChunkOutputItem[constants.ChunkTypeOutputItemDone]{
+				SequenceNumber: seq,
+				OutputIndex:    index,
+				Item: responses.ChunkOutputItemData{
+					Type:    "message",
+					Id:      msg.ID,
+					Status:  "completed",
+					Role:    constants.RoleAssistant,
+					Content: &done,
+				},
+			}}) {
+				return
+			}
+		}
+
+		send(&responses.ResponseChunk{OfResponseCompleted: &responses.ChunkResponse[constants.ChunkTypeResponseCompleted]{
+			SequenceNumber: seq,
+			Response:       envelope("completed", resp.Output),
+		}})
+	}()
+
+	return out
+}
+
+// metadataInt reads back an integer stored in vector store metadata, which
+// may come back as any numeric type depending on the store.
+func metadataInt(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case int:
+		return int64(n), true
+	case float64:
+		return int64(n), true
+	case string:
+		i, err := strconv.ParseInt(n, 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}
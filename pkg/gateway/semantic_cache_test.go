@@ -0,0 +1,479 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/constants"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/llmtest"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/knowledge/vectorstores"
+	"github.com/hastekit/agent-sdk-go/pkg/utils"
+)
+
+// wordEmbedder embeds text as a bag of its content words, so rephrasings
+// that share their key words land close together.
+type wordEmbedder struct{}
+
+var stopWords = map[string]bool{"how": true, "do": true, "can": true, "i": true, "my": true, "the": true, "a": true, "to": true, "what": true, "is": true}
+
+func (wordEmbedder) Embed(_ context.Context, text string) ([]float64, error) {
+	v := make([]float64, 64)
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return r < 'a' || r > 'z' }) {
+		if stopWords[w] {
+			continue
+		}
+		h := fnv.New32a()
+		h.Write([]byte(w))
+		v[h.Sum32()%64]++
+	}
+	return v, nil
+}
+
+func (e wordEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	out := make([][]float64, len(texts))
+	for i, t := range texts {
+		out[i], _ = e.Embed(ctx, t)
+	}
+	return out, nil
+}
+
+// memoryVectorStore is a brute-force cosine store with the equality filters
+// the Qdrant store supports.
+type memoryVectorStore struct {
+	mu     sync.Mutex
+	points map[string]memoryPoint
+}
+
+type memoryPoint struct {
+	vector []float64
+	meta   map[string]any
+}
+
+var _ vectorstores.VectorStore = (*memoryVectorStore)(nil)
+
+func newMemoryVectorStore() *memoryVectorStore {
+	return &memoryVectorStore{points: map[string]memoryPoint{}}
+}
+
+func (s *memoryVectorStore) EnsureCollection(context.Context, string, int) error { return nil }
+
+func (s *memoryVectorStore) UpsertVectors(_ context.Context, _ string, ids []string, vectors [][]float64, meta []map[string]any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, id := range ids {
+		s.points[id] = memoryPoint{vector: vectors[i], meta: meta[i]}
+	}
+	return nil
+}
+
+func (s *memoryVectorStore) Search(_ context.Context, _ string, query []float64, limit int, filter map[string]any) ([]vectorstores.SearchResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []vectorstores.SearchResult
+next:
+	for id, p := range s.points {
+		for k, v := range filter {
+			if p.meta[k] != v {
+				continue next
+			}
+		}
+		out = append(out, vectorstores.SearchResult{ID: id, Score: float32(cosine(query, p.vector)), Metadata: p.meta})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (s *memoryVectorStore) DeleteCollection(context.Context, string) error { return nil }
+
+func (s *memoryVectorStore) DeleteVectors(_ context.Context, _ string, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		delete(s.points, id)
+	}
+	return nil
+}
+
+func (s *memoryVectorStore) Close() error { return nil }
+
+func (s *memoryVectorStore) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.points)
+}
+
+func cosine(a, b []float64) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+func newTestCache() (*SemanticCache, *memoryVectorStore) {
+	store := newMemoryVectorStore()
+	return NewSemanticCache(SemanticCacheOptions{
+		Embedder:  wordEmbedder{},
+		Store:     store,
+		Dimension: 64,
+		Threshold: 0.9,
+		TTL:       time.Hour,
+	}), store
+}
+
+func question(text string) *llm.Request {
+	return &llm.Request{OfResponsesInput: &responses.Request{
+		Model:        "gpt-4.1-mini",
+		Instructions: utils.Ptr("You are a support bot."),
+		Input:        responses.InputUnion{OfString: utils.Ptr(text)},
+	}}
+}
+
+func outputText(resp *responses.Response) string {
+	for _, item := range resp.Output {
+		if item.OfOutputMessage != nil {
+			return (*item.OfOutputMessage.Content)[0].OfOutputText.Text
+		}
+	}
+	return ""
+}
+
+func TestSemanticCache_ServesParaphrase(t *testing.T) {
+	cache, _ := newTestCache()
+	fake := llmtest.New().RespondText("Use the reset link on the login page.").RespondText("unused")
+	next, _ := fakeHandlers(fake)
+	handler := cache.HandleRequest(next)
+	ctx := context.Background()
+
+	first, err := handler(ctx, "openai", "k", question("How do I reset my password?"))
+	if err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	second, err := handler(ctx, "openai", "k", question("how can i RESET the password"))
+	if err != nil {
+		t.Fatalf("second call: %v", err)
+	}
+
+	if fake.Calls() != 1 {
+		t.Fatalf("provider called %d times, want 1", fake.Calls())
+	}
+	if outputText(second.OfResponsesOutput) != outputText(first.OfResponsesOutput) {
+		t.Fatalf("cached answer = %q", outputText(second.OfResponsesOutput))
+	}
+	if u := second.OfResponsesOutput.Usage; u == nil || u.InputTokens != 0 || u.OutputTokens != 0 {
+		t.Fatalf("a cache hit should report zero usage, got %+v", u)
+	}
+	if hits, misses := cache.Stats(); hits != 1 || misses != 1 {
+		t.Fatalf("stats = %d hits, %d misses", hits, misses)
+	}
+
+	// A different question is not served the cached answer.
+	if _, err := handler(ctx, "openai", "k", question("What are your opening hours?")); err != nil {
+		t.Fatalf("third call: %v", err)
+	}
+	if fake.Calls() != 2 {
+		t.Fatalf("unrelated question should reach the provider")
+	}
+}
+
+// Turns where tools ran, or would have, are never answered from the cache.
+func TestSemanticCache_SkipsToolTurns(t *testing.T) {
+	cache, store := newTestCache()
+	fake := llmtest.New().CallTool("lookup_order", `{"id":"42"}`).RespondText("Order 42 shipped.")
+	next, _ := fakeHandlers(fake)
+	handler := cache.HandleRequest(next)
+	ctx := context.Background()
+
+	if _, err := handler(ctx, "openai", "k", question("Where is order 42?")); err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	if store.len() != 0 {
+		t.Fatalf("an answer that called a tool was cached")
+	}
+
+	afterTool := question("")
+	afterTool.OfResponsesInput.Input = responses.InputUnion{OfInputMessageList: responses.InputMessageList{
+		{OfEasyInput: &responses.EasyMessage{Role: constants.RoleUser, Content: responses.EasyInputContentUnion{OfString: utils.Ptr("Where is order 42?")}}},
+		{OfFunctionCall: &responses.FunctionCallMessage{CallID: "c1", Name: "lookup_order", Arguments: `{"id":"42"}`}},
+		{OfFunctionCallOutput: &responses.FunctionCallOutputMessage{CallID: "c1"}},
+	}}
+	if _, err := handler(ctx, "openai", "k", afterTool); err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	if store.len() != 0 {
+		t.Fatalf("a turn ending on a tool result was cached")
+	}
+	if hits, misses := cache.Stats(); hits != 0 || misses != 1 {
+		t.Fatalf("the tool-result turn should be skipped, not looked up: %d hits, %d misses", hits, misses)
+	}
+}
+
+func TestSemanticCache_NamespaceTTLAndTags(t *testing.T) {
+	cache, _ := newTestCache()
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	cache.now = clock.now
+
+	fake := llmtest.New()
+	for i := 0; i < 6; i++ {
+		fake.RespondText("answer")
+	}
+	next, _ := fakeHandlers(fake)
+	handler := cache.HandleRequest(next)
+
+	tenantA := WithCacheTags(WithCacheNamespace(context.Background(), "tenant-a"), "kb:billing")
+	tenantB := WithCacheNamespace(context.Background(), "tenant-b")
+	ask := func(ctx context.Context) {
+		t.Helper()
+		if _, err := handler(ctx, "openai", "k", question("How do I update billing details?")); err != nil {
+			t.Fatalf("call: %v", err)
+		}
+	}
+
+	ask(tenantA) // miss, stored
+	ask(tenantA) // hit
+	ask(tenantB) // other namespace: miss
+	if fake.Calls() != 2 {
+		t.Fatalf("provider calls = %d, want 2", fake.Calls())
+	}
+
+	if err := cache.InvalidateTag(context.Background(), "kb:billing"); err != nil {
+		t.Fatalf("invalidate: %v", err)
+	}
+	ask(tenantA) // invalidated: miss, stored again under the new generation
+	ask(tenantA) // hit
+	if fake.Calls() != 3 {
+		t.Fatalf("provider calls after invalidation = %d, want 3", fake.Calls())
+	}
+
+	clock.advance(2 * time.Hour)
+	ask(tenantA) // expired: miss
+	if fake.Calls() != 4 {
+		t.Fatalf("provider calls after expiry = %d, want 4", fake.Calls())
+	}
+}
+
+// Tag generations live in the store, so an invalidation reaches every
+// replica sharing it, and outlives the process that made it.
+func TestSemanticCache_InvalidationIsSharedThroughTheStore(t *testing.T) {
+	store := newMemoryVectorStore()
+	newCache := func() *SemanticCache {
+		return NewSemanticCache(SemanticCacheOptions{Embedder: wordEmbedder{}, Store: store, Dimension: 64, Threshold: 0.9, TTL: time.Hour})
+	}
+	replicaA, replicaB := newCache(), newCache()
+
+	fake := llmtest.New().RespondText("old answer").RespondText("new answer")
+	next, _ := fakeHandlers(fake)
+	ctx := WithCacheTags(context.Background(), "kb:billing")
+	ask := func(cache *SemanticCache) string {
+		t.Helper()
+		resp, err := cache.HandleRequest(next)(ctx, "openai", "k", question("How do I update billing details?"))
+		if err != nil {
+			t.Fatalf("call: %v", err)
+		}
+		return outputText(resp.OfResponsesOutput)
+	}
+
+	if got := ask(replicaA); got != "old answer" {
+		t.Fatalf("first answer = %q", got)
+	}
+	if got := ask(replicaB); got != "old answer" || fake.Calls() != 1 {
+		t.Fatalf("replica B should be served A's answer, got %q after %d calls", got, fake.Calls())
+	}
+
+	if err := replicaA.InvalidateTag(context.Background(), "kb:billing"); err != nil {
+		t.Fatalf("invalidate: %v", err)
+	}
+	restarted := newCache()
+	if got := ask(restarted); got != "new answer" || fake.Calls() != 2 {
+		t.Fatalf("after invalidation got %q after %d calls", got, fake.Calls())
+	}
+	if got := ask(replicaB); got != "new answer" || fake.Calls() != 2 {
+		t.Fatalf("replica B should be served the new answer, got %q after %d calls", got, fake.Calls())
+	}
+}
+
+// An answer generated while its tag is invalidated is not served after.
+func TestSemanticCache_InvalidationDuringGeneration(t *testing.T) {
+	cache, _ := newTestCache()
+	fake := llmtest.New().RespondText("stale").RespondText("fresh")
+	next, _ := fakeHandlers(fake)
+	ctx := WithCacheTags(context.Background(), "kb:billing")
+
+	invalidating := func(ctx context.Context, providerName llm.ProviderName, key string, r *llm.Request) (*llm.Response, error) {
+		if err := cache.InvalidateTag(ctx, "kb:billing"); err != nil {
+			t.Fatalf("invalidate: %v", err)
+		}
+		return next(ctx, providerName, key, r)
+	}
+	if _, err := cache.HandleRequest(invalidating)(ctx, "openai", "k", question("How do I update billing details?")); err != nil {
+		t.Fatalf("call: %v", err)
+	}
+
+	resp, err := cache.HandleRequest(next)(ctx, "openai", "k", question("How do I update billing details?"))
+	if err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	if got := outputText(resp.OfResponsesOutput); got != "fresh" {
+		t.Fatalf("served %q", got)
+	}
+}
+
+type failingEmbedder struct{}
+
+func (failingEmbedder) Embed(context.Context, string) ([]float64, error) {
+	return nil, errors.New("embedder down")
+}
+
+func (failingEmbedder) EmbedBatch(context.Context, []string) ([][]float64, error) {
+	return nil, errors.New("embedder down")
+}
+
+func TestSemanticCache_EmbeddingFailureIsAMiss(t *testing.T) {
+	cache := NewSemanticCache(SemanticCacheOptions{Embedder: failingEmbedder{}, Store: newMemoryVectorStore(), Dimension: 64})
+	fake := llmtest.New().RespondText("answer")
+	next, _ := fakeHandlers(fake)
+
+	if _, err := cache.HandleRequest(next)(context.Background(), "openai", "k", question("How do I reset my password?")); err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	if hits, misses := cache.Stats(); hits != 0 || misses != 1 {
+		t.Fatalf("stats = %d hits, %d misses", hits, misses)
+	}
+}
+
+// ensureOnceStore fails EnsureCollection when the caller's context is done.
+type ensureOnceStore struct {
+	*memoryVectorStore
+	ensured int
+}
+
+func (s *ensureOnceStore) EnsureCollection(ctx context.Context, _ string, _ int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.ensured++
+	return nil
+}
+
+// A caller that gave up while the collection was being created does not
+// leave the cache broken for the callers after it.
+func TestSemanticCache_RetriesEnsureCollectionAfterCancellation(t *testing.T) {
+	store := &ensureOnceStore{memoryVectorStore: newMemoryVectorStore()}
+	cache := NewSemanticCache(SemanticCacheOptions{Embedder: wordEmbedder{}, Store: store, Dimension: 64, Threshold: 0.9})
+	fake := llmtest.New().RespondText("answer").RespondText("unused")
+	next, _ := fakeHandlers(fake)
+	handler := cache.HandleRequest(next)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _ = handler(cancelled, "openai", "k", question("How do I reset my password?"))
+
+	for i := 0; i < 2; i++ {
+		if _, err := handler(context.Background(), "openai", "k", question("How do I reset my password?")); err != nil {
+			t.Fatalf("call: %v", err)
+		}
+	}
+	if store.ensured != 1 {
+		t.Fatalf("collection ensured %d times, want 1", store.ensured)
+	}
+	if hits, _ := cache.Stats(); hits != 1 {
+		t.Fatalf("the cache should work once the collection exists, got %d hits", hits)
+	}
+}
+
+func TestSemanticCache_StreamingStoresAndReplays(t *testing.T) {
+	exporter := withRecordingTracer(t)
+	cache, _ := newTestCache()
+	fake := llmtest.New().RespondText("Use the reset link on the login page.")
+	_, nextStream := fakeHandlers(fake)
+	handler := cache.HandleStreamingRequest(nextStream)
+
+	collect := func(ctx context.Context, text string) string {
+		t.Helper()
+		resp, err := handler(ctx, "openai", "k", question(text))
+		if err != nil {
+			t.Fatalf("call: %v", err)
+		}
+		acc := &responsesStreamAccumulator{}
+		for chunk := range resp.ResponsesStreamData {
+			acc.add(chunk)
+		}
+		if !acc.completed {
+			t.Fatalf("stream did not complete")
+		}
+		return outputText(acc.response())
+	}
+
+	first := collect(context.Background(), "How do I reset my password?")
+
+	ctx, span := tracer.Start(context.Background(), "chat gpt-4.1-mini")
+	second := collect(ctx, "reset password how?")
+	span.End()
+
+	if fake.Calls() != 1 || second != first {
+		t.Fatalf("replayed %q after %d provider calls", second, fake.Calls())
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if got := spanAttr(spans[0], "hastekit.semantic_cache.result"); got != "hit" {
+		t.Fatalf("cache result attribute = %q", got)
+	}
+	for _, kv := range spans[0].Attributes {
+		if kv.Key == "hastekit.semantic_cache.hits" && kv.Value.AsInt64() != 1 {
+			t.Fatalf("hits attribute = %d", kv.Value.AsInt64())
+		}
+	}
+}
+
+// A streamed turn in which the provider ran a hosted tool is not cached,
+// whether or not the stream fold knows the tool's item type.
+func TestSemanticCache_StreamingSkipsHostedToolTurns(t *testing.T) {
+	tests := map[string]string{
+		"known hosted tool":   `{"type":"web_search_call","id":"ws_1","status":"completed","action":{"type":"search","query":"order 42"}}`,
+		"unknown output item": `{"type":"computer_call","id":"cu_1","status":"completed","call_id":"c1"}`,
+	}
+	for name, item := range tests {
+		t.Run(name, func(t *testing.T) {
+			cache, store := newTestCache()
+			fake := llmtest.New().StreamChunks(decodeResponseChunks(t,
+				`{"type":"response.created","sequence_number":0,"response":{"id":"resp_1","model":"gpt-4.1-mini","status":"in_progress"}}`,
+				`{"type":"response.output_item.done","sequence_number":1,"output_index":0,"item":`+item+`}`,
+				`{"type":"response.output_item.done","sequence_number":2,"output_index":1,"item":{"type":"message","id":"msg_1","role":"assistant","status":"completed","content":[{"type":"output_text","text":"Order 42 shipped.","annotations":[]}]}}`,
+				`{"type":"response.completed","sequence_number":3,"response":{"id":"resp_1","model":"gpt-4.1-mini","status":"completed","usage":{"input_tokens":5,"output_tokens":4,"total_tokens":9}}}`,
+			)...)
+			_, nextStream := fakeHandlers(fake)
+
+			resp, err := cache.HandleStreamingRequest(nextStream)(context.Background(), "openai", "k", question("Where is order 42?"))
+			if err != nil {
+				t.Fatalf("call: %v", err)
+			}
+			for range resp.ResponsesStreamData {
+			}
+
+			if store.len() != 0 {
+				t.Fatalf("a streamed answer that ran a hosted tool was cached")
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/chat_completion"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/speech"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
 	"github.com/hastekit/agent-sdk-go/pkg/genai"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -94,6 +95,12 @@ func operationAndType(r *llm.Request, streaming bool) (op, reqType string) {
 			return genai.OpChat, genai.RequestTypeResponsesStream
 		}
 		return genai.OpChat, genai.RequestTypeResponses
+	case r.OfResponsesGet != nil:
+		return genai.OpChat, genai.RequestTypeResponsesGet
+	case r.OfResponsesCancel != nil:
+		return genai.OpChat, genai.RequestTypeResponsesCancel
+	case r.OfResponsesStreamFrom != nil:
+		return genai.OpChat, genai.RequestTypeResponsesStreamFrom
 	case r.OfChatCompletionInput != nil:
 		if streaming {
 			return genai.OpChat, genai.RequestTypeChatStream
@@ -107,11 +114,20 @@ func operationAndType(r *llm.Request, streaming bool) (op, reqType string) {
 		}
 		return genai.OpSpeech, genai.RequestTypeSpeech
 	case r.OfTranscription != nil:
+		if streaming {
+			return genai.OpTranscription, genai.RequestTypeTranscriptionStream
+		}
 		return genai.OpTranscription, genai.RequestTypeTranscription
 	case r.OfImageGeneration != nil:
 		return genai.OpImageGeneration, genai.RequestTypeImageGeneration
 	case r.OfImageEdit != nil:
 		return genai.OpImageEdit, genai.RequestTypeImageEdit
+	case r.OfVideoGeneration != nil:
+		return genai.OpVideoGeneration, genai.RequestTypeVideoGeneration
+	case r.OfVideoGenerationGet != nil:
+		return genai.OpVideoGeneration, genai.RequestTypeVideoGenerationGet
+	case r.OfVideoGenerationDownload != nil:
+		return genai.OpVideoGeneration, genai.RequestTypeVideoGenerationDownload
 	}
 	return genai.OpChat, ""
 }
@@ -232,14 +248,28 @@ func setResponseAttributes(span trace.Span, resp *llm.Response) {
 			// Embeddings only consume input tokens.
 			span.SetAttributes(attribute.Int64(genai.AttrUsageInputTokens, out.Usage.PromptTokens))
 		}
+
+	case resp.OfVideoGeneration != nil:
+		out := resp.OfVideoGeneration
+		span.SetAttributes(
+			attribute.String(genai.AttrResponseID, out.ID),
+			attribute.String(genai.AttrVideoGenerationStatus, string(out.Status)),
+		)
+		if out.Model != "" {
+			span.SetAttributes(attribute.String(genai.AttrResponseModel, out.Model))
+		}
+		// The call succeeded but the job it reports on did not.
+		if out.Error != nil {
+			span.SetStatus(codes.Error, out.Error.Message)
+		}
 	}
 }
 
 // wrapStreamingResponse replaces the provider's stream channel with one that
 // forwards every chunk, records the final usage / output attributes, and ends
 // the span when the channel closes. Only the modalities the gateway streams
-// (responses, chat completion, speech) are handled; anything else ends the
-// span immediately.
+// (responses, chat completion, speech, transcription) are handled; anything
+// else ends the span immediately.
 func wrapStreamingResponse(span trace.Span, resp *llm.StreamingResponse) {
 	switch {
 	case resp != nil && resp.ResponsesStreamData != nil:
@@ -315,6 +345,25 @@ func wrapStreamingResponse(span trace.Span, resp *llm.StreamingResponse) {
 			}
 		}()
 
+	case resp != nil && resp.TranscriptionStreamData != nil:
+		orig := resp.TranscriptionStreamData
+		wrapped := make(chan *transcription.ResponseChunk)
+		resp.TranscriptionStreamData = wrapped
+		go func() {
+			defer close(wrapped)
+			defer span.End()
+			for chunk := range orig {
+				wrapped <- chunk
+				if chunk.OfDone != nil && chunk.OfDone.Usage != nil {
+					span.SetAttributes(attribute.Int(genai.AttrUsageInputTokens, chunk.OfDone.Usage.PromptTokens))
+					span.SetAttributes(attribute.Int(genai.AttrUsageOutputTokens, chunk.OfDone.Usage.CompletionTokens))
+				}
+				if chunk.OfError != nil {
+					span.SetStatus(codes.Error, chunk.OfError.Message)
+				}
+			}
+		}()
+
 	default:
 		span.End()
 	}
@@ -8,8 +8,11 @@ import (
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/chat_completion"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/video_generation"
 	"github.com/hastekit/agent-sdk-go/pkg/utils"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
@@ -160,3 +163,97 @@ func TestTracingMiddleware_Streaming(t *testing.T) {
 		t.Fatalf("request_type = %q, want %q", got, "Responses (Stream)")
 	}
 }
+
+func TestTracingMiddleware_StreamingTranscription(t *testing.T) {
+	exporter := withRecordingTracer(t)
+
+	provided := make(chan *transcription.ResponseChunk, 3)
+	provided <- &transcription.ResponseChunk{OfPartial: &transcription.ChunkPartial[transcription.ChunkTypePartial]{Text: "hel"}}
+	provided <- &transcription.ResponseChunk{OfFinal: &transcription.ChunkFinal[transcription.ChunkTypeFinal]{Text: "hello", End: 1.2}}
+	provided <- &transcription.ResponseChunk{OfDone: &transcription.ChunkDone[transcription.ChunkTypeDone]{
+		Text:  "hello",
+		Usage: &transcription.Usage{PromptTokens: 12, CompletionTokens: 3, TotalTokens: 15},
+	}}
+	close(provided)
+
+	next := func(context.Context, llm.ProviderName, string, *llm.Request) (*llm.StreamingResponse, error) {
+		return &llm.StreamingResponse{TranscriptionStreamData: provided}, nil
+	}
+	handler := NewTracingMiddleware().HandleStreamingRequest(next)
+	resp, err := handler(context.Background(), "openai", "key", &llm.Request{
+		OfTranscription: &transcription.Request{Model: "gpt-4o-transcribe"},
+	})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	got := 0
+	for range resp.TranscriptionStreamData {
+		got++
+	}
+	if got != 3 {
+		t.Fatalf("forwarded %d chunks, want 3", got)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Name != "transcription gpt-4o-transcribe" {
+		t.Fatalf("span name = %q", spans[0].Name)
+	}
+	if got := spanAttr(spans[0], "hastekit.request_type"); got != "Transcription (Stream)" {
+		t.Fatalf("request_type = %q, want %q", got, "Transcription (Stream)")
+	}
+	var inputTokens int64
+	for _, kv := range spans[0].Attributes {
+		if string(kv.Key) == "gen_ai.usage.input_tokens" {
+			inputTokens = kv.Value.AsInt64()
+		}
+	}
+	if inputTokens != 12 {
+		t.Fatalf("input tokens = %d, want 12", inputTokens)
+	}
+}
+
+// Polling a video job records the job's id and status, and a job that failed
+// marks the span as an error even though the poll itself succeeded.
+func TestTracingMiddleware_VideoGenerationPoll(t *testing.T) {
+	exporter := withRecordingTracer(t)
+
+	next := func(context.Context, llm.ProviderName, string, *llm.Request) (*llm.Response, error) {
+		return &llm.Response{OfVideoGeneration: &video_generation.Response{
+			ID:     "video_123",
+			Model:  "sora-2",
+			Status: video_generation.StatusFailed,
+			Error:  &video_generation.Error{Code: "moderation_blocked", Message: "blocked by moderation"},
+		}}, nil
+	}
+	handler := NewTracingMiddleware().HandleRequest(next)
+	_, err := handler(context.Background(), "OpenAI", "key", &llm.Request{
+		OfVideoGenerationGet: &video_generation.GetRequest{Model: "sora-2", ID: "video_123"},
+	})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Name != "video_generation sora-2" {
+		t.Fatalf("span name = %q", spans[0].Name)
+	}
+	if got := spanAttr(spans[0], "hastekit.request_type"); got != "VideoGeneration (Get)" {
+		t.Fatalf("request_type = %q, want %q", got, "VideoGeneration (Get)")
+	}
+	if got := spanAttr(spans[0], "gen_ai.response.id"); got != "video_123" {
+		t.Fatalf("response id = %q, want %q", got, "video_123")
+	}
+	if got := spanAttr(spans[0], "hastekit.video_generation.status"); got != "failed" {
+		t.Fatalf("status = %q, want %q", got, "failed")
+	}
+	if spans[0].Status.Code != codes.Error {
+		t.Fatalf("span status = %v, want Error", spans[0].Status.Code)
+	}
+}
@@ -12,3 +12,7 @@ import (
 func (g *LLMGateway) handleTranscriptionRequest(ctx context.Context, providerName llm.ProviderName, p llm.Provider, in *transcription.Request) (*transcription.Response, error) {
 	return p.NewTranscription(ctx, in)
 }
+
+func (g *LLMGateway) handleStreamingTranscriptionRequest(ctx context.Context, providerName llm.ProviderName, p llm.Provider, in *transcription.Request) (chan *transcription.ResponseChunk, error) {
+	return p.NewStreamingTranscription(ctx, in)
+}
@@ -21,8 +21,35 @@ type APIKeyConfig struct {
 	Name         string
 	RateLimits   []RateLimit
 	Weight       int
-	Enabled      bool
-	IsDefault    bool
+	// Disabled takes the key out of rotation without deleting it. A
+	// disabled key is never selected.
+	Disabled bool
+	// Deprecated: use Disabled. Enabled is still honored: once any key of a
+	// provider sets it, that provider's keys without it are not selected.
+	Enabled   bool
+	IsDefault bool
+}
+
+// enabledKeys returns the keys that may be handed out, in order.
+func enabledKeys(keys []*APIKeyConfig) []*APIKeyConfig {
+	// Keys written before Disabled existed opt in with Enabled; a provider
+	// none of whose keys set it is taken to have them all enabled.
+	legacy := false
+	for _, key := range keys {
+		if key.Enabled {
+			legacy = true
+			break
+		}
+	}
+
+	var enabled []*APIKeyConfig
+	for _, key := range keys {
+		if key.Disabled || (legacy && !key.Enabled) {
+			continue
+		}
+		enabled = append(enabled, key)
+	}
+	return enabled
 }
 
 // VirtualKeyConfig contains virtual key access configuration.
@@ -0,0 +1,22 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/video_generation"
+)
+
+// Tracing for these requests is handled by TracingMiddleware, not inline.
+
+func (g *LLMGateway) handleVideoGenerationRequest(ctx context.Context, providerName llm.ProviderName, p llm.Provider, in *video_generation.Request) (*video_generation.Response, error) {
+	return p.NewVideoGeneration(ctx, in)
+}
+
+func (g *LLMGateway) handleVideoGenerationGetRequest(ctx context.Context, providerName llm.ProviderName, p llm.Provider, in *video_generation.GetRequest) (*video_generation.Response, error) {
+	return p.GetVideoGeneration(ctx, in)
+}
+
+func (g *LLMGateway) handleVideoGenerationDownloadRequest(ctx context.Context, providerName llm.ProviderName, p llm.Provider, in *video_generation.DownloadRequest) (*video_generation.Content, error) {
+	return p.DownloadVideoGeneration(ctx, in)
+}
@@ -66,10 +66,22 @@ const (
 	// invoke_agent span so traces can be correlated with the run.* lifecycle
 	// events, which carry the same id.
 	AttrRunID = "hastekit.run_id"
+
+	// Semantic cache outcome on the LLM span: the result of this lookup
+	// ("hit", "miss" or "skip"), the similarity of a hit, and the cache's
+	// running hit and miss counts.
+	AttrSemanticCacheResult     = "hastekit.semantic_cache.result"
+	AttrSemanticCacheSimilarity = "hastekit.semantic_cache.similarity"
+	AttrSemanticCacheHits       = "hastekit.semantic_cache.hits"
+	AttrSemanticCacheMisses     = "hastekit.semantic_cache.misses"
+
+	// AttrVideoGenerationStatus is the status of a video generation job as
+	// started or polled ("queued", "in_progress", "completed" or "failed").
+	AttrVideoGenerationStatus = "hastekit.video_generation.status"
 )
 
 // gen_ai.operation.name values (plus best-effort values for operations the
-// spec does not yet cover: speech/transcription/image).
+// spec does not yet cover: speech/transcription/image/realtime).
 const (
 	OpChat            = "chat"
 	OpEmbeddings      = "embeddings"
@@ -77,20 +89,30 @@ const (
 	OpTranscription   = "transcription"
 	OpImageGeneration = "image_generation"
 	OpImageEdit       = "image_edit"
+	OpVideoGeneration = "video_generation"
+	OpRealtime        = "realtime"
 	OpExecuteTool     = "execute_tool"
 	OpInvokeAgent     = "invoke_agent"
 )
 
 // hastekit.request_type values — the usage dashboard groups by these verbatim.
 const (
-	RequestTypeChat            = "Chat"
-	RequestTypeChatStream      = "Chat (Stream)"
-	RequestTypeResponses       = "Responses"
-	RequestTypeResponsesStream = "Responses (Stream)"
-	RequestTypeEmbeddings      = "Embeddings"
-	RequestTypeSpeech          = "Speech"
-	RequestTypeSpeechStream    = "Speech (Stream)"
-	RequestTypeTranscription   = "Transcription"
-	RequestTypeImageGeneration = "ImageGeneration"
-	RequestTypeImageEdit       = "ImageEdit"
+	RequestTypeChat                    = "Chat"
+	RequestTypeChatStream              = "Chat (Stream)"
+	RequestTypeResponses               = "Responses"
+	RequestTypeResponsesStream         = "Responses (Stream)"
+	RequestTypeResponsesGet            = "Responses (Get)"
+	RequestTypeResponsesCancel         = "Responses (Cancel)"
+	RequestTypeResponsesStreamFrom     = "Responses (Stream From)"
+	RequestTypeEmbeddings              = "Embeddings"
+	RequestTypeSpeech                  = "Speech"
+	RequestTypeSpeechStream            = "Speech (Stream)"
+	RequestTypeTranscription           = "Transcription"
+	RequestTypeTranscriptionStream     = "Transcription (Stream)"
+	RequestTypeImageGeneration         = "ImageGeneration"
+	RequestTypeImageEdit               = "ImageEdit"
+	RequestTypeVideoGeneration         = "VideoGeneration"
+	RequestTypeVideoGenerationGet      = "VideoGeneration (Get)"
+	RequestTypeVideoGenerationDownload = "VideoGeneration (Download)"
+	RequestTypeRealtime                = "Realtime"
 )
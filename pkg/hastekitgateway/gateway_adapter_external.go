@@ -18,6 +18,7 @@ import (
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/responses"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/speech"
 	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/transcription"
+	"github.com/hastekit/agent-sdk-go/pkg/gateway/llm/video_generation"
 	"github.com/hastekit/agent-sdk-go/pkg/utils"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
@@ -509,6 +510,13 @@ func (p *ExternalLLMGateway) NewTranscription(ctx context.Context, providerName
 	return &nativeResp, nil
 }
 
+// NewStreamingTranscription is not available remotely: the gateway API takes
+// audio as one JSON body, so there is no way to stream it in. Use
+// NewTranscription, or run the gateway in-process.
+func (p *ExternalLLMGateway) NewStreamingTranscription(ctx context.Context, providerName llm.ProviderName, key string, req *transcription.Request) (chan *transcription.ResponseChunk, error) {
+	return nil, fmt.Errorf("streaming transcription is not supported over the agent-server gateway API")
+}
+
 func (p *ExternalLLMGateway) NewImageGeneration(ctx context.Context, providerName llm.ProviderName, key string, req *image_generation.Request) (*image_generation.Response, error) {
 	// Prepend provider to model for gateway routing
 	originalModel := req.Model
@@ -598,3 +606,19 @@ func (p *ExternalLLMGateway) NewImageEdit(ctx context.Context, providerName llm.
 
 	return &nativeResp, nil
 }
+
+// Video generation is not yet part of the agent-server gateway API. Run the
+// gateway in-process to use it.
+var errVideoGenerationNotSupported = fmt.Errorf("video generation is not supported over the agent-server gateway API")
+
+func (p *ExternalLLMGateway) NewVideoGeneration(ctx context.Context, providerName llm.ProviderName, key string, req *video_generation.Request) (*video_generation.Response, error) {
+	return nil, errVideoGenerationNotSupported
+}
+
+func (p *ExternalLLMGateway) GetVideoGeneration(ctx context.Context, providerName llm.ProviderName, key string, req *video_generation.GetRequest) (*video_generation.Response, error) {
+	return nil, errVideoGenerationNotSupported
+}
+
+func (p *ExternalLLMGateway) DownloadVideoGeneration(ctx context.Context, providerName llm.ProviderName, key string, req *video_generation.DownloadRequest) (*video_generation.Content, error) {
+	return nil, errVideoGenerationNotSupported
+}
@@ -1,43 +1,28 @@
 package sdk
 
 import (
-	"log/slog"
+	"context"
 
-	json "github.com/bytedance/sonic"
+	"github.com/hastekit/agent-sdk-go/pkg/agents"
 	"github.com/invopop/jsonschema"
 )
 
 type OutputSchema = jsonschema.Schema
 
+// NewOutputSchema reflects v's type into a JSON schema — see
+// agents.NewOutputSchema.
 func NewOutputSchema(v any) map[string]any {
-	r := &jsonschema.Reflector{
-		// Inline the root struct (type/properties/required at top level)
-		// instead of emitting a top-level $ref into $defs.
-		ExpandedStruct: true,
-		// Inline all nested definitions so the schema is self-contained
-		// (no $ref/$defs), which the LLM tool APIs require.
-		DoNotReference: true,
-	}
-
-	schema := r.Reflect(v)
-	// Drop $schema and $id metadata that the tool APIs reject.
-	schema.Version = ""
-	schema.ID = ""
-
-	buf, err := schema.MarshalJSON()
-	if err != nil {
-		slog.Warn("failed to reflect output schema: " + err.Error())
-		return nil
-	}
+	return agents.NewOutputSchema(v)
+}
 
-	ss := map[string]any{}
-	err = json.Unmarshal(buf, &ss)
-	if err != nil {
-		slog.Warn("failed to unmarshal output schema: " + err.Error())
-		return nil
-	}
+// OutputSchemaFor is NewOutputSchema for the type T.
+func OutputSchemaFor[T any]() map[string]any {
+	return agents.OutputSchemaFor[T]()
+}
 
-	return ss
+// RunTyped runs agent and decodes its answer into a T — see agents.RunTyped.
+func RunTyped[T any](ctx context.Context, agent *Agent, in *agents.AgentInput) (T, *agents.AgentOutput, error) {
+	return agents.RunTyped[T](ctx, agent, in)
 }
 
 func NewOutputFormatJSONSchema(v any, strict bool) map[string]any {